@@ -11,6 +11,7 @@ import (
 	"github.com/nexodus-io/nexodus/internal/email"
 	"github.com/nexodus-io/nexodus/internal/ipam/cmd"
 	"github.com/nexodus-io/nexodus/internal/signalbus"
+	"github.com/nexodus-io/nexodus/internal/stun"
 	"github.com/nexodus-io/nexodus/internal/util"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
@@ -20,6 +21,8 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -28,8 +31,10 @@ import (
 
 	"gorm.io/gorm"
 
+	goipam "github.com/metal-stack/go-ipam"
 	"github.com/nexodus-io/nexodus/internal/database"
 	"github.com/nexodus-io/nexodus/internal/fflags"
+	"github.com/nexodus-io/nexodus/internal/grpcapi"
 	"github.com/nexodus-io/nexodus/internal/handlers"
 	"github.com/nexodus-io/nexodus/internal/ipam"
 	"github.com/nexodus-io/nexodus/internal/routers"
@@ -85,6 +90,18 @@ func main() {
 				Usage:   "Enable debug logging",
 				Sources: cli.EnvVars("NEXAPI_DEBUG"),
 			},
+			&cli.BoolFlag{
+				Name:    "dev",
+				Value:   false,
+				Usage:   "Run the control plane as one process with a sqlite database and an embedded IPAM kept in --dev-dir, without an identity provider",
+				Sources: cli.EnvVars("NEXAPI_DEV"),
+			},
+			&cli.StringFlag{
+				Name:    "dev-dir",
+				Value:   "nexodus-dev",
+				Usage:   "The directory the dev mode keeps its database, IPAM allocations and keys in",
+				Sources: cli.EnvVars("NEXAPI_DEV_DIR"),
+			},
 			&cli.StringFlag{
 				Name:    "listen",
 				Value:   "0.0.0.0:8080",
@@ -99,6 +116,32 @@ func main() {
 				Sources: cli.EnvVars("NEXAPI_LISTEN_GRPC"),
 			},
 
+			&cli.StringFlag{
+				Name:    "listen-grpc-api",
+				Value:   "",
+				Usage:   "The address and port to serve the gRPC API of the device sync and the events on, for example 0.0.0.0:5081, the gRPC API is not served when empty",
+				Sources: cli.EnvVars("NEXAPI_LISTEN_GRPC_API"),
+			},
+
+			&cli.StringFlag{
+				Name:    "grpc-api-tls-cert",
+				Usage:   "The certificate file the gRPC API is served with over TLS, without it the gRPC API is served in plaintext and must sit behind a TLS terminating proxy",
+				Sources: cli.EnvVars("NEXAPI_GRPC_API_TLS_CERT"),
+			},
+
+			&cli.StringFlag{
+				Name:    "grpc-api-tls-key",
+				Usage:   "The private key file of --grpc-api-tls-cert",
+				Sources: cli.EnvVars("NEXAPI_GRPC_API_TLS_KEY"),
+			},
+
+			&cli.StringFlag{
+				Name:    "listen-stun",
+				Value:   "",
+				Usage:   "The address and port to serve STUN on, for example 0.0.0.0:3478, STUN is not served when empty",
+				Sources: cli.EnvVars("NEXAPI_LISTEN_STUN"),
+			},
+
 			&cli.StringFlag{
 				Name:    "oidc-url",
 				Value:   "https://auth.try.nexodus.127.0.0.1.nip.io",
@@ -135,6 +178,18 @@ func main() {
 				Usage:   "OIDC client id for cli",
 				Sources: cli.EnvVars("NEXAPI_OIDC_CLIENT_ID_CLI"),
 			},
+			&cli.StringFlag{
+				Name:    "oidc-display-name",
+				Value:   "Nexodus",
+				Usage:   "Name of the oidc provider shown to the users selecting the provider they log in with",
+				Sources: cli.EnvVars("NEXAPI_OIDC_DISPLAY_NAME"),
+			},
+			&cli.StringFlag{
+				Name:    "oidc-providers-file",
+				Value:   "",
+				Usage:   "YAML file of the oidc providers the users can log in with besides the one of --oidc-url",
+				Sources: cli.EnvVars("NEXAPI_OIDC_PROVIDERS_FILE"),
+			},
 			&cli.StringFlag{
 				Name:    "db-host",
 				Value:   "apiserver-db",
@@ -171,12 +226,23 @@ func main() {
 				Usage:   "Database ssl mode",
 				Sources: cli.EnvVars("NEXAPI_DB_SSLMODE"),
 			},
+			&cli.StringFlag{
+				Name:    "db-read-dsn",
+				Usage:   "DSN of a read replica of the database, the device, user and organization lists are read from it when set",
+				Sources: cli.EnvVars("NEXAPI_DB_READ_DSN"),
+			},
 			&cli.StringFlag{
 				Name:    "ipam-address",
 				Value:   "ipam:9090",
 				Usage:   "Address of ipam grpc service",
 				Sources: cli.EnvVars("NEXAPI_IPAM_URL"),
 			},
+			&cli.StringFlag{
+				Name:    "ipam-backend",
+				Value:   "service",
+				Usage:   "IPAM backend: service to use the ipam grpc service at --ipam-address, or embedded to allocate in process with the state kept in the apiserver database",
+				Sources: cli.EnvVars("NEXAPI_IPAM_BACKEND"),
+			},
 			&cli.BoolFlag{
 				Name:    "trace-insecure",
 				Value:   false,
@@ -216,7 +282,7 @@ func main() {
 				Usage:    "Redis host:port address",
 				Value:    "redis:6379",
 				Sources:  cli.EnvVars("NEXAPI_REDIS_SERVER"),
-				Required: true,
+				Required: false,
 			},
 			&cli.IntFlag{
 				Name:    "redis-db",
@@ -224,22 +290,28 @@ func main() {
 				Value:   1,
 				Sources: cli.EnvVars("NEXAPI_REDIS_DB"),
 			},
+			&cli.StringFlag{
+				Name:    "signal-bus",
+				Usage:   "How the apiserver replicas notify each other of the changes for the event streams: postgres, redis, or memory for a single replica",
+				Value:   "postgres",
+				Sources: cli.EnvVars("NEXAPI_SIGNAL_BUS"),
+			},
 			&cli.StringFlag{
 				Name:     "tls-key",
-				Usage:    "The server jwks private key",
-				Required: true,
+				Usage:    "The server jwks private key, generated in dev mode",
+				Required: false,
 				Sources:  cli.EnvVars("NEXAPI_TLS_KEY"),
 			},
 			&cli.StringFlag{
 				Name:     "tls-cert",
 				Usage:    "The server jwks cert key",
-				Required: true,
+				Required: false,
 				Sources:  cli.EnvVars("NEXAPI_TLS_KEY"),
 			},
 			&cli.StringFlag{
 				Name:     "url",
-				Usage:    "The server url",
-				Required: true,
+				Usage:    "The server url, https://api.127.0.0.1.nip.io with the port of --listen in dev mode",
+				Required: false,
 				Sources:  cli.EnvVars("NEXAPI_URL"),
 			},
 
@@ -285,6 +357,76 @@ func main() {
 				Required: false,
 				Sources:  cli.EnvVars("NEXAPI_CA_KEY"),
 			},
+			&cli.StringFlag{
+				Name:     "agent-releases",
+				Usage:    "JSON list of the nexd releases advertised on each update channel",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_AGENT_RELEASES"),
+			},
+			&cli.StringFlag{
+				Name:     "min-agent-version",
+				Usage:    "Oldest nexd version allowed to connect, older agents are asked to upgrade",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_MIN_AGENT_VERSION"),
+			},
+			&cli.StringFlag{
+				Name:     "unversioned-api-sunset",
+				Usage:    "The date, in the YYYY-MM-DD format, the unversioned /api routes are to be removed on, announced in their Sunset header",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_UNVERSIONED_API_SUNSET"),
+			},
+			&cli.StringFlag{
+				Name:     "agent-upgrade-instructions",
+				Usage:    "Instructions returned to nexd agents older than --min-agent-version",
+				Value:    "see https://docs.nexodus.io/user-guide/nexd/ to install the latest nexd release",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_AGENT_UPGRADE_INSTRUCTIONS"),
+			},
+			&cli.DurationFlag{
+				Name:     "ipam-reclaim-interval",
+				Usage:    "How often to release the IPAM allocations of deleted devices, 0 disables the reclaim job",
+				Value:    5 * time.Minute,
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_IPAM_RECLAIM_INTERVAL"),
+			},
+			&cli.DurationFlag{
+				Name:     "ipam-reclaim-after",
+				Usage:    "Delete the devices not seen for this long and release their IPAM allocations, 0 keeps them",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_IPAM_RECLAIM_AFTER"),
+			},
+			&cli.DurationFlag{
+				Name:     "device-cache-ttl",
+				Usage:    "Cache the device lists and devices read by the agents in redis for this long, 0 disables the cache",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_DEVICE_CACHE_TTL"),
+			},
+			&cli.DurationFlag{
+				Name:     "webhook-interval",
+				Usage:    "How often to post the queued webhook deliveries, 0 disables the webhook posts",
+				Value:    10 * time.Second,
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_WEBHOOK_INTERVAL"),
+			},
+			&cli.BoolFlag{
+				Name:     "webhook-allow-private",
+				Usage:    "Allow the webhooks to post to loopback, private and link local addresses",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_WEBHOOK_ALLOW_PRIVATE"),
+			},
+			&cli.StringSliceFlag{
+				Name:     "rate-limit",
+				Usage:    "Rate limit of each user and device on an api route group, in the group=rate:burst format with the rate in requests per second, the default group applies to the groups without a limit",
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_RATE_LIMITS"),
+			},
+			&cli.BoolFlag{
+				Name:     "auto-migrate",
+				Usage:    "Apply the pending database migrations on start, when disabled the apiserver refuses to start until they are applied with the migrate up command",
+				Value:    true,
+				Required: false,
+				Sources:  cli.EnvVars("NEXAPI_AUTO_MIGRATE"),
+			},
 		},
 
 		Action: func(ctx context.Context, command *cli.Command) error {
@@ -294,30 +436,70 @@ func main() {
 			withLoggerAndDB(ctx, command, func(logger *zap.Logger, db *gorm.DB, dsn string) {
 				pprof_init(ctx, command, logger)
 
-				if err := database.Migrations().Migrate(ctx, db); err != nil {
+				migrations := database.Migrations()
+				autoMigrate := command.Bool("auto-migrate")
+				if err := migrations.CheckSchema(ctx, db, logger.Sugar(), autoMigrate); err != nil {
 					log.Fatal(err)
 				}
+				if autoMigrate {
+					if err := migrations.Migrate(ctx, db); err != nil {
+						log.Fatal(err)
+					}
+				}
+				if dsn := command.String("db-read-dsn"); dsn != "" {
+					if err := database.UseReadReplica(db, dsn); err != nil {
+						log.Fatal(fmt.Errorf("failed to open the read replica: %w", err))
+					}
+				}
+
+				dev := command.Bool("dev")
+				// the dev mode runs without redis, the users and the devices are not cached
+				var redisClient *redis.Client
+				if !dev {
+					redisClient = redis.NewClient(&redis.Options{
+						Addr:             command.String("redis-server"),
+						DB:               int(command.Int("redis-db")),
+						DisableIndentity: true,
+					})
+				}
 
-				signalBus := signalbus.NewPgSignalBus(signalbus.NewSignalBus(), db, dsn, logger.Sugar())
 				wg := &sync.WaitGroup{}
-				signalBus.Start(ctx, wg)
+				var signalBus signalbus.SignalBus
+				signalBusKind := command.String("signal-bus")
+				if dev {
+					// there are no other replicas to notify
+					signalBusKind = "memory"
+				}
+				switch signalBusKind {
+				case "memory":
+					signalBus = signalbus.NewSignalBus()
+				case "postgres":
+					pgSignalBus := signalbus.NewPgSignalBus(signalbus.NewSignalBus(), db, dsn, logger.Sugar())
+					pgSignalBus.Start(ctx, wg)
+					signalBus = pgSignalBus
+				case "redis":
+					redisSignalBus := signalbus.NewRedisSignalBus(signalbus.NewSignalBus(), redisClient, logger.Sugar())
+					redisSignalBus.Start(ctx, wg)
+					signalBus = redisSignalBus
+				default:
+					log.Fatalf("invalid --signal-bus value: %s", command.String("signal-bus"))
+				}
 
-				ipam := ipam.NewIPAM(logger.Sugar(), command.String("ipam-address"))
+				ipam := newIPAM(command, logger)
 
 				fflags := fflags.NewFFlags(logger.Sugar())
 
 				store := inmem.New()
 
-				redisClient := redis.NewClient(&redis.Options{
-					Addr:             command.String("redis-server"),
-					DB:               int(command.Int("redis-db")),
-					DisableIndentity: true,
-				})
-
-				sessionStore := redisStore.NewRedisStore(&redisStore.Options{
-					Addr: command.String("redis-server"),
-					DB:   int(command.Int("redis-db")),
-				})
+				var sessionStore session.ManagerStore
+				if dev {
+					sessionStore = session.NewMemoryStore()
+				} else {
+					sessionStore = redisStore.NewRedisStore(&redisStore.Options{
+						Addr: command.String("redis-server"),
+						DB:   int(command.Int("redis-db")),
+					})
+				}
 
 				sessionManager := session.NewManager(
 					session.SetCookieName(handlers.SESSION_ID_COOKIE_NAME),
@@ -339,7 +521,24 @@ func main() {
 				}
 
 				api.URL = command.String("url")
-				api.FrontendURL = command.StringSlice("origins")[0]
+				if dev && api.URL == "" {
+					_, port, err := net.SplitHostPort(command.String("listen"))
+					if err != nil {
+						log.Fatal(fmt.Errorf("invalid listen address: %w", err))
+					}
+					api.URL = fmt.Sprintf("https://api.%s:%s", devHost, port)
+				}
+				if api.URL == "" {
+					log.Fatal("the --url flag is required")
+				}
+				origins := command.StringSlice("origins")
+				if dev && len(origins) == 0 {
+					origins = []string{api.URL}
+				}
+				if len(origins) == 0 {
+					log.Fatal("at least one --origins value is required")
+				}
+				api.FrontendURL = origins[0]
 				api.URLParsed, err = url.Parse(api.URL)
 				if err != nil {
 					log.Fatal(fmt.Errorf("invalid url: %w", err))
@@ -358,66 +557,186 @@ func main() {
 				api.SmtpServer = smtpServer
 				api.SmtpFrom = command.String("smtp-from")
 
-				scopes := []string{"openid", "profile", "email"}
-				scopes = append(scopes, command.StringSlice("scopes")...)
-
-				webAuth, err := agent.NewOidcAgent(
-					ctx,
-					logger,
-					command.String("oidc-url"),
-					command.String("oidc-backchannel-url"),
-					command.Bool("insecure-tls"),
-					command.String("oidc-client-id-web"),
-					command.String("oidc-client-secret-web"),
-					fmt.Sprintf("%s/web/login/end", api.URL),
-					scopes,
-					command.String("domain"),
-					command.StringSlice("origins"),
-					"", // backend
-					command.String("cookie-key"),
-				)
-				if err != nil {
-					log.Fatal(err)
+				if command.String("min-agent-version") != "" {
+					if _, err := util.ParseVersion(command.String("min-agent-version")); err != nil {
+						log.Fatal("invalid --min-agent-version value:", err)
+					}
 				}
 
-				cliAuth, err := agent.NewOidcAgent(
-					ctx,
-					logger,
-					command.String("oidc-url"),
-					command.String("oidc-backchannel-url"),
-					command.Bool("insecure-tls"),
-					command.String("oidc-client-id-cli"),
-					"", // clientSecret
-					"", // redirectURL
-					scopes,
-					command.String("domain"),
-					[]string{}, // origins
-					"",         // backend
-					"",         // cookieKey
-				)
-				if err != nil {
+				if command.String("agent-releases") != "" {
+					api.AgentReleases, err = handlers.ParseAgentReleases([]byte(command.String("agent-releases")))
+					if err != nil {
+						log.Fatal("invalid --agent-releases value:", err)
+					}
+				}
+
+				if err := api.EnableDeviceCache(command.Duration("device-cache-ttl")); err != nil {
 					log.Fatal(err)
 				}
 
-				tlsKey := command.String("tls-key")
-				api.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(tlsKey))
+				api.IPAMReclaimAfter = command.Duration("ipam-reclaim-after")
+				api.StartIPAMReclaimer(ctx, command.Duration("ipam-reclaim-interval"))
+
+				api.WebhookAllowPrivate = command.Bool("webhook-allow-private")
+				api.StartWebhookDispatcher(ctx, command.Duration("webhook-interval"))
+
+				// the dev mode has no identity provider, the clients authenticate with the tokens of the apiserver
+				oidcURL := command.String("oidc-url")
+				var browserFlow, deviceFlow *agent.MultiOidcAgent
+				var routerProviders []routers.OidcProvider
+				if dev {
+					oidcURL = ""
+				} else {
+					scopes := []string{"openid", "profile", "email"}
+					scopes = append(scopes, command.StringSlice("scopes")...)
+
+					webAuth, err := agent.NewOidcAgent(
+						ctx,
+						logger,
+						command.String("oidc-url"),
+						command.String("oidc-backchannel-url"),
+						command.Bool("insecure-tls"),
+						command.String("oidc-client-id-web"),
+						command.String("oidc-client-secret-web"),
+						fmt.Sprintf("%s/web/login/end", api.URL),
+						scopes,
+						command.String("domain"),
+						origins,
+						"", // backend
+						command.String("cookie-key"),
+					)
+					if err != nil {
+						log.Fatal(err)
+					}
+
+					cliAuth, err := agent.NewOidcAgent(
+						ctx,
+						logger,
+						command.String("oidc-url"),
+						command.String("oidc-backchannel-url"),
+						command.Bool("insecure-tls"),
+						command.String("oidc-client-id-cli"),
+						"", // clientSecret
+						"", // redirectURL
+						scopes,
+						command.String("domain"),
+						[]string{}, // origins
+						"",         // backend
+						"",         // cookieKey
+					)
+					if err != nil {
+						log.Fatal(err)
+					}
+
+					oidcProviders, err := readOidcProviders(command.String("oidc-providers-file"))
+					if err != nil {
+						log.Fatal(err)
+					}
+					webProviders := []agent.Provider{{Name: defaultOidcProvider, DisplayName: command.String("oidc-display-name"), Agent: webAuth}}
+					cliProviders := []agent.Provider{{Name: defaultOidcProvider, DisplayName: command.String("oidc-display-name"), Agent: cliAuth}}
+					for _, p := range oidcProviders {
+						providerScopes := append([]string{"openid", "profile", "email"}, p.Scopes...)
+						providerWebAuth, err := agent.NewOidcAgent(
+							ctx,
+							logger,
+							p.URL,
+							p.BackchannelURL,
+							command.Bool("insecure-tls"),
+							p.ClientIDWeb,
+							p.ClientSecretWeb,
+							fmt.Sprintf("%s/web/login/end", api.URL),
+							providerScopes,
+							command.String("domain"),
+							origins,
+							"", // backend
+							command.String("cookie-key"),
+						)
+						if err != nil {
+							log.Fatal(err)
+						}
+						webProviders = append(webProviders, agent.Provider{Name: p.Name, DisplayName: p.DisplayName, Agent: providerWebAuth})
+						if p.ClientIDCli != "" {
+							providerCliAuth, err := agent.NewOidcAgent(
+								ctx,
+								logger,
+								p.URL,
+								p.BackchannelURL,
+								command.Bool("insecure-tls"),
+								p.ClientIDCli,
+								"", // clientSecret
+								"", // redirectURL
+								providerScopes,
+								command.String("domain"),
+								[]string{}, // origins
+								"",         // backend
+								"",         // cookieKey
+							)
+							if err != nil {
+								log.Fatal(err)
+							}
+							cliProviders = append(cliProviders, agent.Provider{Name: p.Name, DisplayName: p.DisplayName, Agent: providerCliAuth})
+						}
+						routerProviders = append(routerProviders, routers.OidcProvider{
+							Name:        p.Name,
+							Issuer:      p.URL,
+							Backchannel: p.BackchannelURL,
+							Audience:    p.Audience,
+						})
+					}
+					browserFlow = agent.NewMultiOidcAgent(webProviders...)
+					deviceFlow = agent.NewMultiOidcAgent(cliProviders...)
+				}
+
+				tlsKey := []byte(command.String("tls-key"))
+				if dev && len(tlsKey) == 0 {
+					tlsKey, err = devJWKSKey(command.String("dev-dir"))
+					if err != nil {
+						log.Fatal(fmt.Errorf("failed to create the dev tls-key: %w", err))
+					}
+				}
+				api.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM(tlsKey)
 				if err != nil {
 					log.Fatal(fmt.Errorf("invalid tls-key: %w", err))
 				}
 
-				router, err := routers.NewAPIRouter(ctx, routers.APIRouterOptions{
+				var unversionedAPISunset time.Time
+				if sunset := command.String("unversioned-api-sunset"); sunset != "" {
+					unversionedAPISunset, err = time.Parse(time.DateOnly, sunset)
+					if err != nil {
+						log.Fatalf("invalid --unversioned-api-sunset: %v", err)
+					}
+				}
+
+				rateLimits, err := routers.ParseRateLimits(command.StringSlice("rate-limit"))
+				if err != nil {
+					log.Fatalf("invalid --rate-limit: %v", err)
+				}
+
+				routerOptions := routers.APIRouterOptions{
 					Logger:          logger.Sugar(),
 					Api:             api,
 					ClientIdWeb:     command.String("oidc-client-id-web"),
 					ClientIdCli:     command.String("oidc-client-id-cli"),
-					OidcURL:         command.String("oidc-url"),
+					OidcURL:         oidcURL,
 					OidcBackchannel: command.String("oidc-backchannel-url"),
 					InsecureTLS:     command.Bool("insecure-tls"),
-					BrowserFlow:     webAuth,
-					DeviceFlow:      cliAuth,
+					BrowserFlow:     browserFlow,
+					DeviceFlow:      deviceFlow,
+					OidcProviders:   routerProviders,
 					Store:           store,
 					SessionStore:    sessionStore,
-				})
+
+					MinAgentVersion:          command.String("min-agent-version"),
+					AgentUpgradeInstructions: command.String("agent-upgrade-instructions"),
+					UnversionedAPISunset:     unversionedAPISunset,
+					RateLimits:               rateLimits,
+				}
+				// the gRPC API shares the middleware of the api routes
+				routerOptions.AuthMiddleware, err = routers.NewAuthMiddleware(ctx, routerOptions)
+				if err != nil {
+					log.Fatal(err)
+				}
+				router, err := routers.NewAPIRouter(ctx, routerOptions)
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -431,9 +750,30 @@ func main() {
 				}
 				defer util.IgnoreError(httpServer.Close)
 
-				serveErrors := make(chan error, 2)
+				if dev {
+					// the agents only connect to https urls
+					cert, err := devTLSCertificate(command.String("dev-dir"))
+					if err != nil {
+						log.Fatal(fmt.Errorf("failed to create the dev tls certificate: %w", err))
+					}
+					httpServer.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+					key, err := api.CreateDevAPIKey(ctx)
+					if err != nil {
+						log.Fatal(fmt.Errorf("failed to create the dev api key: %w", err))
+					}
+					serviceURL := strings.Replace(api.URL, "://api.", "://", 1)
+					logger.Sugar().Infof("Running in dev mode, authenticate as the dev user with the api key %s, e.g. nexctl --service-url %s --insecure-skip-tls-verify --api-key %s vpc list", key.Token, serviceURL, key.Token)
+				}
+
+				serveErrors := make(chan error, 3)
 				util.GoWithWaitGroup(wg, func() {
-					if err = httpServer.ListenAndServe(); err != nil {
+					if httpServer.TLSConfig != nil {
+						err = httpServer.ListenAndServeTLS("", "")
+					} else {
+						err = httpServer.ListenAndServe()
+					}
+					if err != nil {
 						serveErrors <- err
 					}
 				})
@@ -453,6 +793,43 @@ func main() {
 					}
 				})
 
+				var grpcAPIServer *grpc.Server
+				if address := command.String("listen-grpc-api"); address != "" {
+					grpcAPIListener, err := net.Listen("tcp", address)
+					if err != nil {
+						log.Fatal(err)
+					}
+					defer util.IgnoreError(grpcAPIListener.Close)
+
+					// without a certificate, the gRPC API must be served behind a TLS terminating proxy
+					var serverOptions []grpc.ServerOption
+					if certFile := command.String("grpc-api-tls-cert"); certFile != "" {
+						creds, err := credentials.NewServerTLSFromFile(certFile, command.String("grpc-api-tls-key"))
+						if err != nil {
+							log.Fatal(fmt.Errorf("invalid --grpc-api-tls-cert or --grpc-api-tls-key: %w", err))
+						}
+						serverOptions = append(serverOptions, grpc.Creds(creds))
+					} else if httpServer.TLSConfig != nil {
+						serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(httpServer.TLSConfig)))
+					}
+					grpcAPIServer = grpc.NewServer(serverOptions...)
+					defer grpcAPIServer.Stop()
+					grpcapi.NewServer(logger.Sugar(), api, routerOptions.AuthMiddleware...).Register(grpcAPIServer)
+					util.GoWithWaitGroup(wg, func() {
+						if err := grpcAPIServer.Serve(grpcAPIListener); err != nil {
+							serveErrors <- err
+						}
+					})
+				}
+
+				if address := command.String("listen-stun"); address != "" {
+					stunServer, err := stun.ListenAndStart(address, logger)
+					if err != nil {
+						log.Fatal(err)
+					}
+					defer util.IgnoreError(stunServer.Shutdown)
+				}
+
 				// Wait for a shutdown signal or a server has an error
 				beginShutdown := &sync.WaitGroup{}
 				util.GoWithWaitGroup(beginShutdown, func() {
@@ -470,6 +847,11 @@ func main() {
 				go func() {
 					grpcServer.GracefulStop()
 				}()
+				if grpcAPIServer != nil {
+					go func() {
+						grpcAPIServer.GracefulStop()
+					}()
+				}
 				go func() {
 					_ = httpServer.Shutdown(shutdownCtx)
 				}()
@@ -513,6 +895,90 @@ func main() {
 			return nil
 		},
 	})
+	app.Commands = append(app.Commands, &cli.Command{
+		Name:  "migrate",
+		Usage: "Manage the database migrations",
+		Commands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "Apply the pending database migrations",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Only apply the migrations up to and including this migration id",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					withLoggerAndDB(ctx, command, func(logger *zap.Logger, db *gorm.DB, dsn string) {
+						migrations := database.Migrations()
+						if err := migrations.CheckSchema(ctx, db, logger.Sugar(), true); err != nil {
+							log.Fatal(err)
+						}
+						var err error
+						if to := command.String("to"); to != "" {
+							err = migrations.MigrateTo(ctx, db, to)
+						} else {
+							err = migrations.Migrate(ctx, db)
+						}
+						if err != nil {
+							log.Fatal(err)
+						}
+					})
+					return nil
+				},
+			},
+			{
+				Name:  "down",
+				Usage: "Rollback the last database migration",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Rollback the migrations applied after this migration id instead",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					withLoggerAndDB(ctx, command, func(logger *zap.Logger, db *gorm.DB, dsn string) {
+						migrations := database.Migrations()
+						if err := migrations.CheckSchema(ctx, db, logger.Sugar(), true); err != nil {
+							log.Fatal(err)
+						}
+						var err error
+						if to := command.String("to"); to != "" {
+							err = migrations.RollbackTo(ctx, db, to)
+						} else {
+							err = migrations.RollbackLast(ctx, db)
+						}
+						if err != nil {
+							log.Fatal(err)
+						}
+					})
+					return nil
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "List the database migrations and whether they are applied",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					withLoggerAndDB(ctx, command, func(logger *zap.Logger, db *gorm.DB, dsn string) {
+						status, err := database.Migrations().Status(ctx, db)
+						if err != nil {
+							log.Fatal(err)
+						}
+						for _, s := range status {
+							state := "pending"
+							if s.Unknown {
+								state = "applied, unknown to this version"
+							} else if s.Applied {
+								state = "applied"
+							}
+							fmt.Printf("%s\t%s\n", s.ID, state)
+						}
+					})
+					return nil
+				},
+			},
+		},
+	})
 	app.Commands = append(app.Commands, &cli.Command{
 		Name: "ipam",
 		// only show this sub command if your in debug mode.
@@ -525,7 +991,7 @@ func main() {
 				Action: func(ctx context.Context, command *cli.Command) error {
 
 					withLoggerAndDB(ctx, command, func(logger *zap.Logger, db *gorm.DB, dsn string) {
-						ipam := ipam.NewIPAM(logger.Sugar(), command.String("ipam-address"))
+						ipam := newIPAM(command, logger)
 						if err := cmd.Rebuild(ctx, logger, db, ipam); err != nil {
 							log.Fatal(err)
 						}
@@ -620,6 +1086,38 @@ func getLogger(command *cli.Command) *zap.Logger {
 	}
 	return logger
 }
+
+// newIPAM returns the IPAM backend selected with --ipam-backend
+func newIPAM(command *cli.Command, logger *zap.Logger) ipam.IPAM {
+	if command.Bool("dev") {
+		storage, err := ipam.NewSnapshotStorage(context.Background(), filepath.Join(command.String("dev-dir"), "ipam.json"))
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to open the dev ipam storage: %w", err))
+		}
+		return ipam.NewEmbeddedIPAM(logger.Sugar(), storage)
+	}
+	switch command.String("ipam-backend") {
+	case "service":
+		return ipam.NewIPAM(logger.Sugar(), command.String("ipam-address"))
+	case "embedded":
+		storage, err := goipam.NewPostgresStorage(
+			command.String("db-host"),
+			command.String("db-port"),
+			command.String("db-user"),
+			command.String("db-password"),
+			command.String("db-name"),
+			goipam.SSLMode(command.String("db-sslmode")),
+		)
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to open the embedded ipam storage: %w", err))
+		}
+		return ipam.NewEmbeddedIPAM(logger.Sugar(), storage)
+	default:
+		log.Fatalf("invalid --ipam-backend value: %s, must be service or embedded", command.String("ipam-backend"))
+		return nil
+	}
+}
+
 func withLoggerAndDB(ctx context.Context, command *cli.Command, f func(logger *zap.Logger, db *gorm.DB, dsn string)) {
 	logger := getLogger(command)
 	cleanup := initTracer(logger.Sugar(), command.Bool("trace-insecure"), command.String("trace-endpoint"))
@@ -632,6 +1130,19 @@ func withLoggerAndDB(ctx context.Context, command *cli.Command, f func(logger *z
 		}
 	}()
 
+	if command.Bool("dev") {
+		dir := command.String("dev-dir")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			log.Fatal(err)
+		}
+		db, err := database.NewSqliteDatabase(ctx, logger.Sugar(), filepath.Join(dir, "nexodus.db"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		f(logger, db, "")
+		return
+	}
+
 	db, dsn, err := database.NewDatabase(
 		ctx,
 		logger.Sugar(),
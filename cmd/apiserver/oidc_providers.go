@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ghodss/yaml"
+)
+
+// defaultOidcProvider is the name of the identity provider of the --oidc-* flags
+const defaultOidcProvider = "default"
+
+// oidcProviderConfig is an identity provider of the --oidc-providers-file, besides the one of the --oidc-* flags
+type oidcProviderConfig struct {
+	// Name identifies the provider in the login requests, it must be unique
+	Name string `json:"name"`
+	// DisplayName is shown to the users selecting the provider they log in with
+	DisplayName     string `json:"display-name"`
+	URL             string `json:"url"`
+	BackchannelURL  string `json:"backchannel-url"`
+	ClientIDWeb     string `json:"client-id-web"`
+	ClientSecretWeb string `json:"client-secret-web"`
+	// ClientIDCli is the client of the device flow of nexd and nexctl, the device flow is not offered when empty
+	ClientIDCli string `json:"client-id-cli"`
+	// Audience is the audience of the access tokens of the provider
+	Audience string `json:"audience"`
+	// Scopes are requested besides openid, profile and email
+	Scopes []string `json:"scopes"`
+}
+
+// readOidcProviders reads the identity providers of an --oidc-providers-file
+func readOidcProviders(file string) ([]oidcProviderConfig, error) {
+	if file == "" {
+		return nil, nil
+	}
+	// #nosec G304
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var providers []oidcProviderConfig
+	if err := yaml.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("invalid oidc providers file %s: %w", file, err)
+	}
+	names := map[string]bool{defaultOidcProvider: true}
+	for i, p := range providers {
+		if p.Name == "" || p.URL == "" || p.ClientIDWeb == "" {
+			return nil, fmt.Errorf("invalid oidc providers file %s: provider %d requires a name, an url and a client-id-web", file, i)
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("invalid oidc providers file %s: the provider name %s is used twice", file, p.Name)
+		}
+		names[p.Name] = true
+	}
+	return providers, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+func createAPIKeyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "api-key",
+		Usage: "Commands relating to personal api keys",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List api keys",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return listAPIKeys(ctx, command)
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create an api key, its token is only shown once",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "description",
+						Required: false,
+					},
+					&cli.StringSliceFlag{
+						Name:     "scope",
+						Usage:    "the scopes of the api key: read-only, read:organizations, write:organizations, read:devices, write:devices, read:users, write:users",
+						Required: true,
+					},
+					&cli.DurationFlag{
+						Name:     "expiration",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return createAPIKey(ctx, command, public.ModelsAddAPIKey{
+						Description: command.String("description"),
+						Scopes:      command.StringSlice("scope"),
+						ExpiresAt:   getExpiration(command, "expiration"),
+					})
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete an api key",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "api-key-id",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					id, err := getUUID(command, "api-key-id")
+					if err != nil {
+						return err
+					}
+					return deleteAPIKey(ctx, command, id)
+				},
+			},
+		},
+	}
+}
+
+func apiKeyTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "API KEY ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	fields = append(fields, TableField{Header: "SCOPES", Formatter: func(item interface{}) string {
+		switch key := item.(type) {
+		case *public.ModelsAPIKey:
+			return strings.Join(key.Scopes, ",")
+		case public.ModelsAPIKey:
+			return strings.Join(key.Scopes, ",")
+		}
+		return ""
+	}})
+	fields = append(fields, TableField{Header: "EXPIRES AT", Field: "ExpiresAt"})
+	return fields
+}
+
+func listAPIKeys(ctx context.Context, command *cli.Command) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.APIKeyApi.
+		ListAPIKeys(ctx).
+		Execute())
+	show(command, apiKeyTableFields(), res)
+	return nil
+}
+
+func createAPIKey(ctx context.Context, command *cli.Command, key public.ModelsAddAPIKey) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.APIKeyApi.
+		CreateAPIKey(ctx).
+		APIKey(key).
+		Execute())
+	fields := apiKeyTableFields()
+	fields = append(fields, TableField{Header: "TOKEN", Field: "Token"})
+	show(command, fields, res)
+	return nil
+}
+
+func deleteAPIKey(ctx context.Context, command *cli.Command, id string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.APIKeyApi.
+		DeleteAPIKey(ctx, id).
+		Execute())
+	show(command, apiKeyTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+func createAuditCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "audit",
+		Usage: "Commands relating to the audit log of an organization",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the changes made to the resources of an organization",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "organization-id",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:  "resource-type",
+						Usage: "only list changes of this resource type: device, organization, organization_user, security_group or user",
+					},
+					&cli.StringFlag{
+						Name:  "resource-id",
+						Usage: "only list changes of this resource",
+					},
+					&cli.StringFlag{
+						Name:  "action",
+						Usage: "only list changes of this action: create, update, delete or expire",
+					},
+					&cli.StringFlag{
+						Name:  "actor-id",
+						Usage: "only list changes made by this user",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					organizationID, err := requireOrganizationID(command)
+					if err != nil {
+						return err
+					}
+					resourceID, err := getUUID(command, "resource-id")
+					if err != nil {
+						return err
+					}
+					actorID, err := getUUID(command, "actor-id")
+					if err != nil {
+						return err
+					}
+					return listAuditEvents(ctx, command, organizationID, resourceID, actorID)
+				},
+			},
+		},
+	}
+}
+
+func auditTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "TIME", Formatter: func(item interface{}) string {
+		event := item.(public.ModelsAuditEvent)
+		return formatLocalTime(event.CreatedAt)
+	}})
+	fields = append(fields, TableField{Header: "ACTOR ID", Field: "ActorId"})
+	fields = append(fields, TableField{Header: "SOURCE IP", Field: "SourceIp"})
+	fields = append(fields, TableField{Header: "ACTION", Field: "Action"})
+	fields = append(fields, TableField{Header: "RESOURCE TYPE", Field: "ResourceType"})
+	fields = append(fields, TableField{Header: "RESOURCE ID", Field: "ResourceId"})
+	fields = append(fields, TableField{Header: "CHANGED FIELDS", Formatter: func(item interface{}) string {
+		event := item.(public.ModelsAuditEvent)
+		var changed []string
+		for field := range event.Changes {
+			changed = append(changed, field)
+		}
+		sort.Strings(changed)
+		return strings.Join(changed, ",")
+	}})
+	return fields
+}
+
+func listAuditEvents(ctx context.Context, command *cli.Command, organizationID, resourceID, actorID string) error {
+	c := createClient(ctx, command)
+	request := c.OrganizationsApi.ListAuditEvents(ctx, organizationID)
+	if resourceType := command.String("resource-type"); resourceType != "" {
+		request = request.ResourceType(resourceType)
+	}
+	if action := command.String("action"); action != "" {
+		request = request.Action(action)
+	}
+	if resourceID != "" {
+		request = request.ResourceId(resourceID)
+	}
+	if actorID != "" {
+		request = request.ActorId(actorID)
+	}
+	res := apiResponse(request.Execute())
+	show(command, auditTableFields(), res)
+	return nil
+}
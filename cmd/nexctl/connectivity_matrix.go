@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+// connectivityRow is a row of the connectivity matrix, the reachability of the peers reported by a device keyed by peer id
+type connectivityRow struct {
+	Device public.ModelsDevice
+	Peers  map[string]public.ModelsPeerConnectivity
+}
+
+func createConnectivityCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "connectivity",
+		Usage: "Show the connectivity matrix of an organization reported by its devices, see nexd --report-connectivity",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "organization-id",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:  "vpc-id",
+				Usage: "only show the devices of this vpc",
+			},
+		},
+		Action: func(ctx context.Context, command *cli.Command) error {
+			organizationID, err := getOrganizationID(command)
+			if err != nil {
+				return err
+			}
+			vpcID, err := getUUID(command, "vpc-id")
+			if err != nil {
+				return err
+			}
+			return showConnectivity(ctx, command, organizationID, vpcID)
+		},
+	}
+}
+
+// formatPeerConnectivity formats a cell of the connectivity matrix
+func formatPeerConnectivity(peer public.ModelsPeerConnectivity, reported bool) string {
+	if !reported {
+		return "-"
+	}
+	cell := ""
+	switch peer.Reachability {
+	case "reachable":
+		cell = fmt.Sprintf("✓ %.2fms", peer.LatencyAvgMs)
+	case "degraded":
+		cell = fmt.Sprintf("~ %.2fms %.0f%% loss", peer.LatencyAvgMs, peer.Loss*100)
+	default:
+		cell = "x"
+	}
+	if strings.HasPrefix(peer.Method, "via-") {
+		cell += " (relay)"
+	}
+	return cell
+}
+
+func connectivityTableFields(peers []public.ModelsDevice) []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "DEVICE", Formatter: func(item interface{}) string {
+		return deviceName(item.(connectivityRow).Device)
+	}})
+	for _, peer := range peers {
+		peer := peer
+		fields = append(fields, TableField{Header: deviceName(peer), Formatter: func(item interface{}) string {
+			row := item.(connectivityRow)
+			if row.Device.Id == peer.Id {
+				return ""
+			}
+			reported, ok := row.Peers[peer.Id]
+			return formatPeerConnectivity(reported, ok)
+		}})
+	}
+	return fields
+}
+
+func showConnectivity(ctx context.Context, command *cli.Command, organizationID string, vpcID string) error {
+	c := createClient(ctx, command)
+	if organizationID == "" {
+		organizationID = getDefaultOrgId(ctx, c)
+	}
+	reports := apiResponse(c.OrganizationsApi.
+		ListOrganizationConnectivity(ctx, organizationID).
+		Execute())
+	if vpcID != "" {
+		filtered := []public.ModelsPeerConnectivity{}
+		for _, r := range reports {
+			if r.VpcId == vpcID {
+				filtered = append(filtered, r)
+			}
+		}
+		reports = filtered
+	}
+	if command.String("output") != encodeColumn && command.String("output") != encodeNoHeader {
+		show(command, nil, reports)
+		return nil
+	}
+
+	devices := map[string]public.ModelsDevice{}
+	for _, d := range apiResponse(c.DevicesApi.ListDevices(ctx).Execute()) {
+		devices[d.Id] = d
+	}
+	// the rows of each vpc, keyed by the id of the reporting device
+	vpcs := map[string]map[string]*connectivityRow{}
+	for _, r := range reports {
+		rows, ok := vpcs[r.VpcId]
+		if !ok {
+			rows = map[string]*connectivityRow{}
+			vpcs[r.VpcId] = rows
+		}
+		for _, id := range []string{r.DeviceId, r.PeerId} {
+			if _, ok := rows[id]; !ok {
+				device, ok := devices[id]
+				if !ok {
+					device = public.ModelsDevice{Id: id, Hostname: id}
+				}
+				rows[id] = &connectivityRow{Device: device, Peers: map[string]public.ModelsPeerConnectivity{}}
+			}
+		}
+		rows[r.DeviceId].Peers[r.PeerId] = r
+	}
+
+	vpcIDs := make([]string, 0, len(vpcs))
+	for id := range vpcs {
+		vpcIDs = append(vpcIDs, id)
+	}
+	sort.Strings(vpcIDs)
+	for i, id := range vpcIDs {
+		items := []connectivityRow{}
+		for _, row := range vpcs[id] {
+			items = append(items, *row)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return deviceName(items[i].Device) < deviceName(items[j].Device)
+		})
+		peers := []public.ModelsDevice{}
+		for _, row := range items {
+			peers = append(peers, row.Device)
+		}
+
+		if len(vpcIDs) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("VPC %s\n", id)
+		}
+		show(command, connectivityTableFields(peers), items)
+
+		// a peer that is reachable in one direction only usually points at a NAT or a relay failure
+		for _, row := range items {
+			for _, device := range peers {
+				peer, ok := row.Peers[device.Id]
+				if !ok || peer.Reachability == "unreachable" {
+					continue
+				}
+				reverse, ok := vpcs[id][device.Id].Peers[row.Device.Id]
+				if ok && reverse.Reachability == "unreachable" {
+					fmt.Fprintf(os.Stderr, "WARNING: %s reaches %s, but %s does not reach %s\n",
+						deviceName(row.Device), deviceName(device), deviceName(device), deviceName(row.Device))
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/nexodus-io/nexodus/internal/client"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/oauth2"
+)
+
+const (
+	authMethodPassword   = "password"
+	authMethodDeviceFlow = "device-flow"
+)
+
+// nexctlConfig is the config file holding the named contexts, each of the contexts selects a nexodus service
+// and how to authenticate against it, similar to a kubeconfig file.
+type nexctlConfig struct {
+	CurrentContext string          `json:"current-context,omitempty"`
+	Contexts       []nexctlContext `json:"contexts"`
+}
+
+type nexctlContext struct {
+	Name                  string        `json:"name"`
+	ServiceURL            string        `json:"service-url,omitempty"`
+	AuthMethod            string        `json:"auth-method,omitempty"`
+	Username              string        `json:"username,omitempty"`
+	Password              string        `json:"password,omitempty"`
+	OrganizationID        string        `json:"organization-id,omitempty"`
+	InsecureSkipTLSVerify bool          `json:"insecure-skip-tls-verify,omitempty"`
+	Token                 *oauth2.Token `json:"token,omitempty"`
+}
+
+func (c *nexctlConfig) find(name string) *nexctlContext {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".nexodus", "config")
+	}
+	return filepath.Join(home, ".nexodus", "config")
+}
+
+func configPath(command *cli.Command) string {
+	if path := command.String("config"); path != "" {
+		return path
+	}
+	return defaultConfigPath()
+}
+
+// loadConfig reads the config file, a missing config file holds no contexts
+func loadConfig(command *cli.Command) (*nexctlConfig, error) {
+	config := &nexctlConfig{}
+	data, err := os.ReadFile(configPath(command))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse the config file %s: %w", configPath(command), err)
+	}
+	return config, nil
+}
+
+func storeConfig(command *cli.Command, config *nexctlConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode the config file: %w", err)
+	}
+	path := configPath(command)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the config directory: %w", err)
+	}
+	// the config file holds credentials
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write the config file: %w", err)
+	}
+	return nil
+}
+
+// currentContext returns the context selected by the --context flag or the current context of
+// the config file, nil if no context is selected.
+func currentContext(command *cli.Command) *nexctlContext {
+	config, err := loadConfig(command)
+	if err != nil {
+		Fatal(err)
+	}
+	name := config.CurrentContext
+	if command.IsSet("context") {
+		name = command.String("context")
+	}
+	if name == "" {
+		return nil
+	}
+	ctx := config.find(name)
+	if ctx == nil {
+		Fatalf("context %q not found in the config file %s", name, configPath(command))
+	}
+	return ctx
+}
+
+// contextTokenStore stores the token of the device flow in the context so that
+// the user does not need to authenticate for every command.
+type contextTokenStore struct {
+	command *cli.Command
+	name    string
+}
+
+func (s contextTokenStore) Load() (*oauth2.Token, error) {
+	config, err := loadConfig(s.command)
+	if err != nil {
+		return nil, err
+	}
+	ctx := config.find(s.name)
+	if ctx == nil || ctx.Token == nil {
+		return nil, nil
+	}
+	return ctx.Token, nil
+}
+
+func (s contextTokenStore) Store(token *oauth2.Token) error {
+	config, err := loadConfig(s.command)
+	if err != nil {
+		return err
+	}
+	ctx := config.find(s.name)
+	if ctx == nil {
+		return fmt.Errorf("context %q not found in the config file", s.name)
+	}
+	ctx.Token = token
+	return storeConfig(s.command, config)
+}
+
+var _ client.TokenStore = contextTokenStore{}
+
+func createContextCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "context",
+		Usage: "Commands relating to the contexts of the config file, which select the nexodus service to use",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the contexts",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return listContexts(command)
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "Set the current context",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					if command.Args().Len() != 1 {
+						return fmt.Errorf("a context name is required")
+					}
+					return useContext(command, command.Args().First())
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "Create or update a context",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "auth-method",
+						Usage: "How to authenticate: password or device-flow",
+					},
+					&cli.StringFlag{
+						Name:  "organization-id",
+						Usage: "The organization used by commands when the --organization-id flag is not set",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					if command.Args().Len() != 1 {
+						return fmt.Errorf("a context name is required")
+					}
+					return setContext(command, command.Args().First())
+				},
+			},
+			{
+				Name:      "delete",
+				Usage:     "Delete a context",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					if command.Args().Len() != 1 {
+						return fmt.Errorf("a context name is required")
+					}
+					return deleteContext(command, command.Args().First())
+				},
+			},
+		},
+	}
+}
+
+// contextListItem is a context as listed, without its credentials
+type contextListItem struct {
+	Current        bool   `json:"current"`
+	Name           string `json:"name"`
+	ServiceURL     string `json:"service-url,omitempty"`
+	AuthMethod     string `json:"auth-method"`
+	Username       string `json:"username,omitempty"`
+	OrganizationID string `json:"organization-id,omitempty"`
+}
+
+func contextTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "CURRENT", Formatter: func(item interface{}) string {
+		if item.(contextListItem).Current {
+			return "*"
+		}
+		return ""
+	}})
+	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
+	fields = append(fields, TableField{Header: "SERVICE URL", Field: "ServiceURL"})
+	fields = append(fields, TableField{Header: "AUTH METHOD", Field: "AuthMethod"})
+	fields = append(fields, TableField{Header: "USERNAME", Field: "Username"})
+	fields = append(fields, TableField{Header: "ORGANIZATION ID", Field: "OrganizationID"})
+	return fields
+}
+
+func listContexts(command *cli.Command) error {
+	config, err := loadConfig(command)
+	if err != nil {
+		return err
+	}
+	items := []contextListItem{}
+	for _, c := range config.Contexts {
+		authMethod := c.AuthMethod
+		if authMethod == "" {
+			authMethod = authMethodPassword
+		}
+		items = append(items, contextListItem{
+			Current:        c.Name == config.CurrentContext,
+			Name:           c.Name,
+			ServiceURL:     c.ServiceURL,
+			AuthMethod:     authMethod,
+			Username:       c.Username,
+			OrganizationID: c.OrganizationID,
+		})
+	}
+	show(command, contextTableFields(), items)
+	return nil
+}
+
+func useContext(command *cli.Command, name string) error {
+	config, err := loadConfig(command)
+	if err != nil {
+		return err
+	}
+	if config.find(name) == nil {
+		return fmt.Errorf("context %q not found in the config file %s", name, configPath(command))
+	}
+	config.CurrentContext = name
+	if err := storeConfig(command, config); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to context %q\n", name)
+	return nil
+}
+
+// setContext creates or updates a context with the global flags that are set, such as --service-url and --username
+func setContext(command *cli.Command, name string) error {
+	config, err := loadConfig(command)
+	if err != nil {
+		return err
+	}
+	ctx := config.find(name)
+	if ctx == nil {
+		config.Contexts = append(config.Contexts, nexctlContext{Name: name})
+		ctx = &config.Contexts[len(config.Contexts)-1]
+	}
+
+	if command.IsSet("service-url") {
+		ctx.ServiceURL = command.String("service-url")
+		// a token is only valid for the service that issued it
+		ctx.Token = nil
+	}
+	if command.IsSet("auth-method") {
+		switch command.String("auth-method") {
+		case authMethodPassword, authMethodDeviceFlow:
+			ctx.AuthMethod = command.String("auth-method")
+		default:
+			return fmt.Errorf("invalid value for --auth-method flag: %q, must be password or device-flow", command.String("auth-method"))
+		}
+	}
+	if command.IsSet("username") {
+		ctx.Username = command.String("username")
+	}
+	if command.IsSet("password") {
+		ctx.Password = command.String("password")
+	}
+	if command.IsSet("organization-id") {
+		organizationID, err := getUUID(command, "organization-id")
+		if err != nil {
+			return err
+		}
+		ctx.OrganizationID = organizationID
+	}
+	if command.IsSet("insecure-skip-tls-verify") {
+		ctx.InsecureSkipTLSVerify = command.Bool("insecure-skip-tls-verify")
+	}
+	if config.CurrentContext == "" {
+		config.CurrentContext = name
+	}
+	if err := storeConfig(command, config); err != nil {
+		return err
+	}
+	fmt.Printf("Context %q set\n", name)
+	return nil
+}
+
+func deleteContext(command *cli.Command, name string) error {
+	config, err := loadConfig(command)
+	if err != nil {
+		return err
+	}
+	found := false
+	contexts := config.Contexts[:0]
+	for _, c := range config.Contexts {
+		if c.Name == name {
+			found = true
+			continue
+		}
+		contexts = append(contexts, c)
+	}
+	if !found {
+		return fmt.Errorf("context %q not found in the config file %s", name, configPath(command))
+	}
+	config.Contexts = contexts
+	if config.CurrentContext == name {
+		config.CurrentContext = ""
+	}
+	if err := storeConfig(command, config); err != nil {
+		return err
+	}
+	fmt.Printf("Context %q deleted\n", name)
+	return nil
+}
+
+// getOrganizationID returns the value of the --organization-id flag, or the organization of the current context
+func getOrganizationID(command *cli.Command) (string, error) {
+	if command.IsSet("organization-id") {
+		return getUUID(command, "organization-id")
+	}
+	if ctx := currentContext(command); ctx != nil && ctx.OrganizationID != "" {
+		return ctx.OrganizationID, nil
+	}
+	return "", nil
+}
+
+// requireOrganizationID is getOrganizationID for the commands that require an organization
+func requireOrganizationID(command *cli.Command) (string, error) {
+	organizationID, err := getOrganizationID(command)
+	if err != nil {
+		return "", err
+	}
+	if organizationID == "" {
+		return "", fmt.Errorf("the --organization-id flag is required when the current context has no organization-id")
+	}
+	return organizationID, nil
+}
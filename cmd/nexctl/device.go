@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/nexodus-io/nexodus/internal/api/public"
 	"github.com/urfave/cli/v3"
 )
@@ -31,6 +34,20 @@ func createDeviceCommand() *cli.Command {
 						Usage:   "display the full set of device details",
 						Value:   false,
 					},
+					&cli.BoolFlag{
+						Name:  "show-latency",
+						Usage: "display the latency and loss the devices reported for the paths to their peers, see nexd --report-peer-latency",
+						Value: false,
+					},
+					&cli.BoolFlag{
+						Name:  "show-traffic",
+						Usage: "display the traffic the devices reported exchanging with their peers, see nexd --report-peer-traffic",
+						Value: false,
+					},
+					&cli.StringSliceFlag{
+						Name:  "label",
+						Usage: "only list the devices with the label, in the form key=value, may be repeated",
+					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
 					vpcId, err := getUUID(command, "vpc-id")
@@ -45,14 +62,38 @@ func createDeviceCommand() *cli.Command {
 			},
 			{
 				Name:  "delete",
-				Usage: "Delete a device",
+				Usage: "Delete a device, or all the devices of an organization",
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:     "device-id",
-						Required: true,
+						Required: false,
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "delete all the devices of the organization",
+					},
+					&cli.StringFlag{
+						Name:     "organization-id",
+						Usage:    "the organization to delete all the devices of with --all",
+						Required: false,
 					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
+					if command.Bool("all") {
+						if command.IsSet("device-id") {
+							return fmt.Errorf("the --device-id flag can not be used with --all")
+						}
+						organizationID, err := requireOrganizationID(command)
+						if err != nil {
+							return err
+						}
+						return batchDeleteDevices(ctx, command, public.ModelsBatchDeleteDevices{
+							OrganizationId: organizationID,
+						})
+					}
+					if !command.IsSet("device-id") {
+						return fmt.Errorf("the --device-id flag is required without --all")
+					}
 					devID, err := getUUID(command, "device-id")
 					if err != nil {
 						return err
@@ -60,6 +101,186 @@ func createDeviceCommand() *cli.Command {
 					return deleteDevice(ctx, command, devID)
 				},
 			},
+			{
+				Name:  "label",
+				Usage: "Set labels on several devices, or all the devices of an organization",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:     "device-id",
+						Usage:    "a device to label, may be repeated",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "organization-id",
+						Usage:    "label all the devices of the organization",
+						Required: false,
+					},
+					&cli.StringSliceFlag{
+						Name:     "label",
+						Usage:    "a label in the form key=value, key= removes the label, may be repeated",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					labels, err := parseLabels(command.StringSlice("label"))
+					if err != nil {
+						return err
+					}
+					request := public.ModelsBatchUpdateDeviceLabels{
+						Labels: labels,
+					}
+					if command.IsSet("device-id") {
+						request.DeviceIds = command.StringSlice("device-id")
+					} else {
+						request.OrganizationId, err = requireOrganizationID(command)
+						if err != nil {
+							return err
+						}
+					}
+					return batchUpdateDeviceLabels(ctx, command, request)
+				},
+			},
+			{
+				Name:  "approve",
+				Usage: "Approve a device pending approval, it then receives its peers and is a peer of the other devices",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "device-id",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					devID, err := getUUID(command, "device-id")
+					if err != nil {
+						return err
+					}
+					return approveDevice(ctx, command, devID)
+				},
+			},
+			{
+				Name:  "rotate-key",
+				Usage: "Replace the public key of a device, it keeps its id and tunnel addresses",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "device-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "public-key",
+						Usage:    "the new wireguard public key of the device",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					devID, err := getUUID(command, "device-id")
+					if err != nil {
+						return err
+					}
+					return rotateDeviceKey(ctx, command, devID, command.String("public-key"))
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create an unmanaged device, for a device that can not run nexd, and print its wg-quick config",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "vpc-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "hostname",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					vpcId, err := getUUID(command, "vpc-id")
+					if err != nil {
+						return err
+					}
+					return createUnmanagedDevice(ctx, command, vpcId, command.String("hostname"))
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Import the peers of an existing WireGuard network as unmanaged devices",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "vpc-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "config",
+						Usage: "the wg-quick config `file` to import all the peers of",
+					},
+					&cli.StringFlag{
+						Name:  "public-key",
+						Usage: "the public key of the peer to import",
+					},
+					&cli.StringFlag{
+						Name:  "hostname",
+						Usage: "the hostname of the peer to import",
+					},
+					&cli.StringFlag{
+						Name:  "endpoint",
+						Usage: "the endpoint of the peer to import, an address with a port",
+					},
+					&cli.StringSliceFlag{
+						Name:  "allowed-ip",
+						Usage: "an allowed ip of the peer to import, its address in the vpc is kept as its tunnel ip and the others are advertised cidrs",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					vpcId, err := getUUID(command, "vpc-id")
+					if err != nil {
+						return err
+					}
+					var peers []wireguardPeer
+					switch {
+					case command.IsSet("config") && command.IsSet("public-key"):
+						return fmt.Errorf("--config and --public-key are mutually exclusive")
+					case command.IsSet("config"):
+						peers, err = readWireguardPeers(command.String("config"))
+						if err != nil {
+							return err
+						}
+					case command.IsSet("public-key"):
+						peers = append(peers, wireguardPeer{
+							Name:       command.String("hostname"),
+							PublicKey:  command.String("public-key"),
+							Endpoint:   command.String("endpoint"),
+							AllowedIPs: command.StringSlice("allowed-ip"),
+						})
+					default:
+						return fmt.Errorf("--config or --public-key is required")
+					}
+					return importDevices(ctx, command, vpcId, peers)
+				},
+			},
+			{
+				Name:      "export-config",
+				Usage:     "Print the wg-quick config of an unmanaged device",
+				ArgsUsage: "<device-id>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "private-key-file",
+						Usage: "the `file` holding the private key of the device, to include it in the config",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					devID := command.Args().First()
+					if _, err := uuid.Parse(devID); err != nil {
+						return fmt.Errorf("invalid device id %q: %w", devID, err)
+					}
+					privateKey := ""
+					if path := command.String("private-key-file"); path != "" {
+						var err error
+						if privateKey, err = readPrivateKeyFile(path); err != nil {
+							return err
+						}
+					}
+					return exportDeviceConfig(ctx, command, devID, privateKey)
+				},
+			},
 			{
 				Name:  "update",
 				Usage: "Update a device",
@@ -76,6 +297,21 @@ func createDeviceCommand() *cli.Command {
 						Name:     "hostname",
 						Required: false,
 					},
+					&cli.StringFlag{
+						Name:     "dns-name",
+						Usage:    "reserve a name for the device in the overlay DNS of the organization",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "display-name",
+						Usage:    "rename the device, its dns name is derived from the new name, an empty name reverts to the hostname",
+						Required: false,
+					},
+					&cli.StringSliceFlag{
+						Name:     "label",
+						Usage:    "set a label on the device in the form key=value, key= removes the label, may be repeated",
+						Required: false,
+					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
 
@@ -89,6 +325,19 @@ func createDeviceCommand() *cli.Command {
 						value := command.String("hostname")
 						update.Hostname = value
 					}
+					if command.IsSet("dns-name") {
+						update.DnsName = command.String("dns-name")
+					}
+					if command.IsSet("display-name") {
+						displayName := command.String("display-name")
+						update.DisplayName = &displayName
+					}
+					if command.IsSet("label") {
+						update.Labels, err = parseLabels(command.StringSlice("label"))
+						if err != nil {
+							return err
+						}
+					}
 					if command.IsSet("security-group-id") {
 						value, err := getUUID(command, "security-group-id")
 						if err != nil {
@@ -99,6 +348,56 @@ func createDeviceCommand() *cli.Command {
 					return updateDevice(ctx, command, devID, update)
 				},
 			},
+			{
+				Name:  "attach-security-group",
+				Usage: "Attach an additional security group to a device",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "device-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "security-group-id",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					devID, err := getUUID(command, "device-id")
+					if err != nil {
+						return err
+					}
+					sgID, err := getUUID(command, "security-group-id")
+					if err != nil {
+						return err
+					}
+					return attachDeviceSecurityGroup(ctx, command, devID, sgID)
+				},
+			},
+			{
+				Name:  "detach-security-group",
+				Usage: "Detach an additional security group from a device",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "device-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "security-group-id",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					devID, err := getUUID(command, "device-id")
+					if err != nil {
+						return err
+					}
+					sgID, err := getUUID(command, "security-group-id")
+					if err != nil {
+						return err
+					}
+					return detachDeviceSecurityGroup(ctx, command, devID, sgID)
+				},
+			},
 			{
 				Name:     "metadata",
 				Usage:    "Commands relating to device metadata",
@@ -109,10 +408,13 @@ func createDeviceCommand() *cli.Command {
 }
 func deviceTableFields(command *cli.Command) []TableField {
 	var fields []TableField
-	full := command.Bool("full")
+	// all the columns can be selected with --columns
+	full := command.Bool("full") || command.IsSet("columns")
 
 	fields = append(fields, TableField{Header: "DEVICE ID", Field: "Id"})
 	fields = append(fields, TableField{Header: "HOSTNAME", Field: "Hostname"})
+	fields = append(fields, TableField{Header: "DISPLAY NAME", Field: "DisplayName"})
+	fields = append(fields, TableField{Header: "DNS NAME", Field: "DnsName"})
 	fields = append(fields, TableField{Header: "TUNNEL IPS",
 		Formatter: func(item interface{}) string {
 			dev := item.(public.ModelsDevice)
@@ -129,6 +431,8 @@ func deviceTableFields(command *cli.Command) []TableField {
 
 	fields = append(fields, TableField{Header: "VPC ID", Field: "VpcId"})
 	fields = append(fields, TableField{Header: "RELAY", Field: "Relay"})
+	fields = append(fields, TableField{Header: "ONLINE", Field: "Online"})
+	fields = append(fields, TableField{Header: "PENDING", Field: "Pending"})
 	if full {
 		fields = append(fields, TableField{Header: "PUBLIC KEY", Field: "PublicKey"})
 		fields = append(fields, TableField{Header: "LOCAL IP", Formatter: func(item interface{}) string {
@@ -148,7 +452,7 @@ func deviceTableFields(command *cli.Command) []TableField {
 			dev := item.(public.ModelsDevice)
 			var reflexiveIp4 []string
 			for _, endpoint := range dev.Endpoints {
-				if endpoint.Source != "local" {
+				if endpoint.Source != "local" && endpoint.Source != "host" {
 					reflexiveIp4 = append(reflexiveIp4, endpoint.Address)
 				}
 			}
@@ -158,37 +462,94 @@ func deviceTableFields(command *cli.Command) []TableField {
 			dev := item.(public.ModelsDevice)
 			var localIp4 []string
 			for _, endpoint := range dev.Endpoints {
-				if endpoint.Source == "local" {
+				if endpoint.Source == "local" || endpoint.Source == "host" {
 					localIp4 = append(localIp4, endpoint.Address)
 				}
 			}
 			return strings.Join(localIp4, ", ")
 		}})
+		fields = append(fields, TableField{Header: "LISTEN PORT", Field: "ListenPort"})
+		fields = append(fields, TableField{Header: "IPV6 ENDPOINT", Field: "EndpointIpv6"})
+		fields = append(fields, TableField{Header: "NAT TYPE", Formatter: func(item interface{}) string {
+			dev := item.(public.ModelsDevice)
+			if dev.NatType == "" {
+				return "unknown"
+			}
+			return dev.NatType
+		}})
+		fields = append(fields, TableField{Header: "NAT HAIRPIN", Field: "NatHairpin"})
 		fields = append(fields, TableField{Header: "OS", Field: "Os"})
 		fields = append(fields, TableField{Header: "SECURITY GROUP ID", Field: "SecurityGroupId"})
-		fields = append(fields, TableField{Header: "ONLINE", Field: "Online"})
+		fields = append(fields, TableField{Header: "ATTACHED SECURITY GROUP IDS", Formatter: func(item interface{}) string {
+			dev := item.(public.ModelsDevice)
+			return strings.Join(dev.SecurityGroupIds, ", ")
+		}})
 		fields = append(fields, TableField{Header: "ONLINE SINCE", Formatter: func(item interface{}) string {
 			d := item.(public.ModelsDevice)
 			if !d.Online {
 				return ""
 			}
-			parsedTime, err := time.Parse(time.RFC3339, d.OnlineAt)
-			if err != nil {
-				return d.OnlineAt
+			return formatLocalTime(d.OnlineAt)
+		}})
+		fields = append(fields, TableField{Header: "LAST SEEN", Formatter: func(item interface{}) string {
+			d := item.(public.ModelsDevice)
+			if d.Online {
+				return "now"
+			}
+			return formatLocalTime(d.LastSeen)
+		}})
+		fields = append(fields, TableField{Header: "LABELS", Formatter: func(item interface{}) string {
+			d := item.(public.ModelsDevice)
+			labels := make([]string, 0, len(d.Labels))
+			for key, value := range d.Labels {
+				labels = append(labels, key+"="+value)
 			}
-			localTime := parsedTime.Local()
-			return localTime.Format(LocalTimeFormat)
+			sort.Strings(labels)
+			return strings.Join(labels, ", ")
 		}})
+		fields = append(fields, TableField{Header: "EXPIRED", Formatter: func(item interface{}) string {
+			d := item.(public.ModelsDevice)
+			return formatLocalTime(d.ExpiredAt)
+		}})
+		fields = append(fields, TableField{Header: "UNMANAGED", Field: "Unmanaged"})
 	}
 	return fields
 }
 
+// deviceName returns the display name of the device, or its hostname when it was not given one
+func deviceName(device public.ModelsDevice) string {
+	if device.DisplayName != "" {
+		return device.DisplayName
+	}
+	return device.Hostname
+}
+
+// formatLocalTime formats an RFC3339 timestamp in the local time zone
+func formatLocalTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return parsedTime.Local().Format(LocalTimeFormat)
+}
+
 func listAllDevices(ctx context.Context, command *cli.Command) error {
 	c := createClient(ctx, command)
 	res := apiResponse(c.DevicesApi.
 		ListDevices(ctx).
+		Label(command.StringSlice("label")).
 		Execute())
-	show(command, deviceTableFields(command), res)
+	fields := deviceTableFields(command)
+	if command.Bool("show-latency") {
+		fields = append(fields, deviceLatencyTableFields(listPeerLatency(ctx, c, res))...)
+	}
+	if command.Bool("show-traffic") {
+		fields = append(fields, deviceTrafficTableFields(listPeerTraffic(ctx, c, res))...)
+	}
+	show(command, fields, res)
 	return nil
 }
 
@@ -196,8 +557,16 @@ func listVpcDevices(ctx context.Context, command *cli.Command, vpcId string) err
 	c := createClient(ctx, command)
 	response := apiResponse(c.VPCApi.
 		ListDevicesInVPC(ctx, vpcId).
+		Label(command.StringSlice("label")).
 		Execute())
-	show(command, deviceTableFields(command), response)
+	fields := deviceTableFields(command)
+	if command.Bool("show-latency") {
+		fields = append(fields, deviceLatencyTableFields(listPeerLatency(ctx, c, response))...)
+	}
+	if command.Bool("show-traffic") {
+		fields = append(fields, deviceTrafficTableFields(listPeerTraffic(ctx, c, response))...)
+	}
+	show(command, fields, response)
 	return nil
 }
 
@@ -211,6 +580,77 @@ func deleteDevice(ctx context.Context, command *cli.Command, devID string) error
 	return nil
 }
 
+func batchDeleteDevices(ctx context.Context, command *cli.Command, request public.ModelsBatchDeleteDevices) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		BatchDeleteDevices(ctx).
+		Request(request).
+		Execute())
+	showBatchDeviceResult(command, res, "deleted")
+	return nil
+}
+
+func batchUpdateDeviceLabels(ctx context.Context, command *cli.Command, request public.ModelsBatchUpdateDeviceLabels) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		BatchUpdateDeviceLabels(ctx).
+		Request(request).
+		Execute())
+	showBatchDeviceResult(command, res, "labeled")
+	return nil
+}
+
+// showBatchDeviceResult lists the devices a batch operation failed for in the column outputs, the other outputs
+// encode the whole result
+func showBatchDeviceResult(command *cli.Command, res *public.ModelsBatchDeviceResult, action string) {
+	encodeOut := command.String("output")
+	if encodeOut != encodeColumn && encodeOut != encodeNoHeader {
+		show(command, nil, res)
+		return
+	}
+	if len(res.Failed) > 0 {
+		var fields []TableField
+		fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
+		fields = append(fields, TableField{Header: "ERROR", Field: "Error"})
+		show(command, fields, res.Failed)
+	}
+	showSuccessfully(command, fmt.Sprintf("%s %d devices", action, len(res.Succeeded)))
+}
+
+// parseLabels parses labels in the form key=value
+func parseLabels(values []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, label := range values {
+		key, value, found := strings.Cut(label, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid label %q, labels are in the form key=value", label)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+func approveDevice(ctx context.Context, command *cli.Command, devID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		ApproveDevice(ctx, devID).
+		Execute())
+	show(command, deviceTableFields(command), res)
+	showSuccessfully(command, "approved")
+	return nil
+}
+
+func rotateDeviceKey(ctx context.Context, command *cli.Command, devID, publicKey string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		RotateDeviceKey(ctx, devID).
+		Key(public.ModelsRotateDeviceKey{PublicKey: publicKey}).
+		Execute())
+	show(command, deviceTableFields(command), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
 func updateDevice(ctx context.Context, command *cli.Command, devID string, update public.ModelsUpdateDevice) error {
 	c := createClient(ctx, command)
 	res := apiResponse(c.DevicesApi.
@@ -221,3 +661,23 @@ func updateDevice(ctx context.Context, command *cli.Command, devID string, updat
 	showSuccessfully(command, "updated")
 	return nil
 }
+
+func attachDeviceSecurityGroup(ctx context.Context, command *cli.Command, devID, sgID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		AttachDeviceSecurityGroup(ctx, devID, sgID).
+		Execute())
+	show(command, deviceTableFields(command), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
+func detachDeviceSecurityGroup(ctx context.Context, command *cli.Command, devID, sgID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.DevicesApi.
+		DetachDeviceSecurityGroup(ctx, devID, sgID).
+		Execute())
+	show(command, deviceTableFields(command), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/client"
+)
+
+// peerLatencyMetadataKey is the metadata key under which nexd --report-peer-latency reports the paths to the peers
+const peerLatencyMetadataKey = "peer-latency"
+
+// peerLatencySummary aggregates the latency and loss a device reported for the paths to its peers
+type peerLatencySummary struct {
+	Peers        int
+	Degraded     int
+	LatencyAvgMs float64
+	LatencyMaxMs float64
+	Loss         float64
+}
+
+// listPeerLatency returns the peer latency reports of the devices, keyed by device id
+func listPeerLatency(ctx context.Context, c *client.APIClient, devices []public.ModelsDevice) map[string]peerLatencySummary {
+	vpcs := map[string]bool{}
+	for _, d := range devices {
+		vpcs[d.VpcId] = true
+	}
+	summaries := map[string]peerLatencySummary{}
+	for vpcId := range vpcs {
+		metadata := apiResponse(c.VPCApi.
+			ListMetadataInVPC(ctx, vpcId, []string{peerLatencyMetadataKey}).
+			Execute())
+		for _, m := range metadata {
+			if m.Key != peerLatencyMetadataKey {
+				continue
+			}
+			summaries[m.DeviceId] = summarizePeerLatency(m.Value)
+		}
+	}
+	return summaries
+}
+
+// summarizePeerLatency averages the latency and loss over the peers of a peer latency report
+func summarizePeerLatency(report map[string]interface{}) peerLatencySummary {
+	summary := peerLatencySummary{}
+	var totalLatency, totalLoss float64
+	for _, value := range report {
+		peer, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		summary.Peers++
+		if v, ok := peer["reachability"].(string); ok && v != "reachable" {
+			summary.Degraded++
+		}
+		if v, ok := peer["latency_avg_ms"].(float64); ok {
+			totalLatency += v
+		}
+		if v, ok := peer["latency_max_ms"].(float64); ok && v > summary.LatencyMaxMs {
+			summary.LatencyMaxMs = v
+		}
+		if v, ok := peer["loss"].(float64); ok {
+			totalLoss += v
+		}
+	}
+	if summary.Peers > 0 {
+		summary.LatencyAvgMs = totalLatency / float64(summary.Peers)
+		summary.Loss = totalLoss / float64(summary.Peers)
+	}
+	return summary
+}
+
+func deviceLatencyTableFields(summaries map[string]peerLatencySummary) []TableField {
+	var fields []TableField
+	format := func(item interface{}, value func(peerLatencySummary) string) string {
+		summary, ok := summaries[item.(public.ModelsDevice).Id]
+		if !ok || summary.Peers == 0 {
+			return "-"
+		}
+		return value(summary)
+	}
+	fields = append(fields, TableField{Header: "AVG PEER LATENCY", Formatter: func(item interface{}) string {
+		return format(item, func(s peerLatencySummary) string { return fmt.Sprintf("%.2fms", s.LatencyAvgMs) })
+	}})
+	fields = append(fields, TableField{Header: "MAX PEER LATENCY", Formatter: func(item interface{}) string {
+		return format(item, func(s peerLatencySummary) string { return fmt.Sprintf("%.2fms", s.LatencyMaxMs) })
+	}})
+	fields = append(fields, TableField{Header: "PEER LOSS", Formatter: func(item interface{}) string {
+		return format(item, func(s peerLatencySummary) string { return fmt.Sprintf("%.0f%%", s.Loss*100) })
+	}})
+	fields = append(fields, TableField{Header: "DEGRADED PEERS", Formatter: func(item interface{}) string {
+		return format(item, func(s peerLatencySummary) string { return fmt.Sprintf("%d/%d", s.Degraded, s.Peers) })
+	}})
+	return fields
+}
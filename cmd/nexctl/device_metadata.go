@@ -153,7 +153,7 @@ func init() {
 
 func metadataTableFields(command *cli.Command, includeDeviceId bool) []TableField {
 	var fields = []TableField{}
-	full := command.Bool("full")
+	full := command.Bool("full") || command.IsSet("columns")
 	if includeDeviceId || full {
 		fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
 	}
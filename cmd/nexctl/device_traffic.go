@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/client"
+)
+
+// peerTrafficMetadataKey is the metadata key under which nexd --report-peer-traffic reports the traffic with the peers
+const peerTrafficMetadataKey = "peer-traffic"
+
+// peerTrafficSummary totals the traffic a device reported exchanging with its peers over its last report
+type peerTrafficSummary struct {
+	Peers   int
+	RxBytes float64
+	TxBytes float64
+	RxRate  float64 // bytes per second
+	TxRate  float64 // bytes per second
+	// the hostname of the peer the device exchanged the most traffic with
+	TopPeer string
+}
+
+// listPeerTraffic returns the peer traffic reports of the devices, keyed by device id
+func listPeerTraffic(ctx context.Context, c *client.APIClient, devices []public.ModelsDevice) map[string]peerTrafficSummary {
+	vpcs := map[string]bool{}
+	for _, d := range devices {
+		vpcs[d.VpcId] = true
+	}
+	summaries := map[string]peerTrafficSummary{}
+	for vpcId := range vpcs {
+		metadata := apiResponse(c.VPCApi.
+			ListMetadataInVPC(ctx, vpcId, []string{peerTrafficMetadataKey}).
+			Execute())
+		for _, m := range metadata {
+			if m.Key != peerTrafficMetadataKey {
+				continue
+			}
+			summaries[m.DeviceId] = summarizePeerTraffic(m.Value)
+		}
+	}
+	return summaries
+}
+
+// summarizePeerTraffic totals the traffic over the peers of a peer traffic report
+func summarizePeerTraffic(report map[string]interface{}) peerTrafficSummary {
+	summary := peerTrafficSummary{}
+	var topBytes float64
+	for _, value := range report {
+		peer, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		summary.Peers++
+		var peerBytes float64
+		if v, ok := peer["rx_bytes"].(float64); ok {
+			summary.RxBytes += v
+			peerBytes += v
+		}
+		if v, ok := peer["tx_bytes"].(float64); ok {
+			summary.TxBytes += v
+			peerBytes += v
+		}
+		if v, ok := peer["rx_rate"].(float64); ok {
+			summary.RxRate += v
+		}
+		if v, ok := peer["tx_rate"].(float64); ok {
+			summary.TxRate += v
+		}
+		if hostname, ok := peer["hostname"].(string); ok && peerBytes > topBytes {
+			topBytes = peerBytes
+			summary.TopPeer = hostname
+		}
+	}
+	return summary
+}
+
+// formatBytes formats a number of bytes with a binary unit
+func formatBytes(bytes float64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", bytes/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", bytes/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", bytes/(1<<10))
+	default:
+		return fmt.Sprintf("%.0f B", bytes)
+	}
+}
+
+func deviceTrafficTableFields(summaries map[string]peerTrafficSummary) []TableField {
+	var fields []TableField
+	format := func(item interface{}, value func(peerTrafficSummary) string) string {
+		summary, ok := summaries[item.(public.ModelsDevice).Id]
+		if !ok || summary.Peers == 0 {
+			return "-"
+		}
+		return value(summary)
+	}
+	fields = append(fields, TableField{Header: "PEER RX", Formatter: func(item interface{}) string {
+		return format(item, func(s peerTrafficSummary) string { return formatBytes(s.RxBytes) })
+	}})
+	fields = append(fields, TableField{Header: "PEER TX", Formatter: func(item interface{}) string {
+		return format(item, func(s peerTrafficSummary) string { return formatBytes(s.TxBytes) })
+	}})
+	fields = append(fields, TableField{Header: "PEER RX RATE", Formatter: func(item interface{}) string {
+		return format(item, func(s peerTrafficSummary) string { return formatRate(s.RxRate) })
+	}})
+	fields = append(fields, TableField{Header: "PEER TX RATE", Formatter: func(item interface{}) string {
+		return format(item, func(s peerTrafficSummary) string { return formatRate(s.TxRate) })
+	}})
+	fields = append(fields, TableField{Header: "TOP PEER", Formatter: func(item interface{}) string {
+		return format(item, func(s peerTrafficSummary) string {
+			if s.TopPeer == "" {
+				return "-"
+			}
+			return s.TopPeer
+		})
+	}})
+	return fields
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/client"
+	"github.com/nexodus-io/nexodus/internal/wgquick"
+	"github.com/urfave/cli/v3"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// the source of the endpoints of the imported peers, configured by the user instead of discovered by nexd
+const endpointSourceStatic = "static"
+
+// the placeholder of the private key in an exported config, the controller never has the private key of a device
+const privateKeyPlaceholder = "<private key of the device>"
+
+// createUnmanagedDevice registers a device that does not run nexd and prints its wg-quick config. The key pair is
+// generated locally, so the private key is only ever part of the printed config. Like a mobile device, the device
+// is reached through a WireGuard relay when the vpc has one.
+func createUnmanagedDevice(ctx context.Context, command *cli.Command, vpcId, hostname string) error {
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate private key: %w", err)
+	}
+	c := createClient(ctx, command)
+	devices := apiResponse(c.VPCApi.
+		ListDevicesInVPC(ctx, vpcId).
+		Execute())
+	metadata := apiResponse(c.VPCApi.
+		ListMetadataInVPC(ctx, vpcId, []string{"relay"}).
+		Execute())
+	_, relayAvailable := wgquick.SelectRelay(devices, metadata, "")
+
+	device := apiResponse(c.DevicesApi.
+		CreateDevice(ctx).
+		Device(public.ModelsAddDevice{
+			VpcId:        vpcId,
+			PublicKey:    key.PublicKey().String(),
+			Hostname:     hostname,
+			SymmetricNat: relayAvailable,
+			Unmanaged:    true,
+		}).
+		Execute())
+	return showDeviceConfig(ctx, c, device.Id, key.String())
+}
+
+func exportDeviceConfig(ctx context.Context, command *cli.Command, devID, privateKey string) error {
+	c := createClient(ctx, command)
+	return showDeviceConfig(ctx, c, devID, privateKey)
+}
+
+func showDeviceConfig(ctx context.Context, c *client.APIClient, devID, privateKey string) error {
+	self := apiResponse(c.DevicesApi.
+		GetDevice(ctx, devID).
+		Execute())
+	if !self.Unmanaged {
+		return fmt.Errorf("the device %s is managed by nexd, only the config of an unmanaged device can be exported", devID)
+	}
+	vpc := apiResponse(c.VPCApi.
+		GetVPC(ctx, self.VpcId).
+		Execute())
+	devices := apiResponse(c.VPCApi.
+		ListDevicesInVPC(ctx, self.VpcId).
+		Execute())
+	metadata := apiResponse(c.VPCApi.
+		ListMetadataInVPC(ctx, self.VpcId, []string{"relay"}).
+		Execute())
+
+	relay, relayAvailable := wgquick.SelectRelay(devices, metadata, self.RelayId)
+	if self.SymmetricNat && !relayAvailable {
+		return fmt.Errorf("the vpc has no WireGuard relay to reach the peers of the device through")
+	}
+	if !self.SymmetricNat {
+		relay = public.ModelsDevice{}
+	}
+	if privateKey == "" {
+		privateKey = privateKeyPlaceholder
+	}
+	config, err := wgquick.BuildConfig(privateKey, *vpc, *self, devices, relay)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("# Nexodus device %s\n%s", self.Id, config)
+	return nil
+}
+
+func readPrivateKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the private key: %w", err)
+	}
+	key, err := wgtypes.ParseKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid private key in %s: %w", path, err)
+	}
+	return key.String(), nil
+}
+
+// wireguardPeer is a peer of an existing WireGuard network to import as an unmanaged device
+type wireguardPeer struct {
+	Name       string
+	PublicKey  string
+	Endpoint   string
+	AllowedIPs []string
+}
+
+// importedPeer is the result of the import of a peer
+type importedPeer struct {
+	PublicKey string `json:"public_key"`
+	DeviceId  string `json:"device_id"`
+	TunnelIp  string `json:"tunnel_ip"`
+	Error     string `json:"error"`
+}
+
+// importDevices registers the peers of an existing WireGuard network as unmanaged devices of the vpc. The host
+// address of a peer in the vpc cidr is kept as its tunnel address, its other allowed IPs are advertised as the
+// cidrs routed to it. The peers registered already are skipped, so a network can be imported incrementally.
+func importDevices(ctx context.Context, command *cli.Command, vpcId string, peers []wireguardPeer) error {
+	c := createClient(ctx, command)
+	vpc := apiResponse(c.VPCApi.
+		GetVPC(ctx, vpcId).
+		Execute())
+	vpcPrefix, err := netip.ParsePrefix(vpc.Ipv4Cidr)
+	if err != nil {
+		return fmt.Errorf("invalid vpc cidr %q: %w", vpc.Ipv4Cidr, err)
+	}
+
+	var results []importedPeer
+	for _, peer := range peers {
+		result := importedPeer{PublicKey: peer.PublicKey}
+		request, err := importRequest(vpcId, vpcPrefix, peer)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		device, _, err := c.DevicesApi.CreateDevice(ctx).Device(request).Execute()
+		if err != nil {
+			var apiError *public.GenericOpenAPIError
+			if errors.As(err, &apiError) {
+				switch model := apiError.Model().(type) {
+				case public.ModelsConflictsError:
+					result.DeviceId = model.Id
+					result.Error = "already registered"
+				case public.ModelsBaseError:
+					result.Error = model.Error
+				case public.ModelsValidationError:
+					result.Error = fmt.Sprintf("%s %s: %s", model.Error, model.Field, model.Reason)
+				default:
+					result.Error = err.Error()
+				}
+			} else {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+			continue
+		}
+		result.DeviceId = device.Id
+		if len(device.Ipv4TunnelIps) > 0 {
+			result.TunnelIp = device.Ipv4TunnelIps[0].Address
+		}
+		results = append(results, result)
+	}
+
+	var fields []TableField
+	fields = append(fields, TableField{Header: "PUBLIC KEY", Field: "PublicKey"})
+	fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
+	fields = append(fields, TableField{Header: "TUNNEL IP", Field: "TunnelIp"})
+	fields = append(fields, TableField{Header: "ERROR", Field: "Error"})
+	show(command, fields, results)
+	return nil
+}
+
+// importRequest returns the registration of a peer as an unmanaged device of the vpc
+func importRequest(vpcId string, vpcPrefix netip.Prefix, peer wireguardPeer) (public.ModelsAddDevice, error) {
+	if _, err := wgtypes.ParseKey(peer.PublicKey); err != nil {
+		return public.ModelsAddDevice{}, fmt.Errorf("invalid public key: %w", err)
+	}
+	request := public.ModelsAddDevice{
+		VpcId:          vpcId,
+		PublicKey:      peer.PublicKey,
+		Hostname:       peer.Name,
+		Unmanaged:      true,
+		DisableIpv6:    true,
+		AdvertiseCidrs: []string{},
+	}
+	if request.Hostname == "" {
+		request.Hostname = "imported-" + strings.Map(func(r rune) rune {
+			if r == '+' || r == '/' {
+				return -1
+			}
+			return r
+		}, peer.PublicKey[:8])
+	}
+	if peer.Endpoint != "" {
+		if _, err := netip.ParseAddrPort(peer.Endpoint); err != nil {
+			return public.ModelsAddDevice{}, fmt.Errorf("invalid endpoint %q, endpoints are addresses with a port: %w", peer.Endpoint, err)
+		}
+		request.Endpoints = []public.ModelsEndpoint{{Source: endpointSourceStatic, Address: peer.Endpoint}}
+	}
+	for _, allowedIP := range peer.AllowedIPs {
+		prefix, err := netip.ParsePrefix(allowedIP)
+		if err != nil {
+			return public.ModelsAddDevice{}, fmt.Errorf("invalid allowed ip %q: %w", allowedIP, err)
+		}
+		if prefix.IsSingleIP() && prefix.Addr().Is4() && vpcPrefix.Contains(prefix.Addr()) && len(request.Ipv4TunnelIps) == 0 {
+			request.Ipv4TunnelIps = []public.ModelsTunnelIP{{Address: prefix.Addr().String()}}
+			continue
+		}
+		request.AdvertiseCidrs = append(request.AdvertiseCidrs, prefix.Masked().String())
+	}
+	return request, nil
+}
+
+// readWireguardPeers returns the peers of a WireGuard config in the wg-quick format. The comment preceding the
+// [Peer] section of a peer, if any, is the name of the peer.
+func readWireguardPeers(path string) ([]wireguardPeer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the wireguard config: %w", err)
+	}
+	defer file.Close()
+
+	var peers []wireguardPeer
+	var peer *wireguardPeer
+	comment := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			comment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		case strings.HasPrefix(line, "["):
+			peer = nil
+			if strings.EqualFold(line, "[Peer]") {
+				peers = append(peers, wireguardPeer{Name: comment})
+				peer = &peers[len(peers)-1]
+			}
+			comment = ""
+		case peer != nil:
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid line %q in the wireguard config", line)
+			}
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "publickey":
+				peer.PublicKey = value
+			case "endpoint":
+				peer.Endpoint = value
+			case "allowedips":
+				for _, allowedIP := range strings.Split(value, ",") {
+					if allowedIP = strings.TrimSpace(allowedIP); allowedIP != "" {
+						peer.AllowedIPs = append(peer.AllowedIPs, allowedIP)
+					}
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the wireguard config: %w", err)
+	}
+	return peers, nil
+}
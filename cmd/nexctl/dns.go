@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+type dnsName struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+func dnsTableFields(command *cli.Command) []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
+	fields = append(fields, TableField{Header: "ADDRESSES", Formatter: func(item interface{}) string {
+		return strings.Join(item.(dnsName).Addresses, ", ")
+	}})
+	return fields
+}
+
+func listDnsNames(ctx context.Context, command *cli.Command) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("DnsList", "")
+	if err != nil {
+		return fmt.Errorf("Failed to list dns names: %w\n", err)
+	}
+
+	var names map[string][]string
+	if err := json.Unmarshal([]byte(result), &names); err != nil {
+		return fmt.Errorf("Failed to unmarshal dns names: %w\n", err)
+	}
+
+	var results []dnsName
+	for name, addrs := range names {
+		results = append(results, dnsName{Name: name, Addresses: addrs})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	show(command, dnsTableFields(command), results)
+	return nil
+}
+
+func lookupDnsName(ctx context.Context, command *cli.Command, name string) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("DnsLookup", name)
+	if err != nil {
+		return fmt.Errorf("Failed to lookup %s: %w\n", name, err)
+	}
+
+	var addrs []string
+	if err := json.Unmarshal([]byte(result), &addrs); err != nil {
+		return fmt.Errorf("Failed to unmarshal dns lookup results: %w\n", err)
+	}
+
+	show(command, dnsTableFields(command), []dnsName{{Name: name, Addresses: addrs}})
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+var organizationDNSRecordSubcommands []*cli.Command
+
+func init() {
+	organizationDNSRecordSubcommands = []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List the records of the overlay DNS zone of an organization",
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				return listDNSRecords(ctx, command, organizationID)
+			},
+		},
+		{
+			Name:  "create",
+			Usage: "Add an A, AAAA or CNAME record to the overlay DNS zone of an organization",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Usage:    "the name of the record in the zone of the organization, e.g. www or db.prod",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "type",
+					Usage: "A, AAAA or CNAME",
+					Value: "A",
+				},
+				&cli.StringFlag{
+					Name:     "value",
+					Usage:    "the address of an A or AAAA record, the target name of a CNAME record",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "description",
+					Required: false,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				return createDNSRecord(ctx, command, organizationID, public.ModelsAddDNSRecord{
+					Name:        command.String("name"),
+					Type:        command.String("type"),
+					Value:       command.String("value"),
+					Description: command.String("description"),
+				})
+			},
+		},
+		{
+			Name:  "update",
+			Usage: "Update the value or the description of a record",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "record-id",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name: "value",
+				},
+				&cli.StringFlag{
+					Name: "description",
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				recordID, err := getUUID(command, "record-id")
+				if err != nil {
+					return err
+				}
+				return updateDNSRecord(ctx, command, organizationID, recordID, public.ModelsUpdateDNSRecord{
+					Value:       command.String("value"),
+					Description: command.String("description"),
+				})
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete a record",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "record-id",
+					Required: true,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				recordID, err := getUUID(command, "record-id")
+				if err != nil {
+					return err
+				}
+				return deleteDNSRecord(ctx, command, organizationID, recordID)
+			},
+		},
+	}
+}
+
+func dnsRecordTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "RECORD ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
+	fields = append(fields, TableField{Header: "TYPE", Field: "Type"})
+	fields = append(fields, TableField{Header: "VALUE", Field: "Value"})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	return fields
+}
+
+func listDNSRecords(ctx context.Context, command *cli.Command, organizationID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		ListDNSRecords(ctx, organizationID).
+		Execute())
+	show(command, dnsRecordTableFields(), res)
+	return nil
+}
+
+func createDNSRecord(ctx context.Context, command *cli.Command, organizationID string, record public.ModelsAddDNSRecord) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		CreateDNSRecord(ctx, organizationID).
+		DNSRecord(record).
+		Execute())
+	show(command, dnsRecordTableFields(), res)
+	return nil
+}
+
+func updateDNSRecord(ctx context.Context, command *cli.Command, organizationID, recordID string, update public.ModelsUpdateDNSRecord) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		UpdateDNSRecord(ctx, organizationID, recordID).
+		Update(update).
+		Execute())
+	show(command, dnsRecordTableFields(), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
+func deleteDNSRecord(ctx context.Context, command *cli.Command, organizationID, recordID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		DeleteDNSRecord(ctx, organizationID, recordID).
+		Execute())
+	show(command, dnsRecordTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/nexodus-io/nexodus/internal/api"
+	"github.com/urfave/cli/v3"
+)
+
+func doctorTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "CHECK", Field: "Check"})
+	fields = append(fields, TableField{Header: "STATUS", Formatter: func(item interface{}) string {
+		status := item.(api.DoctorFinding).Status
+		switch status {
+		case api.DoctorOK:
+			return color.New(color.FgGreen).Sprint(status)
+		case api.DoctorWarn:
+			return color.New(color.FgYellow).Sprint(status)
+		case api.DoctorFail:
+			return color.New(color.FgRed).Sprint(status)
+		default:
+			return status
+		}
+	}})
+	fields = append(fields, TableField{Header: "FINDING", Field: "Message"})
+	return fields
+}
+
+// cmdLocalDoctor runs the connectivity checks of nexd and prints what to do about the problems found
+func cmdLocalDoctor(ctx context.Context, command *cli.Command) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("Doctor", "")
+	if err != nil {
+		return fmt.Errorf("Failed to run the nexd checks: %w\n", err)
+	}
+	var findings []api.DoctorFinding
+	if err := json.Unmarshal([]byte(result), &findings); err != nil {
+		return fmt.Errorf("Failed to unmarshal the nexd checks: %w\n", err)
+	}
+
+	show(command, doctorTableFields(), findings)
+
+	failed := 0
+	if command.String("output") == encodeColumn {
+		for _, finding := range findings {
+			if finding.Hint == "" || (finding.Status != api.DoctorWarn && finding.Status != api.DoctorFail) {
+				continue
+			}
+			fmt.Printf("\n%s: %s\n  %s\n", finding.Check, finding.Message, finding.Hint)
+		}
+	}
+	for _, finding := range findings {
+		if finding.Status == api.DoctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the checks failed", failed)
+	}
+	return nil
+}
@@ -8,10 +8,12 @@ import (
 )
 
 type exitNodeOrigin struct {
-	PublicKey           string
-	Endpoint            string
-	AllowedIPs          []string
-	PersistentKeepAlive string
+	Hostname  string `json:"hostname"`
+	DeviceId  string `json:"device-id"`
+	PublicKey string `json:"public-key"`
+	Endpoint  string `json:"endpoint"`
+	Active    bool   `json:"active"`
+	Local     bool   `json:"local,omitempty"`
 }
 
 func enableExitNodeClient(ctx context.Context, command *cli.Command) error {
@@ -54,10 +56,19 @@ func disableExitNodeClient(ctx context.Context, command *cli.Command) error {
 
 func exitNodeTableFields(command *cli.Command) []TableField {
 	var fields []TableField
+	fields = append(fields, TableField{Header: "ACTIVE", Formatter: func(item interface{}) string {
+		if item.(exitNodeOrigin).Active {
+			return "*"
+		}
+		return ""
+	}})
+	fields = append(fields, TableField{Header: "HOSTNAME", Field: "Hostname"})
+	fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
 	fields = append(fields, TableField{Header: "ENDPOINT ADDRESS", Field: "Endpoint"})
 	fields = append(fields, TableField{Header: "PUBLIC KEY", Field: "PublicKey"})
 	return fields
 }
+
 func listExitNodes(ctx context.Context, command *cli.Command, encodeOut string) error {
 	var err error
 	var exitNodes []exitNodeOrigin
@@ -78,3 +89,52 @@ func listExitNodes(ctx context.Context, command *cli.Command, encodeOut string)
 	show(command, exitNodeTableFields(command), exitNodes)
 	return nil
 }
+
+func useExitNode(ctx context.Context, command *cli.Command, name string) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("UseExitNode", name)
+	if err != nil {
+		return fmt.Errorf("Failed to use the exit node %s: %w\n", name, err)
+	}
+
+	fmt.Print(result)
+	return nil
+}
+
+func exitNodeExceptAddRemove(ctx context.Context, command *cli.Command, add bool) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+	if command.Args().Len() == 0 {
+		return fmt.Errorf("at least one CIDR, IP address or domain is required")
+	}
+
+	method := "ExitNodeExceptRemove"
+	if add {
+		method = "ExitNodeExceptAdd"
+	}
+	for _, except := range command.Args().Slice() {
+		result, err := callNexd(method, except)
+		if err != nil {
+			return err
+		}
+		fmt.Print(result)
+	}
+	return nil
+}
+
+func listExitNodeExcepts(ctx context.Context, command *cli.Command) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("ExitNodeExceptList", "")
+	if err != nil {
+		return err
+	}
+	fmt.Print(result)
+	return nil
+}
@@ -44,7 +44,7 @@ func createInvitationCommand() *cli.Command {
 					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
-					organizationId, err := getUUID(command, "organization-id")
+					organizationId, err := getOrganizationID(command)
 					if err != nil {
 						return err
 					}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+var organizationIPExclusionSubcommands []*cli.Command
+
+func init() {
+	organizationIPExclusionSubcommands = []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List the exclusion ranges of an organization",
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				return listIPExclusionRanges(ctx, command, organizationID)
+			},
+		},
+		{
+			Name:  "create",
+			Usage: "Exclude a range of the organization cidr from the addresses handed out to devices",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "start",
+					Usage:    "the first address of the range",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "end",
+					Usage:    "the last address of the range, defaults to the start address",
+					Required: false,
+				},
+				&cli.StringFlag{
+					Name:     "description",
+					Required: false,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				end := command.String("end")
+				if end == "" {
+					end = command.String("start")
+				}
+				return createIPExclusionRange(ctx, command, organizationID, public.ModelsAddIPExclusionRange{
+					StartAddress: command.String("start"),
+					EndAddress:   end,
+					Description:  command.String("description"),
+				})
+			},
+		},
+		{
+			Name:  "update",
+			Usage: "Update the description of an exclusion range",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "range-id",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "description",
+					Required: true,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				rangeID, err := getUUID(command, "range-id")
+				if err != nil {
+					return err
+				}
+				return updateIPExclusionRange(ctx, command, organizationID, rangeID, public.ModelsUpdateIPExclusionRange{
+					Description: command.String("description"),
+				})
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete an exclusion range",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "range-id",
+					Required: true,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				rangeID, err := getUUID(command, "range-id")
+				if err != nil {
+					return err
+				}
+				return deleteIPExclusionRange(ctx, command, organizationID, rangeID)
+			},
+		},
+	}
+}
+
+func ipExclusionRangeTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "RANGE ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "START", Field: "StartAddress"})
+	fields = append(fields, TableField{Header: "END", Field: "EndAddress"})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	return fields
+}
+
+func listIPExclusionRanges(ctx context.Context, command *cli.Command, organizationID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		ListIPExclusionRanges(ctx, organizationID).
+		Execute())
+	show(command, ipExclusionRangeTableFields(), res)
+	return nil
+}
+
+func createIPExclusionRange(ctx context.Context, command *cli.Command, organizationID string, resource public.ModelsAddIPExclusionRange) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		CreateIPExclusionRange(ctx, organizationID).
+		IPExclusionRange(resource).
+		Execute())
+	show(command, ipExclusionRangeTableFields(), res)
+	return nil
+}
+
+func updateIPExclusionRange(ctx context.Context, command *cli.Command, organizationID, rangeID string, update public.ModelsUpdateIPExclusionRange) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		UpdateIPExclusionRange(ctx, organizationID, rangeID).
+		Update(update).
+		Execute())
+	show(command, ipExclusionRangeTableFields(), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
+func deleteIPExclusionRange(ctx context.Context, command *cli.Command, organizationID, rangeID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		DeleteIPExclusionRange(ctx, organizationID, rangeID).
+		Execute())
+	show(command, ipExclusionRangeTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+var vpcIPReservationSubcommands []*cli.Command
+
+func init() {
+	vpcIPReservationSubcommands = []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List the ip reservations of a vpc",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "vpc-id",
+					Required: false,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				vpcId, err := getUUID(command, "vpc-id")
+				if err != nil {
+					return err
+				}
+				return listIPReservations(ctx, command, vpcId)
+			},
+		},
+		{
+			Name:  "create",
+			Usage: "Reserve a tunnel ip of a vpc to a device public key or device id",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "vpc-id",
+					Required: false,
+				},
+				&cli.StringFlag{
+					Name:     "address",
+					Usage:    "the IPv4 tunnel address to reserve",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "public-key",
+					Usage:    "the wireguard public key of the device",
+					Required: false,
+				},
+				&cli.StringFlag{
+					Name:     "device-id",
+					Usage:    "the id of the device, e.g. the device id of a single device registration key",
+					Required: false,
+				},
+				&cli.StringFlag{
+					Name:     "description",
+					Required: false,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				vpcId, err := getUUID(command, "vpc-id")
+				if err != nil {
+					return err
+				}
+				deviceId, err := getUUID(command, "device-id")
+				if err != nil {
+					return err
+				}
+				return createIPReservation(ctx, command, vpcId, public.ModelsAddIPReservation{
+					Address:     command.String("address"),
+					PublicKey:   command.String("public-key"),
+					DeviceId:    deviceId,
+					Description: command.String("description"),
+				})
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete an ip reservation",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "vpc-id",
+					Required: false,
+				},
+				&cli.StringFlag{
+					Name:     "reservation-id",
+					Required: true,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				vpcId, err := getUUID(command, "vpc-id")
+				if err != nil {
+					return err
+				}
+				reservationId, err := getUUID(command, "reservation-id")
+				if err != nil {
+					return err
+				}
+				return deleteIPReservation(ctx, command, vpcId, reservationId)
+			},
+		},
+	}
+}
+
+func ipReservationTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "RESERVATION ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "ADDRESS", Field: "Address"})
+	fields = append(fields, TableField{Header: "PUBLIC KEY", Field: "PublicKey"})
+	fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	return fields
+}
+
+func listIPReservations(ctx context.Context, command *cli.Command, vpcId string) error {
+	c := createClient(ctx, command)
+	if vpcId == "" {
+		vpcId = getDefaultVpcId(ctx, c)
+	}
+	res := apiResponse(c.VPCApi.
+		ListIPReservationsInVPC(ctx, vpcId).
+		Execute())
+	show(command, ipReservationTableFields(), res)
+	return nil
+}
+
+func createIPReservation(ctx context.Context, command *cli.Command, vpcId string, resource public.ModelsAddIPReservation) error {
+	c := createClient(ctx, command)
+	if vpcId == "" {
+		vpcId = getDefaultVpcId(ctx, c)
+	}
+	res := apiResponse(c.VPCApi.
+		CreateIPReservation(ctx, vpcId).
+		IPReservation(resource).
+		Execute())
+	show(command, ipReservationTableFields(), res)
+	return nil
+}
+
+func deleteIPReservation(ctx context.Context, command *cli.Command, vpcId, reservationId string) error {
+	c := createClient(ctx, command)
+	if vpcId == "" {
+		vpcId = getDefaultVpcId(ctx, c)
+	}
+	res := apiResponse(c.VPCApi.
+		DeleteIPReservation(ctx, vpcId, reservationId).
+		Execute())
+	show(command, ipReservationTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
@@ -3,7 +3,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/rpc/jsonrpc"
@@ -34,10 +36,49 @@ func init() {
 				Action: cmdLocalVersion,
 			},
 			{
-				Name:   "status",
-				Usage:  "Display the nexd status",
+				Name:  "status",
+				Usage: "Display the nexd status",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "display the status, peers and security group rules in effect as json",
+						Value: false,
+					},
+				},
 				Action: cmdLocalStatus,
 			},
+			{
+				Name:   "doctor",
+				Usage:  "Check the connectivity of nexd to the service, the STUN servers, the relay and the peers, and suggest fixes for the problems found",
+				Action: cmdLocalDoctor,
+			},
+			{
+				Name:  "support-bundle",
+				Usage: "Collect the peers, routes, firewall rules, logs, flags and device state of nexd into a redacted tarball to attach to bug reports",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "the file the tarball is written to, defaults to nexd-support-<hostname>-<time>.tar.gz",
+					},
+				},
+				Action: cmdLocalSupportBundle,
+			},
+			{
+				Name:  "reload",
+				Usage: "Reload the configuration of nexd without restarting it, the same as sending it a SIGHUP",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					if err := checkVersion(); err != nil {
+						return err
+					}
+					result, err := callNexd("Reload", "")
+					if err != nil {
+						fmt.Printf("%s\n", err)
+						return err
+					}
+					fmt.Printf("%s\n", result)
+					return nil
+				},
+			},
 			{
 				Name:  "get",
 				Usage: "Get a value from the local nexd instance",
@@ -155,8 +196,9 @@ func init() {
 						},
 					},
 					{
-						Name:  "add",
-						Usage: "Add one or more proxy rules to nexd",
+						Name:      "add",
+						Usage:     "Add one or more proxy rules to nexd",
+						ArgsUsage: "[ingress|egress protocol port:destination_ip:destination_port]",
 						Flags: []cli.Flag{
 							&cli.StringSliceFlag{
 								Name:     "ingress",
@@ -174,8 +216,9 @@ func init() {
 						},
 					},
 					{
-						Name:  "remove",
-						Usage: "remove one or more proxy rules to nexd",
+						Name:      "remove",
+						Usage:     "remove one or more proxy rules to nexd",
+						ArgsUsage: "[ingress|egress protocol port:destination_ip:destination_port]",
 						Flags: []cli.Flag{
 							&cli.StringSliceFlag{
 								Name:     "ingress",
@@ -196,7 +239,10 @@ func init() {
 			},
 			{
 				Name:  "peers",
-				Usage: "Commands for interacting with nexd peer connectivity",
+				Usage: "Commands for interacting with nexd peer connectivity, lists the peers if no command is given",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return cmdListPeers(ctx, command)
+				},
 				Commands: []*cli.Command{
 					{
 						Name:  "list",
@@ -229,6 +275,29 @@ func init() {
 					},
 				},
 			},
+			{
+				Name:  "dns",
+				Usage: "Commands for interacting with the nexd overlay DNS resolver",
+				Commands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "List the device names published in the overlay DNS zone of the organization",
+						Action: listDnsNames,
+					},
+					{
+						Name:      "lookup",
+						Usage:     "Lookup the tunnel addresses of a device name",
+						ArgsUsage: "<name>",
+						Action: func(ctx context.Context, command *cli.Command) error {
+							name := command.Args().First()
+							if name == "" {
+								return fmt.Errorf("a device name is required")
+							}
+							return lookupDnsName(ctx, command, name)
+						},
+					},
+				},
+			},
 			{
 				Name:  "exit-node",
 				Usage: "Commands for interacting nexd exit node configuration",
@@ -241,6 +310,53 @@ func init() {
 							return listExitNodes(ctx, command, encodeOut)
 						},
 					},
+					{
+						Name:      "use",
+						Usage:     "Route the traffic of this device through the exit node with the hostname, device id or public key",
+						ArgsUsage: "<device>",
+						Action: func(ctx context.Context, command *cli.Command) error {
+							if command.Args().Len() != 1 {
+								return fmt.Errorf("an exit node hostname, device id or public key is required")
+							}
+							return useExitNode(ctx, command, command.Args().First())
+						},
+					},
+					{
+						Name:  "off",
+						Usage: "Stop routing the traffic of this device through an exit node, the same as disable",
+						Action: func(ctx context.Context, command *cli.Command) error {
+							return disableExitNodeClient(ctx, command)
+						},
+					},
+					{
+						Name:  "except",
+						Usage: "Commands for the CIDRs and domains kept on the local default route when an exit node is in use",
+						Commands: []*cli.Command{
+							{
+								Name:  "list",
+								Usage: "List the exit node exceptions",
+								Action: func(ctx context.Context, command *cli.Command) error {
+									return listExitNodeExcepts(ctx, command)
+								},
+							},
+							{
+								Name:      "add",
+								Usage:     "Keep the traffic to the CIDRs, IP addresses or domains off the exit node",
+								ArgsUsage: "<cidr|ip|domain>...",
+								Action: func(ctx context.Context, command *cli.Command) error {
+									return exitNodeExceptAddRemove(ctx, command, true)
+								},
+							},
+							{
+								Name:      "remove",
+								Usage:     "Route the traffic to the CIDRs, IP addresses or domains through the exit node again",
+								ArgsUsage: "<cidr|ip|domain>...",
+								Action: func(ctx context.Context, command *cli.Command) error {
+									return exitNodeExceptAddRemove(ctx, command, false)
+								},
+							},
+						},
+					},
 					{
 						Name:  "enable",
 						Usage: "Enable the device to use an exit node in the current organization. Warning: this will funnel all traffic through the exit node if one exists and will likely cause your device to be unreachable outside of the nexodus peer network.",
@@ -319,6 +435,19 @@ func cmdLocalStatus(ctx context.Context, command *cli.Command) error {
 		return err
 	}
 
+	if command.Bool("json") {
+		result, err := callNexd("StatusJSON", "")
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, []byte(result), "", "  "); err != nil {
+			return fmt.Errorf("Failed to format the status: %w\n", err)
+		}
+		fmt.Println(out.String())
+		return nil
+	}
+
 	result, err := callNexd("Status", "")
 	if err != nil {
 		return err
@@ -335,6 +464,22 @@ func proxyAddRemove(ctx context.Context, command *cli.Command, add bool) error {
 	}
 	ingress := command.StringSlice("ingress")
 	egress := command.StringSlice("egress")
+	if command.Args().Present() {
+		// a rule can also be given as arguments, for example: ingress tcp 8080:100.100.0.5:80
+		args := command.Args().Slice()
+		if len(args) != 3 {
+			return fmt.Errorf("a proxy rule given as arguments must be in the form: ingress|egress protocol port:destination_ip:destination_port")
+		}
+		rule := fmt.Sprintf("%s:%s", args[1], args[2])
+		switch args[0] {
+		case "ingress":
+			ingress = append(ingress, rule)
+		case "egress":
+			egress = append(egress, rule)
+		default:
+			return fmt.Errorf("invalid proxy rule type (%s), must be ingress or egress", args[0])
+		}
+	}
 	if len(ingress) == 0 && len(egress) == 0 {
 		return fmt.Errorf("No rules provided")
 	}
@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/nexodus-io/nexodus/internal/client"
@@ -66,6 +67,18 @@ func main() {
 				Usage:      "Api server URL",
 				Persistent: true,
 			},
+			&cli.StringFlag{
+				Name:       "config",
+				Usage:      "Path of the config file holding the contexts (default $HOME/.nexodus/config)",
+				Sources:    cli.EnvVars("NEXCTL_CONFIG"),
+				Persistent: true,
+			},
+			&cli.StringFlag{
+				Name:       "context",
+				Usage:      "Name of the context of the config file to use instead of the current context",
+				Sources:    cli.EnvVars("NEXCTL_CONTEXT"),
+				Persistent: true,
+			},
 			&cli.StringFlag{
 				Name:       "username",
 				Usage:      "Username",
@@ -76,6 +89,24 @@ func main() {
 				Usage:      "Password",
 				Persistent: true,
 			},
+			&cli.StringFlag{
+				Name:       "client-id",
+				Usage:      "ID of the service account to authenticate as",
+				Sources:    cli.EnvVars("NEXCTL_CLIENT_ID"),
+				Persistent: true,
+			},
+			&cli.StringFlag{
+				Name:       "client-secret",
+				Usage:      "Client secret of the service account",
+				Sources:    cli.EnvVars("NEXCTL_CLIENT_SECRET"),
+				Persistent: true,
+			},
+			&cli.StringFlag{
+				Name:       "api-key",
+				Usage:      "Personal api key to authenticate with, see nexctl api-key create",
+				Sources:    cli.EnvVars("NEXCTL_API_KEY"),
+				Persistent: true,
+			},
 			&cli.StringFlag{
 				Name:       "output",
 				Value:      encodeColumn,
@@ -83,6 +114,11 @@ func main() {
 				Usage:      "Output format: json, json-raw, yaml, no-header, column (default columns)",
 				Persistent: true,
 			},
+			&cli.StringFlag{
+				Name:       "columns",
+				Usage:      "Comma separated list of the columns of the column and no-header output, eg: id,hostname,tunnel_ips,online",
+				Persistent: true,
+			},
 			&cli.BoolFlag{
 				Name:       "insecure-skip-tls-verify",
 				Value:      false,
@@ -101,13 +137,20 @@ func main() {
 				},
 			},
 			createRegKeyCommand(),
+			createAPIKeyCommand(),
 			createOrganizationCommand(),
 			createVpcCommand(),
 			createDeviceCommand(),
+			createRelayCommand(),
 			createUserSubCommand(),
 			createSecurityGroupCommand(),
+			createServiceCommand(),
+			createConnectivityCommand(),
 			createSiteCommand(),
 			createInvitationCommand(),
+			createAuditCommand(),
+			createContextCommand(),
+			createTopCommand(),
 		},
 	}
 
@@ -132,6 +175,8 @@ func Fatalf(format string, a ...any) {
 
 func createClient(ctx context.Context, command *cli.Command) *client.APIClient {
 
+	nexctx := currentContext(command)
+
 	urlValue := DefaultServiceURL
 	flagUsed := "--service-url"
 	addApiPrefix := true
@@ -145,6 +190,9 @@ func createClient(ctx context.Context, command *cli.Command) *client.APIClient {
 		addApiPrefix = false
 	} else if command.IsSet("service-url") {
 		urlValue = command.String("service-url")
+	} else if nexctx != nil && nexctx.ServiceURL != "" {
+		urlValue = nexctx.ServiceURL
+		flagUsed = fmt.Sprintf("context %s service-url", nexctx.Name)
 	}
 
 	apiURL, err := url.Parse(urlValue)
@@ -160,22 +208,43 @@ func createClient(ctx context.Context, command *cli.Command) *client.APIClient {
 		apiURL.Path = ""
 	}
 
-	c, err := client.NewAPIClient(ctx, apiURL.String(), nil, createClientOptions(command)...)
+	c, err := client.NewAPIClient(ctx, apiURL.String(), func(msg string) {
+		fmt.Fprintln(os.Stderr, msg)
+	}, createClientOptions(command, nexctx)...)
 	if err != nil {
 		Fatal(err)
 	}
 	return c
 }
 
-func createClientOptions(command *cli.Command) []client.Option {
+// createClientOptions returns the client options of the flags, the values of the context are used for the flags that are not set
+func createClientOptions(command *cli.Command, nexctx *nexctlContext) []client.Option {
+	username, password := command.String("username"), command.String("password")
+	insecureSkipTLSVerify := command.Bool("insecure-skip-tls-verify")
 	options := []client.Option{
-		client.WithPasswordGrant(
-			command.String("username"),
-			command.String("password"),
-		),
 		client.WithUserAgent(fmt.Sprintf("nexctl/%s (%s; %s)", Version, runtime.GOOS, runtime.GOARCH)),
 	}
-	if command.Bool("insecure-skip-tls-verify") { // #nosec G402
+	if nexctx != nil {
+		if !command.IsSet("username") && !command.IsSet("password") {
+			username, password = nexctx.Username, nexctx.Password
+		}
+		if !command.IsSet("insecure-skip-tls-verify") {
+			insecureSkipTLSVerify = nexctx.InsecureSkipTLSVerify
+		}
+	}
+	if command.String("api-key") != "" {
+		options = append(options, client.WithBearerToken(command.String("api-key")))
+	} else if command.String("client-id") != "" {
+		options = append(options, client.WithServiceAccount(command.String("client-id"), command.String("client-secret")))
+	} else if nexctx != nil && nexctx.AuthMethod == authMethodDeviceFlow && !command.IsSet("username") {
+		options = append(options,
+			client.WithDeviceFlow(),
+			client.WithTokenStore(contextTokenStore{command: command, name: nexctx.Name}),
+		)
+	} else {
+		options = append(options, client.WithPasswordGrant(username, password))
+	}
+	if insecureSkipTLSVerify { // #nosec G402
 		options = append(options, client.WithTLSConfig(&tls.Config{
 			InsecureSkipVerify: true,
 		}))
@@ -214,6 +283,9 @@ func show(command *cli.Command, fields []TableField, result any) {
 		fmt.Println(string(bytes))
 
 	case encodeColumn, encodeNoHeader:
+		if command.IsSet("columns") {
+			fields = selectColumns(fields, command.String("columns"))
+		}
 
 		table := tablewriter.NewWriter(os.Stdout)
 		table.SetBorders(tablewriter.Border{
@@ -271,6 +343,40 @@ func show(command *cli.Command, fields []TableField, result any) {
 	}
 }
 
+// columnName returns the name of the column of a field used by the --columns flag, eg: `tunnel_ips` for the `TUNNEL IPS` header
+func columnName(field TableField) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(field.Header)), " ", "_")
+}
+
+// selectColumns returns the fields of the comma separated columns in the requested order. The id column selects
+// the first field, which is the id of the listed resource, and a trailing s of a column name is optional.
+func selectColumns(fields []TableField, columns string) []TableField {
+	var selected []TableField
+	for _, column := range strings.Split(columns, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		found := false
+		for i, field := range fields {
+			name := columnName(field)
+			if name == column || strings.TrimSuffix(name, "s") == column || (i == 0 && column == "id" && strings.HasSuffix(name, "_id")) {
+				selected = append(selected, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			var names []string
+			for _, field := range fields {
+				names = append(names, columnName(field))
+			}
+			Fatalf("unknown column: %s, the available columns are: %s", column, strings.Join(names, ", "))
+		}
+	}
+	return selected
+}
+
 func showSuccessfully(command *cli.Command, action string) {
 	encodeOut := command.String("output")
 	if encodeOut == encodeColumn || encodeOut == encodeNoHeader {
@@ -366,7 +472,7 @@ func getExpiration(command *cli.Command, name string) string {
 	if value == 0 {
 		return ""
 	}
-	return time.Now().Add(value).String()
+	return time.Now().Add(value).UTC().Format(time.RFC3339)
 }
 
 func getJsonMap(command *cli.Command, name string) (map[string]interface{}, error) {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/nexodus-io/nexodus/internal/api/public"
 	"github.com/urfave/cli/v3"
 )
@@ -17,7 +18,7 @@ func createOrganizationCommand() *cli.Command {
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:       "organization-id",
-						Required:   true,
+						Required:   false,
 						Persistent: true,
 					},
 				},
@@ -27,7 +28,7 @@ func createOrganizationCommand() *cli.Command {
 						Usage: "List organization users",
 						Action: func(ctx context.Context, command *cli.Command) error {
 
-							organizationID, err := getUUID(command, "organization-id")
+							organizationID, err := requireOrganizationID(command)
 							if err != nil {
 								return err
 							}
@@ -45,7 +46,7 @@ func createOrganizationCommand() *cli.Command {
 							},
 						},
 						Action: func(ctx context.Context, command *cli.Command) error {
-							organizationID, err := getUUID(command, "organization-id")
+							organizationID, err := requireOrganizationID(command)
 							if err != nil {
 								return err
 							}
@@ -59,6 +60,60 @@ func createOrganizationCommand() *cli.Command {
 					},
 				},
 			},
+			{
+				Name:  "ip-exclusion",
+				Usage: "Commands relating to the ranges of the organization cidr excluded from IPAM",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:       "organization-id",
+						Required:   false,
+						Persistent: true,
+					},
+				},
+				Commands: organizationIPExclusionSubcommands,
+			},
+			{
+				Name:  "dns-record",
+				Usage: "Commands relating to the custom records of the overlay DNS zone of an organization",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:       "organization-id",
+						Required:   false,
+						Persistent: true,
+					},
+				},
+				Commands: organizationDNSRecordSubcommands,
+			},
+			{
+				Name:  "service-account",
+				Usage: "Commands relating to the service accounts of an organization",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:       "organization-id",
+						Required:   false,
+						Persistent: true,
+					},
+				},
+				Commands: organizationServiceAccountSubcommands,
+			},
+			{
+				Name:  "ipam",
+				Usage: "Show the address usage of the prefixes of an organization",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "organization-id",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					organizationID, err := requireOrganizationID(command)
+					if err != nil {
+						return err
+					}
+
+					return getOrganizationIPAM(ctx, command, organizationID)
+				},
+			},
 			{
 				Name:  "list",
 				Usage: "List organizations",
@@ -78,11 +133,116 @@ func createOrganizationCommand() *cli.Command {
 						Name:     "description",
 						Required: true,
 					},
+					&cli.IntFlag{
+						Name:  "listen-port-min",
+						Usage: "the lowest wireguard listen port devices in the organization may use",
+					},
+					&cli.IntFlag{
+						Name:  "listen-port-max",
+						Usage: "the highest wireguard listen port devices in the organization may use",
+					},
+					&cli.StringFlag{
+						Name:  "update-channel",
+						Usage: "the agent release channel devices in the organization follow: stable or beta",
+					},
+					&cli.BoolFlag{
+						Name:  "require-device-approval",
+						Usage: "new devices of the organization are pending until an owner of the organization approves them",
+					},
+					&cli.IntFlag{
+						Name:  "device-ttl-days",
+						Usage: "devices not seen for this many days are expired, 0 means devices do not expire",
+					},
+					&cli.BoolFlag{
+						Name:  "delete-expired-devices",
+						Usage: "expired devices are deleted and their IPAM allocations released, instead of only flagged",
+					},
+					&cli.StringFlag{
+						Name:  "cidr",
+						Usage: "the IPv4 prefix of the default vpc of the organization, created with the organization",
+					},
+					&cli.StringFlag{
+						Name:  "cidr-v6",
+						Usage: "the IPv6 prefix of the default vpc of the organization, created with the organization",
+					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
-					name := command.String("name")
-					description := command.String("description")
-					return createOrganization(ctx, command, name, description)
+					return createOrganization(ctx, command, public.ModelsAddOrganization{
+						Ipv4Cidr:              command.String("cidr"),
+						Ipv6Cidr:              command.String("cidr-v6"),
+						Name:                  command.String("name"),
+						Description:           command.String("description"),
+						ListenPortMin:         int32(command.Int("listen-port-min")),
+						ListenPortMax:         int32(command.Int("listen-port-max")),
+						UpdateChannel:         command.String("update-channel"),
+						RequireDeviceApproval: command.Bool("require-device-approval"),
+						DeviceTtlDays:         int32(command.Int("device-ttl-days")),
+						DeleteExpiredDevices:  command.Bool("delete-expired-devices"),
+					})
+				},
+			},
+			{
+				Name:  "update",
+				Usage: "Update a organization",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "organization-id",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:     "description",
+						Required: false,
+					},
+					&cli.IntFlag{
+						Name:  "listen-port-min",
+						Usage: "the lowest wireguard listen port devices in the organization may use",
+					},
+					&cli.IntFlag{
+						Name:  "listen-port-max",
+						Usage: "the highest wireguard listen port devices in the organization may use",
+					},
+					&cli.StringFlag{
+						Name:  "update-channel",
+						Usage: "the agent release channel devices in the organization follow: stable or beta",
+					},
+					&cli.BoolFlag{
+						Name:  "require-device-approval",
+						Usage: "new devices of the organization are pending until an owner of the organization approves them",
+					},
+					&cli.IntFlag{
+						Name:  "device-ttl-days",
+						Usage: "devices not seen for this many days are expired, 0 means devices do not expire",
+					},
+					&cli.BoolFlag{
+						Name:  "delete-expired-devices",
+						Usage: "expired devices are deleted and their IPAM allocations released, instead of only flagged",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					organizationID, err := requireOrganizationID(command)
+					if err != nil {
+						return err
+					}
+
+					update := public.ModelsUpdateOrganization{
+						Description:   command.String("description"),
+						ListenPortMin: int32(command.Int("listen-port-min")),
+						ListenPortMax: int32(command.Int("listen-port-max")),
+						UpdateChannel: command.String("update-channel"),
+					}
+					if command.IsSet("require-device-approval") {
+						requireDeviceApproval := command.Bool("require-device-approval")
+						update.RequireDeviceApproval = &requireDeviceApproval
+					}
+					if command.IsSet("device-ttl-days") {
+						deviceTTLDays := int32(command.Int("device-ttl-days"))
+						update.DeviceTtlDays = &deviceTTLDays
+					}
+					if command.IsSet("delete-expired-devices") {
+						deleteExpiredDevices := command.Bool("delete-expired-devices")
+						update.DeleteExpiredDevices = &deleteExpiredDevices
+					}
+					return updateOrganization(ctx, command, organizationID, update)
 				},
 			},
 			{
@@ -91,11 +251,11 @@ func createOrganizationCommand() *cli.Command {
 				Flags: []cli.Flag{
 					&cli.StringFlag{
 						Name:     "organization-id",
-						Required: true,
+						Required: false,
 					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
-					organizationID, err := getUUID(command, "organization-id")
+					organizationID, err := requireOrganizationID(command)
 					if err != nil {
 						return err
 					}
@@ -112,6 +272,17 @@ func orgTableFields() []TableField {
 	fields = append(fields, TableField{Header: "ORGANIZATION ID", Field: "Id"})
 	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
 	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	fields = append(fields, TableField{Header: "LISTEN PORTS", Formatter: func(item interface{}) string {
+		org := item.(public.ModelsOrganization)
+		if org.ListenPortMin == 0 && org.ListenPortMax == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d-%d", org.ListenPortMin, org.ListenPortMax)
+	}})
+	fields = append(fields, TableField{Header: "UPDATE CHANNEL", Field: "UpdateChannel"})
+	fields = append(fields, TableField{Header: "DEVICE APPROVAL", Field: "RequireDeviceApproval"})
+	fields = append(fields, TableField{Header: "DEVICE TTL DAYS", Field: "DeviceTtlDays"})
+	fields = append(fields, TableField{Header: "DELETE EXPIRED", Field: "DeleteExpiredDevices"})
 	return fields
 }
 func listOrganizations(ctx context.Context, command *cli.Command) error {
@@ -123,18 +294,26 @@ func listOrganizations(ctx context.Context, command *cli.Command) error {
 	return nil
 }
 
-func createOrganization(ctx context.Context, command *cli.Command, name, description string) error {
+func createOrganization(ctx context.Context, command *cli.Command, org public.ModelsAddOrganization) error {
 	c := createClient(ctx, command)
 	res := apiResponse(c.OrganizationsApi.
 		CreateOrganization(ctx).
-		Organization(public.ModelsAddOrganization{
-			Name:        name,
-			Description: description,
-		}).Execute())
+		Organization(org).Execute())
 	show(command, orgTableFields(), res)
 	return nil
 }
 
+func updateOrganization(ctx context.Context, command *cli.Command, id string, update public.ModelsUpdateOrganization) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		UpdateOrganization(ctx, id).
+		Update(update).
+		Execute())
+	show(command, orgTableFields(), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
 /*
 func moveUserToOrganization(c *client.APIClient, encodeOut, username, OrganizationID string) error {
 	OrganizationUUID, err := uuid.Parse(OrganizationID)
@@ -202,3 +381,26 @@ func deleteOrganizationUser(ctx context.Context, command *cli.Command, orgId str
 	showSuccessfully(command, "deleted")
 	return nil
 }
+
+func orgIPAMTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "VPC ID", Field: "VpcId"})
+	fields = append(fields, TableField{Header: "CIDR", Field: "Cidr"})
+	fields = append(fields, TableField{Header: "SHARED", Field: "Shared"})
+	fields = append(fields, TableField{Header: "SIZE", Field: "Size"})
+	fields = append(fields, TableField{Header: "ALLOCATED", Field: "Allocated"})
+	fields = append(fields, TableField{Header: "FREE", Field: "Free"})
+	fields = append(fields, TableField{Header: "FRAGMENTATION", Formatter: func(item interface{}) string {
+		usage := item.(public.ModelsIPAMPrefixUsage)
+		return fmt.Sprintf("%.2f", usage.Fragmentation)
+	}})
+	return fields
+}
+func getOrganizationIPAM(ctx context.Context, command *cli.Command, orgId string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		GetOrganizationIPAM(ctx, orgId).
+		Execute())
+	show(command, orgIPAMTableFields(), res.Prefixes)
+	return nil
+}
@@ -20,16 +20,28 @@ type WgSession struct {
 	Tx              int64
 	Rx              int64
 	Healthy         bool
+	Reachability    string
+	ProbeLatency    time.Duration
+	LatencyAvg      time.Duration
+	LatencyMax      time.Duration
+	ProbeLoss       float64
+	RxRate          float64
+	TxRate          float64
+	Hostname        string
+	PeeringMethod   string
+	RelayReason     string
 }
 
 type ListPeersResponse struct {
 	RelayPresent  bool                 `json:"relay-present"`
 	RelayRequired bool                 `json:"relay-required"`
+	Relay         string               `json:"relay,omitempty"`
 	Peers         map[string]WgSession `json:"peers"`
 }
 
 func peerTableFields(command *cli.Command) []TableField {
 	var fields []TableField
+	fields = append(fields, TableField{Header: "HOSTNAME", Field: "Hostname"})
 	fields = append(fields, TableField{Header: "PUBLIC KEY", Field: "PublicKey"})
 	fields = append(fields, TableField{Header: "ENDPOINT", Field: "Endpoint"})
 	fields = append(fields, TableField{Header: "ALLOWED IPS", Field: "AllowedIPs"})
@@ -49,6 +61,53 @@ func peerTableFields(command *cli.Command) []TableField {
 	fields = append(fields, TableField{Header: "TRANSMITTED", Field: "Tx"})
 	fields = append(fields, TableField{Header: "RECEIVED", Field: "Rx"})
 	fields = append(fields, TableField{Header: "HEALTHY", Field: "Healthy"})
+	fields = append(fields, TableField{Header: "REACHABILITY", Formatter: func(item interface{}) string {
+		peer := item.(WgSession)
+		if peer.Reachability == "" {
+			return "-"
+		}
+		if peer.ProbeLatency > 0 {
+			return fmt.Sprintf("%s (%.2fms)", peer.Reachability, float64(peer.ProbeLatency)/float64(time.Millisecond))
+		}
+		return peer.Reachability
+	}})
+	if command.Bool("full") || command.IsSet("columns") {
+		fields = append(fields, TableField{Header: "AVG LATENCY", Formatter: func(item interface{}) string {
+			peer := item.(WgSession)
+			if peer.Reachability == "" {
+				return "-"
+			}
+			return fmt.Sprintf("%.2fms", float64(peer.LatencyAvg)/float64(time.Millisecond))
+		}})
+		fields = append(fields, TableField{Header: "MAX LATENCY", Formatter: func(item interface{}) string {
+			peer := item.(WgSession)
+			if peer.Reachability == "" {
+				return "-"
+			}
+			return fmt.Sprintf("%.2fms", float64(peer.LatencyMax)/float64(time.Millisecond))
+		}})
+		fields = append(fields, TableField{Header: "PROBE LOSS", Formatter: func(item interface{}) string {
+			peer := item.(WgSession)
+			if peer.Reachability == "" {
+				return "-"
+			}
+			return fmt.Sprintf("%.0f%%", peer.ProbeLoss*100)
+		}})
+		fields = append(fields, TableField{Header: "RX RATE", Formatter: func(item interface{}) string {
+			return formatRate(item.(WgSession).RxRate)
+		}})
+		fields = append(fields, TableField{Header: "TX RATE", Formatter: func(item interface{}) string {
+			return formatRate(item.(WgSession).TxRate)
+		}})
+		fields = append(fields, TableField{Header: "PEERING METHOD", Field: "PeeringMethod"})
+		fields = append(fields, TableField{Header: "RELAY REASON", Formatter: func(item interface{}) string {
+			peer := item.(WgSession)
+			if peer.RelayReason == "" {
+				return "-"
+			}
+			return peer.RelayReason
+		}})
+	}
 	return fields
 }
 
@@ -151,7 +151,7 @@ func regTokenTableFields(command *cli.Command) []TableField {
 		record := item.(public.ModelsRegKey)
 		return fmt.Sprintf("--reg-key %s#%s", command.String("service-url"), record.BearerToken)
 	}})
-	if command.Bool("full") {
+	if command.Bool("full") || command.IsSet("columns") {
 		fields = append(fields, TableField{Header: "VPC ID", Field: "VpcId"})
 		fields = append(fields, TableField{Header: "SECURITY GROUP ID", Field: "SecurityGroupId"})
 		fields = append(fields, TableField{Header: "SINGLE USE", Formatter: func(item interface{}) string {
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+// relayStatus is a relay of a vpc with the load it reported and the number of devices the service assigned to it
+type relayStatus struct {
+	Device   public.ModelsDevice `json:"device"`
+	Type     string              `json:"type"`
+	Peers    int                 `json:"peers"`
+	RxRate   float64             `json:"rx_rate"` // bytes per second
+	TxRate   float64             `json:"tx_rate"` // bytes per second
+	Assigned int                 `json:"assigned"`
+}
+
+func createRelayCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "relay",
+		Usage: "Commands relating to relays",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the relays of a vpc and their load",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "vpc-id",
+						Usage: "the vpc of the relays, defaults to the default vpc of the user",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					vpcId, err := getUUID(command, "vpc-id")
+					if err != nil {
+						return err
+					}
+					return listRelays(ctx, command, vpcId)
+				},
+			},
+		},
+	}
+}
+
+func relayTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "DEVICE ID", Formatter: func(item interface{}) string {
+		return item.(relayStatus).Device.Id
+	}})
+	fields = append(fields, TableField{Header: "NAME", Formatter: func(item interface{}) string {
+		return deviceName(item.(relayStatus).Device)
+	}})
+	fields = append(fields, TableField{Header: "TYPE", Field: "Type"})
+	fields = append(fields, TableField{Header: "ONLINE", Formatter: func(item interface{}) string {
+		return fmt.Sprintf("%v", item.(relayStatus).Device.Online)
+	}})
+	fields = append(fields, TableField{Header: "PEERS", Field: "Peers"})
+	fields = append(fields, TableField{Header: "ASSIGNED DEVICES", Field: "Assigned"})
+	fields = append(fields, TableField{Header: "RX", Formatter: func(item interface{}) string {
+		return formatRate(item.(relayStatus).RxRate)
+	}})
+	fields = append(fields, TableField{Header: "TX", Formatter: func(item interface{}) string {
+		return formatRate(item.(relayStatus).TxRate)
+	}})
+	return fields
+}
+
+// formatRate formats a rate in bytes per second as bits per second
+func formatRate(bytesPerSecond float64) string {
+	bits := bytesPerSecond * 8
+	switch {
+	case bits >= 1e9:
+		return fmt.Sprintf("%.1f Gbit/s", bits/1e9)
+	case bits >= 1e6:
+		return fmt.Sprintf("%.1f Mbit/s", bits/1e6)
+	case bits >= 1e3:
+		return fmt.Sprintf("%.1f kbit/s", bits/1e3)
+	default:
+		return fmt.Sprintf("%.0f bit/s", bits)
+	}
+}
+
+func listRelays(ctx context.Context, command *cli.Command, vpcId string) error {
+	c := createClient(ctx, command)
+	if vpcId == "" {
+		vpcId = getDefaultVpcId(ctx, c)
+	}
+	devices := apiResponse(c.VPCApi.
+		ListDevicesInVPC(ctx, vpcId).
+		Execute())
+	metadata := apiResponse(c.VPCApi.
+		ListMetadataInVPC(ctx, vpcId, []string{"relay"}).
+		Execute())
+
+	relays := map[string]*relayStatus{}
+	for _, d := range devices {
+		if d.Relay {
+			relays[d.Id] = &relayStatus{Device: d}
+		}
+	}
+	for _, d := range devices {
+		if r, ok := relays[d.RelayId]; ok && !d.Relay {
+			r.Assigned++
+		}
+	}
+	for _, m := range metadata {
+		r, ok := relays[m.DeviceId]
+		if !ok || m.Key != "relay" {
+			continue
+		}
+		if v, ok := m.Value["type"].(string); ok {
+			r.Type = v
+		}
+		if v, ok := m.Value["peers"].(float64); ok {
+			r.Peers = int(v)
+		}
+		if v, ok := m.Value["rxrate"].(float64); ok {
+			r.RxRate = v
+		}
+		if v, ok := m.Value["txrate"].(float64); ok {
+			r.TxRate = v
+		}
+	}
+
+	items := []relayStatus{}
+	for _, r := range relays {
+		items = append(items, *r)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return deviceName(items[i].Device) < deviceName(items[j].Device)
+	})
+	show(command, relayTableFields(), items)
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+func createServiceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "service",
+		Usage: "Commands relating to the services published in the overlay DNS",
+		Commands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List all services",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return listServices(ctx, command)
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Publish a port of a device as a named service",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "device-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "the name of the service in the overlay DNS, a lowercase DNS label",
+						Required: true,
+					},
+					&cli.IntFlag{
+						Name:     "port",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "protocol",
+						Usage: "tcp or udp",
+						Value: "tcp",
+					},
+					&cli.StringFlag{
+						Name:     "description",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					deviceID, err := getUUID(command, "device-id")
+					if err != nil {
+						return err
+					}
+					return createService(ctx, command, public.ModelsAddService{
+						DeviceId:    deviceID,
+						Name:        command.String("name"),
+						Port:        int32(command.Int("port")),
+						Protocol:    command.String("protocol"),
+						Description: command.String("description"),
+					})
+				},
+			},
+			{
+				Name:  "update",
+				Usage: "Update a service",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "service-id",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "device-id",
+						Usage: "move the service to another device",
+					},
+					&cli.IntFlag{
+						Name: "port",
+					},
+					&cli.StringFlag{
+						Name:  "protocol",
+						Usage: "tcp or udp",
+					},
+					&cli.StringFlag{
+						Name: "description",
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					serviceID, err := getUUID(command, "service-id")
+					if err != nil {
+						return err
+					}
+					update := public.ModelsUpdateService{
+						Port:        int32(command.Int("port")),
+						Protocol:    command.String("protocol"),
+						Description: command.String("description"),
+					}
+					if command.IsSet("device-id") {
+						update.DeviceId, err = getUUID(command, "device-id")
+						if err != nil {
+							return err
+						}
+					}
+					return updateService(ctx, command, serviceID, update)
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete a service",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "service-id",
+						Required: true,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					serviceID, err := getUUID(command, "service-id")
+					if err != nil {
+						return err
+					}
+					return deleteService(ctx, command, serviceID)
+				},
+			},
+		},
+	}
+}
+
+func serviceTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "SERVICE ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
+	fields = append(fields, TableField{Header: "DEVICE ID", Field: "DeviceId"})
+	fields = append(fields, TableField{Header: "PORT", Field: "Port"})
+	fields = append(fields, TableField{Header: "PROTOCOL", Field: "Protocol"})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	return fields
+}
+
+func listServices(ctx context.Context, command *cli.Command) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.ServicesApi.
+		ListServices(ctx).
+		Execute())
+	show(command, serviceTableFields(), res)
+	return nil
+}
+
+func createService(ctx context.Context, command *cli.Command, service public.ModelsAddService) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.ServicesApi.
+		CreateService(ctx).
+		Service(service).
+		Execute())
+	show(command, serviceTableFields(), res)
+	return nil
+}
+
+func updateService(ctx context.Context, command *cli.Command, serviceID string, update public.ModelsUpdateService) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.ServicesApi.
+		UpdateService(ctx, serviceID).
+		Update(update).
+		Execute())
+	show(command, serviceTableFields(), res)
+	showSuccessfully(command, "updated")
+	return nil
+}
+
+func deleteService(ctx context.Context, command *cli.Command, serviceID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.ServicesApi.
+		DeleteService(ctx, serviceID).
+		Execute())
+	show(command, serviceTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
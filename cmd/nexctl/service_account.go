@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/urfave/cli/v3"
+)
+
+var organizationServiceAccountSubcommands []*cli.Command
+
+func init() {
+	organizationServiceAccountSubcommands = []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List the service accounts of an organization",
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				return listServiceAccounts(ctx, command, organizationID)
+			},
+		},
+		{
+			Name:  "create",
+			Usage: "Add a service account to an organization, its client secret is only shown once",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "name",
+					Usage:    "the name of the service account, e.g. ci",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:     "description",
+					Required: false,
+				},
+				&cli.StringSliceFlag{
+					Name:        "role",
+					Usage:       "a role of the service account in the organization, member or owner",
+					Required:    false,
+					DefaultText: "member",
+					Value:       []string{"member"},
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				return createServiceAccount(ctx, command, organizationID, public.ModelsAddServiceAccount{
+					Name:        command.String("name"),
+					Description: command.String("description"),
+					Roles:       command.StringSlice("role"),
+				})
+			},
+		},
+		{
+			Name:  "delete",
+			Usage: "Delete a service account, its access tokens are not accepted anymore",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "account-id",
+					Required: true,
+				},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				organizationID, err := requireOrganizationID(command)
+				if err != nil {
+					return err
+				}
+				accountID, err := getUUID(command, "account-id")
+				if err != nil {
+					return err
+				}
+				return deleteServiceAccount(ctx, command, organizationID, accountID)
+			},
+		},
+	}
+}
+
+func serviceAccountTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "ACCOUNT ID", Field: "Id"})
+	fields = append(fields, TableField{Header: "NAME", Field: "Name"})
+	fields = append(fields, TableField{Header: "ROLES", Formatter: func(item interface{}) string {
+		// the created service account is shown by reference
+		switch account := item.(type) {
+		case *public.ModelsServiceAccount:
+			return strings.Join(account.Roles, ", ")
+		case public.ModelsServiceAccount:
+			return strings.Join(account.Roles, ", ")
+		}
+		return ""
+	}})
+	fields = append(fields, TableField{Header: "DESCRIPTION", Field: "Description"})
+	return fields
+}
+
+func listServiceAccounts(ctx context.Context, command *cli.Command, organizationID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		ListServiceAccounts(ctx, organizationID).
+		Execute())
+	show(command, serviceAccountTableFields(), res)
+	return nil
+}
+
+func createServiceAccount(ctx context.Context, command *cli.Command, organizationID string, account public.ModelsAddServiceAccount) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		CreateServiceAccount(ctx, organizationID).
+		ServiceAccount(account).
+		Execute())
+	fields := serviceAccountTableFields()
+	fields = append(fields, TableField{Header: "CLIENT SECRET", Field: "ClientSecret"})
+	show(command, fields, res)
+	return nil
+}
+
+func deleteServiceAccount(ctx context.Context, command *cli.Command, organizationID, accountID string) error {
+	c := createClient(ctx, command)
+	res := apiResponse(c.OrganizationsApi.
+		DeleteServiceAccount(ctx, organizationID, accountID).
+		Execute())
+	show(command, serviceAccountTableFields(), res)
+	showSuccessfully(command, "deleted")
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// defaultSupportBundleFile returns the default file name of the support bundle, without the extension
+func defaultSupportBundleFile() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("nexd-support-%s-%s", hostname, time.Now().UTC().Format("20060102-150405"))
+}
+
+// cmdLocalSupportBundle collects the diagnostics of nexd into a tarball to attach to bug reports
+func cmdLocalSupportBundle(ctx context.Context, command *cli.Command) error {
+	if err := checkVersion(); err != nil {
+		return err
+	}
+
+	result, err := callNexd("SupportBundle", "")
+	if err != nil {
+		return fmt.Errorf("Failed to collect the nexd support bundle: %w\n", err)
+	}
+	var files map[string]string
+	if err := json.Unmarshal([]byte(result), &files); err != nil {
+		return fmt.Errorf("Failed to unmarshal the nexd support bundle: %w\n", err)
+	}
+
+	fileName := command.String("file")
+	if fileName == "" {
+		fileName = defaultSupportBundleFile() + ".tar.gz"
+	}
+	dir := strings.TrimSuffix(path.Base(fileName), ".tar.gz")
+	if err := writeSupportBundle(fileName, dir, files); err != nil {
+		_ = os.Remove(fileName)
+		return fmt.Errorf("Failed to write the support bundle: %w\n", err)
+	}
+	fmt.Printf("Wrote the support bundle to %s, review it before attaching it to a bug report\n", fileName)
+	return nil
+}
+
+// writeSupportBundle writes the files of the support bundle to a gzipped tarball, under a directory
+func writeSupportBundle(fileName string, dir string, files map[string]string) error {
+	// #nosec G304
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := time.Now()
+	for _, name := range names {
+		content := []byte(files[name])
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(dir, name),
+			Mode:    0600,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}); err != nil {
+			return err
+		}
+		if _, err := tw.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+const (
+	clearScreen = "\033[H\033[2J"
+	hideCursor  = "\033[?25l"
+	showCursor  = "\033[?25h"
+)
+
+// topDevice is a device of the vpc and the wireguard session of the local nexd with it, if any
+type topDevice struct {
+	Device public.ModelsDevice
+	Peer   *WgSession
+}
+
+func createTopCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "top",
+		Usage: "Live view of the devices of a vpc, their online state, tunnel ips, relay usage and handshake ages",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "vpc-id",
+				Usage: "the vpc to watch, defaults to the default vpc of the user",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "how often the view is refreshed, changes of the devices are shown as they happen",
+				Value: 2 * time.Second,
+			},
+		},
+		Action: func(ctx context.Context, command *cli.Command) error {
+			vpcId, err := getUUID(command, "vpc-id")
+			if err != nil {
+				return err
+			}
+			if command.Duration("interval") <= 0 {
+				return fmt.Errorf("invalid value for --interval flag: must be greater than 0")
+			}
+			return cmdTop(ctx, command, vpcId)
+		},
+	}
+}
+
+func topTableFields() []TableField {
+	var fields []TableField
+	fields = append(fields, TableField{Header: "NAME", Formatter: func(item interface{}) string {
+		return deviceName(item.(topDevice).Device)
+	}})
+	fields = append(fields, TableField{Header: "TUNNEL IPS", Formatter: func(item interface{}) string {
+		dev := item.(topDevice).Device
+		ips := []string{}
+		for _, ip := range dev.Ipv4TunnelIps {
+			ips = append(ips, ip.Address)
+		}
+		for _, ip := range dev.Ipv6TunnelIps {
+			ips = append(ips, ip.Address)
+		}
+		return strings.Join(ips, ", ")
+	}})
+	fields = append(fields, TableField{Header: "ONLINE", Formatter: func(item interface{}) string {
+		dev := item.(topDevice).Device
+		state := "offline"
+		if dev.Online {
+			state = "online"
+		}
+		// online_at is the time of the last change of the online state
+		if onlineAt, err := util.ParseTime(dev.OnlineAt); err == nil && !onlineAt.IsZero() {
+			state = fmt.Sprintf("%s (%s)", state, formatAge(onlineAt))
+		}
+		return state
+	}})
+	fields = append(fields, TableField{Header: "RELAY", Formatter: func(item interface{}) string {
+		if item.(topDevice).Device.Relay {
+			return "true"
+		}
+		return "false"
+	}})
+	fields = append(fields, TableField{Header: "PEERING METHOD", Formatter: func(item interface{}) string {
+		peer := item.(topDevice).Peer
+		if peer == nil || peer.PeeringMethod == "" {
+			return "-"
+		}
+		return peer.PeeringMethod
+	}})
+	fields = append(fields, TableField{Header: "LATEST HANDSHAKE", Formatter: func(item interface{}) string {
+		peer := item.(topDevice).Peer
+		if peer == nil {
+			return "-"
+		}
+		handshakeTime, err := util.ParseTime(peer.LatestHandshake)
+		if err != nil || handshakeTime.IsZero() {
+			return "None"
+		}
+		return formatAge(handshakeTime) + " ago"
+	}})
+	fields = append(fields, TableField{Header: "HEALTHY", Formatter: func(item interface{}) string {
+		peer := item.(topDevice).Peer
+		if peer == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%v", peer.Healthy)
+	}})
+	return fields
+}
+
+// cmdTop watches the devices of the vpc with the watch api and redraws the view on every
+// change and refresh interval until it is interrupted.
+func cmdTop(ctx context.Context, command *cli.Command, vpcId string) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	c := createClient(ctx, command)
+	if vpcId == "" {
+		vpcId = getDefaultVpcId(ctx, c)
+	}
+
+	informer := c.VPCApi.ListDevicesInVPC(ctx, vpcId).Informer()
+	devices, _, err := informer.Execute()
+	if err != nil {
+		return fmt.Errorf("failed to list the devices of the vpc: %w", err)
+	}
+
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	if interactive {
+		fmt.Print(hideCursor)
+		defer fmt.Print(showCursor)
+	}
+
+	ticker := time.NewTicker(command.Duration("interval"))
+	defer ticker.Stop()
+	for {
+		view := renderTop(vpcId, devices, localPeers())
+		if interactive {
+			view = clearScreen + view
+		}
+		fmt.Print(view)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-informer.Changed():
+		}
+		devices, _, err = informer.Execute()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to watch the devices of the vpc: %w", err)
+		}
+	}
+}
+
+// renderTop renders the view of the devices sorted by hostname
+func renderTop(vpcId string, devices map[string]public.ModelsDevice, peers map[string]WgSession) string {
+	items := []topDevice{}
+	online := 0
+	for _, dev := range devices {
+		item := topDevice{Device: dev}
+		if peer, found := peers[dev.PublicKey]; found {
+			item.Peer = &peer
+		}
+		if dev.Online {
+			online++
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if deviceName(items[i].Device) != deviceName(items[j].Device) {
+			return deviceName(items[i].Device) < deviceName(items[j].Device)
+		}
+		return items[i].Device.Id < items[j].Device.Id
+	})
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "nexctl top - %s - vpc %s - %d of %d devices online\n", time.Now().Format(time.TimeOnly), vpcId, online, len(items))
+	if peers == nil {
+		fmt.Fprintln(buf, "the local nexd is not reachable, the peering method, handshake and health of the devices are not shown")
+	}
+	fmt.Fprintln(buf)
+
+	fields := topTableFields()
+	table := tablewriter.NewWriter(buf)
+	table.SetBorders(tablewriter.Border{
+		Left:   true,
+		Right:  true,
+		Top:    false,
+		Bottom: false,
+	})
+	table.SetAutoWrapText(false)
+	var headers []string
+	for _, field := range fields {
+		headers = append(headers, field.Header)
+	}
+	table.SetHeader(headers)
+	for _, item := range items {
+		var line []string
+		for _, field := range fields {
+			line = append(line, field.Formatter(item))
+		}
+		table.Append(line)
+	}
+	table.Render()
+	return buf.String()
+}
+
+// localPeers returns the wireguard sessions of the local nexd by public key, nil if nexd is not reachable
+func localPeers() map[string]WgSession {
+	result, err := callNexd("ListPeers", "")
+	if err != nil {
+		return nil
+	}
+	var response ListPeersResponse
+	if err := json.Unmarshal([]byte(result), &response); err != nil {
+		return nil
+	}
+	peers := map[string]WgSession{}
+	for _, peer := range response.Peers {
+		peers[peer.PublicKey] = peer
+	}
+	return peers
+}
+
+// formatAge formats the time elapsed since t rounded to the second, eg: 1h2m3s
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	if age < 0 {
+		age = 0
+	}
+	return age.Round(time.Second).String()
+}
@@ -53,7 +53,7 @@ func createUserSubCommand() *cli.Command {
 					},
 					&cli.StringFlag{
 						Name:     "organization-id",
-						Required: true,
+						Required: false,
 					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
@@ -61,7 +61,7 @@ func createUserSubCommand() *cli.Command {
 					if err != nil {
 						return err
 					}
-					orgID, err := getUUID(command, "organization-id")
+					orgID, err := requireOrganizationID(command)
 					if err != nil {
 						return err
 					}
@@ -41,11 +41,15 @@ func createVpcCommand() *cli.Command {
 					},
 				},
 				Action: func(ctx context.Context, command *cli.Command) error {
+					organizationID, err := getOrganizationID(command)
+					if err != nil {
+						return err
+					}
 					return createVPC(ctx, command, public.ModelsAddVPC{
 						Ipv4Cidr:       command.String("ipv4-cidr"),
 						Ipv6Cidr:       command.String("ipv6-cidr"),
 						Description:    command.String("description"),
-						OrganizationId: command.String("organization-id"),
+						OrganizationId: organizationID,
 						PrivateCidr:    !(command.String("ipv4-cidr") == "" && command.String("ipv6-cidr") == ""),
 					})
 				},
@@ -97,6 +101,11 @@ func createVpcCommand() *cli.Command {
 				Usage:    "Commands relating to device metadata across the vpc",
 				Commands: vpcMetadataSubcommands,
 			},
+			{
+				Name:     "ip-reservation",
+				Usage:    "Commands relating to the tunnel ips reserved to devices of the vpc",
+				Commands: vpcIPReservationSubcommands,
+			},
 		},
 	}
 }
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/nexodus"
+	"github.com/urfave/cli/v3"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	configFileEnv = "NEXD_CONFIG"
+	// logLevelKey is the key of the config file setting the log level, which is not a flag
+	logLevelKey = "log-level"
+)
+
+// configFileKeys are the keys of the flags set by the config file, the other flags that are set
+// were set on the command line or by their environment variable.
+var configFileKeys = map[string]bool{}
+
+// readConfigFile reads the keys of the config file, nil if the default config file does not exist
+func readConfigFile(command *cli.Command) (map[string]interface{}, string, error) {
+	path := command.String("config")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !command.IsSet("config") {
+			// the default config file is optional
+			return nil, path, nil
+		}
+		return nil, path, fmt.Errorf("failed to read the config file: %w", err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, path, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	known := map[string]cli.Flag{}
+	collectFlags(command.Root(), known)
+	for key := range values {
+		if _, found := known[key]; key != logLevelKey && (!found || key == "config" || key == "help") {
+			return nil, path, fmt.Errorf("config file %s: unknown key %q", path, key)
+		}
+	}
+	return values, path, nil
+}
+
+// applyConfigFile sets the flags of the command that are neither set on the command line nor by
+// their environment variable to the values of the keys of the config file with the same name, so
+// that flags take precedence over the environment and the environment over the config file. It
+// runs before the flags of each command are acted on, keys of the flags of other commands are skipped.
+func applyConfigFile(ctx context.Context, command *cli.Command, logLevel *zap.AtomicLevel) error {
+	values, path, err := readConfigFile(command)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := values[key]
+		if key == logLevelKey {
+			if command.Root() != command {
+				continue
+			}
+			if err := applyLogLevel(value, logLevel); err != nil {
+				return fmt.Errorf("config file %s: invalid value for key %q: %w", path, key, err)
+			}
+			continue
+		}
+		fl := lookupFlag(command, key)
+		if fl == nil || command.IsSet(key) {
+			continue
+		}
+		if err := setFlag(command, fl, key, value); err != nil {
+			return fmt.Errorf("config file %s: invalid value for key %q: %w", path, key, err)
+		}
+		configFileKeys[key] = true
+		if af, ok := fl.(cli.ActionableFlag); ok {
+			if err := af.RunAction(ctx, command); err != nil {
+				return fmt.Errorf("config file %s: invalid value for key %q: %w", path, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setFlag sets a flag to a value of the config file, lists are only accepted by the flags that take multiple values
+func setFlag(command *cli.Command, fl cli.Flag, key string, value interface{}) error {
+	multiValue := false
+	if mf, ok := fl.(cli.DocGenerationMultiValueFlag); ok {
+		multiValue = mf.IsMultiValueFlag()
+	}
+	takesValue := true
+	if vf, ok := fl.(cli.DocGenerationFlag); ok {
+		takesValue = vf.TakesValue()
+	}
+
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		if !multiValue {
+			return fmt.Errorf("a list is not supported, it takes a single value")
+		}
+		items = v
+	default:
+		items = []interface{}{v}
+	}
+	for _, item := range items {
+		var s string
+		switch v := item.(type) {
+		case bool:
+			s = strconv.FormatBool(v)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			if !takesValue {
+				return fmt.Errorf("must be true or false")
+			}
+			s = v
+		case nil:
+			continue
+		default:
+			return fmt.Errorf("unsupported value %v", v)
+		}
+		if err := command.Set(key, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyLogLevel(value interface{}, logLevel *zap.AtomicLevel) error {
+	level, err := parseLogLevel(value)
+	if err != nil {
+		return err
+	}
+	if os.Getenv(nexodusLogEnv) != "" {
+		// the environment takes precedence over the config file
+		return nil
+	}
+	logLevel.SetLevel(level)
+	return nil
+}
+
+func parseLogLevel(value interface{}) (zapcore.Level, error) {
+	s, ok := value.(string)
+	if !ok {
+		return zapcore.InfoLevel, fmt.Errorf("must be one of debug, info, warn or error")
+	}
+	level, err := zapcore.ParseLevel(strings.TrimSpace(s))
+	if err != nil {
+		return zapcore.InfoLevel, fmt.Errorf("must be one of debug, info, warn or error")
+	}
+	return level, nil
+}
+
+// reloadOptions reads the options of a running nexd that can be reloaded from the config file, the
+// flags set on the command line or by their environment variable keep their value.
+func reloadOptions(command *cli.Command) (nexodus.ReloadOptions, error) {
+	o := nexodus.ReloadOptions{}
+	values, path, err := readConfigFile(command)
+	if err != nil {
+		return o, err
+	}
+	fromFile := func(key string) bool {
+		return configFileKeys[key] || !command.IsSet(key)
+	}
+	invalid := func(key string, err error) error {
+		return fmt.Errorf("config file %s: invalid value for key %q: %w", path, key, err)
+	}
+
+	o.RelayOnly = command.Bool("relay-only")
+	if fromFile("relay-only") {
+		o.RelayOnly = false
+		if value, found := values["relay-only"]; found && value != nil {
+			relayOnly, ok := value.(bool)
+			if !ok {
+				return o, invalid("relay-only", fmt.Errorf("must be true or false"))
+			}
+			o.RelayOnly = relayOnly
+		}
+	}
+
+	// only routers advertise cidrs
+	if lookupFlag(command, "advertise-cidr") != nil {
+		cidrs := []string{}
+		for _, key := range []string{"advertise-cidr", "child-prefix"} {
+			if !fromFile(key) {
+				cidrs = append(cidrs, command.StringSlice(key)...)
+				continue
+			}
+			list, err := stringList(values[key])
+			if err != nil {
+				return o, invalid(key, err)
+			}
+			for _, cidr := range list {
+				if err := nexodus.ValidateCIDR(cidr); err != nil {
+					return o, invalid(key, err)
+				}
+			}
+			cidrs = append(cidrs, list...)
+		}
+		if command.Bool("exit-node") && !slices.Contains(cidrs, "0.0.0.0/0") {
+			cidrs = append(cidrs, "0.0.0.0/0")
+		}
+		o.AdvertiseCidrs = cidrs
+	}
+
+	if fromFile("security-group-id") {
+		if value, found := values["security-group-id"]; found && value != nil {
+			id, ok := value.(string)
+			if !ok {
+				return o, invalid("security-group-id", fmt.Errorf("must be a security group id"))
+			}
+			if _, err := uuid.Parse(id); err != nil {
+				return o, invalid("security-group-id", err)
+			}
+			o.SecurityGroupId = id
+		}
+	}
+
+	if value, found := values[logLevelKey]; found && os.Getenv(nexodusLogEnv) == "" {
+		level, err := parseLogLevel(value)
+		if err != nil {
+			return o, invalid(logLevelKey, err)
+		}
+		o.LogLevel = &level
+	}
+	return o, nil
+}
+
+// stringList returns the strings of a value of the config file that is a string or a list of strings
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("must be a list of strings")
+	}
+}
+
+// lookupFlag returns the flag of the command or of one of its parents with the name
+func lookupFlag(command *cli.Command, name string) cli.Flag {
+	for _, c := range command.Lineage() {
+		for _, fl := range c.Flags {
+			for _, n := range fl.Names() {
+				if n == name {
+					return fl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// secretFlags are the flags whose values are redacted from the resolved flags
+var secretFlags = map[string]bool{
+	"password": true,
+	"reg-key":  true,
+}
+
+// resolvedFlags returns the values of the flags of the command and its parents that were set on the command line,
+// by their environment variable or by the config file, keyed by flag name
+func resolvedFlags(command *cli.Command) map[string]string {
+	values := map[string]string{}
+	for _, c := range command.Lineage() {
+		for _, fl := range c.Flags {
+			name := fl.Names()[0]
+			if _, found := values[name]; found || !command.IsSet(name) {
+				continue
+			}
+			if secretFlags[name] {
+				values[name] = "REDACTED"
+				continue
+			}
+			values[name] = fmt.Sprint(command.Value(name))
+		}
+	}
+	return values
+}
+
+// collectFlags collects the flags of the command and of all its sub commands by name
+func collectFlags(command *cli.Command, flags map[string]cli.Flag) {
+	for _, fl := range command.Flags {
+		for _, n := range fl.Names() {
+			flags[n] = fl
+		}
+	}
+	for _, c := range command.Commands {
+		collectFlags(c, flags)
+	}
+}
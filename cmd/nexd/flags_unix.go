@@ -12,6 +12,7 @@ import (
 
 var stateDirDefault = "/var/lib/nexd"
 var stateDirDefaultExpression = "/var/lib/nexd"
+var configFileDefault = "/etc/nexodus/nexd.yaml"
 
 func init() {
 
@@ -4,3 +4,4 @@ package main
 
 var stateDirDefault = "C:/nexodus"
 var stateDirDefaultExpression = "C:/nexodus"
+var configFileDefault = "C:/nexodus/nexd.yaml"
@@ -0,0 +1,83 @@
+//go:build darwin
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	launchdLabel     = "io.nexodus.nexd"
+	launchdPlistPath = "/Library/LaunchDaemons/io.nexodus.nexd.plist"
+)
+
+// launchdPlist returns the launch daemon of the nexd service, launchd starts it at boot and restarts it when it
+// exits. launchd has no readiness notification, the units waiting for the overlay poll nexctl nexd status instead.
+func launchdPlist(exe string, args []string) string {
+	sb := &strings.Builder{}
+	for _, arg := range append([]string{exe}, args...) {
+		sb.WriteString("      <string>")
+		_ = xml.EscapeText(sb, []byte(arg))
+		sb.WriteString("</string>\n")
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN"
+  "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+  <dict>
+    <key>Label</key>
+    <string>%s</string>
+
+    <key>ProgramArguments</key>
+    <array>
+%s    </array>
+
+    <key>RunAtLoad</key>
+    <true/>
+
+    <key>UserName</key>
+    <string>root</string>
+
+    <key>KeepAlive</key>
+    <true/>
+
+    <key>ThrottleInterval</key>
+    <integer>5</integer>
+
+    <key>StandardOutPath</key>
+    <string>/var/log/nexd-stdout.log</string>
+
+    <key>StandardErrorPath</key>
+    <string>/var/log/nexd-stderr.log</string>
+
+    <key>EnvironmentVariables</key>
+    <dict>
+      <key>PATH</key>
+      <string>/usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin</string>
+    </dict>
+  </dict>
+</plist>
+`, launchdLabel, sb.String())
+}
+
+func installServiceUnit(exe, configPath, mode string, _, start bool) error {
+	plist := launchdPlist(exe, serviceArgs(configPath, mode))
+	if err := os.WriteFile(launchdPlistPath, []byte(plist), 0644); err != nil {
+		return fmt.Errorf("failed to write the launchd plist: %w", err)
+	}
+	// the launch daemons are loaded at boot
+	if !start {
+		fmt.Printf("Installed the %s launch daemon, start it with: launchctl bootstrap system %s\n", launchdLabel, launchdPlistPath)
+		return nil
+	}
+	// unload an already running service so that it picks up the new flags, it fails when it is not loaded
+	_ = runServiceCommand("launchctl", "bootout", "system/"+launchdLabel)
+	if err := runServiceCommand("launchctl", "bootstrap", "system", launchdPlistPath); err != nil {
+		return err
+	}
+	fmt.Printf("Installed and started the %s launch daemon, its logs are written to /var/log/nexd-stderr.log\n", launchdLabel)
+	return nil
+}
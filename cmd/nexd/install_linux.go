@@ -0,0 +1,81 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const systemdUnitPath = "/etc/systemd/system/nexodus.service"
+
+// systemdUnit returns the unit of the nexd service. nexd notifies systemd once the wireguard interface is up and
+// the peers are configured, the units ordered after it start with the overlay reachable. The device flow login
+// may wait for the user, so the start does not time out.
+func systemdUnit(exe string, args []string, autoUpdate bool) string {
+	execStart := []string{strconv.Quote(exe)}
+	for _, arg := range args {
+		execStart = append(execStart, strconv.Quote(arg))
+	}
+	hardening := ""
+	if autoUpdate {
+		// the updater replaces the binary
+		hardening = "ReadWritePaths=" + strconv.Quote(filepath.Dir(exe)) + "\n"
+	}
+	return fmt.Sprintf(`[Unit]
+Description=Nexodus connectivity daemon
+Wants=network-online.target
+After=network-online.target
+StartLimitIntervalSec=0
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+ExecReload=/bin/kill -HUP $MAINPID
+Restart=always
+RestartSec=5
+TimeoutStartSec=infinity
+User=root
+StateDirectory=nexd
+CapabilityBoundingSet=CAP_NET_ADMIN CAP_NET_RAW CAP_NET_BIND_SERVICE CAP_SYS_MODULE CAP_DAC_OVERRIDE CAP_CHOWN CAP_FOWNER
+NoNewPrivileges=true
+ProtectSystem=true
+%sProtectHome=true
+PrivateTmp=true
+ProtectControlGroups=true
+ProtectKernelLogs=true
+RestrictSUIDSGID=true
+RestrictRealtime=true
+LockPersonality=true
+
+[Install]
+WantedBy=multi-user.target
+`, strings.Join(execStart, " "), hardening)
+}
+
+func installServiceUnit(exe, configPath, mode string, autoUpdate, start bool) error {
+	unit := systemdUnit(exe, serviceArgs(configPath, mode), autoUpdate)
+	if err := os.WriteFile(systemdUnitPath, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write the systemd unit: %w", err)
+	}
+	if err := runServiceCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	if err := runServiceCommand("systemctl", "enable", "nexodus.service"); err != nil {
+		return err
+	}
+	if !start {
+		fmt.Printf("Installed the nexodus service, start it with: systemctl start nexodus\n")
+		return nil
+	}
+	// restart an already running service so that it picks up the new flags
+	if err := runServiceCommand("systemctl", "restart", "nexodus.service"); err != nil {
+		return err
+	}
+	fmt.Printf("Installed and started the nexodus service, follow its logs with: journalctl -u nexodus -f\n")
+	return nil
+}
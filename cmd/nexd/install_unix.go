@@ -0,0 +1,130 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/urfave/cli/v3"
+)
+
+// the modes of nexd a service can run as, the agent runs without a mode
+var serviceModes = []string{"proxy", "router", "relay", "relayderp"}
+
+func init() {
+	additionalPlatformCommands = append(additionalPlatformCommands, installCommand())
+}
+
+func installCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "install",
+		Usage: "Install nexd as a system service started at boot, storing the flags set before install in the config file",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "mode",
+				Usage:    "Run the service as the nexd `mode` proxy, router, relay or relayderp instead of the agent, the flags of the mode can be set in the config file (optional)",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "no-start",
+				Usage:    "Enable the service without starting it",
+				Required: false,
+			},
+		},
+		Action: func(ctx context.Context, command *cli.Command) error {
+			mode := command.String("mode")
+			if mode != "" && !slices.Contains(serviceModes, mode) {
+				return fmt.Errorf("invalid mode %q, it must be one of %s", mode, strings.Join(serviceModes, ", "))
+			}
+			if os.Geteuid() != 0 {
+				return fmt.Errorf("nexd install must run as root")
+			}
+			exe, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			exe, err = filepath.EvalSymlinks(exe)
+			if err != nil {
+				return err
+			}
+			configPath, err := filepath.Abs(command.String("config"))
+			if err != nil {
+				return err
+			}
+			if err := storeServiceFlags(command.Root(), configPath); err != nil {
+				return err
+			}
+			return installServiceUnit(exe, configPath, mode, command.Root().Bool("auto-update"), !command.Bool("no-start"))
+		},
+	}
+}
+
+// storeServiceFlags merges the flags set on the command line or by their environment variable into the config
+// file, so that the service starts with them. The config file may hold credentials, it is only readable by root.
+func storeServiceFlags(root *cli.Command, configPath string) error {
+	values := map[string]interface{}{}
+	data, err := os.ReadFile(configPath)
+	if err == nil {
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("config file %s: %w", configPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read the config file: %w", err)
+	}
+
+	for _, fl := range root.Flags {
+		name := fl.Names()[0]
+		if name == "config" || name == "help" || !root.IsSet(name) || configFileKeys[name] {
+			continue
+		}
+		switch v := root.Value(name).(type) {
+		case bool, []string:
+			values[name] = v
+		default:
+			// the other values are parsed by their flag when the config file is applied
+			values[name] = fmt.Sprint(v)
+		}
+	}
+
+	data, err = yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write the config file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(configPath, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Stored the flags of the service in %s\n", configPath)
+	return nil
+}
+
+// serviceArgs returns the arguments of nexd when the service starts it
+func serviceArgs(configPath, mode string) []string {
+	args := []string{"--config", configPath}
+	if mode != "" {
+		args = append(args, mode)
+	}
+	return args
+}
+
+func runServiceCommand(name string, args ...string) error {
+	// #nosec G204 -- the service manager commands are fixed
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceName        = "nexd"
+	serviceDisplayName = "Nexodus Agent"
+	serviceDescription = "Node agent to configure encrypted mesh networking with nexodus."
+	// how long to wait for the service to reach the state requested by nexd service start|stop
+	serviceStateTimeout = 30 * time.Second
+	// how long the service control manager waits before restarting nexd after it failed, and after how long
+	// without a failure it resets the failure count
+	serviceRestartDelay     = 5 * time.Second
+	serviceFailureResetTime = 24 * time.Hour
+)
+
+func init() {
+	additionalPlatformCommands = append(additionalPlatformCommands, serviceCommand())
+	runAsService = runWindowsService
+}
+
+func serviceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "service",
+		Usage: "Manage the nexd Windows service",
+		Commands: []*cli.Command{
+			{
+				Name:      "install",
+				Usage:     "Install nexd as a Windows service started at boot, the arguments after -- are passed to nexd",
+				ArgsUsage: "[-- nexd arguments]",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return installService(command.Args().Slice())
+				},
+			},
+			{
+				Name:  "start",
+				Usage: "Start the nexd Windows service",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return startService()
+				},
+			},
+			{
+				Name:  "stop",
+				Usage: "Stop the nexd Windows service",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return controlService(svc.Stop, svc.Stopped)
+				},
+			},
+			{
+				Name:  "uninstall",
+				Usage: "Stop and remove the nexd Windows service",
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return uninstallService()
+				},
+			},
+		},
+	}
+}
+
+func installService(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	exe, err = filepath.Abs(exe)
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager, nexd service must run as Administrator: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("the %s service is already installed, uninstall it first", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName:      serviceDisplayName,
+		Description:      serviceDescription,
+		StartType:        mgr.StartAutomatic,
+		DelayedAutoStart: true,
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to create the %s service: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	// restart nexd when it fails, including when it exits with an error
+	actions := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: serviceRestartDelay},
+		{Type: mgr.ServiceRestart, Delay: serviceRestartDelay},
+		{Type: mgr.ServiceRestart, Delay: serviceRestartDelay * 6},
+	}
+	if err := s.SetRecoveryActions(actions, uint32(serviceFailureResetTime.Seconds())); err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("failed to set the recovery actions of the %s service: %w", serviceName, err)
+	}
+	if err := s.SetRecoveryActionsOnNonCrashFailures(true); err != nil {
+		_ = s.Delete()
+		return fmt.Errorf("failed to set the recovery actions of the %s service: %w", serviceName, err)
+	}
+
+	err = eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.Contains(err.Error(), "registry key already exists") {
+		_ = s.Delete()
+		return fmt.Errorf("failed to register the %s event log source: %w", serviceName, err)
+	}
+	fmt.Printf("Installed the %s service, start it with: nexd service start\n", serviceName)
+	return nil
+}
+
+func startService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager, nexd service must run as Administrator: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("the %s service is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start the %s service: %w", serviceName, err)
+	}
+	return waitForServiceState(s, svc.Running)
+}
+
+func controlService(c svc.Cmd, to svc.State) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager, nexd service must run as Administrator: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("the %s service is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+	if _, err := s.Control(c); err != nil {
+		return fmt.Errorf("failed to control the %s service: %w", serviceName, err)
+	}
+	return waitForServiceState(s, to)
+}
+
+func waitForServiceState(s *mgr.Service, to svc.State) error {
+	deadline := time.Now().Add(serviceStateTimeout)
+	for {
+		status, err := s.Query()
+		if err != nil {
+			return fmt.Errorf("failed to query the %s service: %w", serviceName, err)
+		}
+		if status.State == to {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the %s service to reach the state %d", serviceName, to)
+		}
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service control manager, nexd service must run as Administrator: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("the %s service is not installed: %w", serviceName, err)
+	}
+	defer s.Close()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		if _, err := s.Control(svc.Stop); err == nil {
+			_ = waitForServiceState(s, svc.Stopped)
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete the %s service: %w", serviceName, err)
+	}
+	if err := eventlog.Remove(serviceName); err != nil {
+		return fmt.Errorf("failed to remove the %s event log source: %w", serviceName, err)
+	}
+	fmt.Printf("Uninstalled the %s service\n", serviceName)
+	return nil
+}
+
+// runWindowsService runs nexd under the service control manager when it was started by it, the logs are
+// written to the event log as well.
+func runWindowsService(logger *zap.Logger, run func(ctx context.Context, logger *zap.Logger) error) (bool, error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false, err
+	}
+	elog, err := eventlog.Open(serviceName)
+	if err == nil {
+		defer elog.Close()
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, newEventLogCore(elog, core))
+		}))
+	} else {
+		logger.Warn("failed to open the event log, nexd service install registers it", zap.Error(err))
+	}
+
+	handler := &serviceHandler{logger: logger, run: run}
+	if err := svc.Run(serviceName, handler); err != nil {
+		return true, err
+	}
+	return true, handler.err
+}
+
+type serviceHandler struct {
+	logger *zap.Logger
+	run    func(ctx context.Context, logger *zap.Logger) error
+	err    error
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.run(ctx, h.logger)
+	}()
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-done:
+			h.err = err
+			if err != nil {
+				h.logger.Error("nexd service failed", zap.Error(err))
+				// a service specific exit code triggers the recovery actions of the service
+				return true, 1
+			}
+			return false, 0
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				changes <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+					h.logger.Error("nexd service failed to stop", zap.Error(err))
+				}
+				return false, 0
+			}
+		}
+	}
+}
+
+// eventLogCore writes the log entries to the Windows event log, the entries are encoded like the ones of the
+// other core of the logger
+type eventLogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	elog    *eventlog.Log
+}
+
+func newEventLogCore(elog *eventlog.Log, enabler zapcore.LevelEnabler) zapcore.Core {
+	config := zap.NewProductionEncoderConfig()
+	config.TimeKey = ""
+	return &eventLogCore{
+		LevelEnabler: enabler,
+		encoder:      zapcore.NewConsoleEncoder(config),
+		elog:         elog,
+	}
+}
+
+func (c *eventLogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &eventLogCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		elog:         c.elog,
+	}
+	for _, f := range fields {
+		f.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *eventLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *eventLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSpace(buf.String())
+	buf.Free()
+	// the event ids of the messages registered by InstallAsEventCreate are 1 to 1000
+	switch {
+	case entry.Level >= zapcore.ErrorLevel:
+		return c.elog.Error(1, msg)
+	case entry.Level == zapcore.WarnLevel:
+		return c.elog.Warning(1, msg)
+	default:
+		return c.elog.Info(1, msg)
+	}
+}
+
+func (c *eventLogCore) Sync() error {
+	return nil
+}
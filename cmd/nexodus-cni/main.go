@@ -0,0 +1,340 @@
+//go:build linux
+
+// nexodus-cni is a CNI plugin attaching the pods of a Kubernetes node to the Nexodus network. The addresses of the
+// pods are allocated by the nexd of the node from its pod CIDR, see nexd router --pod-cidr, and the pods are routed
+// through a veth pair, so that nexd forwards the traffic of the peers to the pods over the WireGuard mesh.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc/jsonrpc"
+	"os"
+	"runtime"
+
+	"github.com/nexodus-io/nexodus/internal/api"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+const (
+	defaultMTU = 1420
+	// the pods route through a link-local gateway answered by proxy ARP on the host side of their veth pair
+	podGateway = "169.254.1.1"
+
+	// the CNI error codes, see the CNI specification
+	errCodeIncompatibleVersion = 1
+	errCodeInvalidEnv          = 4
+	errCodeDecodingFailure     = 6
+	errCodeTryAgainLater       = 11
+	errCodeInternal            = 999
+)
+
+var supportedVersions = []string{"0.4.0", "1.0.0"}
+
+// netConf is the network configuration given to the plugin on its stdin
+type netConf struct {
+	CNIVersion string `json:"cniVersion"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	// the path of the unix socket of the nexd of this node
+	Socket string `json:"socket,omitempty"`
+	MTU    int    `json:"mtu,omitempty"`
+}
+
+// podAddress is the address nexd allocated to a pod
+type podAddress struct {
+	Address string `json:"address"`
+	PodCIDR string `json:"pod-cidr"`
+}
+
+type cniError struct {
+	CNIVersion string `json:"cniVersion"`
+	Code       int    `json:"code"`
+	Msg        string `json:"msg"`
+	Details    string `json:"details,omitempty"`
+}
+
+func (e *cniError) Error() string {
+	return e.Msg
+}
+
+type cniInterface struct {
+	Name    string `json:"name"`
+	Mac     string `json:"mac,omitempty"`
+	Sandbox string `json:"sandbox,omitempty"`
+}
+
+type cniIP struct {
+	Address   string `json:"address"`
+	Gateway   string `json:"gateway,omitempty"`
+	Interface *int   `json:"interface,omitempty"`
+	Version   string `json:"version,omitempty"` // 0.4.0 only
+}
+
+type cniRoute struct {
+	Dst string `json:"dst"`
+	GW  string `json:"gw,omitempty"`
+}
+
+type cniResult struct {
+	CNIVersion string         `json:"cniVersion"`
+	Interfaces []cniInterface `json:"interfaces"`
+	IPs        []cniIP        `json:"ips"`
+	Routes     []cniRoute     `json:"routes"`
+}
+
+func main() {
+	// the network namespace of the pod is entered by the current thread
+	runtime.LockOSThread()
+
+	err := run(os.Getenv("CNI_COMMAND"), os.Stdin, os.Stdout)
+	if err == nil {
+		return
+	}
+	var e *cniError
+	if !errors.As(err, &e) {
+		e = &cniError{Code: errCodeInternal, Msg: err.Error()}
+	}
+	if e.CNIVersion == "" {
+		e.CNIVersion = supportedVersions[len(supportedVersions)-1]
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(e)
+	os.Exit(1)
+}
+
+func run(command string, stdin io.Reader, stdout io.Writer) error {
+	if command == "VERSION" {
+		return json.NewEncoder(stdout).Encode(map[string]interface{}{
+			"cniVersion":        supportedVersions[len(supportedVersions)-1],
+			"supportedVersions": supportedVersions,
+		})
+	}
+
+	conf := netConf{}
+	if err := json.NewDecoder(stdin).Decode(&conf); err != nil {
+		return &cniError{Code: errCodeDecodingFailure, Msg: "failed to decode the network configuration", Details: err.Error()}
+	}
+	if !supportedVersion(conf.CNIVersion) {
+		return &cniError{Code: errCodeIncompatibleVersion, Msg: fmt.Sprintf("unsupported CNI version %s", conf.CNIVersion)}
+	}
+	if conf.Socket == "" {
+		conf.Socket = api.UnixSocketPath
+	}
+	if conf.MTU == 0 {
+		conf.MTU = defaultMTU
+	}
+	containerID, ifName, netnsPath := os.Getenv("CNI_CONTAINERID"), os.Getenv("CNI_IFNAME"), os.Getenv("CNI_NETNS")
+	if containerID == "" {
+		return &cniError{CNIVersion: conf.CNIVersion, Code: errCodeInvalidEnv, Msg: "CNI_CONTAINERID is required"}
+	}
+
+	switch command {
+	case "ADD":
+		if ifName == "" || netnsPath == "" {
+			return &cniError{CNIVersion: conf.CNIVersion, Code: errCodeInvalidEnv, Msg: "CNI_IFNAME and CNI_NETNS are required"}
+		}
+		result, err := cmdAdd(conf, containerID, ifName, netnsPath)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(stdout).Encode(result)
+	case "DEL":
+		return cmdDel(conf, containerID)
+	case "CHECK":
+		return cmdCheck(containerID)
+	default:
+		return &cniError{CNIVersion: conf.CNIVersion, Code: errCodeInvalidEnv, Msg: fmt.Sprintf("unsupported CNI_COMMAND %q", command)}
+	}
+}
+
+func supportedVersion(version string) bool {
+	for _, v := range supportedVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// hostVethName returns the name of the host side of the veth pair of a container, stable across the CNI commands
+func hostVethName(containerID string) string {
+	sum := sha256.Sum256([]byte(containerID))
+	return "nex" + hex.EncodeToString(sum[:])[:11]
+}
+
+func cmdAdd(conf netConf, containerID, ifName, netnsPath string) (*cniResult, error) {
+	response, err := callNexd(conf.Socket, "CniAdd", containerID)
+	if err != nil {
+		return nil, &cniError{CNIVersion: conf.CNIVersion, Code: errCodeTryAgainLater, Msg: "failed to allocate the address of the pod from nexd", Details: err.Error()}
+	}
+	allocated := podAddress{}
+	if err := json.Unmarshal([]byte(response), &allocated); err != nil {
+		return nil, fmt.Errorf("failed to decode the address of the pod: %w", err)
+	}
+	address, err := netlink.ParseAddr(allocated.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod address %s: %w", allocated.Address, err)
+	}
+
+	podNs, err := netns.GetFromPath(netnsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the network namespace %s: %w", netnsPath, err)
+	}
+	defer podNs.Close()
+
+	hostName := hostVethName(containerID)
+	if link, err := netlink.LinkByName(hostName); err == nil {
+		// a previous ADD of the container failed halfway
+		_ = netlink.LinkDel(link)
+	}
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: hostName, MTU: conf.MTU},
+		PeerName:  "tmp" + hostName[3:],
+	}
+	if err := netlink.LinkAdd(veth); err != nil {
+		return nil, fmt.Errorf("failed to create the veth pair of the pod: %w", err)
+	}
+	success := false
+	defer func() {
+		if !success {
+			_ = netlink.LinkDel(veth)
+		}
+	}()
+	peer, err := netlink.LinkByName(veth.PeerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := netlink.LinkSetNsFd(peer, int(podNs)); err != nil {
+		return nil, fmt.Errorf("failed to move the veth of the pod into its network namespace: %w", err)
+	}
+
+	podMac, err := configurePodInterface(podNs, veth.PeerName, ifName, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// the host answers the ARP requests of the pod for its gateway and routes the pod address to the veth
+	host, err := netlink.LinkByName(hostName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/proxy_arp", hostName), []byte("1"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to enable proxy arp on %s: %w", hostName, err)
+	}
+	if err := netlink.LinkSetUp(host); err != nil {
+		return nil, err
+	}
+	if err := netlink.RouteReplace(&netlink.Route{
+		LinkIndex: host.Attrs().Index,
+		Dst:       address.IPNet,
+		Scope:     netlink.SCOPE_LINK,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to route the pod address %s to %s: %w", address.IPNet, hostName, err)
+	}
+	success = true
+
+	podIndex := 1
+	result := &cniResult{
+		CNIVersion: conf.CNIVersion,
+		Interfaces: []cniInterface{
+			{Name: hostName, Mac: host.Attrs().HardwareAddr.String()},
+			{Name: ifName, Mac: podMac, Sandbox: netnsPath},
+		},
+		IPs:    []cniIP{{Address: address.IPNet.String(), Gateway: podGateway, Interface: &podIndex}},
+		Routes: []cniRoute{{Dst: "0.0.0.0/0", GW: podGateway}},
+	}
+	if conf.CNIVersion == "0.4.0" {
+		result.IPs[0].Version = "4"
+	}
+	return result, nil
+}
+
+// configurePodInterface renames the veth of the pod, assigns its address and routes through the gateway
+func configurePodInterface(podNs netns.NsHandle, tmpName, ifName string, address *netlink.Addr) (string, error) {
+	hostNs, err := netns.Get()
+	if err != nil {
+		return "", err
+	}
+	defer hostNs.Close()
+	if err := netns.Set(podNs); err != nil {
+		return "", fmt.Errorf("failed to enter the network namespace of the pod: %w", err)
+	}
+	defer func() {
+		_ = netns.Set(hostNs)
+	}()
+
+	link, err := netlink.LinkByName(tmpName)
+	if err != nil {
+		return "", err
+	}
+	if err := netlink.LinkSetName(link, ifName); err != nil {
+		return "", fmt.Errorf("failed to rename the veth of the pod to %s: %w", ifName, err)
+	}
+	if err := netlink.AddrAdd(link, address); err != nil {
+		return "", fmt.Errorf("failed to assign the address %s to the pod: %w", address, err)
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return "", err
+	}
+	gateway := net.ParseIP(podGateway)
+	if err := netlink.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: gateway, Mask: net.CIDRMask(32, 32)},
+		Scope:     netlink.SCOPE_LINK,
+	}); err != nil {
+		return "", fmt.Errorf("failed to route the gateway of the pod: %w", err)
+	}
+	if err := netlink.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Gw:        gateway,
+	}); err != nil {
+		return "", fmt.Errorf("failed to add the default route of the pod: %w", err)
+	}
+	return link.Attrs().HardwareAddr.String(), nil
+}
+
+func cmdDel(conf netConf, containerID string) error {
+	// deleting the host side deletes the pod side of the veth pair, DEL must succeed when it was already deleted
+	if link, err := netlink.LinkByName(hostVethName(containerID)); err == nil {
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("failed to delete the veth pair of the pod: %w", err)
+		}
+	}
+	if _, err := callNexd(conf.Socket, "CniDel", containerID); err != nil {
+		return &cniError{CNIVersion: conf.CNIVersion, Code: errCodeTryAgainLater, Msg: "failed to release the address of the pod in nexd", Details: err.Error()}
+	}
+	return nil
+}
+
+func cmdCheck(containerID string) error {
+	link, err := netlink.LinkByName(hostVethName(containerID))
+	if err != nil {
+		return fmt.Errorf("the veth pair of the pod is missing: %w", err)
+	}
+	if link.Attrs().OperState == netlink.OperDown {
+		return fmt.Errorf("the veth %s of the pod is down", link.Attrs().Name)
+	}
+	return nil
+}
+
+// callNexd calls a method of the control socket of nexd
+func callNexd(socket, method, arg string) (string, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to nexd: %w", err)
+	}
+	defer conn.Close()
+
+	client := jsonrpc.NewClient(conn)
+	var result string
+	if err := client.Call("NexdCtl."+method, arg, &result); err != nil {
+		return "", fmt.Errorf("failed to execute method (%s): %w", method, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,348 @@
+//go:build linux
+
+// nexodus-docker is a Docker network plugin providing the "nexodus" network driver. The addresses of the containers
+// attached to a nexodus network are allocated by the local nexd from its pod CIDR, see nexd router --pod-cidr, and the
+// containers are routed through a veth pair, so that nexd forwards the traffic of the peers to them over the
+// WireGuard mesh.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/nexodus-io/nexodus/internal/api"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	driverName      = "nexodus"
+	pluginMediaType = "application/vnd.docker.plugins.v1.2+json"
+	defaultMTU      = 1420
+	// the containers route through a link-local gateway answered by proxy ARP on the host side of their veth pair
+	containerGateway = "169.254.1.1"
+	// the route type of a static route to a directly connected destination, see the libnetwork remote driver API
+	routeTypeConnected = 1
+)
+
+// the pool docker gives to the network driver when the network is created with --ipam-driver null
+const nullIPAMPool = "0.0.0.0/0"
+
+type errorResponse struct {
+	Err string
+}
+
+type ipamData struct {
+	AddressSpace string
+	Pool         string
+	Gateway      string
+}
+
+type createNetworkRequest struct {
+	NetworkID string
+	Options   map[string]interface{}
+	IPv4Data  []ipamData
+	IPv6Data  []ipamData
+}
+
+type endpointInterface struct {
+	Address     string `json:",omitempty"`
+	AddressIPv6 string `json:",omitempty"`
+	MacAddress  string `json:",omitempty"`
+}
+
+type createEndpointRequest struct {
+	NetworkID  string
+	EndpointID string
+	Interface  *endpointInterface
+	Options    map[string]interface{}
+}
+
+type createEndpointResponse struct {
+	Interface *endpointInterface `json:",omitempty"`
+}
+
+type endpointRequest struct {
+	NetworkID  string
+	EndpointID string
+}
+
+type joinRequest struct {
+	NetworkID  string
+	EndpointID string
+	SandboxKey string
+	Options    map[string]interface{}
+}
+
+type interfaceName struct {
+	SrcName   string
+	DstPrefix string
+}
+
+type staticRoute struct {
+	Destination string
+	RouteType   int
+	NextHop     string `json:",omitempty"`
+}
+
+type joinResponse struct {
+	InterfaceName interfaceName
+	Gateway       string
+	StaticRoutes  []staticRoute
+}
+
+// containerAddress is the address nexd allocated to a container
+type containerAddress struct {
+	Address string `json:"address"`
+	PodCIDR string `json:"pod-cidr"`
+}
+
+// driver implements the libnetwork remote network driver API
+type driver struct {
+	socket string
+	mtu    int
+}
+
+func main() {
+	pluginSocket := flag.String("plugin-socket", filepath.Join("/run/docker/plugins", driverName+".sock"), "the unix socket docker discovers the plugin on")
+	socket := flag.String("socket", api.UnixSocketPath, "the unix socket of the nexd of this host")
+	mtu := flag.Int("mtu", defaultMTU, "the MTU of the interface of the containers")
+	flag.Parse()
+
+	d := &driver{socket: *socket, mtu: *mtu}
+	if err := d.serve(*pluginSocket); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve handles the requests of docker on the plugin socket until the process is signaled
+func (d *driver) serve(pluginSocket string) error {
+	if err := os.MkdirAll(filepath.Dir(pluginSocket), 0755); err != nil {
+		return err
+	}
+	// a previous run of the plugin left its socket behind
+	_ = os.Remove(pluginSocket)
+	l, err := net.Listen("unix", pluginSocket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", pluginSocket, err)
+	}
+	defer os.Remove(pluginSocket)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		_ = l.Close()
+	}()
+
+	log.Printf("Serving the %s network driver on %s", driverName, pluginSocket)
+	err = http.Serve(l, d.handler())
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func (d *driver) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, map[string][]string{"Implements": {"NetworkDriver"}})
+	})
+	mux.HandleFunc("/NetworkDriver.GetCapabilities", func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, map[string]string{"Scope": "local", "ConnectivityScope": "global"})
+	})
+	mux.HandleFunc("/NetworkDriver.CreateNetwork", handle(d.createNetwork))
+	mux.HandleFunc("/NetworkDriver.CreateEndpoint", handle(d.createEndpoint))
+	mux.HandleFunc("/NetworkDriver.Join", handle(d.join))
+	mux.HandleFunc("/NetworkDriver.Leave", handle(d.leave))
+	mux.HandleFunc("/NetworkDriver.DeleteEndpoint", handle(d.deleteEndpoint))
+	mux.HandleFunc("/NetworkDriver.EndpointOperInfo", func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, map[string]interface{}{"Value": map[string]interface{}{}})
+	})
+	// the network state is kept by nexd, and the containers leave the host through its routes
+	for _, method := range []string{"DeleteNetwork", "DiscoverNew", "DiscoverDelete", "ProgramExternalConnectivity", "RevokeExternalConnectivity"} {
+		mux.HandleFunc("/NetworkDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			writeResponse(w, struct{}{})
+		})
+	}
+	return mux
+}
+
+// handle decodes the request of docker and encodes the response of the driver, or its error
+func handle[Req any](fn func(Req) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode the request: %w", err))
+			return
+		}
+		res, err := fn(req)
+		if err != nil {
+			log.Printf("%s failed: %v", r.URL.Path, err)
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeResponse(w, res)
+	}
+}
+
+func writeResponse(w http.ResponseWriter, res interface{}) {
+	w.Header().Set("Content-Type", pluginMediaType)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", pluginMediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{Err: err.Error()})
+}
+
+func (d *driver) createNetwork(req createNetworkRequest) (interface{}, error) {
+	for _, data := range req.IPv4Data {
+		if data.Pool != "" && data.Pool != nullIPAMPool {
+			return nil, fmt.Errorf("the addresses of a %s network are allocated by nexd, create the network with --ipam-driver null", driverName)
+		}
+	}
+	if len(req.IPv6Data) > 0 {
+		return nil, fmt.Errorf("a %s network does not support IPv6", driverName)
+	}
+	return struct{}{}, nil
+}
+
+func (d *driver) createEndpoint(req createEndpointRequest) (interface{}, error) {
+	if req.Interface != nil && (req.Interface.Address != "" || req.Interface.AddressIPv6 != "") {
+		return nil, fmt.Errorf("the addresses of a %s network are allocated by nexd, create the network with --ipam-driver null", driverName)
+	}
+	address, err := d.allocate(req.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	return createEndpointResponse{Interface: &endpointInterface{Address: address.IPNet.String()}}, nil
+}
+
+// allocate returns the address of the endpoint, nexd returns the same address for every call until it is released
+func (d *driver) allocate(endpointID string) (*netlink.Addr, error) {
+	response, err := callNexd(d.socket, "CniAdd", endpointID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate the address of the container from nexd: %w", err)
+	}
+	allocated := containerAddress{}
+	if err := json.Unmarshal([]byte(response), &allocated); err != nil {
+		return nil, fmt.Errorf("failed to decode the address of the container: %w", err)
+	}
+	address, err := netlink.ParseAddr(allocated.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container address %s: %w", allocated.Address, err)
+	}
+	return address, nil
+}
+
+// hostVethName returns the name of the host side of the veth pair of an endpoint
+func hostVethName(endpointID string) string {
+	sum := sha256.Sum256([]byte(endpointID))
+	return "nxd" + hex.EncodeToString(sum[:])[:11]
+}
+
+// join creates the veth pair of the endpoint, docker moves its container side into the container and assigns
+// it the address of the endpoint, the gateway and the static routes of the response.
+func (d *driver) join(req joinRequest) (interface{}, error) {
+	address, err := d.allocate(req.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	hostName := hostVethName(req.EndpointID)
+	deleteVeth(hostName)
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: hostName, MTU: d.mtu},
+		PeerName:  "tmp" + hostName[3:],
+	}
+	if err := netlink.LinkAdd(veth); err != nil {
+		return nil, fmt.Errorf("failed to create the veth pair of the container: %w", err)
+	}
+	success := false
+	defer func() {
+		if !success {
+			_ = netlink.LinkDel(veth)
+		}
+	}()
+	peer, err := netlink.LinkByName(veth.PeerName)
+	if err != nil {
+		return nil, err
+	}
+	if err := netlink.LinkSetMTU(peer, d.mtu); err != nil {
+		return nil, err
+	}
+
+	// the host answers the ARP requests of the container for its gateway and routes the container address to the veth
+	host, err := netlink.LinkByName(hostName)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(fmt.Sprintf("/proc/sys/net/ipv4/conf/%s/proxy_arp", hostName), []byte("1"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to enable proxy arp on %s: %w", hostName, err)
+	}
+	if err := netlink.LinkSetUp(host); err != nil {
+		return nil, err
+	}
+	if err := netlink.RouteReplace(&netlink.Route{
+		LinkIndex: host.Attrs().Index,
+		Dst:       address.IPNet,
+		Scope:     netlink.SCOPE_LINK,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to route the container address %s to %s: %w", address.IPNet, hostName, err)
+	}
+	success = true
+
+	return joinResponse{
+		InterfaceName: interfaceName{SrcName: veth.PeerName, DstPrefix: "eth"},
+		Gateway:       containerGateway,
+		StaticRoutes:  []staticRoute{{Destination: containerGateway + "/32", RouteType: routeTypeConnected}},
+	}, nil
+}
+
+func (d *driver) leave(req endpointRequest) (interface{}, error) {
+	deleteVeth(hostVethName(req.EndpointID))
+	return struct{}{}, nil
+}
+
+func (d *driver) deleteEndpoint(req endpointRequest) (interface{}, error) {
+	deleteVeth(hostVethName(req.EndpointID))
+	if _, err := callNexd(d.socket, "CniDel", req.EndpointID); err != nil {
+		return nil, fmt.Errorf("failed to release the address of the container in nexd: %w", err)
+	}
+	return struct{}{}, nil
+}
+
+// deleteVeth deletes the veth pair of an endpoint if it exists, deleting the host side deletes the container side
+func deleteVeth(hostName string) {
+	if link, err := netlink.LinkByName(hostName); err == nil {
+		_ = netlink.LinkDel(link)
+	}
+}
+
+// callNexd calls a method of the control socket of nexd
+func callNexd(socket, method, arg string) (string, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to nexd: %w", err)
+	}
+	defer conn.Close()
+
+	client := jsonrpc.NewClient(conn)
+	var result string
+	if err := client.Call("NexdCtl."+method, arg, &result); err != nil {
+		return "", fmt.Errorf("failed to execute method (%s): %w", method, err)
+	}
+	return result, nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -538,13 +539,10 @@ func TestHubVPC(t *testing.T) {
 	)
 	require.NoError(err)
 
-	// this is probably more time than needed for convergence as polling is currently 5s
-	time.Sleep(time.Second * 10)
-
-	// verify the deleted device details are no longer in a device's tables
-	node2routes := helper.routesDumpV4(ctx, node2)
-
-	require.NotContainsf(node2routes, node3IP, "found deleted device node still in routing tables of a device")
+	// device deletes are streamed to the peers, so the routes should be removed promptly
+	require.Eventuallyf(func() bool {
+		return !strings.Contains(helper.routesDumpV4(ctx, node2), node3IP)
+	}, 10*time.Second, 250*time.Millisecond, "found deleted device node still in routing tables of a device")
 }
 
 // TestAdvertiseCidr tests requesting a specific address in a newly created vpc for v4 and v6. This will start nexd three
@@ -1100,6 +1098,53 @@ func TestV6Disabled(t *testing.T) {
 	require.NoError(err)
 }
 
+// TestDisableV6Flag validate that a node that supports ipv6 provisions with v4 only when started with --disable-v6
+func TestDisableV6Flag(t *testing.T) {
+	t.Parallel()
+	helper := NewHelper(t)
+	require := helper.require
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	password := "floofykittens"
+	username, cleanup := helper.createNewUser(ctx, password)
+	defer cleanup()
+	// create the nodes
+	node1, stop := helper.CreateNode(ctx, "node1", []string{defaultNetwork}, enableV6)
+	defer stop()
+	node2, stop := helper.CreateNode(ctx, "node2", []string{defaultNetwork}, enableV6)
+	defer stop()
+
+	// start nexodus on the nodes
+	helper.runNexd(ctx, node1, "--username", username, "--password", password, "relay")
+	err := helper.nexdStatus(ctx, node1)
+	require.NoError(err)
+
+	// node2 supports ipv6 but explicitly runs in IPv4 only mode
+	helper.runNexd(ctx, node2, "--username", username, "--password", password, "--disable-v6")
+	err = helper.nexdStatus(ctx, node2)
+	require.NoError(err)
+
+	node1IP, err := getContainerIfaceIP(ctx, inetV4, "wg0", node1)
+	require.NoError(err)
+	node2IP, err := getContainerIfaceIP(ctx, inetV4, "wg0", node2)
+	require.NoError(err)
+
+	// node1 is assigned an IPv6 tunnel address, node2 is not
+	_, err = getContainerIfaceIP(ctx, inetV6, "wg0", node1)
+	require.NoError(err)
+	_, err = getContainerIfaceIPNoRetry(ctx, inetV6, "wg0", node2)
+	require.Error(err)
+
+	helper.Logf("Pinging %s from node1", node2IP)
+	err = ping(ctx, node1, inetV4, node2IP)
+	require.NoError(err)
+
+	helper.Logf("Pinging %s from node2", node1IP)
+	err = ping(ctx, node2, inetV4, node1IP)
+	require.NoError(err)
+}
+
 func TestConnectivityUsingWireguardGo(t *testing.T) {
 	t.Parallel()
 	helper := NewHelper(t)
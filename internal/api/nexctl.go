@@ -13,3 +13,18 @@ type KeepaliveStatus struct {
 	Latency     string `json:""`
 	Method      string `json:"method"`
 }
+
+const (
+	DoctorOK   = "ok"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+	DoctorSkip = "skip"
+)
+
+// DoctorFinding is the result of one of the checks of nexctl nexd doctor
+type DoctorFinding struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"` // one of the Doctor* statuses
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"` // what to do about a warning or a failure
+}
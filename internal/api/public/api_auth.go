@@ -331,25 +331,676 @@ func (a *AuthApiService) ClaimsExecute(r ApiClaimsRequest) (map[string]interface
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
+type ApiDeviceAuthorizeRequest struct {
+	ctx        context.Context
+	ApiService *AuthApiService
+	scope      *string
+	provider   *string
+}
+
+// scopes of the tokens of the device login
+func (r ApiDeviceAuthorizeRequest) Scope(scope string) ApiDeviceAuthorizeRequest {
+	r.scope = &scope
+	return r
+}
+
+// name of the identity provider of the device login, see /web/providers
+func (r ApiDeviceAuthorizeRequest) Provider(provider string) ApiDeviceAuthorizeRequest {
+	r.provider = &provider
+	return r
+}
+
+func (r ApiDeviceAuthorizeRequest) Execute() (*ModelsDeviceAuthorizationResponse, *http.Response, error) {
+	return r.ApiService.DeviceAuthorizeExecute(r)
+}
+
+/*
+DeviceAuthorize Authorize Device Login
+
+Requests a device login from the device authorization endpoint of the provider, for the devices that log in through the service rather than the provider. Responds like the device authorization endpoint of the provider.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiDeviceAuthorizeRequest
+*/
+func (a *AuthApiService) DeviceAuthorize(ctx context.Context) ApiDeviceAuthorizeRequest {
+	return ApiDeviceAuthorizeRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+//
+//	@return ModelsDeviceAuthorizationResponse
+func (a *AuthApiService) DeviceAuthorizeExecute(r ApiDeviceAuthorizeRequest) (*ModelsDeviceAuthorizationResponse, *http.Response, error) {
+	var (
+		localVarHTTPMethod  = http.MethodPost
+		localVarPostBody    interface{}
+		formFiles           []formFile
+		localVarReturnValue *ModelsDeviceAuthorizationResponse
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.DeviceAuthorize")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/device/login/authorize"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	if r.provider != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "provider", r.provider, "")
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/x-www-form-urlencoded"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.scope != nil {
+		parameterAddToHeaderOrQuery(localVarFormParams, "scope", r.scope, "")
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ModelsDeviceTokenError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 502 {
+			var v ModelsDeviceTokenError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type ApiDevicePollRequest struct {
+	ctx          context.Context
+	ApiService   *AuthApiService
+	grantType    *string
+	deviceCode   *string
+	refreshToken *string
+	provider     *string
+}
+
+// urn:ietf:params:oauth:grant-type:device_code, the default, or refresh_token
+func (r ApiDevicePollRequest) GrantType(grantType string) ApiDevicePollRequest {
+	r.grantType = &grantType
+	return r
+}
+
+// device code of the device authorization response, required by the device_code grant type
+func (r ApiDevicePollRequest) DeviceCode(deviceCode string) ApiDevicePollRequest {
+	r.deviceCode = &deviceCode
+	return r
+}
+
+// refresh token of the device login, required by the refresh_token grant type
+func (r ApiDevicePollRequest) RefreshToken(refreshToken string) ApiDevicePollRequest {
+	r.refreshToken = &refreshToken
+	return r
+}
+
+// name of the identity provider of the device login, see /web/providers
+func (r ApiDevicePollRequest) Provider(provider string) ApiDevicePollRequest {
+	r.provider = &provider
+	return r
+}
+
+func (r ApiDevicePollRequest) Execute() (*ModelsDeviceTokenResponse, *http.Response, error) {
+	return r.ApiService.DevicePollExecute(r)
+}
+
+/*
+DevicePoll Poll Device Login
+
+Polls the token endpoint of the provider for the tokens of a device login, for the devices that poll through the service rather than the provider. Responds like the token endpoint of the provider, with an authorization_pending error when the user has not authorized the device during the poll. With the refresh_token grant type, refreshes the tokens of a device login instead. The id tokens of the responses are verified by the service.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiDevicePollRequest
+*/
+func (a *AuthApiService) DevicePoll(ctx context.Context) ApiDevicePollRequest {
+	return ApiDevicePollRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+//
+//	@return ModelsDeviceTokenResponse
+func (a *AuthApiService) DevicePollExecute(r ApiDevicePollRequest) (*ModelsDeviceTokenResponse, *http.Response, error) {
+	var (
+		localVarHTTPMethod  = http.MethodPost
+		localVarPostBody    interface{}
+		formFiles           []formFile
+		localVarReturnValue *ModelsDeviceTokenResponse
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.DevicePoll")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/device/login/poll"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	if r.provider != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "provider", r.provider, "")
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{"application/x-www-form-urlencoded"}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	if r.grantType != nil {
+		parameterAddToHeaderOrQuery(localVarFormParams, "grant_type", r.grantType, "")
+	}
+	if r.deviceCode != nil {
+		parameterAddToHeaderOrQuery(localVarFormParams, "device_code", r.deviceCode, "")
+	}
+	if r.refreshToken != nil {
+		parameterAddToHeaderOrQuery(localVarFormParams, "refresh_token", r.refreshToken, "")
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ModelsDeviceTokenError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 502 {
+			var v ModelsDeviceTokenError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 type ApiDeviceStartRequest struct {
 	ctx        context.Context
 	ApiService *AuthApiService
+	provider   *string
+}
+
+// name of the identity provider to log in with, see /web/providers
+func (r ApiDeviceStartRequest) Provider(provider string) ApiDeviceStartRequest {
+	r.provider = &provider
+	return r
+}
+
+func (r ApiDeviceStartRequest) Execute() (*ModelsDeviceStartResponse, *http.Response, error) {
+	return r.ApiService.DeviceStartExecute(r)
+}
+
+/*
+DeviceStart Start Login
+
+Starts a device login request
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiDeviceStartRequest
+*/
+func (a *AuthApiService) DeviceStart(ctx context.Context) ApiDeviceStartRequest {
+	return ApiDeviceStartRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+//
+//	@return ModelsDeviceStartResponse
+func (a *AuthApiService) DeviceStartExecute(r ApiDeviceStartRequest) (*ModelsDeviceStartResponse, *http.Response, error) {
+	var (
+		localVarHTTPMethod  = http.MethodPost
+		localVarPostBody    interface{}
+		formFiles           []formFile
+		localVarReturnValue *ModelsDeviceStartResponse
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.DeviceStart")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/device/login/start"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	if r.provider != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "provider", r.provider, "")
+	}
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
+type ApiLogoutRequest struct {
+	ctx        context.Context
+	ApiService *AuthApiService
+	redirect   *string
+	csrfToken  *string
+}
+
+// URL to redirect to after logout
+func (r ApiLogoutRequest) Redirect(redirect string) ApiLogoutRequest {
+	r.redirect = &redirect
+	return r
+}
+
+// CSRF token, see /web/csrf_token
+func (r ApiLogoutRequest) CsrfToken(csrfToken string) ApiLogoutRequest {
+	r.csrfToken = &csrfToken
+	return r
+}
+
+func (r ApiLogoutRequest) Execute() (*http.Response, error) {
+	return r.ApiService.LogoutExecute(r)
+}
+
+/*
+Logout Generate Logout URL
+
+Revokes the tokens of the current user and provides the URL to initiate the logout process for the user.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiLogoutRequest
+*/
+func (a *AuthApiService) Logout(ctx context.Context) ApiLogoutRequest {
+	return ApiLogoutRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+func (a *AuthApiService) LogoutExecute(r ApiLogoutRequest) (*http.Response, error) {
+	var (
+		localVarHTTPMethod = http.MethodGet
+		localVarPostBody   interface{}
+		formFiles          []formFile
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.Logout")
+	if err != nil {
+		return nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/web/logout"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.redirect == nil {
+		return nil, reportError("redirect is required and must be specified")
+	}
+	if r.csrfToken == nil {
+		return nil, reportError("csrfToken is required and must be specified")
+	}
+
+	parameterAddToHeaderOrQuery(localVarQueryParams, "redirect", r.redirect, "")
+	parameterAddToHeaderOrQuery(localVarQueryParams, "csrf_token", r.csrfToken, "")
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		if localVarHTTPResponse.StatusCode == 302 {
+			var v string
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
+}
+
+type ApiRefreshRequest struct {
+	ctx        context.Context
+	ApiService *AuthApiService
+	xCSRFToken *string
+}
+
+// CSRF token, see /web/csrf_token
+func (r ApiRefreshRequest) XCSRFToken(xCSRFToken string) ApiRefreshRequest {
+	r.xCSRFToken = &xCSRFToken
+	return r
+}
+
+func (r ApiRefreshRequest) Execute() (*http.Response, error) {
+	return r.ApiService.RefreshExecute(r)
+}
+
+/*
+Refresh Refresh Access Token
+
+Obtains and updates a new access token for the user.
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiRefreshRequest
+*/
+func (a *AuthApiService) Refresh(ctx context.Context) ApiRefreshRequest {
+	return ApiRefreshRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+func (a *AuthApiService) RefreshExecute(r ApiRefreshRequest) (*http.Response, error) {
+	var (
+		localVarHTTPMethod = http.MethodPost
+		localVarPostBody   interface{}
+		formFiles          []formFile
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.Refresh")
+	if err != nil {
+		return nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/web/refresh"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+	if r.xCSRFToken == nil {
+		return nil, reportError("xCSRFToken is required and must be specified")
+	}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		return localVarHTTPResponse, newErr
+	}
+
+	return localVarHTTPResponse, nil
 }
 
-func (r ApiDeviceStartRequest) Execute() (*ModelsDeviceStartResponse, *http.Response, error) {
-	return r.ApiService.DeviceStartExecute(r)
+type ApiServiceAccountTokenRequest struct {
+	ctx          context.Context
+	ApiService   *AuthApiService
+	grantType    *string
+	clientId     *string
+	clientSecret *string
+}
+
+// client_credentials
+func (r ApiServiceAccountTokenRequest) GrantType(grantType string) ApiServiceAccountTokenRequest {
+	r.grantType = &grantType
+	return r
+}
+
+// ID of the service account
+func (r ApiServiceAccountTokenRequest) ClientId(clientId string) ApiServiceAccountTokenRequest {
+	r.clientId = &clientId
+	return r
+}
+
+// client secret of the service account
+func (r ApiServiceAccountTokenRequest) ClientSecret(clientSecret string) ApiServiceAccountTokenRequest {
+	r.clientSecret = &clientSecret
+	return r
+}
+
+func (r ApiServiceAccountTokenRequest) Execute() (*ModelsServiceAccountToken, *http.Response, error) {
+	return r.ApiService.ServiceAccountTokenExecute(r)
 }
 
 /*
-DeviceStart Start Login
+ServiceAccountToken Service Account Token
 
-Starts a device login request
+Issues an access token to the client credentials of a service account (the client credentials grant of RFC 6749), the client id is the ID of the service account. The credentials are sent with HTTP basic authentication or in the form.
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
-	@return ApiDeviceStartRequest
+	@return ApiServiceAccountTokenRequest
 */
-func (a *AuthApiService) DeviceStart(ctx context.Context) ApiDeviceStartRequest {
-	return ApiDeviceStartRequest{
+func (a *AuthApiService) ServiceAccountToken(ctx context.Context) ApiServiceAccountTokenRequest {
+	return ApiServiceAccountTokenRequest{
 		ApiService: a,
 		ctx:        ctx,
 	}
@@ -357,25 +1008,28 @@ func (a *AuthApiService) DeviceStart(ctx context.Context) ApiDeviceStartRequest
 
 // Execute executes the request
 //
-//	@return ModelsDeviceStartResponse
-func (a *AuthApiService) DeviceStartExecute(r ApiDeviceStartRequest) (*ModelsDeviceStartResponse, *http.Response, error) {
+//	@return ModelsServiceAccountToken
+func (a *AuthApiService) ServiceAccountTokenExecute(r ApiServiceAccountTokenRequest) (*ModelsServiceAccountToken, *http.Response, error) {
 	var (
 		localVarHTTPMethod  = http.MethodPost
 		localVarPostBody    interface{}
 		formFiles           []formFile
-		localVarReturnValue *ModelsDeviceStartResponse
+		localVarReturnValue *ModelsServiceAccountToken
 	)
 
-	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.DeviceStart")
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.ServiceAccountToken")
 	if err != nil {
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/device/login/start"
+	localVarPath := localBasePath + "/device/token"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
+	if r.grantType == nil {
+		return localVarReturnValue, nil, reportError("grantType is required and must be specified")
+	}
 
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
@@ -416,6 +1070,38 @@ func (a *AuthApiService) DeviceStartExecute(r ApiDeviceStartRequest) (*ModelsDev
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v map[string]string
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 401 {
+			var v map[string]string
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 500 {
+			var v ModelsInternalServerError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+		}
 		return localVarReturnValue, localVarHTTPResponse, newErr
 	}
 
@@ -431,60 +1117,52 @@ func (a *AuthApiService) DeviceStartExecute(r ApiDeviceStartRequest) (*ModelsDev
 	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-type ApiLogoutRequest struct {
+type ApiUserInfoRequest struct {
 	ctx        context.Context
 	ApiService *AuthApiService
-	redirect   *string
-}
-
-// URL to redirect to after logout
-func (r ApiLogoutRequest) Redirect(redirect string) ApiLogoutRequest {
-	r.redirect = &redirect
-	return r
 }
 
-func (r ApiLogoutRequest) Execute() (*http.Response, error) {
-	return r.ApiService.LogoutExecute(r)
+func (r ApiUserInfoRequest) Execute() (*ModelsUserInfoResponse, *http.Response, error) {
+	return r.ApiService.UserInfoExecute(r)
 }
 
 /*
-Logout Generate Logout URL
+UserInfo Retrieve Current User Information
 
-Provides the URL to initiate the logout process for the current user.
+Fetches and returns information for the user who is currently authenticated.
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
-	@return ApiLogoutRequest
+	@return ApiUserInfoRequest
 */
-func (a *AuthApiService) Logout(ctx context.Context) ApiLogoutRequest {
-	return ApiLogoutRequest{
+func (a *AuthApiService) UserInfo(ctx context.Context) ApiUserInfoRequest {
+	return ApiUserInfoRequest{
 		ApiService: a,
 		ctx:        ctx,
 	}
 }
 
 // Execute executes the request
-func (a *AuthApiService) LogoutExecute(r ApiLogoutRequest) (*http.Response, error) {
+//
+//	@return ModelsUserInfoResponse
+func (a *AuthApiService) UserInfoExecute(r ApiUserInfoRequest) (*ModelsUserInfoResponse, *http.Response, error) {
 	var (
-		localVarHTTPMethod = http.MethodGet
-		localVarPostBody   interface{}
-		formFiles          []formFile
+		localVarHTTPMethod  = http.MethodGet
+		localVarPostBody    interface{}
+		formFiles           []formFile
+		localVarReturnValue *ModelsUserInfoResponse
 	)
 
-	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.Logout")
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.UserInfo")
 	if err != nil {
-		return nil, &GenericOpenAPIError{error: err.Error()}
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/web/logout"
+	localVarPath := localBasePath + "/web/user_info"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
-	if r.redirect == nil {
-		return nil, reportError("redirect is required and must be specified")
-	}
 
-	parameterAddToHeaderOrQuery(localVarQueryParams, "redirect", r.redirect, "")
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -504,19 +1182,19 @@ func (a *AuthApiService) LogoutExecute(r ApiLogoutRequest) (*http.Response, erro
 	}
 	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
 	if err != nil {
-		return nil, err
+		return localVarReturnValue, nil, err
 	}
 
 	localVarHTTPResponse, err := a.client.callAPI(req)
 	if err != nil || localVarHTTPResponse == nil {
-		return localVarHTTPResponse, err
+		return localVarReturnValue, localVarHTTPResponse, err
 	}
 
 	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
 	localVarHTTPResponse.Body.Close()
 	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
 	if err != nil {
-		return localVarHTTPResponse, err
+		return localVarReturnValue, localVarHTTPResponse, err
 	}
 
 	if localVarHTTPResponse.StatusCode >= 300 {
@@ -524,65 +1202,84 @@ func (a *AuthApiService) LogoutExecute(r ApiLogoutRequest) (*http.Response, erro
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
-		if localVarHTTPResponse.StatusCode == 302 {
-			var v string
-			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
-			if err != nil {
-				newErr.error = err.Error()
-				return localVarHTTPResponse, newErr
-			}
-			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
-			newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
 		}
-		return localVarHTTPResponse, newErr
+		return localVarReturnValue, localVarHTTPResponse, newErr
 	}
 
-	return localVarHTTPResponse, nil
+	return localVarReturnValue, localVarHTTPResponse, nil
 }
 
-type ApiRefreshRequest struct {
-	ctx        context.Context
-	ApiService *AuthApiService
+type ApiWebBackchannelLogoutRequest struct {
+	ctx         context.Context
+	ApiService  *AuthApiService
+	logoutToken *string
+	provider    *string
 }
 
-func (r ApiRefreshRequest) Execute() (*http.Response, error) {
-	return r.ApiService.RefreshExecute(r)
+// logout token of the provider
+func (r ApiWebBackchannelLogoutRequest) LogoutToken(logoutToken string) ApiWebBackchannelLogoutRequest {
+	r.logoutToken = &logoutToken
+	return r
+}
+
+// name of the identity provider of the logout token, the default provider when not given
+func (r ApiWebBackchannelLogoutRequest) Provider(provider string) ApiWebBackchannelLogoutRequest {
+	r.provider = &provider
+	return r
+}
+
+func (r ApiWebBackchannelLogoutRequest) Execute() (*http.Response, error) {
+	return r.ApiService.WebBackchannelLogoutExecute(r)
 }
 
 /*
-Refresh Refresh Access Token
+WebBackchannelLogout OIDC Back-Channel Logout
 
-Obtains and updates a new access token for the user.
+Ends the sessions of the subject or the provider session of the logout token, called by the provider when the user logs out of it.
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
-	@return ApiRefreshRequest
+	@return ApiWebBackchannelLogoutRequest
 */
-func (a *AuthApiService) Refresh(ctx context.Context) ApiRefreshRequest {
-	return ApiRefreshRequest{
+func (a *AuthApiService) WebBackchannelLogout(ctx context.Context) ApiWebBackchannelLogoutRequest {
+	return ApiWebBackchannelLogoutRequest{
 		ApiService: a,
 		ctx:        ctx,
 	}
 }
 
 // Execute executes the request
-func (a *AuthApiService) RefreshExecute(r ApiRefreshRequest) (*http.Response, error) {
+func (a *AuthApiService) WebBackchannelLogoutExecute(r ApiWebBackchannelLogoutRequest) (*http.Response, error) {
 	var (
 		localVarHTTPMethod = http.MethodPost
 		localVarPostBody   interface{}
 		formFiles          []formFile
 	)
 
-	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.Refresh")
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.WebBackchannelLogout")
 	if err != nil {
 		return nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/web/refresh"
+	localVarPath := localBasePath + "/web/backchannel_logout"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
+	if r.logoutToken == nil {
+		return nil, reportError("logoutToken is required and must be specified")
+	}
 
+	if r.provider != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "provider", r.provider, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -593,7 +1290,7 @@ func (a *AuthApiService) RefreshExecute(r ApiRefreshRequest) (*http.Response, er
 	}
 
 	// to determine the Accept header
-	localVarHTTPHeaderAccepts := []string{}
+	localVarHTTPHeaderAccepts := []string{"application/json"}
 
 	// set Accept header
 	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
@@ -622,31 +1319,41 @@ func (a *AuthApiService) RefreshExecute(r ApiRefreshRequest) (*http.Response, er
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v map[string]string
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+		}
 		return localVarHTTPResponse, newErr
 	}
 
 	return localVarHTTPResponse, nil
 }
 
-type ApiUserInfoRequest struct {
+type ApiWebCSRFTokenRequest struct {
 	ctx        context.Context
 	ApiService *AuthApiService
 }
 
-func (r ApiUserInfoRequest) Execute() (*ModelsUserInfoResponse, *http.Response, error) {
-	return r.ApiService.UserInfoExecute(r)
+func (r ApiWebCSRFTokenRequest) Execute() (*ModelsCSRFTokenResponse, *http.Response, error) {
+	return r.ApiService.WebCSRFTokenExecute(r)
 }
 
 /*
-UserInfo Retrieve Current User Information
+WebCSRFToken Get CSRF Token
 
-Fetches and returns information for the user who is currently authenticated.
+Gets the CSRF token to send in the X-CSRF-Token header or the csrf_token query parameter of the requests changing the session.
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
-	@return ApiUserInfoRequest
+	@return ApiWebCSRFTokenRequest
 */
-func (a *AuthApiService) UserInfo(ctx context.Context) ApiUserInfoRequest {
-	return ApiUserInfoRequest{
+func (a *AuthApiService) WebCSRFToken(ctx context.Context) ApiWebCSRFTokenRequest {
+	return ApiWebCSRFTokenRequest{
 		ApiService: a,
 		ctx:        ctx,
 	}
@@ -654,21 +1361,21 @@ func (a *AuthApiService) UserInfo(ctx context.Context) ApiUserInfoRequest {
 
 // Execute executes the request
 //
-//	@return ModelsUserInfoResponse
-func (a *AuthApiService) UserInfoExecute(r ApiUserInfoRequest) (*ModelsUserInfoResponse, *http.Response, error) {
+//	@return ModelsCSRFTokenResponse
+func (a *AuthApiService) WebCSRFTokenExecute(r ApiWebCSRFTokenRequest) (*ModelsCSRFTokenResponse, *http.Response, error) {
 	var (
 		localVarHTTPMethod  = http.MethodGet
 		localVarPostBody    interface{}
 		formFiles           []formFile
-		localVarReturnValue *ModelsUserInfoResponse
+		localVarReturnValue *ModelsCSRFTokenResponse
 	)
 
-	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.UserInfo")
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.WebCSRFToken")
 	if err != nil {
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/web/user_info"
+	localVarPath := localBasePath + "/web/csrf_token"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -859,11 +1566,112 @@ func (a *AuthApiService) WebEndExecute(r ApiWebEndRequest) (*http.Response, erro
 	return localVarHTTPResponse, nil
 }
 
+type ApiWebProvidersRequest struct {
+	ctx        context.Context
+	ApiService *AuthApiService
+}
+
+func (r ApiWebProvidersRequest) Execute() ([]ModelsProviderResponse, *http.Response, error) {
+	return r.ApiService.WebProvidersExecute(r)
+}
+
+/*
+WebProviders List Identity Providers
+
+Lists the identity providers the users can log in with, the first one is the default
+
+	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
+	@return ApiWebProvidersRequest
+*/
+func (a *AuthApiService) WebProviders(ctx context.Context) ApiWebProvidersRequest {
+	return ApiWebProvidersRequest{
+		ApiService: a,
+		ctx:        ctx,
+	}
+}
+
+// Execute executes the request
+//
+//	@return []ModelsProviderResponse
+func (a *AuthApiService) WebProvidersExecute(r ApiWebProvidersRequest) ([]ModelsProviderResponse, *http.Response, error) {
+	var (
+		localVarHTTPMethod  = http.MethodGet
+		localVarPostBody    interface{}
+		formFiles           []formFile
+		localVarReturnValue []ModelsProviderResponse
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(r.ctx, "AuthApiService.WebProviders")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/web/providers"
+
+	localVarHeaderParams := make(map[string]string)
+	localVarQueryParams := url.Values{}
+	localVarFormParams := url.Values{}
+
+	// to determine the Content-Type header
+	localVarHTTPContentTypes := []string{}
+
+	// set Content-Type header
+	localVarHTTPContentType := selectHeaderContentType(localVarHTTPContentTypes)
+	if localVarHTTPContentType != "" {
+		localVarHeaderParams["Content-Type"] = localVarHTTPContentType
+	}
+
+	// to determine the Accept header
+	localVarHTTPHeaderAccepts := []string{"application/json"}
+
+	// set Accept header
+	localVarHTTPHeaderAccept := selectHeaderAccept(localVarHTTPHeaderAccepts)
+	if localVarHTTPHeaderAccept != "" {
+		localVarHeaderParams["Accept"] = localVarHTTPHeaderAccept
+	}
+	req, err := a.client.prepareRequest(r.ctx, localVarPath, localVarHTTPMethod, localVarPostBody, localVarHeaderParams, localVarQueryParams, localVarFormParams, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
+
 type ApiWebStartRequest struct {
 	ctx        context.Context
 	ApiService *AuthApiService
 	redirect   *string
 	failure    *string
+	provider   *string
 }
 
 // URL to redirect to if login succeeds
@@ -878,6 +1686,12 @@ func (r ApiWebStartRequest) Failure(failure string) ApiWebStartRequest {
 	return r
 }
 
+// name of the identity provider to log in with, see /web/providers, a page to select one is returned when there are several and none is given
+func (r ApiWebStartRequest) Provider(provider string) ApiWebStartRequest {
+	r.provider = &provider
+	return r
+}
+
 func (r ApiWebStartRequest) Execute() (*http.Response, error) {
 	return r.ApiService.WebStartExecute(r)
 }
@@ -885,7 +1699,7 @@ func (r ApiWebStartRequest) Execute() (*http.Response, error) {
 /*
 WebStart Initiates OIDC Web Login
 
-Generates state and nonce, then redirects the user to the OAuth2 authorization URL.
+Generates state, nonce and a PKCE code verifier, then redirects the user to the OAuth2 authorization URL.
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
 	@return ApiWebStartRequest
@@ -923,6 +1737,9 @@ func (a *AuthApiService) WebStartExecute(r ApiWebStartRequest) (*http.Response,
 	if r.failure != nil {
 		parameterAddToHeaderOrQuery(localVarQueryParams, "failure", r.failure, "")
 	}
+	if r.provider != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "provider", r.provider, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -68,7 +68,7 @@ func (a *CAApiService) SignCSRExecute(r ApiSignCSRRequest) (*ModelsCertificateSi
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/ca/sign"
+	localVarPath := localBasePath + "/api/v1/ca/sign"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
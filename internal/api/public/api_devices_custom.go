@@ -0,0 +1,77 @@
+package public
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UpdateDeviceFields is a version of UpdateDevice that sends the fields as given, which allows
+// setting fields to their zero value, such as an empty advertise_cidrs list or a false symmetric_nat,
+// that the omitempty fields of ModelsUpdateDevice leave out of the request.
+func (a *DevicesApiService) UpdateDeviceFields(ctx context.Context, id string, fields map[string]interface{}) (*ModelsDevice, *http.Response, error) {
+	var (
+		localVarHTTPMethod  = http.MethodPatch
+		formFiles           []formFile
+		localVarReturnValue *ModelsDevice
+	)
+
+	localBasePath, err := a.client.cfg.ServerURLWithContext(ctx, "DevicesApiService.UpdateDevice")
+	if err != nil {
+		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
+	}
+
+	localVarPath := localBasePath + "/api/v1/devices/{id}"
+	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(id, "id")), -1)
+
+	localVarHeaderParams := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	req, err := a.client.prepareRequest(ctx, localVarPath, localVarHTTPMethod, fields, localVarHeaderParams, url.Values{}, url.Values{}, formFiles)
+	if err != nil {
+		return localVarReturnValue, nil, err
+	}
+
+	localVarHTTPResponse, err := a.client.callAPI(req)
+	if err != nil || localVarHTTPResponse == nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	localVarBody, err := io.ReadAll(localVarHTTPResponse.Body)
+	_ = localVarHTTPResponse.Body.Close()
+	localVarHTTPResponse.Body = io.NopCloser(bytes.NewBuffer(localVarBody))
+	if err != nil {
+		return localVarReturnValue, localVarHTTPResponse, err
+	}
+
+	if localVarHTTPResponse.StatusCode >= 300 {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: localVarHTTPResponse.Status,
+		}
+		var v ModelsBaseError
+		err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+		if err != nil {
+			newErr.error = err.Error()
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+		newErr.model = v
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	err = a.client.decode(&localVarReturnValue, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+	if err != nil {
+		newErr := &GenericOpenAPIError{
+			body:  localVarBody,
+			error: err.Error(),
+		}
+		return localVarReturnValue, localVarHTTPResponse, newErr
+	}
+
+	return localVarReturnValue, localVarHTTPResponse, nil
+}
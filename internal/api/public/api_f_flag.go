@@ -23,9 +23,16 @@ import (
 type FFlagApiService service
 
 type ApiGetFeatureFlagRequest struct {
-	ctx        context.Context
-	ApiService *FFlagApiService
-	name       string
+	ctx            context.Context
+	ApiService     *FFlagApiService
+	name           string
+	organizationId *string
+}
+
+// the organization the flag is evaluated for, the default organization of the user by default
+func (r ApiGetFeatureFlagRequest) OrganizationId(organizationId string) ApiGetFeatureFlagRequest {
+	r.organizationId = &organizationId
+	return r
 }
 
 func (r ApiGetFeatureFlagRequest) Execute() (map[string]bool, *http.Response, error) {
@@ -35,7 +42,7 @@ func (r ApiGetFeatureFlagRequest) Execute() (map[string]bool, *http.Response, er
 /*
 GetFeatureFlag Get Feature Flag
 
-Gets a Feature Flag by name
+Gets a Feature Flag by name, a flag targeting organizations is evaluated for an organization
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
 	@param name feature flag name
@@ -65,13 +72,16 @@ func (a *FFlagApiService) GetFeatureFlagExecute(r ApiGetFeatureFlagRequest) (map
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/fflags/{name}"
+	localVarPath := localBasePath + "/api/v1/fflags/{name}"
 	localVarPath = strings.Replace(localVarPath, "{"+"name"+"}", url.PathEscape(parameterValueToString(r.name, "name")), -1)
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
 
+	if r.organizationId != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "organization_id", r.organizationId, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -170,8 +180,15 @@ func (a *FFlagApiService) GetFeatureFlagExecute(r ApiGetFeatureFlagRequest) (map
 }
 
 type ApiListFeatureFlagsRequest struct {
-	ctx        context.Context
-	ApiService *FFlagApiService
+	ctx            context.Context
+	ApiService     *FFlagApiService
+	organizationId *string
+}
+
+// the organization the flags are evaluated for, the default organization of the user by default
+func (r ApiListFeatureFlagsRequest) OrganizationId(organizationId string) ApiListFeatureFlagsRequest {
+	r.organizationId = &organizationId
+	return r
 }
 
 func (r ApiListFeatureFlagsRequest) Execute() (map[string]bool, *http.Response, error) {
@@ -181,7 +198,7 @@ func (r ApiListFeatureFlagsRequest) Execute() (map[string]bool, *http.Response,
 /*
 ListFeatureFlags List Feature Flags
 
-Lists all feature flags
+Lists all feature flags, with the values of the flags targeting organizations evaluated for an organization
 
 	@param ctx context.Context - for authentication, logging, cancellation, deadlines, tracing, etc. Passed from http.Request or context.Background().
 	@return ApiListFeatureFlagsRequest
@@ -209,12 +226,15 @@ func (a *FFlagApiService) ListFeatureFlagsExecute(r ApiListFeatureFlagsRequest)
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/fflags"
+	localVarPath := localBasePath + "/api/v1/fflags"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
 
+	if r.organizationId != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "organization_id", r.organizationId, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -254,6 +274,28 @@ func (a *FFlagApiService) ListFeatureFlagsExecute(r ApiListFeatureFlagsRequest)
 			body:  localVarBody,
 			error: localVarHTTPResponse.Status,
 		}
+		if localVarHTTPResponse.StatusCode == 400 {
+			var v ModelsBaseError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
+		if localVarHTTPResponse.StatusCode == 404 {
+			var v ModelsBaseError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
 		if localVarHTTPResponse.StatusCode == 429 {
 			var v ModelsBaseError
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
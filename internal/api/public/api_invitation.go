@@ -62,7 +62,7 @@ func (a *InvitationApiService) AcceptInvitationExecute(r ApiAcceptInvitationRequ
 		return nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/invitations/{id}/accept"
+	localVarPath := localBasePath + "/api/v1/invitations/{id}/accept"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -204,7 +204,7 @@ func (a *InvitationApiService) CreateInvitationExecute(r ApiCreateInvitationRequ
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/invitations"
+	localVarPath := localBasePath + "/api/v1/invitations"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -355,7 +355,7 @@ func (a *InvitationApiService) DeleteInvitationExecute(r ApiDeleteInvitationRequ
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/invitations/{id}"
+	localVarPath := localBasePath + "/api/v1/invitations/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -502,7 +502,7 @@ func (a *InvitationApiService) GetInvitationExecute(r ApiGetInvitationRequest) (
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/invitations/{id}"
+	localVarPath := localBasePath + "/api/v1/invitations/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -657,7 +657,7 @@ func (a *InvitationApiService) ListInvitationsExecute(r ApiListInvitationsReques
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/invitations"
+	localVarPath := localBasePath + "/api/v1/invitations"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
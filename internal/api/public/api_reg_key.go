@@ -69,7 +69,7 @@ func (a *RegKeyApiService) CreateRegKeyExecute(r ApiCreateRegKeyRequest) (*Model
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/reg-keys"
+	localVarPath := localBasePath + "/api/v1/reg-keys"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -220,7 +220,7 @@ func (a *RegKeyApiService) DeleteRegKeyExecute(r ApiDeleteRegKeyRequest) (*Model
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/reg-keys/{id}"
+	localVarPath := localBasePath + "/api/v1/reg-keys/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -367,7 +367,7 @@ func (a *RegKeyApiService) GetRegKeyExecute(r ApiGetRegKeyRequest) (*ModelsRegKe
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/reg-keys/{id}"
+	localVarPath := localBasePath + "/api/v1/reg-keys/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -522,7 +522,7 @@ func (a *RegKeyApiService) ListRegKeysExecute(r ApiListRegKeysRequest) ([]Models
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/reg-keys"
+	localVarPath := localBasePath + "/api/v1/reg-keys"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -664,7 +664,7 @@ func (a *RegKeyApiService) UpdateRegKeyExecute(r ApiUpdateRegKeyRequest) (*Model
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/reg-keys/{id}"
+	localVarPath := localBasePath + "/api/v1/reg-keys/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
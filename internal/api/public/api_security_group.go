@@ -69,7 +69,7 @@ func (a *SecurityGroupApiService) CreateSecurityGroupExecute(r ApiCreateSecurity
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/security-groups"
+	localVarPath := localBasePath + "/api/v1/security-groups"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -141,6 +141,17 @@ func (a *SecurityGroupApiService) CreateSecurityGroupExecute(r ApiCreateSecurity
 			newErr.model = v
 			return localVarReturnValue, localVarHTTPResponse, newErr
 		}
+		if localVarHTTPResponse.StatusCode == 403 {
+			var v ModelsQuotaExceededError
+			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
+			if err != nil {
+				newErr.error = err.Error()
+				return localVarReturnValue, localVarHTTPResponse, newErr
+			}
+			newErr.error = formatErrorMessage(localVarHTTPResponse.Status, &v)
+			newErr.model = v
+			return localVarReturnValue, localVarHTTPResponse, newErr
+		}
 		if localVarHTTPResponse.StatusCode == 409 {
 			var v ModelsConflictsError
 			err = a.client.decode(&v, localVarBody, localVarHTTPResponse.Header.Get("Content-Type"))
@@ -242,7 +253,7 @@ func (a *SecurityGroupApiService) DeleteSecurityGroupExecute(r ApiDeleteSecurity
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/security-groups/{id}"
+	localVarPath := localBasePath + "/api/v1/security-groups/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -378,7 +389,7 @@ func (a *SecurityGroupApiService) GetSecurityGroupExecute(r ApiGetSecurityGroupR
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/security-groups/{id}"
+	localVarPath := localBasePath + "/api/v1/security-groups/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -497,6 +508,9 @@ type ApiListSecurityGroupsRequest struct {
 	ctx        context.Context
 	ApiService *SecurityGroupApiService
 	gtRevision *int32
+	sort       *string
+	limit      *int32
+	cursor     *string
 }
 
 // greater than revision
@@ -505,6 +519,24 @@ func (r ApiListSecurityGroupsRequest) GtRevision(gtRevision int32) ApiListSecuri
 	return r
 }
 
+// comma separated fields to sort by, prefix a field with - to sort in descending order
+func (r ApiListSecurityGroupsRequest) Sort(sort string) ApiListSecurityGroupsRequest {
+	r.sort = &sort
+	return r
+}
+
+// the number of items in a page, enables cursor pagination
+func (r ApiListSecurityGroupsRequest) Limit(limit int32) ApiListSecurityGroupsRequest {
+	r.limit = &limit
+	return r
+}
+
+// the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page
+func (r ApiListSecurityGroupsRequest) Cursor(cursor string) ApiListSecurityGroupsRequest {
+	r.cursor = &cursor
+	return r
+}
+
 func (r ApiListSecurityGroupsRequest) Execute() ([]ModelsSecurityGroup, *http.Response, error) {
 	return r.ApiService.ListSecurityGroupsExecute(r)
 }
@@ -540,7 +572,7 @@ func (a *SecurityGroupApiService) ListSecurityGroupsExecute(r ApiListSecurityGro
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/security-groups"
+	localVarPath := localBasePath + "/api/v1/security-groups"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -549,6 +581,15 @@ func (a *SecurityGroupApiService) ListSecurityGroupsExecute(r ApiListSecurityGro
 	if r.gtRevision != nil {
 		parameterAddToHeaderOrQuery(localVarQueryParams, "gt_revision", r.gtRevision, "")
 	}
+	if r.sort != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "sort", r.sort, "")
+	}
+	if r.limit != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "limit", r.limit, "")
+	}
+	if r.cursor != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "cursor", r.cursor, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -685,7 +726,7 @@ func (a *SecurityGroupApiService) UpdateSecurityGroupExecute(r ApiUpdateSecurity
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/security-groups/{id}"
+	localVarPath := localBasePath + "/api/v1/security-groups/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
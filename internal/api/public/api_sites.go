@@ -69,7 +69,7 @@ func (a *SitesApiService) CreateSiteExecute(r ApiCreateSiteRequest) (*ModelsSite
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/sites"
+	localVarPath := localBasePath + "/api/v1/sites"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -231,7 +231,7 @@ func (a *SitesApiService) DeleteSiteExecute(r ApiDeleteSiteRequest) (*ModelsSite
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/sites/{id}"
+	localVarPath := localBasePath + "/api/v1/sites/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -367,7 +367,7 @@ func (a *SitesApiService) GetSiteExecute(r ApiGetSiteRequest) (*ModelsSite, *htt
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/sites/{id}"
+	localVarPath := localBasePath + "/api/v1/sites/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -522,7 +522,7 @@ func (a *SitesApiService) ListSitesExecute(r ApiListSitesRequest) ([]ModelsSite,
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/sites"
+	localVarPath := localBasePath + "/api/v1/sites"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
@@ -664,7 +664,7 @@ func (a *SitesApiService) UpdateSiteExecute(r ApiUpdateSiteRequest) (*ModelsSite
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/sites/{id}"
+	localVarPath := localBasePath + "/api/v1/sites/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -65,7 +65,7 @@ func (a *UsersApiService) DeleteUserExecute(r ApiDeleteUserRequest) (*ModelsUser
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/users/{id}"
+	localVarPath := localBasePath + "/api/v1/users/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -204,7 +204,7 @@ func (a *UsersApiService) DeleteUserFromOrganizationExecute(r ApiDeleteUserFromO
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/users/{id}/organizations/{organization}"
+	localVarPath := localBasePath + "/api/v1/users/{id}/organizations/{organization}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 	localVarPath = strings.Replace(localVarPath, "{"+"organization"+"}", url.PathEscape(parameterValueToString(r.organization, "organization")), -1)
 
@@ -330,7 +330,7 @@ func (a *UsersApiService) GetUserExecute(r ApiGetUserRequest) (*ModelsUser, *htt
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/users/{id}"
+	localVarPath := localBasePath + "/api/v1/users/{id}"
 	localVarPath = strings.Replace(localVarPath, "{"+"id"+"}", url.PathEscape(parameterValueToString(r.id, "id")), -1)
 
 	localVarHeaderParams := make(map[string]string)
@@ -448,6 +448,27 @@ func (a *UsersApiService) GetUserExecute(r ApiGetUserRequest) (*ModelsUser, *htt
 type ApiListUsersRequest struct {
 	ctx        context.Context
 	ApiService *UsersApiService
+	sort       *string
+	limit      *int32
+	cursor     *string
+}
+
+// comma separated fields to sort by, prefix a field with - to sort in descending order
+func (r ApiListUsersRequest) Sort(sort string) ApiListUsersRequest {
+	r.sort = &sort
+	return r
+}
+
+// the number of items in a page, enables cursor pagination
+func (r ApiListUsersRequest) Limit(limit int32) ApiListUsersRequest {
+	r.limit = &limit
+	return r
+}
+
+// the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page
+func (r ApiListUsersRequest) Cursor(cursor string) ApiListUsersRequest {
+	r.cursor = &cursor
+	return r
 }
 
 func (r ApiListUsersRequest) Execute() ([]ModelsUser, *http.Response, error) {
@@ -485,12 +506,21 @@ func (a *UsersApiService) ListUsersExecute(r ApiListUsersRequest) ([]ModelsUser,
 		return localVarReturnValue, nil, &GenericOpenAPIError{error: err.Error()}
 	}
 
-	localVarPath := localBasePath + "/api/users"
+	localVarPath := localBasePath + "/api/v1/users"
 
 	localVarHeaderParams := make(map[string]string)
 	localVarQueryParams := url.Values{}
 	localVarFormParams := url.Values{}
 
+	if r.sort != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "sort", r.sort, "")
+	}
+	if r.limit != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "limit", r.limit, "")
+	}
+	if r.cursor != nil {
+		parameterAddToHeaderOrQuery(localVarQueryParams, "cursor", r.cursor, "")
+	}
 	// to determine the Content-Type header
 	localVarHTTPContentTypes := []string{}
 
@@ -0,0 +1,39 @@
+package public
+
+import (
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// Informer creates a *ApiListDNSRecordsInformer which provides a simpler
+// API to list dns records but which is implemented with the Watch api.  The *ApiListDNSRecordsInformer
+// maintains a local dns record cache which gets updated with the Watch events.
+func (r ApiListDNSRecordsInVPCRequest) Informer() *Informer[ModelsDNSRecord] {
+	informer := NewInformer[ModelsDNSRecord](&DNSRecordAdaptor{}, r.gtRevision, ApiWatchEventsRequest{
+		ctx:        r.ctx,
+		ApiService: r.ApiService.client.VPCApi,
+		id:         r.id,
+	})
+	return informer
+}
+
+type DNSRecordAdaptor struct{}
+
+func (d DNSRecordAdaptor) Revision(item ModelsDNSRecord) int32 {
+	return item.Revision
+}
+
+func (d DNSRecordAdaptor) Key(item ModelsDNSRecord) string {
+	return item.Id
+}
+
+func (d DNSRecordAdaptor) Kind() string {
+	return "dns-record"
+}
+
+func (d DNSRecordAdaptor) Item(value map[string]interface{}) (ModelsDNSRecord, error) {
+	item := ModelsDNSRecord{}
+	err := util.JsonUnmarshal(value, &item)
+	return item, err
+}
+
+var _ InformerAdaptor[ModelsDNSRecord] = &DNSRecordAdaptor{}
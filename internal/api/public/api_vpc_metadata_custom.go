@@ -0,0 +1,44 @@
+package public
+
+import (
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// Informer creates a *Informer[ModelsDeviceMetadata] which provides a simpler API to list the metadata of the
+// devices in a VPC but which is implemented with the Watch api. The informer only watches the keys with the
+// prefixes of the request, and maintains a local metadata cache which gets updated with the Watch events.
+func (r ApiListMetadataInVPCRequest) Informer() *Informer[ModelsDeviceMetadata] {
+	informer := NewInformer[ModelsDeviceMetadata](&DeviceMetadataAdaptor{}, r.gtRevision, ApiWatchEventsRequest{
+		ctx:        r.ctx,
+		ApiService: r.ApiService.client.VPCApi,
+		id:         r.id,
+	})
+	if len(r.prefix) > 0 {
+		informer.watch.Options = map[string]interface{}{
+			"prefixes": r.prefix,
+		}
+	}
+	return informer
+}
+
+type DeviceMetadataAdaptor struct{}
+
+func (d DeviceMetadataAdaptor) Revision(item ModelsDeviceMetadata) int32 {
+	return item.Revision
+}
+
+func (d DeviceMetadataAdaptor) Key(item ModelsDeviceMetadata) string {
+	return item.DeviceId + "/" + item.Key
+}
+
+func (d DeviceMetadataAdaptor) Kind() string {
+	return "device-metadata"
+}
+
+func (d DeviceMetadataAdaptor) Item(value map[string]interface{}) (ModelsDeviceMetadata, error) {
+	item := ModelsDeviceMetadata{}
+	err := util.JsonUnmarshal(value, &item)
+	return item, err
+}
+
+var _ InformerAdaptor[ModelsDeviceMetadata] = &DeviceMetadataAdaptor{}
@@ -0,0 +1,39 @@
+package public
+
+import (
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// Informer creates a *ApiListServicesInformer which provides a simpler
+// API to list services but which is implemented with the Watch api.  The *ApiListServicesInformer
+// maintains a local service cache which gets updated with the Watch events.
+func (r ApiListServicesInVPCRequest) Informer() *Informer[ModelsService] {
+	informer := NewInformer[ModelsService](&ServiceAdaptor{}, r.gtRevision, ApiWatchEventsRequest{
+		ctx:        r.ctx,
+		ApiService: r.ApiService.client.VPCApi,
+		id:         r.id,
+	})
+	return informer
+}
+
+type ServiceAdaptor struct{}
+
+func (d ServiceAdaptor) Revision(item ModelsService) int32 {
+	return item.Revision
+}
+
+func (d ServiceAdaptor) Key(item ModelsService) string {
+	return item.Id
+}
+
+func (d ServiceAdaptor) Kind() string {
+	return "service"
+}
+
+func (d ServiceAdaptor) Item(value map[string]interface{}) (ModelsService, error) {
+	item := ModelsService{}
+	err := util.JsonUnmarshal(value, &item)
+	return item, err
+}
+
+var _ InformerAdaptor[ModelsService] = &ServiceAdaptor{}
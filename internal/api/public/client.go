@@ -50,6 +50,10 @@ type APIClient struct {
 
 	// API Services
 
+	APIKeyApi *APIKeyApiService
+
+	AdminApi *AdminApiService
+
 	AuthApi *AuthApiService
 
 	CAApi *CAApiService
@@ -66,6 +70,8 @@ type APIClient struct {
 
 	SecurityGroupApi *SecurityGroupApiService
 
+	ServicesApi *ServicesApiService
+
 	SitesApi *SitesApiService
 
 	UsersApi *UsersApiService
@@ -89,6 +95,8 @@ func NewAPIClient(cfg *Configuration) *APIClient {
 	c.common.client = c
 
 	// API Services
+	c.APIKeyApi = (*APIKeyApiService)(&c.common)
+	c.AdminApi = (*AdminApiService)(&c.common)
 	c.AuthApi = (*AuthApiService)(&c.common)
 	c.CAApi = (*CAApiService)(&c.common)
 	c.DevicesApi = (*DevicesApiService)(&c.common)
@@ -97,6 +105,7 @@ func NewAPIClient(cfg *Configuration) *APIClient {
 	c.OrganizationsApi = (*OrganizationsApiService)(&c.common)
 	c.RegKeyApi = (*RegKeyApiService)(&c.common)
 	c.SecurityGroupApi = (*SecurityGroupApiService)(&c.common)
+	c.ServicesApi = (*ServicesApiService)(&c.common)
 	c.SitesApi = (*SitesApiService)(&c.common)
 	c.UsersApi = (*UsersApiService)(&c.common)
 	c.VPCApi = (*VPCApiService)(&c.common)
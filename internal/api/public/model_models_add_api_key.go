@@ -0,0 +1,21 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAddAPIKey struct for ModelsAddAPIKey
+type ModelsAddAPIKey struct {
+	// Description of the api key.
+	Description string `json:"description,omitempty"`
+	// ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.
+	ExpiresAt string `json:"expires_at,omitempty"`
+	// Scopes of the api key, read-only is all the read scopes.
+	Scopes []string `json:"scopes,omitempty"`
+}
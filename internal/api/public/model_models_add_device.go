@@ -12,14 +12,23 @@ package public
 
 // ModelsAddDevice struct for ModelsAddDevice
 type ModelsAddDevice struct {
-	AdvertiseCidrs  []string         `json:"advertise_cidrs,omitempty"`
+	AdvertiseCidrs []string `json:"advertise_cidrs,omitempty"`
+	// when set, the device is not assigned an IPv6 tunnel address
+	DisableIpv6     bool             `json:"disable_ipv6,omitempty"`
+	DnsName         string           `json:"dns_name,omitempty"`
+	EndpointIpv6    string           `json:"endpoint_ipv6,omitempty"`
 	Endpoints       []ModelsEndpoint `json:"endpoints,omitempty"`
 	Hostname        string           `json:"hostname,omitempty"`
 	Ipv4TunnelIps   []ModelsTunnelIP `json:"ipv4_tunnel_ips,omitempty"`
+	ListenPort      int32            `json:"listen_port,omitempty"`
+	NatHairpin      bool             `json:"nat_hairpin,omitempty"`
+	NatType         string           `json:"nat_type,omitempty"`
 	Os              string           `json:"os,omitempty"`
 	PublicKey       string           `json:"public_key,omitempty"`
 	Relay           bool             `json:"relay,omitempty"`
 	SecurityGroupId string           `json:"security_group_id,omitempty"`
 	SymmetricNat    bool             `json:"symmetric_nat,omitempty"`
-	VpcId           string           `json:"vpc_id,omitempty"`
+	// the device does not run nexd, it is configured with a wg-quick config exported by nexctl
+	Unmanaged bool   `json:"unmanaged,omitempty"`
+	VpcId     string `json:"vpc_id,omitempty"`
 }
@@ -0,0 +1,20 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAddFeatureFlag struct for ModelsAddFeatureFlag
+type ModelsAddFeatureFlag struct {
+	Description   string   `json:"description,omitempty"`
+	Enabled       bool     `json:"enabled,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Organizations []string `json:"organizations,omitempty"`
+	Percentage    int32    `json:"percentage,omitempty"`
+}
@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAddIPReservation struct for ModelsAddIPReservation
+type ModelsAddIPReservation struct {
+	Address     string `json:"address,omitempty"`
+	Description string `json:"description,omitempty"`
+	DeviceId    string `json:"device_id,omitempty"`
+	PublicKey   string `json:"public_key,omitempty"`
+}
@@ -12,6 +12,16 @@ package public
 
 // ModelsAddOrganization struct for ModelsAddOrganization
 type ModelsAddOrganization struct {
-	Description string `json:"description,omitempty"`
-	Name        string `json:"name,omitempty"`
+	DeleteExpiredDevices bool   `json:"delete_expired_devices,omitempty"`
+	Description          string `json:"description,omitempty"`
+	DeviceTtlDays        int32  `json:"device_ttl_days,omitempty"`
+	// the IPv4 prefix of the default vpc of the organization, the vpc is only created when a prefix is given
+	Ipv4Cidr string `json:"ipv4_cidr,omitempty"`
+	// the IPv6 prefix of the default vpc of the organization
+	Ipv6Cidr              string `json:"ipv6_cidr,omitempty"`
+	ListenPortMax         int32  `json:"listen_port_max,omitempty"`
+	ListenPortMin         int32  `json:"listen_port_min,omitempty"`
+	Name                  string `json:"name,omitempty"`
+	RequireDeviceApproval bool   `json:"require_device_approval,omitempty"`
+	UpdateChannel         string `json:"update_channel,omitempty"`
 }
@@ -0,0 +1,20 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAddService struct for ModelsAddService
+type ModelsAddService struct {
+	Description string `json:"description,omitempty"`
+	DeviceId    string `json:"device_id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Port        int32  `json:"port,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+}
@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAddServiceAccount struct for ModelsAddServiceAccount
+type ModelsAddServiceAccount struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+	// Roles of the service account in the organization, member when not set.
+	Roles []string `json:"roles,omitempty"`
+}
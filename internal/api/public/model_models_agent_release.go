@@ -0,0 +1,24 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAgentRelease struct for ModelsAgentRelease
+type ModelsAgentRelease struct {
+	Arch    string `json:"arch,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Os      string `json:"os,omitempty"`
+	// hex encoded sha256 digest of the binary
+	Sha256 string `json:"sha256,omitempty"`
+	// base64 encoded ed25519 signature of the binary
+	Signature string `json:"signature,omitempty"`
+	Url       string `json:"url,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
@@ -0,0 +1,26 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAPIKey struct for ModelsAPIKey
+type ModelsAPIKey struct {
+	// Description of the api key.
+	Description string `json:"description,omitempty"`
+	// ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Id        string `json:"id,omitempty"`
+	// Scopes of the api requests of the key.
+	Scopes []string `json:"scopes,omitempty"`
+	// Token is only returned when the api key is created, clients send it as the bearer token.
+	Token string `json:"token,omitempty"`
+	// UserID is the ID of the user the api requests of the key are made as.
+	UserId string `json:"user_id,omitempty"`
+}
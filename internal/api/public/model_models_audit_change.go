@@ -0,0 +1,17 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAuditChange struct for ModelsAuditChange
+type ModelsAuditChange struct {
+	New map[string]interface{} `json:"new,omitempty"`
+	Old map[string]interface{} `json:"old,omitempty"`
+}
@@ -0,0 +1,25 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsAuditEvent struct for ModelsAuditEvent
+type ModelsAuditEvent struct {
+	Action string `json:"action,omitempty"`
+	// the user that made the change
+	ActorId        string                       `json:"actor_id,omitempty"`
+	Changes        map[string]ModelsAuditChange `json:"changes,omitempty"`
+	CreatedAt      string                       `json:"created_at,omitempty"`
+	Id             string                       `json:"id,omitempty"`
+	OrganizationId string                       `json:"organization_id,omitempty"`
+	ResourceId     string                       `json:"resource_id,omitempty"`
+	ResourceType   string                       `json:"resource_type,omitempty"`
+	SourceIp       string                       `json:"source_ip,omitempty"`
+}
@@ -0,0 +1,21 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsBatchUpdateDeviceLabels struct for ModelsBatchUpdateDeviceLabels
+type ModelsBatchUpdateDeviceLabels struct {
+	// the devices to label
+	DeviceIds []string `json:"device_ids,omitempty"`
+	// the labels to set on the devices, a label with an empty value is removed
+	Labels map[string]string `json:"labels,omitempty"`
+	// labels all the devices of the organization, only an owner of the organization can
+	OrganizationId string `json:"organization_id,omitempty"`
+}
@@ -15,20 +15,45 @@ type ModelsDevice struct {
 	AdvertiseCidrs []string `json:"advertise_cidrs,omitempty"`
 	AllowedIps     []string `json:"allowed_ips,omitempty"`
 	// the token nexd should use to reconcile device state.
-	BearerToken     string           `json:"bearer_token,omitempty"`
-	Endpoints       []ModelsEndpoint `json:"endpoints,omitempty"`
-	Hostname        string           `json:"hostname,omitempty"`
-	Id              string           `json:"id,omitempty"`
-	Ipv4TunnelIps   []ModelsTunnelIP `json:"ipv4_tunnel_ips,omitempty"`
-	Ipv6TunnelIps   []ModelsTunnelIP `json:"ipv6_tunnel_ips,omitempty"`
-	Online          bool             `json:"online,omitempty"`
-	OnlineAt        string           `json:"online_at,omitempty"`
-	Os              string           `json:"os,omitempty"`
-	OwnerId         string           `json:"owner_id,omitempty"`
-	PublicKey       string           `json:"public_key,omitempty"`
-	Relay           bool             `json:"relay,omitempty"`
-	Revision        int32            `json:"revision,omitempty"`
-	SecurityGroupId string           `json:"security_group_id,omitempty"`
-	SymmetricNat    bool             `json:"symmetric_nat,omitempty"`
-	VpcId           string           `json:"vpc_id,omitempty"`
+	BearerToken string `json:"bearer_token,omitempty"`
+	// the name users gave the device, the dns name of the device is derived from it when set
+	DisplayName string `json:"display_name,omitempty"`
+	// the name reserved for the device in the overlay DNS of the organization
+	DnsName string `json:"dns_name,omitempty"`
+	// the global IPv6 endpoint of the device, empty when it has no global IPv6 connectivity
+	EndpointIpv6 string           `json:"endpoint_ipv6,omitempty"`
+	Endpoints    []ModelsEndpoint `json:"endpoints,omitempty"`
+	// when the device was flagged for not being seen for the device ttl of its organization, cleared when it is seen again
+	ExpiredAt     string           `json:"expired_at,omitempty"`
+	Hostname      string           `json:"hostname,omitempty"`
+	Id            string           `json:"id,omitempty"`
+	Ipv4TunnelIps []ModelsTunnelIP `json:"ipv4_tunnel_ips,omitempty"`
+	Ipv6TunnelIps []ModelsTunnelIP `json:"ipv6_tunnel_ips,omitempty"`
+	// key/value inventory metadata operators attach to the device, e.g. rack, owner or environment
+	Labels map[string]string `json:"labels,omitempty"`
+	// the last time the device was connected to the event stream of the service
+	LastSeen   string `json:"last_seen,omitempty"`
+	ListenPort int32  `json:"listen_port,omitempty"`
+	// whether the NAT in front of the device supports hairpinning
+	NatHairpin bool `json:"nat_hairpin,omitempty"`
+	// the RFC 5780 mapping behavior of the NAT in front of the device, empty when unknown
+	NatType  string `json:"nat_type,omitempty"`
+	Online   bool   `json:"online,omitempty"`
+	OnlineAt string `json:"online_at,omitempty"`
+	Os       string `json:"os,omitempty"`
+	OwnerId  string `json:"owner_id,omitempty"`
+	// the device awaits the approval of an organization owner, it neither receives peers nor is a peer of the other devices
+	Pending   bool   `json:"pending,omitempty"`
+	PublicKey string `json:"public_key,omitempty"`
+	Relay     bool   `json:"relay,omitempty"`
+	// the relay the service assigned to the device when it is behind a symmetric NAT
+	RelayId         string `json:"relay_id,omitempty"`
+	Revision        int32  `json:"revision,omitempty"`
+	SecurityGroupId string `json:"security_group_id,omitempty"`
+	// the additional security groups attached to the device, denormalized from the device_security_groups table
+	SecurityGroupIds []string `json:"security_group_ids,omitempty"`
+	SymmetricNat     bool     `json:"symmetric_nat,omitempty"`
+	// the device does not run nexd, it is configured with a wg-quick config exported by nexctl
+	Unmanaged bool   `json:"unmanaged,omitempty"`
+	VpcId     string `json:"vpc_id,omitempty"`
 }
@@ -0,0 +1,21 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsDeviceChanges struct for ModelsDeviceChanges
+type ModelsDeviceChanges struct {
+	// the ids of the devices that were deleted
+	Deleted []string `json:"deleted,omitempty"`
+	// the devices that were added or updated
+	Devices []ModelsDevice `json:"devices,omitempty"`
+	// the revision to list the next changes from
+	Revision int32 `json:"revision,omitempty"`
+}
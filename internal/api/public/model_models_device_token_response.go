@@ -0,0 +1,20 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsDeviceTokenResponse struct for ModelsDeviceTokenResponse
+type ModelsDeviceTokenResponse struct {
+	AccessToken  string `json:"access_token,omitempty"`
+	ExpiresIn    int32  `json:"expires_in,omitempty"`
+	IdToken      string `json:"id_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+}
@@ -0,0 +1,25 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsDNSRecord struct for ModelsDNSRecord
+type ModelsDNSRecord struct {
+	Description string `json:"description,omitempty"`
+	Id          string `json:"id,omitempty"`
+	// the name of the record in the zone of the organization
+	Name           string `json:"name,omitempty"`
+	OrganizationId string `json:"organization_id,omitempty"`
+	Revision       int32  `json:"revision,omitempty"`
+	// A, AAAA or CNAME
+	Type string `json:"type,omitempty"`
+	// the address of an A or AAAA record, the target name of a CNAME record
+	Value string `json:"value,omitempty"`
+}
@@ -14,6 +14,8 @@ package public
 type ModelsEndpoint struct {
 	// IP address and port of the endpoint.
 	Address string `json:"address,omitempty"`
+	// Priority of the endpoint candidate, peers try the candidates with a higher priority first.
+	Priority int32 `json:"priority,omitempty"`
 	// How the endpoint was discovered
 	Source string `json:"source,omitempty"`
 }
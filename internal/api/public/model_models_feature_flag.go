@@ -0,0 +1,24 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsFeatureFlag struct for ModelsFeatureFlag
+type ModelsFeatureFlag struct {
+	Description string `json:"description,omitempty"`
+	// Enabled is the value of the flag for the organizations that are not targeted.
+	Enabled bool   `json:"enabled,omitempty"`
+	Id      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Organizations are the IDs of the organizations the flag is enabled for.
+	Organizations []string `json:"organizations,omitempty"`
+	// Percentage of the other organizations the flag is enabled for, from 0 to 100.
+	Percentage int32 `json:"percentage,omitempty"`
+}
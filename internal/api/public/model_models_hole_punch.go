@@ -0,0 +1,26 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsHolePunch struct for ModelsHolePunch
+type ModelsHolePunch struct {
+	// when set, the device sprays many ports of the peer, whose NAT maps a different port per destination
+	Birthday bool `json:"birthday,omitempty"`
+	// the device the hole punch is signaled to
+	DeviceId      string           `json:"device_id,omitempty"`
+	Id            string           `json:"id,omitempty"`
+	PeerEndpoints []ModelsEndpoint `json:"peer_endpoints,omitempty"`
+	PeerId        string           `json:"peer_id,omitempty"`
+	PeerNatType   string           `json:"peer_nat_type,omitempty"`
+	PeerPublicKey string           `json:"peer_public_key,omitempty"`
+	// when both devices start sending
+	StartAt string `json:"start_at,omitempty"`
+}
@@ -0,0 +1,22 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsIPExclusionRange struct for ModelsIPExclusionRange
+type ModelsIPExclusionRange struct {
+	Description string `json:"description,omitempty"`
+	// the last excluded address
+	EndAddress     string `json:"end_address,omitempty"`
+	Id             string `json:"id,omitempty"`
+	OrganizationId string `json:"organization_id,omitempty"`
+	// the first excluded address
+	StartAddress string `json:"start_address,omitempty"`
+}
@@ -0,0 +1,23 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsIPReservation struct for ModelsIPReservation
+type ModelsIPReservation struct {
+	Address     string `json:"address,omitempty"`
+	Description string `json:"description,omitempty"`
+	// the id of the device the address is reserved to
+	DeviceId string `json:"device_id,omitempty"`
+	Id       string `json:"id,omitempty"`
+	// the public key of the device the address is reserved to
+	PublicKey string `json:"public_key,omitempty"`
+	VpcId     string `json:"vpc_id,omitempty"`
+}
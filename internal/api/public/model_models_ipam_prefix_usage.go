@@ -0,0 +1,25 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsIPAMPrefixUsage struct for ModelsIPAMPrefixUsage
+type ModelsIPAMPrefixUsage struct {
+	Allocated int32  `json:"allocated,omitempty"`
+	Cidr      string `json:"cidr,omitempty"`
+	// Fragmentation is 0 when the free addresses form a single block and approaches 1 as they get scattered
+	Fragmentation float32 `json:"fragmentation,omitempty"`
+	Free          int32   `json:"free,omitempty"`
+	// Shared is set when the prefix is the pool shared by all the VPCs without a private cidr
+	Shared bool `json:"shared,omitempty"`
+	// Size is the number of addresses of the prefix, capped at 2147483647
+	Size  int32  `json:"size,omitempty"`
+	VpcId string `json:"vpc_id,omitempty"`
+}
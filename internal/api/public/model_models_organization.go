@@ -12,7 +12,20 @@ package public
 
 // ModelsOrganization struct for ModelsOrganization
 type ModelsOrganization struct {
-	Description string `json:"description,omitempty"`
-	Id          string `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
+	// expired devices are deleted and their IPAM allocations released, instead of only flagged
+	DeleteExpiredDevices bool   `json:"delete_expired_devices,omitempty"`
+	Description          string `json:"description,omitempty"`
+	// devices not seen for this many days are expired, 0 means devices do not expire
+	DeviceTtlDays int32  `json:"device_ttl_days,omitempty"`
+	Id            string `json:"id,omitempty"`
+	// the highest wireguard listen port devices may use, 0 means no limit
+	ListenPortMax int32 `json:"listen_port_max,omitempty"`
+	// the lowest wireguard listen port devices may use, 0 means no limit
+	ListenPortMin int32                   `json:"listen_port_min,omitempty"`
+	Name          string                  `json:"name,omitempty"`
+	Quota         ModelsOrganizationQuota `json:"quota,omitempty"`
+	// new devices of the org are pending until an owner of the org approves them
+	RequireDeviceApproval bool `json:"require_device_approval,omitempty"`
+	// the agent release channel devices in the org follow
+	UpdateChannel string `json:"update_channel,omitempty"`
 }
@@ -0,0 +1,20 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsOrganizationQuota struct for ModelsOrganizationQuota
+type ModelsOrganizationQuota struct {
+	// the total number of cidrs advertised by the devices
+	MaxChildPrefixes int32 `json:"max_child_prefixes,omitempty"`
+	MaxDevices       int32 `json:"max_devices,omitempty"`
+	// not counting the default security group of each vpc
+	MaxSecurityGroups int32 `json:"max_security_groups,omitempty"`
+}
@@ -0,0 +1,30 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsPeerConnectivity struct for ModelsPeerConnectivity
+type ModelsPeerConnectivity struct {
+	// the device that probed the peer
+	DeviceId     string  `json:"device_id,omitempty"`
+	LatencyAvgMs float32 `json:"latency_avg_ms,omitempty"`
+	LatencyMaxMs float32 `json:"latency_max_ms,omitempty"`
+	// the fraction of the last probes that were not answered
+	Loss float32 `json:"loss,omitempty"`
+	// the peering method of the device with the peer
+	Method         string `json:"method,omitempty"`
+	OrganizationId string `json:"organization_id,omitempty"`
+	PeerId         string `json:"peer_id,omitempty"`
+	// reachable, degraded or unreachable
+	Reachability string `json:"reachability,omitempty"`
+	// the time of the report
+	UpdatedAt string `json:"updated_at,omitempty"`
+	VpcId     string `json:"vpc_id,omitempty"`
+}
@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsProviderResponse struct for ModelsProviderResponse
+type ModelsProviderResponse struct {
+	DisplayName string `json:"display_name,omitempty"`
+	Issuer      string `json:"issuer,omitempty"`
+	// the name of the provider in the provider query parameter of the login requests
+	Name string `json:"name,omitempty"`
+}
@@ -0,0 +1,21 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsReportPeerConnectivity struct for ModelsReportPeerConnectivity
+type ModelsReportPeerConnectivity struct {
+	LatencyAvgMs float32 `json:"latency_avg_ms,omitempty"`
+	LatencyMaxMs float32 `json:"latency_max_ms,omitempty"`
+	Loss         float32 `json:"loss,omitempty"`
+	Method       string  `json:"method,omitempty"`
+	PeerId       string  `json:"peer_id,omitempty"`
+	Reachability string  `json:"reachability,omitempty"`
+}
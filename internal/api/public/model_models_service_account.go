@@ -0,0 +1,29 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsServiceAccount struct for ModelsServiceAccount
+type ModelsServiceAccount struct {
+	// ClientSecret is only returned when the service account is created.
+	ClientSecret string `json:"client_secret,omitempty"`
+	// Description of the service account.
+	Description string `json:"description,omitempty"`
+	Id          string `json:"id,omitempty"`
+	// Name of the service account, the username of its user.
+	Name           string `json:"name,omitempty"`
+	OrganizationId string `json:"organization_id,omitempty"`
+	// OwnerID is the ID of the user that created the service account.
+	OwnerId string `json:"owner_id,omitempty"`
+	// Roles of the service account in the organization.
+	Roles []string `json:"roles,omitempty"`
+	// UserID is the ID of the user the api requests of the service account are made as.
+	UserId string `json:"user_id,omitempty"`
+}
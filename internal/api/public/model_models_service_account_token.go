@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsServiceAccountToken struct for ModelsServiceAccountToken
+type ModelsServiceAccountToken struct {
+	AccessToken string `json:"access_token,omitempty"`
+	// ExpiresIn is the lifetime of the access token in seconds.
+	ExpiresIn int32  `json:"expires_in,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+}
@@ -12,12 +12,21 @@ package public
 
 // ModelsUpdateDevice struct for ModelsUpdateDevice
 type ModelsUpdateDevice struct {
-	AdvertiseCidrs  []string         `json:"advertise_cidrs,omitempty"`
-	Endpoints       []ModelsEndpoint `json:"endpoints,omitempty"`
-	Hostname        string           `json:"hostname,omitempty"`
-	Relay           bool             `json:"relay,omitempty"`
-	Revision        int32            `json:"revision,omitempty"`
-	SecurityGroupId string           `json:"security_group_id,omitempty"`
-	SymmetricNat    bool             `json:"symmetric_nat,omitempty"`
-	VpcId           string           `json:"vpc_id,omitempty"`
+	AdvertiseCidrs []string `json:"advertise_cidrs,omitempty"`
+	// renames the device, an empty name reverts to the hostname
+	DisplayName  *string          `json:"display_name,omitempty"`
+	DnsName      string           `json:"dns_name,omitempty"`
+	EndpointIpv6 string           `json:"endpoint_ipv6,omitempty"`
+	Endpoints    []ModelsEndpoint `json:"endpoints,omitempty"`
+	Hostname     string           `json:"hostname,omitempty"`
+	// the labels to set on the device, a label with an empty value is removed
+	Labels          map[string]string `json:"labels,omitempty"`
+	ListenPort      int32             `json:"listen_port,omitempty"`
+	NatHairpin      bool              `json:"nat_hairpin,omitempty"`
+	NatType         string            `json:"nat_type,omitempty"`
+	Relay           bool              `json:"relay,omitempty"`
+	Revision        int32             `json:"revision,omitempty"`
+	SecurityGroupId string            `json:"security_group_id,omitempty"`
+	SymmetricNat    bool              `json:"symmetric_nat,omitempty"`
+	VpcId           string            `json:"vpc_id,omitempty"`
 }
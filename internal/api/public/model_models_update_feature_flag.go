@@ -0,0 +1,20 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsUpdateFeatureFlag struct for ModelsUpdateFeatureFlag
+type ModelsUpdateFeatureFlag struct {
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled,omitempty"`
+	// Organizations replace the targeted organizations when present, an empty list removes them.
+	Organizations []string `json:"organizations,omitempty"`
+	Percentage    int32    `json:"percentage,omitempty"`
+}
@@ -0,0 +1,22 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsUpdateOrganization struct for ModelsUpdateOrganization
+type ModelsUpdateOrganization struct {
+	DeleteExpiredDevices  *bool  `json:"delete_expired_devices,omitempty"`
+	Description           string `json:"description,omitempty"`
+	DeviceTtlDays         *int32 `json:"device_ttl_days,omitempty"`
+	ListenPortMax         int32  `json:"listen_port_max,omitempty"`
+	ListenPortMin         int32  `json:"listen_port_min,omitempty"`
+	RequireDeviceApproval *bool  `json:"require_device_approval,omitempty"`
+	UpdateChannel         string `json:"update_channel,omitempty"`
+}
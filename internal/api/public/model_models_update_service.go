@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsUpdateService struct for ModelsUpdateService
+type ModelsUpdateService struct {
+	Description string `json:"description,omitempty"`
+	DeviceId    string `json:"device_id,omitempty"`
+	Port        int32  `json:"port,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+}
@@ -0,0 +1,19 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsUpdateWebhook struct for ModelsUpdateWebhook
+type ModelsUpdateWebhook struct {
+	Description string   `json:"description,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Url         string   `json:"url,omitempty"`
+}
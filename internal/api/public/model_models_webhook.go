@@ -0,0 +1,21 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsWebhook struct for ModelsWebhook
+type ModelsWebhook struct {
+	Description string `json:"description,omitempty"`
+	// EventTypes are the types of the events posted to the webhook.
+	EventTypes     []string `json:"event_types,omitempty"`
+	Id             string   `json:"id,omitempty"`
+	OrganizationId string   `json:"organization_id,omitempty"`
+	Url            string   `json:"url,omitempty"`
+}
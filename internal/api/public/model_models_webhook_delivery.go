@@ -0,0 +1,23 @@
+/*
+Nexodus API
+
+This is the Nexodus API Server.
+
+API version: 1.0
+*/
+
+// Code generated by OpenAPI Generator (https://openapi-generator.tech); DO NOT EDIT.
+
+package public
+
+// ModelsWebhookDelivery struct for ModelsWebhookDelivery
+type ModelsWebhookDelivery struct {
+	Attempts      int32  `json:"attempts,omitempty"`
+	DeliveredAt   string `json:"delivered_at,omitempty"`
+	EventId       string `json:"event_id,omitempty"`
+	EventType     string `json:"event_type,omitempty"`
+	Id            string `json:"id,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	WebhookId     string `json:"webhook_id,omitempty"`
+}
@@ -0,0 +1,439 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+)
+
+// the message types, RFC 4271 section 4
+const (
+	msgOpen         = 1
+	msgUpdate       = 2
+	msgNotification = 3
+	msgKeepalive    = 4
+)
+
+const (
+	headerLen     = 19
+	maxMessageLen = 4096
+	bgpVersion    = 4
+	// asTrans is the 2-octet AS sent in the OPEN by a speaker with a 4-octet AS, RFC 6793
+	asTrans = 23456
+)
+
+// the path attributes, RFC 4271 section 5
+const (
+	attrOrigin    = 1
+	attrASPath    = 2
+	attrNextHop   = 3
+	attrLocalPref = 5
+	// the IPv6 routes are exchanged in the multiprotocol attributes, RFC 4760
+	attrMPReachNLRI   = 14
+	attrMPUnreachNLRI = 15
+
+	attrFlagOptional = 0x80
+	attrFlagTransit  = 0x40
+	attrFlagExtended = 0x10
+
+	originIncomplete = 2
+	asSequence       = 2
+	asSet            = 1
+)
+
+// the capabilities of the OPEN, RFC 5492
+const (
+	paramCapabilities = 2
+	capMultiprotocol  = 1
+	capAS4            = 65
+	afiIPv4           = 1
+	afiIPv6           = 2
+	safiUnicast       = 1
+)
+
+// the error codes of the NOTIFICATION, RFC 4271 section 4.5
+const (
+	errMessageHeader = 1
+	errOpenMessage   = 2
+	errUpdateMessage = 3
+	errHoldTimer     = 4
+
+	errOpenBadPeerAS   = 2
+	errOpenBadHoldTime = 6
+)
+
+var marker = [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// NotificationError is the error of a session closed with a NOTIFICATION
+type NotificationError struct {
+	Code    uint8
+	Subcode uint8
+	// Sent is true when the NOTIFICATION was sent to the peer rather than received from it
+	Sent bool
+}
+
+func (e *NotificationError) Error() string {
+	if e.Sent {
+		return fmt.Sprintf("sent notification code %d subcode %d", e.Code, e.Subcode)
+	}
+	return fmt.Sprintf("peer sent notification code %d subcode %d", e.Code, e.Subcode)
+}
+
+func writeMessage(w io.Writer, typ uint8, body []byte) error {
+	if headerLen+len(body) > maxMessageLen {
+		return fmt.Errorf("message of %d bytes is too long", headerLen+len(body))
+	}
+	msg := make([]byte, headerLen, headerLen+len(body))
+	copy(msg, marker[:])
+	binary.BigEndian.PutUint16(msg[16:], uint16(headerLen+len(body)))
+	msg[18] = typ
+	_, err := w.Write(append(msg, body...))
+	return err
+}
+
+func readMessage(r io.Reader) (uint8, []byte, error) {
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	if [16]byte(header[:16]) != marker {
+		return 0, nil, &NotificationError{Code: errMessageHeader, Subcode: 1, Sent: true}
+	}
+	length := int(binary.BigEndian.Uint16(header[16:]))
+	if length < headerLen || length > maxMessageLen {
+		return 0, nil, &NotificationError{Code: errMessageHeader, Subcode: 2, Sent: true}
+	}
+	body := make([]byte, length-headerLen)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[18], body, nil
+}
+
+type openMessage struct {
+	as       uint32
+	holdTime uint16
+	routerID netip.Addr
+	// as4 is true when the speaker supports 4-octet AS numbers
+	as4 bool
+	// ipv6 is true when the speaker supports the IPv6 unicast routes
+	ipv6 bool
+}
+
+func (o openMessage) marshal() []byte {
+	as2 := uint16(asTrans)
+	if o.as <= 0xffff {
+		as2 = uint16(o.as)
+	}
+	caps := []byte{
+		capMultiprotocol, 4, 0, afiIPv4, 0, safiUnicast,
+		capAS4, 4, 0, 0, 0, 0,
+	}
+	binary.BigEndian.PutUint32(caps[8:], o.as)
+	if o.ipv6 {
+		caps = append(caps, capMultiprotocol, 4, 0, afiIPv6, 0, safiUnicast)
+	}
+
+	body := make([]byte, 10, 12+len(caps))
+	body[0] = bgpVersion
+	binary.BigEndian.PutUint16(body[1:], as2)
+	binary.BigEndian.PutUint16(body[3:], o.holdTime)
+	id := o.routerID.As4()
+	copy(body[5:], id[:])
+	body[9] = byte(2 + len(caps))
+	body = append(body, paramCapabilities, byte(len(caps)))
+	return append(body, caps...)
+}
+
+func parseOpen(body []byte) (openMessage, error) {
+	if len(body) < 10 {
+		return openMessage{}, &NotificationError{Code: errMessageHeader, Subcode: 2, Sent: true}
+	}
+	if body[0] != bgpVersion {
+		return openMessage{}, &NotificationError{Code: errOpenMessage, Subcode: 1, Sent: true}
+	}
+	o := openMessage{
+		as:       uint32(binary.BigEndian.Uint16(body[1:])),
+		holdTime: binary.BigEndian.Uint16(body[3:]),
+		routerID: netip.AddrFrom4([4]byte(body[5:9])),
+	}
+	params := body[10:]
+	if len(params) != int(body[9]) {
+		return openMessage{}, &NotificationError{Code: errOpenMessage, Sent: true}
+	}
+	for len(params) >= 2 {
+		typ, length := params[0], int(params[1])
+		if len(params) < 2+length {
+			return openMessage{}, &NotificationError{Code: errOpenMessage, Sent: true}
+		}
+		value := params[2 : 2+length]
+		params = params[2+length:]
+		if typ != paramCapabilities {
+			continue
+		}
+		for len(value) >= 2 {
+			code, capLen := value[0], int(value[1])
+			if len(value) < 2+capLen {
+				return openMessage{}, &NotificationError{Code: errOpenMessage, Sent: true}
+			}
+			if code == capAS4 && capLen == 4 {
+				o.as4 = true
+				o.as = binary.BigEndian.Uint32(value[2:])
+			}
+			if code == capMultiprotocol && capLen == 4 && binary.BigEndian.Uint16(value[2:]) == afiIPv6 && value[5] == safiUnicast {
+				o.ipv6 = true
+			}
+			value = value[2+capLen:]
+		}
+	}
+	return o, nil
+}
+
+// updateMessage is an UPDATE of the IPv4 and IPv6 unicast routes, the IPv6 prefixes are withdrawn and announced
+// in the multiprotocol attributes with their own next hop
+type updateMessage struct {
+	withdrawn []netip.Prefix
+	nlri      []netip.Prefix
+	nextHop   netip.Addr
+	nextHop6  netip.Addr
+	asPath    []uint32
+	// localPref is sent to the peers of the same AS only
+	localPref uint32
+}
+
+// marshal encodes the update, as4 selects the size of the AS numbers of the AS_PATH
+func (u updateMessage) marshal(as4 bool) []byte {
+	withdrawn4, withdrawn6 := splitPrefixes(u.withdrawn)
+	nlri4, nlri6 := splitPrefixes(u.nlri)
+	withdrawn := marshalPrefixes(withdrawn4)
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(withdrawn)))
+	body = append(body, withdrawn...)
+
+	var attrs []byte
+	if len(u.nlri) != 0 {
+		attrs = appendAttr(attrs, attrFlagTransit, attrOrigin, []byte{originIncomplete})
+		var path []byte
+		if len(u.asPath) != 0 {
+			path = []byte{asSequence, byte(len(u.asPath))}
+			for _, as := range u.asPath {
+				if as4 {
+					path = binary.BigEndian.AppendUint32(path, as)
+				} else if as > 0xffff {
+					path = binary.BigEndian.AppendUint16(path, asTrans)
+				} else {
+					path = binary.BigEndian.AppendUint16(path, uint16(as))
+				}
+			}
+		}
+		attrs = appendAttr(attrs, attrFlagTransit, attrASPath, path)
+		if len(nlri4) != 0 {
+			nextHop := u.nextHop.As4()
+			attrs = appendAttr(attrs, attrFlagTransit, attrNextHop, nextHop[:])
+		}
+		if u.localPref != 0 {
+			attrs = appendAttr(attrs, attrFlagTransit, attrLocalPref, binary.BigEndian.AppendUint32(nil, u.localPref))
+		}
+	}
+	if len(nlri6) != 0 {
+		nextHop := u.nextHop6.As16()
+		value := []byte{0, afiIPv6, safiUnicast, byte(len(nextHop))}
+		value = append(value, nextHop[:]...)
+		// the reserved octet
+		value = append(value, 0)
+		attrs = appendAttr(attrs, attrFlagOptional, attrMPReachNLRI, append(value, marshalPrefixes(nlri6)...))
+	}
+	if len(withdrawn6) != 0 {
+		attrs = appendAttr(attrs, attrFlagOptional, attrMPUnreachNLRI, append([]byte{0, afiIPv6, safiUnicast}, marshalPrefixes(withdrawn6)...))
+	}
+	body = binary.BigEndian.AppendUint16(body, uint16(len(attrs)))
+	body = append(body, attrs...)
+	return append(body, marshalPrefixes(nlri4)...)
+}
+
+// splitPrefixes returns the IPv4 and the IPv6 prefixes
+func splitPrefixes(prefixes []netip.Prefix) ([]netip.Prefix, []netip.Prefix) {
+	var prefixes4, prefixes6 []netip.Prefix
+	for _, prefix := range prefixes {
+		if prefix.Addr().Is4() {
+			prefixes4 = append(prefixes4, prefix)
+		} else {
+			prefixes6 = append(prefixes6, prefix)
+		}
+	}
+	return prefixes4, prefixes6
+}
+
+func appendAttr(b []byte, flags, typ uint8, value []byte) []byte {
+	if len(value) > 0xff {
+		b = append(b, flags|attrFlagExtended, typ)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	} else {
+		b = append(b, flags, typ, byte(len(value)))
+	}
+	return append(b, value...)
+}
+
+func parseUpdate(body []byte, as4 bool) (updateMessage, error) {
+	malformed := &NotificationError{Code: errUpdateMessage, Subcode: 1, Sent: true}
+	u := updateMessage{}
+	if len(body) < 4 {
+		return u, malformed
+	}
+	withdrawnLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 4+withdrawnLen {
+		return u, malformed
+	}
+	var err error
+	if u.withdrawn, err = parsePrefixes(body[2:2+withdrawnLen], false); err != nil {
+		return u, malformed
+	}
+	body = body[2+withdrawnLen:]
+	attrsLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+attrsLen {
+		return u, malformed
+	}
+	attrs := body[2 : 2+attrsLen]
+	if u.nlri, err = parsePrefixes(body[2+attrsLen:], false); err != nil {
+		return u, malformed
+	}
+	nlri4 := len(u.nlri) != 0
+
+	for len(attrs) > 0 {
+		if len(attrs) < 3 {
+			return u, malformed
+		}
+		flags, typ := attrs[0], attrs[1]
+		var length int
+		if flags&attrFlagExtended != 0 {
+			if len(attrs) < 4 {
+				return u, malformed
+			}
+			length = int(binary.BigEndian.Uint16(attrs[2:]))
+			attrs = attrs[4:]
+		} else {
+			length = int(attrs[2])
+			attrs = attrs[3:]
+		}
+		if len(attrs) < length {
+			return u, malformed
+		}
+		value := attrs[:length]
+		attrs = attrs[length:]
+
+		switch typ {
+		case attrNextHop:
+			if length != 4 {
+				return u, malformed
+			}
+			u.nextHop = netip.AddrFrom4([4]byte(value))
+		case attrLocalPref:
+			if length != 4 {
+				return u, malformed
+			}
+			u.localPref = binary.BigEndian.Uint32(value)
+		case attrMPReachNLRI:
+			if length < 5 {
+				return u, malformed
+			}
+			nextHopLen := int(value[3])
+			if length < 5+nextHopLen {
+				return u, malformed
+			}
+			// the routes of the other address families are ignored
+			if binary.BigEndian.Uint16(value) != afiIPv6 || value[2] != safiUnicast {
+				continue
+			}
+			// the next hop is the global address, optionally followed by the link local address
+			if nextHopLen != 16 && nextHopLen != 32 {
+				return u, malformed
+			}
+			u.nextHop6 = netip.AddrFrom16([16]byte(value[4:20]))
+			prefixes, err := parsePrefixes(value[5+nextHopLen:], true)
+			if err != nil {
+				return u, malformed
+			}
+			u.nlri = append(u.nlri, prefixes...)
+		case attrMPUnreachNLRI:
+			if length < 3 {
+				return u, malformed
+			}
+			if binary.BigEndian.Uint16(value) != afiIPv6 || value[2] != safiUnicast {
+				continue
+			}
+			prefixes, err := parsePrefixes(value[3:], true)
+			if err != nil {
+				return u, malformed
+			}
+			u.withdrawn = append(u.withdrawn, prefixes...)
+		case attrASPath:
+			asLen := 2
+			if as4 {
+				asLen = 4
+			}
+			for len(value) > 0 {
+				if len(value) < 2 || len(value) < 2+int(value[1])*asLen {
+					return u, malformed
+				}
+				segment, count := value[0], int(value[1])
+				if segment != asSequence && segment != asSet {
+					return u, malformed
+				}
+				for i := 0; i < count; i++ {
+					as := value[2+i*asLen:]
+					if as4 {
+						u.asPath = append(u.asPath, binary.BigEndian.Uint32(as))
+					} else {
+						u.asPath = append(u.asPath, uint32(binary.BigEndian.Uint16(as)))
+					}
+				}
+				value = value[2+count*asLen:]
+			}
+		}
+	}
+	if nlri4 && !u.nextHop.IsValid() {
+		// the NEXT_HOP is a well-known mandatory attribute
+		return u, &NotificationError{Code: errUpdateMessage, Subcode: 3, Sent: true}
+	}
+	return u, nil
+}
+
+func marshalPrefixes(prefixes []netip.Prefix) []byte {
+	var b []byte
+	for _, prefix := range prefixes {
+		addr := prefix.Addr().AsSlice()
+		b = append(b, byte(prefix.Bits()))
+		b = append(b, addr[:(prefix.Bits()+7)/8]...)
+	}
+	return b
+}
+
+// parsePrefixes parses the IPv4 prefixes, or the IPv6 prefixes of the multiprotocol attributes
+func parsePrefixes(b []byte, ipv6 bool) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for len(b) > 0 {
+		bits := int(b[0])
+		n := (bits + 7) / 8
+		if ipv6 && bits > 128 || !ipv6 && bits > 32 || len(b) < 1+n {
+			return nil, errors.New("invalid prefix")
+		}
+		var addr [16]byte
+		copy(addr[:], b[1:1+n])
+		if ipv6 {
+			prefixes = append(prefixes, netip.PrefixFrom(netip.AddrFrom16(addr), bits).Masked())
+		} else {
+			prefixes = append(prefixes, netip.PrefixFrom(netip.AddrFrom4([4]byte(addr[:4])), bits).Masked())
+		}
+		b = b[1+n:]
+	}
+	return prefixes, nil
+}
+
+func parseNotification(body []byte) *NotificationError {
+	e := &NotificationError{}
+	if len(body) >= 2 {
+		e.Code, e.Subcode = body[0], body[1]
+	}
+	return e
+}
@@ -0,0 +1,103 @@
+package bgp
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMessage(t *testing.T) {
+	require := require.New(t)
+
+	open := openMessage{as: 4200000001, holdTime: 90, routerID: netip.MustParseAddr("192.168.1.1"), as4: true}
+	parsed, err := parseOpen(open.marshal())
+	require.NoError(err)
+	require.Equal(open, parsed)
+	open.ipv6 = true
+	parsed, err = parseOpen(open.marshal())
+	require.NoError(err)
+	require.Equal(open, parsed)
+
+	// a speaker without the 4-octet AS capability
+	parsed, err = parseOpen([]byte{4, 0xfd, 0xe8, 0, 180, 10, 0, 0, 1, 0})
+	require.NoError(err)
+	require.Equal(openMessage{as: 65000, holdTime: 180, routerID: netip.MustParseAddr("10.0.0.1")}, parsed)
+
+	_, err = parseOpen([]byte{3, 0xfd, 0xe8, 0, 180, 10, 0, 0, 1, 0})
+	require.Error(err)
+}
+
+func TestUpdateMessage(t *testing.T) {
+	require := require.New(t)
+
+	update := updateMessage{
+		withdrawn: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")},
+		nlri:      []netip.Prefix{netip.MustParsePrefix("100.64.0.0/10"), netip.MustParsePrefix("192.168.7.0/24"), netip.MustParsePrefix("0.0.0.0/0")},
+		nextHop:   netip.MustParseAddr("192.168.1.1"),
+		asPath:    []uint32{65001, 4200000001},
+	}
+	for _, as4 := range []bool{true, false} {
+		parsed, err := parseUpdate(update.marshal(as4), as4)
+		require.NoError(err)
+		if !as4 {
+			update.asPath[1] = asTrans
+		}
+		require.Equal(update, parsed)
+	}
+
+	// the announcements require a next hop
+	_, err := parseUpdate([]byte{0, 0, 0, 0, 24, 192, 168, 7}, true)
+	require.Error(err)
+
+	withdraw := updateMessage{withdrawn: []netip.Prefix{netip.MustParsePrefix("192.168.7.0/24")}}
+	parsed, err := parseUpdate(withdraw.marshal(true), true)
+	require.NoError(err)
+	require.Equal(withdraw, parsed)
+}
+
+func TestUpdateMessageIPv6(t *testing.T) {
+	require := require.New(t)
+
+	update := updateMessage{
+		withdrawn: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("fd00:1::/48")},
+		nlri:      []netip.Prefix{netip.MustParsePrefix("192.168.7.0/24"), netip.MustParsePrefix("2001:db8::/32"), netip.MustParsePrefix("fd00:2::/64")},
+		nextHop:   netip.MustParseAddr("192.168.1.1"),
+		nextHop6:  netip.MustParseAddr("2001:db8::1"),
+		asPath:    []uint32{65001},
+	}
+	parsed, err := parseUpdate(update.marshal(true), true)
+	require.NoError(err)
+	require.Equal(update, parsed)
+
+	// an UPDATE of the IPv6 routes only has no NEXT_HOP attribute
+	update = updateMessage{
+		nlri:     []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")},
+		nextHop6: netip.MustParseAddr("2001:db8::1"),
+		asPath:   []uint32{65001},
+	}
+	parsed, err = parseUpdate(update.marshal(true), true)
+	require.NoError(err)
+	require.Equal(update, parsed)
+
+	withdraw := updateMessage{withdrawn: []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")}}
+	parsed, err = parseUpdate(withdraw.marshal(true), true)
+	require.NoError(err)
+	require.Equal(withdraw, parsed)
+}
+
+func TestReadMessage(t *testing.T) {
+	require := require.New(t)
+
+	buf := &bytes.Buffer{}
+	require.NoError(writeMessage(buf, msgKeepalive, nil))
+	require.Equal(headerLen, buf.Len())
+	typ, body, err := readMessage(buf)
+	require.NoError(err)
+	require.Equal(uint8(msgKeepalive), typ)
+	require.Empty(body)
+
+	_, _, err = readMessage(bytes.NewReader(make([]byte, headerLen)))
+	require.ErrorContains(err, "notification code 1")
+}
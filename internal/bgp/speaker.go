@@ -0,0 +1,451 @@
+// Package bgp is a minimal BGP-4 speaker for the IPv4 and IPv6 unicast routes of a single peer. It keeps a session with the
+// router of a site, announces the exported prefixes to it and learns the prefixes the router announces.
+package bgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPort     = 179
+	defaultHoldTime = 90 * time.Second
+	// the time a session may take to exchange the OPEN and KEEPALIVE messages
+	openTimeout = 30 * time.Second
+	// the time to wait before connecting again after a session failed
+	connectRetry = 10 * time.Second
+	// the number of prefixes per UPDATE, so that the message fits in 4096 bytes
+	prefixesPerUpdate  = 500
+	prefixes6PerUpdate = 200
+	localPref          = 100
+)
+
+// Config is the configuration of the session with the peer
+type Config struct {
+	LocalAS  uint32
+	PeerAS   uint32
+	RouterID netip.Addr
+	// Peer is the address of the peer, the port defaults to 179
+	Peer netip.AddrPort
+	// NextHop is the next hop of the exported IPv4 prefixes, by default the local address of a session over IPv4.
+	// The IPv4 prefixes are not exported without it.
+	NextHop netip.Addr
+	// NextHop6 is the next hop of the exported IPv6 prefixes, by default the local address of a session over IPv6.
+	// The IPv6 prefixes are not exported without it.
+	NextHop6 netip.Addr
+	// HoldTime defaults to 90 seconds
+	HoldTime time.Duration
+}
+
+// ParsePeer parses the address of the peer with an optional port
+func ParsePeer(peer string) (netip.AddrPort, error) {
+	if addr, err := netip.ParseAddr(peer); err == nil {
+		return netip.AddrPortFrom(addr, defaultPort), nil
+	}
+	addrPort, err := netip.ParseAddrPort(peer)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("invalid BGP peer %s, it must be an address with an optional port", peer)
+	}
+	return addrPort, nil
+}
+
+// Speaker keeps the session with the peer
+type Speaker struct {
+	config Config
+	logger *zap.SugaredLogger
+
+	lock     sync.Mutex
+	exported []netip.Prefix
+	imported map[netip.Prefix]netip.Addr
+	session  *session
+	changed  chan struct{}
+}
+
+type session struct {
+	conn      net.Conn
+	writeLock sync.Mutex
+	as4       bool
+	// ipv6 is true when the peer supports the IPv6 unicast routes
+	ipv6     bool
+	holdTime time.Duration
+	nextHop  netip.Addr
+	nextHop6 netip.Addr
+}
+
+// NewSpeaker returns the speaker of the session configured by config
+func NewSpeaker(config Config, logger *zap.SugaredLogger) (*Speaker, error) {
+	if config.LocalAS == 0 || config.PeerAS == 0 {
+		return nil, errors.New("the local and peer AS numbers are required")
+	}
+	if !config.RouterID.Is4() {
+		return nil, fmt.Errorf("invalid router id %s, it must be an IPv4 address", config.RouterID)
+	}
+	if config.NextHop.IsValid() && !config.NextHop.Is4() {
+		return nil, fmt.Errorf("invalid next hop %s, it must be an IPv4 address", config.NextHop)
+	}
+	if config.NextHop6.IsValid() && !config.NextHop6.Is6() {
+		return nil, fmt.Errorf("invalid IPv6 next hop %s, it must be an IPv6 address", config.NextHop6)
+	}
+	if !config.Peer.Addr().IsValid() {
+		return nil, errors.New("the address of the peer is required")
+	}
+	if config.HoldTime == 0 {
+		config.HoldTime = defaultHoldTime
+	}
+	if config.HoldTime < 3*time.Second {
+		return nil, fmt.Errorf("invalid hold time %s, it must be at least 3s", config.HoldTime)
+	}
+	return &Speaker{
+		config:   config,
+		logger:   logger,
+		imported: map[netip.Prefix]netip.Addr{},
+		changed:  make(chan struct{}, 1),
+	}, nil
+}
+
+// Changed is signaled when the imported prefixes changed
+func (s *Speaker) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// Established is true while the session with the peer is established
+func (s *Speaker) Established() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.session != nil
+}
+
+// Imported returns the sorted prefixes announced by the peer
+func (s *Speaker) Imported() []netip.Prefix {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	prefixes := make([]netip.Prefix, 0, len(s.imported))
+	for prefix := range s.imported {
+		prefixes = append(prefixes, prefix)
+	}
+	sortPrefixes(prefixes)
+	return prefixes
+}
+
+// SetExported replaces the prefixes announced to the peer, only the changes are sent
+func (s *Speaker) SetExported(prefixes []netip.Prefix) {
+	prefixes = slices.Clone(prefixes)
+	sortPrefixes(prefixes)
+	prefixes = slices.Compact(prefixes)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if slices.Equal(prefixes, s.exported) {
+		return
+	}
+	var withdrawn, announced []netip.Prefix
+	for _, prefix := range s.exported {
+		if !slices.Contains(prefixes, prefix) {
+			withdrawn = append(withdrawn, prefix)
+		}
+	}
+	for _, prefix := range prefixes {
+		if !slices.Contains(s.exported, prefix) {
+			announced = append(announced, prefix)
+		}
+	}
+	s.exported = prefixes
+	if s.session != nil {
+		if err := s.sendUpdates(s.session, withdrawn, announced); err != nil {
+			s.logger.Warnf("failed to send the BGP updates: %v", err)
+			_ = s.session.conn.Close()
+		}
+	}
+}
+
+// Run keeps the session with the peer until ctx is done
+func (s *Speaker) Run(ctx context.Context) {
+	for {
+		err := s.connect(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warnf("BGP session with %s failed: %v", s.config.Peer, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(connectRetry):
+		}
+	}
+}
+
+func (s *Speaker) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: openTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.config.Peer.String())
+	if err != nil {
+		return err
+	}
+	return s.runSession(ctx, conn)
+}
+
+func (s *Speaker) runSession(ctx context.Context, conn net.Conn) error {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	sess, err := s.open(conn)
+	if err != nil {
+		var notification *NotificationError
+		if errors.As(err, &notification) && notification.Sent {
+			_ = writeMessage(conn, msgNotification, []byte{notification.Code, notification.Subcode})
+		}
+		return err
+	}
+
+	s.lock.Lock()
+	s.session = sess
+	err = s.sendUpdates(sess, nil, s.exported)
+	s.lock.Unlock()
+	s.logger.Infof("BGP session with %s established", s.config.Peer)
+	defer s.closeSession()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go s.sendKeepalives(sess, done)
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(sess.holdTime))
+		typ, body, err := readMessage(conn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				err = &NotificationError{Code: errHoldTimer, Sent: true}
+			}
+			return s.fail(sess, err)
+		}
+		switch typ {
+		case msgKeepalive:
+		case msgNotification:
+			return parseNotification(body)
+		case msgUpdate:
+			update, err := parseUpdate(body, sess.as4)
+			if err != nil {
+				return s.fail(sess, err)
+			}
+			s.applyUpdate(update)
+		default:
+			return s.fail(sess, &NotificationError{Code: errMessageHeader, Subcode: 3, Sent: true})
+		}
+	}
+}
+
+// open exchanges the OPEN and KEEPALIVE messages that establish the session
+func (s *Speaker) open(conn net.Conn) (*session, error) {
+	_ = conn.SetDeadline(time.Now().Add(openTimeout))
+	defer func() {
+		_ = conn.SetDeadline(time.Time{})
+	}()
+	local := openMessage{
+		as:       s.config.LocalAS,
+		holdTime: uint16(s.config.HoldTime / time.Second),
+		routerID: s.config.RouterID,
+		as4:      true,
+		ipv6:     true,
+	}
+	if err := writeMessage(conn, msgOpen, local.marshal()); err != nil {
+		return nil, err
+	}
+
+	typ, body, err := readMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case msgNotification:
+		return nil, parseNotification(body)
+	case msgOpen:
+	default:
+		return nil, &NotificationError{Code: errMessageHeader, Subcode: 3, Sent: true}
+	}
+	peer, err := parseOpen(body)
+	if err != nil {
+		return nil, err
+	}
+	if peer.as != s.config.PeerAS {
+		return nil, &NotificationError{Code: errOpenMessage, Subcode: errOpenBadPeerAS, Sent: true}
+	}
+	if peer.holdTime != 0 && peer.holdTime < 3 {
+		return nil, &NotificationError{Code: errOpenMessage, Subcode: errOpenBadHoldTime, Sent: true}
+	}
+	sess := &session{conn: conn, as4: peer.as4, ipv6: peer.ipv6, holdTime: s.config.HoldTime, nextHop: s.config.NextHop, nextHop6: s.config.NextHop6}
+	// the local address of the session is the next hop of its address family
+	localAddr, err := netip.ParseAddrPort(conn.LocalAddr().String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid local address of the session: %w", err)
+	}
+	if addr := localAddr.Addr().Unmap(); addr.Is4() && !sess.nextHop.IsValid() {
+		sess.nextHop = addr
+	} else if addr.Is6() && !sess.nextHop6.IsValid() {
+		sess.nextHop6 = addr
+	}
+	if peer.holdTime != 0 {
+		sess.holdTime = min(sess.holdTime, time.Duration(peer.holdTime)*time.Second)
+	}
+
+	if err := writeMessage(conn, msgKeepalive, nil); err != nil {
+		return nil, err
+	}
+	typ, body, err = readMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case msgKeepalive:
+		return sess, nil
+	case msgNotification:
+		return nil, parseNotification(body)
+	default:
+		return nil, &NotificationError{Code: errMessageHeader, Subcode: 3, Sent: true}
+	}
+}
+
+func (s *Speaker) sendKeepalives(sess *session, done chan struct{}) {
+	ticker := time.NewTicker(sess.holdTime / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := sess.send(msgKeepalive, nil); err != nil {
+				_ = sess.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// fail sends the NOTIFICATION of err to the peer before the session is closed
+func (s *Speaker) fail(sess *session, err error) error {
+	var notification *NotificationError
+	if errors.As(err, &notification) && notification.Sent {
+		_ = sess.send(msgNotification, []byte{notification.Code, notification.Subcode})
+	}
+	return err
+}
+
+// closeSession withdraws the prefixes learned in the session
+func (s *Speaker) closeSession() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.session = nil
+	if len(s.imported) != 0 {
+		s.imported = map[netip.Prefix]netip.Addr{}
+		s.notifyChanged()
+	}
+}
+
+func (s *Speaker) applyUpdate(update updateMessage) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	changed := false
+	for _, prefix := range update.withdrawn {
+		if _, found := s.imported[prefix]; found {
+			delete(s.imported, prefix)
+			changed = true
+		}
+	}
+	// the routes that went through this AS are a loop
+	if !slices.Contains(update.asPath, s.config.LocalAS) {
+		for _, prefix := range update.nlri {
+			updateNextHop := update.nextHop
+			if prefix.Addr().Is6() {
+				updateNextHop = update.nextHop6
+			}
+			if nextHop, found := s.imported[prefix]; !found || nextHop != updateNextHop {
+				s.imported[prefix] = updateNextHop
+				changed = true
+			}
+		}
+	}
+	if changed {
+		s.notifyChanged()
+	}
+}
+
+func (s *Speaker) notifyChanged() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// sendUpdates sends the withdrawn and announced prefixes, the caller holds the lock. The prefixes of an address
+// family are only sent when the session has a next hop of the family, and the IPv6 prefixes to a peer that
+// supports them.
+func (s *Speaker) sendUpdates(sess *session, withdrawn, announced []netip.Prefix) error {
+	withdrawn4, withdrawn6 := splitPrefixes(withdrawn)
+	announced4, announced6 := splitPrefixes(announced)
+	if !sess.nextHop.IsValid() {
+		withdrawn4, announced4 = nil, nil
+	}
+	if !sess.ipv6 || !sess.nextHop6.IsValid() {
+		withdrawn6, announced6 = nil, nil
+	}
+	if err := s.sendFamilyUpdates(sess, withdrawn4, announced4, prefixesPerUpdate); err != nil {
+		return err
+	}
+	return s.sendFamilyUpdates(sess, withdrawn6, announced6, prefixes6PerUpdate)
+}
+
+// sendFamilyUpdates sends the withdrawn and announced prefixes of an address family, up to n per UPDATE
+func (s *Speaker) sendFamilyUpdates(sess *session, withdrawn, announced []netip.Prefix, perUpdate int) error {
+	for len(withdrawn) > 0 {
+		n := min(len(withdrawn), perUpdate)
+		if err := sess.send(msgUpdate, updateMessage{withdrawn: withdrawn[:n]}.marshal(sess.as4)); err != nil {
+			return err
+		}
+		withdrawn = withdrawn[n:]
+	}
+	for len(announced) > 0 {
+		n := min(len(announced), perUpdate)
+		update := updateMessage{nlri: announced[:n], nextHop: sess.nextHop, nextHop6: sess.nextHop6}
+		if s.config.LocalAS == s.config.PeerAS {
+			update.localPref = localPref
+		} else {
+			update.asPath = []uint32{s.config.LocalAS}
+		}
+		if err := sess.send(msgUpdate, update.marshal(sess.as4)); err != nil {
+			return err
+		}
+		announced = announced[n:]
+	}
+	return nil
+}
+
+func (sess *session) send(typ uint8, body []byte) error {
+	sess.writeLock.Lock()
+	defer sess.writeLock.Unlock()
+	_ = sess.conn.SetWriteDeadline(time.Now().Add(sess.holdTime))
+	return writeMessage(sess.conn, typ, body)
+}
+
+func sortPrefixes(prefixes []netip.Prefix) {
+	slices.SortFunc(prefixes, func(a, b netip.Prefix) int {
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c
+		}
+		return a.Bits() - b.Bits()
+	})
+}
@@ -0,0 +1,168 @@
+package bgp
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSpeaker(t *testing.T) {
+	require := require.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer listener.Close()
+
+	speaker, err := NewSpeaker(Config{
+		LocalAS:  65001,
+		PeerAS:   65000,
+		RouterID: netip.MustParseAddr("192.168.1.2"),
+		Peer:     netip.MustParseAddrPort(listener.Addr().String()),
+	}, zap.NewNop().Sugar())
+	require.NoError(err)
+	overlay := netip.MustParsePrefix("100.64.0.0/10")
+	speaker.SetExported([]netip.Prefix{overlay})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go speaker.Run(ctx)
+
+	conn, err := listener.Accept()
+	require.NoError(err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// the session is established
+	typ, body, err := readMessage(conn)
+	require.NoError(err)
+	require.Equal(uint8(msgOpen), typ)
+	open, err := parseOpen(body)
+	require.NoError(err)
+	require.Equal(uint32(65001), open.as)
+	require.NoError(writeMessage(conn, msgOpen, openMessage{as: 65000, holdTime: 90, routerID: netip.MustParseAddr("192.168.1.1"), as4: true}.marshal()))
+	require.NoError(writeMessage(conn, msgKeepalive, nil))
+	typ, _, err = readMessage(conn)
+	require.NoError(err)
+	require.Equal(uint8(msgKeepalive), typ)
+
+	// the exported prefixes are announced
+	update := readUpdate(t, conn)
+	require.Equal([]netip.Prefix{overlay}, update.nlri)
+	// the local address of the session
+	require.Equal(netip.MustParseAddr("127.0.0.1"), update.nextHop)
+	require.Equal([]uint32{65001}, update.asPath)
+
+	// the announced prefixes are imported, except the ones that went through the AS of the speaker
+	datacenter := netip.MustParsePrefix("10.20.0.0/16")
+	require.NoError(writeMessage(conn, msgUpdate, updateMessage{
+		nlri:    []netip.Prefix{netip.MustParsePrefix("10.30.0.0/16")},
+		nextHop: netip.MustParseAddr("192.168.1.1"),
+		asPath:  []uint32{65000, 65001},
+	}.marshal(true)))
+	require.NoError(writeMessage(conn, msgUpdate, updateMessage{
+		nlri:    []netip.Prefix{datacenter},
+		nextHop: netip.MustParseAddr("192.168.1.1"),
+		asPath:  []uint32{65000},
+	}.marshal(true)))
+	waitChanged(t, speaker)
+	require.Equal([]netip.Prefix{datacenter}, speaker.Imported())
+
+	require.NoError(writeMessage(conn, msgUpdate, updateMessage{withdrawn: []netip.Prefix{datacenter}}.marshal(true)))
+	waitChanged(t, speaker)
+	require.Empty(speaker.Imported())
+
+	// the prefixes that are no longer exported are withdrawn
+	speaker.SetExported(nil)
+	update = readUpdate(t, conn)
+	require.Equal([]netip.Prefix{overlay}, update.withdrawn)
+	require.Empty(update.nlri)
+	require.True(speaker.Established())
+}
+
+func TestSpeakerIPv6(t *testing.T) {
+	require := require.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	defer listener.Close()
+
+	speaker, err := NewSpeaker(Config{
+		LocalAS:  65001,
+		PeerAS:   65000,
+		RouterID: netip.MustParseAddr("192.168.1.2"),
+		Peer:     netip.MustParseAddrPort(listener.Addr().String()),
+		NextHop6: netip.MustParseAddr("2001:db8::2"),
+	}, zap.NewNop().Sugar())
+	require.NoError(err)
+	overlay := netip.MustParsePrefix("100.64.0.0/10")
+	overlay6 := netip.MustParsePrefix("fd00:100::/64")
+	speaker.SetExported([]netip.Prefix{overlay6, overlay})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go speaker.Run(ctx)
+
+	conn, err := listener.Accept()
+	require.NoError(err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// the speaker supports the IPv6 unicast routes
+	typ, body, err := readMessage(conn)
+	require.NoError(err)
+	require.Equal(uint8(msgOpen), typ)
+	open, err := parseOpen(body)
+	require.NoError(err)
+	require.True(open.ipv6)
+	require.NoError(writeMessage(conn, msgOpen, openMessage{as: 65000, holdTime: 90, routerID: netip.MustParseAddr("192.168.1.1"), as4: true, ipv6: true}.marshal()))
+	require.NoError(writeMessage(conn, msgKeepalive, nil))
+	typ, _, err = readMessage(conn)
+	require.NoError(err)
+	require.Equal(uint8(msgKeepalive), typ)
+
+	// the prefixes of each address family are announced in their own UPDATE
+	update := readUpdate(t, conn)
+	require.Equal([]netip.Prefix{overlay}, update.nlri)
+	update = readUpdate(t, conn)
+	require.Equal([]netip.Prefix{overlay6}, update.nlri)
+	require.Equal(netip.MustParseAddr("2001:db8::2"), update.nextHop6)
+
+	datacenter6 := netip.MustParsePrefix("2001:db8:20::/48")
+	require.NoError(writeMessage(conn, msgUpdate, updateMessage{
+		nlri:     []netip.Prefix{datacenter6},
+		nextHop6: netip.MustParseAddr("2001:db8::1"),
+		asPath:   []uint32{65000},
+	}.marshal(true)))
+	waitChanged(t, speaker)
+	require.Equal([]netip.Prefix{datacenter6}, speaker.Imported())
+
+	require.NoError(writeMessage(conn, msgUpdate, updateMessage{withdrawn: []netip.Prefix{datacenter6}}.marshal(true)))
+	waitChanged(t, speaker)
+	require.Empty(speaker.Imported())
+
+	speaker.SetExported([]netip.Prefix{overlay})
+	update = readUpdate(t, conn)
+	require.Equal([]netip.Prefix{overlay6}, update.withdrawn)
+}
+
+func readUpdate(t *testing.T, conn net.Conn) updateMessage {
+	typ, body, err := readMessage(conn)
+	require.NoError(t, err)
+	require.Equal(t, uint8(msgUpdate), typ)
+	update, err := parseUpdate(body, true)
+	require.NoError(t, err)
+	return update
+}
+
+func waitChanged(t *testing.T, speaker *Speaker) {
+	select {
+	case <-speaker.Changed():
+	case <-time.After(10 * time.Second):
+		t.Fatal("the imported prefixes did not change")
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/nexodus-io/nexodus/internal/api/public"
+	"golang.org/x/net/http/httpproxy"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 type APIClient = public.APIClient
@@ -21,6 +24,38 @@ func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error)
 	return fn(req)
 }
 
+// UpgradeRequiredError is returned when the nexodus service no longer supports the version of the client
+type UpgradeRequiredError struct {
+	CurrentVersion string `json:"current_version"`
+	MinimumVersion string `json:"minimum_version"`
+	Instructions   string `json:"instructions"`
+}
+
+func (e *UpgradeRequiredError) Error() string {
+	msg := fmt.Sprintf("version %s is no longer supported by the nexodus service, please upgrade to version %s or newer", e.CurrentVersion, e.MinimumVersion)
+	if e.Instructions != "" {
+		msg += ": " + e.Instructions
+	}
+	return msg
+}
+
+// upgradeRequiredTransport turns HTTP 426 responses into an UpgradeRequiredError so that callers
+// can report why the request failed instead of a generic api error.
+func upgradeRequiredTransport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusUpgradeRequired {
+			return resp, err
+		}
+		defer resp.Body.Close()
+		upgradeErr := &UpgradeRequiredError{}
+		if err := json.NewDecoder(resp.Body).Decode(upgradeErr); err != nil {
+			return nil, fmt.Errorf("the nexodus service requires a newer client version: %w", err)
+		}
+		return nil, upgradeErr
+	})
+}
+
 func NewAPIClient(ctx context.Context, addr string, authcb func(string), options ...Option) (*APIClient, error) {
 	opts, err := newOptions(options...)
 	if err != nil {
@@ -38,13 +73,14 @@ func NewAPIClient(ctx context.Context, addr string, authcb func(string), options
 	}
 	clientConfig := public.NewConfiguration()
 	clientConfig.HTTPClient = &http.Client{
-		Transport: &http.Transport{
+		Transport: upgradeRequiredTransport(&http.Transport{
+			Proxy:                 ProxyFunc(opts.proxyURL),
 			DialContext:           dialer.DialContext,
 			TLSHandshakeTimeout:   10 * time.Second,
 			ResponseHeaderTimeout: 30 * time.Second,
 			ExpectContinueTimeout: 5 * time.Second,
 			TLSClientConfig:       opts.tlsConfig,
-		},
+		}),
 	}
 	clientConfig.Host = baseURL.Host
 	clientConfig.Scheme = baseURL.Scheme
@@ -57,6 +93,14 @@ func NewAPIClient(ctx context.Context, addr string, authcb func(string), options
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", opts.bearerToken))
 			return nextTransport.RoundTrip(req)
 		})
+	} else if opts.serviceAccountID != "" {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, clientConfig.HTTPClient)
+		config := &clientcredentials.Config{
+			ClientID:     opts.serviceAccountID,
+			ClientSecret: opts.serviceAccountSecret,
+			TokenURL:     serviceTokenURL(baseURL),
+		}
+		clientConfig.HTTPClient = oauth2.NewClient(ctx, config.TokenSource(ctx))
 	} else {
 		ctx = context.WithValue(ctx, oauth2.HTTPClient, clientConfig.HTTPClient)
 		apiClient := public.NewAPIClient(clientConfig)
@@ -68,9 +112,28 @@ func NewAPIClient(ctx context.Context, addr string, authcb func(string), options
 	return public.NewAPIClient(clientConfig), nil
 }
 
+// ProxyFunc returns the proxy of the requests to the service and to its identity provider, the proxy of the
+// environment when proxyURL is nil. The hosts of NO_PROXY are reached directly either way.
+func ProxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxyURL == nil {
+		return http.ProxyFromEnvironment
+	}
+	config := httpproxy.FromEnvironment()
+	config.HTTPProxy = proxyURL.String()
+	config.HTTPSProxy = proxyURL.String()
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
 func createOAuthHttpClient(ctx context.Context, apiClient *public.APIClient, opts *options, authcb func(string)) (*http.Client, error) {
 	startTime := time.Now()
-	resp, _, err := apiClient.AuthApi.DeviceStart(ctx).Execute()
+	request := apiClient.AuthApi.DeviceStart(ctx)
+	if opts.oidcProvider != "" {
+		request = request.Provider(opts.oidcProvider)
+	}
+	resp, _, err := request.Execute()
 	if err != nil {
 		return nil, err
 	}
@@ -94,22 +157,32 @@ func createOAuthHttpClient(ctx context.Context, apiClient *public.APIClient, opt
 		}
 	}
 
-	provider, err := oidc.NewProvider(ctx, resp.Issuer)
-	if err != nil {
-		return nil, err
-	}
+	var verifier *oidc.IDTokenVerifier
+	endpoint, deviceAuthURL := oauth2.Endpoint{}, resp.DeviceAuthorizationEndpoint
+	if opts.servicePolling {
+		// the service requests the device login, polls and refreshes its tokens and verifies their id tokens
+		// on behalf of the clients that do not reach the identity provider
+		endpoint = oauth2.Endpoint{TokenURL: deviceLoginURL(apiClient, opts, "poll"), AuthStyle: oauth2.AuthStyleInParams}
+		deviceAuthURL = deviceLoginURL(apiClient, opts, "authorize")
+	} else {
+		provider, err := oidc.NewProvider(ctx, resp.Issuer)
+		if err != nil {
+			return nil, err
+		}
 
-	oidcConfig := &oidc.Config{
-		ClientID: resp.ClientId,
-		Now:      serverTimeFn,
-	}
+		oidcConfig := &oidc.Config{
+			ClientID: resp.ClientId,
+			Now:      serverTimeFn,
+		}
 
-	verifier := provider.Verifier(oidcConfig)
+		verifier = provider.Verifier(oidcConfig)
+		endpoint = provider.Endpoint()
+	}
 
 	config := &oauth2.Config{
 		ClientID:     resp.ClientId,
 		ClientSecret: opts.clientSecret,
-		Endpoint:     provider.Endpoint(),
+		Endpoint:     endpoint,
 		Scopes:       []string{"openid", "profile", "email", "offline_access", "read:organizations", "write:organizations", "read:users", "write:users", "read:devices", "write:devices"},
 	}
 
@@ -121,7 +194,8 @@ func createOAuthHttpClient(ctx context.Context, apiClient *public.APIClient, opt
 	}
 	if token == nil {
 		if opts.deviceFlow {
-			token, rawIdToken, err = newDeviceFlowToken(ctx, resp.DeviceAuthorizationEndpoint, provider.Endpoint().TokenURL, resp.ClientId, authcb)
+			httpClient := apiClient.GetConfig().HTTPClient
+			token, rawIdToken, err = newDeviceFlowToken(ctx, httpClient, deviceAuthURL, endpoint.TokenURL, resp.ClientId, authcb)
 			if err != nil {
 				return nil, err
 			}
@@ -137,8 +211,10 @@ func createOAuthHttpClient(ctx context.Context, apiClient *public.APIClient, opt
 		if rawIdToken == nil {
 			return nil, fmt.Errorf("no id_token in response")
 		}
-		if _, err = verifier.Verify(ctx, rawIdToken.(string)); err != nil {
-			return nil, err
+		if verifier != nil {
+			if _, err = verifier.Verify(ctx, rawIdToken.(string)); err != nil {
+				return nil, err
+			}
 		}
 
 		if opts.tokenStore != nil {
@@ -160,6 +236,21 @@ func createOAuthHttpClient(ctx context.Context, apiClient *public.APIClient, opt
 	return oauth2.NewClient(ctx, source), nil
 }
 
+// deviceLoginURL returns the URL of an endpoint of the device login of the service, authorize or poll
+func deviceLoginURL(apiClient *public.APIClient, opts *options, endpoint string) string {
+	u := url.URL{Scheme: apiClient.GetConfig().Scheme, Host: apiClient.GetConfig().Host, Path: "/device/login/" + endpoint}
+	if opts.oidcProvider != "" {
+		u.RawQuery = url.Values{"provider": []string{opts.oidcProvider}}.Encode()
+	}
+	return u.String()
+}
+
+// serviceTokenURL returns the URL of the token endpoint of the service accounts
+func serviceTokenURL(baseURL *url.URL) string {
+	u := url.URL{Scheme: baseURL.Scheme, Host: baseURL.Host, Path: "/device/token"}
+	return u.String()
+}
+
 type storeOnChangeSource struct {
 	tokenStore TokenStore
 	source     oauth2.TokenSource
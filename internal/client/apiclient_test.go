@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/golang-jwt/jwt/v4"
@@ -15,6 +16,7 @@ import (
 	"golang.org/x/oauth2"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -74,7 +76,7 @@ func TestWithTokenFileOption(t *testing.T) {
 	assert.Equal(*originalToken, *nextToken)
 
 	// wait for the token to expire...
-	mockRouter.HandleFunc("/api/users/me", func(resp http.ResponseWriter, request *http.Request) {
+	mockRouter.HandleFunc("/api/v1/users/me", func(resp http.ResponseWriter, request *http.Request) {
 		sendJson(resp, 200, "{}")
 	})
 	time.Sleep(time.Second * 3)
@@ -86,6 +88,196 @@ func TestWithTokenFileOption(t *testing.T) {
 	assert.NotEqual(*originalToken, *nextToken)
 }
 
+func TestServiceDevicePolling(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	// the identity provider is not reached by the client, only by the service
+	idpRequests := int64(0)
+	idpRouter := http.NewServeMux()
+	idpServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&idpRequests, 1)
+		idpRouter.ServeHTTP(resp, req)
+	}))
+	defer idpServer.Close()
+	accessTokensCreated := int64(0)
+	err := addMockOIDCRoutes(idpServer, idpRouter, func() string {
+		return fmt.Sprintf("%d", atomic.AddInt64(&accessTokensCreated, 1))
+	})
+	require.NoError(err)
+	idpRouter.HandleFunc("/realms/nexodus/protocol/openid-connect/auth/device", func(resp http.ResponseWriter, req *http.Request) {
+		sendJson(resp, http.StatusOK, map[string]interface{}{
+			"device_code":               "device",
+			"user_code":                 "ABCD-EFGH",
+			"verification_uri_complete": idpServer.URL + "/realms/nexodus/device?user_code=ABCD-EFGH",
+			"expires_in":                30,
+			"interval":                  1,
+		})
+	})
+
+	mockRouter := http.NewServeMux()
+	mockServer := httptest.NewServer(mockRouter)
+	defer mockServer.Close()
+	mockRouter.HandleFunc("/device/login/start", func(resp http.ResponseWriter, req *http.Request) {
+		sendJson(resp, http.StatusOK, models.DeviceStartResponse{
+			ClientID:      "nexodus-cli",
+			DeviceAuthURL: idpServer.URL + "/realms/nexodus/protocol/openid-connect/auth/device",
+			Issuer:        idpServer.URL + "/realms/nexodus",
+		})
+	})
+	// the service requests the device login and polls and refreshes its tokens on behalf of the client
+	mockRouter.HandleFunc("/device/login/authorize", func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal("corp", req.URL.Query().Get("provider"))
+		req.URL.Path = "/realms/nexodus/protocol/openid-connect/auth/device"
+		idpRouter.ServeHTTP(resp, req)
+	})
+	grants := make(chan string, 10)
+	mockRouter.HandleFunc("/device/login/poll", func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal("corp", req.URL.Query().Get("provider"))
+		require.NoError(req.ParseForm())
+		grants <- req.PostForm.Get("grant_type")
+		switch req.PostForm.Get("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			assert.Equal("device", req.PostForm.Get("device_code"))
+		case "refresh_token":
+			assert.NotEmpty(req.PostForm.Get("refresh_token"))
+		}
+		req.URL.Path = "/realms/nexodus/protocol/openid-connect/token"
+		idpRouter.ServeHTTP(resp, req)
+	})
+	mockRouter.HandleFunc("/api/v1/users/me", func(resp http.ResponseWriter, request *http.Request) {
+		sendJson(resp, 200, "{}")
+	})
+
+	store := &testTokenStore{}
+	c, err := client.NewAPIClient(context.Background(), mockServer.URL, nil,
+		client.WithDeviceFlow(),
+		client.WithOidcProvider("corp"),
+		client.WithServiceDevicePolling(),
+		client.WithTokenStore(store),
+	)
+	require.NoError(err)
+	assert.Equal("urn:ietf:params:oauth:grant-type:device_code", <-grants)
+	require.NotNil(store.token)
+	assert.Equal("1", store.token.AccessToken)
+
+	// wait for the token to expire, it is refreshed through the service
+	time.Sleep(time.Second * 3)
+	_, _, err = c.UsersApi.GetUser(context.Background(), "me").Execute()
+	require.NoError(err)
+	assert.Equal("refresh_token", <-grants)
+	assert.Equal("2", store.token.AccessToken)
+	assert.Equal(int64(0), atomic.LoadInt64(&idpRequests))
+}
+
+func TestServiceAccount(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	mockRouter := http.NewServeMux()
+	mockServer := httptest.NewServer(mockRouter)
+	defer mockServer.Close()
+
+	mockRouter.HandleFunc("/device/token", func(resp http.ResponseWriter, req *http.Request) {
+		require.NoError(req.ParseForm())
+		assert.Equal("client_credentials", req.PostForm.Get("grant_type"))
+		id, secret, _ := req.BasicAuth()
+		if id != "ci" || secret != "secret" {
+			sendJson(resp, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+			return
+		}
+		sendJson(resp, http.StatusOK, map[string]interface{}{
+			"access_token": "service-account-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	mockRouter.HandleFunc("/api/v1/users/me", func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal("Bearer service-account-token", req.Header.Get("Authorization"))
+		sendJson(resp, http.StatusOK, map[string]interface{}{"id": "aa22666c-0f57-45cb-a449-16efecc04f2e", "username": "ci"})
+	})
+
+	c, err := client.NewAPIClient(context.Background(), mockServer.URL, nil,
+		client.WithServiceAccount("ci", "secret"),
+	)
+	require.NoError(err)
+	user, _, err := c.UsersApi.GetUser(context.Background(), "me").Execute()
+	require.NoError(err)
+	assert.Equal("ci", user.Username)
+
+	c, err = client.NewAPIClient(context.Background(), mockServer.URL, nil,
+		client.WithServiceAccount("ci", "not the secret"),
+	)
+	require.NoError(err)
+	_, _, err = c.UsersApi.GetUser(context.Background(), "me").Execute()
+	require.Error(err)
+}
+
+func TestUpgradeRequired(t *testing.T) {
+
+	require := require.New(t)
+	assert := assert.New(t)
+
+	mockRouter := http.NewServeMux()
+	mockServer := httptest.NewServer(mockRouter)
+	defer mockServer.Close()
+
+	mockRouter.HandleFunc("/api/v1/users/me", func(resp http.ResponseWriter, request *http.Request) {
+		sendJson(resp, http.StatusUpgradeRequired, `{"error":"agent upgrade required","current_version":"2024.01.31","minimum_version":"2024.03.05","instructions":"install the latest release"}`)
+	})
+
+	c, err := client.NewAPIClient(context.Background(), mockServer.URL, nil,
+		client.WithBearerToken("token"),
+	)
+	require.NoError(err)
+
+	_, _, err = c.UsersApi.GetUser(context.Background(), "me").Execute()
+	var upgradeErr *client.UpgradeRequiredError
+	require.True(errors.As(err, &upgradeErr))
+	assert.Equal("2024.01.31", upgradeErr.CurrentVersion)
+	assert.Equal("2024.03.05", upgradeErr.MinimumVersion)
+	assert.Equal("install the latest release", upgradeErr.Instructions)
+}
+
+func TestProxy(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	// the proxy answers the requests itself, a plain http proxy gets the absolute url of the request
+	var proxied atomic.Value
+	mockProxy := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, request *http.Request) {
+		proxied.Store(request.URL.String() + " " + request.Header.Get("Proxy-Authorization"))
+		sendJson(resp, http.StatusOK, `{"id":"me"}`)
+	}))
+	defer mockProxy.Close()
+	proxyURL, err := url.Parse(mockProxy.URL)
+	require.NoError(err)
+	proxyURL.User = url.UserPassword("user", "password")
+
+	c, err := client.NewAPIClient(context.Background(), "http://api.nexodus.invalid", nil,
+		client.WithBearerToken("token"),
+		client.WithProxy(proxyURL),
+	)
+	require.NoError(err)
+	_, _, err = c.UsersApi.GetUser(context.Background(), "me").Execute()
+	require.NoError(err)
+	assert.Equal("http://api.nexodus.invalid/api/v1/users/me Basic dXNlcjpwYXNzd29yZA==", proxied.Load())
+
+	// the hosts of NO_PROXY are reached directly
+	t.Setenv("NO_PROXY", "direct.nexodus.invalid")
+	proxy := client.ProxyFunc(proxyURL)
+	request, err := http.NewRequest(http.MethodGet, "https://direct.nexodus.invalid/api/v1/users/me", nil)
+	require.NoError(err)
+	u, err := proxy(request)
+	require.NoError(err)
+	assert.Nil(u)
+	request, err = http.NewRequest(http.MethodGet, "https://api.nexodus.invalid/api/v1/users/me", nil)
+	require.NoError(err)
+	u, err = proxy(request)
+	require.NoError(err)
+	assert.Equal(proxyURL.String(), u.String())
+}
+
 func sendJson(resp http.ResponseWriter, status int, body interface{}) {
 	resp.Header().Add("Content-Type", "application/json")
 	resp.WriteHeader(status)
@@ -21,9 +21,10 @@ type deviceFlowResponse struct {
 	Interval                int    `json:"interval"`
 }
 
-func newDeviceFlowToken(ctx context.Context, deviceEndpoint, tokenEndpoint, clientID string, authcb func(string)) (*oauth2.Token, interface{}, error) {
+// newDeviceFlowToken runs the device flow with httpClient, polling tokenEndpoint for the tokens
+func newDeviceFlowToken(ctx context.Context, httpClient *http.Client, deviceEndpoint, tokenEndpoint string, clientID string, authcb func(string)) (*oauth2.Token, interface{}, error) {
 	requestTime := time.Now()
-	d, err := startDeviceFlow(deviceEndpoint, clientID)
+	d, err := startDeviceFlow(httpClient, deviceEndpoint, clientID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -43,7 +44,7 @@ func newDeviceFlowToken(ctx context.Context, deviceEndpoint, tokenEndpoint, clie
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(d.ExpiresIn)*time.Second)
 	defer cancel()
 	go func() {
-		token, idToken, err = pollForResponse(ctx, clientID, tokenEndpoint, d, requestTime)
+		token, idToken, err = pollForResponse(ctx, httpClient, clientID, tokenEndpoint, d, requestTime)
 		c <- err
 	}()
 
@@ -57,12 +58,12 @@ func newDeviceFlowToken(ctx context.Context, deviceEndpoint, tokenEndpoint, clie
 	return token, idToken, nil
 }
 
-func startDeviceFlow(deviceEndpoint string, clientID string) (*deviceFlowResponse, error) {
+func startDeviceFlow(httpClient *http.Client, deviceEndpoint string, clientID string) (*deviceFlowResponse, error) {
 	v := url.Values{}
 	v.Set("client_id", clientID)
 	v.Set("scope", "openid profile email offline_access read:organizations write:organizations read:users write:users read:devices write:devices")
 	// #nosec -- G107: Potential HTTP request made with variable url (gosec)
-	res, err := http.PostForm(deviceEndpoint, v)
+	res, err := httpClient.PostForm(deviceEndpoint, v)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +93,7 @@ const (
 	errExpiredToken         = "expired_token"
 )
 
-func pollForResponse(ctx context.Context, clientID string, tokenURL string, t *deviceFlowResponse, requestTime time.Time) (*oauth2.Token, interface{}, error) {
+func pollForResponse(ctx context.Context, client *http.Client, clientID string, tokenURL string, t *deviceFlowResponse, requestTime time.Time) (*oauth2.Token, interface{}, error) {
 	v := url.Values{}
 	v.Set("device_code", t.DeviceCode)
 	v.Set("client_id", clientID)
@@ -111,8 +112,7 @@ func pollForResponse(ctx context.Context, clientID string, tokenURL string, t *d
 			return nil, nil, ctx.Err()
 		case <-ticker.C:
 			requestTime := time.Now()
-			// #nosec -- G107: Potential HTTP request made with variable url (gosec)
-			res, err := http.PostForm(tokenURL, v)
+			res, err := client.PostForm(tokenURL, v)
 			if err != nil {
 				// possible transient connection error, continue retrying
 				continue
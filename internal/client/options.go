@@ -2,6 +2,8 @@ package client
 
 import (
 	"crypto/tls"
+	"net/url"
+
 	"golang.org/x/oauth2"
 )
 
@@ -14,6 +16,14 @@ type options struct {
 	tlsConfig    *tls.Config
 	bearerToken  string
 	userAgent    string
+	oidcProvider string
+	// poll the tokens of the device flow through the service
+	servicePolling bool
+	// the client credentials of a service account
+	serviceAccountID     string
+	serviceAccountSecret string
+	// the proxy of the requests, the proxy of the environment when nil
+	proxyURL *url.URL
 }
 
 type TokenStore interface {
@@ -78,6 +88,35 @@ func WithDeviceFlow() Option {
 	}
 }
 
+// WithOidcProvider selects the identity provider the device flow logs in with, the default provider of
+// the service when empty
+func WithOidcProvider(name string) Option {
+	return func(o *options) error {
+		o.oidcProvider = name
+		return nil
+	}
+}
+
+// WithServiceDevicePolling logs in with the device flow through the Nexodus service rather than the identity
+// provider: the service requests the device login, polls and refreshes its tokens and verifies its id tokens.
+// Only the browser the user authorizes the device with reaches the identity provider.
+func WithServiceDevicePolling() Option {
+	return func(o *options) error {
+		o.servicePolling = true
+		return nil
+	}
+}
+
+// WithServiceAccount authenticates as a service account, with the access tokens the service issues to the
+// client credentials of the account
+func WithServiceAccount(id string, secret string) Option {
+	return func(o *options) error {
+		o.serviceAccountID = id
+		o.serviceAccountSecret = secret
+		return nil
+	}
+}
+
 func WithTokenStore(
 	tokenStore TokenStore,
 ) Option {
@@ -86,3 +125,13 @@ func WithTokenStore(
 		return nil
 	}
 }
+
+// WithProxy sends the requests through the proxy rather than the proxy of the HTTPS_PROXY and HTTP_PROXY
+// environment variables, the hosts of NO_PROXY are still reached directly. The scheme of the proxy is http,
+// https or socks5, the credentials of the url are sent with basic authentication.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *options) error {
+		o.proxyURL = proxyURL
+		return nil
+	}
+}
@@ -15,6 +15,32 @@ import (
 	_ "github.com/nexodus-io/nexodus/internal/database/migration_20231206_0000"
 	_ "github.com/nexodus-io/nexodus/internal/database/migration_20231211_0000"
 	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240221_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240304_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240305_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240306_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240307_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240308_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240308_0001"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240309_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240310_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240311_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240312_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240313_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240314_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240315_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240316_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240317_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240318_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240319_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240320_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240321_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240322_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240323_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240324_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240325_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240326_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240327_0000"
+	_ "github.com/nexodus-io/nexodus/internal/database/migration_20240328_0000"
 	"sort"
 
 	"github.com/cenkalti/backoff/v4"
@@ -27,6 +53,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 var tracer trace.Tracer
@@ -71,6 +98,40 @@ func NewDatabase(
 	return db, dsn, nil
 }
 
+// NewSqliteDatabase opens the sqlite database of the file, for the dev mode of the apiserver
+func NewSqliteDatabase(parent context.Context, logger *zap.SugaredLogger, file string) (*gorm.DB, error) {
+	_, span := tracer.Start(parent, "NewSqliteDatabase")
+	defer span.End()
+	// the requests wait on the writes of each other rather than failing with database is locked
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", file)
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: NewLogger(logger),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(otelgorm.NewPlugin()); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// ReadReplica names the resolver of the read replica, the queries of the statements using it are sent to the
+// read replica when one is configured, and to the primary database otherwise.
+const ReadReplica = "read-replica"
+
+// UseReadReplica sends the queries of the statements using the ReadReplica resolver to the database of the dsn,
+// the writes and all other statements keep using the primary database.
+func UseReadReplica(db *gorm.DB, dsn string) error {
+	return useReadReplica(db, postgres.Open(dsn))
+}
+
+func useReadReplica(db *gorm.DB, replica gorm.Dialector) error {
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{replica},
+	}, ReadReplica))
+}
+
 func NewTestDatabase() (*gorm.DB, error) {
 	logger, err := zap.NewDevelopment()
 	if err != nil {
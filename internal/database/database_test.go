@@ -0,0 +1,44 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+func TestUseReadReplica(t *testing.T) {
+	require := require.New(t)
+	type item struct {
+		ID   uint
+		Name string
+	}
+
+	primary, err := gorm.Open(sqlite.Open("file:primary?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(err)
+	replica, err := gorm.Open(sqlite.Open("file:replica?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(err)
+	require.NoError(primary.AutoMigrate(&item{}))
+	require.NoError(replica.AutoMigrate(&item{}))
+	require.NoError(replica.Create(&item{Name: "replicated"}).Error)
+
+	require.NoError(useReadReplica(primary, sqlite.Open("file:replica?mode=memory&cache=shared")))
+	require.NoError(primary.Create(&item{Name: "written"}).Error)
+
+	// only the statements using the resolver read from the replica
+	var items []item
+	require.NoError(primary.Find(&items).Error)
+	require.Len(items, 1)
+	require.Equal("written", items[0].Name)
+	require.NoError(primary.Clauses(dbresolver.Use(ReadReplica)).Find(&items).Error)
+	require.Len(items, 1)
+	require.Equal("replicated", items[0].Name)
+
+	// the writes of the statements using the resolver go to the primary
+	require.NoError(primary.Clauses(dbresolver.Use(ReadReplica)).Create(&item{Name: "written again"}).Error)
+	var count int64
+	require.NoError(primary.Model(&item{}).Count(&count).Error)
+	require.Equal(int64(2), count)
+}
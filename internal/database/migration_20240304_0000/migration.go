@@ -0,0 +1,22 @@
+package migration_20240304_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Organization struct {
+	ListenPortMin int
+	ListenPortMax int
+}
+
+type Device struct {
+	ListenPort int
+}
+
+func init() {
+	migrationId := "20240304-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Organization{}),
+		AddTableColumnsAction(&Device{}),
+	)
+}
@@ -0,0 +1,16 @@
+package migration_20240305_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Organization struct {
+	UpdateChannel string
+}
+
+func init() {
+	migrationId := "20240305-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Organization{}),
+	)
+}
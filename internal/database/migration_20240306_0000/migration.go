@@ -0,0 +1,16 @@
+package migration_20240306_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	DnsName string
+}
+
+func init() {
+	migrationId := "20240306-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
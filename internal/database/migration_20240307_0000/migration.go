@@ -0,0 +1,18 @@
+package migration_20240307_0000
+
+import (
+	"time"
+
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	LastSeen *time.Time
+}
+
+func init() {
+	migrationId := "20240307-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
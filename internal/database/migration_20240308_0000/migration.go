@@ -0,0 +1,41 @@
+package migration_20240308_0000
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type AuditEvent struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key"`
+	CreatedAt      time.Time `gorm:"index"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	ActorID        uuid.UUID `gorm:"type:uuid;index"`
+	SourceIP       string
+	Action         string
+	ResourceType   string
+	ResourceID     uuid.UUID              `gorm:"type:uuid;index"`
+	Changes        map[string]interface{} `gorm:"type:JSONB; serializer:json"`
+}
+
+func init() {
+	migrationId := "20240308-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&AuditEvent{}),
+		ExecActionIf(`
+			CREATE OR REPLACE FUNCTION audit_events_append_only_trigger() RETURNS TRIGGER LANGUAGE plpgsql AS '
+			BEGIN
+			RAISE EXCEPTION ''audit events cannot be modified'';
+			END;'
+		`, `
+			DROP FUNCTION IF EXISTS audit_events_append_only_trigger
+		`, NotOnSqlLite),
+		ExecActionIf(`
+			CREATE OR REPLACE TRIGGER audit_events_append_only_trigger BEFORE UPDATE OR DELETE ON audit_events
+			FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only_trigger();
+		`, `
+			DROP TRIGGER IF EXISTS audit_events_append_only_trigger ON audit_events
+		`, NotOnSqlLite),
+	)
+}
@@ -0,0 +1,39 @@
+package migration_20240308_0001
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database/datatype"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Webhook struct {
+	migration_20231031_0000.Base
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	URL            string
+	Secret         string
+	EventTypes     datatype.StringArray
+	Description    string
+}
+
+type WebhookDelivery struct {
+	migration_20231031_0000.Base
+	WebhookID     uuid.UUID `gorm:"type:uuid;index"`
+	EventID       uuid.UUID `gorm:"type:uuid"`
+	EventType     string
+	Payload       string
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	DeliveredAt   *time.Time
+	LastError     string
+}
+
+func init() {
+	migrationId := "20240308-0001"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&Webhook{}),
+		CreateTableAction(&WebhookDelivery{}),
+	)
+}
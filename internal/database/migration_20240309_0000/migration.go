@@ -0,0 +1,18 @@
+package migration_20240309_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Organization struct {
+	QuotaMaxDevices        int
+	QuotaMaxSecurityGroups int
+	QuotaMaxChildPrefixes  int
+}
+
+func init() {
+	migrationId := "20240309-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Organization{}),
+	)
+}
@@ -0,0 +1,27 @@
+package migration_20240310_0000
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type DeviceSecurityGroup struct {
+	DeviceID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	SecurityGroupID uuid.UUID `gorm:"type:uuid;primary_key;index"`
+	CreatedAt       time.Time
+}
+
+type Device struct {
+	SecurityGroupIds pq.StringArray `gorm:"type:text[]"`
+}
+
+func init() {
+	migrationId := "20240310-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&DeviceSecurityGroup{}),
+		AddTableColumnsAction(&Device{}),
+	)
+}
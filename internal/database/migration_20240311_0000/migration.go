@@ -0,0 +1,17 @@
+package migration_20240311_0000
+
+import (
+	"github.com/google/uuid"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	RelayID uuid.UUID `gorm:"type:uuid"`
+}
+
+func init() {
+	migrationId := "20240311-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
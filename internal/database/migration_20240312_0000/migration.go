@@ -0,0 +1,17 @@
+package migration_20240312_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	NatType    string
+	NatHairpin bool
+}
+
+func init() {
+	migrationId := "20240312-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
@@ -0,0 +1,16 @@
+package migration_20240313_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	EndpointIPv6 string
+}
+
+func init() {
+	migrationId := "20240313-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
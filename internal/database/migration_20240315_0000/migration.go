@@ -0,0 +1,22 @@
+package migration_20240315_0000
+
+import (
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type IPExclusionRange struct {
+	migration_20231031_0000.Base
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	StartAddress   string
+	EndAddress     string
+	Description    string
+}
+
+func init() {
+	migrationId := "20240315-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&IPExclusionRange{}),
+	)
+}
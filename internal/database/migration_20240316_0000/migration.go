@@ -0,0 +1,20 @@
+package migration_20240316_0000
+
+import (
+	"time"
+
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	IpamReleasedAt *time.Time
+}
+
+func init() {
+	migrationId := "20240316-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+		// the devices deleted before the reclaim job existed were released by the delete path
+		ExecAction(`UPDATE devices SET ipam_released_at = deleted_at WHERE deleted_at IS NOT NULL`, ""),
+	)
+}
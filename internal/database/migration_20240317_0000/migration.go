@@ -0,0 +1,21 @@
+package migration_20240317_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Organization struct {
+	RequireDeviceApproval bool
+}
+
+type Device struct {
+	Pending bool
+}
+
+func init() {
+	migrationId := "20240317-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Organization{}),
+		AddTableColumnsAction(&Device{}),
+	)
+}
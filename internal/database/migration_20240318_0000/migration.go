@@ -0,0 +1,24 @@
+package migration_20240318_0000
+
+import (
+	"time"
+
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Organization struct {
+	DeviceTTLDays        int
+	DeleteExpiredDevices bool
+}
+
+type Device struct {
+	ExpiredAt *time.Time
+}
+
+func init() {
+	migrationId := "20240318-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Organization{}),
+		AddTableColumnsAction(&Device{}),
+	)
+}
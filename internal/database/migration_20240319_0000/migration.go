@@ -0,0 +1,16 @@
+package migration_20240319_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	DisplayName string
+}
+
+func init() {
+	migrationId := "20240319-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
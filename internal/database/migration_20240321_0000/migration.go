@@ -0,0 +1,16 @@
+package migration_20240321_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Device struct {
+	Unmanaged bool
+}
+
+func init() {
+	migrationId := "20240321-0000"
+	CreateMigrationFromActions(migrationId,
+		AddTableColumnsAction(&Device{}),
+	)
+}
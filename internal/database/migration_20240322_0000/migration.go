@@ -0,0 +1,40 @@
+package migration_20240322_0000
+
+import (
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type Service struct {
+	migration_20231031_0000.Base
+	Revision       uint64    `gorm:"type:bigserial;index"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	DeviceID       uuid.UUID `gorm:"type:uuid;index"`
+	Name           string    `gorm:"index"`
+	Port           int
+	Protocol       string
+	Description    string
+}
+
+func init() {
+	migrationId := "20240322-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&Service{}),
+		ExecActionIf(`
+			CREATE OR REPLACE FUNCTION services_revision_trigger() RETURNS TRIGGER LANGUAGE plpgsql AS '
+			BEGIN
+			NEW.revision := nextval(''services_revision_seq'');
+			RETURN NEW;
+			END;'
+		`, `
+			DROP FUNCTION IF EXISTS services_revision_trigger
+		`, NotOnSqlLite),
+		ExecActionIf(`
+			CREATE OR REPLACE TRIGGER services_revision_trigger BEFORE INSERT OR UPDATE ON services
+			FOR EACH ROW EXECUTE PROCEDURE services_revision_trigger();
+		`, `
+			DROP TRIGGER IF EXISTS services_revision_trigger ON services
+		`, NotOnSqlLite),
+	)
+}
@@ -0,0 +1,28 @@
+package migration_20240324_0000
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type PeerConnectivity struct {
+	DeviceID       uuid.UUID `gorm:"type:uuid;primary_key"`
+	PeerID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	VpcID          uuid.UUID `gorm:"type:uuid"`
+	Reachability   string
+	Method         string
+	LatencyAvgMs   float64
+	LatencyMaxMs   float64
+	Loss           float64
+	UpdatedAt      time.Time
+}
+
+func init() {
+	migrationId := "20240324-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&PeerConnectivity{}),
+	)
+}
@@ -0,0 +1,26 @@
+package migration_20240325_0000
+
+import (
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database/datatype"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type ServiceAccount struct {
+	migration_20231031_0000.Base
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	OwnerID        uuid.UUID `gorm:"type:uuid"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	Name           string
+	Description    string
+	Roles          datatype.StringArray
+	SecretHash     string
+}
+
+func init() {
+	migrationId := "20240325-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&ServiceAccount{}),
+	)
+}
@@ -0,0 +1,26 @@
+package migration_20240326_0000
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database/datatype"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type APIKey struct {
+	migration_20231031_0000.Base
+	UserID      uuid.UUID `gorm:"type:uuid;index"`
+	Description string
+	Scopes      datatype.StringArray
+	ExpiresAt   *time.Time
+	TokenHash   string `gorm:"index"`
+}
+
+func init() {
+	migrationId := "20240326-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&APIKey{}),
+	)
+}
@@ -0,0 +1,23 @@
+package migration_20240327_0000
+
+import (
+	"github.com/nexodus-io/nexodus/internal/database/datatype"
+	"github.com/nexodus-io/nexodus/internal/database/migration_20231031_0000"
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+type FeatureFlag struct {
+	migration_20231031_0000.Base
+	Name          string `gorm:"uniqueIndex"`
+	Description   string
+	Enabled       bool
+	Organizations datatype.StringArray
+	Percentage    int
+}
+
+func init() {
+	migrationId := "20240327-0000"
+	CreateMigrationFromActions(migrationId,
+		CreateTableAction(&FeatureFlag{}),
+	)
+}
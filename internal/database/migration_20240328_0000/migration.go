@@ -0,0 +1,17 @@
+package migration_20240328_0000
+
+import (
+	. "github.com/nexodus-io/nexodus/internal/database/migrations"
+)
+
+func init() {
+	migrationId := "20240328-0000"
+	CreateMigrationFromActions(migrationId,
+		// the dns names of the devices are unique in their organization, the devices registered before they
+		// had a dns name are excluded
+		ExecAction(
+			`CREATE UNIQUE INDEX IF NOT EXISTS "idx_devices_organization_dns_name" ON "devices" ("organization_id", "dns_name") WHERE deleted_at IS NULL AND dns_name <> ''`,
+			`DROP INDEX IF EXISTS idx_devices_organization_dns_name`,
+		),
+	)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"sort"
 
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -111,6 +113,70 @@ func (m *Migrations) CountMigrationsApplied(db *gorm.DB) (int, error) {
 	return count, nil
 }
 
+// MigrationStatus is whether a migration is applied to the database
+type MigrationStatus struct {
+	ID      string
+	Applied bool
+	// Unknown is set for the migrations applied by a newer version, the schema is newer than this version expects
+	Unknown bool
+}
+
+// Status returns the status of the migrations in the order they are applied, followed by the applied migrations
+// this version does not know.
+func (m *Migrations) Status(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	_, span := tracer.Start(ctx, "Status")
+	defer span.End()
+
+	applied := map[string]bool{}
+	if db.Migrator().HasTable(m.GormOptions.TableName) {
+		var ids []string
+		sql := fmt.Sprintf("SELECT %s AS id FROM %s ORDER BY %s", m.GormOptions.IDColumnName, m.GormOptions.TableName, m.GormOptions.IDColumnName)
+		if err := db.Raw(sql).Scan(&ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			applied[id] = true
+		}
+	}
+
+	var status []MigrationStatus
+	for _, migration := range m.Migrations {
+		status = append(status, MigrationStatus{ID: migration.ID, Applied: applied[migration.ID]})
+		delete(applied, migration.ID)
+	}
+	unknown := make([]string, 0, len(applied))
+	for id := range applied {
+		unknown = append(unknown, id)
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		status = append(status, MigrationStatus{ID: id, Applied: true, Unknown: true})
+	}
+	return status, nil
+}
+
+// CheckSchema returns an error when pending is false and migrations of this version are not applied yet. The
+// migrations applied by a newer version are logged, the newer schema is expected to stay compatible with this
+// version during a rolling upgrade.
+func (m *Migrations) CheckSchema(ctx context.Context, db *gorm.DB, logger *zap.SugaredLogger, pending bool) error {
+	status, err := m.Status(ctx, db)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, s := range status {
+		if s.Unknown {
+			logger.Warnf("the database has the migration %s applied that this version does not know", s.ID)
+		} else if !s.Applied {
+			missing = append(missing, s.ID)
+		}
+	}
+	if !pending && len(missing) > 0 {
+		return fmt.Errorf("the database is missing the migrations %v, apply them with the migrate up command", missing)
+	}
+	return nil
+}
+
 type MigrationAction func(tx *gorm.DB, apply bool) error
 
 func CreateTableAction(table interface{}) MigrationAction {
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStatusAndCheckSchema(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open("file:migrationstatus?mode=memory"), &gorm.Config{})
+	require.NoError(err)
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core).Sugar()
+
+	migration := func(id string) *gormigrate.Migration {
+		return &gormigrate.Migration{
+			ID:       id,
+			Migrate:  func(tx *gorm.DB) error { return nil },
+			Rollback: func(tx *gorm.DB) error { return nil },
+		}
+	}
+	options := &gormigrate.Options{
+		TableName:    "test_migrations",
+		IDColumnName: "id",
+		IDColumnSize: 40,
+	}
+	newer := &Migrations{
+		GormOptions: options,
+		Migrations:  []*gormigrate.Migration{migration("1"), migration("2"), migration("3")},
+	}
+	older := &Migrations{
+		GormOptions: options,
+		Migrations:  []*gormigrate.Migration{migration("1"), migration("2")},
+	}
+
+	// nothing is applied before the first migration
+	status, err := older.Status(ctx, db)
+	require.NoError(err)
+	require.Equal([]MigrationStatus{{ID: "1"}, {ID: "2"}}, status)
+	require.NoError(older.CheckSchema(ctx, db, logger, true))
+	require.Error(older.CheckSchema(ctx, db, logger, false))
+
+	require.NoError(older.MigrateTo(ctx, db, "1"))
+	status, err = older.Status(ctx, db)
+	require.NoError(err)
+	require.Equal([]MigrationStatus{{ID: "1", Applied: true}, {ID: "2"}}, status)
+	require.Error(older.CheckSchema(ctx, db, logger, false))
+
+	require.NoError(newer.Migrate(ctx, db))
+	require.NoError(newer.CheckSchema(ctx, db, logger, false))
+
+	// the older version does not know the schema of the newer version
+	status, err = older.Status(ctx, db)
+	require.NoError(err)
+	require.Equal([]MigrationStatus{{ID: "1", Applied: true}, {ID: "2", Applied: true}, {ID: "3", Applied: true, Unknown: true}}, status)
+	require.NoError(older.CheckSchema(ctx, db, logger, false))
+	require.Equal(1, logs.FilterMessage("the database has the migration 3 applied that this version does not know").Len())
+
+	require.NoError(newer.RollbackLast(ctx, db))
+	require.NoError(older.CheckSchema(ctx, db, logger, false))
+}
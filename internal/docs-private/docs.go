@@ -63,6 +63,36 @@ const docTemplate = `{
                 }
             }
         },
+        "/private/ipam/stale-leases": {
+            "get": {
+                "description": "Lists the IPAM allocations of the deleted devices that were not released, and of the devices not seen for the reclaim period, without releasing them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Private"
+                ],
+                "summary": "List Stale IPAM Leases",
+                "operationId": "ListStaleLeases",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaleLeaseReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
         "/private/live": {
             "post": {
                 "description": "Checks if the service is live",
@@ -90,6 +120,115 @@ const docTemplate = `{
                 }
             }
         },
+        "/private/organizations/{id}/quota": {
+            "get": {
+                "description": "Gets the limits of the resources of an organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Private"
+                ],
+                "summary": "Get Organization Quota",
+                "operationId": "GetOrganizationQuota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationQuota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Sets the limits of the resources of an organization, a limit of 0 means no limit. Existing resources are not removed when a limit is lowered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Private"
+                ],
+                "summary": "Update Organization Quota",
+                "operationId": "UpdateOrganizationQuota",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization Quota",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationQuota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationQuota"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
         "/private/ready": {
             "post": {
                 "description": "Checks if the service is ready to accept requests",
@@ -119,6 +258,15 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "models.BaseError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad"
+                }
+            }
+        },
         "models.InternalServerError": {
             "type": "object",
             "properties": {
@@ -131,6 +279,65 @@ const docTemplate = `{
                 }
             }
         },
+        "models.OrganizationQuota": {
+            "type": "object",
+            "properties": {
+                "max_child_prefixes": {
+                    "description": "the total number of cidrs advertised by the devices",
+                    "type": "integer",
+                    "example": 20
+                },
+                "max_devices": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "max_security_groups": {
+                    "description": "not counting the default security group of each vpc",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.StaleLease": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "the tunnel IP, empty for an advertised child prefix",
+                    "type": "string",
+                    "example": "100.64.0.10"
+                },
+                "cidr": {
+                    "description": "the prefix the address belongs to, or the advertised child prefix",
+                    "type": "string",
+                    "example": "100.64.0.0/10"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "deleted or not-seen",
+                    "type": "string",
+                    "example": "deleted"
+                },
+                "vpc_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StaleLeaseReport": {
+            "type": "object",
+            "properties": {
+                "leases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StaleLease"
+                    }
+                }
+            }
+        },
         "models.ValidationError": {
             "type": "object",
             "properties": {
@@ -22,9 +22,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/ca/sign": {
-            "post": {
-                "description": "Signs a certificate signing request",
+        "/api/v1/admin/audit": {
+            "get": {
+                "description": "Lists the audit events of all organizations along with the requests of the controller admins, newest first, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -32,32 +32,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "CA"
+                    "Admin"
                 ],
-                "summary": "Signs a certificate signing request",
-                "operationId": "SignCSR",
+                "summary": "List All Audit Events",
+                "operationId": "AdminListAuditEvents",
                 "parameters": [
                     {
-                        "description": "Certificate signing request",
-                        "name": "CertificateSigningRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CertificateSigningRequest"
-                        }
+                        "type": "string",
+                        "description": "only list events of this organization",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events made by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events of this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CertificateSigningResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEvent"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.ValidationError"
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "500": {
@@ -69,9 +111,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/devices": {
+        "/api/v1/admin/devices": {
             "get": {
-                "description": "Lists all devices",
+                "description": "Lists the devices of all users, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,10 +121,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
+                ],
+                "summary": "List All Devices",
+                "operationId": "AdminListDevices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only list the devices of this organization",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List Devices",
-                "operationId": "ListDevices",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -91,6 +159,18 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/models.Device"
                             }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "401": {
@@ -112,9 +192,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Adds a new device",
+            }
+        },
+        "/api/v1/admin/devices/{id}": {
+            "delete": {
+                "description": "Deletes a device of any user, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -122,24 +204,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Add Devices",
-                "operationId": "CreateDevice",
+                "summary": "Force Delete Device",
+                "operationId": "AdminDeleteDevice",
                 "parameters": [
                     {
-                        "description": "Add Device",
-                        "name": "Device",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AddDevice"
-                        }
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.Device"
                         }
@@ -156,10 +236,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.ConflictsError"
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "429": {
@@ -177,9 +257,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/devices/{id}": {
+        "/api/v1/admin/fflags": {
             "get": {
-                "description": "Gets a device by ID",
+                "description": "Lists the feature flags managed through the api along with their targeting, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -187,24 +267,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Get Devices",
-                "operationId": "GetDevice",
+                "summary": "List Stored Feature Flags",
+                "operationId": "AdminListFeatureFlags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FeatureFlag"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a feature flag, it overrides the flag of the same name configured on the apiserver. Reserved to the controller admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create Feature Flag",
+                "operationId": "AdminCreateFeatureFlag",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Add Feature Flag",
+                        "name": "FeatureFlag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddFeatureFlag"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Device"
+                            "$ref": "#/definitions/models.FeatureFlag"
                         }
                     },
                     "400": {
@@ -219,10 +344,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.ConflictsError"
                         }
                     },
                     "429": {
@@ -238,9 +363,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/admin/fflags/{name}": {
             "delete": {
-                "description": "Deletes an existing device and associated IPAM lease",
+                "description": "Deletes a feature flag, the flag of the same name configured on the apiserver applies again. Reserved to the controller admins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -248,28 +375,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Delete Device",
-                "operationId": "DeleteDevice",
+                "summary": "Delete Feature Flag",
+                "operationId": "AdminDeleteFeatureFlag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
+                        "description": "feature flag name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Device"
+                            "$ref": "#/definitions/models.FeatureFlag"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
@@ -289,7 +422,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Updates a device by ID",
+                "description": "Updates the value and targeting of a feature flag, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -297,25 +430,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Update Devices",
-                "operationId": "UpdateDevice",
+                "summary": "Update Feature Flag",
+                "operationId": "AdminUpdateFeatureFlag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
+                        "description": "feature flag name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Device Update",
+                        "description": "Feature Flag Update",
                         "name": "update",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateDevice"
+                            "$ref": "#/definitions/models.UpdateFeatureFlag"
                         }
                     }
                 ],
@@ -323,7 +456,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Device"
+                            "$ref": "#/definitions/models.FeatureFlag"
                         }
                     },
                     "400": {
@@ -359,9 +492,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/devices/{id}/metadata": {
+        "/api/v1/admin/organizations": {
             "get": {
-                "description": "Lists metadata for a device",
+                "description": "Lists the organizations of all users, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -369,22 +502,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "List Device Metadata",
-                "operationId": "ListDeviceMetadata",
+                "summary": "List All Organizations",
+                "operationId": "AdminListOrganizations",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "greater than revision",
-                        "name": "gt_revision",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
                         "in": "query"
                     }
                 ],
@@ -394,8 +532,26 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.DeviceMetadata"
+                                "$ref": "#/definitions/models.Organization"
                             }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "500": {
@@ -405,26 +561,61 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/admin/organizations/{id}": {
             "delete": {
-                "description": "Delete all metadata for a device",
-                "tags": [
-                    "Devices"
+                "description": "Deletes an organization of any user along with its devices, reserved to the controller admins. The default organization of a user is deleted with the user.",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "Delete all Device metadata",
-                "operationId": "DeleteDeviceMetadata",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Force Delete Organization",
+                "operationId": "AdminDeleteOrganization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -435,9 +626,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/devices/{id}/metadata/{key}": {
+        "/api/v1/admin/users": {
             "get": {
-                "description": "Get metadata for a device",
+                "description": "Lists all users, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -445,31 +636,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Get Device Metadata",
-                "operationId": "GetDeviceMetadataKey",
+                "summary": "List All Users",
+                "operationId": "AdminListUsers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Metadata Key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DeviceMetadata"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "500": {
@@ -479,9 +695,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Set metadata key for a device",
+            }
+        },
+        "/api/v1/admin/users/{id}": {
+            "delete": {
+                "description": "Deletes any user along with its devices and the organizations it is the only owner of, reserved to the controller admins",
                 "consumes": [
                     "application/json"
                 ],
@@ -489,106 +707,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Devices"
+                    "Admin"
                 ],
-                "summary": "Set Device Metadata by key",
-                "operationId": "UpdateDeviceMetadataKey",
+                "summary": "Force Delete User",
+                "operationId": "AdminDeleteUser",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Device ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Metadata Key",
-                        "name": "key",
-                        "in": "path"
-                    },
-                    {
-                        "description": "Metadata Value",
-                        "name": "value",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DeviceMetadata"
+                            "$ref": "#/definitions/models.User"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.InternalServerError"
+                            "$ref": "#/definitions/models.BaseError"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete a metadata key for a device",
-                "tags": [
-                    "Devices"
-                ],
-                "summary": "Delete a Device metadata key",
-                "operationId": "DeleteDeviceMetadataKey",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Device ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Metadata Key",
-                        "name": "key",
-                        "in": "path"
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/models.InternalServerError"
+                            "$ref": "#/definitions/models.BaseError"
                         }
-                    }
-                }
-            }
-        },
-        "/api/fflags": {
-            "get": {
-                "description": "Lists all feature flags",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "FFlag"
-                ],
-                "summary": "List Feature Flags",
-                "operationId": "ListFeatureFlags",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "boolean"
-                            }
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "429": {
@@ -606,9 +760,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/fflags/{name}": {
-            "get": {
-                "description": "Gets a Feature Flag by name",
+        "/api/v1/admin/users/{id}/impersonate": {
+            "post": {
+                "description": "Creates a short lived api key acting as a user with all the api key scopes, reserved to the controller admins. The impersonation is recorded in the audit log of the default organization of the user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -616,27 +770,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "FFlag"
+                    "Admin"
                 ],
-                "summary": "Get Feature Flag",
-                "operationId": "GetFeatureFlag",
+                "summary": "Impersonate User",
+                "operationId": "AdminImpersonateUser",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "feature flag name",
-                        "name": "name",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Impersonation",
+                        "name": "Reason",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminImpersonateUser"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "boolean"
-                            }
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
@@ -645,6 +805,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -666,9 +832,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/invitations": {
+        "/api/v1/api-keys": {
             "get": {
-                "description": "Lists all invitations",
+                "description": "Lists the api keys of the current user",
                 "consumes": [
                     "application/json"
                 ],
@@ -676,17 +842,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Invitation"
+                    "APIKey"
                 ],
-                "summary": "List Invitations",
-                "operationId": "ListInvitations",
+                "summary": "List API Keys",
+                "operationId": "ListAPIKeys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Invitation"
+                                "$ref": "#/definitions/models.APIKey"
                             }
                         }
                     },
@@ -711,7 +877,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create an invitation to an organization",
+                "description": "Creates an api key for the current user limited to the scopes of the request, the token of the key is only returned in the response",
                 "consumes": [
                     "application/json"
                 ],
@@ -719,18 +885,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Invitation"
+                    "APIKey"
                 ],
-                "summary": "Create an invitation",
-                "operationId": "CreateInvitation",
+                "summary": "Create an API Key",
+                "operationId": "CreateAPIKey",
                 "parameters": [
                     {
-                        "description": "Add Invitation",
-                        "name": "Invitation",
+                        "description": "Add API Key",
+                        "name": "APIKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AddInvitation"
+                            "$ref": "#/definitions/models.AddAPIKey"
                         }
                     }
                 ],
@@ -738,7 +904,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Invitation"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
@@ -747,8 +913,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
@@ -768,9 +940,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/invitations/{id}": {
+        "/api/v1/api-keys/{id}": {
             "get": {
-                "description": "Gets an Invitation by Invitation ID",
+                "description": "Gets an api key of the current user by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -778,14 +950,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Invitation"
+                    "APIKey"
                 ],
-                "summary": "Get Invitation",
-                "operationId": "GetInvitation",
+                "summary": "Get API Key",
+                "operationId": "GetAPIKey",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Invitation ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -795,7 +967,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Invitation"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
@@ -831,7 +1003,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes an existing invitation",
+                "description": "Deletes an api key of the current user, the key is not accepted anymore",
                 "consumes": [
                     "application/json"
                 ],
@@ -839,24 +1011,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Invitation"
+                    "APIKey"
                 ],
-                "summary": "Delete Invitation",
-                "operationId": "DeleteInvitation",
+                "summary": "Delete API Key",
+                "operationId": "DeleteAPIKey",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Invitation ID",
+                        "description": "API Key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Organization"
+                            "$ref": "#/definitions/models.APIKey"
                         }
                     },
                     "400": {
@@ -865,8 +1037,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
@@ -886,9 +1064,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/invitations/{id}/accept": {
+        "/api/v1/ca/sign": {
             "post": {
-                "description": "Accept an invitation to an organization",
+                "description": "Signs a certificate signing request",
                 "consumes": [
                     "application/json"
                 ],
@@ -896,39 +1074,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Invitation"
+                    "CA"
                 ],
-                "summary": "Accept an invitation",
-                "operationId": "AcceptInvitation",
+                "summary": "Signs a certificate signing request",
+                "operationId": "SignCSR",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Invitation ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Certificate signing request",
+                        "name": "CertificateSigningRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CertificateSigningRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.CertificateSigningResponse"
                         }
                     },
-                    "429": {
-                        "description": "Too Many Requests",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.ValidationError"
                         }
                     },
                     "500": {
@@ -940,9 +1111,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/organizations": {
+        "/api/v1/devices": {
             "get": {
-                "description": "Lists all Organizations",
+                "description": "Lists all devices",
                 "consumes": [
                     "application/json"
                 ],
@@ -950,20 +1121,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
+                ],
+                "summary": "List Devices",
+                "operationId": "ListDevices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list the devices with this label, formatted as key=value, the devices must have all the given labels",
+                        "name": "label",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List Organizations",
-                "operationId": "ListOrganizations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Organization"
+                                "$ref": "#/definitions/models.Device"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -985,7 +1198,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a named organization with the given CIDR",
+                "description": "Adds a new device",
                 "consumes": [
                     "application/json"
                 ],
@@ -993,18 +1206,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "Create an Organization",
-                "operationId": "CreateOrganization",
+                "summary": "Add Devices",
+                "operationId": "CreateDevice",
                 "parameters": [
                     {
-                        "description": "Add Organization",
-                        "name": "Organization",
+                        "description": "Add Device",
+                        "name": "Device",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AddOrganization"
+                            "$ref": "#/definitions/models.AddDevice"
                         }
                     }
                 ],
@@ -1012,7 +1225,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Organization"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
@@ -1027,10 +1240,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.QuotaExceededError"
                         }
                     },
                     "409": {
@@ -1054,9 +1267,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/organizations/{id}": {
-            "get": {
-                "description": "Gets a Organization by Organization ID",
+        "/api/v1/devices/batch-delete": {
+            "post": {
+                "description": "Deletes several devices and releases their IPAM allocations, either by id or all the devices of an organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -1064,24 +1277,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "Get Organizations",
-                "operationId": "GetOrganizations",
+                "summary": "Delete Devices",
+                "operationId": "BatchDeleteDevices",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Organization ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Devices to delete",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchDeleteDevices"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Organization"
+                            "$ref": "#/definitions/models.BatchDeviceResult"
                         }
                     },
                     "400": {
@@ -1115,9 +1330,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes an existing organization and associated IPAM prefix",
+            }
+        },
+        "/api/v1/devices/batch-labels": {
+            "post": {
+                "description": "Sets labels on several devices, either by id or all the devices of an organization. A label with an empty value is removed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1125,24 +1342,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "Delete Organization",
-                "operationId": "DeleteOrganization",
+                "summary": "Label Devices",
+                "operationId": "BatchUpdateDeviceLabels",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Organization ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Devices and labels",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchUpdateDeviceLabels"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Organization"
+                            "$ref": "#/definitions/models.BatchDeviceResult"
                         }
                     },
                     "400": {
@@ -1151,8 +1370,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
@@ -1172,9 +1397,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/organizations/{id}/users": {
+        "/api/v1/devices/{id}": {
             "get": {
-                "description": "Lists all the users of an organization",
+                "description": "Gets a device by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1182,14 +1407,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "List Organization Users",
-                "operationId": "ListOrganizationUsers",
+                "summary": "Get Devices",
+                "operationId": "GetDevice",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Organization ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1199,10 +1424,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.UserOrganization"
-                            }
+                            "$ref": "#/definitions/models.Device"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "401": {
@@ -1211,6 +1439,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -1224,11 +1458,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/organizations/{id}/users/{uid}": {
-            "get": {
-                "description": "Gets a Organization User by Organization ID and User ID",
+            },
+            "delete": {
+                "description": "Deletes an existing device and associated IPAM lease",
                 "consumes": [
                     "application/json"
                 ],
@@ -1236,31 +1468,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "Get Organization User",
-                "operationId": "GetOrganizationUser",
+                "summary": "Delete Device",
+                "operationId": "DeleteDevice",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Organization ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/models.UserOrganization"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
@@ -1269,18 +1494,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -1295,8 +1508,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "Deletes an existing organization user",
+            "patch": {
+                "description": "Updates a device by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1304,37 +1517,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Devices"
                 ],
-                "summary": "Delete a Organization User",
-                "operationId": "DeleteOrganizationUser",
+                "summary": "Update Devices",
+                "operationId": "UpdateDevice",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Organization ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "uid",
-                        "in": "path",
-                        "required": true
+                        "description": "Device Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateDevice"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserOrganization"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.ValidationError"
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.QuotaExceededError"
                         }
                     },
                     "404": {
@@ -1343,43 +1570,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/models.InternalServerError"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/reg-keys": {
-            "get": {
-                "description": "Lists all reg keys",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "RegKey"
-                ],
-                "summary": "List reg keys",
-                "operationId": "ListRegKeys",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.RegKey"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.ConflictsError"
                         }
                     },
                     "429": {
@@ -1395,9 +1589,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/devices/{id}/approve": {
             "post": {
-                "description": "Create a RegKey for a vpc",
+                "description": "Approves a device registered in an organization requiring device approval, the device then receives its peers and is a peer of the other devices",
                 "consumes": [
                     "application/json"
                 ],
@@ -1405,26 +1601,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "RegKey"
+                    "Devices"
                 ],
-                "summary": "Create a RegKey",
-                "operationId": "CreateRegKey",
+                "summary": "Approve Device",
+                "operationId": "ApproveDevice",
                 "parameters": [
                     {
-                        "description": "Add RegKey",
-                        "name": "RegKey",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AddRegKey"
-                        }
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RegKey"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
@@ -1433,6 +1627,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1454,9 +1654,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/reg-keys/{id}": {
-            "get": {
-                "description": "Gets a RegKey by RegKey ID",
+        "/api/v1/devices/{id}/connectivity": {
+            "put": {
+                "description": "Replaces the reachability of the peers of a device last reported by its agent, the peers that are not devices of the vpc of the device are ignored",
                 "consumes": [
                     "application/json"
                 ],
@@ -1464,24 +1664,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "RegKey"
+                    "Devices"
                 ],
-                "summary": "Get a RegKey",
-                "operationId": "GetRegKey",
+                "summary": "Report Device Connectivity",
+                "operationId": "ReportDeviceConnectivity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "RegKey ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Connectivity Report",
+                        "name": "report",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReportConnectivity"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RegKey"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PeerConnectivity"
+                            }
                         }
                     },
                     "400": {
@@ -1515,9 +1727,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes an existing RegKey",
+            }
+        },
+        "/api/v1/devices/{id}/hole-punch": {
+            "post": {
+                "description": "Signals a hole punch to a device and a peer behind NATs, so that they send to each other at the same time",
                 "consumes": [
                     "application/json"
                 ],
@@ -1525,91 +1739,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "RegKey"
-                ],
-                "summary": "Delete RegKey",
-                "operationId": "DeleteRegKey",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "RegKey ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "$ref": "#/definitions/models.RegKey"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "405": {
-                        "description": "Method Not Allowed",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.InternalServerError"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Updates a RegKey by ID",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "RegKey"
+                    "Devices"
                 ],
-                "summary": "Update RegKey",
-                "operationId": "UpdateRegKey",
+                "summary": "Coordinate a hole punch",
+                "operationId": "CreateHolePunch",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "RegKey ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "RegKey Update",
-                        "name": "update",
+                        "description": "Hole Punch",
+                        "name": "HolePunch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateRegKey"
+                            "$ref": "#/definitions/models.AddHolePunch"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.RegKey"
+                            "$ref": "#/definitions/models.HolePunch"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.ValidationError"
                         }
                     },
                     "401": {
@@ -1624,12 +1786,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/models.ValidationError"
-                        }
-                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -1645,18 +1801,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/security-groups": {
+        "/api/v1/devices/{id}/metadata": {
             "get": {
-                "description": "Lists all Security Groups",
+                "description": "Lists metadata for a device",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "SecurityGroup"
+                    "Devices"
                 ],
-                "summary": "List Security Groups",
-                "operationId": "ListSecurityGroups",
+                "summary": "List Device Metadata",
+                "operationId": "ListDeviceMetadata",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "greater than revision",
@@ -1670,22 +1836,10 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.SecurityGroup"
+                                "$ref": "#/definitions/models.DeviceMetadata"
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1694,63 +1848,25 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Adds a new Security Group",
-                "produces": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Delete all metadata for a device",
                 "tags": [
-                    "SecurityGroup"
+                    "Devices"
                 ],
-                "summary": "Add SecurityGroup",
-                "operationId": "CreateSecurityGroup",
+                "summary": "Delete all Device metadata",
+                "operationId": "DeleteDeviceMetadata",
                 "parameters": [
                     {
-                        "description": "Add SecurityGroup",
-                        "name": "SecurityGroup",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AddSecurityGroup"
-                        }
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/models.SecurityGroup"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/models.ConflictsError"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/models.ValidationError"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -1761,55 +1877,93 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/security-groups/{id}": {
+        "/api/v1/devices/{id}/metadata/{key}": {
             "get": {
-                "description": "Gets a security group by ID",
-                "produces": [
+                "description": "Get metadata for a device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "SecurityGroup"
+                    "Devices"
                 ],
-                "summary": "Get SecurityGroup",
-                "operationId": "GetSecurityGroup",
+                "summary": "Get Device Metadata",
+                "operationId": "GetDeviceMetadataKey",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Security Group ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata Key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SecurityGroup"
+                            "$ref": "#/definitions/models.DeviceMetadata"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.InternalServerError"
                         }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set metadata key for a device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Devices"
+                ],
+                "summary": "Set Device Metadata by key",
+                "operationId": "UpdateDeviceMetadataKey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Metadata Key",
+                        "name": "key",
+                        "in": "path"
                     },
-                    "404": {
-                        "description": "Not Found",
+                    {
+                        "description": "Metadata Value",
+                        "name": "value",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "type": "object"
                         }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.BaseError"
+                            "$ref": "#/definitions/models.DeviceMetadata"
                         }
                     },
                     "500": {
@@ -1821,42 +1975,30 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes an existing SecurityGroup",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Delete a metadata key for a device",
                 "tags": [
-                    "SecurityGroup"
+                    "Devices"
                 ],
-                "summary": "Delete SecurityGroup",
-                "operationId": "DeleteSecurityGroup",
+                "summary": "Delete a Device metadata key",
+                "operationId": "DeleteDeviceMetadataKey",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Security Group ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metadata Key",
+                        "name": "key",
+                        "in": "path"
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "$ref": "#/definitions/models.SecurityGroup"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
+                        "description": "No Content"
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -1865,32 +2007,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Updates a Security Group by ID",
+            }
+        },
+        "/api/v1/devices/{id}/rotate-key": {
+            "post": {
+                "description": "Replaces the wireguard public key of a device, the device keeps its id, tunnel addresses and security group, and its peers are sent the new key",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "SecurityGroup"
+                    "Devices"
                 ],
-                "summary": "Update Security Group",
-                "operationId": "UpdateSecurityGroup",
+                "summary": "Rotate Device Key",
+                "operationId": "RotateDeviceKey",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Security Group ID",
+                        "description": "Device ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Security Group Update",
-                        "name": "update",
+                        "description": "Device Key",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateSecurityGroup"
+                            "$ref": "#/definitions/models.RotateDeviceKey"
                         }
                     }
                 ],
@@ -1898,7 +2045,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SecurityGroup"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
@@ -1913,16 +2060,22 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/models.ValidationError"
+                            "$ref": "#/definitions/models.ConflictsError"
                         }
                     },
                     "429": {
@@ -1940,9 +2093,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/sites": {
-            "get": {
-                "description": "Lists all sites",
+        "/api/v1/devices/{id}/security-groups/{security_group_id}": {
+            "put": {
+                "description": "Attaches an additional security group to a device, the rules of all the security groups of the device are applied to it",
                 "consumes": [
                     "application/json"
                 ],
@@ -1950,18 +2103,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sites"
+                    "Devices"
+                ],
+                "summary": "Attach Device Security Group",
+                "operationId": "AttachDeviceSecurityGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Security Group ID",
+                        "name": "security_group_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List Sites",
-                "operationId": "ListSites",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Site"
-                            }
+                            "$ref": "#/definitions/models.Device"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "401": {
@@ -1970,6 +2142,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -1984,8 +2162,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Adds a new site",
+            "delete": {
+                "description": "Detaches an additional security group from a device",
                 "consumes": [
                     "application/json"
                 ],
@@ -1993,26 +2171,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sites"
+                    "Devices"
                 ],
-                "summary": "Add Sites",
-                "operationId": "CreateSite",
+                "summary": "Detach Device Security Group",
+                "operationId": "DetachDeviceSecurityGroup",
                 "parameters": [
                     {
-                        "description": "Add Site",
-                        "name": "Site",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.AddSite"
-                        }
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Security Group ID",
+                        "name": "security_group_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Site"
+                            "$ref": "#/definitions/models.Device"
                         }
                     },
                     "400": {
@@ -2027,10 +2210,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/models.ConflictsError"
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "429": {
@@ -2048,9 +2231,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/sites/{id}": {
+        "/api/v1/fflags": {
             "get": {
-                "description": "Gets a site by ID",
+                "description": "Lists all feature flags, with the values of the flags targeting organizations evaluated for an organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -2058,24 +2241,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sites"
+                    "FFlag"
                 ],
-                "summary": "Get Sites",
-                "operationId": "GetSite",
+                "summary": "List Feature Flags",
+                "operationId": "ListFeatureFlags",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "the organization the flags are evaluated for, the default organization of the user by default",
+                        "name": "organization_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Site"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
                         }
                     },
                     "400": {
@@ -2084,12 +2269,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2109,9 +2288,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes an existing site and associated IPAM lease",
+            }
+        },
+        "/api/v1/fflags/{name}": {
+            "get": {
+                "description": "Gets a Feature Flag by name, a flag targeting organizations is evaluated for an organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -2119,82 +2300,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sites"
+                    "FFlag"
                 ],
-                "summary": "Delete Site",
-                "operationId": "DeleteSite",
+                "summary": "Get Feature Flag",
+                "operationId": "GetFeatureFlag",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
+                        "description": "feature flag name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "$ref": "#/definitions/models.Site"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
-                    "429": {
-                        "description": "Too Many Requests",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.InternalServerError"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "description": "Updates a site by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Sites"
-                ],
-                "summary": "Update Sites",
-                "operationId": "UpdateSite",
-                "parameters": [
                     {
                         "type": "string",
-                        "description": "Site ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Site Update",
-                        "name": "update",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateSite"
-                        }
+                        "description": "the organization the flag is evaluated for, the default organization of the user by default",
+                        "name": "organization_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Site"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
                         }
                     },
                     "400": {
@@ -2203,12 +2335,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2230,9 +2356,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users": {
+        "/api/v1/invitations": {
             "get": {
-                "description": "Lists all users",
+                "description": "Lists all invitations",
                 "consumes": [
                     "application/json"
                 ],
@@ -2240,17 +2366,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Invitation"
                 ],
-                "summary": "List Users",
-                "operationId": "ListUsers",
+                "summary": "List Invitations",
+                "operationId": "ListInvitations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.User"
+                                "$ref": "#/definitions/models.Invitation"
                             }
                         }
                     },
@@ -2273,11 +2399,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/users/{id}": {
-            "get": {
-                "description": "Gets a user",
+            },
+            "post": {
+                "description": "Create an invitation to an organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -2285,24 +2409,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Invitation"
                 ],
-                "summary": "Get User",
-                "operationId": "GetUser",
+                "summary": "Create an invitation",
+                "operationId": "CreateInvitation",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Add Invitation",
+                        "name": "Invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddInvitation"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Invitation"
                         }
                     },
                     "400": {
@@ -2311,12 +2437,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.BaseError"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2336,9 +2456,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user",
+            }
+        },
+        "/api/v1/invitations/{id}": {
+            "get": {
+                "description": "Gets an Invitation by Invitation ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2346,14 +2468,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Invitation"
                 ],
-                "summary": "Delete User",
-                "operationId": "DeleteUser",
+                "summary": "Get Invitation",
+                "operationId": "GetInvitation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Invitation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2363,13 +2485,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Invitation"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.NotAllowedError"
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "429": {
@@ -2385,11 +2519,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/users/{id}/organizations/{organization}": {
+            },
             "delete": {
-                "description": "Deletes an existing organization associated to a user",
+                "description": "Deletes an existing invitation",
                 "consumes": [
                     "application/json"
                 ],
@@ -2397,31 +2529,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Invitation"
                 ],
-                "summary": "Remove a User from an Organization",
-                "operationId": "DeleteUserFromOrganization",
+                "summary": "Delete Invitation",
+                "operationId": "DeleteInvitation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Invitation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Organization ID",
-                        "name": "organization",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
@@ -2430,6 +2555,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2439,9 +2576,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpc/{id}/events": {
+        "/api/v1/invitations/{id}/accept": {
             "post": {
-                "description": "Watches events occurring in the vpc",
+                "description": "Accept an invitation to an organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -2449,43 +2586,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Invitation"
                 ],
-                "summary": "Watch events occurring in the vpc",
-                "operationId": "WatchEvents",
+                "summary": "Accept an invitation",
+                "operationId": "AcceptInvitation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "connect as the device with the given public key, device will be considered to be online for the duration of this request",
-                        "name": "public_key",
-                        "in": "query"
-                    },
-                    {
-                        "description": "List of events to watch",
-                        "name": "Watches",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Watch"
-                            }
-                        }
-                    },
-                    {
-                        "type": "string",
-                        "description": "VPC ID",
+                        "description": "Invitation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.WatchEvent"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -2493,8 +2609,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
@@ -2514,9 +2630,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs": {
+        "/api/v1/organizations": {
             "get": {
-                "description": "Lists all VPCs",
+                "description": "Lists all Organizations",
                 "consumes": [
                     "application/json"
                 ],
@@ -2524,17 +2640,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
+                ],
+                "summary": "List Organizations",
+                "operationId": "ListOrganizations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List VPCs",
-                "operationId": "ListVPCs",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.VPC"
+                                "$ref": "#/definitions/models.Organization"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
                             }
                         }
                     },
@@ -2559,7 +2701,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a named vpc with the given CIDR",
+                "description": "Creates a named organization, and its default vpc with the given CIDRs",
                 "consumes": [
                     "application/json"
                 ],
@@ -2567,18 +2709,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "Create an VPC",
-                "operationId": "CreateVPC",
-                "parameters": [
+                "summary": "Create an Organization",
+                "operationId": "CreateOrganization",
+                "parameters": [
                     {
-                        "description": "Add VPC",
-                        "name": "VPC",
+                        "description": "Add Organization",
+                        "name": "Organization",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AddVPC"
+                            "$ref": "#/definitions/models.AddOrganization"
                         }
                     }
                 ],
@@ -2586,7 +2728,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.VPC"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
@@ -2628,9 +2770,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs/{id}": {
+        "/api/v1/organizations/{id}": {
             "get": {
-                "description": "Gets a VPC by VPC ID",
+                "description": "Gets a Organization by Organization ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2638,14 +2780,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "Get VPCs",
-                "operationId": "GetVPC",
+                "summary": "Get Organizations",
+                "operationId": "GetOrganizations",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2655,7 +2797,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.VPC"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
@@ -2691,7 +2833,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes an existing vpc and associated IPAM prefix",
+                "description": "Deletes an existing organization and associated IPAM prefix",
                 "consumes": [
                     "application/json"
                 ],
@@ -2699,14 +2841,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "Delete VPC",
-                "operationId": "DeleteVPC",
+                "summary": "Delete Organization",
+                "operationId": "DeleteOrganization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2716,7 +2858,7 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/models.VPC"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
@@ -2746,7 +2888,7 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Updates a vpc by ID",
+                "description": "Updates an Organization by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -2754,25 +2896,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "Update VPCs",
-                "operationId": "UpdateVPC",
+                "summary": "Update Organization",
+                "operationId": "UpdateOrganization",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "VPC Update",
+                        "description": "Organization Update",
                         "name": "update",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateVPC"
+                            "$ref": "#/definitions/models.UpdateOrganization"
                         }
                     }
                 ],
@@ -2780,7 +2922,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.VPC"
+                            "$ref": "#/definitions/models.Organization"
                         }
                     },
                     "400": {
@@ -2816,9 +2958,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs/{id}/devices": {
+        "/api/v1/organizations/{id}/agent-release": {
             "get": {
-                "description": "Lists all devices for this VPC",
+                "description": "Gets the nexd release advertised on the update channel of the organization for an os and architecture",
                 "consumes": [
                     "application/json"
                 ],
@@ -2826,33 +2968,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "List Devices",
-                "operationId": "ListDevicesInVPC",
+                "summary": "Get Agent Release",
+                "operationId": "GetAgentRelease",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "greater than revision",
-                        "name": "gt_revision",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operating system of the agent",
+                        "name": "os",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "CPU architecture of the agent",
+                        "name": "arch",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Device"
-                            }
+                            "$ref": "#/definitions/models.AgentRelease"
                         }
                     },
                     "400": {
@@ -2867,6 +3014,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -2882,9 +3035,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs/{id}/metadata": {
+        "/api/v1/organizations/{id}/audit": {
             "get": {
-                "description": "Lists metadata for a device",
+                "description": "Lists the create, update, delete and expire operations made on the resources of an organization, and the impersonations of its users, newest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2892,34 +3045,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "List Device Metadata",
-                "operationId": "ListMetadataInVPC",
+                "summary": "List Audit Events",
+                "operationId": "ListAuditEvents",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "greater than revision",
-                        "name": "gt_revision",
+                        "type": "string",
+                        "description": "only list events of this resource type, e.g. device",
+                        "name": "resource_type",
                         "in": "query"
                     },
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "used to filter down to the specified key prefixes",
-                        "name": "prefix",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "only list events of this resource",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events of this action, one of create, update, delete, expire or impersonate",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only list events made by this user",
+                        "name": "actor_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2928,10 +3088,34 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.DeviceMetadata"
+                                "$ref": "#/definitions/models.AuditEvent"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2941,27 +3125,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs/{id}/security-groups": {
+        "/api/v1/organizations/{id}/connectivity": {
             "get": {
-                "description": "Lists all Security Groups in a VPC",
+                "description": "Lists the reachability of the peers last reported by each device of the organization",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "List Security Groups in a VPC",
-                "operationId": "ListSecurityGroupsInVPC",
+                "summary": "List Organization Connectivity",
+                "operationId": "ListOrganizationConnectivity",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "greater than revision",
-                        "name": "gt_revision",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "VPC ID",
+                        "description": "Organization ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2973,16 +3154,28 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.SecurityGroup"
+                                "$ref": "#/definitions/models.PeerConnectivity"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -2998,9 +3191,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/vpcs/{id}/sites": {
+        "/api/v1/organizations/{id}/devices": {
             "get": {
-                "description": "Lists all sites for this VPC",
+                "description": "Lists the devices of an organization that were added, updated or deleted since a revision. Without a revision all devices are listed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3008,33 +3201,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "VPC"
+                    "Organizations"
                 ],
-                "summary": "List Sites",
-                "operationId": "ListSitesInVPC",
+                "summary": "List Device Changes",
+                "operationId": "ListDevicesInOrganization",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "greater than revision",
-                        "name": "gt_revision",
+                        "description": "only list the changes made after this revision",
+                        "name": "since",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "VPC ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "only list the devices of this VPC",
+                        "name": "vpc_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Site"
-                            }
+                            "$ref": "#/definitions/models.DeviceChanges"
                         }
                     },
                     "400": {
@@ -3049,6 +3245,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.BaseError"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
                     "429": {
                         "description": "Too Many Requests",
                         "schema": {
@@ -3064,9 +3266,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/check/auth": {
+        "/api/v1/organizations/{id}/dns-records": {
             "get": {
-                "description": "Checks if the user is currently authenticated",
+                "description": "Lists the custom records of the overlay DNS zone of the organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -3074,26 +3276,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
+                ],
+                "summary": "List DNS Records",
+                "operationId": "ListDNSRecords",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Check Authentication",
-                "operationId": "CheckAuth",
                 "responses": {
                     "200": {
-                        "description": "logged_in status will be returned",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "boolean"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DNSRecord"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
                     }
                 }
-            }
-        },
-        "/device/certs": {
-            "get": {
-                "description": "gets the jwks that can be used to verify JWTs created by this server.",
+            },
+            "post": {
+                "description": "Adds an A, AAAA or CNAME record to the overlay DNS zone of the organization, the agents serve it next to the names of the devices",
                 "consumes": [
                     "application/json"
                 ],
@@ -3101,15 +3340,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
                 ],
-                "summary": "gets the jwks",
-                "operationId": "Certs",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Create a DNS Record",
+                "operationId": "CreateDNSRecord",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add DNS Record",
+                        "name": "DNSRecord",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/models.AddDNSRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DNSRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
                         }
                     },
                     "500": {
@@ -3121,9 +3408,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/device/login/start": {
-            "post": {
-                "description": "Starts a device login request",
+        "/api/v1/organizations/{id}/dns-records/{record_id}": {
+            "get": {
+                "description": "Gets a custom record of the overlay DNS zone of the organization by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -3131,23 +3418,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
+                ],
+                "summary": "Get DNS Record",
+                "operationId": "GetDNSRecord",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DNS Record ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Start Login",
-                "operationId": "DeviceStart",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.DeviceStartResponse"
+                            "$ref": "#/definitions/models.DNSRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
                         }
                     }
                 }
-            }
-        },
-        "/web/claims": {
-            "get": {
-                "description": "Retrieves the claims present in the user's access token.",
+            },
+            "delete": {
+                "description": "Deletes a custom record of the overlay DNS zone of the organization",
                 "consumes": [
                     "application/json"
                 ],
@@ -3155,104 +3486,294 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
+                ],
+                "summary": "Delete DNS Record",
+                "operationId": "DeleteDNSRecord",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "DNS Record ID",
+                        "name": "record_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get Access Token Claims",
-                "operationId": "Claims",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.DNSRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
                         }
                     }
                 }
-            }
-        },
-        "/web/login/end": {
-            "get": {
-                "description": "Handles the callback from the OAuth2/OpenID provider and verifies the tokens.",
+            },
+            "patch": {
+                "description": "Updates the value or the description of a record, the name and the type of a record can not be changed",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
                 ],
-                "summary": "Completes OIDC Web Login",
-                "operationId": "WebEnd",
+                "summary": "Update DNS Record",
+                "operationId": "UpdateDNSRecord",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "oauth2 code from authorization server",
-                        "name": "code",
-                        "in": "query",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "state value from the login start request",
-                        "name": "state",
-                        "in": "query",
+                        "description": "DNS Record ID",
+                        "name": "record_id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "error message if login failed",
-                        "name": "error",
-                        "in": "query",
-                        "required": true
+                        "description": "DNS Record Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateDNSRecord"
+                        }
                     }
                 ],
                 "responses": {
-                    "302": {
-                        "description": "Redirects to the URLs specified in the login start request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.DNSRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
                         }
                     }
                 }
             }
         },
-        "/web/login/start": {
+        "/api/v1/organizations/{id}/ip-exclusion-ranges": {
             "get": {
-                "description": "Generates state and nonce, then redirects the user to the OAuth2 authorization URL.",
+                "description": "Lists the ranges of the organization cidr that IPAM does not hand out",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
                 ],
-                "summary": "Initiates OIDC Web Login",
-                "operationId": "WebStart",
+                "summary": "List IP Exclusion Ranges",
+                "operationId": "ListIPExclusionRanges",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "URL to redirect to if login succeeds",
-                        "name": "redirect",
-                        "in": "query",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "URL to redirect to if login fails (optional)",
-                        "name": "failure",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "302": {
-                        "description": "Redirects to the OAuth2 authorization URL",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPExclusionRange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Excludes a range of the organization cidr from the addresses IPAM hands out, the organization must have its own cidr",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create an IP Exclusion Range",
+                "operationId": "CreateIPExclusionRange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add IP Exclusion Range",
+                        "name": "IPExclusionRange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddIPExclusionRange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.IPExclusionRange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
                         }
                     }
                 }
             }
         },
-        "/web/logout": {
+        "/api/v1/organizations/{id}/ip-exclusion-ranges/{range_id}": {
             "get": {
-                "description": "Provides the URL to initiate the logout process for the current user.",
+                "description": "Gets an exclusion range of the organization by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -3260,87 +3781,4891 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Organizations"
                 ],
-                "summary": "Generate Logout URL",
-                "operationId": "Logout",
+                "summary": "Get IP Exclusion Range",
+                "operationId": "GetIPExclusionRange",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "URL to redirect to after logout",
-                        "name": "redirect",
-                        "in": "query",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP Exclusion Range ID",
+                        "name": "range_id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "302": {
-                        "description": "Redirects to the OAuth2 logout URL",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.IPExclusionRange"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an exclusion range, IPAM hands out its addresses again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete IP Exclusion Range",
+                "operationId": "DeleteIPExclusionRange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP Exclusion Range ID",
+                        "name": "range_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.IPExclusionRange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates the description of an exclusion range, the addresses of a range can not be changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update IP Exclusion Range",
+                "operationId": "UpdateIPExclusionRange",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP Exclusion Range ID",
+                        "name": "range_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "IP Exclusion Range Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateIPExclusionRange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IPExclusionRange"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/ipam": {
+            "get": {
+                "description": "Reports the size, allocated and free address counts, and fragmentation of each prefix of the VPCs of the organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get Organization IPAM Usage",
+                "operationId": "GetOrganizationIPAM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationIPAM"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/service-accounts": {
+            "get": {
+                "description": "Lists the service accounts of the organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List Service Accounts",
+                "operationId": "ListServiceAccounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ServiceAccount"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a service account to the organization, the client secret of the account is only returned in the response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create a Service Account",
+                "operationId": "CreateServiceAccount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add Service Account",
+                        "name": "ServiceAccount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddServiceAccount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/service-accounts/{account_id}": {
+            "get": {
+                "description": "Gets a service account of the organization by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get Service Account",
+                "operationId": "GetServiceAccount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a service account of the organization, its access tokens are not accepted anymore",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete Service Account",
+                "operationId": "DeleteServiceAccount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Service Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/users": {
+            "get": {
+                "description": "Lists all the users of an organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List Organization Users",
+                "operationId": "ListOrganizationUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserOrganization"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/users/{uid}": {
+            "get": {
+                "description": "Gets a Organization User by Organization ID and User ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get Organization User",
+                "operationId": "GetOrganizationUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserOrganization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing organization user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete a Organization User",
+                "operationId": "DeleteOrganizationUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserOrganization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/webhooks": {
+            "get": {
+                "description": "Lists the webhooks the events of the organization are posted to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List Webhooks",
+                "operationId": "ListWebhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a webhook the events of the organization of the given types are posted to, signed with the secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create a Webhook",
+                "operationId": "CreateWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add Webhook",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/webhooks/{webhook_id}": {
+            "get": {
+                "description": "Gets a webhook of the organization by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get Webhook",
+                "operationId": "GetWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a webhook, its pending deliveries are dropped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Delete Webhook",
+                "operationId": "DeleteWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates the url, secret, event types or description of a webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update Webhook",
+                "operationId": "UpdateWebhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{id}/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "description": "Lists the pending, delivered and failed posts of the events to a webhook, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List Webhook Deliveries",
+                "operationId": "ListWebhookDeliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reg-keys": {
+            "get": {
+                "description": "Lists all reg keys",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegKey"
+                ],
+                "summary": "List reg keys",
+                "operationId": "ListRegKeys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RegKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a RegKey for a vpc",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegKey"
+                ],
+                "summary": "Create a RegKey",
+                "operationId": "CreateRegKey",
+                "parameters": [
+                    {
+                        "description": "Add RegKey",
+                        "name": "RegKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddRegKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reg-keys/{id}": {
+            "get": {
+                "description": "Gets a RegKey by RegKey ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegKey"
+                ],
+                "summary": "Get a RegKey",
+                "operationId": "GetRegKey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RegKey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing RegKey",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegKey"
+                ],
+                "summary": "Delete RegKey",
+                "operationId": "DeleteRegKey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RegKey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates a RegKey by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegKey"
+                ],
+                "summary": "Update RegKey",
+                "operationId": "UpdateRegKey",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "RegKey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "RegKey Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRegKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/security-groups": {
+            "get": {
+                "description": "Lists all Security Groups",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SecurityGroup"
+                ],
+                "summary": "List Security Groups",
+                "operationId": "ListSecurityGroups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SecurityGroup"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new Security Group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SecurityGroup"
+                ],
+                "summary": "Add SecurityGroup",
+                "operationId": "CreateSecurityGroup",
+                "parameters": [
+                    {
+                        "description": "Add SecurityGroup",
+                        "name": "SecurityGroup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddSecurityGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SecurityGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.QuotaExceededError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/security-groups/{id}": {
+            "get": {
+                "description": "Gets a security group by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SecurityGroup"
+                ],
+                "summary": "Get SecurityGroup",
+                "operationId": "GetSecurityGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Security Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SecurityGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing SecurityGroup",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SecurityGroup"
+                ],
+                "summary": "Delete SecurityGroup",
+                "operationId": "DeleteSecurityGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Security Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.SecurityGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates a Security Group by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SecurityGroup"
+                ],
+                "summary": "Update Security Group",
+                "operationId": "UpdateSecurityGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Security Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Security Group Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSecurityGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SecurityGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/services": {
+            "get": {
+                "description": "Lists all Services",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Services"
+                ],
+                "summary": "List Services",
+                "operationId": "ListServices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Service"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new Service, a named port of a device published in the overlay DNS of its organization",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Services"
+                ],
+                "summary": "Add Service",
+                "operationId": "CreateService",
+                "parameters": [
+                    {
+                        "description": "Add Service",
+                        "name": "Service",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddService"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/services/{id}": {
+            "get": {
+                "description": "Gets a service by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Services"
+                ],
+                "summary": "Get Service",
+                "operationId": "GetService",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing Service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Services"
+                ],
+                "summary": "Delete Service",
+                "operationId": "DeleteService",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates a Service by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Services"
+                ],
+                "summary": "Update Service",
+                "operationId": "UpdateService",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Service ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Service Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateService"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Service"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sites": {
+            "get": {
+                "description": "Lists all sites",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "List Sites",
+                "operationId": "ListSites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Site"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Add Sites",
+                "operationId": "CreateSite",
+                "parameters": [
+                    {
+                        "description": "Add Site",
+                        "name": "Site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddSite"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sites/{id}": {
+            "get": {
+                "description": "Gets a site by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Get Sites",
+                "operationId": "GetSite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing site and associated IPAM lease",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Delete Site",
+                "operationId": "DeleteSite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates a site by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Update Sites",
+                "operationId": "UpdateSite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Site Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSite"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Site"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users": {
+            "get": {
+                "description": "Lists all users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "List Users",
+                "operationId": "ListUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to sort by, prefix a field with - to sort in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the number of items in a page, enables cursor pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "the cursor of the next page when using cursor pagination"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}": {
+            "get": {
+                "description": "Gets a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get User",
+                "operationId": "GetUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete User",
+                "operationId": "DeleteUser",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotAllowedError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{id}/organizations/{organization}": {
+            "delete": {
+                "description": "Deletes an existing organization associated to a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Remove a User from an Organization",
+                "operationId": "DeleteUserFromOrganization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpc/{id}/events": {
+            "post": {
+                "description": "Watches events occurring in the vpc",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Watch events occurring in the vpc",
+                "operationId": "WatchEvents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "connect as the device with the given public key, device will be considered to be online for the duration of this request",
+                        "name": "public_key",
+                        "in": "query"
+                    },
+                    {
+                        "description": "List of events to watch",
+                        "name": "Watches",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Watch"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs": {
+            "get": {
+                "description": "Lists all VPCs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List VPCs",
+                "operationId": "ListVPCs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.VPC"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a named vpc with the given CIDR",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Create an VPC",
+                "operationId": "CreateVPC",
+                "parameters": [
+                    {
+                        "description": "Add VPC",
+                        "name": "VPC",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddVPC"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.VPC"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}": {
+            "get": {
+                "description": "Gets a VPC by VPC ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Get VPCs",
+                "operationId": "GetVPC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VPC"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes an existing vpc and associated IPAM prefix",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Delete VPC",
+                "operationId": "DeleteVPC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.VPC"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Updates a vpc by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Update VPCs",
+                "operationId": "UpdateVPC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "VPC Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateVPC"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.VPC"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/devices": {
+            "get": {
+                "description": "Lists all devices for this VPC",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List Devices",
+                "operationId": "ListDevicesInVPC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "only list the devices with this label, formatted as key=value, the devices must have all the given labels",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Device"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/dns-records": {
+            "get": {
+                "description": "Lists the custom records of the overlay DNS zone of the organization of a VPC",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List DNS Records in a VPC",
+                "operationId": "ListDNSRecordsInVPC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DNSRecord"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/ip-reservations": {
+            "get": {
+                "description": "Lists the tunnel IPs reserved to device identities in this VPC",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List IP Reservations",
+                "operationId": "ListIPReservationsInVPC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.IPReservation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reserves a tunnel IP of the VPC to a device public key or device id, the device gets the address every time it registers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Create an IP Reservation",
+                "operationId": "CreateIPReservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add IP Reservation",
+                        "name": "IPReservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddIPReservation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.IPReservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConflictsError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/ip-reservations/{reservation_id}": {
+            "delete": {
+                "description": "Deletes an ip reservation, the address is released once no device holds it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "Delete IP Reservation",
+                "operationId": "DeleteIPReservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP Reservation ID",
+                        "name": "reservation_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "$ref": "#/definitions/models.IPReservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/metadata": {
+            "get": {
+                "description": "Lists metadata for a device",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List Device Metadata",
+                "operationId": "ListMetadataInVPC",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "used to filter down to the specified key prefixes",
+                        "name": "prefix",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.DeviceMetadata"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/security-groups": {
+            "get": {
+                "description": "Lists all Security Groups in a VPC",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List Security Groups in a VPC",
+                "operationId": "ListSecurityGroupsInVPC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SecurityGroup"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/services": {
+            "get": {
+                "description": "Lists the Services of the organization of a VPC",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List Services in a VPC",
+                "operationId": "ListServicesInVPC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Service"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vpcs/{id}/sites": {
+            "get": {
+                "description": "Lists all sites for this VPC",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VPC"
+                ],
+                "summary": "List Sites",
+                "operationId": "ListSitesInVPC",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "greater than revision",
+                        "name": "gt_revision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "VPC ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Site"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.BaseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/check/auth": {
+            "get": {
+                "description": "Checks if the user is currently authenticated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Check Authentication",
+                "operationId": "CheckAuth",
+                "responses": {
+                    "200": {
+                        "description": "logged_in status will be returned",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/device/certs": {
+            "get": {
+                "description": "gets the jwks that can be used to verify JWTs created by this server.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "gets the jwks",
+                "operationId": "Certs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/login/authorize": {
+            "post": {
+                "description": "Requests a device login from the device authorization endpoint of the provider, for the devices that log in through the service rather than the provider. Responds like the device authorization endpoint of the provider.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Authorize Device Login",
+                "operationId": "DeviceAuthorize",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "scopes of the tokens of the device login",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the identity provider of the device login, see /web/providers",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceAuthorizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenError"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/login/poll": {
+            "post": {
+                "description": "Polls the token endpoint of the provider for the tokens of a device login, for the devices that poll through the service rather than the provider. Responds like the token endpoint of the provider, with an authorization_pending error when the user has not authorized the device during the poll. With the refresh_token grant type, refreshes the tokens of a device login instead. The id tokens of the responses are verified by the service.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Poll Device Login",
+                "operationId": "DevicePoll",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "urn:ietf:params:oauth:grant-type:device_code, the default, or refresh_token",
+                        "name": "grant_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "device code of the device authorization response, required by the device_code grant type",
+                        "name": "device_code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "refresh token of the device login, required by the refresh_token grant type",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the identity provider of the device login, see /web/providers",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenError"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceTokenError"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/login/start": {
+            "post": {
+                "description": "Starts a device login request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Start Login",
+                "operationId": "DeviceStart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of the identity provider to log in with, see /web/providers",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeviceStartResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/device/token": {
+            "post": {
+                "description": "Issues an access token to the client credentials of a service account (the client credentials grant of RFC 6749), the client id is the ID of the service account. The credentials are sent with HTTP basic authentication or in the form.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Service Account Token",
+                "operationId": "ServiceAccountToken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the service account",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "client secret of the service account",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceAccountToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.InternalServerError"
+                        }
+                    }
+                }
+            }
+        },
+        "/web/backchannel_logout": {
+            "post": {
+                "description": "Ends the sessions of the subject or the provider session of the logout token, called by the provider when the user logs out of it.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "OIDC Back-Channel Logout",
+                "operationId": "WebBackchannelLogout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "logout token of the provider",
+                        "name": "logout_token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the identity provider of the logout token, the default provider when not given",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/web/claims": {
+            "get": {
+                "description": "Retrieves the claims present in the user's access token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get Access Token Claims",
+                "operationId": "Claims",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/web/csrf_token": {
+            "get": {
+                "description": "Gets the CSRF token to send in the X-CSRF-Token header or the csrf_token query parameter of the requests changing the session.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get CSRF Token",
+                "operationId": "WebCSRFToken",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CSRFTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/web/login/end": {
+            "get": {
+                "description": "Handles the callback from the OAuth2/OpenID provider and verifies the tokens.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Completes OIDC Web Login",
+                "operationId": "WebEnd",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "oauth2 code from authorization server",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "state value from the login start request",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "error message if login failed",
+                        "name": "error",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirects to the URLs specified in the login start request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/web/login/start": {
+            "get": {
+                "description": "Generates state, nonce and a PKCE code verifier, then redirects the user to the OAuth2 authorization URL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Initiates OIDC Web Login",
+                "operationId": "WebStart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to redirect to if login succeeds",
+                        "name": "redirect",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL to redirect to if login fails (optional)",
+                        "name": "failure",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the identity provider to log in with, see /web/providers, a page to select one is returned when there are several and none is given",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirects to the OAuth2 authorization URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/web/logout": {
+            "get": {
+                "description": "Revokes the tokens of the current user and provides the URL to initiate the logout process for the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Generate Logout URL",
+                "operationId": "Logout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to redirect to after logout",
+                        "name": "redirect",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "CSRF token, see /web/csrf_token",
+                        "name": "csrf_token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirects to the OAuth2 logout URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/web/providers": {
+            "get": {
+                "description": "Lists the identity providers the users can log in with, the first one is the default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "List Identity Providers",
+                "operationId": "WebProviders",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProviderResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/web/refresh": {
+            "post": {
+                "description": "Obtains and updates a new access token for the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh Access Token",
+                "operationId": "Refresh",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CSRF token, see /web/csrf_token",
+                        "name": "X-CSRF-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/web/user_info": {
+            "get": {
+                "description": "Fetches and returns information for the user who is currently authenticated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Retrieve Current User Information",
+                "operationId": "UserInfo",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserInfoResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description of the api key.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "scopes": {
+                    "description": "Scopes of the api requests of the key.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "description": "Token is only returned when the api key is created, clients send it as the bearer token.",
+                    "type": "string",
+                    "example": "AK:ABCD..."
+                },
+                "user_id": {
+                    "description": "UserID is the ID of the user the api requests of the key are made as.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddAPIKey": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description of the api key.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes of the api key, read-only is all the read scopes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AddDNSRecord": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "www"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "100.100.0.100"
+                }
+            }
+        },
+        "models.AddDevice": {
+            "type": "object",
+            "properties": {
+                "advertise_cidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "172.16.42.0/24"
+                    ]
+                },
+                "disable_ipv6": {
+                    "description": "when set, the device is not assigned an IPv6 tunnel address",
+                    "type": "boolean"
+                },
+                "dns_name": {
+                    "type": "string",
+                    "example": "myhost"
+                },
+                "endpoint_ipv6": {
+                    "type": "string",
+                    "example": "[2001:db8::1]:51820"
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Endpoint"
+                    }
+                },
+                "hostname": {
+                    "type": "string",
+                    "example": "myhost"
+                },
+                "ipv4_tunnel_ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TunnelIP"
+                    }
+                },
+                "listen_port": {
+                    "type": "integer",
+                    "example": 51820
+                },
+                "nat_hairpin": {
+                    "type": "boolean"
+                },
+                "nat_type": {
+                    "type": "string",
+                    "example": "endpoint-independent"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "relay": {
+                    "type": "boolean"
+                },
+                "security_group_id": {
+                    "type": "string"
+                },
+                "symmetric_nat": {
+                    "type": "boolean"
+                },
+                "unmanaged": {
+                    "description": "the device does not run nexd, it is configured with a wg-quick config exported by nexctl",
+                    "type": "boolean"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
+                }
+            }
+        },
+        "models.AddFeatureFlag": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "exit-node"
+                },
+                "organizations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "percentage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AddHolePunch": {
+            "type": "object",
+            "properties": {
+                "peer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddIPExclusionRange": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "end_address": {
+                    "type": "string",
+                    "example": "10.200.0.99"
+                },
+                "start_address": {
+                    "type": "string",
+                    "example": "10.200.0.1"
+                }
+            }
+        },
+        "models.AddIPReservation": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "100.64.0.10"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddInvitation": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "The email address of the user to invite (one of email or user_id is required)",
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "description": "The user id to invite (one of email or user_id is required)",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddOrganization": {
+            "type": "object",
+            "properties": {
+                "delete_expired_devices": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "The Red Zone"
+                },
+                "device_ttl_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "ipv4_cidr": {
+                    "description": "the IPv4 prefix of the default vpc of the organization, the vpc is only created when a prefix is given",
+                    "type": "string",
+                    "example": "10.200.0.0/16"
+                },
+                "ipv6_cidr": {
+                    "description": "the IPv6 prefix of the default vpc of the organization",
+                    "type": "string",
+                    "example": "fd00:200::/64"
+                },
+                "listen_port_max": {
+                    "type": "integer",
+                    "example": 51830
+                },
+                "listen_port_min": {
+                    "type": "integer",
+                    "example": 51820
+                },
+                "name": {
+                    "type": "string",
+                    "example": "zone-red"
+                },
+                "require_device_approval": {
+                    "type": "boolean"
+                },
+                "update_channel": {
+                    "type": "string",
+                    "example": "stable"
+                }
+            }
+        },
+        "models.AddRegKey": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description of the registration key.",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is optional, if set the registration key is only valid until the ExpiresAt time.",
+                    "type": "string"
+                },
+                "security_group_id": {
+                    "description": "SecurityGroupId is the ID of the security group to assign to the device.",
+                    "type": "string"
+                },
+                "settings": {
+                    "description": "Settings contains general settings for the device.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "single_use": {
+                    "description": "SingleUse only allows the registration key to be used once.",
+                    "type": "boolean"
+                },
+                "vpc_id": {
+                    "description": "VpcID is the ID of the VPC the device will join.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddSecurityGroup": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "group_description"
+                },
+                "inbound_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecurityRule"
+                    }
+                },
+                "outbound_rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SecurityRule"
+                    }
+                },
+                "vpc_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AddService": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "the database of the inventory app"
+                },
+                "device_id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "postgres"
+                },
+                "port": {
+                    "type": "integer",
+                    "example": 5432
+                },
+                "protocol": {
+                    "type": "string",
+                    "example": "tcp"
+                }
+            }
+        },
+        "models.AddServiceAccount": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ci"
+                },
+                "roles": {
+                    "description": "Roles of the service account in the organization, member when not set.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AddSite": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "public_key": {
+                    "type": "string"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
+                }
+            }
+        },
+        "models.AddVPC": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "The Red Zone"
+                },
+                "ipv4_cidr": {
+                    "type": "string",
+                    "example": "172.16.42.0/24"
+                },
+                "ipv6_cidr": {
+                    "type": "string",
+                    "example": "0200::/8"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "private_cidr": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.AddWebhook": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "device.create",
+                        "device.delete"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/nexodus"
+                }
+            }
+        },
+        "models.AdminImpersonateUser": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Reason the user is impersonated, it is recorded in the audit log.",
+                    "type": "string",
+                    "example": "support ticket 1234"
+                }
+            }
+        },
+        "models.AgentRelease": {
+            "type": "object",
+            "properties": {
+                "arch": {
+                    "type": "string",
+                    "example": "amd64"
+                },
+                "channel": {
+                    "type": "string",
+                    "example": "stable"
+                },
+                "os": {
+                    "type": "string",
+                    "example": "linux"
+                },
+                "sha256": {
+                    "description": "hex encoded sha256 digest of the binary",
+                    "type": "string"
+                },
+                "signature": {
+                    "description": "base64 encoded ed25519 signature of the binary",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://nexodus-io.s3.amazonaws.com/nexd-linux-amd64"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v0.1.0"
+                }
+            }
+        },
+        "models.AuditChange": {
+            "type": "object",
+            "properties": {
+                "new": {},
+                "old": {}
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor_id": {
+                    "description": "the user that made the change",
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.AuditChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string",
+                    "example": "device"
+                },
+                "source_ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                }
+            }
+        },
+        "models.BaseError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad"
+                }
+            }
+        },
+        "models.BatchDeleteDevices": {
+            "type": "object",
+            "properties": {
+                "device_ids": {
+                    "description": "the devices to delete",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "organization_id": {
+                    "description": "deletes all the devices of the organization, only an owner of the organization can",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchDeviceFailure": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchDeviceResult": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchDeviceFailure"
+                    }
+                },
+                "succeeded": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BatchUpdateDeviceLabels": {
+            "type": "object",
+            "properties": {
+                "device_ids": {
+                    "description": "the devices to label",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "labels": {
+                    "description": "the labels to set on the devices, a label with an empty value is removed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "organization_id": {
+                    "description": "labels all the devices of the organization, only an owner of the organization can",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CSRFTokenResponse": {
+            "type": "object",
+            "properties": {
+                "csrf_token": {
+                    "description": "the token to send in the X-CSRF-Token header, or the csrf_token query parameter, of the requests changing the session",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CertificateSigningRequest": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Requested 'duration' (i.e. lifetime) of the Certificate. Note that the\nissuer may choose to ignore the requested duration, just like any other\nrequested attribute.\n+optional",
+                    "type": "string"
+                },
+                "is_ca": {
+                    "description": "Requested basic constraints isCA value. Note that the issuer may choose\nto ignore the requested isCA value, just like any other requested attribute.\n\nNOTE: If the CSR in the ` + "`" + `Request` + "`" + ` field has a BasicConstraints extension,\nit must have the same isCA value as specified here.\n\nIf true, this will automatically add the ` + "`" + `cert sign` + "`" + ` usage to the list\nof requested ` + "`" + `usages` + "`" + `.\n+optional",
+                    "type": "boolean"
+                },
+                "request": {
+                    "description": "The PEM-encoded X.509 certificate signing request to be submitted to the\nissuer for signing.\n\nIf the CSR has a BasicConstraints extension, its isCA attribute must\nmatch the ` + "`" + `isCA` + "`" + ` value of this CertificateRequest.\nIf the CSR has a KeyUsage extension, its key usages must match the\nkey usages in the ` + "`" + `usages` + "`" + ` field of this CertificateRequest.\nIf the CSR has a ExtKeyUsage extension, its extended key usages\nmust match the extended key usages in the ` + "`" + `usages` + "`" + ` field of this\nCertificateRequest.",
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE REQUEST-----(...)-----END CERTIFICATE REQUEST-----"
+                },
+                "usages": {
+                    "description": "Requested key usages and extended key usages.\n\nNOTE: If the CSR in the ` + "`" + `Request` + "`" + ` field has uses the KeyUsage or\nExtKeyUsage extension, these extensions must have the same values\nas specified here without any additional values.\n\nIf unset, defaults to ` + "`" + `digital signature` + "`" + ` and ` + "`" + `key encipherment` + "`" + `.\n+optional",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.KeyUsage"
                     }
                 }
             }
         },
-        "/web/refresh": {
-            "post": {
-                "description": "Obtains and updates a new access token for the user.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Refresh Access Token",
-                "operationId": "Refresh",
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    }
+        "models.CertificateSigningResponse": {
+            "type": "object",
+            "properties": {
+                "ca": {
+                    "description": "The PEM encoded X.509 certificate of the signer, also known as the CA\n(Certificate Authority).\nThis is set on a best-effort basis by different issuers.\nIf not set, the CA is assumed to be unknown/not available.\n+optional",
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE-----(...)-----END CERTIFICATE-----"
+                },
+                "certificate": {
+                    "description": "The PEM encoded X.509 certificate resulting from the certificate\nsigning request.\nIf not set, the CertificateRequest has either not been completed or has\nfailed. More information on failure can be found by checking the\n` + "`" + `conditions` + "`" + ` field.\n+optional",
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE-----(...)-----END CERTIFICATE-----"
                 }
             }
         },
-        "/web/user_info": {
-            "get": {
-                "description": "Fetches and returns information for the user who is currently authenticated.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Retrieve Current User Information",
-                "operationId": "UserInfo",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.UserInfoResponse"
-                        }
-                    }
+        "models.ConflictsError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a1fae5de-dd96-4b20-8362-95f6a574c4b1"
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.AddDevice": {
+        },
+        "models.DNSRecord": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "name": {
+                    "description": "the name of the record in the zone of the organization",
+                    "type": "string",
+                    "example": "www"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "A, AAAA or CNAME",
+                    "type": "string",
+                    "example": "A"
+                },
+                "value": {
+                    "description": "the address of an A or AAAA record, the target name of a CNAME record",
+                    "type": "string",
+                    "example": "100.100.0.100"
+                }
+            }
+        },
+        "models.Device": {
             "type": "object",
             "properties": {
                 "advertise_cidrs": {
                     "type": "array",
                     "items": {
                         "type": "string"
-                    },
-                    "example": [
-                        "172.16.42.0/24"
-                    ]
+                    }
+                },
+                "allowed_ips": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "bearer_token": {
+                    "description": "the token nexd should use to reconcile device state.",
+                    "type": "string"
+                },
+                "display_name": {
+                    "description": "the name users gave the device, the dns name of the device is derived from it when set",
+                    "type": "string"
+                },
+                "dns_name": {
+                    "description": "the name reserved for the device in the overlay DNS of the organization",
+                    "type": "string"
+                },
+                "endpoint_ipv6": {
+                    "description": "the global IPv6 endpoint of the device, empty when it has no global IPv6 connectivity",
+                    "type": "string"
                 },
                 "endpoints": {
                     "type": "array",
@@ -3348,9 +8673,16 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.Endpoint"
                     }
                 },
+                "expired_at": {
+                    "description": "when the device was flagged for not being seen for the device ttl of its organization, cleared when it is seen again",
+                    "type": "string"
+                },
                 "hostname": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string",
-                    "example": "myhost"
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
                 },
                 "ipv4_tunnel_ips": {
                     "type": "array",
@@ -3358,342 +8690,364 @@ const docTemplate = `{
                         "$ref": "#/definitions/models.TunnelIP"
                     }
                 },
+                "ipv6_tunnel_ips": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TunnelIP"
+                    }
+                },
+                "labels": {
+                    "description": "key/value inventory metadata operators attach to the device, e.g. rack, owner or environment",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "last_seen": {
+                    "description": "the last time the device was connected to the event stream of the service",
+                    "type": "string"
+                },
+                "listen_port": {
+                    "type": "integer"
+                },
+                "nat_hairpin": {
+                    "description": "whether the NAT in front of the device supports hairpinning",
+                    "type": "boolean"
+                },
+                "nat_type": {
+                    "description": "the RFC 5780 mapping behavior of the NAT in front of the device, empty when unknown",
+                    "type": "string"
+                },
+                "online": {
+                    "type": "boolean"
+                },
+                "online_at": {
+                    "type": "string"
+                },
                 "os": {
                     "type": "string"
                 },
-                "public_key": {
+                "owner_id": {
                     "type": "string"
                 },
-                "relay": {
+                "pending": {
+                    "description": "the device awaits the approval of an organization owner, it neither receives peers nor is a peer of the other devices",
                     "type": "boolean"
                 },
-                "security_group_id": {
+                "public_key": {
                     "type": "string"
                 },
-                "symmetric_nat": {
+                "relay": {
                     "type": "boolean"
                 },
-                "vpc_id": {
-                    "type": "string",
-                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
-                }
-            }
-        },
-        "models.AddInvitation": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "description": "The email address of the user to invite (one of email or user_id is required)",
+                "relay_id": {
+                    "description": "the relay the service assigned to the device when it is behind a symmetric NAT",
                     "type": "string"
                 },
-                "organization_id": {
+                "revision": {
+                    "type": "integer"
+                },
+                "security_group_id": {
                     "type": "string"
                 },
-                "roles": {
+                "security_group_ids": {
+                    "description": "the additional security groups attached to the device, denormalized from the device_security_groups table",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "user_id": {
-                    "description": "The user id to invite (one of email or user_id is required)",
-                    "type": "string"
-                }
-            }
-        },
-        "models.AddOrganization": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "The Red Zone"
+                "symmetric_nat": {
+                    "type": "boolean"
                 },
-                "name": {
+                "unmanaged": {
+                    "description": "the device does not run nexd, it is configured with a wg-quick config exported by nexctl",
+                    "type": "boolean"
+                },
+                "vpc_id": {
                     "type": "string",
-                    "example": "zone-red"
+                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
                 }
             }
         },
-        "models.AddRegKey": {
+        "models.DeviceAuthorizationResponse": {
             "type": "object",
             "properties": {
-                "description": {
-                    "description": "Description of the registration key.",
+                "device_code": {
                     "type": "string"
                 },
-                "expires_at": {
-                    "description": "ExpiresAt is optional, if set the registration key is only valid until the ExpiresAt time.",
-                    "type": "string"
+                "expires_in": {
+                    "type": "integer"
                 },
-                "security_group_id": {
-                    "description": "SecurityGroupId is the ID of the security group to assign to the device.",
-                    "type": "string"
+                "interval": {
+                    "type": "integer"
                 },
-                "settings": {
-                    "description": "Settings contains general settings for the device.",
-                    "type": "object",
-                    "additionalProperties": true
+                "user_code": {
+                    "type": "string"
                 },
-                "single_use": {
-                    "description": "SingleUse only allows the registration key to be used once.",
-                    "type": "boolean"
+                "verification_uri": {
+                    "type": "string"
                 },
-                "vpc_id": {
-                    "description": "VpcID is the ID of the VPC the device will join.",
+                "verification_uri_complete": {
                     "type": "string"
                 }
             }
         },
-        "models.AddSecurityGroup": {
+        "models.DeviceChanges": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "group_description"
-                },
-                "inbound_rules": {
+                "deleted": {
+                    "description": "the ids of the devices that were deleted",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.SecurityRule"
+                        "type": "string"
                     }
                 },
-                "outbound_rules": {
+                "devices": {
+                    "description": "the devices that were added or updated",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.SecurityRule"
+                        "$ref": "#/definitions/models.Device"
                     }
                 },
-                "vpc_id": {
-                    "type": "string"
+                "revision": {
+                    "description": "the revision to list the next changes from",
+                    "type": "integer"
                 }
             }
         },
-        "models.AddSite": {
+        "models.DeviceMetadata": {
             "type": "object",
             "properties": {
-                "name": {
+                "device_id": {
                     "type": "string"
                 },
-                "platform": {
+                "key": {
                     "type": "string"
                 },
-                "public_key": {
-                    "type": "string"
+                "revision": {
+                    "type": "integer"
                 },
-                "vpc_id": {
-                    "type": "string",
-                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
-                }
+                "value": {}
             }
         },
-        "models.AddVPC": {
+        "models.DeviceStartResponse": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "The Red Zone"
-                },
-                "ipv4_cidr": {
-                    "type": "string",
-                    "example": "172.16.42.0/24"
+                "client_id": {
+                    "type": "string"
                 },
-                "ipv6_cidr": {
-                    "type": "string",
-                    "example": "0200::/8"
+                "device_authorization_endpoint": {
+                    "description": "TODO: Remove this once golang/oauth2 supports device flow\nand when coreos/go-oidc adds device_authorization_endpoint discovery",
+                    "type": "string"
                 },
-                "organization_id": {
+                "issuer": {
                     "type": "string"
                 },
-                "private_cidr": {
-                    "type": "boolean"
+                "server_time": {
+                    "description": "the current time on the server, can be used by a client to get an idea of what the time skew is\nin relation to the server.",
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         },
-        "models.BaseError": {
+        "models.DeviceTokenError": {
             "type": "object",
             "properties": {
                 "error": {
-                    "type": "string",
-                    "example": "something bad"
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
                 }
             }
         },
-        "models.CertificateSigningRequest": {
+        "models.DeviceTokenResponse": {
             "type": "object",
             "properties": {
-                "duration": {
-                    "description": "Requested 'duration' (i.e. lifetime) of the Certificate. Note that the\nissuer may choose to ignore the requested duration, just like any other\nrequested attribute.\n+optional",
+                "access_token": {
                     "type": "string"
                 },
-                "is_ca": {
-                    "description": "Requested basic constraints isCA value. Note that the issuer may choose\nto ignore the requested isCA value, just like any other requested attribute.\n\nNOTE: If the CSR in the ` + "`" + `Request` + "`" + ` field has a BasicConstraints extension,\nit must have the same isCA value as specified here.\n\nIf true, this will automatically add the ` + "`" + `cert sign` + "`" + ` usage to the list\nof requested ` + "`" + `usages` + "`" + `.\n+optional",
-                    "type": "boolean"
+                "expires_in": {
+                    "type": "integer"
                 },
-                "request": {
-                    "description": "The PEM-encoded X.509 certificate signing request to be submitted to the\nissuer for signing.\n\nIf the CSR has a BasicConstraints extension, its isCA attribute must\nmatch the ` + "`" + `isCA` + "`" + ` value of this CertificateRequest.\nIf the CSR has a KeyUsage extension, its key usages must match the\nkey usages in the ` + "`" + `usages` + "`" + ` field of this CertificateRequest.\nIf the CSR has a ExtKeyUsage extension, its extended key usages\nmust match the extended key usages in the ` + "`" + `usages` + "`" + ` field of this\nCertificateRequest.",
-                    "type": "string",
-                    "example": "-----BEGIN CERTIFICATE REQUEST-----(...)-----END CERTIFICATE REQUEST-----"
+                "id_token": {
+                    "type": "string"
                 },
-                "usages": {
-                    "description": "Requested key usages and extended key usages.\n\nNOTE: If the CSR in the ` + "`" + `Request` + "`" + ` field has uses the KeyUsage or\nExtKeyUsage extension, these extensions must have the same values\nas specified here without any additional values.\n\nIf unset, defaults to ` + "`" + `digital signature` + "`" + ` and ` + "`" + `key encipherment` + "`" + `.\n+optional",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.KeyUsage"
-                    }
-                }
-            }
-        },
-        "models.CertificateSigningResponse": {
-            "type": "object",
-            "properties": {
-                "ca": {
-                    "description": "The PEM encoded X.509 certificate of the signer, also known as the CA\n(Certificate Authority).\nThis is set on a best-effort basis by different issuers.\nIf not set, the CA is assumed to be unknown/not available.\n+optional",
-                    "type": "string",
-                    "example": "-----BEGIN CERTIFICATE-----(...)-----END CERTIFICATE-----"
+                "refresh_token": {
+                    "type": "string"
                 },
-                "certificate": {
-                    "description": "The PEM encoded X.509 certificate resulting from the certificate\nsigning request.\nIf not set, the CertificateRequest has either not been completed or has\nfailed. More information on failure can be found by checking the\n` + "`" + `conditions` + "`" + ` field.\n+optional",
-                    "type": "string",
-                    "example": "-----BEGIN CERTIFICATE-----(...)-----END CERTIFICATE-----"
+                "token_type": {
+                    "type": "string"
                 }
             }
         },
-        "models.ConflictsError": {
+        "models.Endpoint": {
             "type": "object",
             "properties": {
-                "error": {
+                "address": {
+                    "description": "IP address and port of the endpoint.",
                     "type": "string",
-                    "example": "something bad"
+                    "example": "10.1.1.1:51820"
                 },
-                "id": {
-                    "type": "string",
-                    "example": "a1fae5de-dd96-4b20-8362-95f6a574c4b1"
+                "priority": {
+                    "description": "Priority of the endpoint candidate, peers try the candidates with a higher priority first.",
+                    "type": "integer",
+                    "example": 126
+                },
+                "source": {
+                    "description": "How the endpoint was discovered",
+                    "type": "string"
                 }
             }
         },
-        "models.Device": {
+        "models.FeatureFlag": {
             "type": "object",
             "properties": {
-                "advertise_cidrs": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "allowed_ips": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "bearer_token": {
-                    "description": "the token nexd should use to reconcile device state.",
+                "description": {
                     "type": "string"
                 },
-                "endpoints": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Endpoint"
-                    }
-                },
-                "hostname": {
-                    "type": "string"
+                "enabled": {
+                    "description": "Enabled is the value of the flag for the organizations that are not targeted.",
+                    "type": "boolean"
                 },
                 "id": {
                     "type": "string",
                     "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
                 },
-                "ipv4_tunnel_ips": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.TunnelIP"
-                    }
+                "name": {
+                    "type": "string",
+                    "example": "exit-node"
                 },
-                "ipv6_tunnel_ips": {
+                "organizations": {
+                    "description": "Organizations are the IDs of the organizations the flag is enabled for.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.TunnelIP"
+                        "type": "string"
                     }
                 },
-                "online": {
+                "percentage": {
+                    "description": "Percentage of the other organizations the flag is enabled for, from 0 to 100.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.HolePunch": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "description": "when set, the device sprays many ports of the peer, whose NAT maps a different port per destination",
                     "type": "boolean"
                 },
-                "online_at": {
+                "device_id": {
+                    "description": "the device the hole punch is signaled to",
                     "type": "string"
                 },
-                "os": {
+                "id": {
                     "type": "string"
                 },
-                "owner_id": {
-                    "type": "string"
+                "peer_endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Endpoint"
+                    }
                 },
-                "public_key": {
+                "peer_id": {
                     "type": "string"
                 },
-                "relay": {
-                    "type": "boolean"
-                },
-                "revision": {
-                    "type": "integer"
-                },
-                "security_group_id": {
+                "peer_nat_type": {
                     "type": "string"
                 },
-                "symmetric_nat": {
-                    "type": "boolean"
+                "peer_public_key": {
+                    "type": "string"
                 },
-                "vpc_id": {
-                    "type": "string",
-                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
+                "start_at": {
+                    "description": "when both devices start sending",
+                    "type": "string"
                 }
             }
         },
-        "models.DeviceMetadata": {
+        "models.IPAMPrefixUsage": {
             "type": "object",
             "properties": {
-                "device_id": {
-                    "type": "string"
+                "allocated": {
+                    "type": "integer"
                 },
-                "key": {
-                    "type": "string"
+                "cidr": {
+                    "type": "string",
+                    "example": "100.64.0.0/10"
                 },
-                "revision": {
+                "fragmentation": {
+                    "description": "Fragmentation is 0 when the free addresses form a single block and approaches 1 as they get scattered",
+                    "type": "number",
+                    "example": 0.25
+                },
+                "free": {
                     "type": "integer"
                 },
-                "value": {}
+                "shared": {
+                    "description": "Shared is set when the prefix is the pool shared by all the VPCs without a private cidr",
+                    "type": "boolean"
+                },
+                "size": {
+                    "description": "Size is the number of addresses of the prefix, capped at 2147483647",
+                    "type": "integer"
+                },
+                "vpc_id": {
+                    "type": "string"
+                }
             }
         },
-        "models.DeviceStartResponse": {
+        "models.IPExclusionRange": {
             "type": "object",
             "properties": {
-                "client_id": {
+                "description": {
                     "type": "string"
                 },
-                "device_authorization_endpoint": {
-                    "description": "TODO: Remove this once golang/oauth2 supports device flow\nand when coreos/go-oidc adds device_authorization_endpoint discovery",
-                    "type": "string"
+                "end_address": {
+                    "description": "the last excluded address",
+                    "type": "string",
+                    "example": "10.200.0.99"
                 },
-                "issuer": {
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "organization_id": {
                     "type": "string"
                 },
-                "server_time": {
-                    "description": "the current time on the server, can be used by a client to get an idea of what the time skew is\nin relation to the server.",
+                "start_address": {
+                    "description": "the first excluded address",
                     "type": "string",
-                    "format": "date-time"
+                    "example": "10.200.0.1"
                 }
             }
         },
-        "models.Endpoint": {
+        "models.IPReservation": {
             "type": "object",
             "properties": {
                 "address": {
-                    "description": "IP address and port of the endpoint.",
                     "type": "string",
-                    "example": "10.1.1.1:51820"
+                    "example": "100.64.0.10"
                 },
-                "source": {
-                    "description": "How the endpoint was discovered",
+                "description": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "description": "the id of the device the address is reserved to",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "public_key": {
+                    "description": "the public key of the device the address is reserved to",
                     "type": "string"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "694aa002-5d19-495e-980b-3d8fd508ea10"
                 }
             }
         },
@@ -3796,32 +9150,170 @@ const docTemplate = `{
                 "UsageNetscapeSGC"
             ]
         },
-        "models.NotAllowedError": {
+        "models.NotAllowedError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "delete_expired_devices": {
+                    "description": "expired devices are deleted and their IPAM allocations released, instead of only flagged",
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Team A"
+                },
+                "device_ttl_days": {
+                    "description": "devices not seen for this many days are expired, 0 means devices do not expire",
+                    "type": "integer",
+                    "example": 30
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "listen_port_max": {
+                    "description": "the highest wireguard listen port devices may use, 0 means no limit",
+                    "type": "integer",
+                    "example": 51830
+                },
+                "listen_port_min": {
+                    "description": "the lowest wireguard listen port devices may use, 0 means no limit",
+                    "type": "integer",
+                    "example": 51820
+                },
+                "name": {
+                    "type": "string",
+                    "example": "zone-red"
+                },
+                "quota": {
+                    "$ref": "#/definitions/models.OrganizationQuota"
+                },
+                "require_device_approval": {
+                    "description": "new devices of the org are pending until an owner of the org approves them",
+                    "type": "boolean"
+                },
+                "update_channel": {
+                    "description": "the agent release channel devices in the org follow",
+                    "type": "string",
+                    "example": "stable"
+                }
+            }
+        },
+        "models.OrganizationIPAM": {
+            "type": "object",
+            "properties": {
+                "organization_id": {
+                    "type": "string"
+                },
+                "prefixes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IPAMPrefixUsage"
+                    }
+                }
+            }
+        },
+        "models.OrganizationQuota": {
+            "type": "object",
+            "properties": {
+                "max_child_prefixes": {
+                    "description": "the total number of cidrs advertised by the devices",
+                    "type": "integer",
+                    "example": 20
+                },
+                "max_devices": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "max_security_groups": {
+                    "description": "not counting the default security group of each vpc",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "models.PeerConnectivity": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "description": "the device that probed the peer",
+                    "type": "string"
+                },
+                "latency_avg_ms": {
+                    "type": "number"
+                },
+                "latency_max_ms": {
+                    "type": "number"
+                },
+                "loss": {
+                    "description": "the fraction of the last probes that were not answered",
+                    "type": "number"
+                },
+                "method": {
+                    "description": "the peering method of the device with the peer",
+                    "type": "string",
+                    "example": "direct"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "peer_id": {
+                    "type": "string"
+                },
+                "reachability": {
+                    "description": "reachable, degraded or unreachable",
+                    "type": "string",
+                    "example": "reachable"
+                },
+                "updated_at": {
+                    "description": "the time of the report",
+                    "type": "string"
+                },
+                "vpc_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProviderResponse": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "something bad"
+                "display_name": {
+                    "type": "string"
                 },
-                "reason": {
+                "issuer": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "the name of the provider in the provider query parameter of the login requests",
                     "type": "string"
                 }
             }
         },
-        "models.Organization": {
+        "models.QuotaExceededError": {
             "type": "object",
             "properties": {
-                "description": {
+                "error": {
                     "type": "string",
-                    "example": "Team A"
+                    "example": "something bad"
                 },
-                "id": {
-                    "type": "string",
-                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                "limit": {
+                    "type": "integer",
+                    "example": 100
                 },
-                "name": {
+                "resource": {
                     "type": "string",
-                    "example": "zone-red"
+                    "example": "devices"
                 }
             }
         },
@@ -3867,6 +9359,50 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ReportConnectivity": {
+            "type": "object",
+            "properties": {
+                "peers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReportPeerConnectivity"
+                    }
+                }
+            }
+        },
+        "models.ReportPeerConnectivity": {
+            "type": "object",
+            "properties": {
+                "latency_avg_ms": {
+                    "type": "number"
+                },
+                "latency_max_ms": {
+                    "type": "number"
+                },
+                "loss": {
+                    "type": "number"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "direct"
+                },
+                "peer_id": {
+                    "type": "string"
+                },
+                "reachability": {
+                    "type": "string",
+                    "example": "reachable"
+                }
+            }
+        },
+        "models.RotateDeviceKey": {
+            "type": "object",
+            "properties": {
+                "public_key": {
+                    "type": "string"
+                }
+            }
+        },
         "models.SecurityGroup": {
             "type": "object",
             "properties": {
@@ -3917,6 +9453,93 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Service": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "postgres"
+                },
+                "port": {
+                    "type": "integer",
+                    "example": 5432
+                },
+                "protocol": {
+                    "type": "string",
+                    "example": "tcp"
+                },
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ServiceAccount": {
+            "type": "object",
+            "properties": {
+                "client_secret": {
+                    "description": "ClientSecret is only returned when the service account is created.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description of the service account.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "name": {
+                    "description": "Name of the service account, the username of its user.",
+                    "type": "string",
+                    "example": "ci"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "OwnerID is the ID of the user that created the service account.",
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles of the service account in the organization.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "description": "UserID is the ID of the user the api requests of the service account are made as.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ServiceAccountToken": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "ExpiresIn is the lifetime of the access token in seconds.",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
         "models.Site": {
             "type": "object",
             "properties": {
@@ -3974,6 +9597,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateDNSRecord": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UpdateDevice": {
             "type": "object",
             "properties": {
@@ -3986,6 +9620,20 @@ const docTemplate = `{
                         "172.16.42.0/24"
                     ]
                 },
+                "display_name": {
+                    "description": "renames the device, an empty name reverts to the hostname",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "Build Server"
+                },
+                "dns_name": {
+                    "type": "string",
+                    "example": "myhost"
+                },
+                "endpoint_ipv6": {
+                    "type": "string",
+                    "example": "[2001:db8::1]:51820"
+                },
                 "endpoints": {
                     "type": "array",
                     "items": {
@@ -3996,6 +9644,24 @@ const docTemplate = `{
                     "type": "string",
                     "example": "myhost"
                 },
+                "labels": {
+                    "description": "the labels to set on the device, a label with an empty value is removed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "listen_port": {
+                    "type": "integer",
+                    "example": 51820
+                },
+                "nat_hairpin": {
+                    "type": "boolean"
+                },
+                "nat_type": {
+                    "type": "string",
+                    "example": "endpoint-independent"
+                },
                 "relay": {
                     "type": "boolean"
                 },
@@ -4014,6 +9680,69 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateFeatureFlag": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "organizations": {
+                    "description": "Organizations replace the targeted organizations when present, an empty list removes them.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "percentage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UpdateIPExclusionRange": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateOrganization": {
+            "type": "object",
+            "properties": {
+                "delete_expired_devices": {
+                    "type": "boolean",
+                    "x-nullable": true
+                },
+                "description": {
+                    "type": "string",
+                    "example": "The Red Zone"
+                },
+                "device_ttl_days": {
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 30
+                },
+                "listen_port_max": {
+                    "type": "integer",
+                    "example": 51830
+                },
+                "listen_port_min": {
+                    "type": "integer",
+                    "example": 51820
+                },
+                "require_device_approval": {
+                    "type": "boolean",
+                    "x-nullable": true
+                },
+                "update_channel": {
+                    "type": "string",
+                    "example": "stable"
+                }
+            }
+        },
         "models.UpdateRegKey": {
             "type": "object",
             "properties": {
@@ -4056,6 +9785,27 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateService": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "the database of the inventory app"
+                },
+                "device_id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "port": {
+                    "type": "integer",
+                    "example": 5432
+                },
+                "protocol": {
+                    "type": "string",
+                    "example": "tcp"
+                }
+            }
+        },
         "models.UpdateSite": {
             "type": "object",
             "properties": {
@@ -4083,6 +9833,26 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateWebhook": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -4227,6 +9997,67 @@ const docTemplate = `{
                 },
                 "value": {}
             }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "EventTypes are the types of the events posted to the webhook.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "device.create",
+                        "device.delete"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/nexodus"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "device.create"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "aa22666c-0f57-45cb-a449-16efecc04f2e"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -47,6 +47,13 @@ func (f *FFlags) getFlagValue(c *gin.Context, name string, fn func() bool) bool
 	return fn()
 }
 
+// Overridden returns whether the value of the flag is set in the gin
+// context of the request, which takes precedence over any other value.
+func (f *FFlags) Overridden(c *gin.Context, name string) bool {
+	_, found := c.Get(fmt.Sprintf("nexodus.fflag.%s", name))
+	return found
+}
+
 // ListFlags returns a map of all currently defined feature flags and
 // whether those features are enabled (true) or not (false).
 func (f *FFlags) ListFlags(c *gin.Context) map[string]bool {
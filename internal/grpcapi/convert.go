@@ -0,0 +1,275 @@
+package grpcapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	nexodusv1 "github.com/nexodus-io/nexodus/internal/grpcapi/proto/nexodus/v1"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// parseID parses the uuid of a field of a request, an empty field is uuid.Nil
+func parseID(field string, value string) (uuid.UUID, error) {
+	if value == "" {
+		return uuid.Nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "%s must be a uuid", field)
+	}
+	return id, nil
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toTunnelIPs(ips []models.TunnelIP) []*nexodusv1.TunnelIP {
+	result := make([]*nexodusv1.TunnelIP, 0, len(ips))
+	for _, ip := range ips {
+		result = append(result, &nexodusv1.TunnelIP{Address: ip.Address, Cidr: ip.CIDR})
+	}
+	return result
+}
+
+func fromTunnelIPs(ips []*nexodusv1.TunnelIP) []models.TunnelIP {
+	var result []models.TunnelIP
+	for _, ip := range ips {
+		result = append(result, models.TunnelIP{Address: ip.GetAddress(), CIDR: ip.GetCidr()})
+	}
+	return result
+}
+
+func toEndpoints(endpoints []models.Endpoint) []*nexodusv1.Endpoint {
+	result := make([]*nexodusv1.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result = append(result, &nexodusv1.Endpoint{Source: endpoint.Source, Address: endpoint.Address, Priority: int32(endpoint.Priority)})
+	}
+	return result
+}
+
+func fromEndpoints(endpoints []*nexodusv1.Endpoint) []models.Endpoint {
+	var result []models.Endpoint
+	for _, endpoint := range endpoints {
+		result = append(result, models.Endpoint{Source: endpoint.GetSource(), Address: endpoint.GetAddress(), Priority: int(endpoint.GetPriority())})
+	}
+	return result
+}
+
+func toDevice(device *models.Device) *nexodusv1.Device {
+	return &nexodusv1.Device{
+		Id:               device.ID.String(),
+		OwnerId:          device.OwnerID.String(),
+		VpcId:            device.VpcID.String(),
+		PublicKey:        device.PublicKey,
+		AllowedIps:       device.AllowedIPs,
+		Ipv4TunnelIps:    toTunnelIPs(device.IPv4TunnelIPs),
+		Ipv6TunnelIps:    toTunnelIPs(device.IPv6TunnelIPs),
+		AdvertiseCidrs:   device.AdvertiseCidrs,
+		Relay:            device.Relay,
+		SymmetricNat:     device.SymmetricNat,
+		RelayId:          device.RelayID.String(),
+		NatType:          device.NatType,
+		NatHairpin:       device.NatHairpin,
+		Hostname:         device.Hostname,
+		DnsName:          device.DnsName,
+		DisplayName:      device.DisplayName,
+		Labels:           device.Labels,
+		Os:               device.Os,
+		Endpoints:        toEndpoints(device.Endpoints),
+		EndpointIpv6:     device.EndpointIPv6,
+		ListenPort:       int32(device.ListenPort),
+		Revision:         device.Revision,
+		SecurityGroupId:  device.SecurityGroupId.String(),
+		SecurityGroupIds: device.SecurityGroupIds,
+		Online:           device.Online,
+		OnlineAt:         toTimestamp(device.OnlineAt),
+		LastSeen:         toTimestamp(device.LastSeen),
+		BearerToken:      device.BearerToken,
+		Pending:          device.Pending,
+		ExpiredAt:        toTimestamp(device.ExpiredAt),
+		Unmanaged:        device.Unmanaged,
+	}
+}
+
+func fromCreateDeviceRequest(in *nexodusv1.CreateDeviceRequest) (models.AddDevice, error) {
+	vpcId, err := parseID("vpc_id", in.GetVpcId())
+	if err != nil {
+		return models.AddDevice{}, err
+	}
+	securityGroupId, err := parseID("security_group_id", in.GetSecurityGroupId())
+	if err != nil {
+		return models.AddDevice{}, err
+	}
+	return models.AddDevice{
+		VpcID:           vpcId,
+		PublicKey:       in.GetPublicKey(),
+		AdvertiseCidrs:  in.GetAdvertiseCidrs(),
+		IPv4TunnelIPs:   fromTunnelIPs(in.GetIpv4TunnelIps()),
+		Relay:           in.GetRelay(),
+		SymmetricNat:    in.GetSymmetricNat(),
+		NatType:         in.GetNatType(),
+		NatHairpin:      in.GetNatHairpin(),
+		Hostname:        in.GetHostname(),
+		DnsName:         in.GetDnsName(),
+		Endpoints:       fromEndpoints(in.GetEndpoints()),
+		EndpointIPv6:    in.GetEndpointIpv6(),
+		Os:              in.GetOs(),
+		SecurityGroupId: securityGroupId,
+		ListenPort:      int(in.GetListenPort()),
+		DisableIPv6:     in.GetDisableIpv6(),
+		Unmanaged:       in.GetUnmanaged(),
+	}, nil
+}
+
+func fromUpdateDeviceRequest(in *nexodusv1.UpdateDeviceRequest) (models.UpdateDevice, error) {
+	update := models.UpdateDevice{
+		SymmetricNat: in.SymmetricNat,
+		NatType:      in.NatType,
+		NatHairpin:   in.NatHairpin,
+		Hostname:     in.GetHostname(),
+		DnsName:      in.GetDnsName(),
+		DisplayName:  in.DisplayName,
+		Labels:       in.GetLabels(),
+		Endpoints:    fromEndpoints(in.GetEndpoints()),
+		EndpointIPv6: in.EndpointIpv6,
+		Relay:        in.Relay,
+	}
+	if in.VpcId != nil {
+		vpcId, err := parseID("vpc_id", in.GetVpcId())
+		if err != nil {
+			return models.UpdateDevice{}, err
+		}
+		update.VpcID = &vpcId
+	}
+	if in.AdvertiseCidrs != nil {
+		update.AdvertiseCidrs = append([]string{}, in.AdvertiseCidrs.GetValues()...)
+	}
+	if in.SecurityGroupId != nil {
+		securityGroupId, err := parseID("security_group_id", in.GetSecurityGroupId())
+		if err != nil {
+			return models.UpdateDevice{}, err
+		}
+		update.SecurityGroupId = &securityGroupId
+	}
+	if in.ListenPort != nil {
+		listenPort := int(in.GetListenPort())
+		update.ListenPort = &listenPort
+	}
+	return update, nil
+}
+
+func fromWatches(watches []*nexodusv1.Watch) []models.Watch {
+	var result []models.Watch
+	for _, watch := range watches {
+		w := models.Watch{
+			Kind:       watch.GetKind(),
+			GtRevision: watch.GetGtRevision(),
+			AtTail:     watch.GetAtTail(),
+		}
+		if watch.Options != nil {
+			w.Options = watch.Options.AsMap()
+		}
+		result = append(result, w)
+	}
+	return result
+}
+
+func toSecurityRules(rules []models.SecurityRule) []*nexodusv1.SecurityRule {
+	result := make([]*nexodusv1.SecurityRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, &nexodusv1.SecurityRule{
+			IpProtocol: rule.IpProtocol,
+			FromPort:   rule.FromPort,
+			ToPort:     rule.ToPort,
+			IpRanges:   rule.IpRanges,
+		})
+	}
+	return result
+}
+
+// toWatchEvent converts an event of the event stream of the handlers, whose value is the resource of its kind
+func toWatchEvent(event models.WatchEvent) (*nexodusv1.WatchEvent, error) {
+	result := &nexodusv1.WatchEvent{Kind: event.Kind, Type: event.Type}
+	switch value := event.Value.(type) {
+	case nil:
+	case *models.Device:
+		result.Value = &nexodusv1.WatchEvent_Device{Device: toDevice(value)}
+	case *models.Site:
+		result.Value = &nexodusv1.WatchEvent_Site{Site: &nexodusv1.Site{
+			Id:          value.ID.String(),
+			Revision:    value.Revision,
+			OwnerId:     value.OwnerID.String(),
+			VpcId:       value.VpcID.String(),
+			BearerToken: value.BearerToken,
+			Hostname:    value.Hostname,
+			Os:          value.Os,
+			Name:        value.Name,
+			Platform:    value.Platform,
+			PublicKey:   value.PublicKey,
+			LinkSecret:  value.LinkSecret,
+		}}
+	case *models.SecurityGroup:
+		result.Value = &nexodusv1.WatchEvent_SecurityGroup{SecurityGroup: &nexodusv1.SecurityGroup{
+			Id:            value.ID.String(),
+			Description:   value.Description,
+			VpcId:         value.VpcId.String(),
+			InboundRules:  toSecurityRules(value.InboundRules),
+			OutboundRules: toSecurityRules(value.OutboundRules),
+			Revision:      value.Revision,
+		}}
+	case *models.Service:
+		result.Value = &nexodusv1.WatchEvent_Service{Service: &nexodusv1.Service{
+			Id:          value.ID.String(),
+			DeviceId:    value.DeviceID.String(),
+			Name:        value.Name,
+			Port:        int32(value.Port),
+			Protocol:    value.Protocol,
+			Description: value.Description,
+			Revision:    value.Revision,
+		}}
+	case *models.DNSRecord:
+		result.Value = &nexodusv1.WatchEvent_DnsRecord{DnsRecord: &nexodusv1.DNSRecord{
+			Id:             value.ID.String(),
+			OrganizationId: value.OrganizationID.String(),
+			Name:           value.Name,
+			Type:           value.Type,
+			Value:          value.Value,
+			Description:    value.Description,
+			Revision:       value.Revision,
+		}}
+	case *models.DeviceMetadata:
+		metadata, err := structpb.NewValue(value.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of the metadata %s of device %s: %w", value.Key, value.DeviceID, err)
+		}
+		result.Value = &nexodusv1.WatchEvent_DeviceMetadata{DeviceMetadata: &nexodusv1.DeviceMetadata{
+			DeviceId: value.DeviceID.String(),
+			Key:      value.Key,
+			Value:    metadata,
+			Revision: value.Revision,
+		}}
+	case *models.VPC:
+		result.Value = &nexodusv1.WatchEvent_Vpc{Vpc: &nexodusv1.VPC{
+			Id:             value.ID.String(),
+			OrganizationId: value.OrganizationID.String(),
+			Description:    value.Description,
+			PrivateCidr:    value.PrivateCidr,
+			Ipv4Cidr:       value.Ipv4Cidr,
+			Ipv6Cidr:       value.Ipv6Cidr,
+			CaCertificates: value.CaCertificates,
+			Revision:       value.Revision,
+		}}
+	default:
+		return nil, fmt.Errorf("unsupported value of a %s event: %T", event.Kind, event.Value)
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	nexodusv1 "github.com/nexodus-io/nexodus/internal/grpcapi/proto/nexodus/v1"
+	"github.com/nexodus-io/nexodus/internal/handlers"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Handlers serve the RPCs, they are the handlers of the REST API that return their result instead of
+// responding with it, implemented by handlers.API
+type Handlers interface {
+	FindDevice(c *gin.Context, id uuid.UUID) (models.Device, error)
+	AddDevice(c *gin.Context, request models.AddDevice) (models.Device, error)
+	PatchDevice(c *gin.Context, id uuid.UUID, request models.UpdateDevice) (models.Device, error)
+	ListVPCDevices(c *gin.Context, vpcId uuid.UUID, labels map[string]string, paginate func(db *gorm.DB) *gorm.DB) ([]*models.Device, error)
+	StreamEvents(c *gin.Context, vpcId uuid.UUID, publicKey string, request []models.Watch, start func(), send func(models.WatchEvent) error) error
+}
+
+var _ Handlers = &handlers.API{}
+
+// Server serves the gRPC API of the device sync and the events
+type Server struct {
+	nexodusv1.UnimplementedNexodusServer
+	handlers Handlers
+	router   *gin.Engine
+	logger   *zap.SugaredLogger
+}
+
+// rpcKey is the context key of the function serving an RPC once its request passed the middleware
+type rpcKey struct{}
+
+// NewServer returns a Server whose RPCs are served by the handlers. The request of each RPC is authenticated,
+// authorized and rate limited by the middleware of the REST API as a request of the matching REST endpoint, so
+// that the policies of the endpoints apply to the RPCs.
+func NewServer(logger *zap.SugaredLogger, h Handlers, middleware ...gin.HandlerFunc) *Server {
+	s := &Server{
+		handlers: h,
+		router:   gin.New(),
+		logger:   logger,
+	}
+	s.router.Use(gin.CustomRecovery(func(c *gin.Context, err any) {
+		util.WithTrace(c.Request.Context(), s.logger).Errorw("panic serving a grpc request", "error", err)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	s.router.Use(middleware...)
+	serve := func(c *gin.Context) {
+		c.Request.Context().Value(rpcKey{}).(func(*gin.Context))(c)
+	}
+	s.router.GET("/api/v1/devices/:id", serve)
+	s.router.PATCH("/api/v1/devices/:id", serve)
+	s.router.POST("/api/v1/devices", serve)
+	s.router.GET("/api/v1/vpcs/:id/devices", serve)
+	s.router.POST("/api/v1/vpcs/:id/events", serve)
+	return s
+}
+
+// Register registers the service with a gRPC server
+func (s *Server) Register(server *grpc.Server) {
+	nexodusv1.RegisterNexodusServer(server, s)
+}
+
+// call serves an RPC with rpc once the middleware accepted the request of the REST endpoint at path, the
+// request is authenticated with the authorization metadata of the call
+func (s *Server) call(ctx context.Context, method string, path string, rpc func(c *gin.Context) error) error {
+	var err error
+	called := false
+	ctx = context.WithValue(ctx, rpcKey{}, func(c *gin.Context) {
+		called = true
+		err = rpc(c)
+	})
+	req, reqErr := http.NewRequestWithContext(ctx, method, path, http.NoBody)
+	if reqErr != nil {
+		return status.Error(codes.Internal, reqErr.Error())
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{"authorization", "user-agent"} {
+			if values := md.Get(key); len(values) > 0 {
+				req.Header.Set(key, values[0])
+			}
+		}
+	}
+
+	// the handlers return their result, only the middleware responds to the request
+	res := httptest.NewRecorder()
+	s.router.ServeHTTP(res, req)
+	if !called {
+		return responseError(res.Code, res.Body.Bytes())
+	}
+	return s.statusError(ctx, err)
+}
+
+func (s *Server) GetDevice(ctx context.Context, in *nexodusv1.GetDeviceRequest) (*nexodusv1.Device, error) {
+	id, err := parseID("id", in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	var device models.Device
+	err = s.call(ctx, http.MethodGet, fmt.Sprintf("/api/v1/devices/%s", id), func(c *gin.Context) (err error) {
+		device, err = s.handlers.FindDevice(c, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDevice(&device), nil
+}
+
+func (s *Server) CreateDevice(ctx context.Context, in *nexodusv1.CreateDeviceRequest) (*nexodusv1.Device, error) {
+	request, err := fromCreateDeviceRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	var device models.Device
+	err = s.call(ctx, http.MethodPost, "/api/v1/devices", func(c *gin.Context) (err error) {
+		device, err = s.handlers.AddDevice(c, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDevice(&device), nil
+}
+
+func (s *Server) UpdateDevice(ctx context.Context, in *nexodusv1.UpdateDeviceRequest) (*nexodusv1.Device, error) {
+	id, err := parseID("id", in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	request, err := fromUpdateDeviceRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	var device models.Device
+	err = s.call(ctx, http.MethodPatch, fmt.Sprintf("/api/v1/devices/%s", id), func(c *gin.Context) (err error) {
+		device, err = s.handlers.PatchDevice(c, id, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDevice(&device), nil
+}
+
+func (s *Server) ListVPCDevices(ctx context.Context, in *nexodusv1.ListVPCDevicesRequest) (*nexodusv1.ListVPCDevicesResponse, error) {
+	vpcId, err := parseID("vpc_id", in.GetVpcId())
+	if err != nil {
+		return nil, err
+	}
+	var devices []*models.Device
+	err = s.call(ctx, http.MethodGet, fmt.Sprintf("/api/v1/vpcs/%s/devices", vpcId), func(c *gin.Context) (err error) {
+		devices, err = s.handlers.ListVPCDevices(c, vpcId, in.GetLabels(), nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &nexodusv1.ListVPCDevicesResponse{}
+	for _, device := range devices {
+		result.Devices = append(result.Devices, toDevice(device))
+	}
+	return result, nil
+}
+
+// errStreamFailed is the error of the stream once an error event ended it
+var errStreamFailed = errors.New("the event stream failed")
+
+func (s *Server) WatchEvents(in *nexodusv1.WatchEventsRequest, stream nexodusv1.Nexodus_WatchEventsServer) error {
+	vpcId, err := parseID("vpc_id", in.GetVpcId())
+	if err != nil {
+		return err
+	}
+	return s.call(stream.Context(), http.MethodPost, fmt.Sprintf("/api/v1/vpcs/%s/events", vpcId), func(c *gin.Context) error {
+		return s.handlers.StreamEvents(c, vpcId, in.GetPublicKey(), fromWatches(in.GetWatches()), func() {
+			_ = stream.SendHeader(metadata.MD{})
+		}, func(event models.WatchEvent) error {
+			if event.Type == "error" {
+				util.WithTrace(c.Request.Context(), s.logger).Errorw("event stream failed", "error", event.Value)
+				return errStreamFailed
+			}
+			result, err := toWatchEvent(event)
+			if err != nil {
+				return err
+			}
+			return stream.Send(result)
+		})
+	})
+}
+
+// statusError converts the error of a handler to a gRPC status
+func (s *Server) statusError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiResponseError *handlers.ApiResponseError
+	if errors.As(err, &apiResponseError) {
+		body, _ := json.Marshal(apiResponseError.Body)
+		return responseError(apiResponseError.Status, body)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if !errors.Is(err, errStreamFailed) {
+		util.WithTrace(ctx, s.logger).Errorw("internal server error", "error", err)
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// responseError converts the error response of the REST API to a gRPC status
+func responseError(code int, body []byte) error {
+	var apiError models.ValidationError
+	if err := json.Unmarshal(body, &apiError); err != nil || apiError.Error == "" {
+		apiError.Error = http.StatusText(code)
+	}
+	message := apiError.Error
+	if apiError.Field != "" {
+		message = fmt.Sprintf("%s: %s %s", message, apiError.Field, apiError.Reason)
+	}
+	return status.Error(statusCode(code), message)
+}
+
+// statusCode returns the gRPC status code of an HTTP status code
+func statusCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusMethodNotAllowed, http.StatusUpgradeRequired:
+		return codes.FailedPrecondition
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,242 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	nexodusv1 "github.com/nexodus-io/nexodus/internal/grpcapi/proto/nexodus/v1"
+	"github.com/nexodus-io/nexodus/internal/handlers"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gorm.io/gorm"
+)
+
+// fakeHandlers serve the devices of a single vpc
+type fakeHandlers struct {
+	userId  uuid.UUID
+	vpcId   uuid.UUID
+	device  models.Device
+	updates []models.UpdateDevice
+	paths   []string
+}
+
+func (h *fakeHandlers) FindDevice(c *gin.Context, id uuid.UUID) (models.Device, error) {
+	h.paths = append(h.paths, c.FullPath())
+	if c.MustGet(gin.AuthUserKey) != h.userId || id != h.device.ID {
+		return models.Device{}, handlers.NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+	}
+	return h.device, nil
+}
+
+func (h *fakeHandlers) AddDevice(c *gin.Context, request models.AddDevice) (models.Device, error) {
+	h.paths = append(h.paths, c.FullPath())
+	if request.PublicKey == "" {
+		return models.Device{}, handlers.NewApiResponseError(http.StatusBadRequest, models.NewFieldNotPresentError("public_key"))
+	}
+	return models.Device{Base: models.Base{ID: uuid.New()}, VpcID: request.VpcID, PublicKey: request.PublicKey, ListenPort: request.ListenPort}, nil
+}
+
+func (h *fakeHandlers) PatchDevice(c *gin.Context, id uuid.UUID, request models.UpdateDevice) (models.Device, error) {
+	h.paths = append(h.paths, c.FullPath())
+	h.updates = append(h.updates, request)
+	device := h.device
+	if request.Relay != nil {
+		device.Relay = *request.Relay
+	}
+	if request.AdvertiseCidrs != nil {
+		device.AdvertiseCidrs = request.AdvertiseCidrs
+	}
+	return device, nil
+}
+
+func (h *fakeHandlers) ListVPCDevices(c *gin.Context, vpcId uuid.UUID, labels map[string]string, _ func(db *gorm.DB) *gorm.DB) ([]*models.Device, error) {
+	h.paths = append(h.paths, c.FullPath())
+	if vpcId != h.vpcId {
+		return nil, handlers.NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
+	}
+	if labels["env"] != h.device.Labels["env"] {
+		return nil, nil
+	}
+	return []*models.Device{&h.device}, nil
+}
+
+func (h *fakeHandlers) StreamEvents(c *gin.Context, vpcId uuid.UUID, publicKey string, request []models.Watch, start func(), send func(models.WatchEvent) error) error {
+	h.paths = append(h.paths, c.FullPath())
+	for i, watch := range request {
+		if watch.Kind != "device" && watch.Kind != "device-metadata" {
+			return handlers.NewApiResponseError(http.StatusBadRequest, models.NewInvalidField(fmt.Sprintf("request[%d].kind", i)))
+		}
+	}
+	start()
+	for _, event := range []models.WatchEvent{
+		{Kind: "device", Type: "change", Value: &h.device},
+		{Kind: "device-metadata", Type: "change", Value: &models.DeviceMetadata{DeviceID: h.device.ID, Key: "tcp", Value: map[string]interface{}{"port": float64(80)}}},
+		{Kind: "device", Type: "tail"},
+	} {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+	<-c.Request.Context().Done()
+	return nil
+}
+
+func newTestClient(t *testing.T, h Handlers) nexodusv1.NexodusClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	// authenticates the bearer token of a single user, like the middleware of the REST API
+	auth := func(c *gin.Context) {
+		if c.GetHeader("authorization") != "Bearer token" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.NewBaseError("invalid token"))
+			return
+		}
+		c.Set(gin.AuthUserKey, h.(*fakeHandlers).userId)
+		c.Next()
+	}
+	NewServer(zaptest.NewLogger(t).Sugar(), h, auth).Register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return nexodusv1.NewNexodusClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	require := require.New(t)
+	gin.SetMode(gin.TestMode)
+	h := &fakeHandlers{
+		userId: uuid.New(),
+		vpcId:  uuid.New(),
+	}
+	h.device = models.Device{
+		Base:          models.Base{ID: uuid.New()},
+		VpcID:         h.vpcId,
+		Hostname:      "node1",
+		Labels:        map[string]string{"env": "prod"},
+		IPv4TunnelIPs: []models.TunnelIP{{Address: "100.64.0.1", CIDR: "100.64.0.0/10"}},
+		Endpoints:     []models.Endpoint{{Source: "stun", Address: "192.0.2.1:51820"}},
+	}
+
+	client := newTestClient(t, h)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	device, err := client.GetDevice(ctx, &nexodusv1.GetDeviceRequest{Id: h.device.ID.String()})
+	require.NoError(err)
+	require.Equal("node1", device.Hostname)
+	require.Equal(h.vpcId.String(), device.VpcId)
+	require.Equal("100.64.0.1", device.Ipv4TunnelIps[0].Address)
+	require.Equal("192.0.2.1:51820", device.Endpoints[0].Address)
+	require.Equal("prod", device.Labels["env"])
+
+	// the errors of the middleware and of the handlers are mapped to status codes
+	_, err = client.GetDevice(context.Background(), &nexodusv1.GetDeviceRequest{Id: h.device.ID.String()})
+	require.Equal(codes.Unauthenticated, status.Code(err))
+	require.Equal("invalid token", status.Convert(err).Message())
+	_, err = client.GetDevice(ctx, &nexodusv1.GetDeviceRequest{Id: uuid.New().String()})
+	require.Equal(codes.NotFound, status.Code(err))
+	_, err = client.GetDevice(ctx, &nexodusv1.GetDeviceRequest{Id: "node1"})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
+	created, err := client.CreateDevice(ctx, &nexodusv1.CreateDeviceRequest{VpcId: h.vpcId.String(), PublicKey: "key", ListenPort: 51820})
+	require.NoError(err)
+	require.Equal("key", created.PublicKey)
+	require.Equal(int32(51820), created.ListenPort)
+	_, err = client.CreateDevice(ctx, &nexodusv1.CreateDeviceRequest{VpcId: h.vpcId.String()})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+	require.Contains(status.Convert(err).Message(), "public_key")
+
+	// the fields that are not set are not updated
+	relay := true
+	device, err = client.UpdateDevice(ctx, &nexodusv1.UpdateDeviceRequest{Id: h.device.ID.String(), Relay: &relay})
+	require.NoError(err)
+	require.True(device.Relay)
+	require.Nil(h.updates[0].AdvertiseCidrs)
+	require.Nil(h.updates[0].SymmetricNat)
+	device, err = client.UpdateDevice(ctx, &nexodusv1.UpdateDeviceRequest{Id: h.device.ID.String(), AdvertiseCidrs: &nexodusv1.StringList{}})
+	require.NoError(err)
+	require.NotNil(h.updates[1].AdvertiseCidrs)
+	require.Empty(device.AdvertiseCidrs)
+
+	devices, err := client.ListVPCDevices(ctx, &nexodusv1.ListVPCDevicesRequest{VpcId: h.vpcId.String(), Labels: map[string]string{"env": "prod"}})
+	require.NoError(err)
+	require.Len(devices.Devices, 1)
+	devices, err = client.ListVPCDevices(ctx, &nexodusv1.ListVPCDevicesRequest{VpcId: h.vpcId.String(), Labels: map[string]string{"env": "dev"}})
+	require.NoError(err)
+	require.Empty(devices.Devices)
+
+	_, err = receiveEvent(ctx, client, &nexodusv1.WatchEventsRequest{VpcId: h.vpcId.String(), Watches: []*nexodusv1.Watch{{Kind: "site"}}})
+	require.Equal(codes.InvalidArgument, status.Code(err))
+
+	options, err := structpb.NewStruct(map[string]interface{}{"prefix": []interface{}{"tcp"}})
+	require.NoError(err)
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.WatchEvents(streamCtx, &nexodusv1.WatchEventsRequest{
+		VpcId:     h.vpcId.String(),
+		PublicKey: "key",
+		Watches: []*nexodusv1.Watch{
+			{Kind: "device"},
+			{Kind: "device-metadata", Options: options},
+		},
+	})
+	require.NoError(err)
+	event, err := stream.Recv()
+	require.NoError(err)
+	require.Equal("change", event.Type)
+	require.Equal(h.device.ID.String(), event.GetDevice().Id)
+	event, err = stream.Recv()
+	require.NoError(err)
+	require.Equal("device-metadata", event.Kind)
+	require.Equal(float64(80), event.GetDeviceMetadata().Value.GetStructValue().Fields["port"].GetNumberValue())
+	event, err = stream.Recv()
+	require.NoError(err)
+	require.Equal("tail", event.Type)
+	require.Nil(event.Value)
+	cancel()
+	_, err = stream.Recv()
+	require.Equal(codes.Canceled, status.Code(err))
+
+	// the RPCs are served as requests of the REST endpoints
+	require.Equal([]string{
+		"/api/v1/devices/:id",
+		"/api/v1/devices/:id",
+		"/api/v1/devices",
+		"/api/v1/devices",
+		"/api/v1/devices/:id",
+		"/api/v1/devices/:id",
+		"/api/v1/vpcs/:id/devices",
+		"/api/v1/vpcs/:id/devices",
+		"/api/v1/vpcs/:id/events",
+		"/api/v1/vpcs/:id/events",
+	}, h.paths)
+}
+
+// receiveEvent opens an event stream and receives its first event
+func receiveEvent(ctx context.Context, client nexodusv1.NexodusClient, request *nexodusv1.WatchEventsRequest) (*nexodusv1.WatchEvent, error) {
+	stream, err := client.WatchEvents(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
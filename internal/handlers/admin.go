@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// The admin api is reserved to the controller admins, the users with the controller-admin role of the identity
+// provider. It reads and deletes the resources of every organization, and every request of it is audited.
+
+// impersonationExpiry is the lifetime of the api keys created to impersonate a user
+const impersonationExpiry = time.Hour
+
+// recordAdminListEvent records that a controller admin listed the resources of a type across the organizations, the
+// events are not recorded in the audit log of an organization.
+func (api *API) recordAdminListEvent(c *gin.Context, db *gorm.DB, resourceType string) error {
+	return api.recordAuditEvent(c, db, uuid.Nil, models.AuditActionList, resourceType, uuid.Nil, nil, map[string]interface{}{
+		"query": c.Request.URL.RawQuery,
+	})
+}
+
+// AdminListOrganizations lists the organizations of all users
+// @Summary      List All Organizations
+// @Description  Lists the organizations of all users, reserved to the controller admins
+// @Id 			 AdminListOrganizations
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 sort    query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit   query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor  query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Success      200  {object}  []models.Organization
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/organizations [get]
+func (api *API) AdminListOrganizations(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminListOrganizations")
+	defer span.End()
+
+	if err := api.recordAdminListEvent(c, api.db.WithContext(ctx), "organization"); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	orgs := []models.Organization{}
+	db := FilterAndPaginate(api.readReplicaDB(ctx), &models.Organization{}, c, "name")
+	if res := db.Find(&orgs); res.Error != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(res.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	if err := SetNextCursor(c, orgs); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// AdminListDevices lists the devices of all users
+// @Summary      List All Devices
+// @Description  Lists the devices of all users, reserved to the controller admins
+// @Id 			 AdminListDevices
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 organization_id query  string false "only list the devices of this organization"
+// @Param		 sort            query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit           query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor          query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Success      200  {object}  []models.Device
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/devices [get]
+func (api *API) AdminListDevices(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminListDevices")
+	defer span.End()
+
+	db := api.readReplicaDB(ctx)
+	if value := c.Query("organization_id"); value != "" {
+		orgId, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("organization_id", "must be a uuid"))
+			return
+		}
+		db = db.Where("organization_id = ?", orgId)
+	}
+
+	if err := api.recordAdminListEvent(c, api.db.WithContext(ctx), "device"); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	devices := []models.Device{}
+	db = FilterAndPaginate(db, &models.Device{}, c, "hostname")
+	if res := db.Find(&devices); res.Error != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(res.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	if err := SetNextCursor(c, devices); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	for i := range devices {
+		hideDeviceBearerToken(&devices[i], nil)
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+// AdminListUsers lists all users
+// @Summary      List All Users
+// @Description  Lists all users, reserved to the controller admins
+// @Id 			 AdminListUsers
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 sort    query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit   query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor  query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Success      200  {object}  []models.User
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/users [get]
+func (api *API) AdminListUsers(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminListUsers")
+	defer span.End()
+
+	if err := api.recordAdminListEvent(c, api.db.WithContext(ctx), "user"); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	users := []models.User{}
+	db := FilterAndPaginate(api.readReplicaDB(ctx), &models.User{}, c, "user_name")
+	if res := db.Find(&users); res.Error != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(res.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	if err := SetNextCursor(c, users); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// AdminListAuditEvents lists the audit events of all organizations
+// @Summary      List All Audit Events
+// @Description  Lists the audit events of all organizations along with the requests of the controller admins, newest first, reserved to the controller admins
+// @Id 			 AdminListAuditEvents
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 organization_id query  string false "only list events of this organization"
+// @Param		 actor_id        query  string false "only list events made by this user"
+// @Param		 action          query  string false "only list events of this action"
+// @Param		 limit           query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor          query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Success      200  {object}  []models.AuditEvent
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/audit [get]
+func (api *API) AdminListAuditEvents(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminListAuditEvents")
+	defer span.End()
+
+	db := api.db.WithContext(ctx)
+	for _, param := range []string{"organization_id", "actor_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError(param, "must be a uuid"))
+			return
+		}
+		db = db.Where(param+" = ?", value)
+	}
+	if action := c.Query("action"); action != "" {
+		db = db.Where("action = ?", action)
+	}
+
+	if err := api.recordAdminListEvent(c, api.db.WithContext(ctx), "audit-event"); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	events := []models.AuditEvent{}
+	db = FilterAndPaginate(db, &models.AuditEvent{}, c, "created_at DESC")
+	if res := db.Find(&events); res.Error != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(res.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	if err := SetNextCursor(c, events); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// AdminDeleteDevice deletes a device of any user
+// @Summary      Force Delete Device
+// @Description  Deletes a device of any user, reserved to the controller admins
+// @Id 			 AdminDeleteDevice
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "Device ID"
+// @Success      200  {object}  models.Device
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/devices/{id} [delete]
+func (api *API) AdminDeleteDevice(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminDeleteDevice", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var devices []models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.Find(&devices, "id = ?", deviceId); res.Error != nil {
+			return res.Error
+		}
+		if len(devices) == 0 {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+		}
+		return api.adminDeleteDevices(c, tx, devices)
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.releaseDeletedDevices(ctx, devices)
+
+	hideDeviceBearerToken(&devices[0], nil)
+	c.JSON(http.StatusOK, devices[0])
+}
+
+// AdminDeleteOrganization deletes an organization of any user along with its devices
+// @Summary      Force Delete Organization
+// @Description  Deletes an organization of any user along with its devices, reserved to the controller admins. The default organization of a user is deleted with the user.
+// @Id 			 AdminDeleteOrganization
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "Organization ID"
+// @Success      200  {object}  models.Organization
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/organizations/{id} [delete]
+func (api *API) AdminDeleteOrganization(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminDeleteOrganization", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var org models.Organization
+	var devices []models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.First(&org, "id = ?", orgId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+			}
+			return res.Error
+		}
+		var count int64
+		if res := tx.Model(&models.User{}).Where("id = ?", orgId).Count(&count); res.Error != nil {
+			return res.Error
+		}
+		if count > 0 {
+			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("default organization cannot be deleted, delete its user instead"))
+		}
+
+		if res := tx.Find(&devices, "organization_id = ?", orgId); res.Error != nil {
+			return res.Error
+		}
+		if err := api.adminDeleteDevices(c, tx, devices); err != nil {
+			return err
+		}
+		if err := deleteOrganization(tx, orgId); err != nil {
+			return err
+		}
+		return api.recordAuditEvent(c, tx, orgId, models.AuditActionDelete, "organization", orgId, auditState(org), nil)
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.releaseDeletedDevices(ctx, devices)
+
+	c.JSON(http.StatusOK, org)
+}
+
+// AdminDeleteUser deletes any user along with its devices
+// @Summary      Force Delete User
+// @Description  Deletes any user along with its devices and the organizations it is the only owner of, reserved to the controller admins
+// @Id 			 AdminDeleteUser
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "User ID"
+// @Success      200  {object}  models.User
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/users/{id} [delete]
+func (api *API) AdminDeleteUser(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminDeleteUser", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var user models.User
+	var devices []models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.First(&user, "id = ?", userId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("user"))
+			}
+			return res.Error
+		}
+		if res := tx.Find(&devices, "owner_id = ?", userId); res.Error != nil {
+			return res.Error
+		}
+		if err := api.adminDeleteDevices(c, tx, devices); err != nil {
+			return err
+		}
+		before := auditState(user)
+		if err := api.deleteUser(ctx, tx, &user); err != nil {
+			return err
+		}
+		return api.recordAuditEvent(c, tx, user.ID, models.AuditActionDelete, "user", user.ID, before, nil)
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.releaseDeletedDevices(ctx, devices)
+
+	c.JSON(http.StatusOK, user)
+}
+
+// AdminImpersonateUser creates an api key acting as a user
+// @Summary      Impersonate User
+// @Description  Creates a short lived api key acting as a user with all the api key scopes, reserved to the controller admins. The impersonation is recorded in the audit log of the default organization of the user.
+// @Id 			 AdminImpersonateUser
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                       true "User ID"
+// @Param        Reason  body      models.AdminImpersonateUser  true "Impersonation"
+// @Success      201  {object}  models.APIKey
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/users/{id}/impersonate [post]
+func (api *API) AdminImpersonateUser(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminImpersonateUser", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var request models.AdminImpersonateUser
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.Reason == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("reason"))
+		return
+	}
+
+	token, err := generateAPIKeyToken()
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	expiresAt := time.Now().Add(impersonationExpiry)
+	key := models.APIKey{
+		UserID:      userId,
+		Description: fmt.Sprintf("impersonated by %s: %s", api.GetCurrentUserID(c), request.Reason),
+		Scopes:      allAPIKeyScopes,
+		ExpiresAt:   &expiresAt,
+		TokenHash:   hashClientSecret(token),
+	}
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var user models.User
+		if res := tx.First(&user, "id = ?", userId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("user"))
+			}
+			return res.Error
+		}
+		if res := tx.Create(&key); res.Error != nil {
+			return res.Error
+		}
+		// recorded in the default organization of the user, so the user can tell it was impersonated
+		return api.recordAuditEvent(c, tx, user.ID, models.AuditActionImpersonate, "user", user.ID, nil, map[string]interface{}{
+			"api_key_id": key.ID.String(),
+			"reason":     request.Reason,
+			"expires_at": expiresAt,
+		})
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	key.Token = token
+	c.JSON(http.StatusCreated, key)
+}
+
+// adminDeleteDevices deletes the devices the way DeleteDevice does, their IPAM allocations are released by
+// releaseDeletedDevices once the transaction is committed.
+func (api *API) adminDeleteDevices(c *gin.Context, tx *gorm.DB, devices []models.Device) error {
+	for i := range devices {
+		before := auditState(devices[i])
+		if err := api.deleteDevice(c.Request.Context(), tx, &devices[i]); err != nil {
+			return err
+		}
+		if err := api.recordAuditEvent(c, tx, devices[i].OrganizationID, models.AuditActionDelete, "device", devices[i].ID, before, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseDeletedDevices notifies the vpcs of the deleted devices and releases their IPAM allocations, the reclaim job
+// releases the allocations that fail to release here.
+func (api *API) releaseDeletedDevices(ctx context.Context, devices []models.Device) {
+	api.notifyDeviceVpcs(devices)
+	db := api.db.WithContext(ctx)
+	for _, device := range devices {
+		api.signalBus.Notify(fmt.Sprintf("/services/vpc=%s", device.VpcID.String()))
+		d, err := staleDeviceLeases(db, device, staleLeaseDeleted)
+		if err != nil {
+			api.logger.Errorf("failed to find the ipam leases of deleted device %s: %v", device.ID, err)
+			continue
+		}
+		api.releaseStaleLeases(ctx, db, d)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+func (suite *HandlerTestSuite) TestAdmin() {
+	require := suite.Require()
+
+	code, body := suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "adminpubkey",
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+	// the device of another user
+	require.NoError(suite.api.db.Model(&models.Device{}).Where("id = ?", device.ID).Update("owner_id", suite.testUser2ID).Error)
+
+	code, body = suite.serve(http.MethodGet, "/", "/?organization_id="+suite.testUserID.String(), suite.api.AdminListDevices, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var devices []models.Device
+	require.NoError(json.Unmarshal(body, &devices))
+	require.Len(devices, 1)
+	require.Equal(device.ID, devices[0].ID)
+	require.Empty(devices[0].BearerToken)
+
+	code, body = suite.serve(http.MethodGet, "/", "/", suite.api.AdminListUsers, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var users []models.User
+	require.NoError(json.Unmarshal(body, &users))
+	require.Len(users, 2)
+
+	code, body = suite.serve(http.MethodGet, "/", "/", suite.api.AdminListOrganizations, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var orgs []models.Organization
+	require.NoError(json.Unmarshal(body, &orgs))
+	require.Len(orgs, 2)
+
+	code, _ = suite.serve(http.MethodPost, "/:id", "/"+suite.testUser2ID.String(), suite.api.AdminImpersonateUser, models.AdminImpersonateUser{})
+	require.Equal(http.StatusBadRequest, code)
+	code, _ = suite.serve(http.MethodPost, "/:id", "/"+uuid.New().String(), suite.api.AdminImpersonateUser, models.AdminImpersonateUser{Reason: "support"})
+	require.Equal(http.StatusNotFound, code)
+	code, body = suite.serve(http.MethodPost, "/:id", "/"+suite.testUser2ID.String(), suite.api.AdminImpersonateUser, models.AdminImpersonateUser{Reason: "support"})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var key models.APIKey
+	require.NoError(json.Unmarshal(body, &key))
+	require.Equal(suite.testUser2ID, key.UserID)
+	require.NotEmpty(key.Token)
+	require.NotNil(key.ExpiresAt)
+
+	var event models.AuditEvent
+	require.NoError(suite.api.db.First(&event, "organization_id = ? AND action = ?", suite.testUser2ID, models.AuditActionImpersonate).Error)
+	require.Equal(suite.testUserID, event.ActorID)
+	require.Equal("support", event.Changes["reason"].New)
+
+	code, _ = suite.serve(http.MethodDelete, "/:id", "/"+uuid.New().String(), suite.api.AdminDeleteDevice, nil)
+	require.Equal(http.StatusNotFound, code)
+
+	// the user is deleted along with its devices
+	code, body = suite.serve(http.MethodDelete, "/:id", "/"+suite.testUser2ID.String(), suite.api.AdminDeleteUser, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.ErrorIs(suite.api.db.First(&models.Device{}, "id = ?", device.ID).Error, gorm.ErrRecordNotFound)
+	require.ErrorIs(suite.api.db.First(&models.User{}, "id = ?", suite.testUser2ID).Error, gorm.ErrRecordNotFound)
+	var deleted models.AuditEvent
+	require.NoError(suite.api.db.First(&deleted, "resource_id = ? AND action = ?", device.ID, models.AuditActionDelete).Error)
+	require.Equal(suite.testUserID, deleted.ActorID)
+
+	code, body = suite.serve(http.MethodGet, "/", "/?action=list&actor_id="+suite.testUserID.String(), suite.api.AdminListAuditEvents, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var events []models.AuditEvent
+	require.NoError(json.Unmarshal(body, &events))
+	require.GreaterOrEqual(len(events), 4)
+	for _, e := range events {
+		require.Equal(uuid.Nil, e.OrganizationID)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// ParseAgentReleases parses and validates the JSON encoded list of agent releases advertised to agents
+func ParseAgentReleases(data []byte) ([]models.AgentRelease, error) {
+	var releases []models.AgentRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, err
+	}
+	for i, release := range releases {
+		if !validUpdateChannel(release.Channel) {
+			return nil, fmt.Errorf("release %d: invalid channel: %q", i, release.Channel)
+		}
+		if release.Version == "" || release.Os == "" || release.Arch == "" || release.URL == "" {
+			return nil, fmt.Errorf("release %d: version, os, arch and url are required", i)
+		}
+		if digest, err := hex.DecodeString(release.Sha256); err != nil || len(digest) != 32 {
+			return nil, fmt.Errorf("release %d: sha256 must be a hex encoded sha256 digest", i)
+		}
+		if _, err := base64.StdEncoding.DecodeString(release.Signature); err != nil || release.Signature == "" {
+			return nil, fmt.Errorf("release %d: signature must be base64 encoded", i)
+		}
+	}
+	return releases, nil
+}
+
+func validUpdateChannel(channel string) bool {
+	return channel == models.UpdateChannelStable || channel == models.UpdateChannelBeta
+}
+
+// GetAgentRelease gets the agent release devices in an Organization should run
+// @Summary      Get Agent Release
+// @Description  Gets the nexd release advertised on the update channel of the organization for an os and architecture
+// @Id 			 GetAgentRelease
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Param		 os   query     string true "Operating system of the agent"
+// @Param		 arch query     string true "CPU architecture of the agent"
+// @Success      200  {object}  models.AgentRelease
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/agent-release [get]
+func (api *API) GetAgentRelease(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetAgentRelease",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+	k, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	os := c.Query("os")
+	if os == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("os"))
+		return
+	}
+	arch := c.Query("arch")
+	if arch == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("arch"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", k.String())
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	channel := org.AgentUpdateChannel()
+	for _, release := range api.AgentReleases {
+		if release.Channel == channel && release.Os == os && release.Arch == arch {
+			c.JSON(http.StatusOK, release)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, models.NewNotFoundError("agent release"))
+}
@@ -4,18 +4,21 @@ import (
 	"context"
 	"crypto/rsa"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-session/session/v3"
 	"github.com/nexodus-io/nexodus/internal/email"
 	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr"
 	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr/envfm"
+	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr/memfm"
 	"github.com/nexodus-io/nexodus/internal/models"
 	"github.com/nexodus-io/nexodus/internal/signalbus"
 	"github.com/redis/go-redis/v9"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/nexodus-io/nexodus/internal/database"
 	"github.com/open-policy-agent/opa/storage"
@@ -29,6 +32,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
 var tracer trace.Tracer
@@ -59,6 +63,14 @@ type API struct {
 	SmtpFrom       string
 	caKeyPair      CertificateKeyPair
 	FrontendURL    string
+	AgentReleases  []models.AgentRelease
+	// the reclaim job deletes the devices not seen for this long and releases their IPAM allocations, 0 disables it
+	IPAMReclaimAfter time.Duration
+	// the webhooks may post to the loopback, private and link local addresses, for the deployments whose receivers are on the private network
+	WebhookAllowPrivate bool
+	// the devices read by ListDevicesInOrganization and GetDevice are cached in redis for this long, 0 disables the cache
+	deviceCacheTTL time.Duration
+	featureFlags   featureFlagCache
 }
 
 func NewAPI(
@@ -96,12 +108,18 @@ func NewAPI(
 		return nil, err
 	}
 
-	fetchManager, err := envfm.New(logger.Desugar())
-	if err != nil {
-		return nil, err
+	// without redis the apiserver runs as a single replica, the fetches and the online devices are tracked in memory
+	var fetchManager fetchmgr.FetchManager
+	if redis == nil {
+		fetchManager = memfm.New()
+	} else {
+		fetchManager, err = envfm.New(logger.Desugar())
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	onlineTracker, err := New(logger.Desugar())
+	onlineTracker, err := New(logger.Desugar(), redis == nil)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +150,20 @@ func NewAPI(
 		return nil, err
 	}
 
+	go api.watchFeatureFlags(ctx, signalBus.Subscribe(featureFlagsSignal))
+
+	go util.RunPeriodically(ctx, onlineTracker.sweepInterval, func() {
+		onlineTracker.sweep(ctx, db)
+	})
+
+	go util.RunPeriodically(ctx, ipamMetricsInterval, func() {
+		api.recordIPAMMetrics(ctx)
+	})
+
+	go util.RunPeriodically(ctx, deviceExpiryInterval, func() {
+		api.expireDevices(ctx)
+	})
+
 	return api, nil
 }
 
@@ -139,18 +171,33 @@ func (api *API) Logger(ctx context.Context) *zap.SugaredLogger {
 	return util.WithTrace(ctx, api.logger)
 }
 
+// readReplicaDB returns the database of the read only handlers whose queries are sent to the read replica when
+// one is configured. The replica may lag behind the writes of the primary database.
+func (api *API) readReplicaDB(ctx context.Context) *gorm.DB {
+	return api.db.WithContext(ctx).Clauses(dbresolver.Use(database.ReadReplica))
+}
+
 func (api *API) sendList(c *gin.Context, ctx context.Context, getList func(db *gorm.DB) (fetchmgr.ResourceList, error)) {
 	db := api.db.WithContext(ctx)
 
 	items, err := getList(db)
 	if err != nil {
-		api.SendInternalServerError(c, err)
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
 		return
 	}
 
 	// For pagination
 	c.Header("Access-Control-Expose-Headers", TotalCountHeader)
 	c.Header(TotalCountHeader, strconv.Itoa(items.Len()))
+	if err := SetNextCursor(c, items); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, items)
 }
 
@@ -182,17 +229,48 @@ func (api *API) GetCurrentUserID(c *gin.Context) uuid.UUID {
 	return userId.(uuid.UUID)
 }
 
+// FlagCheck responds with an error when the feature flag is disabled. A flag targeting organizations is evaluated
+// for the organization of the resource named by the path of the request, or for the organizations of the current
+// user when the path names none, the handlers creating a resource check it again for the organization of the new
+// resource with checkFeatureFlag.
 func (api *API) FlagCheck(c *gin.Context, name string) bool {
-	enabled, err := api.fflags.GetFlag(c, name)
-	if err != nil {
-		api.SendInternalServerError(c, err)
+	if err := api.flagCheck(c, name); err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
 		return false
 	}
+	return true
+}
+
+// flagCheck returns an ApiResponseError when the feature flag is disabled, for the handlers that return their
+// errors instead of responding with them
+func (api *API) flagCheck(c *gin.Context, name string) error {
+	stored, err := api.storedFeatureFlags(c.Request.Context())
+	if err != nil {
+		return err
+	}
+	var orgIds []uuid.UUID
+	if flag, found := stored[name]; found && featureFlagTargeted(flag) {
+		if orgIds, err = api.requestOrganizations(c); err != nil {
+			return err
+		}
+	}
+	flags, err := api.featureFlagValues(c, name, orgIds)
+	if err != nil {
+		return err
+	}
+	enabled, found := flags[name]
+	if !found {
+		return fmt.Errorf("invalid feature flag name: %s", name)
+	}
 	if !enabled {
-		c.JSON(http.StatusMethodNotAllowed, models.NewNotAllowedError(fmt.Sprintf("%s support is disabled", name)))
-		return false
+		return NewApiResponseError(http.StatusMethodNotAllowed, models.NewNotAllowedError(fmt.Sprintf("%s support is disabled", name)))
 	}
-	return enabled
+	return nil
 }
 
 func (api *API) createDefaultIPamNamespace(ctx context.Context) error {
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"gorm.io/gorm"
+)
+
+// APIKeyScope marks the access tokens of the api keys, the api accepts them like the tokens of their user
+// limited to the scopes of the key.
+const APIKeyScope = "api-key"
+
+// APIKeyPrefix is the prefix of the tokens of the api keys.
+const APIKeyPrefix = "AK:"
+
+// apiKeyScopes are the scopes an api key can be limited to, the aliases are replaced by their scopes.
+var apiKeyScopes = map[string][]string{
+	"read:organizations":  {"read:organizations"},
+	"write:organizations": {"write:organizations"},
+	"read:devices":        {"read:devices"},
+	"write:devices":       {"write:devices"},
+	"read:users":          {"read:users"},
+	"write:users":         {"write:users"},
+	"read-only":           {"read:organizations", "read:devices", "read:users"},
+}
+
+// allAPIKeyScopes are all the scopes of the api keys
+var allAPIKeyScopes = []string{"read:organizations", "write:organizations", "read:devices", "write:devices", "read:users", "write:users"}
+
+// apiKeyTokenExpiry is the lifetime of the access tokens the api keys are replaced with, a token is issued for
+// every request of the key.
+const apiKeyTokenExpiry = 5 * time.Minute
+
+// ListAPIKeys lists the api keys of the current user
+// @Summary      List API Keys
+// @Description  Lists the api keys of the current user
+// @Id           ListAPIKeys
+// @Tags         APIKey
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  []models.APIKey
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/api-keys [get]
+func (api *API) ListAPIKeys(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListAPIKeys")
+	defer span.End()
+	keys := []models.APIKey{}
+	db := api.db.WithContext(ctx).Where("user_id = ?", api.GetCurrentUserID(c))
+	db = FilterAndPaginate(db, &models.APIKey{}, c, "id")
+	if result := db.Find(&keys); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching api keys from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetAPIKey gets an api key of the current user
+// @Summary      Get API Key
+// @Description  Gets an api key of the current user by ID
+// @Id           GetAPIKey
+// @Tags         APIKey
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "API Key ID"
+// @Success      200  {object}  models.APIKey
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/api-keys/{id} [get]
+func (api *API) GetAPIKey(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetAPIKey",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var key models.APIKey
+	if res := api.db.WithContext(ctx).First(&key, "id = ? AND user_id = ?", id, api.GetCurrentUserID(c)); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("api key"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+// CreateAPIKey creates an api key for the current user
+// @Summary      Create an API Key
+// @Description  Creates an api key for the current user limited to the scopes of the request, the token of the key is only returned in the response
+// @Id           CreateAPIKey
+// @Tags         APIKey
+// @Accept       json
+// @Produce      json
+// @Param        APIKey  body   models.AddAPIKey  true  "Add API Key"
+// @Success      201  {object}  models.APIKey
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 403  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/api-keys [post]
+func (api *API) CreateAPIKey(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateAPIKey")
+	defer span.End()
+
+	// the callers authenticated by an api key can not create api keys
+	tokenClaims, apierr := NxodusClaims(c, api.db.WithContext(ctx))
+	if apierr != nil {
+		c.JSON(apierr.Status, apierr.Body)
+		return
+	}
+	if slices.Contains(strings.Fields(tokenClaims.Scope), APIKeyScope) {
+		c.JSON(http.StatusForbidden, models.NewNotAllowedError("api keys can not be created with an api key"))
+		return
+	}
+
+	var request models.AddAPIKey
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if len(request.Scopes) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("scopes"))
+		return
+	}
+	scopes := []string{}
+	for _, scope := range request.Scopes {
+		expanded, ok := apiKeyScopes[scope]
+		if !ok {
+			allowed := maps.Keys(apiKeyScopes)
+			slices.Sort(allowed)
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("scopes", "allowed values are: "+strings.Join(allowed, ", ")))
+			return
+		}
+		for _, s := range expanded {
+			if !slices.Contains(scopes, s) {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("expires_at", "must be in the future"))
+		return
+	}
+
+	token, err := generateAPIKeyToken()
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	key := models.APIKey{
+		UserID:      api.GetCurrentUserID(c),
+		Description: request.Description,
+		Scopes:      scopes,
+		ExpiresAt:   request.ExpiresAt,
+		TokenHash:   hashClientSecret(token),
+	}
+	if res := api.db.WithContext(ctx).Create(&key); res.Error != nil {
+		api.SendInternalServerError(c, res.Error)
+		return
+	}
+	span.SetAttributes(attribute.String("id", key.ID.String()))
+
+	key.Token = token
+	c.JSON(http.StatusCreated, key)
+}
+
+// generateAPIKeyToken returns the token of a new api key
+func generateAPIKeyToken() (string, error) {
+	// use a wg private key as the token, since it should be hard to guess.
+	secret, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return "", err
+	}
+	return APIKeyPrefix + secret.String(), nil
+}
+
+// DeleteAPIKey deletes an api key of the current user
+// @Summary      Delete API Key
+// @Description  Deletes an api key of the current user, the key is not accepted anymore
+// @Id           DeleteAPIKey
+// @Tags         APIKey
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "API Key ID"
+// @Success      200  {object}  models.APIKey
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/api-keys/{id} [delete]
+func (api *API) DeleteAPIKey(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteAPIKey",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var key models.APIKey
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.First(&key, "id = ? AND user_id = ?", id, api.GetCurrentUserID(c)); res.Error != nil {
+			return res.Error
+		}
+		return tx.Delete(&key).Error
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("api key"))
+		return
+	} else if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// APIKeyJWT returns an access token of the apiserver for the token of an api key, the token is accepted like
+// the tokens of the user of the key limited to the scopes of the key.
+func (api *API) APIKeyJWT(ctx context.Context, token string) (string, error) {
+	var key models.APIKey
+	db := api.db.WithContext(ctx)
+	if res := db.First(&key, "token_hash = ?", hashClientSecret(token)); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return "", NewApiResponseError(http.StatusUnauthorized, models.NewBaseError("invalid api key"))
+		}
+		return "", res.Error
+	}
+	now := time.Now()
+	expiresAt := now.Add(apiKeyTokenExpiry)
+	if key.ExpiresAt != nil {
+		if !key.ExpiresAt.After(now) {
+			return "", NewApiResponseError(http.StatusUnauthorized, models.NewBaseError("api key expired"))
+		}
+		if key.ExpiresAt.Before(expiresAt) {
+			expiresAt = *key.ExpiresAt
+		}
+	}
+
+	var user models.User
+	if res := db.First(&user, "id = ?", key.UserID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return "", NewApiResponseError(http.StatusUnauthorized, models.NewBaseError("invalid api key user"))
+		}
+		return "", res.Error
+	}
+
+	claims := models.NexodusClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    api.URL,
+			ID:        key.ID.String(),
+			Subject:   user.IdpID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Scope: strings.Join(append([]string{APIKeyScope}, key.Scopes...), " "),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(api.PrivateKey)
+}
+
+// APIKeyUserID returns the ID of the user of the api key of an access token, the tokens of the deleted and the
+// expired api keys are not accepted.
+func (api *API) APIKeyUserID(ctx context.Context, keyId string) (uuid.UUID, error) {
+	id, err := uuid.Parse(keyId)
+	if err != nil {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	var key models.APIKey
+	if res := api.db.WithContext(ctx).First(&key, "id = ?", id); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return key.UserID, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+func (suite *HandlerTestSuite) TestAPIKeys() {
+	require := suite.Require()
+	if suite.api.PrivateKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(err)
+		suite.api.PrivateKey = key
+	}
+
+	create := func(request models.AddAPIKey) (int, []byte) {
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/api-keys", "/api-keys",
+			suite.api.CreateAPIKey, bytes.NewBuffer(suite.jsonMarshal(request)),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	code, body := create(models.AddAPIKey{Description: "no scopes"})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddAPIKey{Scopes: []string{"write:everything"}})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+
+	expiresAt := time.Now().Add(time.Minute).UTC()
+	code, body = create(models.AddAPIKey{Description: "scripts", Scopes: []string{"read-only", "read:devices"}, ExpiresAt: &expiresAt})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var key models.APIKey
+	require.NoError(json.Unmarshal(body, &key))
+	require.True(strings.HasPrefix(key.Token, APIKeyPrefix))
+	require.Equal([]string{"read:organizations", "read:devices", "read:users"}, []string(key.Scopes))
+
+	// the token is only returned when the api key is created
+	_, res, err := suite.ServeRequest(
+		http.MethodGet,
+		"/api-keys", "/api-keys",
+		suite.api.ListAPIKeys, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code)
+	var keys []models.APIKey
+	require.NoError(json.Unmarshal(res.Body.Bytes(), &keys))
+	require.Len(keys, 1)
+	require.Equal(key.ID, keys[0].ID)
+	require.Empty(keys[0].Token)
+
+	accessToken, err := suite.api.APIKeyJWT(context.Background(), key.Token)
+	require.NoError(err)
+	claims := models.NexodusClaims{}
+	_, err = jwt.ParseWithClaims(accessToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		return &suite.api.PrivateKey.PublicKey, nil
+	})
+	require.NoError(err)
+	require.Equal(key.ID.String(), claims.ID)
+	require.Contains(strings.Fields(claims.Scope), APIKeyScope)
+	require.Contains(strings.Fields(claims.Scope), "read:devices")
+	require.NotContains(strings.Fields(claims.Scope), "write:devices")
+	require.False(claims.ExpiresAt.After(expiresAt))
+
+	userId, err := suite.api.APIKeyUserID(context.Background(), claims.ID)
+	require.NoError(err)
+	require.Equal(suite.testUserID, userId)
+
+	_, err = suite.api.APIKeyJWT(context.Background(), APIKeyPrefix+"not-a-key")
+	var apiResponseError *ApiResponseError
+	require.ErrorAs(err, &apiResponseError)
+	require.Equal(http.StatusUnauthorized, apiResponseError.Status)
+
+	// an api key can not create api keys
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(gin.AuthUserKey, suite.testUserID)
+		c.Set("_nexodus.Claims", map[string]interface{}{"jti": claims.ID, "scope": claims.Scope})
+		c.Next()
+	})
+	r.POST("/api-keys", suite.api.CreateAPIKey)
+	req, err := http.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(suite.jsonMarshal(models.AddAPIKey{Scopes: []string{"write:users"}})))
+	require.NoError(err)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	require.Equal(http.StatusForbidden, res.Code, "HTTP error: %s", res.Body.String())
+
+	_, res, err = suite.ServeRequest(
+		http.MethodDelete,
+		"/api-keys/:id", fmt.Sprintf("/api-keys/%s", key.ID),
+		suite.api.DeleteAPIKey, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", res.Body.String())
+
+	// a deleted api key is not accepted
+	_, err = suite.api.APIKeyUserID(context.Background(), claims.ID)
+	require.ErrorIs(err, gorm.ErrRecordNotFound)
+	_, err = suite.api.APIKeyJWT(context.Background(), key.Token)
+	require.ErrorAs(err, &apiResponseError)
+	require.Equal(http.StatusUnauthorized, apiResponseError.Status)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/api-keys/:id", fmt.Sprintf("/api-keys/%s", key.ID),
+		suite.api.GetAPIKey, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, res.Code)
+}
+
+func (suite *HandlerTestSuite) TestCreateDevAPIKey() {
+	require := suite.Require()
+	if suite.api.PrivateKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(err)
+		suite.api.PrivateKey = key
+	}
+	ctx := context.Background()
+
+	first, err := suite.api.CreateDevAPIKey(ctx)
+	require.NoError(err)
+	require.True(strings.HasPrefix(first.Token, APIKeyPrefix))
+	_, err = suite.api.APIKeyJWT(ctx, first.Token)
+	require.NoError(err)
+
+	// a restart replaces the key of the dev user
+	second, err := suite.api.CreateDevAPIKey(ctx)
+	require.NoError(err)
+	require.Equal(first.UserID, second.UserID)
+	_, err = suite.api.APIKeyJWT(ctx, second.Token)
+	require.NoError(err)
+	_, err = suite.api.APIKeyJWT(ctx, first.Token)
+	var apiResponseError *ApiResponseError
+	require.ErrorAs(err, &apiResponseError)
+	require.Equal(http.StatusUnauthorized, apiResponseError.Status)
+}
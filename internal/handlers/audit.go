@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// auditIgnoredFields are not recorded in audit events, they either change on every write, hold
+// secrets, or are status reported by the agents rather than configuration.
+var auditIgnoredFields = map[string]struct{}{
+	"revision":     {},
+	"bearer_token": {},
+	"online":       {},
+	"online_at":    {},
+	"last_seen":    {},
+}
+
+// auditState captures the json representation of a resource so that it can be compared
+// to the state of the resource after it has been modified.
+func auditState(resource interface{}) map[string]interface{} {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil
+	}
+	state := map[string]interface{}{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	return state
+}
+
+// auditChanges returns the fields that differ between two states captured by auditState
+func auditChanges(before, after map[string]interface{}) map[string]models.AuditChange {
+	changes := map[string]models.AuditChange{}
+	for key, old := range before {
+		if _, ignored := auditIgnoredFields[key]; ignored {
+			continue
+		}
+		if value, found := after[key]; !found || !reflect.DeepEqual(old, value) {
+			changes[key] = models.AuditChange{Old: old, New: value}
+		}
+	}
+	for key, value := range after {
+		if _, ignored := auditIgnoredFields[key]; ignored {
+			continue
+		}
+		if _, found := before[key]; !found {
+			changes[key] = models.AuditChange{New: value}
+		}
+	}
+	return changes
+}
+
+// recordAuditEvent appends an audit event for a mutation of a resource of an organization. before
+// and after are the states of the resource captured with auditState, before is nil for created
+// resources and after is nil for deleted resources. Updates that did not change anything are not recorded. The
+// event is posted to the webhooks of the organization subscribed to it.
+func (api *API) recordAuditEvent(c *gin.Context, tx *gorm.DB, orgId uuid.UUID, action string, resourceType string, resourceId uuid.UUID, before, after map[string]interface{}) error {
+	changes := auditChanges(before, after)
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+	event := models.AuditEvent{
+		OrganizationID: orgId,
+		ActorID:        api.GetCurrentUserID(c),
+		SourceIP:       c.ClientIP(),
+		Action:         action,
+		ResourceType:   resourceType,
+		ResourceID:     resourceId,
+		Changes:        changes,
+	}
+	if res := tx.Create(&event); res.Error != nil {
+		return fmt.Errorf("failed to record audit event: %w", res.Error)
+	}
+	return queueWebhookDeliveries(tx, event)
+}
+
+// recordSystemAuditEvent appends an audit event for a mutation made by a background job of the apiserver, the
+// event has no actor.
+func recordSystemAuditEvent(tx *gorm.DB, orgId uuid.UUID, action string, resourceType string, resourceId uuid.UUID, before, after map[string]interface{}) error {
+	event := models.AuditEvent{
+		OrganizationID: orgId,
+		Action:         action,
+		ResourceType:   resourceType,
+		ResourceID:     resourceId,
+		Changes:        auditChanges(before, after),
+	}
+	if res := tx.Create(&event); res.Error != nil {
+		return fmt.Errorf("failed to record audit event: %w", res.Error)
+	}
+	return queueWebhookDeliveries(tx, event)
+}
+
+// ListAuditEvents lists the audit events of an Organization
+// @Summary      List Audit Events
+// @Description  Lists the create, update, delete and expire operations made on the resources of an organization, and the impersonations of its users, newest first
+// @Id 			 ListAuditEvents
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id            path   string true  "Organization ID"
+// @Param		 resource_type query  string false "only list events of this resource type, e.g. device"
+// @Param		 resource_id   query  string false "only list events of this resource"
+// @Param		 action        query  string false "only list events of this action, one of create, update, delete, expire or impersonate"
+// @Param		 actor_id      query  string false "only list events made by this user"
+// @Success      200  {object}  []models.AuditEvent
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/audit [get]
+func (api *API) ListAuditEvents(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListAuditEvents",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	// the audit log is reserved for the owners of the organization, it holds the addresses users connect from
+	owner, err := api.IsOwnerOfOrg(c, id)
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	if !owner {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+
+	db := api.db.WithContext(ctx)
+	db = db.Where("organization_id = ?", id)
+	if resourceType := c.Query("resource_type"); resourceType != "" {
+		db = db.Where("resource_type = ?", resourceType)
+	}
+	if action := c.Query("action"); action != "" {
+		if action != models.AuditActionCreate && action != models.AuditActionUpdate && action != models.AuditActionDelete && action != models.AuditActionExpire && action != models.AuditActionImpersonate {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("action", "must be create, update, delete, expire or impersonate"))
+			return
+		}
+		db = db.Where("action = ?", action)
+	}
+	for _, param := range []string{"resource_id", "actor_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if _, err := uuid.Parse(value); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError(param, "must be a uuid"))
+			return
+		}
+		db = db.Where(param+" = ?", value)
+	}
+	db = FilterAndPaginate(db, &models.AuditEvent{}, c, "created_at DESC")
+
+	var events []models.AuditEvent
+	result := db.Find(&events)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		api.SendInternalServerError(c, result.Error)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
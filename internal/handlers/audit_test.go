@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditChanges(t *testing.T) {
+	device := models.Device{
+		Base:        models.Base{ID: uuid.New()},
+		Hostname:    "host1",
+		Relay:       false,
+		Revision:    1,
+		BearerToken: "DT:secret",
+	}
+	before := auditState(device)
+
+	device.Hostname = "host2"
+	device.Relay = true
+	device.Revision = 2
+	changes := auditChanges(before, auditState(device))
+	assert.Equal(t, map[string]models.AuditChange{
+		"hostname": {Old: "host1", New: "host2"},
+		"relay":    {Old: false, New: true},
+	}, changes)
+
+	assert.Empty(t, auditChanges(auditState(device), auditState(device)))
+
+	created := auditChanges(nil, auditState(device))
+	assert.Equal(t, models.AuditChange{New: "host2"}, created["hostname"])
+	assert.NotContains(t, created, "bearer_token")
+	assert.NotContains(t, created, "revision")
+
+	deleted := auditChanges(auditState(device), nil)
+	assert.Equal(t, models.AuditChange{Old: "host2"}, deleted["hostname"])
+}
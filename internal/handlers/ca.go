@@ -63,7 +63,7 @@ func newSerialNumber() (*big.Int, error) {
 // @Success      201  {object}  models.CertificateSigningResponse
 // @Failure      400  {object}  models.ValidationError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/ca/sign [post]
+// @Router       /api/v1/ca/sign [post]
 func (api *API) SignCSR(c *gin.Context) {
 	_, span := tracer.Start(c.Request.Context(), "SignCSR")
 	defer span.End()
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// the reachability of a peer as classified by the in-tunnel probes of nexd
+var peerReachabilities = map[string]bool{
+	"reachable":   true,
+	"degraded":    true,
+	"unreachable": true,
+}
+
+// ReportDeviceConnectivity replaces the connectivity report of a device
+// @Summary      Report Device Connectivity
+// @Description  Replaces the reachability of the peers of a device last reported by its agent, the peers that are not devices of the vpc of the device are ignored
+// @Id           ReportDeviceConnectivity
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                     true "Device ID"
+// @Param        report  body      models.ReportConnectivity  true "Connectivity Report"
+// @Success      200  {object}  []models.PeerConnectivity
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/connectivity [put]
+func (api *API) ReportDeviceConnectivity(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ReportDeviceConnectivity", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var request models.ReportConnectivity
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	for _, peer := range request.Peers {
+		if !peerReachabilities[peer.Reachability] {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("reachability", "must be reachable, degraded or unreachable"))
+			return
+		}
+		if peer.Loss < 0 || peer.Loss > 1 {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("loss", "must be between 0 and 1"))
+			return
+		}
+	}
+
+	report := []models.PeerConnectivity{}
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var device models.Device
+		if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", deviceId); res.Error != nil {
+			return res.Error
+		}
+
+		peerIds := []uuid.UUID{}
+		for _, peer := range request.Peers {
+			peerIds = append(peerIds, peer.PeerID)
+		}
+		var peers []uuid.UUID
+		if len(peerIds) > 0 {
+			if res := tx.Model(&models.Device{}).
+				Where("id IN ? AND vpc_id = ? AND id <> ?", peerIds, device.VpcID, device.ID).
+				Pluck("id", &peers); res.Error != nil {
+				return res.Error
+			}
+		}
+		inVpc := map[uuid.UUID]bool{}
+		for _, id := range peers {
+			inVpc[id] = true
+		}
+		for _, peer := range request.Peers {
+			if !inVpc[peer.PeerID] {
+				continue
+			}
+			// a peer reported twice is only stored once
+			delete(inVpc, peer.PeerID)
+			report = append(report, models.PeerConnectivity{
+				DeviceID:       device.ID,
+				PeerID:         peer.PeerID,
+				OrganizationID: device.OrganizationID,
+				VpcID:          device.VpcID,
+				Reachability:   peer.Reachability,
+				Method:         peer.Method,
+				LatencyAvgMs:   peer.LatencyAvgMs,
+				LatencyMaxMs:   peer.LatencyMaxMs,
+				Loss:           peer.Loss,
+			})
+		}
+
+		if res := tx.Delete(&models.PeerConnectivity{}, "device_id = ?", device.ID); res.Error != nil {
+			return res.Error
+		}
+		if len(report) > 0 {
+			if res := tx.Create(&report); res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
+			return
+		}
+		api.SendInternalServerError(c, fmt.Errorf("error storing the connectivity report: %w", err))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ListOrganizationConnectivity lists the connectivity matrix of an organization
+// @Summary      List Organization Connectivity
+// @Description  Lists the reachability of the peers last reported by each device of the organization
+// @Id           ListOrganizationConnectivity
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  []models.PeerConnectivity
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/connectivity [get]
+func (api *API) ListOrganizationConnectivity(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListOrganizationConnectivity",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	report := []models.PeerConnectivity{}
+	if res := db.Where("organization_id = ?", org.ID).
+		Order("device_id").Order("peer_id").
+		Find(&report); res.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching the connectivity reports from db: %w", res.Error))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// deleteDeviceConnectivity deletes the reports of a deleted device and the reachability of the device reported by its peers
+func deleteDeviceConnectivity(tx *gorm.DB, device models.Device) error {
+	return tx.Delete(&models.PeerConnectivity{}, "device_id = ? OR peer_id = ?", device.ID, device.ID).Error
+}
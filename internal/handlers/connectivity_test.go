@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestConnectivity() {
+	require := suite.Require()
+
+	node1 := suite.createDevice(models.AddDevice{PublicKey: suite.newPublicKey(), Hostname: "node1"})
+	node2 := suite.createDevice(models.AddDevice{PublicKey: suite.newPublicKey(), Hostname: "node2"})
+
+	report := func(device models.Device, request models.ReportConnectivity) (int, []byte) {
+		reqBody, err := json.Marshal(request)
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPut,
+			"/:id/connectivity", fmt.Sprintf("/%s/connectivity", device.ID),
+			suite.api.ReportDeviceConnectivity, bytes.NewBuffer(reqBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	// the peers that are not devices of the vpc are ignored
+	code, body := report(node1, models.ReportConnectivity{Peers: []models.ReportPeerConnectivity{
+		{PeerID: node2.ID, Reachability: "reachable", Method: "direct", LatencyAvgMs: 1.5, LatencyMaxMs: 3},
+		{PeerID: uuid.New(), Reachability: "unreachable"},
+	}})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	code, body = report(node2, models.ReportConnectivity{Peers: []models.ReportPeerConnectivity{
+		{PeerID: node1.ID, Reachability: "unreachable", Method: "relay", Loss: 1},
+	}})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+
+	code, body = report(node1, models.ReportConnectivity{Peers: []models.ReportPeerConnectivity{
+		{PeerID: node2.ID, Reachability: "up"},
+	}})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+	code, body = report(node1, models.ReportConnectivity{Peers: []models.ReportPeerConnectivity{
+		{PeerID: node2.ID, Reachability: "degraded", Loss: 2},
+	}})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+
+	list := func() []models.PeerConnectivity {
+		_, res, err := suite.ServeRequest(
+			http.MethodGet,
+			"/:id/connectivity", fmt.Sprintf("/%s/connectivity", suite.testUserID),
+			suite.api.ListOrganizationConnectivity, nil,
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+		var matrix []models.PeerConnectivity
+		require.NoError(json.Unmarshal(body, &matrix))
+		return matrix
+	}
+	matrix := list()
+	require.Len(matrix, 2)
+	reported := map[uuid.UUID]models.PeerConnectivity{}
+	for _, peer := range matrix {
+		reported[peer.DeviceID] = peer
+	}
+	require.Equal(node2.ID, reported[node1.ID].PeerID)
+	require.Equal("reachable", reported[node1.ID].Reachability)
+	require.Equal(1.5, reported[node1.ID].LatencyAvgMs)
+	require.Equal("relay", reported[node2.ID].Method)
+
+	// a report replaces the previous report of the device
+	code, body = report(node1, models.ReportConnectivity{})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.Len(list(), 1)
+
+	// the reachability of a device is deleted with it
+	_, res, err := suite.ServeRequest(
+		http.MethodDelete,
+		"/:id", fmt.Sprintf("/%s", node1.ID),
+		suite.api.DeleteDevice, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code)
+	require.Len(list(), 0)
+}
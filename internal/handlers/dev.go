@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+// devUserIdpID is the identity of the user of the dev mode, which has no identity provider
+const devUserIdpID = "dev"
+
+// devAPIKeyDescription is the description of the api key of the dev mode user
+const devAPIKeyDescription = "dev mode"
+
+// CreateDevAPIKey creates the user of the dev mode if it does not exist and replaces its api key, the token of the
+// returned key authenticates clients as the user when the apiserver runs without an identity provider.
+func (api *API) CreateDevAPIKey(ctx context.Context) (models.APIKey, error) {
+	userId, err := api.CreateUserIfNotExists(ctx, devUserIdpID, devUserIdpID, nil)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	token, err := generateAPIKeyToken()
+	if err != nil {
+		return models.APIKey{}, err
+	}
+
+	key := models.APIKey{
+		UserID:      userId,
+		Description: devAPIKeyDescription,
+		Scopes:      allAPIKeyScopes,
+		TokenHash:   hashClientSecret(token),
+	}
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		// the token of the previous key is not known anymore
+		if res := tx.Where("user_id = ? AND description = ?", userId, devAPIKeyDescription).Delete(&models.APIKey{}); res.Error != nil {
+			return res.Error
+		}
+		return tx.Create(&key).Error
+	})
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	key.Token = token
+	return key, nil
+}
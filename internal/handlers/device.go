@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr"
 	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nexodus-io/nexodus/internal/database"
 	"github.com/nexodus-io/nexodus/internal/models"
 	"github.com/nexodus-io/nexodus/internal/util"
 	"github.com/nexodus-io/nexodus/internal/wgcrypto"
@@ -28,6 +35,15 @@ var (
 	errRegKeyExhausted       = errors.New("single use reg key exhausted")
 )
 
+// maxDnsNameAttempts is the number of suffixed names tried when reserving a dns name for a hostname
+const maxDnsNameAttempts = 100
+
+// maxDisplayNameLength is the longest display name a device can be given
+const maxDisplayNameLength = 64
+
+// endpointIPv6Reason is the validation error of an IPv6 endpoint that is not a global IPv6 address and port
+const endpointIPv6Reason = "must be a global IPv6 address and port"
+
 type deviceList []*models.Device
 
 func (d deviceList) Item(i int) (any, uint64, gorm.DeletedAt) {
@@ -46,11 +62,17 @@ func (d deviceList) Len() int {
 // @Tags         Devices
 // @Accept       json
 // @Produce      json
+// @Param		 sort    query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit   query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor  query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Param		 label   query  []string false "only list the devices with this label, formatted as key=value, the devices must have all the given labels" collectionFormat(multi)
 // @Success      200  {object}  []models.Device
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure      400  {object}  models.BaseError
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices [get]
+// @Router       /api/v1/devices [get]
 func (api *API) ListDevices(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListDevices")
 	defer span.End()
@@ -61,12 +83,27 @@ func (api *API) ListDevices(c *gin.Context) {
 
 	devices := make([]models.Device, 0)
 
-	db := api.db.WithContext(ctx)
+	db := api.readReplicaDB(ctx)
 	db = api.DeviceIsOwnedByCurrentUser(c, db)
+	labels, apiErr := parseLabelSelector(c.QueryArray("label"))
+	if apiErr != nil {
+		c.JSON(apiErr.Status, apiErr.Body)
+		return
+	}
+	db = api.whereDeviceLabels(db, labels)
 	db = FilterAndPaginate(db, &models.Device{}, c, "hostname")
 	result := db.Find(&devices)
 	if result.Error != nil {
-		api.SendInternalServerError(c, errors.New("error fetching keys from db"))
+		var apiResponseError *ApiResponseError
+		if errors.As(result.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, errors.New("error fetching keys from db"))
+		}
+		return
+	}
+	if err := SetNextCursor(c, devices); err != nil {
+		api.SendInternalServerError(c, err)
 		return
 	}
 
@@ -135,42 +172,61 @@ func (api *API) DeviceIsOwnedByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id} [get]
+// @Router       /api/v1/devices/{id} [get]
 func (api *API) GetDevice(c *gin.Context) {
+	k, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	device, err := api.FindDevice(c, k)
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// FindDevice returns a device of the current user by ID, it serves GetDevice and the gRPC API
+func (api *API) FindDevice(c *gin.Context, id uuid.UUID) (models.Device, error) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetDevice", trace.WithAttributes(
-		attribute.String("id", c.Param("id")),
+		attribute.String("id", id.String()),
 	))
 	defer span.End()
 
-	if !api.FlagCheck(c, "devices") {
-		return
+	if err := api.flagCheck(c, "devices"); err != nil {
+		return models.Device{}, err
 	}
 
-	k, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
-		return
+	device, revision, cached := api.cachedDevice(ctx, id)
+	if cached && device.OwnerID != api.GetCurrentUserID(c) {
+		return models.Device{}, NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
 	}
-	var device models.Device
-
-	db := api.db.WithContext(ctx)
-	db = api.DeviceIsOwnedByCurrentUser(c, db)
-	result := db.First(&device, "id = ?", k)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		c.Status(http.StatusNotFound)
-		return
+	if !cached {
+		db := api.db.WithContext(ctx)
+		db = api.DeviceIsOwnedByCurrentUser(c, db)
+		result := db.First(&device, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return models.Device{}, NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+		}
+		if result.Error == nil {
+			api.cacheDevice(ctx, revision, device)
+		}
 	}
 
-	tokenClaims, err2 := NxodusClaims(c, api.db.WithContext(ctx))
-	if err2 != nil {
-		c.JSON(err2.Status, err2.Body)
-		return
+	tokenClaims, err := NxodusClaims(c, api.db.WithContext(ctx))
+	if err != nil {
+		return models.Device{}, err
 	}
 
 	// only show the device token when using the reg token that created the device.
 	hideDeviceBearerToken(&device, tokenClaims)
-
-	c.JSON(http.StatusOK, device)
+	return device, nil
 }
 
 // UpdateDevice updates a Device
@@ -185,20 +241,13 @@ func (api *API) GetDevice(c *gin.Context) {
 // @Success      200  {object}  models.Device
 // @Failure		 401  {object}  models.BaseError
 // @Failure      400  {object}  models.BaseError
+// @Failure      403  {object}  models.QuotaExceededError
 // @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id} [patch]
+// @Router       /api/v1/devices/{id} [patch]
 func (api *API) UpdateDevice(c *gin.Context) {
-	ctx, span := tracer.Start(c.Request.Context(), "UpdateDevice", trace.WithAttributes(
-		attribute.String("id", c.Param("id")),
-	))
-	defer span.End()
-
-	if !api.FlagCheck(c, "devices") {
-		return
-	}
-
 	deviceId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
@@ -211,8 +260,33 @@ func (api *API) UpdateDevice(c *gin.Context) {
 		return
 	}
 
+	device, err := api.PatchDevice(c, deviceId, request)
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, device)
+}
+
+// PatchDevice applies an update to a device of the current user, it serves UpdateDevice and the gRPC API
+func (api *API) PatchDevice(c *gin.Context, deviceId uuid.UUID, request models.UpdateDevice) (models.Device, error) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateDevice", trace.WithAttributes(
+		attribute.String("id", deviceId.String()),
+	))
+	defer span.End()
+
+	if err := api.flagCheck(c, "devices"); err != nil {
+		return models.Device{}, err
+	}
+
 	var device models.Device
 	var tokenClaims *models.NexodusClaims
+	var err error
 	err = api.transaction(ctx, func(tx *gorm.DB) error {
 
 		db := api.DeviceIsOwnedByCurrentUser(c, tx)
@@ -222,6 +296,7 @@ func (api *API) UpdateDevice(c *gin.Context) {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return errDeviceNotFound
 		}
+		before := auditState(device)
 
 		var err2 *ApiResponseError
 		tokenClaims, err2 = NxodusClaims(c, tx)
@@ -256,10 +331,57 @@ func (api *API) UpdateDevice(c *gin.Context) {
 			device.Hostname = request.Hostname
 		}
 
+		renamed := false
+		if request.DisplayName != nil {
+			displayName := strings.TrimSpace(*request.DisplayName)
+			if len(displayName) > maxDisplayNameLength {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("display_name", fmt.Sprintf("must be at most %d characters", maxDisplayNameLength)))
+			}
+			renamed = displayName != device.DisplayName
+			device.DisplayName = displayName
+		}
+
+		// a renamed device gets a dns name derived from its new name
+		if request.DnsName != "" || device.DnsName == "" || renamed {
+			device.DnsName, err = reserveDnsName(tx, device, request.DnsName)
+			if err != nil {
+				return err
+			}
+		}
+
+		if request.Labels != nil {
+			if err := applyDeviceLabels(&device, request.Labels); err != nil {
+				return err
+			}
+		}
+
 		if len(request.Endpoints) > 0 {
 			device.Endpoints = request.Endpoints
 		}
 
+		var org models.Organization
+		if result = tx.First(&org, "id = ?", device.OrganizationID); result.Error != nil {
+			return result.Error
+		}
+
+		if request.AdvertiseCidrs != nil && !advertiseCidrEquals(device.AdvertiseCidrs, request.AdvertiseCidrs) {
+			if err := checkChildPrefixQuota(tx, org, device.ID, request.AdvertiseCidrs); err != nil {
+				return err
+			}
+		}
+
+		if request.ListenPort != nil {
+			// relays listen on the well-known wireguard port, outside of the range of the organization
+			relay := device.Relay
+			if request.Relay != nil {
+				relay = *request.Relay
+			}
+			if !relay && !org.ListenPortAllowed(*request.ListenPort) {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("listen_port", "is outside of the organization's allowed listen port range"))
+			}
+			device.ListenPort = *request.ListenPort
+		}
+
 		// TODO: re-enable this when we are ready to support changing a device's VPC.
 
 		if request.VpcID != nil && *request.VpcID != device.OrganizationID {
@@ -283,27 +405,45 @@ func (api *API) UpdateDevice(c *gin.Context) {
 					address := t.Address
 					cidr := t.CIDR
 					if address != "" && cidr != "" {
+						reserved, err := addressIsReserved(ctx, tx, device.VpcID, address)
+						if err != nil {
+							return err
+						}
+						if reserved {
+							continue
+						}
 						if err := api.ipam.ReleaseToPool(c.Request.Context(), originalIpamNamespace, address, cidr); err != nil {
 							return fmt.Errorf("failed to release the ip address to pool: %w", err)
 						}
 					}
 				}
 				for _, cidr := range device.AdvertiseCidrs {
-					if err := api.ipam.ReleaseCIDR(c.Request.Context(), originalIpamNamespace, cidr); err != nil {
+					if err := api.releaseAdvertisedCidr(c.Request.Context(), tx, originalIpamNamespace, device, cidr); err != nil {
 						return fmt.Errorf("failed to release cidr: %w", err)
 					}
 				}
 
-				device.IPv4TunnelIPs[0].CIDR = newVpc.Ipv4Cidr
-				device.IPv4TunnelIPs[0].Address, err = api.ipam.AssignFromPool(ctx, newIpamNamespace, newVpc.Ipv4Cidr)
+				reservation, err := reservationOfDevice(tx, newVpc.ID, device.PublicKey, device.ID)
 				if err != nil {
-					return fmt.Errorf("failed to request ipam address: %w", err)
+					return err
+				}
+				device.IPv4TunnelIPs[0].CIDR = newVpc.Ipv4Cidr
+				if reservation != nil {
+					device.IPv4TunnelIPs[0].Address = reservation.Address
+				} else {
+					device.IPv4TunnelIPs[0].Address, err = api.ipam.AssignFromPool(ctx, newIpamNamespace, newVpc.Ipv4Cidr)
+					if err != nil {
+						return fmt.Errorf("failed to request ipam address: %w", err)
+					}
 				}
 
-				device.IPv6TunnelIPs[0].CIDR = newVpc.Ipv6Cidr
-				device.IPv6TunnelIPs[0].Address, err = api.ipam.AssignFromPool(ctx, newIpamNamespace, newVpc.Ipv6Cidr)
-				if err != nil {
-					return fmt.Errorf("failed to request ipam address: %w", err)
+				// devices running in IPv4 only mode do not get a v6 address
+				if len(device.IPv6TunnelIPs) > 0 {
+					device.IPv6TunnelIPs[0].CIDR = newVpc.Ipv6Cidr
+					device.IPv6TunnelIPs[0].Address, err = api.ipam.AssignFromPool(ctx, newIpamNamespace, newVpc.Ipv6Cidr)
+					if err != nil {
+						return fmt.Errorf("failed to request ipam address: %w", err)
+					}
 				}
 
 				// allocate a CIDR if requested
@@ -320,19 +460,49 @@ func (api *API) UpdateDevice(c *gin.Context) {
 				}
 			}
 
-			device.AllowedIPs, err = getAllowedIPs(device.IPv4TunnelIPs[0].Address, device.IPv6TunnelIPs[0].Address, device.Relay)
+			ipv6Address := ""
+			if len(device.IPv6TunnelIPs) > 0 {
+				ipv6Address = device.IPv6TunnelIPs[0].Address
+			}
+			device.AllowedIPs, err = getAllowedIPs(device.IPv4TunnelIPs[0].Address, ipv6Address, device.Relay)
 			if err != nil {
 				return err
 			}
 
+			if *request.VpcID != device.VpcID {
+				// the additional security groups of the device belong to its previous vpc
+				if res := tx.Delete(&models.DeviceSecurityGroup{}, "device_id = ?", device.ID); res.Error != nil {
+					return res.Error
+				}
+				device.SecurityGroupIds = nil
+			}
 			device.VpcID = *request.VpcID
 		}
 		if request.SymmetricNat != nil {
 			device.SymmetricNat = *request.SymmetricNat
+			if !device.SymmetricNat {
+				// only the devices behind a symmetric NAT are assigned a relay
+				device.RelayID = uuid.Nil
+			}
+		}
+		if request.NatType != nil {
+			device.NatType = *request.NatType
+		}
+		if request.NatHairpin != nil {
+			device.NatHairpin = *request.NatHairpin
+		}
+		if request.EndpointIPv6 != nil {
+			if !validEndpointIPv6(*request.EndpointIPv6) {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("endpoint_ipv6", endpointIPv6Reason))
+			}
+			device.EndpointIPv6 = *request.EndpointIPv6
 		}
 		if request.Relay != nil {
 			device.Relay = *request.Relay
 		}
+		if device.Relay && device.SymmetricNat {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("relay", relayBehindSymmetricNatReason))
+		}
 
 		if request.SecurityGroupId != nil {
 			var sg models.SecurityGroup
@@ -352,22 +522,24 @@ func (api *API) UpdateDevice(c *gin.Context) {
 				}
 				cidrAllocated[cidr] = struct{}{}
 			}
+			requested := make(map[string]struct{})
 			for _, cidr := range request.AdvertiseCidrs {
-				isDefaultRoute := util.IsDefaultIPRoute(cidr)
-				// If the prefix is not a default route, process IPAM allocation/release
-				if isDefaultRoute {
+				requested[cidr] = struct{}{}
+				// the prefixes newly advertised are allocated, unless they are a default route
+				if _, ok := cidrAllocated[cidr]; ok || util.IsDefaultIPRoute(cidr) {
 					continue
 				}
-				// lookup miss of prefix means we need to release it
-				if _, ok := cidrAllocated[cidr]; ok {
-					if err := api.ipam.ReleaseCIDR(ctx, originalIpamNamespace, cidr); err != nil {
-						return err
-					}
-				} else {
-					// otherwise we need to allocate it
-					if err := api.ipam.AssignCIDR(ctx, originalIpamNamespace, cidr); err != nil {
-						return err
-					}
+				if err := api.ipam.AssignCIDR(ctx, originalIpamNamespace, cidr); err != nil {
+					return err
+				}
+			}
+			// the prefixes no longer advertised are released
+			for cidr := range cidrAllocated {
+				if _, ok := requested[cidr]; ok {
+					continue
+				}
+				if err := api.releaseAdvertisedCidr(ctx, tx, originalIpamNamespace, device, cidr); err != nil {
+					return err
 				}
 			}
 			device.AdvertiseCidrs = request.AdvertiseCidrs
@@ -377,28 +549,29 @@ func (api *API) UpdateDevice(c *gin.Context) {
 		if res := tx.
 			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
 			Save(&device); res.Error != nil {
-			return res.Error
+			return dnsNameTaken(res.Error)
 		}
 
-		return nil
+		if request.SymmetricNat != nil || request.Relay != nil {
+			if _, err := api.assignRelays(ctx, tx, device.VpcID); err != nil {
+				return err
+			}
+		}
+
+		return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionUpdate, "device", device.ID, before, auditState(device))
 	})
 
+	if errors.Is(err, errDeviceNotFound) {
+		return models.Device{}, NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+	}
 	if err != nil {
-		var apiResponseError *ApiResponseError
-		if errors.Is(err, errDeviceNotFound) {
-			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
-		} else if errors.As(err, &apiResponseError) {
-			c.JSON(apiResponseError.Status, apiResponseError.Body)
-		} else {
-			api.SendInternalServerError(c, err)
-		}
-		return
+		return models.Device{}, err
 	}
 
 	hideDeviceBearerToken(&device, tokenClaims)
 
 	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
-	c.JSON(http.StatusOK, device)
+	return device, nil
 }
 
 func getAllowedIPs(ip string, ip6 string, relay bool) ([]string, error) {
@@ -414,8 +587,9 @@ func getAllowedIPs(ip string, ip6 string, relay bool) ([]string, error) {
 			return nil, fmt.Errorf("failed to append a v4 prefix length to the IPAM address: %w", err)
 		}
 	}
-	// append a host prefix length to the leased v6 IPAM address to add to the allowed-ips slice
-	if !relay {
+	// append a host prefix length to the leased v6 IPAM address to add to the allowed-ips slice,
+	// devices running in IPv4 only mode do not have a v6 address.
+	if !relay && hostPrefixV6 != "" {
 		hostPrefixV6, err = util.AppendPrefixMask(hostPrefixV6, 128)
 		if err != nil {
 			return nil, fmt.Errorf("failed to append a v4 prefix length to the IPAM address: %w", err)
@@ -425,11 +599,101 @@ func getAllowedIPs(ip string, ip6 string, relay bool) ([]string, error) {
 	var allowedIPs []string
 	// append the IPAM leases to the allowed-ips list to be distributed to peers
 	allowedIPs = append(allowedIPs, hostPrefixV4)
-	allowedIPs = append(allowedIPs, hostPrefixV6)
+	if hostPrefixV6 != "" {
+		allowedIPs = append(allowedIPs, hostPrefixV6)
+	}
 
 	return allowedIPs, nil
 }
 
+// reserveDnsName reserves a name for the device in the overlay DNS of its organization. A requested
+// name must be available, otherwise the name is derived from the display name or the hostname and a
+// numeric suffix is added until a name that is not used by another device, a service or a dns record
+// of the organization is found.
+func reserveDnsName(tx *gorm.DB, device models.Device, requested string) (string, error) {
+	if requested != "" {
+		if !util.IsValidDNSLabel(requested) {
+			return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("dns_name", "must be a lowercase DNS label"))
+		}
+		owner, err := dnsNameOwner(tx, device, requested)
+		if err != nil {
+			return "", err
+		}
+		if owner != uuid.Nil {
+			return "", NewApiResponseError(http.StatusConflict, models.NewConflictsError(owner.String()))
+		}
+		return requested, nil
+	}
+
+	base := util.DNSLabel(device.DisplayName)
+	if base == "" {
+		base = util.DNSLabel(device.Hostname)
+	}
+	if base == "" {
+		base = "device"
+	}
+	for i := 1; i <= maxDnsNameAttempts; i++ {
+		name := base
+		if i > 1 {
+			suffix := fmt.Sprintf("-%d", i)
+			name = strings.TrimRight(base[:min(len(base), 63-len(suffix))], "-") + suffix
+		}
+		owner, err := dnsNameOwner(tx, device, name)
+		if err != nil {
+			return "", err
+		}
+		if owner == uuid.Nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("failed to reserve a dns name for %s", base)
+}
+
+// dnsNameOwner returns the id of the other device, of the service or of the dns record in the organization
+// that uses the dns name, or uuid.Nil
+func dnsNameOwner(tx *gorm.DB, device models.Device, name string) (uuid.UUID, error) {
+	var owner models.Device
+	res := tx.Select("id").
+		Where("organization_id = ? AND dns_name = ? AND id != ?", device.OrganizationID, name, device.ID).
+		First(&owner)
+	if res.Error == nil {
+		return owner.ID, nil
+	}
+	if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return uuid.Nil, res.Error
+	}
+	var service models.Service
+	res = tx.Select("id").
+		Where("organization_id = ? AND name = ?", device.OrganizationID, name).
+		First(&service)
+	if res.Error == nil {
+		return service.ID, nil
+	}
+	if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return uuid.Nil, res.Error
+	}
+	var record models.DNSRecord
+	res = tx.Select("id").
+		Where("organization_id = ? AND name = ?", device.OrganizationID, name).
+		First(&record)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return uuid.Nil, nil
+	}
+	if res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	return record.ID, nil
+}
+
+// dnsNameTaken maps the violation of the unique index of the dns names of an organization, when another
+// device reserved the same name concurrently, to an HTTP 409
+func dnsNameTaken(err error) error {
+	if database.IsDuplicateError(err) {
+		return NewApiResponseError(http.StatusConflict, models.NewConflictsError(""))
+	}
+	return err
+}
+
 // CreateDevice handles adding a new device
 // @Summary      Add Devices
 // @Id  		 CreateDevice
@@ -441,18 +705,12 @@ func getAllowedIPs(ip string, ip6 string, relay bool) ([]string, error) {
 // @Success      201  {object}  models.Device
 // @Failure      400  {object}  models.BaseError
 // @Failure		 401  {object}  models.BaseError
+// @Failure      403  {object}  models.QuotaExceededError
 // @Failure      409  {object}  models.ConflictsError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices [post]
+// @Router       /api/v1/devices [post]
 func (api *API) CreateDevice(c *gin.Context) {
-	ctx, span := tracer.Start(c.Request.Context(), "AddDevice")
-	defer span.End()
-
-	if !api.FlagCheck(c, "devices") {
-		return
-	}
-
 	var request models.AddDevice
 	// Call BindJSON to bind the received JSON
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -460,13 +718,45 @@ func (api *API) CreateDevice(c *gin.Context) {
 		return
 	}
 
-	if request.PublicKey == "" {
-		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("public_key"))
+	device, err := api.AddDevice(c, request)
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
 		return
 	}
+	c.JSON(http.StatusCreated, device)
+}
+
+// AddDevice registers a device of the current user, it serves CreateDevice and the gRPC API
+func (api *API) AddDevice(c *gin.Context, request models.AddDevice) (models.Device, error) {
+	ctx, span := tracer.Start(c.Request.Context(), "AddDevice")
+	defer span.End()
+
+	if err := api.flagCheck(c, "devices"); err != nil {
+		return models.Device{}, err
+	}
+
+	if request.PublicKey == "" {
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldNotPresentError("public_key"))
+	}
 	if request.VpcID == uuid.Nil {
-		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("vpc_id"))
-		return
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldNotPresentError("vpc_id"))
+	}
+	if request.Relay && request.SymmetricNat {
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("relay", relayBehindSymmetricNatReason))
+	}
+	if request.Relay && request.Unmanaged {
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("relay", "an unmanaged device can not be a relay"))
+	}
+	if request.Unmanaged && !validEndpoints(request.Endpoints) {
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("endpoints", "must be addresses with a port"))
+	}
+	if !validEndpointIPv6(request.EndpointIPv6) {
+		return models.Device{}, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("endpoint_ipv6", endpointIPv6Reason))
 	}
 
 	userId := api.GetCurrentUserID(c)
@@ -480,6 +770,9 @@ func (api *API) CreateDevice(c *gin.Context) {
 			First(&vpc, "id = ?", request.VpcID); result.Error != nil {
 			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
 		}
+		if err := api.checkFeatureFlag(c, "devices", vpc.OrganizationID); err != nil {
+			return err
+		}
 
 		res := tx.Where("public_key = ?", request.PublicKey).First(&device)
 		if res.Error == nil {
@@ -532,6 +825,17 @@ func (api *API) CreateDevice(c *gin.Context) {
 			ipamNamespace = vpc.ID
 		}
 
+		// relays listen on the well-known wireguard port, outside of the range of the organization
+		if !request.Relay && request.ListenPort != 0 && !vpc.Organization.ListenPortAllowed(request.ListenPort) {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("listen_port", "is outside of the organization's allowed listen port range"))
+		}
+		if err := checkDeviceQuota(tx, *vpc.Organization); err != nil {
+			return err
+		}
+		if err := checkChildPrefixQuota(tx, *vpc.Organization, deviceId, request.AdvertiseCidrs); err != nil {
+			return err
+		}
+
 		var relay bool
 		// determine if the node joining is a relay node
 		if request.Relay {
@@ -541,10 +845,31 @@ func (api *API) CreateDevice(c *gin.Context) {
 		var ipamIP string
 		var ipamIPv6 string
 
+		// the address reserved to the device identity is allocated in IPAM by the reservation
+		reservation, err := reservationOfDevice(tx, vpc.ID, request.PublicKey, deviceId)
+		if err != nil {
+			return err
+		}
+
 		// If this was a static address request
 		// TODO: handle a user requesting an IP not in the IPAM prefix
 		if len(request.IPv4TunnelIPs) > 1 {
 			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("tunnel_ips_v4", "can only specify a single IPv4 address request"))
+		} else if reservation != nil {
+			holder, err := deviceHoldingAddress(tx, vpc.ID, reservation.Address)
+			if err != nil {
+				return err
+			}
+			if holder != nil {
+				return NewApiResponseError(http.StatusConflict, models.NewConflictsError(holder.ID.String()))
+			}
+			ipamIP = reservation.Address
+		} else if len(request.IPv4TunnelIPs) == 1 && request.Unmanaged {
+			// an imported peer is already configured with its address, it is not replaced with one of the pool
+			ipamIP = request.IPv4TunnelIPs[0].Address
+			if err := api.ipam.AcquireIP(ctx, ipamNamespace, vpc.Ipv4Cidr, ipamIP); err != nil {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("ipv4_tunnel_ips", fmt.Sprintf("%s is not an available address of the vpc", ipamIP)))
+			}
 		} else if len(request.IPv4TunnelIPs) == 1 {
 			ipamIP, err = api.ipam.AssignSpecificTunnelIP(ctx, ipamNamespace, vpc.Ipv4Cidr, request.IPv4TunnelIPs[0].Address)
 			if err != nil {
@@ -558,9 +883,11 @@ func (api *API) CreateDevice(c *gin.Context) {
 		}
 
 		// Currently only support v4 requesting of specific addresses
-		ipamIPv6, err = api.ipam.AssignFromPool(ctx, ipamNamespace, vpc.Ipv6Cidr)
-		if err != nil {
-			return fmt.Errorf("failed to request ipam v6 address: %w", err)
+		if !request.DisableIPv6 {
+			ipamIPv6, err = api.ipam.AssignFromPool(ctx, ipamNamespace, vpc.Ipv6Cidr)
+			if err != nil {
+				return fmt.Errorf("failed to request ipam v6 address: %w", err)
+			}
 		}
 
 		// allocate a CIDR if requested
@@ -603,47 +930,58 @@ func (api *API) CreateDevice(c *gin.Context) {
 					CIDR:    vpc.Ipv4Cidr,
 				},
 			},
-			IPv6TunnelIPs: []models.TunnelIP{
-				{
-					Address: ipamIPv6,
-					CIDR:    vpc.Ipv6Cidr,
-				},
-			},
+			IPv6TunnelIPs:   []models.TunnelIP{},
 			AdvertiseCidrs:  request.AdvertiseCidrs,
 			Relay:           request.Relay,
 			SymmetricNat:    request.SymmetricNat,
+			NatType:         request.NatType,
+			NatHairpin:      request.NatHairpin,
+			EndpointIPv6:    request.EndpointIPv6,
 			Hostname:        request.Hostname,
 			Os:              request.Os,
+			ListenPort:      request.ListenPort,
+			Unmanaged:       request.Unmanaged,
 			SecurityGroupId: vpc.ID,
 			RegKeyID:        regKeyID,
 			BearerToken:     "DT:" + deviceToken.String(),
+			Pending:         vpc.Organization.RequireDeviceApproval,
+		}
+
+		device.DnsName, err = reserveDnsName(tx, device, request.DnsName)
+		if err != nil {
+			return err
+		}
+
+		if ipamIPv6 != "" {
+			device.IPv6TunnelIPs = append(device.IPv6TunnelIPs, models.TunnelIP{
+				Address: ipamIPv6,
+				CIDR:    vpc.Ipv6Cidr,
+			})
 		}
 
 		if res := tx.
 			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
 			Create(&device); res.Error != nil {
-			return res.Error
+			return dnsNameTaken(res.Error)
+		}
+		if device.Relay || device.SymmetricNat {
+			if _, err := api.assignRelays(ctx, tx, device.VpcID); err != nil {
+				return err
+			}
 		}
 		span.SetAttributes(
 			attribute.String("id", device.ID.String()),
 		)
-		return nil
+		return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionCreate, "device", device.ID, nil, auditState(device))
 	})
-
 	if err != nil {
-		var apiResponseError *ApiResponseError
-		if errors.As(err, &apiResponseError) {
-			c.JSON(apiResponseError.Status, apiResponseError.Body)
-		} else {
-			api.SendInternalServerError(c, err)
-		}
-		return
+		return models.Device{}, err
 	}
 
 	hideDeviceBearerToken(&device, tokenClaims)
 
 	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
-	c.JSON(http.StatusCreated, device)
+	return device, nil
 }
 
 // DeleteDevice handles deleting an existing device and associated ipam lease
@@ -658,7 +996,7 @@ func (api *API) CreateDevice(c *gin.Context) {
 // @Failure      400  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id} [delete]
+// @Router       /api/v1/devices/{id} [delete]
 func (api *API) DeleteDevice(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteDevice")
 	defer span.End()
@@ -702,23 +1040,29 @@ func (api *API) DeleteDevice(c *gin.Context) {
 	orgPrefix := device.IPv4TunnelIPs[0].CIDR
 	advertiseCidrs := device.AdvertiseCidrs
 
-	// Null out unique fields to that a new device can be created later with the same values
-	if res := api.db.WithContext(ctx).
-		Model(&device).
-		Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
-		Where("id = ?", device.Base.ID).
-		Updates(map[string]interface{}{
-			"bearer_token": nil,
-			"public_key":   nil,
-			"deleted_at":   gorm.DeletedAt{Time: time.Now(), Valid: true},
-		}); res.Error != nil {
-		api.SendInternalServerError(c, res.Error)
+	before := auditState(device)
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if err := api.deleteDevice(ctx, tx, &device); err != nil {
+			return err
+		}
+		return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionDelete, "device", device.ID, before, nil)
+	})
+	if err != nil {
+		api.SendInternalServerError(c, err)
 		return
 	}
 
 	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	// the services of the device are only published to the devices of its vpc
+	api.signalBus.Notify(fmt.Sprintf("/services/vpc=%s", device.VpcID.String()))
 
-	if ipamAddress != "" && orgPrefix != "" {
+	// a reserved address stays allocated for the next device of the identity
+	reserved, err := addressIsReserved(ctx, api.db, device.VpcID, ipamAddress)
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	if ipamAddress != "" && orgPrefix != "" && !reserved {
 		if err := api.ipam.ReleaseToPool(c.Request.Context(), ipamNamespace, ipamAddress, orgPrefix); err != nil {
 			api.SendInternalServerError(c, fmt.Errorf("failed to release the v4 address to pool: %w", err))
 			return
@@ -726,25 +1070,56 @@ func (api *API) DeleteDevice(c *gin.Context) {
 	}
 
 	for _, cidr := range advertiseCidrs {
-		if err := api.ipam.ReleaseCIDR(c.Request.Context(), ipamNamespace, cidr); err != nil {
+		if err := api.releaseAdvertisedCidr(c.Request.Context(), api.db, ipamNamespace, device, cidr); err != nil {
 			api.SendInternalServerError(c, fmt.Errorf("failed to release cidr: %w", err))
 			return
 		}
 	}
 
-	ipamAddressV6 := device.IPv6TunnelIPs[0].Address
-	orgPrefixV6 := device.IPv6TunnelIPs[0].CIDR
+	// devices running in IPv4 only mode do not have a v6 address to release
+	if len(device.IPv6TunnelIPs) > 0 {
+		ipamAddressV6 := device.IPv6TunnelIPs[0].Address
+		orgPrefixV6 := device.IPv6TunnelIPs[0].CIDR
 
-	if ipamAddressV6 != "" && orgPrefixV6 != "" {
-		if err := api.ipam.ReleaseToPool(c.Request.Context(), ipamNamespace, ipamAddressV6, orgPrefixV6); err != nil {
-			api.SendInternalServerError(c, fmt.Errorf("failed to release the v6 address to pool: %w", err))
-			return
+		if ipamAddressV6 != "" && orgPrefixV6 != "" {
+			if err := api.ipam.ReleaseToPool(c.Request.Context(), ipamNamespace, ipamAddressV6, orgPrefixV6); err != nil {
+				api.SendInternalServerError(c, fmt.Errorf("failed to release the v6 address to pool: %w", err))
+				return
+			}
 		}
 	}
 
+	// the reclaim job releases the allocations of the deleted devices without this mark
+	if res := api.db.WithContext(ctx).Unscoped().Model(&models.Device{}).
+		Where("id = ?", device.ID).
+		Update("ipam_released_at", time.Now()); res.Error != nil {
+		api.SendInternalServerError(c, res.Error)
+		return
+	}
+
 	c.JSON(http.StatusOK, device)
 }
 
+// releaseAdvertisedCidr releases the IPAM prefix of a cidr the device no longer advertises. The gateways of a site
+// may advertise the same prefix, which is only released once no other device of the vpc advertises it.
+func (api *API) releaseAdvertisedCidr(ctx context.Context, db *gorm.DB, namespace uuid.UUID, device models.Device, cidr string) error {
+	if util.IsDefaultIPRoute(cidr) {
+		return nil
+	}
+	var advertised []pq.StringArray
+	if res := db.WithContext(ctx).Model(&models.Device{}).
+		Where("vpc_id = ? AND id <> ?", device.VpcID, device.ID).
+		Pluck("advertise_cidrs", &advertised); res.Error != nil {
+		return res.Error
+	}
+	for _, cidrs := range advertised {
+		if slices.Contains(cidrs, cidr) {
+			return nil
+		}
+	}
+	return api.ipam.ReleaseCIDR(ctx, namespace, cidr)
+}
+
 func advertiseCidrEquals(existingPrefix, newPrefix []string) bool {
 	if len(existingPrefix) != len(newPrefix) {
 		return false
@@ -771,17 +1146,108 @@ func advertiseCidrEquals(existingPrefix, newPrefix []string) bool {
 // @Produce      json
 // @Param		 gt_revision     query  uint64   false "greater than revision"
 // @Param		 id              path   string true "VPC ID"
+// @Param		 label           query  []string false "only list the devices with this label, formatted as key=value, the devices must have all the given labels" collectionFormat(multi)
 // @Success      200  {object}  []models.Device
 // @Failure      400  {object}  models.BaseError
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id}/devices [get]
+// @Router       /api/v1/vpcs/{id}/devices [get]
 func (api *API) ListDevicesInVPC(c *gin.Context) {
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err))
+		return
+	}
+
+	labels, apiErr := parseLabelSelector(c.QueryArray("label"))
+	if apiErr != nil {
+		c.JSON(apiErr.Status, apiErr.Body)
+		return
+	}
+
+	api.sendList(c, c.Request.Context(), func(_ *gorm.DB) (fetchmgr.ResourceList, error) {
+		items, err := api.ListVPCDevices(c, vpcId, labels, func(db *gorm.DB) *gorm.DB {
+			return FilterAndPaginateWithQuery(db, &models.Device{}, c, query, "hostname")
+		})
+		return deviceList(items), err
+	})
+}
 
+// ListVPCDevices returns the devices of a vpc that have all the labels, paginate refines the query of the devices
+// when not nil. It serves ListDevicesInVPC and the gRPC API.
+func (api *API) ListVPCDevices(c *gin.Context, vpcId uuid.UUID, labels map[string]string, paginate func(db *gorm.DB) *gorm.DB) ([]*models.Device, error) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListDevicesInVPC",
 		trace.WithAttributes(
-			attribute.String("vpc_id", c.Param("id")),
+			attribute.String("vpc_id", vpcId.String()),
+		))
+	defer span.End()
+
+	if err := api.flagCheck(c, "devices"); err != nil {
+		return nil, err
+	}
+
+	var vpc models.VPC
+	db := api.db.WithContext(ctx)
+	result := api.VPCIsReadableByCurrentUser(c, db).
+		First(&vpc, "id = ?", vpcId.String())
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	tokenClaims, err := NxodusClaims(c, db)
+	if err != nil {
+		return nil, err
+	}
+
+	db = db.Where("vpc_id = ?", vpcId.String())
+	db = api.whereDeviceLabels(db, labels)
+	if paginate != nil {
+		db = paginate(db)
+	}
+
+	var items []*models.Device
+	result = db.Find(&items)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+
+	for i := range items {
+		hideDeviceBearerToken(items[i], tokenClaims)
+	}
+	return items, nil
+}
+
+// ListDevicesInOrganization lists the changes made to the devices of an Organization
+// @Summary      List Device Changes
+// @Description  Lists the devices of an organization that were added, updated or deleted since a revision. Without a revision all devices are listed.
+// @Id           ListDevicesInOrganization
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id              path   string true  "Organization ID"
+// @Param		 since           query  uint64 false "only list the changes made after this revision"
+// @Param		 vpc_id          query  string false "only list the devices of this VPC"
+// @Success      200  {object}  models.DeviceChanges
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/devices [get]
+func (api *API) ListDevicesInOrganization(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListDevicesInOrganization",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
 		))
 	defer span.End()
 
@@ -789,51 +1255,153 @@ func (api *API) ListDevicesInVPC(c *gin.Context) {
 		return
 	}
 
-	vpcId, err := uuid.Parse(c.Param("id"))
+	orgId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
 		return
 	}
-	var vpc models.VPC
-	db := api.db.WithContext(ctx)
-	result := api.VPCIsReadableByCurrentUser(c, db).
-		First(&vpc, "id = ?", vpcId.String())
+	var since uint64
+	if value := c.Query("since"); value != "" {
+		since, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewBadQueryParameterError("since"))
+			return
+		}
+	}
+	vpcId := uuid.Nil
+	if value := c.Query("vpc_id"); value != "" {
+		vpcId, err = uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewBadQueryParameterError("vpc_id"))
+			return
+		}
+	}
 
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId.String())
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, models.NewNotFoundError("vpc"))
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
 		} else {
 			api.SendInternalServerError(c, result.Error)
 		}
 		return
 	}
 
-	var query Query
-	if err := c.ShouldBindQuery(&query); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewApiError(err))
-		return
-	}
-
-	tokenClaims, err2 := NxodusClaims(c, api.db.WithContext(ctx))
+	tokenClaims, err2 := NxodusClaims(c, db)
 	if err2 != nil {
 		c.JSON(err2.Status, err2.Body)
 		return
 	}
+	if err := checkDeviceApproved(db, tokenClaims, ""); err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
 
-	api.sendList(c, ctx, func(db *gorm.DB) (fetchmgr.ResourceList, error) {
-		db = db.Where("vpc_id = ?", vpcId.String())
-		db = FilterAndPaginateWithQuery(db, &models.Device{}, c, query, "hostname")
-
-		var items deviceList
-		result := db.Find(&items)
+	// deleted devices keep their revision, which is bumped when they are deleted, so that the
+	// deletions can be listed along with the other changes.
+	// the devices pending approval are not peers of the other devices, they are listed once approved.
+	devices, err := api.cachedDevicesInOrganization(ctx, orgId, vpcId, since, func() ([]models.Device, error) {
+		var devices []models.Device
+		db := db.Unscoped().Where("organization_id = ? AND pending = ?", orgId.String(), false)
+		if vpcId != uuid.Nil {
+			db = db.Where("vpc_id = ?", vpcId.String())
+		}
+		if since == 0 {
+			db = db.Where("deleted_at IS NULL")
+		} else {
+			db = db.Where("revision > ?", since)
+		}
+		result := db.Order("revision").Find(&devices)
 		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, result.Error
 		}
+		return devices, nil
+	})
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
 
-		for i := range items {
-			hideDeviceBearerToken(items[i], tokenClaims)
+	changes := models.DeviceChanges{
+		Revision: since,
+		Devices:  []models.Device{},
+		Deleted:  []uuid.UUID{},
+	}
+	for i := range devices {
+		device := devices[i]
+		changes.Revision = max(changes.Revision, device.Revision)
+		if device.DeletedAt.Valid {
+			changes.Deleted = append(changes.Deleted, device.ID)
+			continue
 		}
-		return items, nil
-	})
+		hideDeviceBearerToken(&device, tokenClaims)
+		changes.Devices = append(changes.Devices, device)
+	}
+	c.JSON(http.StatusOK, changes)
+}
 
+// validEndpointIPv6 returns true if the IPv6 endpoint of a device is empty or a global IPv6 address and port
+// validEndpoints returns true if the endpoints are addresses with a port, the endpoints of an unmanaged device are
+// configured by the user instead of being discovered by nexd
+func validEndpoints(endpoints []models.Endpoint) bool {
+	for _, endpoint := range endpoints {
+		addrPort, err := netip.ParseAddrPort(endpoint.Address)
+		if err != nil || addrPort.Port() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func validEndpointIPv6(endpoint string) bool {
+	if endpoint == "" {
+		return true
+	}
+	addrPort, err := netip.ParseAddrPort(endpoint)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr()
+	return addr.Is6() && !addr.Is4In6() && addr.IsGlobalUnicast() && !addr.IsPrivate() && addrPort.Port() != 0
+}
+
+// deleteDevice soft deletes a device and the records referring to it, the IPAM allocations of the device are left
+// to the caller or to the reclaim job.
+func (api *API) deleteDevice(ctx context.Context, tx *gorm.DB, device *models.Device) error {
+	// Null out unique fields to that a new device can be created later with the same values
+	if res := tx.
+		Model(device).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+		Where("id = ?", device.Base.ID).
+		Updates(map[string]interface{}{
+			"bearer_token": nil,
+			"public_key":   nil,
+			"deleted_at":   gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Delete(&models.DeviceSecurityGroup{}, "device_id = ?", device.ID); res.Error != nil {
+		return res.Error
+	}
+	if err := deleteDeviceServices(tx, *device); err != nil {
+		return err
+	}
+	if err := deleteDeviceConnectivity(tx, *device); err != nil {
+		return err
+	}
+	if device.Relay {
+		// move the devices assigned to the relay to the other relays
+		if _, err := api.assignRelays(ctx, tx, device.VpcID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errDevicePending = errors.New("device is pending approval by an owner of the organization")
+
+// ApproveDevice approves a device pending approval
+// @Summary      Approve Device
+// @Description  Approves a device registered in an organization requiring device approval, the device then receives its peers and is a peer of the other devices
+// @Id           ApproveDevice
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "Device ID"
+// @Success      200  {object}  models.Device
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/approve [post]
+func (api *API) ApproveDevice(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ApproveDevice",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var device models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		// only the owners of the organization of the device may approve it
+		if res := api.CurrentUserHasRole(c, tx, "organization_id", OwnerRoles).
+			First(&device, "id = ?", deviceId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return errDeviceNotFound
+			}
+			return res.Error
+		}
+		if !device.Pending {
+			return nil
+		}
+
+		before := auditState(device)
+		device.Pending = false
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Save(&device); res.Error != nil {
+			return res.Error
+		}
+		if device.Relay || device.SymmetricNat {
+			if _, err := api.assignRelays(ctx, tx, device.VpcID); err != nil {
+				return err
+			}
+		}
+		return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionUpdate, "device", device.ID, before, auditState(device))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.Is(err, errDeviceNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	hideDeviceBearerToken(&device, nil)
+
+	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	c.JSON(http.StatusOK, device)
+}
+
+// checkDeviceApproved returns an error if the caller connects as a device pending approval, identified by its device
+// token or by the public key it connects with. Those devices are not sent the other devices of their vpc.
+func checkDeviceApproved(db *gorm.DB, claims *models.NexodusClaims, publicKey string) error {
+	db = db.Model(&models.Device{}).Where("pending = ?", true)
+	switch {
+	case claims != nil && claims.Scope == "device-token":
+		db = db.Where("id = ?", claims.ID)
+	case claims != nil && claims.Scope == "reg-token" && claims.DeviceID != uuid.Nil:
+		db = db.Where("id = ?", claims.DeviceID)
+	case publicKey != "":
+		db = db.Where("public_key = ?", publicKey)
+	default:
+		return nil
+	}
+	var count int64
+	if res := db.Count(&count); res.Error != nil {
+		return res.Error
+	}
+	if count > 0 {
+		return NewApiResponseError(http.StatusForbidden, models.NewApiError(errDevicePending))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestApproveDevice() {
+	require := suite.Require()
+
+	require.NoError(suite.api.db.Model(&models.Organization{}).
+		Where("id = ?", suite.testUserID).
+		Update("require_device_approval", true).Error)
+
+	resBody, err := json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "apendingpubkey",
+	})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(resBody),
+	)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+	require.True(device.Pending)
+
+	listed := func() bool {
+		_, res, err := suite.ServeRequest(
+			http.MethodGet, "/:id", fmt.Sprintf("/%s", suite.testUserID),
+			suite.api.ListDevicesInOrganization, nil,
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+		var changes models.DeviceChanges
+		require.NoError(json.Unmarshal(body, &changes))
+		for _, d := range changes.Devices {
+			if d.ID == device.ID {
+				return true
+			}
+		}
+		return false
+	}
+	// the pending device is not a peer of the other devices
+	require.False(listed())
+
+	_, res, err = suite.ServeRequest(
+		http.MethodPost, "/:id", fmt.Sprintf("/%s", device.ID),
+		suite.api.ApproveDevice, nil,
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &device))
+	require.False(device.Pending)
+	require.True(listed())
+
+	_, res, err = suite.ServeRequest(
+		http.MethodPost, "/:id", fmt.Sprintf("/%s", uuid.New()),
+		suite.api.ApproveDevice, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxBatchDevices is the number of device ids a batch request can list
+const maxBatchDevices = 1000
+
+// BatchDeleteDevices deletes several devices
+// @Summary      Delete Devices
+// @Description  Deletes several devices and releases their IPAM allocations, either by id or all the devices of an organization
+// @Id           BatchDeleteDevices
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BatchDeleteDevices  true "Devices to delete"
+// @Success      200  {object}  models.BatchDeviceResult
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/batch-delete [post]
+func (api *API) BatchDeleteDevices(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "BatchDeleteDevices")
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	var request models.BatchDeleteDevices
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var devices []models.Device
+	result := models.BatchDeviceResult{
+		Succeeded: []uuid.UUID{},
+		Failed:    []models.BatchDeviceFailure{},
+	}
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		devices, result.Failed, err = api.batchDevices(c, tx, request.DeviceIDs, request.OrganizationID)
+		if err != nil {
+			return err
+		}
+
+		relayVpcs := map[uuid.UUID]struct{}{}
+		for _, device := range devices {
+			before := auditState(device)
+			// null out the unique fields so that a new device can be created later with the same values
+			if res := tx.
+				Model(&device).
+				Where("id = ?", device.ID).
+				Updates(map[string]interface{}{
+					"bearer_token": nil,
+					"public_key":   nil,
+					"deleted_at":   gorm.DeletedAt{Time: time.Now(), Valid: true},
+				}); res.Error != nil {
+				return res.Error
+			}
+			if res := tx.Delete(&models.DeviceSecurityGroup{}, "device_id = ?", device.ID); res.Error != nil {
+				return res.Error
+			}
+			if err := deleteDeviceServices(tx, device); err != nil {
+				return err
+			}
+			if err := deleteDeviceConnectivity(tx, device); err != nil {
+				return err
+			}
+			if device.Relay {
+				relayVpcs[device.VpcID] = struct{}{}
+			}
+			if err := api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionDelete, "device", device.ID, before, nil); err != nil {
+				return err
+			}
+		}
+		// move the devices assigned to the deleted relays to the other relays
+		for vpcId := range relayVpcs {
+			if _, err := api.assignRelays(ctx, tx, vpcId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyDeviceVpcs(devices)
+
+	// the reclaim job releases the allocations that fail to release here
+	db := api.db.WithContext(ctx)
+	for _, device := range devices {
+		result.Succeeded = append(result.Succeeded, device.ID)
+		api.signalBus.Notify(fmt.Sprintf("/services/vpc=%s", device.VpcID.String()))
+		d, err := staleDeviceLeases(db, device, staleLeaseDeleted)
+		if err != nil {
+			api.logger.Errorf("failed to find the ipam leases of deleted device %s: %v", device.ID, err)
+			continue
+		}
+		api.releaseStaleLeases(ctx, db, d)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BatchUpdateDeviceLabels sets labels on several devices
+// @Summary      Label Devices
+// @Description  Sets labels on several devices, either by id or all the devices of an organization. A label with an empty value is removed.
+// @Id           BatchUpdateDeviceLabels
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BatchUpdateDeviceLabels  true "Devices and labels"
+// @Success      200  {object}  models.BatchDeviceResult
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/batch-labels [post]
+func (api *API) BatchUpdateDeviceLabels(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "BatchUpdateDeviceLabels")
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	var request models.BatchUpdateDeviceLabels
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if len(request.Labels) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("labels"))
+		return
+	}
+	// the keys and values are the same for all the devices, reject invalid ones before selecting the devices
+	if err := applyDeviceLabels(&models.Device{}, request.Labels); err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	var updated []models.Device
+	result := models.BatchDeviceResult{
+		Succeeded: []uuid.UUID{},
+		Failed:    []models.BatchDeviceFailure{},
+	}
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		devices, failed, err := api.batchDevices(c, tx, request.DeviceIDs, request.OrganizationID)
+		if err != nil {
+			return err
+		}
+		result.Failed = failed
+
+		for _, device := range devices {
+			before := auditState(device)
+			if err := applyDeviceLabels(&device, request.Labels); err != nil {
+				// the labels are valid, the device would have too many
+				result.Failed = append(result.Failed, models.BatchDeviceFailure{
+					DeviceID: device.ID,
+					Error:    fmt.Sprintf("a device can have at most %d labels", maxDeviceLabels),
+				})
+				continue
+			}
+			if res := tx.
+				Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+				Select("labels").
+				Updates(&device); res.Error != nil {
+				return res.Error
+			}
+			if err := api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionUpdate, "device", device.ID, before, auditState(device)); err != nil {
+				return err
+			}
+			updated = append(updated, device)
+			result.Succeeded = append(result.Succeeded, device.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyDeviceVpcs(updated)
+	c.JSON(http.StatusOK, result)
+}
+
+// batchDevices returns the devices a batch request selects: the listed devices of the current user, or all the
+// devices of an organization the current user owns. Listed devices that are not found are returned as failures.
+func (api *API) batchDevices(c *gin.Context, tx *gorm.DB, deviceIds []uuid.UUID, organizationId uuid.UUID) ([]models.Device, []models.BatchDeviceFailure, error) {
+	failed := []models.BatchDeviceFailure{}
+	if organizationId != uuid.Nil {
+		if len(deviceIds) > 0 {
+			return nil, nil, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("device_ids", "must not be set with organization_id"))
+		}
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).First(&org, "id = ?", organizationId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return nil, nil, NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+			}
+			return nil, nil, res.Error
+		}
+		var devices []models.Device
+		if res := tx.Where("organization_id = ?", org.ID).Find(&devices); res.Error != nil {
+			return nil, nil, res.Error
+		}
+		return devices, failed, nil
+	}
+
+	if len(deviceIds) == 0 {
+		return nil, nil, NewApiResponseError(http.StatusBadRequest, models.NewFieldNotPresentError("device_ids"))
+	}
+	if len(deviceIds) > maxBatchDevices {
+		return nil, nil, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("device_ids", fmt.Sprintf("must list at most %d devices", maxBatchDevices)))
+	}
+	var devices []models.Device
+	if res := api.DeviceIsOwnedByCurrentUser(c, tx).Where("id IN ?", deviceIds).Find(&devices); res.Error != nil {
+		return nil, nil, res.Error
+	}
+	found := map[uuid.UUID]struct{}{}
+	for _, device := range devices {
+		found[device.ID] = struct{}{}
+	}
+	for _, id := range deviceIds {
+		if _, ok := found[id]; !ok {
+			failed = append(failed, models.BatchDeviceFailure{DeviceID: id, Error: errDeviceNotFound.Error()})
+			// an id listed twice is only reported once
+			found[id] = struct{}{}
+		}
+	}
+	return devices, failed, nil
+}
+
+// notifyDeviceVpcs notifies the watchers of the vpcs of the devices once per vpc
+func (api *API) notifyDeviceVpcs(devices []models.Device) {
+	vpcs := map[uuid.UUID]struct{}{}
+	for _, device := range devices {
+		if _, notified := vpcs[device.VpcID]; notified {
+			continue
+		}
+		vpcs[device.VpcID] = struct{}{}
+		api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	}
+}
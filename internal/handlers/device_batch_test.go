@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestBatchDevices() {
+	require := suite.Require()
+
+	var ids []uuid.UUID
+	for i := 0; i < 2; i++ {
+		resBody, err := json.Marshal(models.AddDevice{
+			VpcID:     suite.testUserID,
+			PublicKey: fmt.Sprintf("abatchpubkey%d", i),
+		})
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/", "/",
+			suite.api.CreateDevice, bytes.NewBuffer(resBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+		var device models.Device
+		require.NoError(json.Unmarshal(body, &device))
+		ids = append(ids, device.ID)
+	}
+	unknown := uuid.New()
+
+	batch := func(handler func(c *gin.Context), request any) (int, models.BatchDeviceResult) {
+		resBody, err := json.Marshal(request)
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost, "/", "/",
+			handler, bytes.NewBuffer(resBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		var result models.BatchDeviceResult
+		if res.Code == http.StatusOK {
+			require.NoError(json.Unmarshal(body, &result))
+		}
+		return res.Code, result
+	}
+
+	code, result := batch(suite.api.BatchUpdateDeviceLabels, models.BatchUpdateDeviceLabels{
+		DeviceIDs: append(ids, unknown),
+		Labels:    map[string]string{"environment": "test"},
+	})
+	require.Equal(http.StatusOK, code)
+	require.ElementsMatch(ids, result.Succeeded)
+	require.Equal([]models.BatchDeviceFailure{{DeviceID: unknown, Error: errDeviceNotFound.Error()}}, result.Failed)
+	for _, id := range ids {
+		var device models.Device
+		require.NoError(suite.api.db.First(&device, "id = ?", id).Error)
+		require.Equal("test", device.Labels["environment"])
+	}
+
+	code, _ = batch(suite.api.BatchUpdateDeviceLabels, models.BatchUpdateDeviceLabels{
+		DeviceIDs: ids,
+		Labels:    map[string]string{"not a key": "test"},
+	})
+	require.Equal(http.StatusBadRequest, code)
+
+	code, _ = batch(suite.api.BatchDeleteDevices, models.BatchDeleteDevices{})
+	require.Equal(http.StatusBadRequest, code)
+	code, _ = batch(suite.api.BatchDeleteDevices, models.BatchDeleteDevices{DeviceIDs: ids, OrganizationID: suite.testUserID})
+	require.Equal(http.StatusBadRequest, code)
+	code, _ = batch(suite.api.BatchDeleteDevices, models.BatchDeleteDevices{OrganizationID: uuid.New()})
+	require.Equal(http.StatusNotFound, code)
+
+	code, result = batch(suite.api.BatchDeleteDevices, models.BatchDeleteDevices{DeviceIDs: append(ids, unknown)})
+	require.Equal(http.StatusOK, code)
+	require.ElementsMatch(ids, result.Succeeded)
+	require.Len(result.Failed, 1)
+	for _, id := range ids {
+		var device models.Device
+		require.NoError(suite.api.db.Unscoped().First(&device, "id = ?", id).Error)
+		require.True(device.DeletedAt.Valid)
+		require.NotNil(device.IpamReleasedAt)
+	}
+}
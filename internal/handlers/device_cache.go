@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// deviceCachePrefix is the prefix of the redis keys of the device cache
+const deviceCachePrefix = "device-cache:"
+
+// deviceCacheGlobal is the revision bumped when the organizations of the changed devices are not known,
+// it invalidates the cached devices of every organization.
+const deviceCacheGlobal = "global"
+
+// deviceCacheChangesKey is the context key of the organizations whose devices a transaction changed
+type deviceCacheChangesKey struct{}
+
+// deviceCacheChanges collects the organizations whose devices a transaction changed, their cached devices are
+// invalidated once the transaction completes, so that no request caches what the transaction had not committed yet.
+type deviceCacheChanges struct {
+	mu            sync.Mutex
+	organizations map[string]struct{}
+}
+
+func (c *deviceCacheChanges) add(organizations []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, org := range organizations {
+		c.organizations[org] = struct{}{}
+	}
+}
+
+// cachedDevice is the redis entry of a device read by GetDevice, the entries are gob encoded since the json
+// encoding of the devices leaves out the fields that are not returned by the api.
+type cachedDevice struct {
+	Revision string // empty when the revision was not known before the device was read
+	Device   models.Device
+}
+
+// EnableDeviceCache caches the devices read by ListDevicesInOrganization and GetDevice in redis for the ttl.
+// The entries are keyed by the revision of the organization of the devices in the cache, which is bumped
+// whenever a device of the organization is written, so the cached devices are never served once they changed.
+func (api *API) EnableDeviceCache(ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	if api.Redis == nil {
+		return errors.New("the device cache requires redis, set --device-cache-ttl to 0")
+	}
+	callbacks := api.db.Callback()
+	if err := callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("nexodus:device_cache", api.deviceCacheCallback); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("nexodus:device_cache", api.deviceCacheCallback); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register("nexodus:device_cache", api.deviceCacheCallback); err != nil {
+		return err
+	}
+
+	next := api.transaction
+	api.transaction = func(ctx context.Context, fn func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+		changes := &deviceCacheChanges{organizations: map[string]struct{}{}}
+		err := next(context.WithValue(ctx, deviceCacheChangesKey{}, changes), fn, opts...)
+		if len(changes.organizations) > 0 {
+			organizations := make([]string, 0, len(changes.organizations))
+			for org := range changes.organizations {
+				organizations = append(organizations, org)
+			}
+			api.invalidateDeviceCache(ctx, organizations)
+		}
+		return err
+	}
+	api.deviceCacheTTL = ttl
+	return nil
+}
+
+// deviceCacheCallback invalidates the cached devices of the organizations of the devices a statement wrote
+func (api *API) deviceCacheCallback(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.Table != "devices" {
+		return
+	}
+	organizations := deviceOrganizations(db.Statement.ReflectValue)
+	if organizations == nil {
+		organizations = []string{deviceCacheGlobal}
+	}
+	if changes, ok := db.Statement.Context.Value(deviceCacheChangesKey{}).(*deviceCacheChanges); ok {
+		changes.add(organizations)
+		return
+	}
+	api.invalidateDeviceCache(db.Statement.Context, organizations)
+}
+
+// deviceOrganizations returns the organizations of the devices of a statement, nil when the statement does not
+// tell, like the updates and deletes of the devices matching a condition.
+func deviceOrganizations(value reflect.Value) []string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		device, ok := value.Interface().(models.Device)
+		if !ok || device.OrganizationID == uuid.Nil {
+			return nil
+		}
+		return []string{device.OrganizationID.String()}
+	case reflect.Slice, reflect.Array:
+		var organizations []string
+		for i := 0; i < value.Len(); i++ {
+			orgs := deviceOrganizations(value.Index(i))
+			if orgs == nil {
+				return nil
+			}
+			organizations = append(organizations, orgs...)
+		}
+		return organizations
+	}
+	return nil
+}
+
+// invalidateDeviceCache bumps the revisions of the organizations, the cached devices of older revisions are
+// not read anymore and expire.
+func (api *API) invalidateDeviceCache(ctx context.Context, organizations []string) {
+	// the cache is invalidated even if the request was canceled meanwhile
+	ctx = context.WithoutCancel(ctx)
+	pipe := api.Redis.Pipeline()
+	for _, org := range organizations {
+		pipe.Incr(ctx, deviceCachePrefix+"revision:"+org)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		api.logger.Warnf("failed to invalidate the device cache of the organizations %v: %v", organizations, err)
+	}
+}
+
+// deviceCacheRevision returns the revision the devices of the organization are cached with, it combines the
+// revision of the organization with the global revision.
+func (api *API) deviceCacheRevision(ctx context.Context, orgId uuid.UUID) (string, error) {
+	revisions, err := api.Redis.MGet(ctx, deviceCachePrefix+"revision:"+deviceCacheGlobal, deviceCachePrefix+"revision:"+orgId.String()).Result()
+	if err != nil {
+		return "", err
+	}
+	revision := ""
+	for _, r := range revisions {
+		if r == nil {
+			r = "0"
+		}
+		revision += fmt.Sprintf(".%v", r)
+	}
+	return revision[1:], nil
+}
+
+// cachedDevicesInOrganization returns the devices that ListDevicesInOrganization cached for the query, the load
+// function reads and caches them on a cache miss. On redis errors the devices are read from the database.
+func (api *API) cachedDevicesInOrganization(ctx context.Context, orgId, vpcId uuid.UUID, since uint64, load func() ([]models.Device, error)) ([]models.Device, error) {
+	if api.deviceCacheTTL == 0 {
+		return load()
+	}
+	revision, err := api.deviceCacheRevision(ctx, orgId)
+	if err != nil {
+		api.logger.Warnf("failed to read the device cache revision: %v", err)
+		return load()
+	}
+	key := fmt.Sprintf("%slist:%s:%s:%s:%d", deviceCachePrefix, orgId, revision, vpcId, since)
+	if data, err := api.Redis.Get(ctx, key).Bytes(); err == nil {
+		var devices []models.Device
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&devices); err == nil {
+			return devices, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		api.logger.Warnf("failed to read the device cache: %v", err)
+	}
+
+	devices, err := load()
+	if err != nil {
+		return nil, err
+	}
+	api.storeDeviceCache(ctx, key, devices)
+	return devices, nil
+}
+
+// cachedDevice returns the device that GetDevice cached, false when it is not cached or it changed since. The
+// current revision of the organization of a cached device is returned either way, the device is cached with it
+// once it is read again. The organization of a device is only known once it was read, so a device is only served
+// from the cache from its third read on.
+func (api *API) cachedDevice(ctx context.Context, id uuid.UUID) (models.Device, string, bool) {
+	if api.deviceCacheTTL == 0 {
+		return models.Device{}, "", false
+	}
+	data, err := api.Redis.Get(ctx, deviceCachePrefix+"device:"+id.String()).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			api.logger.Warnf("failed to read the device cache: %v", err)
+		}
+		return models.Device{}, "", false
+	}
+	var entry cachedDevice
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return models.Device{}, "", false
+	}
+	revision, err := api.deviceCacheRevision(ctx, entry.Device.OrganizationID)
+	if err != nil {
+		api.logger.Warnf("failed to read the device cache revision: %v", err)
+		return models.Device{}, "", false
+	}
+	if revision != entry.Revision {
+		return models.Device{}, revision, false
+	}
+	return entry.Device, revision, true
+}
+
+// cacheDevice caches a device read by GetDevice with the revision of its organization read before the device,
+// a device cached without the revision is not served but remembers the organization of the device.
+func (api *API) cacheDevice(ctx context.Context, revision string, device models.Device) {
+	if api.deviceCacheTTL == 0 {
+		return
+	}
+	api.storeDeviceCache(ctx, deviceCachePrefix+"device:"+device.ID.String(), cachedDevice{
+		Revision: revision,
+		Device:   device,
+	})
+}
+
+func (api *API) storeDeviceCache(ctx context.Context, key string, value interface{}) {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(value); err != nil {
+		api.logger.Warnf("failed to encode the device cache entry: %v", err)
+		return
+	}
+	if err := api.Redis.Set(ctx, key, data.Bytes(), api.deviceCacheTTL).Err(); err != nil {
+		api.logger.Warnf("failed to write the device cache: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/database"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDeviceOrganizations(t *testing.T) {
+	require := require.New(t)
+	orgId := uuid.New()
+	device := models.Device{OrganizationID: orgId}
+	require.Equal([]string{orgId.String()}, deviceOrganizations(reflect.ValueOf(&device)))
+	require.Equal([]string{orgId.String(), orgId.String()}, deviceOrganizations(reflect.ValueOf([]models.Device{device, device})))
+
+	// the organizations of the devices are not known
+	require.Nil(deviceOrganizations(reflect.ValueOf(&models.Device{})))
+	require.Nil(deviceOrganizations(reflect.ValueOf([]models.Device{device, {}})))
+	require.Nil(deviceOrganizations(reflect.ValueOf(&models.VPC{})))
+}
+
+func TestCachedDeviceEncoding(t *testing.T) {
+	require := require.New(t)
+	now := time.Now().UTC()
+	device := models.Device{
+		Base:           models.Base{ID: uuid.New(), DeletedAt: gorm.DeletedAt{Time: now, Valid: true}},
+		OrganizationID: uuid.New(),
+		RegKeyID:       uuid.New(),
+		BearerToken:    "DT:token",
+		AllowedIPs:     []string{"100.64.0.1/32"},
+		Labels:         map[string]string{"rack": "r1"},
+		OnlineAt:       &now,
+	}
+
+	// the fields the api does not return are cached too
+	var data bytes.Buffer
+	require.NoError(gob.NewEncoder(&data).Encode(cachedDevice{Revision: "1.2", Device: device}))
+	var entry cachedDevice
+	require.NoError(gob.NewDecoder(&data).Decode(&entry))
+	require.Equal("1.2", entry.Revision)
+	require.Equal(device.OrganizationID, entry.Device.OrganizationID)
+	require.Equal(device.RegKeyID, entry.Device.RegKeyID)
+	require.True(entry.Device.DeletedAt.Valid)
+	require.Equal(device.Labels, entry.Device.Labels)
+}
+
+func TestDeviceCacheChanges(t *testing.T) {
+	require := require.New(t)
+	db, err := gorm.Open(sqlite.Open("file:devicecache?mode=memory"), &gorm.Config{})
+	require.NoError(err)
+	require.NoError(db.AutoMigrate(&models.Device{}))
+	transaction, _, err := database.GetTransactionFunc(db)
+	require.NoError(err)
+
+	api := &API{
+		logger:      zap.NewNop().Sugar(),
+		db:          db,
+		transaction: transaction,
+		// the cache is not reachable, the revisions are only collected
+		Redis: redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: time.Millisecond}),
+	}
+	require.NoError(api.EnableDeviceCache(time.Minute))
+
+	orgId := uuid.New()
+	var changed []string
+	err = api.transaction(context.Background(), func(tx *gorm.DB) error {
+		device := models.Device{OrganizationID: orgId, PublicKey: "key"}
+		if err := tx.Create(&device).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Device{}).Where("public_key = ?", "key").Update("hostname", "host").Error; err != nil {
+			return err
+		}
+		changes := tx.Statement.Context.Value(deviceCacheChangesKey{}).(*deviceCacheChanges)
+		changed = maps.Keys(changes.organizations)
+		return nil
+	})
+	require.NoError(err)
+	require.ElementsMatch([]string{orgId.String(), deviceCacheGlobal}, changed)
+
+	// without the cache the devices are read from the database
+	devices, err := api.cachedDevicesInOrganization(context.Background(), orgId, uuid.Nil, 0, func() ([]models.Device, error) {
+		return []models.Device{{PublicKey: "key"}}, nil
+	})
+	require.NoError(err)
+	require.Len(devices, 1)
+	_, _, cached := api.cachedDevice(context.Background(), uuid.New())
+	require.False(cached)
+}
+
+func TestWithoutRedis(t *testing.T) {
+	require := require.New(t)
+	api := &API{logger: zap.NewNop().Sugar()}
+
+	// the dev mode runs without redis, the device cache can not be enabled and the users are not cached
+	require.NoError(api.EnableDeviceCache(0))
+	require.Error(api.EnableDeviceCache(time.Minute))
+	api.deleteCachedUser(context.Background(), "idp-user")
+
+	// the devices connected to this apiserver are tracked in memory
+	tracker, err := New(zap.NewNop(), true)
+	require.NoError(err)
+	require.NoError(tracker.connected("key"))
+	require.NoError(tracker.connected("key"))
+	require.NoError(tracker.disconnected("key"))
+	connected, err := tracker.isConnected("key")
+	require.NoError(err)
+	require.True(connected)
+	require.NoError(tracker.disconnected("key"))
+	connected, err = tracker.isConnected("key")
+	require.NoError(err)
+	require.False(connected)
+}
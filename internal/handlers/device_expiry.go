@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+// deviceExpiryInterval is how often the devices are checked against the device ttl of their organization
+const deviceExpiryInterval = time.Hour
+
+// expireDevices flags the devices not seen for the device ttl of their organization. The devices of organizations
+// that delete expired devices are deleted instead, and their IPAM allocations released.
+func (api *API) expireDevices(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "expireDevices")
+	defer span.End()
+
+	db := api.db.WithContext(ctx)
+	var orgs []models.Organization
+	if res := db.Where("device_ttl_days > ?", 0).Find(&orgs); res.Error != nil {
+		api.logger.Errorf("failed to list the organizations with a device ttl: %v", res.Error)
+		return
+	}
+
+	for _, org := range orgs {
+		cutoff := time.Now().Add(-time.Duration(org.DeviceTTLDays) * 24 * time.Hour)
+		var devices []models.Device
+		if res := db.
+			Where("organization_id = ? AND online = ? AND unmanaged = ? AND (last_seen < ? OR (last_seen IS NULL AND created_at < ?))", org.ID, false, false, cutoff, cutoff).
+			Find(&devices); res.Error != nil {
+			api.logger.Errorf("failed to list the devices of organization %s not seen since %v: %v", org.ID, cutoff, res.Error)
+			continue
+		}
+
+		for _, device := range devices {
+			if org.DeleteExpiredDevices {
+				if err := api.deleteStaleDevice(ctx, device); err != nil {
+					api.logger.Errorf("failed to delete expired device %s: %v", device.ID, err)
+					continue
+				}
+				api.logger.Infof("deleted device %s not seen for %d days", device.ID, org.DeviceTTLDays)
+				d, err := staleDeviceLeases(db, device, staleLeaseDeleted)
+				if err != nil {
+					// the reclaim job releases the allocations of the deleted device
+					api.logger.Errorf("failed to find the ipam leases of expired device %s: %v", device.ID, err)
+					continue
+				}
+				api.releaseStaleLeases(ctx, db, d)
+			} else if device.ExpiredAt == nil {
+				if err := api.flagExpiredDevice(ctx, device); err != nil {
+					api.logger.Errorf("failed to flag expired device %s: %v", device.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// flagExpiredDevice marks a device as expired, the device keeps its peers and IPAM allocations.
+func (api *API) flagExpiredDevice(ctx context.Context, device models.Device) error {
+	before := auditState(device)
+	now := time.Now()
+	device.ExpiredAt = &now
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.Model(&device).
+			Where("id = ? AND expired_at IS NULL", device.ID).
+			Update("expired_at", device.ExpiredAt); res.Error != nil {
+			return res.Error
+		}
+		return recordSystemAuditEvent(tx, device.OrganizationID, models.AuditActionExpire, "device", device.ID, before, auditState(device))
+	})
+	if err != nil {
+		return err
+	}
+	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	return nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestExpireDevices() {
+	require := suite.Require()
+	db := suite.api.db
+
+	resBody, err := json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "anexpiringpubkey",
+	})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(resBody),
+	)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+
+	// an unmanaged device does not run nexd and is never seen
+	resBody, err = json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "anunmanagedpubkey",
+		Unmanaged: true,
+	})
+	require.NoError(err)
+	_, res, err = suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(resBody),
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+
+	var unmanaged models.Device
+	require.NoError(json.Unmarshal(body, &unmanaged))
+	require.True(unmanaged.Unmanaged)
+
+	lastSeen := time.Now().Add(-31 * 24 * time.Hour)
+	require.NoError(db.Model(&models.Device{}).Where("id IN ?", []uuid.UUID{device.ID, unmanaged.ID}).Update("last_seen", lastSeen).Error)
+	defer func() {
+		require.NoError(db.Model(&models.Organization{}).
+			Where("id = ?", suite.testUserID).
+			Updates(map[string]interface{}{"device_ttl_days": 0, "delete_expired_devices": false}).Error)
+	}()
+
+	// devices do not expire without a device ttl
+	suite.api.expireDevices(context.Background())
+	require.NoError(db.First(&device, "id = ?", device.ID).Error)
+	require.Nil(device.ExpiredAt)
+
+	require.NoError(db.Model(&models.Organization{}).Where("id = ?", suite.testUserID).Update("device_ttl_days", 30).Error)
+	suite.api.expireDevices(context.Background())
+	require.NoError(db.First(&device, "id = ?", device.ID).Error)
+	require.NotNil(device.ExpiredAt)
+	require.NoError(db.First(&unmanaged, "id = ?", unmanaged.ID).Error)
+	require.Nil(unmanaged.ExpiredAt)
+
+	var events []models.AuditEvent
+	require.NoError(db.Where("resource_id = ? AND action = ?", device.ID, models.AuditActionExpire).Find(&events).Error)
+	require.Len(events, 1)
+	require.Equal(uuid.Nil, events[0].ActorID)
+
+	// the device is only flagged once
+	suite.api.expireDevices(context.Background())
+	require.NoError(db.Where("resource_id = ? AND action = ?", device.ID, models.AuditActionExpire).Find(&events).Error)
+	require.Len(events, 1)
+
+	require.NoError(db.Model(&models.Organization{}).Where("id = ?", suite.testUserID).Update("delete_expired_devices", true).Error)
+	suite.api.expireDevices(context.Background())
+	require.NoError(db.Unscoped().First(&device, "id = ?", device.ID).Error)
+	require.True(device.DeletedAt.Valid)
+	require.NotNil(device.IpamReleasedAt)
+}
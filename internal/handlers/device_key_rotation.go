@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RotateDeviceKey replaces the public key of a device
+// @Summary      Rotate Device Key
+// @Description  Replaces the wireguard public key of a device, the device keeps its id, tunnel addresses and security group, and its peers are sent the new key
+// @Id           RotateDeviceKey
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "Device ID"
+// @Param        key  body      models.RotateDeviceKey  true "Device Key"
+// @Success      200  {object}  models.Device
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      403  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/rotate-key [post]
+func (api *API) RotateDeviceKey(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "RotateDeviceKey",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var request models.RotateDeviceKey
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.PublicKey == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("public_key"))
+		return
+	}
+	if _, err := wgtypes.ParseKey(request.PublicKey); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("public_key", "must be a wireguard public key"))
+		return
+	}
+
+	var device models.Device
+	var tokenClaims *models.NexodusClaims
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", deviceId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return errDeviceNotFound
+			}
+			return res.Error
+		}
+
+		var err2 *ApiResponseError
+		tokenClaims, err2 = NxodusClaims(c, tx)
+		if err2 != nil {
+			return err2
+		}
+		// a device authenticated by a token may only rotate its own key
+		if tokenClaims != nil {
+			switch tokenClaims.Scope {
+			case "reg-token":
+				if tokenClaims.ID != device.RegKeyID.String() {
+					return NewApiResponseError(http.StatusForbidden, models.NewApiError(errors.New("reg key does not have access")))
+				}
+			case "device-token":
+				if tokenClaims.ID != device.ID.String() {
+					return NewApiResponseError(http.StatusForbidden, models.NewApiError(errors.New("device token does not have access")))
+				}
+			}
+		}
+
+		if request.PublicKey == device.PublicKey {
+			return nil
+		}
+		var other models.Device
+		res := tx.Where("public_key = ?", request.PublicKey).First(&other)
+		if res.Error == nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(other.ID.String()))
+		}
+		if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return res.Error
+		}
+
+		// the addresses reserved to the old key stay reserved to the device
+		if res := tx.Model(&models.IPReservation{}).
+			Where("vpc_id = ? AND public_key = ?", device.VpcID, device.PublicKey).
+			Update("public_key", request.PublicKey); res.Error != nil {
+			return res.Error
+		}
+
+		before := auditState(device)
+		device.PublicKey = request.PublicKey
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Save(&device); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionUpdate, "device", device.ID, before, auditState(device))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.Is(err, errDeviceNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	// the device token is sealed with the new key
+	hideDeviceBearerToken(&device, tokenClaims)
+
+	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	c.JSON(http.StatusOK, device)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestRotateDeviceKey() {
+	require := suite.Require()
+
+	rotate := func(id uuid.UUID, publicKey string) (int, []byte) {
+		reqBody, err := json.Marshal(models.RotateDeviceKey{PublicKey: publicKey})
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost, "/:id", fmt.Sprintf("/%s", id),
+			suite.api.RotateDeviceKey, bytes.NewBuffer(reqBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	oldKey := suite.newPublicKey()
+	device := suite.createDevice(models.AddDevice{PublicKey: oldKey})
+	other := suite.createDevice(models.AddDevice{PublicKey: suite.newPublicKey()})
+
+	reservation := models.IPReservation{
+		VpcID:          device.VpcID,
+		OrganizationID: device.OrganizationID,
+		PublicKey:      oldKey,
+		Address:        device.IPv4TunnelIPs[0].Address,
+	}
+	require.NoError(suite.api.db.Create(&reservation).Error)
+
+	rotatedKey := suite.newPublicKey()
+	code, body := rotate(device.ID, rotatedKey)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var rotated models.Device
+	require.NoError(json.Unmarshal(body, &rotated))
+	require.Equal(device.ID, rotated.ID)
+	require.Equal(rotatedKey, rotated.PublicKey)
+	require.Equal(device.IPv4TunnelIPs, rotated.IPv4TunnelIPs)
+
+	// the reservation follows the new key
+	require.NoError(suite.api.db.First(&reservation, "id = ?", reservation.ID).Error)
+	require.Equal(rotatedKey, reservation.PublicKey)
+
+	// the key of another device can not be used
+	code, body = rotate(device.ID, other.PublicKey)
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+
+	code, body = rotate(device.ID, "notakey")
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+
+	code, body = rotate(uuid.New(), suite.newPublicKey())
+	require.Equal(http.StatusNotFound, code, "HTTP error: %s", string(body))
+}
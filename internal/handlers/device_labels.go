@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/database"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxDeviceLabels is the number of labels a device can have
+	maxDeviceLabels = 64
+	// maxLabelValueLength is the longest value a device label can have
+	maxLabelValueLength = 255
+)
+
+// labelKeyPattern matches the keys of device labels, e.g. rack, owner or inventory.example.com/asset-tag
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+
+// applyDeviceLabels merges the labels of an update into the labels of the device, a label with an empty
+// value is removed.
+func applyDeviceLabels(device *models.Device, labels map[string]string) error {
+	result := map[string]string{}
+	for key, value := range device.Labels {
+		result[key] = value
+	}
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("labels", fmt.Sprintf("%q is not a valid label key", key)))
+		}
+		if len(value) > maxLabelValueLength {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("labels", fmt.Sprintf("the value of %q must be at most %d characters", key, maxLabelValueLength)))
+		}
+		if value == "" {
+			delete(result, key)
+		} else {
+			result[key] = value
+		}
+	}
+	if len(result) > maxDeviceLabels {
+		return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("labels", fmt.Sprintf("a device can have at most %d labels", maxDeviceLabels)))
+	}
+	device.Labels = result
+	return nil
+}
+
+// parseLabelSelector parses the label query parameters of a device list, each formatted as key=value
+func parseLabelSelector(labels []string) (map[string]string, *ApiResponseError) {
+	selector := map[string]string{}
+	for _, label := range labels {
+		key, value, found := strings.Cut(label, "=")
+		if !found || !labelKeyPattern.MatchString(key) {
+			return nil, NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("label", "must be formatted as key=value"))
+		}
+		selector[key] = value
+	}
+	return selector, nil
+}
+
+// whereDeviceLabels restricts a device query to the devices that have all the labels of the selector
+func (api *API) whereDeviceLabels(db *gorm.DB, selector map[string]string) *gorm.DB {
+	for key, value := range selector {
+		if api.dialect == database.DialectSqlLite {
+			db = db.Where("json_extract(labels, ?) = ?", fmt.Sprintf(`$."%s"`, key), value)
+		} else {
+			db = db.Where("labels ->> ? = ?", key, value)
+		}
+	}
+	return db
+}
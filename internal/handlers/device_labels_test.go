@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestDeviceLabels() {
+	require := suite.Require()
+
+	resBody, err := json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "alabeledpubkey",
+	})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(resBody),
+	)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+
+	update := func(labels map[string]string) int {
+		resBody, err := json.Marshal(models.UpdateDevice{Labels: labels})
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPatch, "/:id", fmt.Sprintf("/%s", device.ID),
+			suite.api.UpdateDevice, bytes.NewBuffer(resBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		if res.Code == http.StatusOK {
+			// unmarshalling into the device would merge the labels maps
+			var updated models.Device
+			require.NoError(json.Unmarshal(body, &updated))
+			device = updated
+		}
+		return res.Code
+	}
+	list := func(query string) (int, []models.Device) {
+		_, res, err := suite.ServeRequest(
+			http.MethodGet, "/", "/?"+query,
+			suite.api.ListDevices, nil,
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		var devices []models.Device
+		if res.Code == http.StatusOK {
+			require.NoError(json.Unmarshal(body, &devices))
+		}
+		return res.Code, devices
+	}
+
+	require.Equal(http.StatusOK, update(map[string]string{"rack": "r1", "environment": "prod"}))
+	require.Equal(map[string]string{"rack": "r1", "environment": "prod"}, device.Labels)
+
+	// labels are merged, an empty value removes a label
+	require.Equal(http.StatusOK, update(map[string]string{"owner": "team-a", "environment": ""}))
+	require.Equal(map[string]string{"rack": "r1", "owner": "team-a"}, device.Labels)
+
+	code, devices := list("label=rack%3Dr1&label=owner%3Dteam-a")
+	require.Equal(http.StatusOK, code)
+	require.Len(devices, 1)
+	require.Equal(device.ID, devices[0].ID)
+
+	code, devices = list("label=rack%3Dr2")
+	require.Equal(http.StatusOK, code)
+	require.Empty(devices)
+
+	code, _ = list("label=rack")
+	require.Equal(http.StatusBadRequest, code)
+
+	require.Equal(http.StatusBadRequest, update(map[string]string{"not a key": "value"}))
+}
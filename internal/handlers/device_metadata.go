@@ -26,7 +26,7 @@ import (
 // @Produce      json
 // @Success      200  {object}  []models.DeviceMetadata
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id}/metadata [get]
+// @Router       /api/v1/devices/{id}/metadata [get]
 func (api *API) ListDeviceMetadata(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListDeviceMetadata", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -104,7 +104,7 @@ func (api *API) ListDeviceMetadata(c *gin.Context) {
 // @Produce      json
 // @Success      200  {object}  []models.DeviceMetadata
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id}/metadata [get]
+// @Router       /api/v1/vpcs/{id}/metadata [get]
 func (api *API) ListMetadataInVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListMetadataInVPC",
 		trace.WithAttributes(
@@ -197,7 +197,7 @@ func (d deviceMetadataList) Len() int {
 // @Produce      json
 // @Success      200  {object}  models.DeviceMetadata
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id}/metadata/{key} [get]
+// @Router       /api/v1/devices/{id}/metadata/{key} [get]
 func (api *API) GetDeviceMetadataKey(c *gin.Context) {
 
 	ctx, span := tracer.Start(c.Request.Context(), "GetDeviceMetadataKey", trace.WithAttributes(
@@ -252,7 +252,7 @@ func (api *API) GetDeviceMetadataKey(c *gin.Context) {
 // @Produce      json
 // @Success      200  {object}  models.DeviceMetadata
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id}/metadata/{key} [put]
+// @Router       /api/v1/devices/{id}/metadata/{key} [put]
 func (api *API) UpdateDeviceMetadataKey(c *gin.Context) {
 
 	ctx, span := tracer.Start(c.Request.Context(), "UpdateDeviceMetadataKey", trace.WithAttributes(
@@ -280,6 +280,7 @@ func (api *API) UpdateDeviceMetadataKey(c *gin.Context) {
 	}
 
 	var device models.Device
+	relaysChanged := false
 	err = api.transaction(ctx, func(tx *gorm.DB) error {
 		result := api.DeviceIsOwnedByCurrentUser(c, tx).
 			First(&device, "id = ?", deviceId)
@@ -290,7 +291,17 @@ func (api *API) UpdateDeviceMetadataKey(c *gin.Context) {
 		result = tx.
 			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
 			Save(&metadataInstance)
-		return result.Error
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if key == relayMetadataKey || key == relayLatencyMetadataKey {
+			// the load of a relay or the latency of a device to the relays changed
+			var err error
+			relaysChanged, err = api.assignRelays(ctx, tx, device.VpcID)
+			return err
+		}
+		return nil
 	})
 
 	if err != nil {
@@ -304,6 +315,9 @@ func (api *API) UpdateDeviceMetadataKey(c *gin.Context) {
 
 	signalChannel := fmt.Sprintf("/metadata/vpc=%s", device.VpcID.String())
 	api.signalBus.Notify(signalChannel)
+	if relaysChanged {
+		api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	}
 	c.JSON(http.StatusOK, metadataInstance)
 
 }
@@ -316,7 +330,7 @@ func (api *API) UpdateDeviceMetadataKey(c *gin.Context) {
 // @Param        id   path      string  true "Device ID"
 // @Success      204
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id}/metadata [delete]
+// @Router       /api/v1/devices/{id}/metadata [delete]
 func (api *API) DeleteDeviceMetadata(c *gin.Context) {
 
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteDeviceMetadata", trace.WithAttributes(
@@ -363,7 +377,7 @@ func (api *API) DeleteDeviceMetadata(c *gin.Context) {
 // @Param        key  path      string  false "Metadata Key"
 // @Success      204
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/devices/{id}/metadata/{key} [delete]
+// @Router       /api/v1/devices/{id}/metadata/{key} [delete]
 func (api *API) DeleteDeviceMetadataKey(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteDeviceMetadataKey", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttachDeviceSecurityGroup attaches an additional security group to a device
+// @Summary      Attach Device Security Group
+// @Description  Attaches an additional security group to a device, the rules of all the security groups of the device are applied to it
+// @Id           AttachDeviceSecurityGroup
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id                 path      string  true "Device ID"
+// @Param        security_group_id  path      string  true "Security Group ID"
+// @Success      200  {object}  models.Device
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/security-groups/{security_group_id} [put]
+func (api *API) AttachDeviceSecurityGroup(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AttachDeviceSecurityGroup",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+			attribute.String("security_group_id", c.Param("security_group_id")),
+		))
+	defer span.End()
+
+	if !api.FlagCheck(c, "security-groups") {
+		return
+	}
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	secGroupId, err := uuid.Parse(c.Param("security_group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("security_group_id"))
+		return
+	}
+
+	var device models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", deviceId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return errDeviceNotFound
+			}
+			return res.Error
+		}
+
+		var sg models.SecurityGroup
+		if res := api.SecurityGroupIsReadableByCurrentUser(c, tx).
+			First(&sg, "id = ?", secGroupId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("security_group"))
+		}
+		if sg.VpcId != device.VpcID {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("security_group_id", "is not in the vpc of the device"))
+		}
+		if sg.ID == device.SecurityGroupId || slices.Contains(device.SecurityGroupIds, sg.ID.String()) {
+			// the security group already applies to the device
+			return nil
+		}
+
+		before := auditState(device)
+		if res := tx.Create(&models.DeviceSecurityGroup{
+			DeviceID:        device.ID,
+			SecurityGroupID: sg.ID,
+		}); res.Error != nil {
+			return res.Error
+		}
+		device.SecurityGroupIds = append(device.SecurityGroupIds, sg.ID.String())
+		return api.updateDeviceSecurityGroupIds(c, tx, &device, before)
+	})
+	api.sendDeviceSecurityGroupsResponse(c, &device, err)
+}
+
+// DetachDeviceSecurityGroup detaches an additional security group from a device
+// @Summary      Detach Device Security Group
+// @Description  Detaches an additional security group from a device
+// @Id           DetachDeviceSecurityGroup
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id                 path      string  true "Device ID"
+// @Param        security_group_id  path      string  true "Security Group ID"
+// @Success      200  {object}  models.Device
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/security-groups/{security_group_id} [delete]
+func (api *API) DetachDeviceSecurityGroup(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DetachDeviceSecurityGroup",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+			attribute.String("security_group_id", c.Param("security_group_id")),
+		))
+	defer span.End()
+
+	if !api.FlagCheck(c, "security-groups") {
+		return
+	}
+
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	secGroupId, err := uuid.Parse(c.Param("security_group_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("security_group_id"))
+		return
+	}
+
+	var device models.Device
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", deviceId); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return errDeviceNotFound
+			}
+			return res.Error
+		}
+		if !slices.Contains(device.SecurityGroupIds, secGroupId.String()) {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("security_group"))
+		}
+
+		before := auditState(device)
+		if res := tx.Delete(&models.DeviceSecurityGroup{}, "device_id = ? AND security_group_id = ?", device.ID, secGroupId); res.Error != nil {
+			return res.Error
+		}
+		device.SecurityGroupIds = slices.DeleteFunc(device.SecurityGroupIds, func(id string) bool {
+			return id == secGroupId.String()
+		})
+		return api.updateDeviceSecurityGroupIds(c, tx, &device, before)
+	})
+	api.sendDeviceSecurityGroupsResponse(c, &device, err)
+}
+
+// updateDeviceSecurityGroupIds stores the denormalized security group ids of the device, which bumps its
+// revision so that the change is sent to the agents watching the vpc.
+func (api *API) updateDeviceSecurityGroupIds(c *gin.Context, tx *gorm.DB, device *models.Device, before map[string]interface{}) error {
+	if res := tx.Model(device).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+		Select("security_group_ids").
+		Updates(device); res.Error != nil {
+		return res.Error
+	}
+	return api.recordAuditEvent(c, tx, device.OrganizationID, models.AuditActionUpdate, "device", device.ID, before, auditState(*device))
+}
+
+func (api *API) sendDeviceSecurityGroupsResponse(c *gin.Context, device *models.Device, err error) {
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.Is(err, errDeviceNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	hideDeviceBearerToken(device, nil)
+	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	c.JSON(http.StatusOK, device)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/nexodus-io/nexodus/internal/models"
@@ -60,6 +61,108 @@ func (suite *HandlerTestSuite) TestCreateGetDevice() {
 	assert.Equal(actual, device)
 }
 
+func (suite *HandlerTestSuite) TestRenameDevice() {
+	require := suite.Require()
+
+	rename := func(device models.Device, displayName string) (int, models.Device) {
+		resBody, err := json.Marshal(models.UpdateDevice{DisplayName: &displayName})
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPatch, "/:id", fmt.Sprintf("/%s", device.ID),
+			suite.api.UpdateDevice, bytes.NewBuffer(resBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		var updated models.Device
+		if res.Code == http.StatusOK {
+			require.NoError(json.Unmarshal(body, &updated))
+		}
+		return res.Code, updated
+	}
+
+	first := suite.createDevice(models.AddDevice{PublicKey: "arenamepubkey1", Hostname: "rename-host"})
+	second := suite.createDevice(models.AddDevice{PublicKey: "arenamepubkey2", Hostname: "rename-host"})
+	require.Equal("rename-host", first.DnsName)
+	require.Equal("rename-host-2", second.DnsName)
+
+	code, first := rename(first, "Build Server")
+	require.Equal(http.StatusOK, code)
+	require.Equal("Build Server", first.DisplayName)
+	require.Equal("build-server", first.DnsName)
+
+	// the dns names stay unique when devices are given the same name
+	code, second = rename(second, " Build Server ")
+	require.Equal(http.StatusOK, code)
+	require.Equal("Build Server", second.DisplayName)
+	require.Equal("build-server-2", second.DnsName)
+
+	// an empty name reverts to the hostname
+	code, first = rename(first, "")
+	require.Equal(http.StatusOK, code)
+	require.Empty(first.DisplayName)
+	require.Equal("rename-host", first.DnsName)
+
+	code, _ = rename(first, strings.Repeat("a", maxDisplayNameLength+1))
+	require.Equal(http.StatusBadRequest, code)
+
+	// the database rejects a dns name reserved concurrently by another device of the organization
+	res := suite.api.db.Model(&models.Device{}).Where("id = ?", second.ID).Update("dns_name", first.DnsName)
+	require.Error(res.Error)
+}
+
+func (suite *HandlerTestSuite) TestCreateUnmanagedDevice() {
+	require := suite.Require()
+
+	createDevice := func(request models.AddDevice) (int, []byte) {
+		request.VpcID = suite.testUserID
+		request.Unmanaged = true
+		resBody, err := json.Marshal(request)
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/", "/",
+			suite.api.CreateDevice, bytes.NewBuffer(resBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	// an imported peer keeps its address and endpoint
+	code, body := createDevice(models.AddDevice{
+		PublicKey:     "animportedpubkey1",
+		IPv4TunnelIPs: []models.TunnelIP{{Address: "100.64.20.20"}},
+		Endpoints:     []models.Endpoint{{Source: "static", Address: "203.0.113.30:51820"}},
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+	require.True(device.Unmanaged)
+	require.Equal("100.64.20.20", device.IPv4TunnelIPs[0].Address)
+	require.Equal("203.0.113.30:51820", device.Endpoints[0].Address)
+
+	// the address is not replaced with another one when it is taken
+	code, body = createDevice(models.AddDevice{
+		PublicKey:     "animportedpubkey2",
+		IPv4TunnelIPs: []models.TunnelIP{{Address: "100.64.20.20"}},
+	})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+
+	code, body = createDevice(models.AddDevice{
+		PublicKey: "animportedpubkey3",
+		Endpoints: []models.Endpoint{{Source: "static", Address: "vpn.example.com"}},
+	})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+
+	code, body = createDevice(models.AddDevice{
+		PublicKey: "animportedpubkey4",
+		Relay:     true,
+	})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+}
+
 func TestAdvertiseCidrEquals(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -100,3 +203,77 @@ func TestAdvertiseCidrEquals(t *testing.T) {
 		})
 	}
 }
+
+func TestValidEndpointIPv6(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(validEndpointIPv6(""))
+	assert.True(validEndpointIPv6("[2001:db8::1]:51820"))
+	assert.False(validEndpointIPv6("2001:db8::1"))
+	assert.False(validEndpointIPv6("[2001:db8::1]:0"))
+	assert.False(validEndpointIPv6("192.0.2.1:51820"))
+	assert.False(validEndpointIPv6("[::ffff:192.0.2.1]:51820"))
+	assert.False(validEndpointIPv6("[fe80::1]:51820"))
+	assert.False(validEndpointIPv6("[fd00::1]:51820"))
+}
+
+func (suite *HandlerTestSuite) TestDeviceListenPortRange() {
+	require := suite.Require()
+	min, max := 51820, 51830
+	code, body := suite.serve(http.MethodPatch, "/:id", fmt.Sprintf("/%s", suite.testUserID), suite.api.UpdateOrganization, models.UpdateOrganization{
+		ListenPortMin: &min,
+		ListenPortMax: &max,
+	})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+
+	// a device can not register with a listen port outside of the range of the organization
+	code, body = suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+		VpcID:      suite.testUserID,
+		PublicKey:  "alistenportpubkey1",
+		ListenPort: 40000,
+	})
+	require.Equal(http.StatusBadRequest, code)
+	var validationError models.ValidationError
+	require.NoError(json.Unmarshal(body, &validationError))
+	require.Equal("listen_port", validationError.Field)
+
+	code, body = suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+		VpcID:      suite.testUserID,
+		PublicKey:  "alistenportpubkey1",
+		ListenPort: 51825,
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+	require.Equal(51825, device.ListenPort)
+
+	update := func(request models.UpdateDevice) (int, []byte) {
+		return suite.serve(http.MethodPatch, "/:id", fmt.Sprintf("/%s", device.ID), suite.api.UpdateDevice, request)
+	}
+	listenPort := 40000
+	code, body = update(models.UpdateDevice{ListenPort: &listenPort})
+	require.Equal(http.StatusBadRequest, code)
+	require.NoError(json.Unmarshal(body, &validationError))
+	require.Equal("listen_port", validationError.Field)
+
+	listenPort = 51830
+	code, body = update(models.UpdateDevice{ListenPort: &listenPort})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &device))
+	require.Equal(51830, device.ListenPort)
+
+	// relays listen on the well-known wireguard port, outside of the range
+	relay := true
+	listenPort = 51819
+	code, body = update(models.UpdateDevice{Relay: &relay, ListenPort: &listenPort})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &device))
+	require.Equal(51819, device.ListenPort)
+
+	code, body = suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+		VpcID:      suite.testUserID,
+		PublicKey:  "alistenportpubkey2",
+		Relay:      true,
+		ListenPort: 51819,
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+}
@@ -0,0 +1,521 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/miekg/dns"
+	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	dnsRecordTypeA     = "A"
+	dnsRecordTypeAAAA  = "AAAA"
+	dnsRecordTypeCNAME = "CNAME"
+)
+
+type dnsRecordList []*models.DNSRecord
+
+func (d dnsRecordList) Item(i int) (any, uint64, gorm.DeletedAt) {
+	item := d[i]
+	return item, item.Revision, item.DeletedAt
+}
+
+func (d dnsRecordList) Len() int {
+	return len(d)
+}
+
+// validateDNSRecordName returns the name of a record in the zone of the organization in its canonical form, the
+// name is one or more lowercase DNS labels.
+func validateDNSRecordName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" || len(name) > 253 {
+		return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("name", "must be a DNS name in the zone of the organization"))
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !util.IsValidDNSLabel(label) {
+			return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("name", "must be a DNS name in the zone of the organization"))
+		}
+	}
+	return name, nil
+}
+
+// validateDNSRecordValue returns the value of a record of the type in its canonical form. The target of a CNAME
+// record without a dot is a name in the zone of the organization.
+func validateDNSRecordValue(recordType, value string) (string, error) {
+	switch recordType {
+	case dnsRecordTypeA, dnsRecordTypeAAAA:
+		addr, err := netip.ParseAddr(value)
+		if err != nil || addr.Zone() != "" || (recordType == dnsRecordTypeA) != addr.Is4() {
+			family := "ipv6"
+			if recordType == dnsRecordTypeA {
+				family = "ipv4"
+			}
+			return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("value", fmt.Sprintf("must be an %s address", family)))
+		}
+		return addr.String(), nil
+	case dnsRecordTypeCNAME:
+		value = strings.ToLower(strings.TrimSuffix(value, "."))
+		if _, ok := dns.IsDomainName(value); !ok || value == "" {
+			return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("value", "must be a DNS name"))
+		}
+		return value, nil
+	default:
+		return "", NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("type", "must be A, AAAA or CNAME"))
+	}
+}
+
+// dnsRecordConflict returns the id of the record, device or service of the organization that the record can not be
+// added next to, or uuid.Nil. A name can have several A and AAAA records, but a CNAME record must be the only
+// record of its name.
+func dnsRecordConflict(tx *gorm.DB, orgId uuid.UUID, record models.DNSRecord) (uuid.UUID, error) {
+	var existing []models.DNSRecord
+	if res := tx.Where("organization_id = ? AND name = ? AND id != ?", orgId, record.Name, record.ID).
+		Find(&existing); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	if len(existing) == 0 {
+		// the devices and the services can not share the name with the records
+		return dnsNameOwner(tx, models.Device{OrganizationID: orgId}, record.Name)
+	}
+	for _, other := range existing {
+		if record.Type == dnsRecordTypeCNAME || other.Type == dnsRecordTypeCNAME {
+			return other.ID, nil
+		}
+		if other.Type == record.Type && other.Value == record.Value {
+			return other.ID, nil
+		}
+	}
+	return uuid.Nil, nil
+}
+
+// ListDNSRecords lists the records of the overlay DNS zone of an organization
+// @Summary      List DNS Records
+// @Description  Lists the custom records of the overlay DNS zone of the organization
+// @Id           ListDNSRecords
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  []models.DNSRecord
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/dns-records [get]
+func (api *API) ListDNSRecords(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListDNSRecords",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	records := []models.DNSRecord{}
+	db = FilterAndPaginate(db.Where("organization_id = ?", org.ID), &models.DNSRecord{}, c, "name")
+	if result := db.Find(&records); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching dns records from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// ListDNSRecordsInVPC lists the DNS records of the organization of a VPC
+// @Summary      List DNS Records in a VPC
+// @Description  Lists the custom records of the overlay DNS zone of the organization of a VPC
+// @Id  		 ListDNSRecordsInVPC
+// @Tags         VPC
+// @Accepts		 json
+// @Produce      json
+// @Param		 gt_revision       query     uint64 false "greater than revision"
+// @Param        id                path      string  true "VPC ID"
+// @Success      200  {object}  []models.DNSRecord
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/vpcs/{id}/dns-records [get]
+func (api *API) ListDNSRecordsInVPC(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListDNSRecordsInVPC",
+		trace.WithAttributes(
+			attribute.String("vpc_id", c.Param("id")),
+		))
+	defer span.End()
+
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var vpc models.VPC
+	db := api.db.WithContext(ctx)
+	result := api.VPCIsReadableByCurrentUser(c, db).
+		First(&vpc, "id = ?", vpcId.String())
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("vpc"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err))
+		return
+	}
+
+	api.sendList(c, ctx, func(db *gorm.DB) (fetchmgr.ResourceList, error) {
+		var items dnsRecordList
+		db = db.Where("organization_id = ?", vpc.OrganizationID.String())
+		db = FilterAndPaginateWithQuery(db, &models.DNSRecord{}, c, query, "id")
+		result := db.Find(&items)
+		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, result.Error
+		}
+		return items, nil
+	})
+}
+
+// GetDNSRecord gets a record of the overlay DNS zone of an organization
+// @Summary      Get DNS Record
+// @Description  Gets a custom record of the overlay DNS zone of the organization by ID
+// @Id           GetDNSRecord
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id         path      string true "Organization ID"
+// @Param		 record_id  path      string true "DNS Record ID"
+// @Success      200  {object}  models.DNSRecord
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/dns-records/{record_id} [get]
+func (api *API) GetDNSRecord(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetDNSRecord",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("record_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	recordId, err := uuid.Parse(c.Param("record_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("record_id"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	if res := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId); res.Error != nil {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+	var record models.DNSRecord
+	if res := db.First(&record, "id = ? AND organization_id = ?", recordId, org.ID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("dns record"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// CreateDNSRecord adds a record to the overlay DNS zone of an organization
+// @Summary      Create a DNS Record
+// @Description  Adds an A, AAAA or CNAME record to the overlay DNS zone of the organization, the agents serve it next to the names of the devices
+// @Id           CreateDNSRecord
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id         path   string true "Organization ID"
+// @Param        DNSRecord  body   models.AddDNSRecord  true  "Add DNS Record"
+// @Success      201  {object}  models.DNSRecord
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/dns-records [post]
+func (api *API) CreateDNSRecord(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateDNSRecord",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddDNSRecord
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("name"))
+		return
+	}
+	if request.Value == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("value"))
+		return
+	}
+
+	var record models.DNSRecord
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+
+		name, err := validateDNSRecordName(request.Name)
+		if err != nil {
+			return err
+		}
+		recordType := strings.ToUpper(request.Type)
+		value, err := validateDNSRecordValue(recordType, request.Value)
+		if err != nil {
+			return err
+		}
+		record = models.DNSRecord{
+			OrganizationID: org.ID,
+			Name:           name,
+			Type:           recordType,
+			Value:          value,
+			Description:    request.Description,
+		}
+		conflict, err := dnsRecordConflict(tx, org.ID, record)
+		if err != nil {
+			return err
+		}
+		if conflict != uuid.Nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(conflict.String()))
+		}
+
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Create(&record); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", record.ID.String()))
+		api.logger.Infof("New dns record [ %s %s %s ] in organization [ %s ]", record.Name, record.Type, record.Value, org.ID)
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionCreate, "dns_record", record.ID, nil, auditState(record))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, record.OrganizationID, "/dns-records/vpc=%s")
+	c.JSON(http.StatusCreated, record)
+}
+
+// UpdateDNSRecord updates a record of the overlay DNS zone of an organization
+// @Summary      Update DNS Record
+// @Description  Updates the value or the description of a record, the name and the type of a record can not be changed
+// @Id           UpdateDNSRecord
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id         path      string true "Organization ID"
+// @Param		 record_id  path      string true "DNS Record ID"
+// @Param		 update body models.UpdateDNSRecord true "DNS Record Update"
+// @Success      200  {object}  models.DNSRecord
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/dns-records/{record_id} [patch]
+func (api *API) UpdateDNSRecord(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateDNSRecord",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("record_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	recordId, err := uuid.Parse(c.Param("record_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("record_id"))
+		return
+	}
+
+	var request models.UpdateDNSRecord
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var record models.DNSRecord
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&record, "id = ? AND organization_id = ?", recordId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("dns record"))
+		}
+
+		before := auditState(record)
+		if request.Value != nil {
+			value, err := validateDNSRecordValue(record.Type, *request.Value)
+			if err != nil {
+				return err
+			}
+			record.Value = value
+			conflict, err := dnsRecordConflict(tx, org.ID, record)
+			if err != nil {
+				return err
+			}
+			if conflict != uuid.Nil {
+				return NewApiResponseError(http.StatusConflict, models.NewConflictsError(conflict.String()))
+			}
+		}
+		if request.Description != nil {
+			record.Description = *request.Description
+		}
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Save(&record); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionUpdate, "dns_record", record.ID, before, auditState(record))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, record.OrganizationID, "/dns-records/vpc=%s")
+	c.JSON(http.StatusOK, record)
+}
+
+// DeleteDNSRecord deletes a record of the overlay DNS zone of an organization
+// @Summary      Delete DNS Record
+// @Description  Deletes a custom record of the overlay DNS zone of the organization
+// @Id           DeleteDNSRecord
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id         path      string true "Organization ID"
+// @Param		 record_id  path      string true "DNS Record ID"
+// @Success      200  {object}  models.DNSRecord
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/dns-records/{record_id} [delete]
+func (api *API) DeleteDNSRecord(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteDNSRecord",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("record_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	recordId, err := uuid.Parse(c.Param("record_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("record_id"))
+		return
+	}
+
+	var record models.DNSRecord
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&record, "id = ? AND organization_id = ?", recordId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("dns record"))
+		}
+		if res := tx.Delete(&record); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionDelete, "dns_record", record.ID, auditState(record), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, record.OrganizationID, "/dns-records/vpc=%s")
+	c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestDNSRecords() {
+	require := suite.Require()
+
+	create := func(request models.AddDNSRecord) (int, []byte) {
+		reqBody, err := json.Marshal(request)
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/:id/dns-records", fmt.Sprintf("/%s/dns-records", suite.testUserID),
+			suite.api.CreateDNSRecord, bytes.NewBuffer(reqBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	code, body := create(models.AddDNSRecord{Name: "WWW", Type: "a", Value: "100.100.0.10"})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var record models.DNSRecord
+	require.NoError(json.Unmarshal(body, &record))
+	require.Equal("www", record.Name)
+	require.Equal("A", record.Type)
+
+	// a name can have several addresses
+	code, body = create(models.AddDNSRecord{Name: "www", Type: "A", Value: "100.100.0.11"})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddDNSRecord{Name: "www", Type: "A", Value: "100.100.0.11"})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+
+	// a CNAME record is the only record of its name
+	code, body = create(models.AddDNSRecord{Name: "www", Type: "CNAME", Value: "web.example.com"})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddDNSRecord{Name: "db.prod", Type: "CNAME", Value: "node1."})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var cname models.DNSRecord
+	require.NoError(json.Unmarshal(body, &cname))
+	require.Equal("node1", cname.Value)
+	code, body = create(models.AddDNSRecord{Name: "db.prod", Type: "AAAA", Value: "200::10"})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+
+	for _, request := range []models.AddDNSRecord{
+		{Name: "not a name", Type: "A", Value: "100.100.0.10"},
+		{Name: "v6", Type: "A", Value: "200::10"},
+		{Name: "v4", Type: "AAAA", Value: "100.100.0.10"},
+		{Name: "mail", Type: "MX", Value: "mail.example.com"},
+	} {
+		code, body = create(request)
+		require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+	}
+
+	// the names are shared with the services of the organization
+	require.NoError(suite.api.db.Create(&models.Service{
+		OrganizationID: suite.testUserID,
+		Name:           "postgres",
+		Port:           5432,
+		Protocol:       "tcp",
+	}).Error)
+	code, body = create(models.AddDNSRecord{Name: "postgres", Type: "A", Value: "100.100.0.20"})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+
+	value := "100.100.0.12"
+	reqBody, err := json.Marshal(models.UpdateDNSRecord{Value: &value})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPatch,
+		"/:id/dns-records/:record_id", fmt.Sprintf("/%s/dns-records/%s", suite.testUserID, record.ID),
+		suite.api.UpdateDNSRecord, bytes.NewBuffer(reqBody),
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &record))
+	require.Equal(value, record.Value)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/:id/dns-records", fmt.Sprintf("/%s/dns-records", suite.testUserID),
+		suite.api.ListDNSRecordsInVPC, nil,
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+	var records []models.DNSRecord
+	require.NoError(json.Unmarshal(body, &records))
+	require.Len(records, 3)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodDelete,
+		"/:id/dns-records/:record_id", fmt.Sprintf("/%s/dns-records/%s", suite.testUserID, cname.ID),
+		suite.api.DeleteDNSRecord, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/:id/dns-records/:record_id", fmt.Sprintf("/%s/dns-records/%s", suite.testUserID, cname.ID),
+		suite.api.GetDNSRecord, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, res.Code)
+}
@@ -49,6 +49,9 @@ func (api *API) Check(ctx context.Context, checkReq *auth.CheckRequest) (*auth.C
 		} else if strings.HasPrefix(authorizationHeader, "Bearer ST:") {
 			token := strings.TrimPrefix(authorizationHeader, "Bearer ")
 			return checkSiteToken(ctx, api, token)
+		} else if strings.HasPrefix(authorizationHeader, "Bearer "+APIKeyPrefix) {
+			token := strings.TrimPrefix(authorizationHeader, "Bearer ")
+			return checkAPIKey(ctx, api, token)
 		}
 		return okResponse, nil
 	}
@@ -279,6 +282,37 @@ func checkDeviceToken(ctx context.Context, api *API, token string) (*auth.CheckR
 
 }
 
+func checkAPIKey(ctx context.Context, api *API, token string) (*auth.CheckResponse, error) {
+	jwttoken, err := api.APIKeyJWT(ctx, token)
+	if err != nil {
+		message := "internal server error"
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			if body, ok := apiResponseError.Body.(models.BaseError); ok {
+				message = body.Error
+			}
+		}
+		return denyCheckResponse(401, models.NewBaseError(message))
+	}
+
+	return &auth.CheckResponse{
+		Status: &status.Status{Code: int32(codes.OK)},
+		HttpResponse: &auth.CheckResponse_OkResponse{
+			OkResponse: &auth.OkHttpResponse{
+				Headers: []*core.HeaderValueOption{
+					{
+						AppendAction: core.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+						Header: &core.HeaderValue{
+							Key:   "authorization",
+							Value: "Bearer " + jwttoken,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 func denyCheckResponse(statusCode int, baseError models.BaseError) (*auth.CheckResponse, error) {
 	data, err := json.Marshal(baseError)
 	if err != nil {
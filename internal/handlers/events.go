@@ -52,15 +52,8 @@ func init() {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpc/{id}/events [post]
+// @Router       /api/v1/vpc/{id}/events [post]
 func (api *API) WatchEvents(c *gin.Context) {
-
-	ctx, span := tracer.Start(c.Request.Context(), "WatchEvents",
-		trace.WithAttributes(
-			attribute.String("vpc_id", c.Param("id")),
-		))
-	defer span.End()
-
 	var query struct {
 		PublicKey string `form:"public_key"`
 	}
@@ -82,25 +75,60 @@ func (api *API) WatchEvents(c *gin.Context) {
 		return
 	}
 
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		api.SendInternalServerError(c, fmt.Errorf("streaming unsupported"))
+		return
+	}
+	err = api.StreamEvents(c, vpcId, query.PublicKey, request, func() {
+		c.Header("Content-Type", "application/json;stream=watch")
+		c.Writer.WriteHeader(http.StatusOK)
+		flusher.Flush()
+	}, func(event models.WatchEvent) error {
+		_ = json.NewEncoder(c.Writer).Encode(event)
+		_, _ = c.Writer.Write([]byte("\n"))
+		flusher.Flush() // sends the result to the client (forces Transfer-Encoding: chunked)
+		return nil
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+	}
+}
+
+// StreamEvents sends the resource change events of a vpc, it serves WatchEvents and the gRPC API. The watches are
+// validated before start is called, the events are then sent until the request is canceled, an error event was
+// sent or send fails.
+func (api *API) StreamEvents(c *gin.Context, vpcId uuid.UUID, publicKey string, request []models.Watch, start func(), send func(models.WatchEvent) error) error {
+
+	ctx, span := tracer.Start(c.Request.Context(), "WatchEvents",
+		trace.WithAttributes(
+			attribute.String("vpc_id", vpcId.String()),
+		))
+	defer span.End()
+
 	var vpc models.VPC
 	db := api.db.WithContext(ctx)
 	db = api.VPCIsReadableByCurrentUser(c, db)
 	result := db.First(&vpc, "id = ?", vpcId.String())
 
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
+	}
 	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, models.NewNotFoundError("vpc"))
-		} else {
-			api.SendInternalServerError(c, result.Error)
-		}
-		return
+		return result.Error
 	}
 
 	tokenClaims, err2 := NxodusClaims(c, api.db.WithContext(ctx))
 	if err2 != nil {
-		c.JSON(err2.Status, err2.Body)
-
-		return
+		return err2
+	}
+	if err := checkDeviceApproved(api.db.WithContext(ctx), tokenClaims, publicKey); err != nil {
+		return err
 	}
 
 	var closers []func()
@@ -114,8 +142,8 @@ func (api *API) WatchEvents(c *gin.Context) {
 		switch r.Kind {
 
 		case "device":
-			if !api.FlagCheck(c, "devices") {
-				return
+			if err := api.flagCheck(c, "devices"); err != nil {
+				return err
 			}
 
 			fetcher := api.fetchManager.Open("org-devices:"+vpcId.String(), deviceCacheSize, func(db *gorm.DB, gtRevision uint64) (fetchmgr.ResourceList, error) {
@@ -124,7 +152,8 @@ func (api *API) WatchEvents(c *gin.Context) {
 				if gtRevision != 0 {
 					db = db.Where("revision > ?", gtRevision)
 				}
-				db = db.Where("vpc_id = ?", vpcId.String())
+				// the devices pending approval are not peers of the other devices
+				db = db.Where("vpc_id = ? AND pending = ?", vpcId.String(), false)
 				result := db.Find(&items)
 				if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
 					return nil, result.Error
@@ -148,8 +177,8 @@ func (api *API) WatchEvents(c *gin.Context) {
 
 		case "site":
 
-			if !api.FlagCheck(c, "sites") {
-				return
+			if err := api.flagCheck(c, "sites"); err != nil {
+				return err
 			}
 
 			fetcher := api.fetchManager.Open("org-sites:"+vpcId.String(), deviceCacheSize, func(db *gorm.DB, gtRevision uint64) (fetchmgr.ResourceList, error) {
@@ -182,8 +211,8 @@ func (api *API) WatchEvents(c *gin.Context) {
 			})
 
 		case "security-group":
-			if !api.FlagCheck(c, "security-groups") {
-				return
+			if err := api.flagCheck(c, "security-groups"); err != nil {
+				return err
 			}
 
 			watches = append(watches, Watch{
@@ -206,10 +235,56 @@ func (api *API) WatchEvents(c *gin.Context) {
 				},
 			})
 
+		case "service":
+			if err := api.flagCheck(c, "devices"); err != nil {
+				return err
+			}
+
+			watches = append(watches, Watch{
+				kind:       r.Kind,
+				gtRevision: r.GtRevision,
+				atTail:     r.AtTail,
+				signal:     fmt.Sprintf("/services/vpc=%s", vpcId.String()),
+				fetch: func(db *gorm.DB, gtRevision uint64) (fetchmgr.ResourceList, error) {
+					var items serviceList
+					db = db.Unscoped().Limit(100).Order("revision")
+					if gtRevision != 0 {
+						db = db.Where("revision > ?", gtRevision)
+					}
+					db = db.Where("organization_id = ?", vpc.OrganizationID.String())
+					result := db.Find(&items)
+					if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+						return nil, result.Error
+					}
+					return items, nil
+				},
+			})
+
+		case "dns-record":
+			watches = append(watches, Watch{
+				kind:       r.Kind,
+				gtRevision: r.GtRevision,
+				atTail:     r.AtTail,
+				signal:     fmt.Sprintf("/dns-records/vpc=%s", vpcId.String()),
+				fetch: func(db *gorm.DB, gtRevision uint64) (fetchmgr.ResourceList, error) {
+					var items dnsRecordList
+					db = db.Unscoped().Limit(100).Order("revision")
+					if gtRevision != 0 {
+						db = db.Where("revision > ?", gtRevision)
+					}
+					db = db.Where("organization_id = ?", vpc.OrganizationID.String())
+					result := db.Find(&items)
+					if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+						return nil, result.Error
+					}
+					return items, nil
+				},
+			})
+
 		case "device-metadata":
 
-			if !api.FlagCheck(c, "devices") {
-				return
+			if err := api.flagCheck(c, "devices"); err != nil {
+				return err
 			}
 
 			watchOptions := struct {
@@ -219,11 +294,11 @@ func (api *API) WatchEvents(c *gin.Context) {
 			if r.Options != nil {
 				b, err := json.Marshal(r.Options)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, models.NewApiError(err))
+					return NewApiResponseError(http.StatusBadRequest, models.NewApiError(err))
 				}
 				err = json.Unmarshal(b, &watchOptions)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, models.NewApiError(err))
+					return NewApiResponseError(http.StatusBadRequest, models.NewApiError(err))
 				}
 			}
 
@@ -291,18 +366,20 @@ func (api *API) WatchEvents(c *gin.Context) {
 				},
 			})
 		default:
-			c.JSON(http.StatusBadRequest, models.NewInvalidField(fmt.Sprintf("request[%d].kind", i)))
+			return NewApiResponseError(http.StatusBadRequest, models.NewInvalidField(fmt.Sprintf("request[%d].kind", i)))
 		}
 
 	}
 
-	api.onlineTracker.Connected(api, c, query.PublicKey, func() {
-		api.sendMultiWatch(c, ctx, watches)
+	var err error
+	api.onlineTracker.Connected(api, c, publicKey, func() {
+		start()
+		err = api.sendMultiWatch(ctx, watches, send)
 	})
-
+	return err
 }
 
-func (api *API) sendMultiWatch(c *gin.Context, ctx context.Context, watches []Watch) {
+func (api *API) sendMultiWatch(ctx context.Context, watches []Watch, send func(models.WatchEvent) error) error {
 	type watchState struct {
 		Watch
 		sub    *signalbus.Subscription
@@ -336,9 +413,7 @@ func (api *API) sendMultiWatch(c *gin.Context, ctx context.Context, watches []Wa
 		states = append(states, state)
 	}
 
-	c.Header("Content-Type", "application/json;stream=watch")
-	c.Status(http.StatusOK)
-	api.stream(c, func() models.WatchEvent {
+	nextEvent := func() models.WatchEvent {
 		// This function blocks until there is an event to return...
 		for {
 			parkedCounter := 0
@@ -431,27 +506,18 @@ func (api *API) sendMultiWatch(c *gin.Context, ctx context.Context, watches []Wa
 				}
 			}
 		}
-	})
-}
-
-func (api *API) stream(c *gin.Context, nextEvent func() models.WatchEvent) {
-	flusher, ok := c.Writer.(http.Flusher)
-	if !ok {
-		api.SendInternalServerError(c, fmt.Errorf("streaming unsupported"))
-		return
 	}
-	c.Writer.WriteHeader(200)
-	flusher.Flush()
+
 	for {
-		result := nextEvent()
-		if result.Type == "close" {
-			return
+		event := nextEvent()
+		if event.Type == "close" {
+			return nil
 		}
-		_ = json.NewEncoder(c.Writer).Encode(result)
-		_, _ = c.Writer.Write([]byte("\n"))
-		flusher.Flush() // sends the result to the client (forces Transfer-Encoding: chunked)
-		if result.Type == "error" {
-			return
+		if err := send(event); err != nil {
+			return err
+		}
+		if event.Type == "error" {
+			return nil
 		}
 	}
 }
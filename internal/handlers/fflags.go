@@ -1,53 +1,532 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/signalbus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
+// featureFlagsSignal is notified when the stored feature flags changed, so that every apiserver reloads them
+const featureFlagsSignal = "/feature-flags"
+
+// featureFlagCache keeps the stored feature flags, which FlagCheck reads on most requests
+type featureFlagCache struct {
+	lock sync.Mutex
+	// the stored flags by name, nil until they are loaded
+	flags map[string]models.FeatureFlag
+	// bumped when the flags are invalidated, the flags of a load that raced with an invalidation are not kept
+	generation uint64
+}
+
+// flagResourceTables are the tables of the resources named by the id parameter of the routes, the feature flags of
+// a request for one of them are evaluated for its organization
+var flagResourceTables = map[string]string{
+	"devices":         "devices",
+	"security-groups": "security_groups",
+	"services":        "services",
+	"sites":           "sites",
+	"vpcs":            "vpcs",
+}
+
+// storedFeatureFlags returns the stored feature flags by name, they are read from the database once they changed
+func (api *API) storedFeatureFlags(ctx context.Context) (map[string]models.FeatureFlag, error) {
+	cache := &api.featureFlags
+	cache.lock.Lock()
+	flags, generation := cache.flags, cache.generation
+	cache.lock.Unlock()
+	if flags != nil {
+		return flags, nil
+	}
+
+	var stored []models.FeatureFlag
+	if res := api.db.WithContext(ctx).Find(&stored); res.Error != nil {
+		return nil, fmt.Errorf("failed to read the feature flags: %w", res.Error)
+	}
+	flags = make(map[string]models.FeatureFlag, len(stored))
+	for _, flag := range stored {
+		flags[flag.Name] = flag
+	}
+	cache.lock.Lock()
+	if cache.generation == generation {
+		cache.flags = flags
+	}
+	cache.lock.Unlock()
+	return flags, nil
+}
+
+// invalidateFeatureFlags drops the cached feature flags, they are read again by the next check
+func (api *API) invalidateFeatureFlags() {
+	api.featureFlags.lock.Lock()
+	defer api.featureFlags.lock.Unlock()
+	api.featureFlags.generation++
+	api.featureFlags.flags = nil
+}
+
+// featureFlagsChanged reloads the stored feature flags of this apiserver and notifies the other apiservers
+func (api *API) featureFlagsChanged() {
+	api.invalidateFeatureFlags()
+	api.signalBus.Notify(featureFlagsSignal)
+}
+
+// watchFeatureFlags invalidates the cached feature flags whenever an apiserver changed them
+func (api *API) watchFeatureFlags(ctx context.Context, sub *signalbus.Subscription) {
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Signal():
+			api.invalidateFeatureFlags()
+		}
+	}
+}
+
+// featureFlagEnabled evaluates the targeting of a feature flag for an organization. The organizations of the
+// percentage rollout are picked by a hash of the flag and organization, so raising the percentage keeps the
+// organizations it was enabled for.
+func featureFlagEnabled(flag models.FeatureFlag, orgId uuid.UUID) bool {
+	if orgId == uuid.Nil {
+		return flag.Enabled
+	}
+	if slices.Contains(flag.Organizations, orgId.String()) {
+		return true
+	}
+	if flag.Percentage > 0 {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(flag.Name + ":" + orgId.String()))
+		if int(hash.Sum32()%100) < flag.Percentage {
+			return true
+		}
+	}
+	return flag.Enabled
+}
+
+// featureFlagTargeted is true when the flag is evaluated per organization
+func featureFlagTargeted(flag models.FeatureFlag) bool {
+	return len(flag.Organizations) != 0 || flag.Percentage > 0
+}
+
+// featureFlagValues returns the values of the configured and the stored feature flags, the stored flags override
+// the configured flags of the same name. A stored flag is enabled if it is enabled for one of the organizations,
+// without organizations it has its Enabled value. An empty name returns all the flags.
+func (api *API) featureFlagValues(c *gin.Context, name string, orgIds []uuid.UUID) (map[string]bool, error) {
+	flags := map[string]bool{}
+	if name == "" {
+		flags = api.fflags.ListFlags(c)
+	} else if enabled, err := api.fflags.GetFlag(c, name); err == nil {
+		flags[name] = enabled
+	}
+
+	stored, err := api.storedFeatureFlags(c.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, flag := range stored {
+		if name != "" && flag.Name != name || api.fflags.Overridden(c, flag.Name) {
+			continue
+		}
+		enabled := flag.Enabled
+		if len(orgIds) != 0 {
+			enabled = slices.ContainsFunc(orgIds, func(orgId uuid.UUID) bool {
+				return featureFlagEnabled(flag, orgId)
+			})
+		}
+		flags[flag.Name] = enabled
+	}
+	return flags, nil
+}
+
+// requestOrganizations returns the organizations the feature flags checked by a handler are evaluated for: the
+// organization of the resource named by the path of the request, by default the organizations of the current user
+func (api *API) requestOrganizations(c *gin.Context) ([]uuid.UUID, error) {
+	db := api.db.WithContext(c.Request.Context())
+	var orgIds []uuid.UUID
+	segments := apiResourcePath(c.FullPath())
+	if id, err := uuid.Parse(c.Param("id")); err == nil && len(segments) >= 2 && segments[1] == ":id" {
+		if segments[0] == "organizations" {
+			return []uuid.UUID{id}, nil
+		}
+		if table, found := flagResourceTables[segments[0]]; found {
+			if res := db.Table(table).Where("id = ? AND deleted_at IS NULL", id).Pluck("organization_id", &orgIds); res.Error != nil {
+				return nil, res.Error
+			}
+			if len(orgIds) != 0 {
+				return orgIds, nil
+			}
+		}
+	}
+
+	userId, found := c.Get(gin.AuthUserKey)
+	if !found {
+		return nil, nil
+	}
+	if res := db.Model(&models.UserOrganization{}).Where("user_id = ?", userId).Pluck("organization_id", &orgIds); res.Error != nil {
+		return nil, res.Error
+	}
+	return orgIds, nil
+}
+
+// apiResourcePath returns the segments of the path of a route after its versioned /api/v1 or unversioned /api
+// prefix, the way the policy path of the routers is normalized
+func apiResourcePath(fullPath string) []string {
+	path := strings.Split(strings.TrimLeft(fullPath, "/"), "/")
+	if len(path) > 0 && path[0] == "api" {
+		path = path[1:]
+		if len(path) > 0 && strings.HasPrefix(path[0], "v") {
+			if _, err := strconv.Atoi(path[0][1:]); err == nil {
+				path = path[1:]
+			}
+		}
+	}
+	return path
+}
+
+// checkFeatureFlag returns an ApiResponseError when the feature flag is disabled for the organization, for the
+// handlers that only know the organization of the resource once they read the request
+func (api *API) checkFeatureFlag(c *gin.Context, name string, orgId uuid.UUID) error {
+	flags, err := api.featureFlagValues(c, name, []uuid.UUID{orgId})
+	if err != nil {
+		return err
+	}
+	if enabled, found := flags[name]; found && !enabled {
+		return NewApiResponseError(http.StatusMethodNotAllowed, models.NewNotAllowedError(fmt.Sprintf("%s support is disabled", name)))
+	}
+	return nil
+}
+
+// featureFlagOrganization returns the organization the feature flags of a request are evaluated for, the one of the
+// organization_id query parameter, by default the default organization of the current user.
+func (api *API) featureFlagOrganization(c *gin.Context) (uuid.UUID, bool) {
+	value := c.Query("organization_id")
+	if value == "" {
+		return api.GetCurrentUserID(c), true
+	}
+	orgId, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("organization_id", "must be a uuid"))
+		return uuid.Nil, false
+	}
+	var org models.Organization
+	db := api.db.WithContext(c.Request.Context())
+	if res := api.OrganizationIsReadableByCurrentUser(c, db).First(&org, "id = ?", orgId); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return uuid.Nil, false
+	}
+	return orgId, true
+}
+
 // ListFeatureFlags lists all feature flags
 // @Summary      List Feature Flags
-// @Description  Lists all feature flags
+// @Description  Lists all feature flags, with the values of the flags targeting organizations evaluated for an organization
 // @Id           ListFeatureFlags
 // @Tags         FFlag
 // @Accept       json
 // @Produce      json
+// @Param		 organization_id query  string false "the organization the flags are evaluated for, the default organization of the user by default"
 // @Success      200  {object} map[string]bool
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/fflags [get]
+// @Router       /api/v1/fflags [get]
 func (api *API) ListFeatureFlags(c *gin.Context) {
-	c.JSON(http.StatusOK, api.fflags.ListFlags(c))
+	_, span := tracer.Start(c.Request.Context(), "ListFeatureFlags")
+	defer span.End()
+
+	orgId, ok := api.featureFlagOrganization(c)
+	if !ok {
+		return
+	}
+	flags, err := api.featureFlagValues(c, "", []uuid.UUID{orgId})
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, flags)
 }
 
 // GetFeatureFlag gets a feature flag by name
 // @Summary      Get Feature Flag
-// @Description  Gets a Feature Flag by name
+// @Description  Gets a Feature Flag by name, a flag targeting organizations is evaluated for an organization
 // @Id           GetFeatureFlag
 // @Tags         FFlag
 // @Accept       json
 // @Produce      json
 // @Param		 name path      string true  "feature flag name"
+// @Param		 organization_id query  string false "the organization the flag is evaluated for, the default organization of the user by default"
 // @Success      200  {object} map[string]bool
 // @Failure      400  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/fflags/{name} [get]
+// @Router       /api/v1/fflags/{name} [get]
 func (api *API) GetFeatureFlag(c *gin.Context) {
+	_, span := tracer.Start(c.Request.Context(), "GetFeatureFlag", trace.WithAttributes(
+		attribute.String("name", c.Param("name")),
+	))
+	defer span.End()
+
 	flagName := c.Param("name")
 	if flagName == "" {
 		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("name"))
 		return
 	}
 
-	enabled, err := api.fflags.GetFlag(c, flagName)
+	orgId, ok := api.featureFlagOrganization(c)
+	if !ok {
+		return
+	}
+	flags, err := api.featureFlagValues(c, flagName, []uuid.UUID{orgId})
 	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	enabled, found := flags[flagName]
+	if !found {
 		c.JSON(http.StatusNotFound, models.NewNotFoundError("flag"))
 		return
 	}
 
 	c.JSON(http.StatusOK, map[string]bool{flagName: enabled})
 }
+
+// validateFeatureFlag checks the targeting of a feature flag
+func validateFeatureFlag(flag models.FeatureFlag) error {
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("percentage", "must be between 0 and 100"))
+	}
+	for _, org := range flag.Organizations {
+		if _, err := uuid.Parse(org); err != nil {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("organizations", "must be organization ids"))
+		}
+	}
+	return nil
+}
+
+// AdminListFeatureFlags lists the stored feature flags
+// @Summary      List Stored Feature Flags
+// @Description  Lists the feature flags managed through the api along with their targeting, reserved to the controller admins
+// @Id           AdminListFeatureFlags
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  []models.FeatureFlag
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/fflags [get]
+func (api *API) AdminListFeatureFlags(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminListFeatureFlags")
+	defer span.End()
+
+	if err := api.recordAdminListEvent(c, api.db.WithContext(ctx), "feature-flag"); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	flags := []models.FeatureFlag{}
+	if res := api.db.WithContext(ctx).Order("name").Find(&flags); res.Error != nil {
+		api.SendInternalServerError(c, res.Error)
+		return
+	}
+	c.JSON(http.StatusOK, flags)
+}
+
+// AdminCreateFeatureFlag creates a stored feature flag
+// @Summary      Create Feature Flag
+// @Description  Creates a feature flag, it overrides the flag of the same name configured on the apiserver. Reserved to the controller admins.
+// @Id           AdminCreateFeatureFlag
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        FeatureFlag  body   models.AddFeatureFlag  true  "Add Feature Flag"
+// @Success      201  {object}  models.FeatureFlag
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/fflags [post]
+func (api *API) AdminCreateFeatureFlag(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminCreateFeatureFlag")
+	defer span.End()
+
+	var request models.AddFeatureFlag
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("name"))
+		return
+	}
+
+	flag := models.FeatureFlag{
+		Name:          request.Name,
+		Description:   request.Description,
+		Enabled:       request.Enabled,
+		Organizations: append(models.StringArray{}, request.Organizations...),
+		Percentage:    request.Percentage,
+	}
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if err := validateFeatureFlag(flag); err != nil {
+			return err
+		}
+		var existing models.FeatureFlag
+		if res := tx.First(&existing, "name = ?", flag.Name); res.Error == nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(existing.ID.String()))
+		} else if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return res.Error
+		}
+		if res := tx.Create(&flag); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, uuid.Nil, models.AuditActionCreate, "feature-flag", flag.ID, nil, auditState(flag))
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.featureFlagsChanged()
+	c.JSON(http.StatusCreated, flag)
+}
+
+// AdminUpdateFeatureFlag updates a stored feature flag
+// @Summary      Update Feature Flag
+// @Description  Updates the value and targeting of a feature flag, reserved to the controller admins
+// @Id           AdminUpdateFeatureFlag
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 name path      string true  "feature flag name"
+// @Param		 update body models.UpdateFeatureFlag true "Feature Flag Update"
+// @Success      200  {object}  models.FeatureFlag
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/fflags/{name} [patch]
+func (api *API) AdminUpdateFeatureFlag(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminUpdateFeatureFlag", trace.WithAttributes(
+		attribute.String("name", c.Param("name")),
+	))
+	defer span.End()
+
+	var request models.UpdateFeatureFlag
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var flag models.FeatureFlag
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.First(&flag, "name = ?", c.Param("name")); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("flag"))
+			}
+			return res.Error
+		}
+		before := auditState(flag)
+		if request.Description != nil {
+			flag.Description = *request.Description
+		}
+		if request.Enabled != nil {
+			flag.Enabled = *request.Enabled
+		}
+		if request.Organizations != nil {
+			flag.Organizations = request.Organizations
+		}
+		if request.Percentage != nil {
+			flag.Percentage = *request.Percentage
+		}
+		if err := validateFeatureFlag(flag); err != nil {
+			return err
+		}
+		if res := tx.Select("*").Updates(&flag); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, uuid.Nil, models.AuditActionUpdate, "feature-flag", flag.ID, before, auditState(flag))
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.featureFlagsChanged()
+	c.JSON(http.StatusOK, flag)
+}
+
+// AdminDeleteFeatureFlag deletes a stored feature flag
+// @Summary      Delete Feature Flag
+// @Description  Deletes a feature flag, the flag of the same name configured on the apiserver applies again. Reserved to the controller admins.
+// @Id           AdminDeleteFeatureFlag
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param		 name path      string true  "feature flag name"
+// @Success      200  {object}  models.FeatureFlag
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/admin/fflags/{name} [delete]
+func (api *API) AdminDeleteFeatureFlag(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "AdminDeleteFeatureFlag", trace.WithAttributes(
+		attribute.String("name", c.Param("name")),
+	))
+	defer span.End()
+
+	var flag models.FeatureFlag
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.First(&flag, "name = ?", c.Param("name")); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("flag"))
+			}
+			return res.Error
+		}
+		// the flag is removed so that it can be created again with the same name
+		if res := tx.Unscoped().Delete(&flag); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, uuid.Nil, models.AuditActionDelete, "feature-flag", flag.ID, auditState(flag), nil)
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+	api.featureFlagsChanged()
+	c.JSON(http.StatusOK, flag)
+}
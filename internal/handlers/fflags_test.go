@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureFlagEnabled(t *testing.T) {
+	require := require.New(t)
+	targeted := uuid.New()
+	flag := models.FeatureFlag{
+		Name:          "exit-node",
+		Organizations: []string{targeted.String()},
+	}
+	require.True(featureFlagEnabled(flag, targeted))
+	require.False(featureFlagEnabled(flag, uuid.New()))
+
+	// the organizations of a rollout stay enabled when the percentage is raised
+	var orgs []uuid.UUID
+	for i := 0; i < 1000; i++ {
+		orgs = append(orgs, uuid.New())
+	}
+	enabledAt := func(percentage int) map[uuid.UUID]bool {
+		flag.Percentage = percentage
+		enabled := map[uuid.UUID]bool{}
+		for _, org := range orgs {
+			if featureFlagEnabled(flag, org) {
+				enabled[org] = true
+			}
+		}
+		return enabled
+	}
+	require.Empty(enabledAt(0))
+	ten := enabledAt(10)
+	require.InDelta(100, len(ten), 50)
+	fifty := enabledAt(50)
+	for org := range ten {
+		require.True(fifty[org])
+	}
+	require.Len(enabledAt(100), len(orgs))
+
+	flag.Percentage = 0
+	flag.Enabled = true
+	require.True(featureFlagEnabled(flag, uuid.New()))
+	require.True(featureFlagEnabled(flag, uuid.Nil))
+}
+
+func (suite *HandlerTestSuite) TestFeatureFlags() {
+	require := suite.Require()
+	suite.api.db.Exec("DELETE FROM feature_flags")
+	suite.api.invalidateFeatureFlags()
+
+	flags := func(uri string) map[string]bool {
+		code, body := suite.serve(http.MethodGet, "/", uri, suite.api.ListFeatureFlags, nil)
+		require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+		result := map[string]bool{}
+		require.NoError(json.Unmarshal(body, &result))
+		return result
+	}
+
+	require.NotContains(flags("/"), "exit-node")
+
+	code, _ := suite.serve(http.MethodPost, "/", "/", suite.api.AdminCreateFeatureFlag, models.AddFeatureFlag{Name: "exit-node", Percentage: 101})
+	require.Equal(http.StatusBadRequest, code)
+	code, body := suite.serve(http.MethodPost, "/", "/", suite.api.AdminCreateFeatureFlag, models.AddFeatureFlag{
+		Name:          "exit-node",
+		Organizations: []string{suite.testUserID.String()},
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	code, _ = suite.serve(http.MethodPost, "/", "/", suite.api.AdminCreateFeatureFlag, models.AddFeatureFlag{Name: "exit-node"})
+	require.Equal(http.StatusConflict, code)
+
+	// the flag is enabled for the default organization of the user only
+	require.True(flags("/")["exit-node"])
+	require.True(flags("/?organization_id=" + suite.testUserID.String())["exit-node"])
+	code, _ = suite.serve(http.MethodGet, "/", "/?organization_id="+suite.testUser2ID.String(), suite.api.ListFeatureFlags, nil)
+	require.Equal(http.StatusNotFound, code)
+	code, body = suite.serve(http.MethodGet, "/:name", "/exit-node", suite.api.GetFeatureFlag, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.JSONEq(`{"exit-node":true}`, string(body))
+
+	// a stored flag overrides the configured flag of the same name
+	code, _ = suite.serve(http.MethodPost, "/", "/", suite.api.AdminCreateFeatureFlag, models.AddFeatureFlag{Name: "devices"})
+	require.Equal(http.StatusCreated, code)
+	require.False(flags("/")["devices"])
+	code, _ = suite.serve(http.MethodGet, "/", "/", suite.api.ListDevices, nil)
+	require.Equal(http.StatusMethodNotAllowed, code)
+	code, _ = suite.serve(http.MethodDelete, "/:name", "/devices", suite.api.AdminDeleteFeatureFlag, nil)
+	require.Equal(http.StatusOK, code)
+	require.True(flags("/")["devices"])
+
+	code, body = suite.serve(http.MethodPatch, "/:name", "/exit-node", suite.api.AdminUpdateFeatureFlag, models.UpdateFeatureFlag{Organizations: []string{}})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.False(flags("/")["exit-node"])
+	code, _ = suite.serve(http.MethodPatch, "/:name", "/unknown", suite.api.AdminUpdateFeatureFlag, models.UpdateFeatureFlag{})
+	require.Equal(http.StatusNotFound, code)
+
+	code, body = suite.serve(http.MethodGet, "/", "/", suite.api.AdminListFeatureFlags, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var stored []models.FeatureFlag
+	require.NoError(json.Unmarshal(body, &stored))
+	require.Len(stored, 1)
+	require.Equal("exit-node", stored[0].Name)
+	require.Empty(stored[0].Organizations)
+
+	// the stored flags are cached until an apiserver signals that they changed
+	require.NoError(suite.api.db.Create(&models.FeatureFlag{Name: "cached", Enabled: true, Organizations: models.StringArray{}}).Error)
+	require.NotContains(flags("/"), "cached")
+	suite.api.signalBus.Notify(featureFlagsSignal)
+	require.Eventually(func() bool {
+		return flags("/")["cached"]
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (suite *HandlerTestSuite) TestFeatureFlagResourceOrganization() {
+	require := suite.Require()
+	suite.api.db.Exec("DELETE FROM feature_flags")
+	suite.api.invalidateFeatureFlags()
+
+	// the user is a member of a second organization
+	org := models.Organization{Name: "fflags-org"}
+	require.NoError(suite.api.db.Create(&org).Error)
+	require.NoError(suite.api.db.Create(&models.UserOrganization{UserID: suite.testUserID, OrganizationID: org.ID, Roles: []string{"owner"}}).Error)
+	defer func() {
+		suite.api.db.Where("organization_id = ?", org.ID).Delete(&models.UserOrganization{})
+		suite.api.db.Unscoped().Delete(&org)
+	}()
+
+	code, body := suite.serve(http.MethodPost, "/", "/", suite.api.AdminCreateFeatureFlag, models.AddFeatureFlag{
+		Name:          "devices",
+		Organizations: []string{org.ID.String()},
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	defer func() {
+		suite.serve(http.MethodDelete, "/:name", "/devices", suite.api.AdminDeleteFeatureFlag, nil)
+	}()
+
+	// the flag is evaluated for the organization of the resource rather than the default organization of the user
+	listDevices := func(prefix string, orgId uuid.UUID) int {
+		code, _ := suite.serve(http.MethodGet, prefix+"/organizations/:id/devices", prefix+"/organizations/"+orgId.String()+"/devices", suite.api.ListDevicesInOrganization, nil)
+		return code
+	}
+	require.Equal(http.StatusOK, listDevices("/api/v1", org.ID))
+	require.Equal(http.StatusMethodNotAllowed, listDevices("/api/v1", suite.testUserID))
+	// the same for the unversioned alias routes
+	require.Equal(http.StatusOK, listDevices("/api", org.ID))
+	require.Equal(http.StatusMethodNotAllowed, listDevices("/api", suite.testUserID))
+
+	// without a resource in the path, the flag is enabled if it is enabled for one of the organizations of the user
+	code, body = suite.serve(http.MethodGet, "/api/v1/devices", "/api/v1/devices", suite.api.ListDevices, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+}
@@ -3,11 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 const (
@@ -18,6 +22,8 @@ type Query struct {
 	Sort   string `form:"sort"`
 	Filter string `form:"filter"`
 	Range  string `form:"range"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
 }
 
 func (q *Query) GetSort() (string, error) {
@@ -73,8 +79,31 @@ func FilterAndPaginate(db *gorm.DB, model interface{}, c *gin.Context, orderBy s
 
 func FilterAndPaginateWithQuery(db *gorm.DB, model interface{}, c *gin.Context, query Query, defaultOrderBy string) *gorm.DB {
 
-	if order, err := query.GetSort(); err == nil {
-		db = db.Order(order)
+	sortFields, err := query.GetSortFields()
+	if err != nil {
+		db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("sort", err.Error()))
+		return db
+	}
+
+	// a cursor or limit switches the list to cursor pagination
+	if query.Cursor != "" || query.Limit != 0 {
+		return cursorPaginate(db, model, c, query, sortFields, defaultOrderBy)
+	}
+
+	if len(sortFields) > 0 {
+		sch, err := schema.Parse(model, schemaCache, db.NamingStrategy)
+		if err != nil {
+			db.Error = err
+			return db
+		}
+		columns, err := sortColumns(sch, sortFields)
+		if err != nil {
+			db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("sort", err.Error()))
+			return db
+		}
+		for _, column := range columns {
+			db = db.Order(clause.OrderByColumn{Column: column.column(), Desc: column.desc})
+		}
 	} else if defaultOrderBy != "" {
 		db = db.Order(defaultOrderBy)
 	}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -19,11 +20,13 @@ import (
 	"github.com/nexodus-io/nexodus/internal/database"
 	"github.com/nexodus-io/nexodus/internal/fflags"
 	"github.com/nexodus-io/nexodus/internal/ipam"
+	"github.com/nexodus-io/nexodus/internal/models"
 	"github.com/open-policy-agent/opa/storage/inmem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
 var TestUserIdpID = "testuser"
@@ -113,6 +116,42 @@ func (suite *HandlerTestSuite) ServeRequest(method, path string, uri string, han
 	return req, res, nil
 }
 
+// serve serves the request, marshalled to json unless nil, with the handler and returns the status code and the
+// body of the response
+func (suite *HandlerTestSuite) serve(method string, path string, uri string, handler func(c *gin.Context), request any) (int, []byte) {
+	require := suite.Require()
+	var reqBody io.Reader
+	if request != nil {
+		reqBody = bytes.NewBuffer(suite.jsonMarshal(request))
+	}
+	_, res, err := suite.ServeRequest(method, path, uri, handler, reqBody)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	return res.Code, body
+}
+
+// newPublicKey returns the public key of a new wireguard key
+func (suite *HandlerTestSuite) newPublicKey() string {
+	key, err := wgtypes.GeneratePrivateKey()
+	suite.Require().NoError(err)
+	return key.PublicKey().String()
+}
+
+// createDevice creates a device with the request, in the vpc of the test user unless the request sets a vpc, and
+// returns it
+func (suite *HandlerTestSuite) createDevice(request models.AddDevice) models.Device {
+	require := suite.Require()
+	if request.VpcID == uuid.Nil {
+		request.VpcID = suite.testUserID
+	}
+	code, body := suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, request)
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+	return device
+}
+
 func TestHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(HandlerTestSuite))
 }
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/stun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// holePunchLeadTime is how far in the future a hole punch starts, long enough for the signal
+// to reach both devices over their event streams
+const holePunchLeadTime = 2 * time.Second
+
+// CreateHolePunch coordinates a hole punch between a device and a peer
+// @Summary      Coordinate a hole punch
+// @Description  Signals a hole punch to a device and a peer behind NATs, so that they send to each other at the same time
+// @Id           CreateHolePunch
+// @Tags         Devices
+// @Accept       json
+// @Produce      json
+// @Param        id          path      string               true "Device ID"
+// @Param        HolePunch   body      models.AddHolePunch  true "Hole Punch"
+// @Success      201  {object}  models.HolePunch
+// @Failure      400  {object}  models.ValidationError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/devices/{id}/hole-punch [post]
+func (api *API) CreateHolePunch(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateHolePunch", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+	deviceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddHolePunch
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.PeerID == uuid.Nil || request.PeerID == deviceId {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("peer_id", "must be another device"))
+		return
+	}
+
+	var device, peer models.Device
+	var holePunch models.HolePunch
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		result := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", deviceId)
+		if result.Error != nil {
+			return result.Error
+		}
+		// the peer may belong to another user of the vpc
+		result = tx.First(&peer, "id = ? AND vpc_id = ?", request.PeerID, device.VpcID)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("peer_id", "not found in the vpc of the device"))
+			}
+			return result.Error
+		}
+		if device.Relay || peer.Relay {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("peer_id", "relays are reached directly"))
+		}
+		deviceDependent := stun.Mapping(device.NatType).EndpointDependent()
+		peerDependent := stun.Mapping(peer.NatType).EndpointDependent()
+		if deviceDependent && peerDependent {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("peer_id", "both devices are behind a NAT with an endpoint dependent mapping"))
+		}
+
+		id := uuid.New()
+		startAt := time.Now().Add(holePunchLeadTime).UTC()
+		holePunch = newHolePunch(id, device, peer, startAt, peerDependent)
+		for _, hp := range []models.HolePunch{holePunch, newHolePunch(id, peer, device, startAt, deviceDependent)} {
+			metadata := models.DeviceMetadata{
+				DeviceID: hp.DeviceID,
+				Key:      models.HolePunchMetadataPrefix + hp.PeerID.String(),
+				Value:    hp,
+			}
+			if res := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+				Save(&metadata); res.Error != nil {
+				return res.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("device"))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, fmt.Errorf("error coordinating the hole punch: %w", err))
+		}
+		return
+	}
+
+	api.signalBus.Notify(fmt.Sprintf("/metadata/vpc=%s", device.VpcID.String()))
+	c.JSON(http.StatusCreated, holePunch)
+}
+
+// newHolePunch returns the hole punch signaled to device
+func newHolePunch(id uuid.UUID, device, peer models.Device, startAt time.Time, birthday bool) models.HolePunch {
+	return models.HolePunch{
+		ID:            id,
+		DeviceID:      device.ID,
+		PeerID:        peer.ID,
+		PeerPublicKey: peer.PublicKey,
+		PeerEndpoints: peer.Endpoints,
+		PeerNatType:   peer.NatType,
+		StartAt:       startAt,
+		Birthday:      birthday,
+	}
+}
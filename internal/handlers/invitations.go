@@ -34,7 +34,7 @@ var allowedRoles = map[string]struct{}{
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/invitations [post]
+// @Router       /api/v1/invitations [post]
 func (api *API) CreateInvitation(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "CreateInvitation")
 	defer span.End()
@@ -181,7 +181,7 @@ func (api *API) CreateInvitation(c *gin.Context) {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/invitations [get]
+// @Router       /api/v1/invitations [get]
 func (api *API) ListInvitations(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListInvitations")
 	defer span.End()
@@ -225,7 +225,7 @@ func (api *API) InvitationIsForCurrentUserOrOrgOwner(c *gin.Context, db *gorm.DB
 // @Failure		 429  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/invitations/{id} [get]
+// @Router       /api/v1/invitations/{id} [get]
 func (api *API) GetInvitation(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetInvitation",
 		trace.WithAttributes(
@@ -270,7 +270,7 @@ func (api *API) GetInvitation(c *gin.Context) {
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/invitations/{id}/accept [post]
+// @Router       /api/v1/invitations/{id}/accept [post]
 func (api *API) AcceptInvitation(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "AcceptInvitation",
 		trace.WithAttributes(
@@ -307,7 +307,7 @@ func (api *API) AcceptInvitation(c *gin.Context) {
 		if res := tx.Delete(&invitation); res.Error != nil {
 			return res.Error
 		}
-		return nil
+		return api.recordAuditEvent(c, tx, invitation.OrganizationID, models.AuditActionCreate, "organization_user", user.ID, nil, auditState(userOrganization))
 	})
 
 	if err != nil {
@@ -339,7 +339,7 @@ func (api *API) AcceptInvitation(c *gin.Context) {
 // @Failure      405  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/invitations/{id} [delete]
+// @Router       /api/v1/invitations/{id} [delete]
 func (api *API) DeleteInvitation(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteInvitation",
 		trace.WithAttributes(
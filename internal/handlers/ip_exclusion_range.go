@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/ipam"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// each address of an exclusion range is allocated in IPAM, this bounds the allocations of a range
+const maxExclusionRangeSize = 4096
+
+// ListIPExclusionRanges lists the exclusion ranges of an organization
+// @Summary      List IP Exclusion Ranges
+// @Description  Lists the ranges of the organization cidr that IPAM does not hand out
+// @Id           ListIPExclusionRanges
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  []models.IPExclusionRange
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ip-exclusion-ranges [get]
+func (api *API) ListIPExclusionRanges(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListIPExclusionRanges",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	records := []models.IPExclusionRange{}
+	db = FilterAndPaginate(db.Where("organization_id = ?", org.ID), &models.IPExclusionRange{}, c, "start_address")
+	if result := db.Find(&records); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching exclusion ranges from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// GetIPExclusionRange gets an exclusion range of an organization
+// @Summary      Get IP Exclusion Range
+// @Description  Gets an exclusion range of the organization by ID
+// @Id           GetIPExclusionRange
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id        path      string true "Organization ID"
+// @Param		 range_id  path      string true "IP Exclusion Range ID"
+// @Success      200  {object}  models.IPExclusionRange
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ip-exclusion-ranges/{range_id} [get]
+func (api *API) GetIPExclusionRange(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetIPExclusionRange",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("range_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	rangeId, err := uuid.Parse(c.Param("range_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("range_id"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	if res := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId); res.Error != nil {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+	var record models.IPExclusionRange
+	if res := db.First(&record, "id = ? AND organization_id = ?", rangeId, org.ID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("ip exclusion range"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// CreateIPExclusionRange adds an exclusion range to an organization
+// @Summary      Create an IP Exclusion Range
+// @Description  Excludes a range of the organization cidr from the addresses IPAM hands out, the organization must have its own cidr
+// @Id           CreateIPExclusionRange
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id              path   string true "Organization ID"
+// @Param        IPExclusionRange  body     models.AddIPExclusionRange  true  "Add IP Exclusion Range"
+// @Success      201  {object}  models.IPExclusionRange
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ip-exclusion-ranges [post]
+func (api *API) CreateIPExclusionRange(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateIPExclusionRange",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddIPExclusionRange
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.StartAddress == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("start_address"))
+		return
+	}
+	if request.EndAddress == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("end_address"))
+		return
+	}
+
+	var record models.IPExclusionRange
+	// the addresses acquired in IPAM are released when the transaction is rolled back or retried
+	var release func()
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		if release != nil {
+			release()
+			release = nil
+		}
+
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+
+		// the organization cidr is the cidr of its default vpc, which has the id of the organization
+		var vpc models.VPC
+		if res := tx.First(&vpc, "id = ?", org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("the organization has no default vpc"))
+		}
+		if !vpc.PrivateCidr {
+			// the addresses of the shared cidr are handed out to the devices of all the organizations
+			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("exclusion ranges require the organization to have its own cidr"))
+		}
+
+		prefix, addresses, field, reason := parseExclusionRange(request.StartAddress, request.EndAddress, vpc.Ipv4Cidr, vpc.Ipv6Cidr)
+		if reason != "" {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError(field, reason))
+		}
+		conflict, err := exclusionRangeConflict(tx, org.ID, request.StartAddress, request.EndAddress)
+		if err != nil {
+			return err
+		}
+		if conflict != uuid.Nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(conflict.String()))
+		}
+
+		var acquired []netip.Addr
+		release = func() {
+			api.releaseExcludedAddresses(ctx, vpc.ID, prefix, acquired)
+		}
+		for _, addr := range addresses {
+			if err := api.ipam.AcquireIP(ctx, vpc.ID, prefix.String(), addr.String()); err != nil {
+				if errors.Is(err, ipam.ErrAddressInUse) {
+					return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("start_address", fmt.Sprintf("the address %s of the range is already in use", addr)))
+				}
+				return fmt.Errorf("failed to acquire the excluded address %s: %w", addr, err)
+			}
+			acquired = append(acquired, addr)
+		}
+
+		record = models.IPExclusionRange{
+			OrganizationID: org.ID,
+			StartAddress:   request.StartAddress,
+			EndAddress:     request.EndAddress,
+			Description:    request.Description,
+		}
+		if res := tx.Create(&record); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", record.ID.String()))
+		api.logger.Infof("New ip exclusion range [ %s ] from [ %s ] to [ %s ] in organization [ %s ]", record.ID, record.StartAddress, record.EndAddress, org.ID)
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionCreate, "ip_exclusion_range", record.ID, nil, auditState(record))
+	})
+
+	if err != nil {
+		if release != nil {
+			release()
+		}
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+// UpdateIPExclusionRange updates an exclusion range of an organization
+// @Summary      Update IP Exclusion Range
+// @Description  Updates the description of an exclusion range, the addresses of a range can not be changed
+// @Id           UpdateIPExclusionRange
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id        path      string true "Organization ID"
+// @Param		 range_id  path      string true "IP Exclusion Range ID"
+// @Param		 update body models.UpdateIPExclusionRange true "IP Exclusion Range Update"
+// @Success      200  {object}  models.IPExclusionRange
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ip-exclusion-ranges/{range_id} [patch]
+func (api *API) UpdateIPExclusionRange(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateIPExclusionRange",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("range_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	rangeId, err := uuid.Parse(c.Param("range_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("range_id"))
+		return
+	}
+
+	var request models.UpdateIPExclusionRange
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var record models.IPExclusionRange
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&record, "id = ? AND organization_id = ?", rangeId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("ip exclusion range"))
+		}
+
+		before := auditState(record)
+		if request.Description != nil {
+			record.Description = *request.Description
+		}
+		if res := tx.Save(&record); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionUpdate, "ip_exclusion_range", record.ID, before, auditState(record))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// DeleteIPExclusionRange deletes an exclusion range of an organization
+// @Summary      Delete IP Exclusion Range
+// @Description  Deletes an exclusion range, IPAM hands out its addresses again
+// @Id           DeleteIPExclusionRange
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id        path      string true "Organization ID"
+// @Param		 range_id  path      string true "IP Exclusion Range ID"
+// @Success      204  {object}  models.IPExclusionRange
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ip-exclusion-ranges/{range_id} [delete]
+func (api *API) DeleteIPExclusionRange(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteIPExclusionRange",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("range_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	rangeId, err := uuid.Parse(c.Param("range_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("range_id"))
+		return
+	}
+
+	var record models.IPExclusionRange
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&record, "id = ? AND organization_id = ?", rangeId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("ip exclusion range"))
+		}
+		if res := tx.Delete(&record); res.Error != nil {
+			return res.Error
+		}
+
+		var vpc models.VPC
+		if res := tx.First(&vpc, "id = ?", org.ID); res.Error != nil {
+			return res.Error
+		}
+		prefix, addresses, _, reason := parseExclusionRange(record.StartAddress, record.EndAddress, vpc.Ipv4Cidr, vpc.Ipv6Cidr)
+		if reason != "" {
+			return fmt.Errorf("invalid exclusion range %s: %s", record.ID, reason)
+		}
+		for _, addr := range addresses {
+			if err := api.ipam.ReleaseToPool(ctx, vpc.ID, addr.String(), prefix.String()); err != nil {
+				return fmt.Errorf("failed to release the excluded address %s to pool: %w", addr, err)
+			}
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionDelete, "ip_exclusion_range", record.ID, auditState(record), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// parseExclusionRange returns the prefix of the range and the addresses it allocates in IPAM, or the field and the
+// reason the range is invalid. The network address, and the broadcast address of a v4 prefix, are never handed out
+// by IPAM so they are not allocated.
+func parseExclusionRange(start, end string, cidrs ...string) (netip.Prefix, []netip.Addr, string, string) {
+	first, err := netip.ParseAddr(start)
+	if err != nil {
+		return netip.Prefix{}, nil, "start_address", "must be an IP address"
+	}
+	last, err := netip.ParseAddr(end)
+	if err != nil {
+		return netip.Prefix{}, nil, "end_address", "must be an IP address"
+	}
+	if first.Is4() != last.Is4() {
+		return netip.Prefix{}, nil, "end_address", "must be of the same address family as the start_address"
+	}
+	if last.Less(first) {
+		return netip.Prefix{}, nil, "end_address", "can not be lower than the start_address"
+	}
+
+	var prefix netip.Prefix
+	for _, cidr := range cidrs {
+		p, err := netip.ParsePrefix(cidr)
+		if err == nil && p.Contains(first) && p.Contains(last) {
+			prefix = p.Masked()
+			break
+		}
+	}
+	if !prefix.IsValid() {
+		return netip.Prefix{}, nil, "start_address", "the range is not within the organization cidr"
+	}
+
+	var addresses []netip.Addr
+	for addr := first; ; addr = addr.Next() {
+		if addr != prefix.Addr() && !(addr.Is4() && !prefix.Contains(addr.Next())) {
+			if len(addresses) == maxExclusionRangeSize {
+				return netip.Prefix{}, nil, "end_address", fmt.Sprintf("the range can not exceed %d addresses", maxExclusionRangeSize)
+			}
+			addresses = append(addresses, addr)
+		}
+		if addr == last {
+			break
+		}
+	}
+	return prefix, addresses, "", ""
+}
+
+// exclusionRangeConflict returns the id of the exclusion range, device or ip reservation of the organization holding
+// addresses of the range, or uuid.Nil if none. The ranges apply to the default vpc, which has the id of the organization.
+func exclusionRangeConflict(db *gorm.DB, orgId uuid.UUID, start, end string) (uuid.UUID, error) {
+	first, last := netip.MustParseAddr(start), netip.MustParseAddr(end)
+	within := func(address string) bool {
+		addr, err := netip.ParseAddr(address)
+		return err == nil && !addr.Less(first) && !last.Less(addr)
+	}
+
+	var ranges []models.IPExclusionRange
+	if res := db.Where("organization_id = ?", orgId).Find(&ranges); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	for _, r := range ranges {
+		rFirst, err1 := netip.ParseAddr(r.StartAddress)
+		rLast, err2 := netip.ParseAddr(r.EndAddress)
+		if err1 == nil && err2 == nil && rFirst.Is4() == first.Is4() && !last.Less(rFirst) && !rLast.Less(first) {
+			return r.ID, nil
+		}
+	}
+
+	var devices []models.Device
+	if res := db.Where("vpc_id = ?", orgId).Find(&devices); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	for _, d := range devices {
+		for _, t := range append(d.IPv4TunnelIPs, d.IPv6TunnelIPs...) {
+			if within(t.Address) {
+				return d.ID, nil
+			}
+		}
+	}
+
+	var reservations []models.IPReservation
+	if res := db.Where("vpc_id = ?", orgId).Find(&reservations); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	for _, r := range reservations {
+		if within(r.Address) {
+			return r.ID, nil
+		}
+	}
+	return uuid.Nil, nil
+}
+
+// releaseExcludedAddresses releases the addresses of an exclusion range that could not be created
+func (api *API) releaseExcludedAddresses(ctx context.Context, namespace uuid.UUID, prefix netip.Prefix, addresses []netip.Addr) {
+	for _, addr := range addresses {
+		if err := api.ipam.ReleaseToPool(ctx, namespace, addr.String(), prefix.String()); err != nil {
+			api.logger.Warnf("failed to release the excluded address %s: %v", addr, err)
+		}
+	}
+}
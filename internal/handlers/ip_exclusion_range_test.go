@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExclusionRange(t *testing.T) {
+	require := require.New(t)
+	cidrs := []string{"10.200.0.0/24", "fd00:200::/64"}
+
+	prefix, addresses, _, reason := parseExclusionRange("10.200.0.10", "10.200.0.12", cidrs...)
+	require.Empty(reason)
+	require.Equal(netip.MustParsePrefix("10.200.0.0/24"), prefix)
+	require.Equal([]netip.Addr{netip.MustParseAddr("10.200.0.10"), netip.MustParseAddr("10.200.0.11"), netip.MustParseAddr("10.200.0.12")}, addresses)
+
+	// the network and broadcast addresses are never handed out by IPAM
+	_, addresses, _, reason = parseExclusionRange("10.200.0.0", "10.200.0.255", cidrs...)
+	require.Empty(reason)
+	require.Len(addresses, 254)
+	require.Equal("10.200.0.1", addresses[0].String())
+	require.Equal("10.200.0.254", addresses[253].String())
+
+	prefix, addresses, _, reason = parseExclusionRange("fd00:200::1", "fd00:200::ff", cidrs...)
+	require.Empty(reason)
+	require.Equal("fd00:200::/64", prefix.String())
+	require.Len(addresses, 255)
+
+	for _, tc := range []struct {
+		start, end, field, reason string
+	}{
+		{"10.200.0", "10.200.0.12", "start_address", "must be an IP address"},
+		{"10.200.0.10", "", "end_address", "must be an IP address"},
+		{"10.200.0.10", "fd00:200::1", "end_address", "must be of the same address family as the start_address"},
+		{"10.200.0.10", "10.200.0.9", "end_address", "can not be lower than the start_address"},
+		{"10.200.0.10", "10.200.1.10", "start_address", "the range is not within the organization cidr"},
+		{"fd00:200::1", "fd00:200::2:0", "end_address", fmt.Sprintf("the range can not exceed %d addresses", maxExclusionRangeSize)},
+	} {
+		_, _, field, reason := parseExclusionRange(tc.start, tc.end, cidrs...)
+		require.Equal(tc.field, field, tc.start+"-"+tc.end)
+		require.Equal(tc.reason, reason, tc.start+"-"+tc.end)
+	}
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// ListIPReservationsInVPC lists the ip reservations of a VPC
+// @Summary      List IP Reservations
+// @Description  Lists the tunnel IPs reserved to device identities in this VPC
+// @Id           ListIPReservationsInVPC
+// @Tags         VPC
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "VPC ID"
+// @Success      200  {object}  []models.IPReservation
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/vpcs/{id}/ip-reservations [get]
+func (api *API) ListIPReservationsInVPC(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListIPReservationsInVPC",
+		trace.WithAttributes(
+			attribute.String("vpc_id", c.Param("id")),
+		))
+	defer span.End()
+
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var vpc models.VPC
+	db := api.db.WithContext(ctx)
+	result := api.VPCIsReadableByCurrentUser(c, db).
+		First(&vpc, "id = ?", vpcId.String())
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("vpc"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	records := []models.IPReservation{}
+	db = FilterAndPaginate(db.Where("vpc_id = ?", vpc.ID), &models.IPReservation{}, c, "address")
+	if result := db.Find(&records); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching ip reservations from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// CreateIPReservation reserves a tunnel IP of a VPC to a device identity
+// @Summary      Create an IP Reservation
+// @Description  Reserves a tunnel IP of the VPC to a device public key or device id, the device gets the address every time it registers
+// @Id           CreateIPReservation
+// @Tags         VPC
+// @Accept       json
+// @Produce      json
+// @Param		 id              path   string true "VPC ID"
+// @Param        IPReservation  body     models.AddIPReservation  true  "Add IP Reservation"
+// @Success      201  {object}  models.IPReservation
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/vpcs/{id}/ip-reservations [post]
+func (api *API) CreateIPReservation(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateIPReservation",
+		trace.WithAttributes(
+			attribute.String("vpc_id", c.Param("id")),
+		))
+	defer span.End()
+
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddIPReservation
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.PublicKey == "" && request.DeviceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("public_key"))
+		return
+	}
+	if request.PublicKey != "" && request.DeviceID != uuid.Nil {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("device_id", "can not be set along the public_key"))
+		return
+	}
+	if request.Address == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("address"))
+		return
+	}
+
+	var reservation models.IPReservation
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var vpc models.VPC
+		if res := api.VPCIsOwnedByCurrentUser(c, tx).
+			First(&vpc, "id = ?", vpcId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
+		}
+		if reason := validateReservedAddress(request.Address, vpc.Ipv4Cidr); reason != "" {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("address", reason))
+		}
+
+		var existing models.IPReservation
+		res := tx.Where("vpc_id = ? AND address = ?", vpc.ID, request.Address).First(&existing)
+		if res.Error == nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(existing.ID.String()))
+		}
+		if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return res.Error
+		}
+		if existing, err := reservationOfDevice(tx, vpc.ID, request.PublicKey, request.DeviceID); err != nil {
+			return err
+		} else if existing != nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(existing.ID.String()))
+		}
+
+		// the address is already allocated if the device it is reserved to holds it
+		holder, err := deviceHoldingAddress(tx, vpc.ID, request.Address)
+		if err != nil {
+			return err
+		}
+		if holder != nil && holder.PublicKey != request.PublicKey && holder.ID != request.DeviceID {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(holder.ID.String()))
+		}
+		if holder == nil {
+			ipamNamespace := defaultIPAMNamespace
+			if vpc.PrivateCidr {
+				ipamNamespace = vpc.ID
+			}
+			if err := api.ipam.AcquireIP(ctx, ipamNamespace, vpc.Ipv4Cidr, request.Address); err != nil {
+				api.logger.Debugf("failed to acquire the reserved address %s: %v", request.Address, err)
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("address", "is already in use"))
+			}
+		}
+
+		reservation = models.IPReservation{
+			VpcID:          vpc.ID,
+			OrganizationID: vpc.OrganizationID,
+			PublicKey:      request.PublicKey,
+			Address:        request.Address,
+			Description:    request.Description,
+		}
+		if request.DeviceID != uuid.Nil {
+			reservation.DeviceID = &request.DeviceID
+		}
+		if res := tx.Create(&reservation); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", reservation.ID.String()))
+		api.logger.Infof("New ip reservation [ %s ] of address [ %s ] in vpc [ %s ]", reservation.ID, reservation.Address, vpc.ID)
+		return api.recordAuditEvent(c, tx, reservation.OrganizationID, models.AuditActionCreate, "ip_reservation", reservation.ID, nil, auditState(reservation))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// DeleteIPReservation deletes an ip reservation of a VPC
+// @Summary      Delete IP Reservation
+// @Description  Deletes an ip reservation, the address is released once no device holds it
+// @Id           DeleteIPReservation
+// @Tags         VPC
+// @Accept       json
+// @Produce      json
+// @Param		 id              path   string true "VPC ID"
+// @Param		 reservation_id  path   string true "IP Reservation ID"
+// @Success      204  {object}  models.IPReservation
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/vpcs/{id}/ip-reservations/{reservation_id} [delete]
+func (api *API) DeleteIPReservation(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteIPReservation",
+		trace.WithAttributes(
+			attribute.String("vpc_id", c.Param("id")),
+			attribute.String("id", c.Param("reservation_id")),
+		))
+	defer span.End()
+
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	reservationId, err := uuid.Parse(c.Param("reservation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("reservation_id"))
+		return
+	}
+
+	var reservation models.IPReservation
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var vpc models.VPC
+		if res := api.VPCIsOwnedByCurrentUser(c, tx).
+			First(&vpc, "id = ?", vpcId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
+		}
+		if res := tx.First(&reservation, "id = ? AND vpc_id = ?", reservationId, vpc.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("ip reservation"))
+		}
+		if res := tx.Delete(&reservation); res.Error != nil {
+			return res.Error
+		}
+
+		// the device holding the address keeps it until it is deleted
+		holder, err := deviceHoldingAddress(tx, vpc.ID, reservation.Address)
+		if err != nil {
+			return err
+		}
+		if holder == nil {
+			ipamNamespace := defaultIPAMNamespace
+			if vpc.PrivateCidr {
+				ipamNamespace = vpc.ID
+			}
+			if err := api.ipam.ReleaseToPool(ctx, ipamNamespace, reservation.Address, vpc.Ipv4Cidr); err != nil {
+				return fmt.Errorf("failed to release the reserved address to pool: %w", err)
+			}
+		}
+		return api.recordAuditEvent(c, tx, reservation.OrganizationID, models.AuditActionDelete, "ip_reservation", reservation.ID, auditState(reservation), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// validateReservedAddress returns the reason an address can not be reserved in a vpc, only v4 addresses are reserved
+// like the addresses requested by the devices.
+func validateReservedAddress(address, cidr string) string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil || !addr.Is4() {
+		return "must be an IPv4 address"
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil || !prefix.Contains(addr) {
+		return fmt.Sprintf("is not within the vpc cidr %s", cidr)
+	}
+	if addr == prefix.Masked().Addr() {
+		return "can not be the network address of the vpc cidr"
+	}
+	return ""
+}
+
+// reservationOfDevice returns the ip reservation of a device identity in a vpc, or nil if none.
+func reservationOfDevice(db *gorm.DB, vpcId uuid.UUID, publicKey string, deviceId uuid.UUID) (*models.IPReservation, error) {
+	db = db.Where("vpc_id = ?", vpcId)
+	switch {
+	case publicKey != "" && deviceId != uuid.Nil:
+		db = db.Where("public_key = ? OR device_id = ?", publicKey, deviceId)
+	case publicKey != "":
+		db = db.Where("public_key = ?", publicKey)
+	default:
+		db = db.Where("device_id = ?", deviceId)
+	}
+	var reservation models.IPReservation
+	if res := db.First(&reservation); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, res.Error
+	}
+	return &reservation, nil
+}
+
+// addressIsReserved returns true if the tunnel IP is reserved to a device identity in the vpc, the address then
+// stays allocated when the device releases it.
+func addressIsReserved(ctx context.Context, db *gorm.DB, vpcId uuid.UUID, address string) (bool, error) {
+	var count int64
+	if res := db.WithContext(ctx).Model(&models.IPReservation{}).
+		Where("vpc_id = ? AND address = ?", vpcId, address).
+		Count(&count); res.Error != nil {
+		return false, res.Error
+	}
+	return count > 0, nil
+}
+
+// deviceHoldingAddress returns the device of the vpc that holds the tunnel IP, or nil if none. The tunnel IPs are
+// stored as json, the devices whose serialized tunnel IPs contain the quoted address are checked.
+func deviceHoldingAddress(db *gorm.DB, vpcId uuid.UUID, address string) (*models.Device, error) {
+	var devices []models.Device
+	if res := db.Where("vpc_id = ? AND CAST(ipv4_tunnel_ips AS TEXT) LIKE ?", vpcId, "%\""+address+"\"%").
+		Find(&devices); res.Error != nil {
+		return nil, res.Error
+	}
+	for i, d := range devices {
+		for _, t := range d.IPv4TunnelIPs {
+			if t.Address == address {
+				return &devices[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
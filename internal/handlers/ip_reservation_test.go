@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReservedAddress(t *testing.T) {
+	require := require.New(t)
+	require.Empty(validateReservedAddress("100.64.0.10", "100.64.0.0/10"))
+	require.Equal("must be an IPv4 address", validateReservedAddress("200::10", "100.64.0.0/10"))
+	require.Equal("must be an IPv4 address", validateReservedAddress("100.64.0", "100.64.0.0/10"))
+	require.Equal("is not within the vpc cidr 10.10.0.0/24", validateReservedAddress("10.10.1.10", "10.10.0.0/24"))
+	require.Equal("can not be the network address of the vpc cidr", validateReservedAddress("10.10.0.0", "10.10.0.0/24"))
+}
+
+func (suite *HandlerTestSuite) TestIPReservations() {
+	require := suite.Require()
+	ctx := context.Background()
+	const reserved = "100.100.10.10"
+
+	reserve := func(vpcId uuid.UUID, request models.AddIPReservation) (int, []byte) {
+		return suite.serve(http.MethodPost, "/:id/ip-reservations", fmt.Sprintf("/%s/ip-reservations", vpcId), suite.api.CreateIPReservation, request)
+	}
+	deleteDevice := func(device models.Device) {
+		code, body := suite.serve(http.MethodDelete, "/:id", fmt.Sprintf("/%s", device.ID), suite.api.DeleteDevice, nil)
+		require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	}
+
+	code, body := reserve(suite.testUserID, models.AddIPReservation{PublicKey: "reservationpubkey1", Address: reserved})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var reservation models.IPReservation
+	require.NoError(json.Unmarshal(body, &reservation))
+
+	// the device of the identity gets the reserved address
+	device := suite.createDevice(models.AddDevice{PublicKey: "reservationpubkey1"})
+	require.Equal(reserved, device.IPv4TunnelIPs[0].Address)
+
+	// an address held by another device can not be reserved
+	other := suite.createDevice(models.AddDevice{PublicKey: "reservationpubkey2"})
+	code, body = reserve(suite.testUserID, models.AddIPReservation{PublicKey: "reservationpubkey3", Address: other.IPv4TunnelIPs[0].Address})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+	var conflict models.ConflictsError
+	require.NoError(json.Unmarshal(body, &conflict))
+	require.Equal(other.ID.String(), conflict.ID)
+
+	// the reserved address stays allocated when the device is deleted, and is given to its next device
+	deleteDevice(device)
+	require.Error(suite.api.ipam.AcquireIP(ctx, defaultIPAMNamespace, defaultIPAMv4Cidr, reserved))
+	device = suite.createDevice(models.AddDevice{PublicKey: "reservationpubkey1"})
+	require.Equal(reserved, device.IPv4TunnelIPs[0].Address)
+
+	// the device moved to another vpc gets the address reserved to it in that vpc
+	code, body = suite.serve(http.MethodPost, "/", "/", suite.api.CreateVPC, models.AddVPC{
+		OrganizationID: suite.testUserID,
+		PrivateCidr:    true,
+		Ipv4Cidr:       "10.120.0.0/24",
+		Ipv6Cidr:       "fc00:120::/64",
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var vpc models.VPC
+	require.NoError(json.Unmarshal(body, &vpc))
+	code, body = reserve(vpc.ID, models.AddIPReservation{DeviceID: device.ID, Address: "10.120.0.50"})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	code, body = suite.serve(http.MethodPatch, "/:id", fmt.Sprintf("/%s", device.ID), suite.api.UpdateDevice, models.UpdateDevice{VpcID: &vpc.ID})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &device))
+	require.Equal("10.120.0.50", device.IPv4TunnelIPs[0].Address)
+
+	// the address no device holds is released with its reservation
+	require.Error(suite.api.ipam.AcquireIP(ctx, defaultIPAMNamespace, defaultIPAMv4Cidr, reserved))
+	code, body = suite.serve(http.MethodDelete, "/:id/ip-reservations/:reservation_id", fmt.Sprintf("/%s/ip-reservations/%s", suite.testUserID, reservation.ID), suite.api.DeleteIPReservation, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.NoError(suite.api.ipam.AcquireIP(ctx, defaultIPAMNamespace, defaultIPAMv4Cidr, reserved))
+	require.NoError(suite.api.ipam.ReleaseToPool(ctx, defaultIPAMNamespace, reserved, defaultIPAMv4Cidr))
+}
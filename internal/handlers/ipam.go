@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const ipamMetricsInterval = time.Minute
+
+var (
+	ipamMetricLabels = []string{"organization", "vpc", "cidr"}
+
+	ipamPrefixSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "apiserver",
+		Name:      "ipam_prefix_size",
+		Help:      "Number of addresses of the IPAM prefix.",
+	}, ipamMetricLabels)
+	ipamPrefixAllocated = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "apiserver",
+		Name:      "ipam_prefix_allocated",
+		Help:      "Number of addresses of the IPAM prefix that are allocated.",
+	}, ipamMetricLabels)
+	ipamPrefixFree = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "apiserver",
+		Name:      "ipam_prefix_free",
+		Help:      "Number of addresses of the IPAM prefix that are free.",
+	}, ipamMetricLabels)
+	ipamPrefixFragmentation = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "apiserver",
+		Name:      "ipam_prefix_fragmentation",
+		Help:      "Fragmentation of the free addresses of the IPAM prefix, from 0 to 1.",
+	}, ipamMetricLabels)
+)
+
+// GetOrganizationIPAM reports the address usage of the prefixes of an organization
+// @Summary      Get Organization IPAM Usage
+// @Description  Reports the size, allocated and free address counts, and fragmentation of each prefix of the VPCs of the organization
+// @Id           GetOrganizationIPAM
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  models.OrganizationIPAM
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/ipam [get]
+func (api *API) GetOrganizationIPAM(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetOrganizationIPAM",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	var vpcs []models.VPC
+	if result := db.Where("organization_id = ?", org.ID).Order("created_at").Find(&vpcs); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching vpcs from db: %w", result.Error))
+		return
+	}
+
+	report := models.OrganizationIPAM{
+		OrganizationID: org.ID,
+		Prefixes:       []models.IPAMPrefixUsage{},
+	}
+	// the usage of the shared pool is the same for every vpc using it
+	shared := map[string]models.IPAMPrefixUsage{}
+	for _, vpc := range vpcs {
+		for _, cidr := range []string{vpc.Ipv4Cidr, vpc.Ipv6Cidr} {
+			if cidr == "" {
+				continue
+			}
+			usage, found := shared[cidr]
+			if !found {
+				usage, err = api.ipamPrefixUsage(ctx, db, vpc, cidr)
+				if err != nil {
+					api.SendInternalServerError(c, fmt.Errorf("failed to get the usage of prefix %s: %w", cidr, err))
+					return
+				}
+				if usage.Shared {
+					shared[cidr] = usage
+				}
+			}
+			usage.VpcID = vpc.ID
+			report.Prefixes = append(report.Prefixes, usage)
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ipamPrefixUsage reports the usage of a prefix of the vpc, the counts come from IPAM while the fragmentation is
+// computed from the addresses the db knows are allocated.
+func (api *API) ipamPrefixUsage(ctx context.Context, db *gorm.DB, vpc models.VPC, cidr string) (models.IPAMPrefixUsage, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return models.IPAMPrefixUsage{}, err
+	}
+	prefix = prefix.Masked()
+
+	namespace := defaultIPAMNamespace
+	if vpc.PrivateCidr {
+		namespace = vpc.ID
+	}
+
+	size, acquired, err := api.ipam.PrefixUsage(ctx, namespace, prefix.String())
+	if err != nil {
+		return models.IPAMPrefixUsage{}, err
+	}
+
+	allocated, err := heldAddresses(db, vpc, uuid.Nil, prefix.String())
+	if err != nil {
+		return models.IPAMPrefixUsage{}, err
+	}
+
+	free := uint64(0)
+	if acquired < size {
+		free = size - acquired
+	}
+	return models.IPAMPrefixUsage{
+		VpcID:         vpc.ID,
+		Cidr:          cidr,
+		Shared:        !vpc.PrivateCidr,
+		Size:          size,
+		Allocated:     acquired,
+		Free:          free,
+		Fragmentation: ipamFragmentation(prefix, allocated),
+	}, nil
+}
+
+// heldAddresses returns the addresses the db knows are allocated in the IPAM namespace of the vpc: the tunnel IPs
+// of the devices other than excludeDevice, the reservations, and the exclusion ranges within the cidrs.
+func heldAddresses(db *gorm.DB, vpc models.VPC, excludeDevice uuid.UUID, cidrs ...string) ([]netip.Addr, error) {
+	vpcIds := db.Model(&models.VPC{}).Select("id").Where("private_cidr = ?", false)
+	if vpc.PrivateCidr {
+		vpcIds = db.Model(&models.VPC{}).Select("id").Where("id = ?", vpc.ID)
+	}
+
+	var allocated []netip.Addr
+	var devices []models.Device
+	if result := db.Where("vpc_id IN (?) AND id <> ?", vpcIds, excludeDevice).Find(&devices); result.Error != nil {
+		return nil, result.Error
+	}
+	for _, device := range devices {
+		for _, tunnelIP := range append(device.IPv4TunnelIPs, device.IPv6TunnelIPs...) {
+			if addr, err := netip.ParseAddr(tunnelIP.Address); err == nil {
+				allocated = append(allocated, addr)
+			}
+		}
+	}
+	var reservations []models.IPReservation
+	if result := db.Where("vpc_id IN (?)", vpcIds).Find(&reservations); result.Error != nil {
+		return nil, result.Error
+	}
+	for _, reservation := range reservations {
+		if addr, err := netip.ParseAddr(reservation.Address); err == nil {
+			allocated = append(allocated, addr)
+		}
+	}
+	// exclusion ranges are only allocated in the private namespace of the default vpc
+	if vpc.PrivateCidr && vpc.ID == vpc.OrganizationID {
+		var ranges []models.IPExclusionRange
+		if result := db.Where("organization_id = ?", vpc.OrganizationID).Find(&ranges); result.Error != nil {
+			return nil, result.Error
+		}
+		for _, r := range ranges {
+			_, addresses, _, _ := parseExclusionRange(r.StartAddress, r.EndAddress, cidrs...)
+			allocated = append(allocated, addresses...)
+		}
+	}
+	return allocated, nil
+}
+
+// ipamFragmentation returns 1 - (largest block of free addresses / free addresses) for the prefix. The network
+// address, and the broadcast address of an IPv4 prefix, are counted as allocated. Addresses outside the prefix
+// are ignored.
+func ipamFragmentation(prefix netip.Prefix, allocated []netip.Addr) float64 {
+	prefix = prefix.Masked()
+	last := lastAddr(prefix)
+
+	addresses := []netip.Addr{prefix.Addr()}
+	if prefix.Addr().Is4() {
+		addresses = append(addresses, last)
+	}
+	for _, addr := range allocated {
+		if prefix.Contains(addr) {
+			addresses = append(addresses, addr)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Less(addresses[j])
+	})
+
+	free := new(big.Int)
+	largest := new(big.Int)
+	addGap := func(gap *big.Int) {
+		if gap.Sign() <= 0 {
+			return
+		}
+		free.Add(free, gap)
+		if gap.Cmp(largest) > 0 {
+			largest.Set(gap)
+		}
+	}
+	for i := 1; i < len(addresses); i++ {
+		// the addresses strictly between two allocated ones are free
+		gap := new(big.Int).Sub(addrInt(addresses[i]), addrInt(addresses[i-1]))
+		addGap(gap.Sub(gap, big.NewInt(1)))
+	}
+	addGap(new(big.Int).Sub(addrInt(last), addrInt(addresses[len(addresses)-1])))
+
+	if free.Sign() == 0 {
+		return 0
+	}
+	fragmentation, _ := new(big.Rat).SetFrac(new(big.Int).Sub(free, largest), free).Float64()
+	return fragmentation
+}
+
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Masked().Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+func addrInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+// recordIPAMMetrics refreshes the ipam prometheus gauges, with a series for each prefix of the vpcs with a private
+// cidr and for each prefix of the shared pool.
+func (api *API) recordIPAMMetrics(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "recordIPAMMetrics")
+	defer span.End()
+
+	db := api.db.WithContext(ctx)
+	var vpcs []models.VPC
+	if result := db.Where("private_cidr = ?", true).Find(&vpcs); result.Error != nil {
+		api.logger.Errorf("failed to list the vpcs for the ipam metrics: %v", result.Error)
+		return
+	}
+	// the shared pool is reported without organization and vpc labels
+	vpcs = append(vpcs, models.VPC{
+		Ipv4Cidr: defaultIPAMv4Cidr,
+		Ipv6Cidr: defaultIPAMv6Cidr,
+	})
+
+	for _, gauge := range []*prometheus.GaugeVec{ipamPrefixSize, ipamPrefixAllocated, ipamPrefixFree, ipamPrefixFragmentation} {
+		gauge.Reset()
+	}
+	for _, vpc := range vpcs {
+		for _, cidr := range []string{vpc.Ipv4Cidr, vpc.Ipv6Cidr} {
+			if cidr == "" {
+				continue
+			}
+			usage, err := api.ipamPrefixUsage(ctx, db, vpc, cidr)
+			if err != nil {
+				api.logger.Errorf("failed to get the usage of prefix %s of vpc %s: %v", cidr, vpc.ID, err)
+				continue
+			}
+			labels := prometheus.Labels{"organization": "", "vpc": "", "cidr": cidr}
+			if vpc.PrivateCidr {
+				labels["organization"] = vpc.OrganizationID.String()
+				labels["vpc"] = vpc.ID.String()
+			}
+			ipamPrefixSize.With(labels).Set(float64(usage.Size))
+			ipamPrefixAllocated.With(labels).Set(float64(usage.Allocated))
+			ipamPrefixFree.With(labels).Set(float64(usage.Free))
+			ipamPrefixFragmentation.With(labels).Set(usage.Fragmentation)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	staleLeaseDeleted = "deleted"
+	staleLeaseNotSeen = "not-seen"
+)
+
+// staleDevice is a device with IPAM allocations to release
+type staleDevice struct {
+	device    models.Device
+	namespace uuid.UUID
+	leases    []models.StaleLease
+}
+
+// StartIPAMReclaimer runs the reclaim job every interval, an interval of 0 disables it.
+func (api *API) StartIPAMReclaimer(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		return
+	}
+	go util.RunPeriodically(ctx, interval, func() {
+		api.reclaimStaleLeases(ctx)
+	})
+}
+
+// ListStaleLeases reports the IPAM allocations the reclaim job releases on its next run
+// @Summary      List Stale IPAM Leases
+// @Description  Lists the IPAM allocations of the deleted devices that were not released, and of the devices not seen for the reclaim period, without releasing them
+// @Id 			 ListStaleLeases
+// @Tags         Private
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.StaleLeaseReport
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /private/ipam/stale-leases [get]
+func (api *API) ListStaleLeases(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListStaleLeases")
+	defer span.End()
+
+	devices, err := api.findStaleDevices(api.db.WithContext(ctx))
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	report := models.StaleLeaseReport{
+		Leases: []models.StaleLease{},
+	}
+	for _, d := range devices {
+		report.Leases = append(report.Leases, d.leases...)
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// reclaimStaleLeases deletes the devices not seen for the reclaim period, then releases the IPAM allocations of the
+// deleted devices. A device whose allocations fail to release is retried on the next run.
+func (api *API) reclaimStaleLeases(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "reclaimStaleLeases")
+	defer span.End()
+
+	db := api.db.WithContext(ctx)
+	devices, err := api.findStaleDevices(db)
+	if err != nil {
+		api.logger.Errorf("failed to find the stale ipam leases: %v", err)
+		return
+	}
+
+	for _, d := range devices {
+		if !d.device.DeletedAt.Valid {
+			if err := api.deleteStaleDevice(ctx, d.device); err != nil {
+				api.logger.Errorf("failed to delete device %s not seen since %v: %v", d.device.ID, d.device.LastSeen, err)
+				continue
+			}
+			api.logger.Infof("deleted device %s not seen for %v", d.device.ID, api.IPAMReclaimAfter)
+		}
+
+		api.releaseStaleLeases(ctx, db, d)
+	}
+}
+
+// releaseStaleLeases releases the IPAM allocations of a deleted device, and records the release once all of them
+// are released so the device is not retried.
+func (api *API) releaseStaleLeases(ctx context.Context, db *gorm.DB, d staleDevice) {
+	released := true
+	for _, lease := range d.leases {
+		var err error
+		if lease.Address != "" {
+			err = api.ipam.ReleaseToPool(ctx, d.namespace, lease.Address, lease.Cidr)
+		} else {
+			err = api.ipam.ReleaseCIDR(ctx, d.namespace, lease.Cidr)
+		}
+		if err != nil {
+			api.logger.Errorf("failed to release %s%s of device %s: %v", lease.Address, lease.Cidr, d.device.ID, err)
+			released = false
+		}
+	}
+	if !released {
+		return
+	}
+	if res := db.Unscoped().Model(&models.Device{}).
+		Where("id = ?", d.device.ID).
+		Update("ipam_released_at", time.Now()); res.Error != nil {
+		api.logger.Errorf("failed to record the release of the ipam leases of device %s: %v", d.device.ID, res.Error)
+	}
+}
+
+// findStaleDevices returns the deleted devices that hold IPAM allocations, and when IPAMReclaimAfter is set, the
+// devices not seen for that long.
+func (api *API) findStaleDevices(db *gorm.DB) ([]staleDevice, error) {
+	var deleted []models.Device
+	if res := db.Unscoped().
+		Where("deleted_at IS NOT NULL AND ipam_released_at IS NULL").
+		Find(&deleted); res.Error != nil {
+		return nil, fmt.Errorf("failed to list the deleted devices: %w", res.Error)
+	}
+	var notSeen []models.Device
+	if api.IPAMReclaimAfter > 0 {
+		cutoff := time.Now().Add(-api.IPAMReclaimAfter)
+		if res := db.
+			Where("online = ? AND unmanaged = ? AND (last_seen < ? OR (last_seen IS NULL AND created_at < ?))", false, false, cutoff, cutoff).
+			Find(&notSeen); res.Error != nil {
+			return nil, fmt.Errorf("failed to list the devices not seen since %v: %w", cutoff, res.Error)
+		}
+	}
+
+	var result []staleDevice
+	for _, device := range deleted {
+		d, err := staleDeviceLeases(db, device, staleLeaseDeleted)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	for _, device := range notSeen {
+		d, err := staleDeviceLeases(db, device, staleLeaseNotSeen)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// staleDeviceLeases returns the tunnel IPs and advertised child prefixes of the device that no other record of the
+// db holds.
+func staleDeviceLeases(db *gorm.DB, device models.Device, reason string) (staleDevice, error) {
+	result := staleDevice{device: device, namespace: defaultIPAMNamespace}
+
+	var vpc models.VPC
+	if res := db.Unscoped().First(&vpc, "id = ?", device.VpcID); res.Error != nil {
+		return result, fmt.Errorf("failed to get the vpc of device %s: %w", device.ID, res.Error)
+	}
+	if vpc.DeletedAt.Valid {
+		// deleting the vpc released its prefixes along with the addresses allocated from them
+		return result, nil
+	}
+	if vpc.PrivateCidr {
+		result.namespace = vpc.ID
+	}
+
+	held, err := heldAddresses(db, vpc, device.ID, vpc.Ipv4Cidr, vpc.Ipv6Cidr)
+	if err != nil {
+		return result, err
+	}
+	for _, tunnelIP := range append(device.IPv4TunnelIPs, device.IPv6TunnelIPs...) {
+		addr, err := netip.ParseAddr(tunnelIP.Address)
+		if err != nil || tunnelIP.CIDR == "" || slices.Contains(held, addr) {
+			continue
+		}
+		result.leases = append(result.leases, models.StaleLease{
+			DeviceID:       device.ID,
+			VpcID:          device.VpcID,
+			OrganizationID: device.OrganizationID,
+			Address:        tunnelIP.Address,
+			Cidr:           tunnelIP.CIDR,
+			Reason:         reason,
+		})
+	}
+
+	var advertised []pq.StringArray
+	if res := db.Model(&models.Device{}).
+		Where("vpc_id = ? AND id <> ?", device.VpcID, device.ID).
+		Pluck("advertise_cidrs", &advertised); res.Error != nil {
+		return result, res.Error
+	}
+	for _, cidr := range device.AdvertiseCidrs {
+		if util.IsDefaultIPRoute(cidr) || slices.ContainsFunc(advertised, func(cidrs pq.StringArray) bool {
+			return slices.Contains(cidrs, cidr)
+		}) {
+			continue
+		}
+		result.leases = append(result.leases, models.StaleLease{
+			DeviceID:       device.ID,
+			VpcID:          device.VpcID,
+			OrganizationID: device.OrganizationID,
+			Cidr:           cidr,
+			Reason:         reason,
+		})
+	}
+	return result, nil
+}
+
+// deleteStaleDevice deletes a device not seen for the reclaim period or the device ttl of its organization, the way
+// DeleteDevice does.
+func (api *API) deleteStaleDevice(ctx context.Context, device models.Device) error {
+	before := auditState(device)
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if res := tx.
+			Model(&device).
+			Where("id = ?", device.ID).
+			Updates(map[string]interface{}{
+				"bearer_token": nil,
+				"public_key":   nil,
+				"deleted_at":   gorm.DeletedAt{Time: time.Now(), Valid: true},
+			}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Delete(&models.DeviceSecurityGroup{}, "device_id = ?", device.ID); res.Error != nil {
+			return res.Error
+		}
+		if err := deleteDeviceServices(tx, device); err != nil {
+			return err
+		}
+		if err := deleteDeviceConnectivity(tx, device); err != nil {
+			return err
+		}
+		if device.Relay {
+			if _, err := api.assignRelays(ctx, tx, device.VpcID); err != nil {
+				return err
+			}
+		}
+		return recordSystemAuditEvent(tx, device.OrganizationID, models.AuditActionDelete, "device", device.ID, before, nil)
+	})
+	if err != nil {
+		return err
+	}
+	api.signalBus.Notify(fmt.Sprintf("/devices/vpc=%s", device.VpcID.String()))
+	api.signalBus.Notify(fmt.Sprintf("/services/vpc=%s", device.VpcID.String()))
+	return nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestReclaimStaleLeases() {
+	require := suite.Require()
+
+	resBody, err := json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "areclaimpubkey",
+	})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(resBody),
+	)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+
+	staleLeases := func() []models.StaleLease {
+		_, res, err := suite.ServeRequest(
+			http.MethodGet, "/", "/",
+			suite.api.ListStaleLeases, nil,
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+		var report models.StaleLeaseReport
+		require.NoError(json.Unmarshal(body, &report))
+		var leases []models.StaleLease
+		for _, lease := range report.Leases {
+			if lease.DeviceID == device.ID {
+				leases = append(leases, lease)
+			}
+		}
+		return leases
+	}
+	require.Empty(staleLeases())
+
+	// deleting the record without going through DeleteDevice leaks its tunnel ip
+	require.NoError(suite.api.db.Delete(&models.Device{}, "id = ?", device.ID).Error)
+
+	leases := staleLeases()
+	require.Len(leases, len(device.IPv4TunnelIPs)+len(device.IPv6TunnelIPs))
+	require.Equal(device.IPv4TunnelIPs[0].Address, leases[0].Address)
+	require.Equal(staleLeaseDeleted, leases[0].Reason)
+
+	suite.api.reclaimStaleLeases(context.Background())
+	require.Empty(staleLeases())
+}
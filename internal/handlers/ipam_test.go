@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPAMFragmentation(t *testing.T) {
+	require := require.New(t)
+	prefix := netip.MustParsePrefix("10.200.0.0/29")
+	addrs := func(addresses ...string) []netip.Addr {
+		var result []netip.Addr
+		for _, a := range addresses {
+			result = append(result, netip.MustParseAddr(a))
+		}
+		return result
+	}
+
+	// the free addresses .1 to .6 form a single block
+	require.Equal(0.0, ipamFragmentation(prefix, nil))
+	require.Equal(0.0, ipamFragmentation(prefix, addrs("10.200.0.1", "10.200.0.2", "10.100.0.3")))
+
+	// .1 .2 and .4 .5 .6 are free, the largest block holds 3 of the 5 free addresses
+	require.InDelta(0.4, ipamFragmentation(prefix, addrs("10.200.0.3")), 0.0001)
+	require.InDelta(0.4, ipamFragmentation(prefix, addrs("10.200.0.3", "10.200.0.3", "10.200.0.0")), 0.0001)
+	require.InDelta(0.5, ipamFragmentation(prefix, addrs("10.200.0.2", "10.200.0.5")), 0.0001)
+
+	require.Equal(0.0, ipamFragmentation(prefix, addrs("10.200.0.1", "10.200.0.2", "10.200.0.3", "10.200.0.4", "10.200.0.5", "10.200.0.6")))
+
+	// IPv6 prefixes have no broadcast address
+	prefix = netip.MustParsePrefix("fd00:200::/126")
+	require.Equal(0.0, ipamFragmentation(prefix, nil))
+	require.InDelta(0.5, ipamFragmentation(prefix, addrs("fd00:200::2")), 0.0001)
+
+	fragmentation := ipamFragmentation(netip.MustParsePrefix("200::/64"), addrs("200::5"))
+	require.Greater(fragmentation, 0.0)
+	require.Less(fragmentation, 0.0001)
+}
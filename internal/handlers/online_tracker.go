@@ -7,11 +7,23 @@ import (
 	"github.com/nexodus-io/nexodus/internal/util"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"sync"
 	"time"
 )
 
-func New(logger *zap.Logger) (*DeviceTracker, error) {
+// New returns the tracker of the devices connected to the event streams, a local tracker only knows the devices
+// connected to this apiserver and does not use redis
+func New(logger *zap.Logger, local bool) (*DeviceTracker, error) {
+	if local {
+		return &DeviceTracker{
+			reconnectGracePeriod: time.Second * 5,
+			sweepInterval:        time.Minute,
+			logger:               logger,
+			keyPrefix:            "dev-track:",
+			localDevices:         map[string]int{},
+		}, nil
+	}
 
 	redisAddr := util.Getenv("NEXAPI_REDIS_SERVER", "redis:6379")
 	redisDB, err := util.GetenvInt("NEXAPI_REDIS_DB", "1")
@@ -26,6 +38,7 @@ func New(logger *zap.Logger) (*DeviceTracker, error) {
 			DisableIndentity: true,
 		}),
 		reconnectGracePeriod: time.Second * 5,
+		sweepInterval:        time.Minute,
 		logger:               logger,
 		keyPrefix:            "dev-track:",
 		localDevices:         map[string]int{},
@@ -41,6 +54,7 @@ type DeviceTracker struct {
 	pubSub               *redis.PubSub
 	localDevices         map[string]int
 	reconnectGracePeriod time.Duration
+	sweepInterval        time.Duration
 }
 
 func (ot *DeviceTracker) Connected(api *API, c *gin.Context, publicKey string, fn func()) {
@@ -63,15 +77,22 @@ func (ot *DeviceTracker) Connected(api *API, c *gin.Context, publicKey string, f
 		return
 	}
 
+	now := time.Now()
+	device.LastSeen = &now
+	columns := []string{"last_seen"}
 	if !device.Online {
 		device.Online = true
-		now := time.Now()
 		device.OnlineAt = &now
-		err := api.db.Select("online", "online_at").Updates(device).Error
-		if err != nil {
-			ot.logger.Warn("failed to update db state for device", zap.String("public_key", publicKey), zap.Error(err))
-			fn()
-		}
+		columns = append(columns, "online", "online_at")
+	}
+	if device.ExpiredAt != nil {
+		// the device checked in again, it is no longer expired
+		device.ExpiredAt = nil
+		columns = append(columns, "expired_at")
+	}
+	err := api.db.Select(columns).Updates(device).Error
+	if err != nil {
+		ot.logger.Warn("failed to update db state for device", zap.String("public_key", publicKey), zap.Error(err))
 	}
 
 	defer func() {
@@ -96,11 +117,7 @@ func (ot *DeviceTracker) Connected(api *API, c *gin.Context, publicKey string, f
 				return
 			}
 			if device.Online {
-				device.Online = false
-				now := time.Now()
-				device.OnlineAt = &now
-				err := api.db.Select("online", "online_at").Updates(device).Error
-				if err != nil {
+				if err := ot.offline(api.db, device); err != nil {
 					ot.logger.Warn("failed to update db state for device", zap.String("public_key", publicKey), zap.Error(err))
 				}
 			}
@@ -122,6 +139,9 @@ func (ot *DeviceTracker) connected(publicKey string) error {
 	}
 
 	ot.localDevices[publicKey] = 1
+	if ot.redis == nil {
+		return nil
+	}
 	if ot.pubSub == nil {
 		ot.pubSub = ot.redis.Subscribe(context.Background(), ot.keyPrefix+publicKey)
 	}
@@ -150,6 +170,9 @@ func (ot *DeviceTracker) disconnected(publicKey string) error {
 	}
 
 	delete(ot.localDevices, publicKey)
+	if ot.redis == nil {
+		return nil
+	}
 	err := ot.pubSub.Unsubscribe(context.Background(), ot.keyPrefix+publicKey)
 	if err != nil {
 		return err
@@ -158,6 +181,11 @@ func (ot *DeviceTracker) disconnected(publicKey string) error {
 }
 
 func (ot *DeviceTracker) isConnected(publicKey string) (bool, error) {
+	if ot.redis == nil {
+		ot.mu.Lock()
+		defer ot.mu.Unlock()
+		return ot.localDevices[publicKey] != 0, nil
+	}
 	key := ot.keyPrefix + publicKey
 	result, err := ot.redis.PubSubNumSub(context.Background(), key).Result()
 	if err != nil {
@@ -165,3 +193,40 @@ func (ot *DeviceTracker) isConnected(publicKey string) (bool, error) {
 	}
 	return result[key] != 0, nil
 }
+
+// offline marks a device that is no longer connected to the event stream as offline
+func (ot *DeviceTracker) offline(db *gorm.DB, device *models.Device) error {
+	now := time.Now()
+	device.Online = false
+	device.OnlineAt = &now
+	device.LastSeen = &now
+	return db.Select("online", "online_at", "last_seen").Updates(device).Error
+}
+
+// sweep marks devices that are online in the db but not connected to the event stream of any apiserver
+// as offline. This catches devices that were connected to an apiserver that stopped before it could
+// record the disconnect.
+func (ot *DeviceTracker) sweep(ctx context.Context, db *gorm.DB) {
+	var devices []*models.Device
+	err := db.WithContext(ctx).Select("id", "public_key", "online_at").Where("online = ?", true).Find(&devices).Error
+	if err != nil {
+		ot.logger.Warn("failed to list online devices", zap.Error(err))
+		return
+	}
+	for _, device := range devices {
+		if device.OnlineAt != nil && time.Since(*device.OnlineAt) < ot.reconnectGracePeriod {
+			continue
+		}
+		connected, err := ot.isConnected(device.PublicKey)
+		if err != nil {
+			ot.logger.Warn("failed to get online state for device", zap.String("public_key", device.PublicKey), zap.Error(err))
+			return
+		}
+		if connected {
+			continue
+		}
+		if err := ot.offline(db.WithContext(ctx), device); err != nil {
+			ot.logger.Warn("failed to update db state for device", zap.String("public_key", device.PublicKey), zap.Error(err))
+		}
+	}
+}
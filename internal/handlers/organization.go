@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nexodus-io/nexodus/internal/database"
 	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
@@ -27,7 +28,7 @@ func (e errDuplicateOrganization) Error() string {
 
 // CreateOrganization creates a new Organization
 // @Summary      Create an Organization
-// @Description  Creates a named organization with the given CIDR
+// @Description  Creates a named organization, and its default vpc with the given CIDRs
 // @Id			 CreateOrganization
 // @Tags         Organizations
 // @Accept       json
@@ -40,7 +41,7 @@ func (e errDuplicateOrganization) Error() string {
 // @Failure      409  {object}  models.ConflictsError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations [post]
+// @Router       /api/v1/organizations [post]
 func (api *API) CreateOrganization(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "CreateOrganization")
 	defer span.End()
@@ -63,6 +64,38 @@ func (api *API) CreateOrganization(c *gin.Context) {
 		return
 	}
 
+	if err := validateListenPortRange(request.ListenPortMin, request.ListenPortMax); err != nil {
+		c.JSON(err.Status, err.Body)
+		return
+	}
+
+	if request.UpdateChannel != "" && !validUpdateChannel(request.UpdateChannel) {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("update_channel", "must be stable or beta"))
+		return
+	}
+
+	if request.DeviceTTLDays < 0 {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("device_ttl_days", "must not be negative"))
+		return
+	}
+
+	// the default vpc of the organization is created with the prefixes, the prefix of the other family defaults to the
+	// one of the shared IPAM namespace
+	if request.Ipv4Cidr != "" || request.Ipv6Cidr != "" {
+		if request.Ipv4Cidr == "" {
+			request.Ipv4Cidr = defaultIPAMv4Cidr
+		} else if err := util.ValidateIPv4Cidr(request.Ipv4Cidr); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("ipv4_cidr", err.Error()))
+			return
+		}
+		if request.Ipv6Cidr == "" {
+			request.Ipv6Cidr = defaultIPAMv6Cidr
+		} else if err := util.ValidateIPv6Cidr(request.Ipv6Cidr); err != nil || !util.IsIPv6Prefix(request.Ipv6Cidr) {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError("ipv6_cidr", "not an ipv6 cidr"))
+			return
+		}
+	}
+
 	var org models.Organization
 	err := api.transaction(ctx, func(tx *gorm.DB) error {
 		var user models.User
@@ -71,8 +104,14 @@ func (api *API) CreateOrganization(c *gin.Context) {
 		}
 
 		org = models.Organization{
-			Name:        request.Name,
-			Description: request.Description,
+			Name:                  request.Name,
+			Description:           request.Description,
+			ListenPortMin:         request.ListenPortMin,
+			ListenPortMax:         request.ListenPortMax,
+			UpdateChannel:         request.UpdateChannel,
+			RequireDeviceApproval: request.RequireDeviceApproval,
+			DeviceTTLDays:         request.DeviceTTLDays,
+			DeleteExpiredDevices:  request.DeleteExpiredDevices,
 		}
 
 		if res := tx.Create(&org); res.Error != nil {
@@ -95,15 +134,37 @@ func (api *API) CreateOrganization(c *gin.Context) {
 			return res.Error
 		}
 
+		if request.Ipv4Cidr != "" {
+			vpc := models.VPC{
+				Base: models.Base{
+					ID: org.ID,
+				},
+				OrganizationID: org.ID,
+				Description:    "default vpc",
+				PrivateCidr:    true,
+				Ipv4Cidr:       request.Ipv4Cidr,
+				Ipv6Cidr:       request.Ipv6Cidr,
+			}
+			if res := tx.Create(&vpc); res.Error != nil {
+				return fmt.Errorf("failed to create the default vpc: %w", res.Error)
+			}
+			if err := api.setupVPC(ctx, tx, vpc); err != nil {
+				return err
+			}
+		}
+
 		span.SetAttributes(attribute.String("id", org.ID.String()))
 		api.logger.Infof("New organization request [ %s ] request", org.Name)
-		return nil
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionCreate, "organization", org.ID, nil, auditState(org))
 	})
 
 	if err != nil {
 		var duplicate errDuplicateOrganization
+		var apiResponseError *ApiResponseError
 		if errors.Is(err, errUserNotFound) {
 			c.JSON(http.StatusNotFound, models.NewApiError(err))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
 		} else if errors.As(err, &duplicate) {
 			c.JSON(http.StatusConflict, models.NewConflictsError(duplicate.ID))
 		} else {
@@ -139,11 +200,15 @@ func (api *API) CurrentUserHasRole(c *gin.Context, db *gorm.DB, orgIdField strin
 // @Tags         Organizations
 // @Accept       json
 // @Produce      json
+// @Param		 sort    query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit   query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor  query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
 // @Success      200  {object}  []models.Organization
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations [get]
+// @Router       /api/v1/organizations [get]
 func (api *API) ListOrganizations(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListOrganizations")
 	defer span.End()
@@ -155,7 +220,7 @@ func (api *API) ListOrganizations(c *gin.Context) {
 	}
 
 	var orgs []models.Organization
-	db := api.db.WithContext(ctx)
+	db := api.readReplicaDB(ctx)
 	db = api.OrganizationIsReadableByCurrentUser(c, db)
 
 	// handle the special case of a roles filter (since the Organization does not have a roles field)
@@ -196,13 +261,20 @@ func (api *API) ListOrganizations(c *gin.Context) {
 	result := db.Find(&orgs)
 
 	if result.Error != nil {
+		var apiResponseError *ApiResponseError
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else if errors.As(result.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
 		} else {
 			api.SendInternalServerError(c, result.Error)
 		}
 		return
 	}
+	if err := SetNextCursor(c, orgs); err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, orgs)
 }
@@ -221,7 +293,7 @@ func (api *API) ListOrganizations(c *gin.Context) {
 // @Failure		 429  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations/{id} [get]
+// @Router       /api/v1/organizations/{id} [get]
 func (api *API) GetOrganizations(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetOrganizations",
 		trace.WithAttributes(
@@ -234,7 +306,7 @@ func (api *API) GetOrganizations(c *gin.Context) {
 		return
 	}
 	var org models.Organization
-	db := api.db.WithContext(ctx)
+	db := api.readReplicaDB(ctx)
 	result := api.OrganizationIsReadableByCurrentUser(c, db).
 		First(&org, "id = ?", k.String())
 
@@ -250,6 +322,118 @@ func (api *API) GetOrganizations(c *gin.Context) {
 	c.JSON(http.StatusOK, org)
 }
 
+// validateListenPortRange checks that a listen port range is either unset or a valid range of UDP ports
+func validateListenPortRange(min, max int) *ApiResponseError {
+	if min == 0 && max == 0 {
+		return nil
+	}
+	if min < 1 || min > 65535 {
+		return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("listen_port_min", "must be between 1 and 65535"))
+	}
+	if max < 1 || max > 65535 {
+		return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("listen_port_max", "must be between 1 and 65535"))
+	}
+	if min > max {
+		return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("listen_port_max", "must be greater than or equal to listen_port_min"))
+	}
+	return nil
+}
+
+// UpdateOrganization updates an Organization
+// @Summary      Update Organization
+// @Description  Updates an Organization by ID
+// @Id  		 UpdateOrganization
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true "Organization ID"
+// @Param		 update body models.UpdateOrganization true "Organization Update"
+// @Success      200  {object}  models.Organization
+// @Failure		 401  {object}  models.BaseError
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id} [patch]
+func (api *API) UpdateOrganization(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateOrganization", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.UpdateOrganization
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var org models.Organization
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+
+		result := api.OrganizationIsOwnedByCurrentUser(c, tx).First(&org, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else if result.Error != nil {
+			return result.Error
+		}
+		before := auditState(org)
+
+		if request.Description != nil {
+			org.Description = *request.Description
+		}
+		if request.ListenPortMin != nil {
+			org.ListenPortMin = *request.ListenPortMin
+		}
+		if request.ListenPortMax != nil {
+			org.ListenPortMax = *request.ListenPortMax
+		}
+		if err := validateListenPortRange(org.ListenPortMin, org.ListenPortMax); err != nil {
+			return err
+		}
+		if request.UpdateChannel != nil {
+			if *request.UpdateChannel != "" && !validUpdateChannel(*request.UpdateChannel) {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("update_channel", "must be stable or beta"))
+			}
+			org.UpdateChannel = *request.UpdateChannel
+		}
+		if request.RequireDeviceApproval != nil {
+			org.RequireDeviceApproval = *request.RequireDeviceApproval
+		}
+		if request.DeviceTTLDays != nil {
+			if *request.DeviceTTLDays < 0 {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("device_ttl_days", "must not be negative"))
+			}
+			org.DeviceTTLDays = *request.DeviceTTLDays
+		}
+		if request.DeleteExpiredDevices != nil {
+			org.DeleteExpiredDevices = *request.DeleteExpiredDevices
+		}
+
+		if res := tx.Save(&org); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionUpdate, "organization", org.ID, before, auditState(org))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
 // DeleteOrganization handles deleting an existing organization and associated ipam prefix
 // @Summary      Delete Organization
 // @Description  Deletes an existing organization and associated IPAM prefix
@@ -263,7 +447,7 @@ func (api *API) GetOrganizations(c *gin.Context) {
 // @Failure      405  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations/{id} [delete]
+// @Router       /api/v1/organizations/{id} [delete]
 func (api *API) DeleteOrganization(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteOrganization",
 		trace.WithAttributes(
@@ -304,7 +488,10 @@ func (api *API) DeleteOrganization(c *gin.Context) {
 			return result.Error
 		}
 
-		return deleteOrganization(tx, orgID)
+		if err := deleteOrganization(tx, orgID); err != nil {
+			return err
+		}
+		return api.recordAuditEvent(c, tx, orgID, models.AuditActionDelete, "organization", orgID, auditState(org), nil)
 	})
 
 	var apiResponseError *ApiResponseError
@@ -337,6 +524,18 @@ func deleteOrganization(tx *gorm.DB, orgID uuid.UUID) error {
 	if res := tx.Where("organization_id = ?", orgID).Delete(&models.VPC{}); res.Error != nil {
 		return result.Error
 	}
+	if res := tx.Where("organization_id = ?", orgID).Delete(&models.IPReservation{}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Where("organization_id = ?", orgID).Delete(&models.IPExclusionRange{}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Where("webhook_id IN (?)", tx.Model(&models.Webhook{}).Select("id").Where("organization_id = ?", orgID)).Delete(&models.WebhookDelivery{}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Where("organization_id = ?", orgID).Delete(&models.Webhook{}); res.Error != nil {
+		return res.Error
+	}
 	type UserOrganization struct {
 		UserID         uuid.UUID
 		OrganizationID uuid.UUID
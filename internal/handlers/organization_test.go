@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestUpdateOrganizationListenPortRange() {
+	require := suite.Require()
+	uri := fmt.Sprintf("/%s", suite.testUserID)
+	update := func(min, max *int) (int, []byte) {
+		return suite.serve(http.MethodPatch, "/:id", uri, suite.api.UpdateOrganization, models.UpdateOrganization{
+			ListenPortMin: min,
+			ListenPortMax: max,
+		})
+	}
+	port := func(port int) *int {
+		return &port
+	}
+
+	code, body := update(port(51820), port(51830))
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var org models.Organization
+	require.NoError(json.Unmarshal(body, &org))
+	require.Equal(51820, org.ListenPortMin)
+	require.Equal(51830, org.ListenPortMax)
+
+	// the range is validated with the bound that is not updated
+	code, body = update(port(51840), nil)
+	require.Equal(http.StatusBadRequest, code)
+	var validationError models.ValidationError
+	require.NoError(json.Unmarshal(body, &validationError))
+	require.Equal("listen_port_max", validationError.Field)
+
+	code, body = update(nil, port(70000))
+	require.Equal(http.StatusBadRequest, code)
+	require.NoError(json.Unmarshal(body, &validationError))
+	require.Equal("listen_port_max", validationError.Field)
+
+	code, body = update(port(0), nil)
+	require.Equal(http.StatusBadRequest, code)
+	require.NoError(json.Unmarshal(body, &validationError))
+	require.Equal("listen_port_min", validationError.Field)
+
+	// clearing both bounds removes the range
+	code, body = update(port(0), port(0))
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &org))
+	require.False(org.HasListenPortRange())
+}
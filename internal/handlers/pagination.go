@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	NextCursorHeader = "X-Next-Cursor"
+	// DefaultPageSize is the page size of cursor paginated lists when no limit is requested
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+
+	cursorPageKey = "_nexodus.CursorPage"
+)
+
+var schemaCache = &sync.Map{}
+
+// SortField is a field a list is sorted by
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// GetSortFields parses the sort query parameter. It is either a comma separated list of fields, where
+// fields prefixed with a - are sorted in descending order, eg: `hostname,-created_at`, or the JSON
+// encoded field and direction pairs used by the web UI, eg: `["hostname","ASC","created_at","DESC"]`.
+func (q *Query) GetSortFields() ([]SortField, error) {
+	sort := strings.TrimSpace(q.Sort)
+	if sort == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	if strings.HasPrefix(sort, "[") {
+		var parts []string
+		if err := json.Unmarshal([]byte(sort), &parts); err != nil {
+			return nil, err
+		}
+		if len(parts) == 0 || len(parts)%2 != 0 {
+			return nil, fmt.Errorf("must be a list of field and direction pairs")
+		}
+		for i := 0; i < len(parts); i += 2 {
+			switch strings.ToUpper(parts[i+1]) {
+			case "ASC":
+				fields = append(fields, SortField{Name: parts[i]})
+			case "DESC":
+				fields = append(fields, SortField{Name: parts[i], Desc: true})
+			default:
+				return nil, fmt.Errorf("invalid direction: %q", parts[i+1])
+			}
+		}
+		return fields, nil
+	}
+
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Name: strings.TrimLeft(part, "+-"), Desc: strings.HasPrefix(part, "-")}
+		if field.Name == "" {
+			return nil, fmt.Errorf("invalid field: %q", part)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// sortColumn is a validated column of the table a list is sorted by
+type sortColumn struct {
+	table string
+	field *schema.Field
+	desc  bool
+}
+
+func (s sortColumn) column() clause.Column {
+	return clause.Column{Table: s.table, Name: s.field.DBName}
+}
+
+func (s sortColumn) String() string {
+	if s.desc {
+		return "-" + s.field.DBName
+	}
+	return s.field.DBName
+}
+
+// sortColumns looks up the sort fields in the schema of the model, so that only existing columns are used in the ORDER BY
+func sortColumns(sch *schema.Schema, fields []SortField) ([]sortColumn, error) {
+	columns := make([]sortColumn, 0, len(fields))
+	for _, f := range fields {
+		field := sch.LookUpField(f.Name)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("can not sort by %q", f.Name)
+		}
+		columns = append(columns, sortColumn{table: sch.Table, field: field, desc: f.Desc})
+	}
+	return columns, nil
+}
+
+// defaultSortFields parses the default ORDER BY of a list so that it can be used for keyset pagination
+func defaultSortFields(orderBy string) []SortField {
+	var fields []SortField
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil
+		}
+		field := SortField{Name: words[0]}
+		if len(words) == 2 {
+			field.Desc = strings.EqualFold(words[1], "DESC")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// cursorPage describes the page of a cursor paginated list
+type cursorPage struct {
+	columns []sortColumn
+	limit   int
+}
+
+// pageCursor is the position in a list encoded in the cursor query parameter and X-Next-Cursor header
+type pageCursor struct {
+	Sort   string            `json:"sort"`
+	Values []json.RawMessage `json:"values"`
+}
+
+func (p *cursorPage) sort() string {
+	parts := make([]string, 0, len(p.columns))
+	for _, column := range p.columns {
+		parts = append(parts, column.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// decode returns the values of the sort columns of the last item of the previous page
+func (p *cursorPage) decode(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var position pageCursor
+	if err := json.Unmarshal(data, &position); err != nil {
+		return nil, err
+	}
+	if position.Sort != p.sort() || len(position.Values) != len(p.columns) {
+		return nil, fmt.Errorf("cursor does not match the sort order")
+	}
+	values := make([]interface{}, 0, len(p.columns))
+	for i, column := range p.columns {
+		value := reflect.New(column.field.FieldType)
+		if err := json.Unmarshal(position.Values[i], value.Interface()); err != nil {
+			return nil, err
+		}
+		values = append(values, value.Elem().Interface())
+	}
+	return values, nil
+}
+
+// encode returns the cursor of the page that follows the item
+func (p *cursorPage) encode(c *gin.Context, item reflect.Value) (string, error) {
+	position := pageCursor{Sort: p.sort()}
+	for _, column := range p.columns {
+		value, _ := column.field.ValueOf(c, item)
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		position.Values = append(position.Values, data)
+	}
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// after selects the rows that sort after the values of the sort columns
+func (p *cursorPage) after(values []interface{}) clause.Expression {
+	var or []clause.Expression
+	for i, column := range p.columns {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: p.columns[j].column(), Value: values[j]})
+		}
+		if column.desc {
+			and = append(and, clause.Lt{Column: column.column(), Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: column.column(), Value: values[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	if len(or) == 1 {
+		// a single OR condition would be joined to the previous conditions with OR
+		return or[0]
+	}
+	return clause.Or(or...)
+}
+
+// cursorPaginate applies keyset pagination to a list, the list is sorted by the requested fields and then its id
+// so that the order is stable. Use SetNextCursor to return the cursor of the next page with the list.
+func cursorPaginate(db *gorm.DB, model interface{}, c *gin.Context, query Query, sortFields []SortField, defaultOrderBy string) *gorm.DB {
+	sch, err := schema.Parse(model, schemaCache, db.NamingStrategy)
+	if err != nil {
+		db.Error = err
+		return db
+	}
+	idField := sch.LookUpField("id")
+	if idField == nil {
+		db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("cursor", "cursor pagination is not supported by this list"))
+		return db
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if limit < 0 || limit > MaxPageSize {
+		db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("limit", fmt.Sprintf("must be between 1 and %d", MaxPageSize)))
+		return db
+	}
+
+	var columns []sortColumn
+	if len(sortFields) == 0 {
+		// the default order is not always a column of the model, in which case the list is only sorted by id
+		columns, _ = sortColumns(sch, defaultSortFields(defaultOrderBy))
+	} else {
+		columns, err = sortColumns(sch, sortFields)
+		if err != nil {
+			db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("sort", err.Error()))
+			return db
+		}
+	}
+	hasId := false
+	for _, column := range columns {
+		hasId = hasId || column.field == idField
+	}
+	if !hasId {
+		columns = append(columns, sortColumn{table: sch.Table, field: idField})
+	}
+	page := &cursorPage{columns: columns, limit: limit}
+
+	if filter, err := query.GetFilter(); err == nil {
+		db = db.Where(filter)
+	}
+	if query.Cursor != "" {
+		values, err := page.decode(query.Cursor)
+		if err != nil {
+			db.Error = NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError("cursor", "invalid cursor"))
+			return db
+		}
+		db = db.Where(page.after(values))
+	}
+	for _, column := range columns {
+		db = db.Order(clause.OrderByColumn{Column: column.column(), Desc: column.desc})
+	}
+	c.Set(cursorPageKey, page)
+	return db.Limit(limit)
+}
+
+// SetNextCursor sets the X-Next-Cursor header to the cursor of the page following the items of a
+// cursor paginated list. The header is not set once the last page has been reached.
+func SetNextCursor(c *gin.Context, items interface{}) error {
+	value, found := c.Get(cursorPageKey)
+	if !found {
+		return nil
+	}
+	page := value.(*cursorPage)
+	list := reflect.Indirect(reflect.ValueOf(items))
+	if list.Kind() != reflect.Slice || list.Len() < page.limit || list.Len() == 0 {
+		return nil
+	}
+	cursor, err := page.encode(c, reflect.Indirect(list.Index(list.Len()-1)))
+	if err != nil {
+		return err
+	}
+	c.Header("Access-Control-Expose-Headers", TotalCountHeader+", "+NextCursorHeader)
+	c.Header(NextCursorHeader, cursor)
+	return nil
+}
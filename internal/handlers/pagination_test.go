@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestQuerySortFields(t *testing.T) {
+	q := Query{Sort: `hostname, -created_at`}
+	actual, err := q.GetSortFields()
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Name: "hostname"}, {Name: "created_at", Desc: true}}, actual)
+
+	q = Query{Sort: `["name","DESC","id","asc"]`}
+	actual, err = q.GetSortFields()
+	assert.NoError(t, err)
+	assert.Equal(t, []SortField{{Name: "name", Desc: true}, {Name: "id"}}, actual)
+
+	q = Query{Sort: `["name"]`}
+	_, err = q.GetSortFields()
+	assert.Error(t, err)
+
+	q = Query{Sort: `name,,id`}
+	_, err = q.GetSortFields()
+	assert.Error(t, err)
+}
+
+func TestCursorPagination(t *testing.T) {
+	require := require.New(t)
+	db, err := gorm.Open(sqlite.Open("file:cursor?mode=memory"), &gorm.Config{})
+	require.NoError(err)
+	require.NoError(db.AutoMigrate(&models.Organization{}))
+	for i := 0; i < 25; i++ {
+		// pairs of organizations share a description so that the id breaks the ties
+		require.NoError(db.Create(&models.Organization{
+			Name:        fmt.Sprintf("org-%02d", i),
+			Description: fmt.Sprintf("team-%02d", i/2),
+		}).Error)
+	}
+
+	list := func(query url.Values) ([]models.Organization, string, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+		var orgs []models.Organization
+		result := FilterAndPaginate(db, &models.Organization{}, c, "name").Find(&orgs)
+		if result.Error != nil {
+			return nil, "", result.Error
+		}
+		if err := SetNextCursor(c, orgs); err != nil {
+			return nil, "", err
+		}
+		return orgs, c.Writer.Header().Get(NextCursorHeader), nil
+	}
+
+	var all []models.Organization
+	query := url.Values{"limit": {"10"}, "sort": {"-description"}}
+	for pages := 1; ; pages++ {
+		orgs, cursor, err := list(query)
+		require.NoError(err)
+		all = append(all, orgs...)
+		if cursor == "" {
+			require.Equal(3, pages)
+			break
+		}
+		query.Set("cursor", cursor)
+	}
+	require.Len(all, 25)
+	seen := map[string]bool{}
+	for i, org := range all {
+		require.False(seen[org.Name], "%s was listed twice", org.Name)
+		seen[org.Name] = true
+		if i > 0 {
+			require.GreaterOrEqual(all[i-1].Description, org.Description)
+		}
+	}
+
+	// the cursor is only valid for the sort order it was created with
+	_, _, err = list(url.Values{"limit": {"10"}, "sort": {"name"}, "cursor": {query.Get("cursor")}})
+	require.ErrorAs(err, new(*ApiResponseError))
+
+	_, _, err = list(url.Values{"limit": {"10"}, "sort": {"name;drop table organizations"}})
+	require.ErrorAs(err, new(*ApiResponseError))
+
+	_, _, err = list(url.Values{"limit": {fmt.Sprint(MaxPageSize + 1)}})
+	require.ErrorAs(err, new(*ApiResponseError))
+}
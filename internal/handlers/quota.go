@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func quotaExceeded(resource string, limit int) error {
+	return NewApiResponseError(http.StatusForbidden, models.NewQuotaExceededError(resource, limit))
+}
+
+// lockOrganization locks the row of the organization until the end of the transaction and returns its current
+// quota, so that concurrent requests can not both pass a quota check for the last remaining resource
+func lockOrganization(tx *gorm.DB, org models.Organization) (models.Organization, error) {
+	var locked models.Organization
+	if res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, "id = ?", org.ID); res.Error != nil {
+		return org, res.Error
+	}
+	return locked, nil
+}
+
+// checkDeviceQuota returns an error if the organization can not add another device
+func checkDeviceQuota(tx *gorm.DB, org models.Organization) error {
+	org, err := lockOrganization(tx, org)
+	if err != nil {
+		return err
+	}
+	if org.Quota.MaxDevices == 0 {
+		return nil
+	}
+	var count int64
+	if res := tx.Model(&models.Device{}).Where("organization_id = ?", org.ID).Count(&count); res.Error != nil {
+		return res.Error
+	}
+	if count >= int64(org.Quota.MaxDevices) {
+		return quotaExceeded("devices", org.Quota.MaxDevices)
+	}
+	return nil
+}
+
+// checkSecurityGroupQuota returns an error if the organization can not add another security group
+func checkSecurityGroupQuota(tx *gorm.DB, org models.Organization) error {
+	org, err := lockOrganization(tx, org)
+	if err != nil {
+		return err
+	}
+	if org.Quota.MaxSecurityGroups == 0 {
+		return nil
+	}
+	var count int64
+	// the default security group of a vpc has the id of the vpc
+	if res := tx.Model(&models.SecurityGroup{}).
+		Where("organization_id = ? AND id <> vpc_id", org.ID).
+		Count(&count); res.Error != nil {
+		return res.Error
+	}
+	if count >= int64(org.Quota.MaxSecurityGroups) {
+		return quotaExceeded("security_groups", org.Quota.MaxSecurityGroups)
+	}
+	return nil
+}
+
+// checkChildPrefixQuota returns an error if a device of the organization can not advertise the prefixes,
+// the prefixes currently advertised by the device are not counted.
+func checkChildPrefixQuota(tx *gorm.DB, org models.Organization, deviceId uuid.UUID, prefixes []string) error {
+	org, err := lockOrganization(tx, org)
+	if err != nil {
+		return err
+	}
+	if org.Quota.MaxChildPrefixes == 0 || len(prefixes) == 0 {
+		return nil
+	}
+	var advertised []pq.StringArray
+	if res := tx.Model(&models.Device{}).
+		Where("organization_id = ? AND id <> ?", org.ID, deviceId).
+		Pluck("advertise_cidrs", &advertised); res.Error != nil {
+		return res.Error
+	}
+	count := len(prefixes)
+	for _, cidrs := range advertised {
+		count += len(cidrs)
+	}
+	if count > org.Quota.MaxChildPrefixes {
+		return quotaExceeded("child_prefixes", org.Quota.MaxChildPrefixes)
+	}
+	return nil
+}
+
+// GetOrganizationQuota gets the quota of an Organization
+// @Summary      Get Organization Quota
+// @Description  Gets the limits of the resources of an organization
+// @Id 			 GetOrganizationQuota
+// @Tags         Private
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  models.OrganizationQuota
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /private/organizations/{id}/quota [get]
+func (api *API) GetOrganizationQuota(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetOrganizationQuota",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var org models.Organization
+	result := api.db.WithContext(ctx).First(&org, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, org.Quota)
+}
+
+// UpdateOrganizationQuota updates the quota of an Organization
+// @Summary      Update Organization Quota
+// @Description  Sets the limits of the resources of an organization, a limit of 0 means no limit. Existing resources are not removed when a limit is lowered.
+// @Id 			 UpdateOrganizationQuota
+// @Tags         Private
+// @Accept       json
+// @Produce      json
+// @Param		 id     path      string true "Organization ID"
+// @Param		 quota  body      models.OrganizationQuota true "Organization Quota"
+// @Success      200  {object}  models.OrganizationQuota
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /private/organizations/{id}/quota [put]
+func (api *API) UpdateOrganizationQuota(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateOrganizationQuota",
+		trace.WithAttributes(
+			attribute.String("id", c.Param("id")),
+		))
+	defer span.End()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.OrganizationQuota
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	for field, value := range map[string]int{
+		"max_devices":         request.MaxDevices,
+		"max_security_groups": request.MaxSecurityGroups,
+		"max_child_prefixes":  request.MaxChildPrefixes,
+	} {
+		if value < 0 {
+			c.JSON(http.StatusBadRequest, models.NewFieldValidationError(field, "must not be negative"))
+			return
+		}
+	}
+
+	var org models.Organization
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.First(&org, "id = ?", id)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		org.Quota = request
+		if res := tx.Model(&org).Select("quota_max_devices", "quota_max_security_groups", "quota_max_child_prefixes").
+			Updates(&org); res.Error != nil {
+			return res.Error
+		}
+		return nil
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.logger.Infof("Updated the quota of organization [ %s ] to %+v", org.ID, org.Quota)
+	c.JSON(http.StatusOK, org.Quota)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestOrganizationQuota(t *testing.T) {
+	require := require.New(t)
+	db, err := gorm.Open(sqlite.Open("file:quota?mode=memory"), &gorm.Config{})
+	require.NoError(err)
+	require.NoError(db.AutoMigrate(&models.Organization{}, &models.Device{}, &models.SecurityGroup{}))
+
+	org := models.Organization{Base: models.Base{ID: uuid.New()}}
+	require.NoError(db.Create(&org).Error)
+	// the quota is read from the locked row of the organization
+	setQuota := func(quota models.OrganizationQuota) {
+		org.Quota = quota
+		require.NoError(db.Save(&org).Error)
+	}
+	devices := []models.Device{
+		{OrganizationID: org.ID, AdvertiseCidrs: []string{"10.1.0.0/24", "10.2.0.0/24"}},
+		{OrganizationID: org.ID, AdvertiseCidrs: []string{"10.3.0.0/24"}},
+		{OrganizationID: uuid.New(), AdvertiseCidrs: []string{"10.4.0.0/24"}},
+	}
+	require.NoError(db.Create(&devices).Error)
+
+	// a zero quota does not limit the organization
+	require.NoError(checkDeviceQuota(db, org))
+	require.NoError(checkChildPrefixQuota(db, org, uuid.New(), []string{"10.5.0.0/24"}))
+
+	setQuota(models.OrganizationQuota{MaxDevices: 3, MaxChildPrefixes: 4})
+	require.NoError(checkDeviceQuota(db, org))
+	require.NoError(checkChildPrefixQuota(db, org, uuid.New(), []string{"10.5.0.0/24"}))
+	require.ErrorAs(checkChildPrefixQuota(db, org, uuid.New(), []string{"10.5.0.0/24", "10.6.0.0/24"}), new(*ApiResponseError))
+	// the prefixes the device already advertises are replaced
+	require.NoError(checkChildPrefixQuota(db, org, devices[0].ID, []string{"10.5.0.0/24", "10.6.0.0/24", "10.7.0.0/24"}))
+
+	setQuota(models.OrganizationQuota{MaxDevices: 2, MaxChildPrefixes: 4})
+	var apiResponseError *ApiResponseError
+	require.ErrorAs(checkDeviceQuota(db, org), &apiResponseError)
+	require.Equal(models.NewQuotaExceededError("devices", 2), apiResponseError.Body)
+
+	vpcId := uuid.New()
+	require.NoError(db.Create(&[]models.SecurityGroup{
+		{Base: models.Base{ID: vpcId}, VpcId: vpcId, OrganizationID: org.ID},
+		{VpcId: vpcId, OrganizationID: org.ID},
+	}).Error)
+	setQuota(models.OrganizationQuota{MaxSecurityGroups: 2})
+	require.NoError(checkSecurityGroupQuota(db, org))
+	setQuota(models.OrganizationQuota{MaxSecurityGroups: 1})
+	require.ErrorAs(checkSecurityGroupQuota(db, org), new(*ApiResponseError))
+}
+
+func (suite *HandlerTestSuite) TestQuotaExceeded() {
+	require := suite.Require()
+	code, body := suite.serve(http.MethodPut, "/:id", fmt.Sprintf("/%s", suite.testUserID), suite.api.UpdateOrganizationQuota,
+		models.OrganizationQuota{MaxDevices: 1, MaxSecurityGroups: 1})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+
+	createDevice := func(publicKey string) (int, []byte) {
+		return suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+			VpcID:     suite.testUserID,
+			PublicKey: publicKey,
+		})
+	}
+	code, body = createDevice("aquotapubkey1")
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	code, body = createDevice("aquotapubkey2")
+	require.Equal(http.StatusForbidden, code)
+	var quotaError models.QuotaExceededError
+	require.NoError(json.Unmarshal(body, &quotaError))
+	require.Equal(models.NewQuotaExceededError("devices", 1), quotaError)
+
+	createSecurityGroup := func(description string) (int, []byte) {
+		return suite.serve(http.MethodPost, "/security-groups", "/security-groups", func(c *gin.Context) {
+			c.Set("nexodus.fflag.security-groups", true)
+			suite.api.CreateSecurityGroup(c)
+		}, models.AddSecurityGroup{
+			Description: description,
+			VpcId:       suite.testUserID,
+		})
+	}
+	code, body = createSecurityGroup("quota group 1")
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	code, body = createSecurityGroup("quota group 2")
+	require.Equal(http.StatusForbidden, code)
+	require.NoError(json.Unmarshal(body, &quotaError))
+	require.Equal(models.NewQuotaExceededError("security_groups", 1), quotaError)
+}
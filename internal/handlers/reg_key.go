@@ -33,7 +33,7 @@ import (
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/reg-keys [post]
+// @Router       /api/v1/reg-keys [post]
 func (api *API) CreateRegKey(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "CreateRegKey")
 	defer span.End()
@@ -132,7 +132,7 @@ func (api *API) CreateRegKey(c *gin.Context) {
 // @Failure      422  {object}     models.ValidationError
 // @Failure      429  {object}     models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/reg-keys/{id} [patch]
+// @Router       /api/v1/reg-keys/{id} [patch]
 func (api *API) UpdateRegKey(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "UpdateRegKey", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -225,7 +225,7 @@ func NxodusClaims(c *gin.Context, tx *gorm.DB) (*models.NexodusClaims, *ApiRespo
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/reg-keys [get]
+// @Router       /api/v1/reg-keys [get]
 func (api *API) ListRegKeys(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListRegKeys")
 	defer span.End()
@@ -255,7 +255,7 @@ func (api *API) ListRegKeys(c *gin.Context) {
 // @Failure		 429  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/reg-keys/{id} [get]
+// @Router       /api/v1/reg-keys/{id} [get]
 func (api *API) GetRegKey(c *gin.Context) {
 	tokenId := c.Param("id")
 	ctx, span := tracer.Start(c.Request.Context(), "GetRegKey",
@@ -331,7 +331,7 @@ func (api *API) RegKeyIsForCurrentUserOrOrgOwner(c *gin.Context, db *gorm.DB) *g
 // @Failure      405  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/reg-keys/{id} [delete]
+// @Router       /api/v1/reg-keys/{id} [delete]
 func (api *API) DeleteRegKey(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteRegKey",
 		trace.WithAttributes(
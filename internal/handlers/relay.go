@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// the metadata key under which relays describe themselves and report their load
+	relayMetadataKey = "relay"
+	// the metadata key under which devices behind a symmetric NAT report their latency to the relays
+	relayLatencyMetadataKey = "relay-latency"
+
+	// the cost of a relay is expressed in milliseconds of latency, each relay-only device
+	// assigned to a relay and each active peer of a relay add to its cost, and so does each
+	// Mbit/s relayed.
+	relayAssignedCost  = 10.0
+	relayPeerCost      = 1.0
+	relayBandwidthCost = 1.0
+	// the latency assumed to a relay a device did not report a latency for
+	relayUnknownLatency = 100.0
+	// a device moves to another relay only if the cost of its current relay is this much higher,
+	// so that small changes of the load do not move devices back and forth between relays
+	relayRebalanceMargin = 1.25
+
+	// relays must be reachable by all the devices, so they can not be behind a symmetric NAT
+	relayBehindSymmetricNatReason = "can not be set on a device behind a symmetric NAT"
+)
+
+// relayLoad is the load a relay reports in its relay metadata
+type relayLoad struct {
+	Peers  int     `json:"peers"`
+	RxRate float64 `json:"rxrate"` // bytes per second received
+	TxRate float64 `json:"txrate"` // bytes per second sent
+}
+
+type relayCandidate struct {
+	id       uuid.UUID
+	load     relayLoad
+	assigned int
+}
+
+// cost returns the cost of assigning one more device with the latencies to the relays to the relay
+func (r *relayCandidate) cost(latencies map[string]float64) float64 {
+	latency, ok := latencies[r.id.String()]
+	if !ok || latency <= 0 {
+		latency = relayUnknownLatency
+	}
+	mbps := (r.load.RxRate + r.load.TxRate) * 8 / 1e6
+	return latency +
+		float64(r.assigned)*relayAssignedCost +
+		float64(r.load.Peers)*relayPeerCost +
+		mbps*relayBandwidthCost
+}
+
+// selectRelays assigns each relay-only device the relay with the lowest cost, devices keep their
+// current relay unless another relay is clearly better. Returns the relay of each device.
+func selectRelays(relays []*relayCandidate, devices []models.Device, latencies map[uuid.UUID]map[string]float64) map[uuid.UUID]uuid.UUID {
+	assignments := map[uuid.UUID]uuid.UUID{}
+	if len(relays) == 0 {
+		return assignments
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID.String() < devices[j].ID.String()
+	})
+	for _, device := range devices {
+		var best, current *relayCandidate
+		for _, r := range relays {
+			if best == nil || r.cost(latencies[device.ID]) < best.cost(latencies[device.ID]) {
+				best = r
+			}
+			if r.id == device.RelayID {
+				current = r
+			}
+		}
+		if current != nil && current.cost(latencies[device.ID]) <= best.cost(latencies[device.ID])*relayRebalanceMargin {
+			best = current
+		}
+		best.assigned++
+		assignments[device.ID] = best.id
+	}
+	return assignments
+}
+
+// assignRelays assigns the relay-only devices of the vpc their best relay based on the load of the relays and on the
+// latency of the devices to them. Returns true if the relay of a device changed.
+func (api *API) assignRelays(ctx context.Context, tx *gorm.DB, vpcId uuid.UUID) (bool, error) {
+	var devices []models.Device
+	if res := tx.WithContext(ctx).
+		Where("vpc_id = ? AND pending = ? AND (relay = ? OR symmetric_nat = ?)", vpcId, false, true, true).
+		Find(&devices); res.Error != nil {
+		return false, res.Error
+	}
+
+	var relayDevices, relayOnly []models.Device
+	var ids []uuid.UUID
+	for _, d := range devices {
+		ids = append(ids, d.ID)
+		if d.Relay {
+			relayDevices = append(relayDevices, d)
+		} else {
+			relayOnly = append(relayOnly, d)
+		}
+	}
+	if len(relayOnly) == 0 {
+		return false, nil
+	}
+
+	var metadata []models.DeviceMetadata
+	if len(ids) > 0 {
+		if res := tx.WithContext(ctx).
+			Where("device_id IN ? AND key IN ?", ids, []string{relayMetadataKey, relayLatencyMetadataKey}).
+			Find(&metadata); res.Error != nil {
+			return false, res.Error
+		}
+	}
+	loads := map[uuid.UUID]relayLoad{}
+	latencies := map[uuid.UUID]map[string]float64{}
+	for _, m := range metadata {
+		switch m.Key {
+		case relayMetadataKey:
+			var load relayLoad
+			if err := decodeMetadataValue(m.Value, &load); err == nil {
+				loads[m.DeviceID] = load
+			}
+		case relayLatencyMetadataKey:
+			var latency map[string]float64
+			if err := decodeMetadataValue(m.Value, &latency); err == nil {
+				latencies[m.DeviceID] = latency
+			}
+		}
+	}
+
+	// prefer the relays that are online, their load is current
+	var online, all []*relayCandidate
+	for _, d := range relayDevices {
+		r := &relayCandidate{id: d.ID, load: loads[d.ID]}
+		all = append(all, r)
+		if d.Online {
+			online = append(online, r)
+		}
+	}
+	relays := online
+	if len(relays) == 0 {
+		relays = all
+	}
+
+	changed := false
+	assignments := selectRelays(relays, relayOnly, latencies)
+	for _, d := range relayOnly {
+		relayID := assignments[d.ID]
+		if relayID == d.RelayID {
+			continue
+		}
+		if res := tx.WithContext(ctx).Model(&models.Device{}).
+			Where("id = ?", d.ID).
+			Update("relay_id", relayID); res.Error != nil {
+			return false, fmt.Errorf("failed to assign the relay of device %s: %w", d.ID, res.Error)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// decodeMetadataValue decodes the value of a metadata entry into v
+func decodeMetadataValue(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
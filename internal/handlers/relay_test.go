@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectRelays(t *testing.T) {
+	require := require.New(t)
+	relayA := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	relayB := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	device := func(id string, relayID uuid.UUID) models.Device {
+		d := models.Device{RelayID: relayID, SymmetricNat: true}
+		d.ID = uuid.MustParse(id)
+		return d
+	}
+	relays := func(loadA, loadB relayLoad) []*relayCandidate {
+		return []*relayCandidate{{id: relayA, load: loadA}, {id: relayB, load: loadB}}
+	}
+	d1 := device("00000000-0000-0000-0000-000000000001", uuid.Nil)
+	d2 := device("00000000-0000-0000-0000-000000000002", uuid.Nil)
+
+	// without a relay no relay is assigned
+	require.Empty(selectRelays(nil, []models.Device{d1}, nil))
+
+	// the devices are spread over the relays with the same load and latency
+	assignments := selectRelays(relays(relayLoad{}, relayLoad{}), []models.Device{d1, d2}, nil)
+	require.NotEqual(assignments[d1.ID], assignments[d2.ID])
+
+	// the relay with the lower latency wins
+	latencies := map[uuid.UUID]map[string]float64{
+		d1.ID: {relayA.String(): 80, relayB.String(): 5},
+	}
+	assignments = selectRelays(relays(relayLoad{}, relayLoad{}), []models.Device{d1}, latencies)
+	require.Equal(relayB, assignments[d1.ID])
+
+	// the busy relay loses despite its lower latency
+	assignments = selectRelays(relays(relayLoad{}, relayLoad{Peers: 20, RxRate: 5e6, TxRate: 5e6}), []models.Device{d1}, latencies)
+	require.Equal(relayA, assignments[d1.ID])
+
+	// a device keeps its relay unless another relay is clearly better
+	d1 = device(d1.ID.String(), relayA)
+	latencies[d1.ID] = map[string]float64{relayA.String(): 22, relayB.String(): 20}
+	assignments = selectRelays(relays(relayLoad{}, relayLoad{}), []models.Device{d1}, latencies)
+	require.Equal(relayA, assignments[d1.ID])
+}
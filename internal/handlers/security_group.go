@@ -68,11 +68,15 @@ func (api *API) SecurityGroupIsWriteableByCurrentUser(c *gin.Context, db *gorm.D
 // @Accepts		 json
 // @Produce      json
 // @Param		 gt_revision       query     uint64 false "greater than revision"
+// @Param		 sort              query     string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit             query     int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor            query     string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
 // @Success      200  {object}  []models.SecurityGroup
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/security-groups [get]
+// @Router       /api/v1/security-groups [get]
 func (api *API) ListSecurityGroups(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListSecurityGroups")
 	defer span.End()
@@ -120,7 +124,7 @@ func (api *API) ListSecurityGroups(c *gin.Context) {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id}/security-groups [get]
+// @Router       /api/v1/vpcs/{id}/security-groups [get]
 func (api *API) ListSecurityGroupsInVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListSecurityGroupsInVPC",
 		trace.WithAttributes(
@@ -178,7 +182,7 @@ func (api *API) ListSecurityGroupsInVPC(c *gin.Context) {
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/security-groups/{id} [get]
+// @Router       /api/v1/security-groups/{id} [get]
 func (api *API) GetSecurityGroup(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetSecurityGroup", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -213,11 +217,12 @@ func (api *API) GetSecurityGroup(c *gin.Context) {
 // @Success      201  {object}  models.SecurityGroup
 // @Failure      400  {object}  models.BaseError
 // @Failure      401  {object}  models.BaseError
+// @Failure      403  {object}  models.QuotaExceededError
 // @Failure      409  {object}  models.ConflictsError
 // @Failure      422  {object}  models.ValidationError
 // @Failure      429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/security-groups [post]
+// @Router       /api/v1/security-groups [post]
 func (api *API) CreateSecurityGroup(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "CreateSecurityGroup")
 	defer span.End()
@@ -257,9 +262,16 @@ func (api *API) CreateSecurityGroup(c *gin.Context) {
 	err := api.transaction(ctx, func(tx *gorm.DB) error {
 		var vpc models.VPC
 		if res := api.VPCIsOwnedByCurrentUser(c, tx).
+			Preload("Organization").
 			First(&vpc, "id = ?", request.VpcId); res.Error != nil {
 			return res.Error
 		}
+		if err := api.checkFeatureFlag(c, "security-groups", vpc.OrganizationID); err != nil {
+			return err
+		}
+		if err := checkSecurityGroupQuota(tx, *vpc.Organization); err != nil {
+			return err
+		}
 
 		sg = models.SecurityGroup{
 			VpcId:          vpc.ID,
@@ -276,12 +288,15 @@ func (api *API) CreateSecurityGroup(c *gin.Context) {
 
 		span.SetAttributes(attribute.String("id", sg.ID.String()))
 		api.logger.Infof("New security group created [ %s ] in organization [ %s ]", sg.ID, vpc.ID)
-		return nil
+		return api.recordAuditEvent(c, tx, sg.OrganizationID, models.AuditActionCreate, "security_group", sg.ID, nil, auditState(sg))
 	})
 
 	if err != nil {
+		var apiResponseError *ApiResponseError
 		if errors.Is(err, errUserNotFound) {
 			c.JSON(http.StatusNotFound, models.NewApiError(err))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
 		} else {
 			api.SendInternalServerError(c, err)
 		}
@@ -322,7 +337,7 @@ func (api *API) notifySecurityGroupChange(c *gin.Context, orgId uuid.UUID) {
 // @Failure      400  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/security-groups/{id} [delete]
+// @Router       /api/v1/security-groups/{id} [delete]
 func (api *API) DeleteSecurityGroup(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteSecurityGroup", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -352,12 +367,16 @@ func (api *API) DeleteSecurityGroup(c *gin.Context) {
 			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("default security group cannot be deleted"))
 		}
 
-		var count int64
+		var count, attached int64
 		res := tx.Model(&models.Device{}).Where("security_group_id = ?", secGroupID).Count(&count)
 		if res.Error != nil {
 			return res.Error
 		}
-		if count > 0 {
+		res = tx.Model(&models.DeviceSecurityGroup{}).Where("security_group_id = ?", secGroupID).Count(&attached)
+		if res.Error != nil {
+			return res.Error
+		}
+		if count+attached > 0 {
 			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("security group cannot be deleted while devices are still using it"))
 		}
 
@@ -371,7 +390,7 @@ func (api *API) DeleteSecurityGroup(c *gin.Context) {
 			return res.Error
 		}
 
-		return nil
+		return api.recordAuditEvent(c, tx, sg.OrganizationID, models.AuditActionDelete, "security_group", sg.ID, auditState(sg), nil)
 	})
 
 	if err != nil {
@@ -407,7 +426,7 @@ func (api *API) DeleteSecurityGroup(c *gin.Context) {
 // @Failure      422  {object}     models.ValidationError
 // @Failure      429  {object}     models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/security-groups/{id} [patch]
+// @Router       /api/v1/security-groups/{id} [patch]
 func (api *API) UpdateSecurityGroup(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "UpdateSecurityGroup", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -453,6 +472,7 @@ func (api *API) UpdateSecurityGroup(c *gin.Context) {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return errSecurityGroupNotFound
 		}
+		before := auditState(securityGroup)
 
 		if request.Description != nil {
 			securityGroup.Description = *request.Description
@@ -470,7 +490,7 @@ func (api *API) UpdateSecurityGroup(c *gin.Context) {
 			return res.Error
 		}
 
-		return nil
+		return api.recordAuditEvent(c, tx, securityGroup.OrganizationID, models.AuditActionUpdate, "security_group", securityGroup.ID, before, auditState(securityGroup))
 	})
 
 	if err != nil {
@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/handlers/fetchmgr"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errServiceNotFound = errors.New("service not found")
+
+type serviceList []*models.Service
+
+func (d serviceList) Item(i int) (any, uint64, gorm.DeletedAt) {
+	item := d[i]
+	return item, item.Revision, item.DeletedAt
+}
+
+func (d serviceList) Len() int {
+	return len(d)
+}
+
+func (api *API) ServiceIsReadableByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return api.CurrentUserHasRole(c, db, "organization_id", MemberRoles)
+}
+
+// ServiceIsWriteableByCurrentUser limits the services to the services of the devices owned by the current user
+func (api *API) ServiceIsWriteableByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
+	userId := api.GetCurrentUserID(c)
+	return db.Where("device_id in (SELECT id FROM devices WHERE owner_id = ? AND deleted_at IS NULL)", userId)
+}
+
+// validateService checks the port and protocol the service can be reached at
+func validateService(port int, protocol string) error {
+	if port < 1 || port > 65535 {
+		return NewApiResponseError(http.StatusUnprocessableEntity, models.NewFieldValidationError("port", "must be between 1 and 65535"))
+	}
+	if protocol != protoTCP && protocol != protoUDP {
+		return NewApiResponseError(http.StatusUnprocessableEntity, models.NewFieldValidationError("protocol", "must be tcp or udp"))
+	}
+	return nil
+}
+
+// ListServices lists all Services
+// @Summary      List Services
+// @Description  Lists all Services
+// @Id  		 ListServices
+// @Tags         Services
+// @Accepts		 json
+// @Produce      json
+// @Param		 gt_revision       query     uint64 false "greater than revision"
+// @Param		 sort              query     string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit             query     int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor            query     string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
+// @Success      200  {object}  []models.Service
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/services [get]
+func (api *API) ListServices(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListServices")
+	defer span.End()
+
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err))
+		return
+	}
+
+	api.sendList(c, ctx, func(db *gorm.DB) (fetchmgr.ResourceList, error) {
+		var items serviceList
+		db = api.ServiceIsReadableByCurrentUser(c, db)
+		db = FilterAndPaginateWithQuery(db, &models.Service{}, c, query, "name")
+		result := db.Find(&items)
+		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, result.Error
+		}
+		return items, nil
+	})
+}
+
+// ListServicesInVPC lists all Services in a VPC
+// @Summary      List Services in a VPC
+// @Description  Lists the Services of the organization of a VPC
+// @Id  		 ListServicesInVPC
+// @Tags         VPC
+// @Accepts		 json
+// @Produce      json
+// @Param		 gt_revision       query     uint64 false "greater than revision"
+// @Param        id                path      string  true "VPC ID"
+// @Success      200  {object}  []models.Service
+// @Failure		 401  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/vpcs/{id}/services [get]
+func (api *API) ListServicesInVPC(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListServicesInVPC",
+		trace.WithAttributes(
+			attribute.String("vpc_id", c.Param("id")),
+		))
+	defer span.End()
+
+	vpcId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var vpc models.VPC
+	db := api.db.WithContext(ctx)
+	result := api.VPCIsReadableByCurrentUser(c, db).
+		First(&vpc, "id = ?", vpcId.String())
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("vpc"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	var query Query
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewApiError(err))
+		return
+	}
+
+	api.sendList(c, ctx, func(db *gorm.DB) (fetchmgr.ResourceList, error) {
+		var items serviceList
+		db = db.Where("organization_id = ?", vpc.OrganizationID.String())
+		db = FilterAndPaginateWithQuery(db, &models.Service{}, c, query, "id")
+		result := db.Find(&items)
+		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, result.Error
+		}
+		return items, nil
+	})
+}
+
+// GetService gets a Service by ID
+// @Summary      Get Service
+// @Description  Gets a service by ID
+// @Id  		 GetService
+// @Tags         Services
+// @Accepts		 json
+// @Produce      json
+// @Param        id   path      string  true "Service ID"
+// @Success      200  {object}  models.Service
+// @Failure		 401  {object}  models.BaseError
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/services/{id} [get]
+func (api *API) GetService(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetService", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+	k, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	db := api.db.WithContext(ctx)
+	var service models.Service
+	result := api.ServiceIsReadableByCurrentUser(c, db).
+		First(&service, "id = ?", k)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("service"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, service)
+}
+
+// CreateService handles adding a new Service
+// @Summary      Add Service
+// @Id  		 CreateService
+// @Tags         Services
+// @Description  Adds a new Service, a named port of a device published in the overlay DNS of its organization
+// @Accepts		 json
+// @Produce      json
+// @Param        Service   body   models.AddService  true "Add Service"
+// @Success      201  {object}  models.Service
+// @Failure      400  {object}  models.BaseError
+// @Failure      401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure      409  {object}  models.ConflictsError
+// @Failure      422  {object}  models.ValidationError
+// @Failure      429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/services [post]
+func (api *API) CreateService(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateService")
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	var request models.AddService
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.DeviceID == uuid.Nil {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("device_id"))
+		return
+	}
+	if !util.IsValidDNSLabel(request.Name) {
+		c.JSON(http.StatusUnprocessableEntity, models.NewFieldValidationError("name", "must be a lowercase DNS label"))
+		return
+	}
+	if request.Protocol == "" {
+		request.Protocol = protoTCP
+	}
+
+	var service models.Service
+	err := api.transaction(ctx, func(tx *gorm.DB) error {
+		if err := validateService(request.Port, request.Protocol); err != nil {
+			return err
+		}
+		var device models.Device
+		if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+			First(&device, "id = ?", request.DeviceID); res.Error != nil {
+			if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+			}
+			return res.Error
+		}
+		if err := api.checkFeatureFlag(c, "devices", device.OrganizationID); err != nil {
+			return err
+		}
+		// the name is also the name of the service in the overlay DNS, so it is shared with the devices
+		owner, err := dnsNameOwner(tx, models.Device{OrganizationID: device.OrganizationID}, request.Name)
+		if err != nil {
+			return err
+		}
+		if owner != uuid.Nil {
+			return NewApiResponseError(http.StatusConflict, models.NewConflictsError(owner.String()))
+		}
+
+		service = models.Service{
+			OrganizationID: device.OrganizationID,
+			DeviceID:       device.ID,
+			Name:           request.Name,
+			Port:           request.Port,
+			Protocol:       request.Protocol,
+			Description:    request.Description,
+		}
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Create(&service); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", service.ID.String()))
+		api.logger.Infof("New service created [ %s ] for device [ %s ]", service.Name, device.ID)
+		return api.recordAuditEvent(c, tx, service.OrganizationID, models.AuditActionCreate, "service", service.ID, nil, auditState(service))
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, service.OrganizationID, "/services/vpc=%s")
+	c.JSON(http.StatusCreated, service)
+}
+
+// UpdateService updates a Service
+// @Summary      Update Service
+// @Description  Updates a Service by ID
+// @Id           UpdateService
+// @Tags         Services
+// @Accepts      json
+// @Produce      json
+// @Param        id path      string  true "Service ID"
+// @Param        update body       models.UpdateService true "Service Update"
+// @Success      200  {object}     models.Service
+// @Failure      400  {object}     models.BaseError
+// @Failure      401  {object}     models.BaseError
+// @Failure      404  {object}     models.BaseError
+// @Failure      422  {object}     models.ValidationError
+// @Failure      429  {object}     models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/services/{id} [patch]
+func (api *API) UpdateService(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateService", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	k, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.UpdateService
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+
+	var service models.Service
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		result := api.ServiceIsWriteableByCurrentUser(c, tx).
+			First(&service, "id = ?", k)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return errServiceNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		before := auditState(service)
+
+		if request.DeviceID != nil && *request.DeviceID != service.DeviceID {
+			// the service can only move to another device of the owner in the same organization
+			var device models.Device
+			if res := api.DeviceIsOwnedByCurrentUser(c, tx).
+				First(&device, "id = ? AND organization_id = ?", *request.DeviceID, service.OrganizationID); res.Error != nil {
+				if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+					return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("device"))
+				}
+				return res.Error
+			}
+			service.DeviceID = device.ID
+		}
+		if request.Port != nil {
+			service.Port = *request.Port
+		}
+		if request.Protocol != nil {
+			service.Protocol = *request.Protocol
+		}
+		if request.Description != nil {
+			service.Description = *request.Description
+		}
+		if err := validateService(service.Port, service.Protocol); err != nil {
+			return err
+		}
+
+		if res := tx.
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "revision"}}}).
+			Save(&service); res.Error != nil {
+			return res.Error
+		}
+
+		return api.recordAuditEvent(c, tx, service.OrganizationID, models.AuditActionUpdate, "service", service.ID, before, auditState(service))
+	})
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.Is(err, errServiceNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("service"))
+		} else if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, service.OrganizationID, "/services/vpc=%s")
+	c.JSON(http.StatusOK, service)
+}
+
+// DeleteService handles deleting an existing Service
+// @Summary      Delete Service
+// @Description  Deletes an existing Service
+// @Id 			 DeleteService
+// @Tags         Services
+// @Accepts		 json
+// @Produce      json
+// @Param        id   path      string  true "Service ID"
+// @Success      200  {object}  models.Service
+// @Failure      400  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/services/{id} [delete]
+func (api *API) DeleteService(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteService", trace.WithAttributes(
+		attribute.String("id", c.Param("id")),
+	))
+	defer span.End()
+
+	if !api.FlagCheck(c, "devices") {
+		return
+	}
+
+	k, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var service models.Service
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		result := api.ServiceIsWriteableByCurrentUser(c, tx).
+			First(&service, "id = ?", k)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return errServiceNotFound
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if res := tx.Delete(&service, "id = ?", service.ID); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, service.OrganizationID, models.AuditActionDelete, "service", service.ID, auditState(service), nil)
+	})
+	if err != nil {
+		if errors.Is(err, errServiceNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("service"))
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	api.notifyOrganizationVpcs(ctx, service.OrganizationID, "/services/vpc=%s")
+	c.JSON(http.StatusOK, service)
+}
+
+// deleteDeviceServices deletes the services of a deleted device
+func deleteDeviceServices(tx *gorm.DB, device models.Device) error {
+	return tx.Delete(&models.Service{}, "device_id = ?", device.ID).Error
+}
+
+// notifyOrganizationVpcs signals the channel of each of the vpcs of the organization, the channel is formatted
+// with the id of the vpc
+func (api *API) notifyOrganizationVpcs(ctx context.Context, orgId uuid.UUID, channel string) {
+	vpcIds := []uuid.UUID{}
+	result := api.db.WithContext(ctx).Model(&models.VPC{}).
+		Where("organization_id = ?", orgId).
+		Distinct().
+		Pluck("id", &vpcIds)
+	if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		api.logger.Errorf("Failed to fetch vpc ids for organization %s: %s", orgId, result.Error)
+		return
+	}
+
+	for _, id := range vpcIds {
+		api.signalBus.Notify(fmt.Sprintf(channel, id.String()))
+	}
+}
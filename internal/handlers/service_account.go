@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"gorm.io/gorm"
+)
+
+// ServiceAccountScope marks the access tokens of the service accounts, the api accepts them like the tokens of
+// the users of the organization of the service account.
+const ServiceAccountScope = "service-account"
+
+// serviceAccountTokenScopes are the scopes of the access tokens of the service accounts, the scopes of the
+// tokens of the users
+var serviceAccountTokenScopes = []string{
+	ServiceAccountScope,
+	"read:organizations", "write:organizations",
+	"read:devices", "write:devices",
+	"read:users", "write:users",
+}
+
+// serviceAccountTokenExpiry is the lifetime of the access tokens of the service accounts, the clients get a new
+// token with their client credentials when it expires.
+const serviceAccountTokenExpiry = time.Hour
+
+// serviceAccountIdpID is the IdpID of the user of a service account, it can not collide with the subjects of
+// the identity providers.
+func serviceAccountIdpID(id uuid.UUID) string {
+	return "service-account:" + id.String()
+}
+
+func hashClientSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// ListServiceAccounts lists the service accounts of an organization
+// @Summary      List Service Accounts
+// @Description  Lists the service accounts of the organization
+// @Id           ListServiceAccounts
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  []models.ServiceAccount
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/service-accounts [get]
+func (api *API) ListServiceAccounts(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListServiceAccounts",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	accounts := []models.ServiceAccount{}
+	db = FilterAndPaginate(db.Where("organization_id = ?", org.ID), &models.ServiceAccount{}, c, "name")
+	if result := db.Find(&accounts); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching service accounts from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+// GetServiceAccount gets a service account of an organization
+// @Summary      Get Service Account
+// @Description  Gets a service account of the organization by ID
+// @Id           GetServiceAccount
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 account_id  path      string true "Service Account ID"
+// @Success      200  {object}  models.ServiceAccount
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id} [get]
+func (api *API) GetServiceAccount(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetServiceAccount",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("account_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	accountId, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("account_id"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	if res := api.OrganizationIsReadableByCurrentUser(c, db).
+		First(&org, "id = ?", orgId); res.Error != nil {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+	var account models.ServiceAccount
+	if res := db.First(&account, "id = ? AND organization_id = ?", accountId, org.ID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("service account"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// CreateServiceAccount adds a service account to an organization
+// @Summary      Create a Service Account
+// @Description  Adds a service account to the organization, the client secret of the account is only returned in the response
+// @Id           CreateServiceAccount
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id              path   string true "Organization ID"
+// @Param        ServiceAccount  body   models.AddServiceAccount  true  "Add Service Account"
+// @Success      201  {object}  models.ServiceAccount
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/service-accounts [post]
+func (api *API) CreateServiceAccount(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateServiceAccount",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddServiceAccount
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("name"))
+		return
+	}
+	if len(util.FilterOutAllowed(request.Roles, allowedRoles)) > 0 {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("roles", "allowed values are: "+strings.Join(maps.Keys(allowedRoles), ", ")))
+		return
+	}
+	if len(request.Roles) == 0 {
+		request.Roles = []string{"member"}
+	}
+
+	// use a wg private key as the secret, since it should be hard to guess.
+	secret, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+
+	var account models.ServiceAccount
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+
+		account = models.ServiceAccount{
+			Base:           models.Base{ID: uuid.New()},
+			OrganizationID: org.ID,
+			OwnerID:        api.GetCurrentUserID(c),
+			Name:           request.Name,
+			Description:    request.Description,
+			Roles:          request.Roles,
+			SecretHash:     hashClientSecret(secret.String()),
+		}
+
+		// the api requests of the service account are made as a member of the organization
+		user := models.User{
+			IdpID:    serviceAccountIdpID(account.ID),
+			UserName: request.Name,
+			FullName: request.Description,
+		}
+		if res := tx.Create(&user); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Create(&models.UserOrganization{
+			UserID:         user.ID,
+			OrganizationID: org.ID,
+			Roles:          request.Roles,
+		}); res.Error != nil {
+			return res.Error
+		}
+
+		account.UserID = user.ID
+		if res := tx.Create(&account); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", account.ID.String()))
+		api.logger.Infof("New service account [ %s ] in organization [ %s ]", account.Name, org.ID)
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionCreate, "service_account", account.ID, nil, auditState(account))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	account.ClientSecret = secret.String()
+	c.JSON(http.StatusCreated, account)
+}
+
+// DeleteServiceAccount deletes a service account of an organization
+// @Summary      Delete Service Account
+// @Description  Deletes a service account of the organization, its access tokens are not accepted anymore
+// @Id           DeleteServiceAccount
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 account_id  path      string true "Service Account ID"
+// @Success      200  {object}  models.ServiceAccount
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id} [delete]
+func (api *API) DeleteServiceAccount(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteServiceAccount",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("account_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	accountId, err := uuid.Parse(c.Param("account_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("account_id"))
+		return
+	}
+
+	var account models.ServiceAccount
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&account, "id = ? AND organization_id = ?", accountId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("service account"))
+		}
+
+		var count int64
+		if res := tx.Model(&models.Device{}).Where("owner_id = ?", account.UserID).Count(&count); res.Error != nil {
+			return res.Error
+		}
+		if count > 0 {
+			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("service account cannot be deleted while devices owned by the service account are still attached"))
+		}
+
+		// Cascade delete the records of the user of the service account
+		if res := tx.Where("owner_id = ?", account.UserID).Delete(&models.RegKey{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Where("user_id = ?", account.UserID).Delete(&models.APIKey{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Where("user_id = ?", account.UserID).Delete(&models.UserOrganization{}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Model(&models.User{}).
+			Where("id = ?", account.UserID).
+			Updates(map[string]interface{}{
+				"idp_id":     nil,
+				"deleted_at": gorm.DeletedAt{Time: time.Now(), Valid: true},
+			}); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Delete(&account); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionDelete, "service_account", account.ID, auditState(account), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// ServiceAccountToken issues an access token to the client credentials of a service account.
+// @Summary      Service Account Token
+// @Description  Issues an access token to the client credentials of a service account (the client credentials grant of RFC 6749), the client id is the ID of the service account. The credentials are sent with HTTP basic authentication or in the form.
+// @Id           ServiceAccountToken
+// @Tags         Auth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true  "client_credentials"
+// @Param        client_id      formData  string  false "ID of the service account"
+// @Param        client_secret  formData  string  false "client secret of the service account"
+// @Success      200  {object}  models.ServiceAccountToken
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /device/token [post]
+func (api *API) ServiceAccountToken(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ServiceAccountToken")
+	defer span.End()
+	c.Header("Cache-Control", "no-store")
+
+	if grantType := c.PostForm("grant_type"); grantType != "client_credentials" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+	clientId, clientSecret, ok := c.Request.BasicAuth()
+	if ok {
+		// the credentials of the basic authentication are form encoded (RFC 6749 section 2.3.1)
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+	invalidClient := func() {
+		c.Header("WWW-Authenticate", `Basic realm="nexodus"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+	}
+	accountId, err := uuid.Parse(clientId)
+	if err != nil || clientSecret == "" {
+		invalidClient()
+		return
+	}
+
+	var account models.ServiceAccount
+	db := api.db.WithContext(ctx)
+	if res := db.First(&account, "id = ?", accountId); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			invalidClient()
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(hashClientSecret(clientSecret)), []byte(account.SecretHash)) != 1 {
+		invalidClient()
+		return
+	}
+
+	span.SetAttributes(attribute.String("id", account.ID.String()))
+	now := time.Now()
+	claims := models.NexodusClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    api.URL,
+			ID:        account.ID.String(),
+			Subject:   serviceAccountIdpID(account.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(serviceAccountTokenExpiry)),
+		},
+		Scope: strings.Join(serviceAccountTokenScopes, " "),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(api.PrivateKey)
+	if err != nil {
+		api.SendInternalServerError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.ServiceAccountToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(serviceAccountTokenExpiry.Seconds()),
+	})
+}
+
+// ServiceAccountUserID returns the ID of the user of the service account of an access token, the tokens of the
+// deleted service accounts are not accepted.
+func (api *API) ServiceAccountUserID(ctx context.Context, accountId string) (uuid.UUID, error) {
+	id, err := uuid.Parse(accountId)
+	if err != nil {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	var account models.ServiceAccount
+	if res := api.db.WithContext(ctx).First(&account, "id = ?", id); res.Error != nil {
+		return uuid.Nil, res.Error
+	}
+	return account.UserID, nil
+}
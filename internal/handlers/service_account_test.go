@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"gorm.io/gorm"
+)
+
+func (suite *HandlerTestSuite) TestServiceAccounts() {
+	require := suite.Require()
+	if suite.api.PrivateKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(err)
+		suite.api.PrivateKey = key
+	}
+
+	create := func(request models.AddServiceAccount) (int, []byte) {
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/:id/service-accounts", fmt.Sprintf("/%s/service-accounts", suite.testUserID),
+			suite.api.CreateServiceAccount, bytes.NewBuffer(suite.jsonMarshal(request)),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	code, body := create(models.AddServiceAccount{Name: "ci", Roles: []string{"admin"}})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddServiceAccount{Name: "ci", Description: "the ci pipelines"})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var account models.ServiceAccount
+	require.NoError(json.Unmarshal(body, &account))
+	require.NotEmpty(account.ClientSecret)
+	require.Equal([]string{"member"}, []string(account.Roles))
+
+	// the user of the service account is a member of the organization
+	var membership models.UserOrganization
+	require.NoError(suite.api.db.First(&membership, "user_id = ? AND organization_id = ?", account.UserID, suite.testUserID).Error)
+
+	// the client secret is only returned when the service account is created
+	_, res, err := suite.ServeRequest(
+		http.MethodGet,
+		"/:id/service-accounts", fmt.Sprintf("/%s/service-accounts", suite.testUserID),
+		suite.api.ListServiceAccounts, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code)
+	var accounts []models.ServiceAccount
+	require.NoError(json.Unmarshal(res.Body.Bytes(), &accounts))
+	require.Len(accounts, 1)
+	require.Equal(account.ID, accounts[0].ID)
+	require.Empty(accounts[0].ClientSecret)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/device/token", suite.api.ServiceAccountToken)
+	token := func(form url.Values, clientId, clientSecret string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, "/device/token", strings.NewReader(form.Encode()))
+		require.NoError(err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if clientId != "" {
+			req.SetBasicAuth(url.QueryEscape(clientId), url.QueryEscape(clientSecret))
+		}
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+	grant := url.Values{"grant_type": {"client_credentials"}}
+
+	res = token(url.Values{"grant_type": {"password"}}, account.ID.String(), account.ClientSecret)
+	require.Equal(http.StatusBadRequest, res.Code)
+	res = token(grant, account.ID.String(), "not the secret")
+	require.Equal(http.StatusUnauthorized, res.Code)
+
+	// the credentials are sent with basic authentication or in the form
+	res = token(url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {account.ID.String()},
+		"client_secret": {account.ClientSecret},
+	}, "", "")
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", res.Body.String())
+	res = token(grant, account.ID.String(), account.ClientSecret)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", res.Body.String())
+	var tokenResponse models.ServiceAccountToken
+	require.NoError(json.Unmarshal(res.Body.Bytes(), &tokenResponse))
+	require.Equal("Bearer", tokenResponse.TokenType)
+
+	claims := models.NexodusClaims{}
+	_, err = jwt.ParseWithClaims(tokenResponse.AccessToken, &claims, func(t *jwt.Token) (interface{}, error) {
+		return &suite.api.PrivateKey.PublicKey, nil
+	})
+	require.NoError(err)
+	require.Equal(account.ID.String(), claims.ID)
+	require.Contains(strings.Fields(claims.Scope), ServiceAccountScope)
+	require.Contains(strings.Fields(claims.Scope), "write:devices")
+
+	userId, err := suite.api.ServiceAccountUserID(context.Background(), claims.ID)
+	require.NoError(err)
+	require.Equal(account.UserID, userId)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodDelete,
+		"/:id/service-accounts/:account_id", fmt.Sprintf("/%s/service-accounts/%s", suite.testUserID, account.ID),
+		suite.api.DeleteServiceAccount, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", res.Body.String())
+
+	// the tokens of a deleted service account are not accepted
+	_, err = suite.api.ServiceAccountUserID(context.Background(), claims.ID)
+	require.ErrorIs(err, gorm.ErrRecordNotFound)
+	res = token(grant, account.ID.String(), account.ClientSecret)
+	require.Equal(http.StatusUnauthorized, res.Code)
+	require.ErrorIs(suite.api.db.First(&membership, "user_id = ?", account.UserID).Error, gorm.ErrRecordNotFound)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/:id/service-accounts/:account_id", fmt.Sprintf("/%s/service-accounts/%s", suite.testUserID, account.ID),
+		suite.api.GetServiceAccount, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, res.Code)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func (suite *HandlerTestSuite) TestServices() {
+	require := suite.Require()
+
+	key, err := wgtypes.GeneratePrivateKey()
+	require.NoError(err)
+	reqBody, err := json.Marshal(models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: key.PublicKey().String(),
+		Hostname:  "db1",
+	})
+	require.NoError(err)
+	_, res, err := suite.ServeRequest(
+		http.MethodPost,
+		"/", "/",
+		suite.api.CreateDevice, bytes.NewBuffer(reqBody),
+	)
+	require.NoError(err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusCreated, res.Code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+
+	create := func(request models.AddService) (int, []byte) {
+		reqBody, err := json.Marshal(request)
+		require.NoError(err)
+		_, res, err := suite.ServeRequest(
+			http.MethodPost,
+			"/", "/",
+			suite.api.CreateService, bytes.NewBuffer(reqBody),
+		)
+		require.NoError(err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(err)
+		return res.Code, body
+	}
+
+	code, body := create(models.AddService{DeviceID: device.ID, Name: "postgres", Port: 5432})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var service models.Service
+	require.NoError(json.Unmarshal(body, &service))
+	require.Equal(device.ID, service.DeviceID)
+	require.Equal("tcp", service.Protocol)
+
+	// the names are shared with the devices of the organization
+	code, body = create(models.AddService{DeviceID: device.ID, Name: "postgres", Port: 5433})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddService{DeviceID: device.ID, Name: device.DnsName, Port: 5432})
+	require.Equal(http.StatusConflict, code, "HTTP error: %s", string(body))
+
+	code, body = create(models.AddService{DeviceID: device.ID, Name: "Not A Label", Port: 5432})
+	require.Equal(http.StatusUnprocessableEntity, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddService{DeviceID: device.ID, Name: "dns", Port: 70000})
+	require.Equal(http.StatusUnprocessableEntity, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddService{DeviceID: device.ID, Name: "dns", Port: 53, Protocol: "sctp"})
+	require.Equal(http.StatusUnprocessableEntity, code, "HTTP error: %s", string(body))
+	code, body = create(models.AddService{DeviceID: uuid.New(), Name: "dns", Port: 53})
+	require.Equal(http.StatusNotFound, code, "HTTP error: %s", string(body))
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/:id/services", fmt.Sprintf("/%s/services", device.VpcID),
+		suite.api.ListServicesInVPC, nil,
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+	var services []models.Service
+	require.NoError(json.Unmarshal(body, &services))
+	require.Len(services, 1)
+	require.Equal(service.ID, services[0].ID)
+
+	port := 6432
+	reqBody, err = json.Marshal(models.UpdateService{Port: &port})
+	require.NoError(err)
+	_, res, err = suite.ServeRequest(
+		http.MethodPatch,
+		"/:id", fmt.Sprintf("/%s", service.ID),
+		suite.api.UpdateService, bytes.NewBuffer(reqBody),
+	)
+	require.NoError(err)
+	body, err = io.ReadAll(res.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code, "HTTP error: %s", string(body))
+	require.NoError(json.Unmarshal(body, &service))
+	require.Equal(6432, service.Port)
+
+	// the services of a device are deleted with it
+	_, res, err = suite.ServeRequest(
+		http.MethodDelete,
+		"/:id", fmt.Sprintf("/%s", device.ID),
+		suite.api.DeleteDevice, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusOK, res.Code)
+
+	_, res, err = suite.ServeRequest(
+		http.MethodGet,
+		"/:id", fmt.Sprintf("/%s", service.ID),
+		suite.api.GetService, nil,
+	)
+	require.NoError(err)
+	require.Equal(http.StatusNotFound, res.Code)
+}
@@ -40,7 +40,7 @@ func (d siteList) Len() int {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/sites [get]
+// @Router       /api/v1/sites [get]
 func (api *API) ListSites(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListSites")
 	defer span.End()
@@ -129,7 +129,7 @@ func (api *API) SiteIsOwnedByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/sites/{id} [get]
+// @Router       /api/v1/sites/{id} [get]
 func (api *API) GetSite(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetSite", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -183,7 +183,7 @@ func (api *API) GetSite(c *gin.Context) {
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/sites/{id} [patch]
+// @Router       /api/v1/sites/{id} [patch]
 func (api *API) UpdateSite(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "UpdateSite", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
@@ -291,7 +291,7 @@ func (api *API) UpdateSite(c *gin.Context) {
 // @Failure      409  {object}  models.ConflictsError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/sites [post]
+// @Router       /api/v1/sites [post]
 func (api *API) CreateSite(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "AddSite")
 	defer span.End()
@@ -326,6 +326,9 @@ func (api *API) CreateSite(c *gin.Context) {
 			First(&vpc, "id = ?", request.VpcID); result.Error != nil {
 			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("vpc"))
 		}
+		if err := api.checkFeatureFlag(c, "sites", vpc.OrganizationID); err != nil {
+			return err
+		}
 
 		res := tx.Where("public_key = ?", request.PublicKey).First(&site)
 		if res.Error == nil {
@@ -432,7 +435,7 @@ func (api *API) CreateSite(c *gin.Context) {
 // @Failure      400  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/sites/{id} [delete]
+// @Router       /api/v1/sites/{id} [delete]
 func (api *API) DeleteSite(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteSite")
 	defer span.End()
@@ -500,7 +503,7 @@ func (api *API) DeleteSite(c *gin.Context) {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id}/sites [get]
+// @Router       /api/v1/vpcs/{id}/sites [get]
 func (api *API) ListSitesInVPC(c *gin.Context) {
 
 	ctx, span := tracer.Start(c.Request.Context(), "ListSitesInVPC",
@@ -25,6 +25,17 @@ const AuthUserName string = "_nexodus.UserName"
 const CacheExp time.Duration = 0
 const CachePrefix = "user:"
 
+// deleteCachedUser removes the user id cached for the identity provider user, the users are not cached without redis
+func (api *API) deleteCachedUser(ctx context.Context, idpID string) {
+	if api.Redis == nil {
+		return
+	}
+	prefixId := fmt.Sprintf("%s:%s", CachePrefix, idpID)
+	if _, err := api.Redis.Del(ctx, prefixId).Result(); err != nil {
+		api.logger.Warnf("failed to delete the cache user:%s", err)
+	}
+}
+
 func (api *API) UserIsCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
 	userId := api.GetCurrentUserID(c)
 
@@ -242,7 +253,7 @@ func (api *API) CreateUserIfNotExists(ctx context.Context, idpId string, userNam
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/users/{id} [get]
+// @Router       /api/v1/users/{id} [get]
 func (api *API) GetUser(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetUser",
 		trace.WithAttributes(
@@ -279,22 +290,35 @@ func (api *API) GetUser(c *gin.Context) {
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Param		 sort    query  string false "comma separated fields to sort by, prefix a field with - to sort in descending order"
+// @Param		 limit   query  int    false "the number of items in a page, enables cursor pagination"
+// @Param		 cursor  query  string false "the cursor of the page to list, as returned in the X-Next-Cursor header of the previous page"
 // @Success      200  {object}  []models.User
+// @Header      200  {string}  X-Next-Cursor "the cursor of the next page when using cursor pagination"
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/users [get]
+// @Router       /api/v1/users [get]
 func (api *API) ListUsers(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListUsers")
 	defer span.End()
 	users := make([]*models.User, 0)
-	db := api.db.WithContext(ctx)
+	db := api.readReplicaDB(ctx)
 	db = api.UserIsCurrentUser(c, db)
 	db = FilterAndPaginate(db, &models.User{}, c, "user_name")
 	result := db.Find(&users)
 
 	if result.Error != nil {
-		api.SendInternalServerError(c, errors.New("error fetching keys from db"))
+		var apiResponseError *ApiResponseError
+		if errors.As(result.Error, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, errors.New("error fetching keys from db"))
+		}
+		return
+	}
+	if err := SetNextCursor(c, users); err != nil {
+		api.SendInternalServerError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, users)
@@ -313,7 +337,7 @@ func (api *API) ListUsers(c *gin.Context) {
 // @Failure      400  {object}  models.NotAllowedError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/users/{id} [delete]
+// @Router       /api/v1/users/{id} [delete]
 func (api *API) DeleteUser(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteUser")
 	defer span.End()
@@ -343,96 +367,105 @@ func (api *API) DeleteUser(c *gin.Context) {
 			return NewApiResponseError(http.StatusBadRequest, models.NewNotAllowedError("user cannot be deleted while devices owned by the user are still attached"))
 		}
 
-		// Cascade delete related records
-		if res := tx.Where("owner_id = ?", userId).Delete(&models.RegKey{}); res.Error != nil {
-			return result.Error
-		}
-		if res := tx.Where("user_id = ?", userId).Delete(&models.Invitation{}); res.Error != nil {
-			return result.Error
+		if err := api.deleteUser(ctx, tx, &user); err != nil {
+			return err
 		}
 
-		// find the organizations the user is an owner of
-		ownerRole := []string{"owner"}
-		res := tx
-		if api.dialect == database.DialectSqlLite {
-			res = tx.Where("user_id = ? AND EXISTS (SELECT * FROM json_each(roles) AS role WHERE role.value IN ?)", userId, ownerRole)
+		// users are recorded in the audit log of their default organization
+		return api.recordAuditEvent(c, tx, user.ID, models.AuditActionDelete, "user", user.ID, auditState(user), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
 		} else {
-			res = tx.Where("user_id = ? AND (roles && ?)", userId, models.StringArray(ownerRole))
+			api.SendInternalServerError(c, err)
 		}
+		return
+	}
 
-		userOrgs := []models.UserOrganization{}
-		if res = res.Find(&userOrgs); res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return result.Error
-		}
+	c.JSON(http.StatusOK, user)
+}
 
-		for _, org := range userOrgs {
+// deleteUser soft deletes a user and its records, the organizations the user is the only owner of are deleted. The
+// devices of the user must have been deleted before.
+func (api *API) deleteUser(ctx context.Context, tx *gorm.DB, user *models.User) error {
+	// Cascade delete related records
+	if res := tx.Where("owner_id = ?", user.ID).Delete(&models.RegKey{}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Where("user_id = ?", user.ID).Delete(&models.Invitation{}); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Where("user_id = ?", user.ID).Delete(&models.APIKey{}); res.Error != nil {
+		return res.Error
+	}
 
-			// check if the user is the only owner of the organization
-			res := tx.Model(&models.UserOrganization{})
-			if api.dialect == database.DialectSqlLite {
-				where := "organization_id = ? AND EXISTS (SELECT * FROM json_each(roles) AS role WHERE role.value IN ?)"
-				res = res.Where(where, org.OrganizationID, ownerRole)
-			} else {
-				where := "organization_id = ? AND (roles && ?)"
-				res = res.Where(where, org.OrganizationID, models.StringArray(ownerRole))
-			}
-			var count int64
-			if res = res.Count(&count); res.Error != nil {
-				return result.Error
-			}
+	// find the organizations the user is an owner of
+	ownerRole := []string{"owner"}
+	res := tx
+	if api.dialect == database.DialectSqlLite {
+		res = tx.Where("user_id = ? AND EXISTS (SELECT * FROM json_each(roles) AS role WHERE role.value IN ?)", user.ID, ownerRole)
+	} else {
+		res = tx.Where("user_id = ? AND (roles && ?)", user.ID, models.StringArray(ownerRole))
+	}
 
-			// if the user is the only owner, delete the organization
-			if count <= 1 {
-				err := deleteOrganization(tx, org.OrganizationID)
-				if err != nil {
-					return err
-				}
-			} else {
-				// remove the user from the organization
-				if res := tx.Where("user_id = ?", userId).
-					Delete(&models.UserOrganization{}); res.Error != nil {
-					return result.Error
-				}
-			}
-		}
+	userOrgs := []models.UserOrganization{}
+	if res = res.Find(&userOrgs); res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return res.Error
+	}
 
-		// delete the related identities
-		if res := tx.Where("user_id = ?", userId).
-			Delete(&models.UserIdentity{}); res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			return result.Error
-		}
+	for _, org := range userOrgs {
 
-		// delete the cached user
-		prefixId := fmt.Sprintf("%s:%s", CachePrefix, user.IdpID)
-		_, err := api.Redis.Del(c.Request.Context(), prefixId).Result()
-		if err != nil {
-			api.logger.Warnf("failed to delete the cache user:%s", err)
+		// check if the user is the only owner of the organization
+		res := tx.Model(&models.UserOrganization{})
+		if api.dialect == database.DialectSqlLite {
+			where := "organization_id = ? AND EXISTS (SELECT * FROM json_each(roles) AS role WHERE role.value IN ?)"
+			res = res.Where(where, org.OrganizationID, ownerRole)
+		} else {
+			where := "organization_id = ? AND (roles && ?)"
+			res = res.Where(where, org.OrganizationID, models.StringArray(ownerRole))
 		}
-
-		// Null out unique fields so that the user can be created later with the same values
-		if res := tx.Model(&user).
-			Where("id = ?", userId).
-			Updates(map[string]interface{}{
-				"idp_id":     nil,
-				"deleted_at": gorm.DeletedAt{Time: time.Now(), Valid: true},
-			}); res.Error != nil {
+		var count int64
+		if res = res.Count(&count); res.Error != nil {
 			return res.Error
 		}
 
-		return nil
-	})
-
-	if err != nil {
-		var apiResponseError *ApiResponseError
-		if errors.As(err, &apiResponseError) {
-			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		// if the user is the only owner, delete the organization
+		if count <= 1 {
+			err := deleteOrganization(tx, org.OrganizationID)
+			if err != nil {
+				return err
+			}
 		} else {
-			api.SendInternalServerError(c, err)
+			// remove the user from the organization
+			if res := tx.Where("user_id = ?", user.ID).
+				Delete(&models.UserOrganization{}); res.Error != nil {
+				return res.Error
+			}
 		}
-		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	// delete the related identities
+	if res := tx.Where("user_id = ?", user.ID).
+		Delete(&models.UserIdentity{}); res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return res.Error
+	}
+
+	api.deleteCachedUser(ctx, user.IdpID)
+
+	// Null out unique fields so that the user can be created later with the same values
+	if res := tx.Model(user).
+		Where("id = ?", user.ID).
+		Updates(map[string]interface{}{
+			"idp_id":     nil,
+			"deleted_at": gorm.DeletedAt{Time: time.Now(), Valid: true},
+		}); res.Error != nil {
+		return res.Error
+	}
+
+	return nil
 }
 
 // DeleteUserFromOrganization removes a user from an organization
@@ -448,7 +481,7 @@ func (api *API) DeleteUser(c *gin.Context) {
 // @Failure      400  {object}  models.BaseError
 // @Failure      400  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/users/{id}/organizations/{organization} [delete]
+// @Router       /api/v1/users/{id}/organizations/{organization} [delete]
 func (api *API) DeleteUserFromOrganization(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteUser")
 	defer span.End()
@@ -480,7 +513,7 @@ func (api *API) DeleteUserFromOrganization(c *gin.Context) {
 			Delete(&models.UserOrganization{}); res.Error != nil {
 			api.SendInternalServerError(c, fmt.Errorf("failed to remove the association from the user_organizations table: %w", res.Error))
 		}
-		return nil
+		return api.recordAuditEvent(c, tx, organization.ID, models.AuditActionDelete, "organization_user", user.ID, auditState(user), nil)
 	})
 
 	if err != nil {
@@ -494,11 +527,6 @@ func (api *API) DeleteUserFromOrganization(c *gin.Context) {
 		}
 		return
 	}
-	// delete the cached user
-	prefixId := fmt.Sprintf("%s:%s", CachePrefix, user.IdpID)
-	_, err = api.Redis.Del(c.Request.Context(), prefixId).Result()
-	if err != nil {
-		api.logger.Warnf("failed to delete the cache user:%s", err)
-	}
+	api.deleteCachedUser(c.Request.Context(), user.IdpID)
 	c.JSON(http.StatusOK, user)
 }
@@ -55,7 +55,7 @@ func (api *API) IsOwnerOfOrg(c *gin.Context, orgId uuid.UUID) (bool, error) {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations/{id}/users [get]
+// @Router       /api/v1/organizations/{id}/users [get]
 func (api *API) ListOrganizationUsers(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListOrganizationUsers",
 		trace.WithAttributes(
@@ -111,7 +111,7 @@ func (api *API) ListOrganizationUsers(c *gin.Context) {
 // @Failure		 429  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations/{id}/users/{uid} [get]
+// @Router       /api/v1/organizations/{id}/users/{uid} [get]
 func (api *API) GetOrganizationUser(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetOrganizationUser",
 		trace.WithAttributes(
@@ -172,7 +172,7 @@ func (api *API) GetOrganizationUser(c *gin.Context) {
 // @Failure      400  {object}  models.ValidationError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/organizations/{id}/users/{uid} [delete]
+// @Router       /api/v1/organizations/{id}/users/{uid} [delete]
 func (api *API) DeleteOrganizationUser(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteOrganization",
 		trace.WithAttributes(
@@ -243,7 +243,7 @@ func (api *API) DeleteOrganizationUser(c *gin.Context) {
 			return result.Error
 		}
 
-		return nil
+		return api.recordAuditEvent(c, tx, id, models.AuditActionDelete, "organization_user", uid, auditState(model), nil)
 	})
 	if err != nil {
 		var apiResponseError *ApiResponseError
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/nexodus-io/nexodus/internal/util"
@@ -38,7 +39,7 @@ const (
 // @Failure      409  {object}  models.ConflictsError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs [post]
+// @Router       /api/v1/vpcs [post]
 func (api *API) CreateVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "CreateVPC")
 	defer span.End()
@@ -114,26 +115,8 @@ func (api *API) CreateVPC(c *gin.Context) {
 			return fmt.Errorf("failed to create vpc: %w", res.Error)
 		}
 
-		ipamNamespace := defaultIPAMNamespace
-		if vpc.PrivateCidr {
-			ipamNamespace = vpc.ID
-		}
-		if err := api.ipam.CreateNamespace(ctx, ipamNamespace); err != nil {
-			return fmt.Errorf("failed to create namespace: %w", err)
-		}
-
-		if err := api.ipam.AssignCIDR(ctx, ipamNamespace, request.Ipv4Cidr); err != nil {
-			return fmt.Errorf("failed to assign IPv4 prefix: %w", err)
-		}
-
-		if err := api.ipam.AssignCIDR(ctx, ipamNamespace, request.Ipv6Cidr); err != nil {
-			return fmt.Errorf("failed to assign IPv6 prefix: %w", err)
-		}
-
-		// Create a default security group for the organization
-		err := api.createDefaultSecurityGroup(ctx, tx, vpc.ID, org.ID)
-		if err != nil {
-			return fmt.Errorf("failed to create default security group for VPC: %w", err)
+		if err := api.setupVPC(ctx, tx, vpc); err != nil {
+			return err
 		}
 
 		span.SetAttributes(attribute.String("id", vpc.ID.String()))
@@ -154,6 +137,44 @@ func (api *API) CreateVPC(c *gin.Context) {
 	c.JSON(http.StatusCreated, vpc)
 }
 
+// setupVPC assigns the prefixes of a new vpc in IPAM and creates its default security group. The prefixes of a vpc
+// with private cidrs must not overlap the prefixes already allocated in its IPAM namespace.
+func (api *API) setupVPC(ctx context.Context, tx *gorm.DB, vpc models.VPC) error {
+	ipamNamespace := defaultIPAMNamespace
+	if vpc.PrivateCidr {
+		ipamNamespace = vpc.ID
+	}
+	if err := api.ipam.CreateNamespace(ctx, ipamNamespace); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	if vpc.PrivateCidr {
+		for _, prefix := range []struct{ field, cidr string }{{"ipv4_cidr", vpc.Ipv4Cidr}, {"ipv6_cidr", vpc.Ipv6Cidr}} {
+			overlapping, err := api.ipam.OverlappingPrefix(ctx, ipamNamespace, prefix.cidr)
+			if err != nil {
+				return fmt.Errorf("failed to check the allocated prefixes: %w", err)
+			}
+			if overlapping != "" {
+				return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError(prefix.field, fmt.Sprintf("overlaps the allocated prefix %s", overlapping)))
+			}
+		}
+	}
+
+	if err := api.ipam.AssignCIDR(ctx, ipamNamespace, vpc.Ipv4Cidr); err != nil {
+		return fmt.Errorf("failed to assign IPv4 prefix: %w", err)
+	}
+
+	if err := api.ipam.AssignCIDR(ctx, ipamNamespace, vpc.Ipv6Cidr); err != nil {
+		return fmt.Errorf("failed to assign IPv6 prefix: %w", err)
+	}
+
+	// Create a default security group for the organization
+	if err := api.createDefaultSecurityGroup(ctx, tx, vpc.ID, vpc.OrganizationID); err != nil {
+		return fmt.Errorf("failed to create default security group for VPC: %w", err)
+	}
+	return nil
+}
+
 func (api *API) VPCIsReadableByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
 	return api.CurrentUserHasRole(c, db, "organization_id", MemberRoles)
 }
@@ -173,7 +194,7 @@ func (api *API) VPCIsOwnedByCurrentUser(c *gin.Context, db *gorm.DB) *gorm.DB {
 // @Failure		 401  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs [get]
+// @Router       /api/v1/vpcs [get]
 func (api *API) ListVPCs(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "ListVPCs")
 	defer span.End()
@@ -210,7 +231,7 @@ func (api *API) ListVPCs(c *gin.Context) {
 // @Failure		 429  {object}  models.BaseError
 // @Failure      404  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id} [get]
+// @Router       /api/v1/vpcs/{id} [get]
 func (api *API) GetVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "GetVPCs",
 		trace.WithAttributes(
@@ -252,7 +273,7 @@ func (api *API) GetVPC(c *gin.Context) {
 // @Failure      405  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id} [delete]
+// @Router       /api/v1/vpcs/{id} [delete]
 func (api *API) DeleteVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "DeleteVPC",
 		trace.WithAttributes(
@@ -305,6 +326,15 @@ func (api *API) DeleteVPC(c *gin.Context) {
 		api.SendInternalServerError(c, res.Error)
 		return
 	}
+	var reservations []models.IPReservation
+	if res := db.Where("vpc_id = ?", id).Find(&reservations); res.Error != nil {
+		api.SendInternalServerError(c, res.Error)
+		return
+	}
+	if res := db.Where("vpc_id = ?", id).Delete(&models.IPReservation{}); res.Error != nil {
+		api.SendInternalServerError(c, res.Error)
+		return
+	}
 
 	result = db.Delete(&vpc)
 	if result.Error != nil {
@@ -318,6 +348,12 @@ func (api *API) DeleteVPC(c *gin.Context) {
 	}
 	vpcCIDR := vpc.Ipv4Cidr
 	vpcCIDRV6 := vpc.Ipv6Cidr
+	for _, reservation := range reservations {
+		if err := api.ipam.ReleaseToPool(c.Request.Context(), ipamNamespace, reservation.Address, vpcCIDR); err != nil {
+			api.SendInternalServerError(c, fmt.Errorf("failed to release the reserved address to pool: %w", err))
+			return
+		}
+	}
 	if vpc.PrivateCidr {
 		if err := api.ipam.ReleaseCIDR(c.Request.Context(), ipamNamespace, vpcCIDR); err != nil {
 			api.SendInternalServerError(c, fmt.Errorf("failed to release ipam vpc prefix: %w", err))
@@ -348,7 +384,7 @@ func (api *API) DeleteVPC(c *gin.Context) {
 // @Failure      404  {object}  models.BaseError
 // @Failure		 429  {object}  models.BaseError
 // @Failure      500  {object}  models.InternalServerError "Internal Server Error"
-// @Router       /api/vpcs/{id} [patch]
+// @Router       /api/v1/vpcs/{id} [patch]
 func (api *API) UpdateVPC(c *gin.Context) {
 	ctx, span := tracer.Start(c.Request.Context(), "UpdateVPC", trace.WithAttributes(
 		attribute.String("id", c.Param("id")),
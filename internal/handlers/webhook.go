@@ -0,0 +1,491 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// ListWebhooks lists the webhooks of an organization
+// @Summary      List Webhooks
+// @Description  Lists the webhooks the events of the organization are posted to
+// @Id           ListWebhooks
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id   path      string true "Organization ID"
+// @Success      200  {object}  []models.Webhook
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks [get]
+func (api *API) ListWebhooks(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListWebhooks",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	result := api.OrganizationIsOwnedByCurrentUser(c, db).
+		First(&org, "id = ?", orgId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		} else {
+			api.SendInternalServerError(c, result.Error)
+		}
+		return
+	}
+
+	webhooks := []models.Webhook{}
+	db = FilterAndPaginate(db.Where("organization_id = ?", org.ID), &models.Webhook{}, c, "url")
+	if result := db.Find(&webhooks); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching webhooks from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook gets a webhook of an organization
+// @Summary      Get Webhook
+// @Description  Gets a webhook of the organization by ID
+// @Id           GetWebhook
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 webhook_id  path      string true "Webhook ID"
+// @Success      200  {object}  models.Webhook
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks/{webhook_id} [get]
+func (api *API) GetWebhook(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "GetWebhook",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("webhook_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	webhookId, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("webhook_id"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	if res := api.OrganizationIsOwnedByCurrentUser(c, db).
+		First(&org, "id = ?", orgId); res.Error != nil {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+	var webhook models.Webhook
+	if res := db.First(&webhook, "id = ? AND organization_id = ?", webhookId, org.ID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("webhook"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateWebhook adds a webhook to an organization
+// @Summary      Create a Webhook
+// @Description  Adds a webhook the events of the organization of the given types are posted to, signed with the secret
+// @Id           CreateWebhook
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id       path   string true "Organization ID"
+// @Param        Webhook  body   models.AddWebhook  true  "Add Webhook"
+// @Success      201  {object}  models.Webhook
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks [post]
+func (api *API) CreateWebhook(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "CreateWebhook",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+
+	var request models.AddWebhook
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.URL == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("url"))
+		return
+	}
+	if request.Secret == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("secret"))
+		return
+	}
+	if len(request.EventTypes) == 0 {
+		c.JSON(http.StatusBadRequest, models.NewFieldNotPresentError("event_types"))
+		return
+	}
+	if field, reason := validateWebhook(request.URL, request.EventTypes); reason != "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError(field, reason))
+		return
+	}
+
+	var webhook models.Webhook
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+
+		webhook = models.Webhook{
+			OrganizationID: org.ID,
+			URL:            request.URL,
+			Secret:         request.Secret,
+			EventTypes:     append(models.StringArray{}, request.EventTypes...),
+			Description:    request.Description,
+		}
+		if res := tx.Create(&webhook); res.Error != nil {
+			return res.Error
+		}
+
+		span.SetAttributes(attribute.String("id", webhook.ID.String()))
+		api.logger.Infof("New webhook [ %s ] to [ %s ] in organization [ %s ]", webhook.ID, webhook.URL, org.ID)
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionCreate, "webhook", webhook.ID, nil, auditState(webhook))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// UpdateWebhook updates a webhook of an organization
+// @Summary      Update Webhook
+// @Description  Updates the url, secret, event types or description of a webhook
+// @Id           UpdateWebhook
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 webhook_id  path      string true "Webhook ID"
+// @Param		 update body models.UpdateWebhook true "Webhook Update"
+// @Success      200  {object}  models.Webhook
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks/{webhook_id} [patch]
+func (api *API) UpdateWebhook(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateWebhook",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("webhook_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	webhookId, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("webhook_id"))
+		return
+	}
+
+	var request models.UpdateWebhook
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPayloadError(err))
+		return
+	}
+	if request.Secret != nil && *request.Secret == "" {
+		c.JSON(http.StatusBadRequest, models.NewFieldValidationError("secret", "can not be empty"))
+		return
+	}
+
+	var webhook models.Webhook
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&webhook, "id = ? AND organization_id = ?", webhookId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("webhook"))
+		}
+
+		before := auditState(webhook)
+		if request.URL != nil {
+			webhook.URL = *request.URL
+		}
+		if request.Secret != nil {
+			webhook.Secret = *request.Secret
+		}
+		if request.EventTypes != nil {
+			webhook.EventTypes = append(models.StringArray{}, request.EventTypes...)
+		}
+		if request.Description != nil {
+			webhook.Description = *request.Description
+		}
+		if field, reason := validateWebhook(webhook.URL, webhook.EventTypes); reason != "" {
+			return NewApiResponseError(http.StatusBadRequest, models.NewFieldValidationError(field, reason))
+		}
+		if res := tx.Save(&webhook); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionUpdate, "webhook", webhook.ID, before, auditState(webhook))
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes a webhook of an organization
+// @Summary      Delete Webhook
+// @Description  Deletes a webhook, its pending deliveries are dropped
+// @Id           DeleteWebhook
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 webhook_id  path      string true "Webhook ID"
+// @Success      204  {object}  models.Webhook
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks/{webhook_id} [delete]
+func (api *API) DeleteWebhook(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteWebhook",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("webhook_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	webhookId, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("webhook_id"))
+		return
+	}
+
+	var webhook models.Webhook
+	err = api.transaction(ctx, func(tx *gorm.DB) error {
+		var org models.Organization
+		if res := api.OrganizationIsOwnedByCurrentUser(c, tx).
+			First(&org, "id = ?", orgId); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("organization"))
+		}
+		if res := tx.First(&webhook, "id = ? AND organization_id = ?", webhookId, org.ID); res.Error != nil {
+			return NewApiResponseError(http.StatusNotFound, models.NewNotFoundError("webhook"))
+		}
+		if res := tx.Delete(&webhook); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}); res.Error != nil {
+			return res.Error
+		}
+		return api.recordAuditEvent(c, tx, org.ID, models.AuditActionDelete, "webhook", webhook.ID, auditState(webhook), nil)
+	})
+
+	if err != nil {
+		var apiResponseError *ApiResponseError
+		if errors.As(err, &apiResponseError) {
+			c.JSON(apiResponseError.Status, apiResponseError.Body)
+		} else {
+			api.SendInternalServerError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// ListWebhookDeliveries lists the deliveries of a webhook
+// @Summary      List Webhook Deliveries
+// @Description  Lists the pending, delivered and failed posts of the events to a webhook, newest first
+// @Id           ListWebhookDeliveries
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param		 id          path      string true "Organization ID"
+// @Param		 webhook_id  path      string true "Webhook ID"
+// @Success      200  {object}  []models.WebhookDelivery
+// @Failure      400  {object}  models.BaseError
+// @Failure		 401  {object}  models.BaseError
+// @Failure      404  {object}  models.BaseError
+// @Failure		 429  {object}  models.BaseError
+// @Failure      500  {object}  models.InternalServerError "Internal Server Error"
+// @Router       /api/v1/organizations/{id}/webhooks/{webhook_id}/deliveries [get]
+func (api *API) ListWebhookDeliveries(c *gin.Context) {
+	ctx, span := tracer.Start(c.Request.Context(), "ListWebhookDeliveries",
+		trace.WithAttributes(
+			attribute.String("organization", c.Param("id")),
+			attribute.String("id", c.Param("webhook_id")),
+		))
+	defer span.End()
+
+	orgId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("id"))
+		return
+	}
+	webhookId, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewBadPathParameterError("webhook_id"))
+		return
+	}
+
+	var org models.Organization
+	db := api.db.WithContext(ctx)
+	if res := api.OrganizationIsOwnedByCurrentUser(c, db).
+		First(&org, "id = ?", orgId); res.Error != nil {
+		c.JSON(http.StatusNotFound, models.NewNotFoundError("organization"))
+		return
+	}
+	var webhook models.Webhook
+	if res := db.First(&webhook, "id = ? AND organization_id = ?", webhookId, org.ID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.NewNotFoundError("webhook"))
+		} else {
+			api.SendInternalServerError(c, res.Error)
+		}
+		return
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	db = FilterAndPaginate(db.Where("webhook_id = ?", webhook.ID), &models.WebhookDelivery{}, c, "created_at DESC")
+	if result := db.Find(&deliveries); result.Error != nil {
+		api.SendInternalServerError(c, fmt.Errorf("error fetching webhook deliveries from db: %w", result.Error))
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// validateWebhook returns the field and the reason the url or the event types of a webhook are invalid
+func validateWebhook(webhookURL string, eventTypes []string) (string, string) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "url", "must be an http or https url"
+	}
+	if len(eventTypes) == 0 {
+		return "event_types", "can not be empty"
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return "event_types", fmt.Sprintf("unknown event type %s", eventType)
+		}
+	}
+	return "", ""
+}
+
+// queueWebhookDeliveries queues the posts of an audit event to the webhooks of its organization subscribed to its
+// type. The deliveries are created in the transaction of the event, so only the committed changes are posted.
+func queueWebhookDeliveries(tx *gorm.DB, event models.AuditEvent) error {
+	eventType := event.ResourceType + "." + event.Action
+	if event.OrganizationID == uuid.Nil || !slices.Contains(models.WebhookEventTypes, eventType) {
+		return nil
+	}
+	var webhooks []models.Webhook
+	if res := tx.Where("organization_id = ?", event.OrganizationID).Find(&webhooks); res.Error != nil {
+		return fmt.Errorf("failed to find the webhooks of the event: %w", res.Error)
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.EventTypes, eventType) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(models.WebhookEvent{
+				ID:             event.ID,
+				Type:           eventType,
+				CreatedAt:      event.CreatedAt,
+				OrganizationID: event.OrganizationID,
+				ActorID:        event.ActorID,
+				ResourceID:     event.ResourceID,
+				Changes:        event.Changes,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			NextAttemptAt: event.CreatedAt,
+		}
+		if res := tx.Create(&delivery); res.Error != nil {
+			return fmt.Errorf("failed to queue the webhook delivery: %w", res.Error)
+		}
+	}
+	return nil
+}
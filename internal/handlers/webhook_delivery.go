@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	// WebhookSignatureHeader holds the hex HMAC-SHA256 of the body of a webhook post keyed by the webhook secret
+	WebhookSignatureHeader = "X-Nexodus-Signature"
+	// WebhookEventHeader holds the type of the event of a webhook post
+	WebhookEventHeader = "X-Nexodus-Event"
+	// WebhookDeliveryHeader holds the id of the delivery of a webhook post, it is the same on the retries
+	WebhookDeliveryHeader = "X-Nexodus-Delivery"
+
+	// a delivery is given up after this many failed posts, retried after 1, 2, 4... minutes
+	webhookMaxAttempts = 8
+	webhookTimeout     = 10 * time.Second
+	// the deliveries posted per run of the dispatcher
+	webhookBatchSize = 100
+	// the delivered and given up deliveries are kept this long for ListWebhookDeliveries
+	webhookDeliveryRetention = 7 * 24 * time.Hour
+)
+
+// StartWebhookDispatcher posts the queued webhook deliveries every interval, 0 disables the dispatcher
+func (api *API) StartWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		return
+	}
+	go util.RunPeriodically(ctx, interval, func() {
+		api.dispatchWebhooks(ctx)
+	})
+}
+
+// dispatchWebhooks posts the deliveries that are due, oldest first, and drops the deliveries past the retention.
+// A delivery is claimed by counting its attempt before it is posted, so the replicas do not post it twice.
+func (api *API) dispatchWebhooks(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "dispatchWebhooks")
+	defer span.End()
+
+	db := api.db.WithContext(ctx)
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	if res := db.Where("delivered_at IS NULL AND attempts < ? AND next_attempt_at <= ?", webhookMaxAttempts, now).
+		Order("next_attempt_at").Limit(webhookBatchSize).Find(&deliveries); res.Error != nil {
+		api.logger.Warnf("failed to find the webhook deliveries: %v", res.Error)
+		return
+	}
+
+	client := api.webhookClient()
+	for _, delivery := range deliveries {
+		res := db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND attempts = ? AND delivered_at IS NULL", delivery.ID, delivery.Attempts).
+			Updates(map[string]interface{}{
+				"attempts":        delivery.Attempts + 1,
+				"next_attempt_at": now.Add(webhookTimeout + webhookRetryDelay(delivery.Attempts+1)),
+			})
+		if res.Error != nil {
+			api.logger.Warnf("failed to claim the webhook delivery %s: %v", delivery.ID, res.Error)
+			continue
+		}
+		if res.RowsAffected == 0 {
+			// another replica is posting it
+			continue
+		}
+		delivery.Attempts++
+		api.deliverWebhook(ctx, client, delivery)
+	}
+
+	if res := db.Unscoped().Where("created_at < ? AND (delivered_at IS NOT NULL OR attempts >= ?)", now.Add(-webhookDeliveryRetention), webhookMaxAttempts).
+		Delete(&models.WebhookDelivery{}); res.Error != nil {
+		api.logger.Warnf("failed to delete the old webhook deliveries: %v", res.Error)
+	}
+}
+
+// deliverWebhook posts a delivery to its webhook and records the outcome, a failed post is retried after a delay
+// that doubles on each attempt.
+func (api *API) deliverWebhook(ctx context.Context, client *http.Client, delivery models.WebhookDelivery) {
+	db := api.db.WithContext(ctx)
+	var webhook models.Webhook
+	if res := db.First(&webhook, "id = ?", delivery.WebhookID); res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			db.Delete(&delivery)
+		} else {
+			api.logger.Warnf("failed to find the webhook of the delivery %s: %v", delivery.ID, res.Error)
+		}
+		return
+	}
+
+	err := postWebhook(ctx, client, webhook, delivery)
+	updates := map[string]interface{}{"last_error": ""}
+	if err != nil {
+		api.logger.Debugf("failed to post the webhook delivery %s to %s: %v", delivery.ID, webhook.URL, err)
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = time.Now().Add(webhookRetryDelay(delivery.Attempts))
+	} else {
+		updates["delivered_at"] = time.Now()
+	}
+	if res := db.Model(&delivery).Updates(updates); res.Error != nil {
+		api.logger.Warnf("failed to update the webhook delivery %s: %v", delivery.ID, res.Error)
+	}
+}
+
+func postWebhook(ctx context.Context, client *http.Client, webhook models.Webhook, delivery models.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nexodus-webhook")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, "sha256="+WebhookSignature(webhook.Secret, []byte(delivery.Payload)))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// WebhookSignature returns the signature of the body of a webhook post, the receivers compare it to the
+// WebhookSignatureHeader to verify the post was made by the apiserver.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookRetryDelay(attempts int) time.Duration {
+	return time.Minute << (attempts - 1)
+}
+
+// webhookClient returns the client of the webhook posts, it does not connect to the loopback, private and link local
+// addresses unless WebhookAllowPrivate is set, so the webhooks can not reach the services next to the apiserver.
+func (api *API) webhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !api.WebhookAllowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+		// a redirect could lead the post to another address than the one of the webhook url
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+func (suite *HandlerTestSuite) TestWebhooks() {
+	require := suite.Require()
+	suite.api.db.Exec("DELETE FROM webhooks")
+	suite.api.db.Exec("DELETE FROM webhook_deliveries")
+	defer func() {
+		suite.api.WebhookAllowPrivate = false
+	}()
+
+	type post struct {
+		header http.Header
+		body   []byte
+	}
+	posts := make(chan post, 10)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posts <- post{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	org := "/" + suite.testUserID.String()
+	deliveries := func(webhook models.Webhook) []models.WebhookDelivery {
+		code, body := suite.serve(http.MethodGet, "/:id/webhooks/:webhook_id/deliveries", org+"/webhooks/"+webhook.ID.String()+"/deliveries", suite.api.ListWebhookDeliveries, nil)
+		require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+		var result []models.WebhookDelivery
+		require.NoError(json.Unmarshal(body, &result))
+		return result
+	}
+
+	code, _ := suite.serve(http.MethodPost, "/:id/webhooks", org+"/webhooks", suite.api.CreateWebhook, models.AddWebhook{
+		URL: "ftp://hooks.example.com", Secret: "secret", EventTypes: []string{"device.create"},
+	})
+	require.Equal(http.StatusBadRequest, code)
+	code, _ = suite.serve(http.MethodPost, "/:id/webhooks", org+"/webhooks", suite.api.CreateWebhook, models.AddWebhook{
+		URL: server.URL, Secret: "secret", EventTypes: []string{"device.rename"},
+	})
+	require.Equal(http.StatusBadRequest, code)
+	code, _ = suite.serve(http.MethodPost, "/:id/webhooks", "/"+suite.testUser2ID.String()+"/webhooks", suite.api.CreateWebhook, models.AddWebhook{
+		URL: server.URL, Secret: "secret", EventTypes: []string{"device.create"},
+	})
+	require.Equal(http.StatusNotFound, code)
+
+	code, body := suite.serve(http.MethodPost, "/:id/webhooks", org+"/webhooks", suite.api.CreateWebhook, models.AddWebhook{
+		URL: server.URL, Secret: "secret", EventTypes: []string{"device.create", "device.delete"},
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var webhook models.Webhook
+	require.NoError(json.Unmarshal(body, &webhook))
+	require.NotContains(string(body), "secret")
+
+	code, body = suite.serve(http.MethodPost, "/", "/", suite.api.CreateDevice, models.AddDevice{
+		VpcID:     suite.testUserID,
+		PublicKey: "webhookpubkey",
+	})
+	require.Equal(http.StatusCreated, code, "HTTP error: %s", string(body))
+	var device models.Device
+	require.NoError(json.Unmarshal(body, &device))
+
+	// the webhook is not posted to the loopback address unless allowed
+	suite.api.dispatchWebhooks(context.Background())
+	pending := deliveries(webhook)
+	require.Len(pending, 1)
+	require.Equal(1, pending[0].Attempts)
+	require.Contains(pending[0].LastError, "not allowed")
+	require.Nil(pending[0].DeliveredAt)
+	require.NoError(suite.api.db.Model(&models.WebhookDelivery{}).Where("id = ?", pending[0].ID).Update("next_attempt_at", pending[0].CreatedAt).Error)
+
+	suite.api.WebhookAllowPrivate = true
+	suite.api.dispatchWebhooks(context.Background())
+	require.Len(posts, 1)
+	received := <-posts
+	require.Equal("device.create", received.header.Get(WebhookEventHeader))
+	require.Equal(pending[0].ID.String(), received.header.Get(WebhookDeliveryHeader))
+	require.Equal("sha256="+WebhookSignature("secret", received.body), received.header.Get(WebhookSignatureHeader))
+	var event models.WebhookEvent
+	require.NoError(json.Unmarshal(received.body, &event))
+	require.Equal("device.create", event.Type)
+	require.Equal(device.ID, event.ResourceID)
+	require.Equal(suite.testUserID, event.ActorID)
+	delivered := deliveries(webhook)
+	require.Len(delivered, 1)
+	require.NotNil(delivered[0].DeliveredAt)
+	require.Equal(2, delivered[0].Attempts)
+
+	// a failed post is retried later
+	status = http.StatusInternalServerError
+	code, body = suite.serve(http.MethodDelete, "/:id", "/"+device.ID.String(), suite.api.DeleteDevice, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	suite.api.dispatchWebhooks(context.Background())
+	suite.api.dispatchWebhooks(context.Background())
+	require.Len(posts, 1)
+	received = <-posts
+	require.Equal("device.delete", received.header.Get(WebhookEventHeader))
+	failed := deliveries(webhook)
+	require.Len(failed, 2)
+	require.Equal("device.delete", failed[0].EventType)
+	require.Equal(1, failed[0].Attempts)
+	require.Contains(failed[0].LastError, "500")
+	require.True(failed[0].NextAttemptAt.After(failed[0].CreatedAt))
+
+	description := "cmdb"
+	code, body = suite.serve(http.MethodPatch, "/:id/webhooks/:webhook_id", org+"/webhooks/"+webhook.ID.String(), suite.api.UpdateWebhook, models.UpdateWebhook{
+		Description: &description,
+		EventTypes:  []string{"unknown"},
+	})
+	require.Equal(http.StatusBadRequest, code, "HTTP error: %s", string(body))
+	code, body = suite.serve(http.MethodPatch, "/:id/webhooks/:webhook_id", org+"/webhooks/"+webhook.ID.String(), suite.api.UpdateWebhook, models.UpdateWebhook{
+		Description: &description,
+	})
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	code, body = suite.serve(http.MethodGet, "/:id/webhooks", org+"/webhooks", suite.api.ListWebhooks, nil)
+	require.Equal(http.StatusOK, code, "HTTP error: %s", string(body))
+	var webhooks []models.Webhook
+	require.NoError(json.Unmarshal(body, &webhooks))
+	require.Len(webhooks, 1)
+	require.Equal("cmdb", webhooks[0].Description)
+	require.Equal(models.StringArray{"device.create", "device.delete"}, webhooks[0].EventTypes)
+
+	code, _ = suite.serve(http.MethodDelete, "/:id/webhooks/:webhook_id", org+"/webhooks/"+webhook.ID.String(), suite.api.DeleteWebhook, nil)
+	require.Equal(http.StatusOK, code)
+	code, _ = suite.serve(http.MethodGet, "/:id/webhooks/:webhook_id", org+"/webhooks/"+webhook.ID.String(), suite.api.GetWebhook, nil)
+	require.Equal(http.StatusNotFound, code)
+	var count int64
+	require.NoError(suite.api.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID).Count(&count).Error)
+	require.Zero(count)
+}
@@ -0,0 +1,148 @@
+package ipam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/google/uuid"
+	goipam "github.com/metal-stack/go-ipam"
+	"go.uber.org/zap"
+)
+
+// embeddedIPAM is the IPAM allocating in process, so a deployment does not need to run the go-ipam service
+type embeddedIPAM struct {
+	logger  *zap.SugaredLogger
+	ipamer  goipam.Ipamer
+	storage goipam.Storage
+}
+
+// NewEmbeddedIPAM returns an IPAM allocating in process that keeps its state in the storage, e.g. the postgres
+// database of the apiserver or memory for tests.
+func NewEmbeddedIPAM(logger *zap.SugaredLogger, storage goipam.Storage) IPAM {
+	return &embeddedIPAM{
+		logger:  logger,
+		ipamer:  goipam.NewWithStorage(storage),
+		storage: storage,
+	}
+}
+
+func withNamespace(ctx context.Context, namespace uuid.UUID) context.Context {
+	return goipam.NewContextWithNamespace(ctx, uuidToNamespace(namespace))
+}
+
+func (i *embeddedIPAM) CreateNamespace(parent context.Context, namespace uuid.UUID) error {
+	ctx, span := tracer.Start(parent, "CreateNamespace")
+	defer span.End()
+	return i.ipamer.CreateNamespace(ctx, uuidToNamespace(namespace))
+}
+
+func (i *embeddedIPAM) DeleteNamespace(parent context.Context, namespace uuid.UUID) error {
+	ctx, span := tracer.Start(parent, "DeleteNamespace")
+	defer span.End()
+	return i.ipamer.DeleteNamespace(ctx, uuidToNamespace(namespace))
+}
+
+func (i *embeddedIPAM) AcquireIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) error {
+	ctx, span := tracer.Start(parent, "AcquireIP")
+	defer span.End()
+	if err := validateIP(TunnelIP); err != nil {
+		return fmt.Errorf("Address %s is not valid", TunnelIP)
+	}
+	_, err := i.ipamer.AcquireSpecificIP(withNamespace(ctx, namespace), ipamPrefix, TunnelIP)
+	if errors.Is(err, goipam.ErrAlreadyAllocated) {
+		return fmt.Errorf("%w: %s", ErrAddressInUse, TunnelIP)
+	}
+	return err
+}
+
+func (i *embeddedIPAM) AssignSpecificTunnelIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) (string, error) {
+	ctx, span := tracer.Start(parent, "AssignSpecificTunnelIP")
+	defer span.End()
+	if err := validateIP(TunnelIP); err != nil {
+		return "", fmt.Errorf("Address %s is not valid", TunnelIP)
+	}
+	ip, err := i.ipamer.AcquireSpecificIP(withNamespace(ctx, namespace), ipamPrefix, TunnelIP)
+	if err != nil {
+		i.logger.Errorf("failed to assign the requested address %s, assigning an address from the pool: %v\n", TunnelIP, err)
+		return i.AssignFromPool(ctx, namespace, ipamPrefix)
+	}
+	return ip.IP.String(), nil
+}
+
+func (i *embeddedIPAM) AssignFromPool(parent context.Context, namespace uuid.UUID, ipamPrefix string) (string, error) {
+	ctx, span := tracer.Start(parent, "AssignFromPool")
+	defer span.End()
+	ip, err := i.ipamer.AcquireIP(withNamespace(ctx, namespace), ipamPrefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire an IPAM assigned address %w", err)
+	}
+	return ip.IP.String(), nil
+}
+
+func (i *embeddedIPAM) AssignCIDR(parent context.Context, namespace uuid.UUID, cidr string) error {
+	ctx, span := tracer.Start(parent, "AssignPrefix")
+	defer span.End()
+	cidr, err := cleanCidr(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid prefix requested: %w", err)
+	}
+	ctx = withNamespace(ctx, namespace)
+	if _, err := i.ipamer.NewPrefix(ctx, cidr); err != nil {
+		// the prefix may have been created already
+		if prefix := i.ipamer.PrefixFrom(ctx, cidr); prefix != nil && prefix.Cidr == cidr && prefix.ParentCidr == "" {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (i *embeddedIPAM) OverlappingPrefix(parent context.Context, namespace uuid.UUID, cidr string) (string, error) {
+	ctx, span := tracer.Start(parent, "OverlappingPrefix")
+	defer span.End()
+	requested, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return "", fmt.Errorf("invalid prefix requested: %w", err)
+	}
+	// the ipamer lists the prefixes of the root namespace whatever the namespace of the context
+	cidrs, err := i.storage.ReadAllPrefixCidrs(ctx, uuidToNamespace(namespace))
+	if err != nil {
+		return "", err
+	}
+	return overlappingPrefix(requested.Masked(), cidrs), nil
+}
+
+func (i *embeddedIPAM) PrefixUsage(parent context.Context, namespace uuid.UUID, cidr string) (uint64, uint64, error) {
+	ctx, span := tracer.Start(parent, "PrefixUsage")
+	defer span.End()
+	cidr, err := cleanCidr(cidr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid prefix requested: %w", err)
+	}
+	prefix := i.ipamer.PrefixFrom(withNamespace(ctx, namespace), cidr)
+	if prefix == nil {
+		return 0, 0, fmt.Errorf("prefix:%q not found", cidr)
+	}
+	usage := prefix.Usage()
+	return usage.AvailableIPs, usage.AcquiredIPs, nil
+}
+
+func (i *embeddedIPAM) ReleaseToPool(ctx context.Context, namespace uuid.UUID, address, cidr string) error {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return fmt.Errorf("failed to release IPAM address %w", err)
+	}
+	if _, err := i.ipamer.ReleaseIP(withNamespace(ctx, namespace), &goipam.IP{IP: addr, ParentPrefix: cidr}); err != nil {
+		return fmt.Errorf("failed to release IPAM address %w", err)
+	}
+	return nil
+}
+
+func (i *embeddedIPAM) ReleaseCIDR(ctx context.Context, namespace uuid.UUID, cidr string) error {
+	if _, err := i.ipamer.DeletePrefix(withNamespace(ctx, namespace), cidr); err != nil {
+		return fmt.Errorf("failed to release IPAM prefix %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package ipam
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	goipam "github.com/metal-stack/go-ipam"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEmbeddedIPAM(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	ipam := NewEmbeddedIPAM(zaptest.NewLogger(t).Sugar(), goipam.NewMemory(ctx))
+	namespace := uuid.New()
+	prefix := "10.20.30.0/24"
+
+	require.NoError(ipam.CreateNamespace(ctx, namespace))
+	require.NoError(ipam.AssignCIDR(ctx, namespace, prefix))
+	// assigning an existing prefix again is not an error
+	require.NoError(ipam.AssignCIDR(ctx, namespace, "10.20.30.1/24"))
+
+	overlapping, err := ipam.OverlappingPrefix(ctx, namespace, "10.20.0.0/16")
+	require.NoError(err)
+	require.Equal(prefix, overlapping)
+	overlapping, err = ipam.OverlappingPrefix(ctx, namespace, prefix)
+	require.NoError(err)
+	require.Empty(overlapping)
+
+	require.NoError(ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"))
+	require.ErrorIs(ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"), ErrAddressInUse)
+
+	// the requested address is taken, an address of the pool is assigned instead
+	address, err := ipam.AssignSpecificTunnelIP(ctx, namespace, prefix, "10.20.30.10")
+	require.NoError(err)
+	require.NotEqual("10.20.30.10", address)
+
+	size, acquired, err := ipam.PrefixUsage(ctx, namespace, prefix)
+	require.NoError(err)
+	require.Equal(uint64(256), size)
+	// the network and broadcast addresses are acquired with the prefix
+	require.Equal(uint64(4), acquired)
+
+	require.NoError(ipam.ReleaseToPool(ctx, namespace, "10.20.30.10", prefix))
+	require.Error(ipam.ReleaseToPool(ctx, namespace, "10.20.30.10", prefix))
+	requested, err := ipam.AssignSpecificTunnelIP(ctx, namespace, prefix, "10.20.30.10")
+	require.NoError(err)
+	require.Equal("10.20.30.10", requested)
+
+	// namespaces are isolated
+	other := uuid.New()
+	require.NoError(ipam.CreateNamespace(ctx, other))
+	require.NoError(ipam.AssignCIDR(ctx, other, prefix))
+	require.NoError(ipam.AcquireIP(ctx, other, prefix, "10.20.30.10"))
+
+	require.NoError(ipam.ReleaseToPool(ctx, namespace, "10.20.30.10", prefix))
+	require.NoError(ipam.ReleaseToPool(ctx, namespace, address, prefix))
+	require.NoError(ipam.ReleaseCIDR(ctx, namespace, prefix))
+	_, _, err = ipam.PrefixUsage(ctx, namespace, prefix)
+	require.Error(err)
+	require.NoError(ipam.DeleteNamespace(ctx, namespace))
+}
+
+func TestEmbeddedIPAMSnapshot(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "ipam.json")
+	namespace := uuid.New()
+	prefix := "10.20.30.0/24"
+
+	storage, err := NewSnapshotStorage(ctx, file)
+	require.NoError(err)
+	ipam := NewEmbeddedIPAM(zaptest.NewLogger(t).Sugar(), storage)
+	require.NoError(ipam.CreateNamespace(ctx, namespace))
+	require.NoError(ipam.AssignCIDR(ctx, namespace, prefix))
+	require.NoError(ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"))
+
+	// the allocations are restored from the snapshot
+	storage, err = NewSnapshotStorage(ctx, file)
+	require.NoError(err)
+	ipam = NewEmbeddedIPAM(zaptest.NewLogger(t).Sugar(), storage)
+	require.Error(ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"))
+	_, acquired, err := ipam.PrefixUsage(ctx, namespace, prefix)
+	require.NoError(err)
+	require.Equal(uint64(3), acquired)
+
+	require.NoError(ipam.ReleaseToPool(ctx, namespace, "10.20.30.10", prefix))
+	storage, err = NewSnapshotStorage(ctx, file)
+	require.NoError(err)
+	ipam = NewEmbeddedIPAM(zaptest.NewLogger(t).Sugar(), storage)
+	require.NoError(ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"))
+}
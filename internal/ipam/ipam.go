@@ -2,13 +2,16 @@ package ipam
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"github.com/bufbuild/connect-go"
 	"github.com/google/uuid"
+	goipam "github.com/metal-stack/go-ipam"
 	apiv1 "github.com/metal-stack/go-ipam/api/v1"
 	"github.com/metal-stack/go-ipam/api/v1/apiv1connect"
 	"go.opentelemetry.io/otel"
@@ -22,17 +25,38 @@ func init() {
 	tracer = otel.Tracer("github.com/nexodus-io/nexodus/internal/ipam")
 }
 
+// ErrAddressInUse is returned when a specific address is acquired while it is already allocated
+var ErrAddressInUse = errors.New("address already in use")
+
 func uuidToNamespace(id uuid.UUID) string {
 	return strings.ReplaceAll(id.String(), "-", "_")
 }
 
-type IPAM struct {
+// IPAM allocates the prefixes of the vpcs, and the tunnel IPs and advertised cidrs of the devices, each vpc with a
+// private cidr in its own namespace.
+type IPAM interface {
+	CreateNamespace(ctx context.Context, namespace uuid.UUID) error
+	DeleteNamespace(ctx context.Context, namespace uuid.UUID) error
+	// AcquireIP allocates the address from the prefix, failing with ErrAddressInUse if it is already allocated
+	AcquireIP(ctx context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) error
+	// AssignSpecificTunnelIP allocates the address from the prefix, or any free address if it is already allocated
+	AssignSpecificTunnelIP(ctx context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) (string, error)
+	AssignFromPool(ctx context.Context, namespace uuid.UUID, ipamPrefix string) (string, error)
+	AssignCIDR(ctx context.Context, namespace uuid.UUID, cidr string) error
+	OverlappingPrefix(ctx context.Context, namespace uuid.UUID, cidr string) (string, error)
+	PrefixUsage(ctx context.Context, namespace uuid.UUID, cidr string) (uint64, uint64, error)
+	ReleaseToPool(ctx context.Context, namespace uuid.UUID, address, cidr string) error
+	ReleaseCIDR(ctx context.Context, namespace uuid.UUID, cidr string) error
+}
+
+// serviceIPAM is the IPAM backed by the go-ipam grpc service
+type serviceIPAM struct {
 	logger *zap.SugaredLogger
 	client apiv1connect.IpamServiceClient
 }
 
 func NewIPAM(logger *zap.SugaredLogger, ipamAddress string) IPAM {
-	return IPAM{
+	return &serviceIPAM{
 		logger: logger,
 		client: apiv1connect.NewIpamServiceClient(
 			http.DefaultClient,
@@ -41,7 +65,7 @@ func NewIPAM(logger *zap.SugaredLogger, ipamAddress string) IPAM {
 		)}
 }
 
-func (i *IPAM) CreateNamespace(parent context.Context, namespace uuid.UUID) error {
+func (i *serviceIPAM) CreateNamespace(parent context.Context, namespace uuid.UUID) error {
 	ctx, span := tracer.Start(parent, "CreateNamespace")
 	defer span.End()
 	_, err := i.client.CreateNamespace(ctx, connect.NewRequest(&apiv1.CreateNamespaceRequest{
@@ -50,7 +74,7 @@ func (i *IPAM) CreateNamespace(parent context.Context, namespace uuid.UUID) erro
 	return err
 }
 
-func (i *IPAM) DeleteNamespace(parent context.Context, namespace uuid.UUID) error {
+func (i *serviceIPAM) DeleteNamespace(parent context.Context, namespace uuid.UUID) error {
 	ctx, span := tracer.Start(parent, "DeleteNamespace")
 	defer span.End()
 	_, err := i.client.DeleteNamespace(ctx, connect.NewRequest(&apiv1.DeleteNamespaceRequest{
@@ -59,7 +83,7 @@ func (i *IPAM) DeleteNamespace(parent context.Context, namespace uuid.UUID) erro
 	return err
 }
 
-func (i *IPAM) AcquireIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) error {
+func (i *serviceIPAM) AcquireIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) error {
 	ctx, span := tracer.Start(parent, "AssignSpecificTunnelIP")
 	defer span.End()
 	if err := validateIP(TunnelIP); err != nil {
@@ -71,10 +95,14 @@ func (i *IPAM) AcquireIP(parent context.Context, namespace uuid.UUID, ipamPrefix
 		Ip:         &TunnelIP,
 		Namespace:  &ns,
 	}))
+	// the service only reports the cause of the failure in its message
+	if err != nil && strings.Contains(err.Error(), goipam.ErrAlreadyAllocated.Error()) {
+		return fmt.Errorf("%w: %s", ErrAddressInUse, TunnelIP)
+	}
 	return err
 }
 
-func (i *IPAM) AssignSpecificTunnelIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) (string, error) {
+func (i *serviceIPAM) AssignSpecificTunnelIP(parent context.Context, namespace uuid.UUID, ipamPrefix string, TunnelIP string) (string, error) {
 	ctx, span := tracer.Start(parent, "AssignSpecificTunnelIP")
 	defer span.End()
 	if err := validateIP(TunnelIP); err != nil {
@@ -93,7 +121,7 @@ func (i *IPAM) AssignSpecificTunnelIP(parent context.Context, namespace uuid.UUI
 	return res.Msg.Ip.Ip, nil
 }
 
-func (i *IPAM) AssignFromPool(parent context.Context, namespace uuid.UUID, ipamPrefix string) (string, error) {
+func (i *serviceIPAM) AssignFromPool(parent context.Context, namespace uuid.UUID, ipamPrefix string) (string, error) {
 	ctx, span := tracer.Start(parent, "AssignFromPool")
 	defer span.End()
 	ns := uuidToNamespace(namespace)
@@ -107,7 +135,7 @@ func (i *IPAM) AssignFromPool(parent context.Context, namespace uuid.UUID, ipamP
 	return res.Msg.Ip.Ip, nil
 }
 
-func (i *IPAM) AssignCIDR(parent context.Context, namespace uuid.UUID, cidr string) error {
+func (i *serviceIPAM) AssignCIDR(parent context.Context, namespace uuid.UUID, cidr string) error {
 	ctx, span := tracer.Start(parent, "AssignPrefix")
 	defer span.End()
 	cidr, err := cleanCidr(cidr)
@@ -129,8 +157,67 @@ func (i *IPAM) AssignCIDR(parent context.Context, namespace uuid.UUID, cidr stri
 	return originalErr
 }
 
+// OverlappingPrefix returns a prefix of the namespace containing the cidr, other than the cidr itself, or an
+// empty string if no prefix of the namespace contains it. The service lists the prefixes of the root namespace
+// whatever the namespace requested, so the prefixes containing the cidr are looked up one by one instead.
+func (i *serviceIPAM) OverlappingPrefix(parent context.Context, namespace uuid.UUID, cidr string) (string, error) {
+	ctx, span := tracer.Start(parent, "OverlappingPrefix")
+	defer span.End()
+	requested, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return "", fmt.Errorf("invalid prefix requested: %w", err)
+	}
+	requested = requested.Masked()
+	ns := uuidToNamespace(namespace)
+	for bits := 0; bits < requested.Bits(); bits++ {
+		candidate := netip.PrefixFrom(requested.Addr(), bits).Masked().String()
+		resp, err := i.client.GetPrefix(ctx, connect.NewRequest(&apiv1.GetPrefixRequest{Cidr: candidate, Namespace: &ns}))
+		if err != nil {
+			if connect.CodeOf(err) == connect.CodeNotFound {
+				continue
+			}
+			return "", err
+		}
+		if resp.Msg.Prefix != nil && resp.Msg.Prefix.Cidr == candidate {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// overlappingPrefix returns the first of the cidrs, other than the requested prefix itself, that overlaps it
+func overlappingPrefix(requested netip.Prefix, cidrs []string) string {
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil || prefix == requested {
+			continue
+		}
+		if prefix.Overlaps(requested) {
+			return cidr
+		}
+	}
+	return ""
+}
+
+// PrefixUsage returns the number of addresses of the prefix, capped at 2^31-1 by go-ipam, and the number of them
+// acquired
+func (i *serviceIPAM) PrefixUsage(parent context.Context, namespace uuid.UUID, cidr string) (uint64, uint64, error) {
+	ctx, span := tracer.Start(parent, "PrefixUsage")
+	defer span.End()
+	cidr, err := cleanCidr(cidr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid prefix requested: %w", err)
+	}
+	ns := uuidToNamespace(namespace)
+	resp, err := i.client.PrefixUsage(ctx, connect.NewRequest(&apiv1.PrefixUsageRequest{Cidr: cidr, Namespace: &ns}))
+	if err != nil {
+		return 0, 0, err
+	}
+	return resp.Msg.AvailableIps, resp.Msg.AcquiredIps, nil
+}
+
 // ReleaseToPool release the ipam address back to the specified prefix
-func (i *IPAM) ReleaseToPool(ctx context.Context, namespace uuid.UUID, address, cidr string) error {
+func (i *serviceIPAM) ReleaseToPool(ctx context.Context, namespace uuid.UUID, address, cidr string) error {
 	ns := uuidToNamespace(namespace)
 	_, err := i.client.ReleaseIP(ctx, connect.NewRequest(&apiv1.ReleaseIPRequest{
 		Ip:         address,
@@ -145,7 +232,7 @@ func (i *IPAM) ReleaseToPool(ctx context.Context, namespace uuid.UUID, address,
 }
 
 // ReleaseCIDR release the ipam address back to the specified prefix
-func (i *IPAM) ReleaseCIDR(ctx context.Context, namespace uuid.UUID, cidr string) error {
+func (i *serviceIPAM) ReleaseCIDR(ctx context.Context, namespace uuid.UUID, cidr string) error {
 	ns := uuidToNamespace(namespace)
 	_, err := i.client.DeletePrefix(ctx, connect.NewRequest(&apiv1.DeletePrefixRequest{
 		Cidr:      cidr,
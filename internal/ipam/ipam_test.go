@@ -91,6 +91,44 @@ func (suite *IpamTestSuite) TestAllocateTunnelIP() {
 	assert.Equal(suite.T(), "10.20.30.3", ip)
 }
 
+func (suite *IpamTestSuite) TestAcquireIP() {
+	require := suite.Require()
+	ctx := context.Background()
+	namespace := uuid.New()
+	prefix := "10.20.30.0/24"
+
+	require.NoError(suite.ipam.CreateNamespace(ctx, namespace))
+	require.NoError(suite.ipam.AssignCIDR(ctx, namespace, prefix))
+	require.NoError(suite.ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"))
+	require.ErrorIs(suite.ipam.AcquireIP(ctx, namespace, prefix, "10.20.30.10"), ErrAddressInUse)
+	// the address is not in use, the prefix does not exist
+	err := suite.ipam.AcquireIP(ctx, namespace, "10.20.40.0/24", "10.20.40.10")
+	require.Error(err)
+	require.NotErrorIs(err, ErrAddressInUse)
+}
+
+func (suite *IpamTestSuite) TestOverlappingPrefix() {
+	require := suite.Require()
+	ctx := context.Background()
+	namespace := uuid.New()
+
+	require.NoError(suite.ipam.CreateNamespace(ctx, namespace))
+	require.NoError(suite.ipam.AssignCIDR(ctx, namespace, "10.100.0.0/16"))
+
+	overlapping, err := suite.ipam.OverlappingPrefix(ctx, namespace, "10.100.20.0/24")
+	require.NoError(err)
+	require.Equal("10.100.0.0/16", overlapping)
+
+	// the prefix itself is not an overlap
+	overlapping, err = suite.ipam.OverlappingPrefix(ctx, namespace, "10.100.0.0/16")
+	require.NoError(err)
+	require.Empty(overlapping)
+
+	overlapping, err = suite.ipam.OverlappingPrefix(ctx, namespace, "10.200.0.0/16")
+	require.NoError(err)
+	require.Empty(overlapping)
+}
+
 func TestIpamTestSuite(t *testing.T) {
 	suite.Run(t, new(IpamTestSuite))
 }
@@ -0,0 +1,137 @@
+package ipam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	goipam "github.com/metal-stack/go-ipam"
+)
+
+// namespacedDumper dumps and loads the prefixes of a namespace, the go-ipam ipamer implements it
+type namespacedDumper interface {
+	NamespacedDump(ctx context.Context, namespace string) (string, error)
+	NamespacedLoad(ctx context.Context, namespace, dump string) error
+}
+
+// snapshotStorage is a go-ipam storage keeping the prefixes in memory that writes a snapshot of them to a file
+// after every change, so an embedded IPAM keeps its allocations across restarts without a postgres database.
+type snapshotStorage struct {
+	goipam.Storage
+	dumper namespacedDumper
+	file   string
+	mu     sync.Mutex
+}
+
+// NewSnapshotStorage returns a go-ipam storage that keeps its prefixes in memory and in the snapshot file, the
+// prefixes of an existing snapshot file are loaded.
+func NewSnapshotStorage(ctx context.Context, file string) (goipam.Storage, error) {
+	memory := goipam.NewMemory(ctx)
+	dumper, ok := goipam.NewWithStorage(memory).(namespacedDumper)
+	if !ok {
+		return nil, errors.New("the ipamer can not dump its namespaces")
+	}
+	s := &snapshotStorage{
+		Storage: memory,
+		dumper:  dumper,
+		file:    file,
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot := map[string]string{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid ipam snapshot %s: %w", file, err)
+	}
+	for namespace, dump := range snapshot {
+		if err := memory.CreateNamespace(ctx, namespace); err != nil {
+			return nil, err
+		}
+		if err := dumper.NamespacedLoad(ctx, namespace, dump); err != nil {
+			return nil, fmt.Errorf("invalid ipam snapshot %s: %w", file, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *snapshotStorage) Name() string {
+	return "snapshot"
+}
+
+// save writes the prefixes of all the namespaces to the snapshot file, the previous snapshot is only replaced
+// once the new one is complete.
+func (s *snapshotStorage) save(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	namespaces, err := s.Storage.ListNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	snapshot := map[string]string{}
+	for _, namespace := range namespaces {
+		snapshot[namespace], err = s.dumper.NamespacedDump(ctx, namespace)
+		if err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.file+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(s.file+".tmp", s.file)
+}
+
+func (s *snapshotStorage) CreatePrefix(ctx context.Context, prefix goipam.Prefix, namespace string) (goipam.Prefix, error) {
+	prefix, err := s.Storage.CreatePrefix(ctx, prefix, namespace)
+	if err != nil {
+		return prefix, err
+	}
+	return prefix, s.save(ctx)
+}
+
+func (s *snapshotStorage) UpdatePrefix(ctx context.Context, prefix goipam.Prefix, namespace string) (goipam.Prefix, error) {
+	prefix, err := s.Storage.UpdatePrefix(ctx, prefix, namespace)
+	if err != nil {
+		return prefix, err
+	}
+	return prefix, s.save(ctx)
+}
+
+func (s *snapshotStorage) DeletePrefix(ctx context.Context, prefix goipam.Prefix, namespace string) (goipam.Prefix, error) {
+	prefix, err := s.Storage.DeletePrefix(ctx, prefix, namespace)
+	if err != nil {
+		return prefix, err
+	}
+	return prefix, s.save(ctx)
+}
+
+func (s *snapshotStorage) DeleteAllPrefixes(ctx context.Context, namespace string) error {
+	if err := s.Storage.DeleteAllPrefixes(ctx, namespace); err != nil {
+		return err
+	}
+	return s.save(ctx)
+}
+
+func (s *snapshotStorage) CreateNamespace(ctx context.Context, namespace string) error {
+	if err := s.Storage.CreateNamespace(ctx, namespace); err != nil {
+		return err
+	}
+	return s.save(ctx)
+}
+
+func (s *snapshotStorage) DeleteNamespace(ctx context.Context, namespace string) error {
+	if err := s.Storage.DeleteNamespace(ctx, namespace); err != nil {
+		return err
+	}
+	return s.save(ctx)
+}
@@ -0,0 +1,6 @@
+package models
+
+// AdminImpersonateUser is the information needed to impersonate a user.
+type AdminImpersonateUser struct {
+	Reason string `json:"reason" example:"support ticket 1234"` // Reason the user is impersonated, it is recorded in the audit log.
+}
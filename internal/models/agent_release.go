@@ -0,0 +1,17 @@
+package models
+
+const (
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta"
+)
+
+// AgentRelease is a nexd binary advertised to the agents of organizations subscribed to an update channel
+type AgentRelease struct {
+	Channel   string `json:"channel" example:"stable"`
+	Version   string `json:"version" example:"v0.1.0"`
+	Os        string `json:"os" example:"linux"`
+	Arch      string `json:"arch" example:"amd64"`
+	URL       string `json:"url" example:"https://nexodus-io.s3.amazonaws.com/nexd-linux-amd64"`
+	Sha256    string `json:"sha256"`    // hex encoded sha256 digest of the binary
+	Signature string `json:"signature"` // base64 encoded ed25519 signature of the binary
+}
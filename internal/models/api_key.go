@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a personal api key of a user, scripts call the api as the user with the scopes of the key without
+// an interactive login.
+type APIKey struct {
+	Base
+	UserID      uuid.UUID   `json:"user_id"`                                           // UserID is the ID of the user the api requests of the key are made as.
+	Description string      `json:"description,omitempty"`                             // Description of the api key.
+	Scopes      StringArray `json:"scopes" swaggertype:"array,string"`                 // Scopes of the api requests of the key.
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`                              // ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.
+	TokenHash   string      `json:"-"`                                                 // TokenHash is the SHA-256 hash of the token.
+	Token       string      `json:"token,omitempty" gorm:"-:all" example:"AK:ABCD..."` // Token is only returned when the api key is created, clients send it as the bearer token.
+}
+
+// AddAPIKey is the information needed to add an api key.
+type AddAPIKey struct {
+	Description string     `json:"description,omitempty"` // Description of the api key.
+	Scopes      []string   `json:"scopes"`                // Scopes of the api key, read-only is all the read scopes.
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`  // ExpiresAt is optional, if set the api key is only valid until the ExpiresAt time.
+}
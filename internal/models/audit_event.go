@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionExpire = "expire"
+	// AuditActionList records the resources a controller admin listed across the organizations
+	AuditActionList = "list"
+	// AuditActionImpersonate records the api keys a controller admin created to act as a user
+	AuditActionImpersonate = "impersonate"
+)
+
+// AuditEvent is an append-only record of a mutation of a control plane resource
+type AuditEvent struct {
+	ID             uuid.UUID              `json:"id" gorm:"type:uuid;primary_key" example:"aa22666c-0f57-45cb-a449-16efecc04f2e"`
+	CreatedAt      time.Time              `json:"created_at" gorm:"index"`
+	OrganizationID uuid.UUID              `json:"organization_id" gorm:"type:uuid;index"`
+	ActorID        uuid.UUID              `json:"actor_id" gorm:"type:uuid;index"` // the user that made the change
+	SourceIP       string                 `json:"source_ip" example:"203.0.113.7"`
+	Action         string                 `json:"action" example:"update"`
+	ResourceType   string                 `json:"resource_type" example:"device"`
+	ResourceID     uuid.UUID              `json:"resource_id" gorm:"type:uuid;index"`
+	Changes        map[string]AuditChange `json:"changes" gorm:"type:JSONB; serializer:json"`
+}
+
+// AuditChange holds the previous and new value of a changed field
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// BeforeCreate populates the ID (if not set)
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -11,25 +11,39 @@ import (
 // Devices belong to one User and may be onboarded into an organization
 type Device struct {
 	Base
-	OwnerID         uuid.UUID      `json:"owner_id"`
-	VpcID           uuid.UUID      `json:"vpc_id" example:"694aa002-5d19-495e-980b-3d8fd508ea10"`
-	OrganizationID  uuid.UUID      `json:"-"` // Denormalized from the VPC record for performance
-	PublicKey       string         `json:"public_key"`
-	AllowedIPs      pq.StringArray `json:"allowed_ips" gorm:"type:text[]" swaggertype:"array,string"`
-	IPv4TunnelIPs   []TunnelIP     `json:"ipv4_tunnel_ips" gorm:"type:JSONB; serializer:json"`
-	IPv6TunnelIPs   []TunnelIP     `json:"ipv6_tunnel_ips" gorm:"type:JSONB; serializer:json"`
-	AdvertiseCidrs  pq.StringArray `json:"advertise_cidrs" gorm:"type:text[]" swaggertype:"array,string"`
-	Relay           bool           `json:"relay"`
-	SymmetricNat    bool           `json:"symmetric_nat"`
-	Hostname        string         `json:"hostname"`
-	Os              string         `json:"os"`
-	Endpoints       []Endpoint     `json:"endpoints" gorm:"type:JSONB; serializer:json"`
-	Revision        uint64         `json:"revision" gorm:"type:bigserial;index:"`
-	SecurityGroupId uuid.UUID      `json:"security_group_id"`
-	Online          bool           `json:"online"`
-	OnlineAt        *time.Time     `json:"online_at"`
-	RegKeyID        uuid.UUID      `json:"-"`                      // the reg key id that created the device (if it was created with a registration token)
-	BearerToken     string         `json:"bearer_token,omitempty"` // the token nexd should use to reconcile device state.
+	OwnerID          uuid.UUID         `json:"owner_id"`
+	VpcID            uuid.UUID         `json:"vpc_id" example:"694aa002-5d19-495e-980b-3d8fd508ea10"`
+	OrganizationID   uuid.UUID         `json:"-"` // Denormalized from the VPC record for performance
+	PublicKey        string            `json:"public_key"`
+	AllowedIPs       pq.StringArray    `json:"allowed_ips" gorm:"type:text[]" swaggertype:"array,string"`
+	IPv4TunnelIPs    []TunnelIP        `json:"ipv4_tunnel_ips" gorm:"type:JSONB; serializer:json"`
+	IPv6TunnelIPs    []TunnelIP        `json:"ipv6_tunnel_ips" gorm:"type:JSONB; serializer:json"`
+	AdvertiseCidrs   pq.StringArray    `json:"advertise_cidrs" gorm:"type:text[]" swaggertype:"array,string"`
+	Relay            bool              `json:"relay"`
+	SymmetricNat     bool              `json:"symmetric_nat"`
+	RelayID          uuid.UUID         `json:"relay_id"`    // the relay the service assigned to the device when it is behind a symmetric NAT
+	NatType          string            `json:"nat_type"`    // the RFC 5780 mapping behavior of the NAT in front of the device, empty when unknown
+	NatHairpin       bool              `json:"nat_hairpin"` // whether the NAT in front of the device supports hairpinning
+	Hostname         string            `json:"hostname"`
+	DnsName          string            `json:"dns_name"`                                  // the name reserved for the device in the overlay DNS of the organization
+	DisplayName      string            `json:"display_name"`                              // the name users gave the device, the dns name of the device is derived from it when set
+	Labels           map[string]string `json:"labels" gorm:"type:JSONB; serializer:json"` // key/value inventory metadata operators attach to the device, e.g. rack, owner or environment
+	Os               string            `json:"os"`
+	Endpoints        []Endpoint        `json:"endpoints" gorm:"type:JSONB; serializer:json"`
+	EndpointIPv6     string            `json:"endpoint_ipv6"` // the global IPv6 endpoint of the device, empty when it has no global IPv6 connectivity
+	ListenPort       int               `json:"listen_port"`
+	Revision         uint64            `json:"revision" gorm:"type:bigserial;index:"`
+	SecurityGroupId  uuid.UUID         `json:"security_group_id"`
+	SecurityGroupIds pq.StringArray    `json:"security_group_ids" gorm:"type:text[]" swaggertype:"array,string"` // the additional security groups attached to the device, denormalized from the device_security_groups table
+	Online           bool              `json:"online"`
+	OnlineAt         *time.Time        `json:"online_at"`
+	LastSeen         *time.Time        `json:"last_seen"`              // the last time the device was connected to the event stream of the service
+	RegKeyID         uuid.UUID         `json:"-"`                      // the reg key id that created the device (if it was created with a registration token)
+	BearerToken      string            `json:"bearer_token,omitempty"` // the token nexd should use to reconcile device state.
+	IpamReleasedAt   *time.Time        `json:"-"`                      // when the IPAM allocations of the deleted device were released
+	Pending          bool              `json:"pending"`                // the device awaits the approval of an organization owner, it neither receives peers nor is a peer of the other devices
+	ExpiredAt        *time.Time        `json:"expired_at"`             // when the device was flagged for not being seen for the device ttl of its organization, cleared when it is seen again
+	Unmanaged        bool              `json:"unmanaged"`              // the device does not run nexd, it is configured with a wg-quick config exported by nexctl
 }
 
 // AddDevice is the information needed to add a new Device.
@@ -40,20 +54,71 @@ type AddDevice struct {
 	IPv4TunnelIPs   []TunnelIP `json:"ipv4_tunnel_ips" gorm:"type:JSONB; serializer:json"`
 	Relay           bool       `json:"relay"`
 	SymmetricNat    bool       `json:"symmetric_nat"`
+	NatType         string     `json:"nat_type" example:"endpoint-independent"`
+	NatHairpin      bool       `json:"nat_hairpin"`
 	Hostname        string     `json:"hostname" example:"myhost"`
+	DnsName         string     `json:"dns_name" example:"myhost"`
 	Endpoints       []Endpoint `json:"endpoints" gorm:"type:JSONB; serializer:json"`
+	EndpointIPv6    string     `json:"endpoint_ipv6" example:"[2001:db8::1]:51820"`
 	Os              string     `json:"os"`
 	SecurityGroupId uuid.UUID  `json:"security_group_id"`
+	ListenPort      int        `json:"listen_port" example:"51820"`
+	DisableIPv6     bool       `json:"disable_ipv6"` // when set, the device is not assigned an IPv6 tunnel address
+	Unmanaged       bool       `json:"unmanaged"`    // the device does not run nexd, it is configured with a wg-quick config exported by nexctl
+}
+
+// DeviceChanges are the changes made to the devices of an organization since a revision
+type DeviceChanges struct {
+	Revision uint64      `json:"revision"`                           // the revision to list the next changes from
+	Devices  []Device    `json:"devices"`                            // the devices that were added or updated
+	Deleted  []uuid.UUID `json:"deleted" swaggertype:"array,string"` // the ids of the devices that were deleted
 }
 
 // UpdateDevice is the information needed to update a Device.
 type UpdateDevice struct {
-	VpcID           *uuid.UUID `json:"vpc_id" example:"694aa002-5d19-495e-980b-3d8fd508ea10"`
-	AdvertiseCidrs  []string   `json:"advertise_cidrs" example:"172.16.42.0/24"`
-	SymmetricNat    *bool      `json:"symmetric_nat"`
-	Hostname        string     `json:"hostname" example:"myhost"`
-	Endpoints       []Endpoint `json:"endpoints" gorm:"type:JSONB; serializer:json"`
-	Revision        *uint64    `json:"revision"`
-	Relay           *bool      `json:"relay"`
-	SecurityGroupId *uuid.UUID `json:"security_group_id"`
+	VpcID           *uuid.UUID        `json:"vpc_id" example:"694aa002-5d19-495e-980b-3d8fd508ea10"`
+	AdvertiseCidrs  []string          `json:"advertise_cidrs" example:"172.16.42.0/24"`
+	SymmetricNat    *bool             `json:"symmetric_nat"`
+	NatType         *string           `json:"nat_type" example:"endpoint-independent"`
+	NatHairpin      *bool             `json:"nat_hairpin"`
+	Hostname        string            `json:"hostname" example:"myhost"`
+	DnsName         string            `json:"dns_name" example:"myhost"`
+	DisplayName     *string           `json:"display_name" example:"Build Server" extensions:"x-nullable"` // renames the device, an empty name reverts to the hostname
+	Labels          map[string]string `json:"labels"`                                                      // the labels to set on the device, a label with an empty value is removed
+	Endpoints       []Endpoint        `json:"endpoints" gorm:"type:JSONB; serializer:json"`
+	EndpointIPv6    *string           `json:"endpoint_ipv6" example:"[2001:db8::1]:51820"`
+	Revision        *uint64           `json:"revision"`
+	Relay           *bool             `json:"relay"`
+	SecurityGroupId *uuid.UUID        `json:"security_group_id"`
+	ListenPort      *int              `json:"listen_port" example:"51820"`
+}
+
+// RotateDeviceKey is the new public key of a device rotating its wireguard keys
+type RotateDeviceKey struct {
+	PublicKey string `json:"public_key"`
+}
+
+// BatchDeleteDevices selects the devices to delete in one request, either by id or all the devices of an organization
+type BatchDeleteDevices struct {
+	DeviceIDs      []uuid.UUID `json:"device_ids" swaggertype:"array,string"` // the devices to delete
+	OrganizationID uuid.UUID   `json:"organization_id"`                       // deletes all the devices of the organization, only an owner of the organization can
+}
+
+// BatchUpdateDeviceLabels sets labels on several devices in one request, either by id or all the devices of an organization
+type BatchUpdateDeviceLabels struct {
+	DeviceIDs      []uuid.UUID       `json:"device_ids" swaggertype:"array,string"` // the devices to label
+	OrganizationID uuid.UUID         `json:"organization_id"`                       // labels all the devices of the organization, only an owner of the organization can
+	Labels         map[string]string `json:"labels"`                                // the labels to set on the devices, a label with an empty value is removed
+}
+
+// BatchDeviceResult reports the devices a batch operation was applied to and the ones it failed for
+type BatchDeviceResult struct {
+	Succeeded []uuid.UUID          `json:"succeeded" swaggertype:"array,string"`
+	Failed    []BatchDeviceFailure `json:"failed"`
+}
+
+// BatchDeviceFailure is a device a batch operation failed for
+type BatchDeviceFailure struct {
+	DeviceID uuid.UUID `json:"device_id"`
+	Error    string    `json:"error"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// DNSRecord is a custom record of the overlay DNS zone of an organization, it is served by the agents next to
+// the names of the devices and the services of the organization.
+type DNSRecord struct {
+	Base
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Name           string    `json:"name" example:"www"`            // the name of the record in the zone of the organization
+	Type           string    `json:"type" example:"A"`              // A, AAAA or CNAME
+	Value          string    `json:"value" example:"100.100.0.100"` // the address of an A or AAAA record, the target name of a CNAME record
+	Description    string    `json:"description"`
+	Revision       uint64    `json:"revision"  gorm:"type:bigserial;index:"`
+}
+
+// AddDNSRecord is the information needed to add a record to the overlay DNS zone of an organization.
+type AddDNSRecord struct {
+	Name        string `json:"name" example:"www"`
+	Type        string `json:"type" example:"A"`
+	Value       string `json:"value" example:"100.100.0.100"`
+	Description string `json:"description"`
+}
+
+// UpdateDNSRecord is the information needed to update a record, the name and the type of a record can not be
+// changed.
+type UpdateDNSRecord struct {
+	Value       *string `json:"value"`
+	Description *string `json:"description"`
+}
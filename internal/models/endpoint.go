@@ -5,4 +5,6 @@ type Endpoint struct {
 	Source string `json:"source"`
 	// IP address and port of the endpoint.
 	Address string `json:"address" example:"10.1.1.1:51820"`
+	// Priority of the endpoint candidate, peers try the candidates with a higher priority first.
+	Priority int `json:"priority,omitempty" example:"126"`
 }
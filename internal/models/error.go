@@ -138,3 +138,39 @@ func NewNotAllowedError(reason string) NotAllowedError {
 		},
 	}
 }
+
+// UpgradeRequiredError is returned in the body of an HTTP 426
+type UpgradeRequiredError struct {
+	BaseError
+	CurrentVersion string `json:"current_version,omitempty" example:"2024.01.31-abc123"`
+	MinimumVersion string `json:"minimum_version" example:"2024.03.05"`
+	Instructions   string `json:"instructions,omitempty"`
+}
+
+func NewUpgradeRequiredError(currentVersion string, minimumVersion string, instructions string) UpgradeRequiredError {
+	return UpgradeRequiredError{
+		CurrentVersion: currentVersion,
+		MinimumVersion: minimumVersion,
+		Instructions:   instructions,
+		BaseError: BaseError{
+			Error: "agent upgrade required",
+		},
+	}
+}
+
+// QuotaExceededError is returned in the body of an HTTP 403 when an organization has reached a quota
+type QuotaExceededError struct {
+	BaseError
+	Resource string `json:"resource" example:"devices"`
+	Limit    int    `json:"limit" example:"100"`
+}
+
+func NewQuotaExceededError(resource string, limit int) QuotaExceededError {
+	return QuotaExceededError{
+		Resource: resource,
+		Limit:    limit,
+		BaseError: BaseError{
+			Error: "organization quota exceeded",
+		},
+	}
+}
@@ -18,3 +18,10 @@ func TestConflictsError(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, e, e2)
 }
+
+func TestQuotaExceededError(t *testing.T) {
+	e := NewQuotaExceededError("devices", 10)
+	b, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.Equal(t, `{"error":"organization quota exceeded","resource":"devices","limit":10}`, string(b))
+}
@@ -0,0 +1,30 @@
+package models
+
+// FeatureFlag is a feature flag managed through the api, it overrides the flag of the same name configured on the
+// apiserver. The flag is enabled for the organizations it targets and for a stable percentage of the others, and
+// has the Enabled value for the rest.
+type FeatureFlag struct {
+	Base
+	Name          string      `json:"name" gorm:"uniqueIndex" example:"exit-node"`
+	Description   string      `json:"description,omitempty"`
+	Enabled       bool        `json:"enabled"`                                            // Enabled is the value of the flag for the organizations that are not targeted.
+	Organizations StringArray `json:"organizations,omitempty" swaggertype:"array,string"` // Organizations are the IDs of the organizations the flag is enabled for.
+	Percentage    int         `json:"percentage,omitempty"`                               // Percentage of the other organizations the flag is enabled for, from 0 to 100.
+}
+
+// AddFeatureFlag is the information needed to add a feature flag.
+type AddFeatureFlag struct {
+	Name          string   `json:"name" example:"exit-node"`
+	Description   string   `json:"description,omitempty"`
+	Enabled       bool     `json:"enabled"`
+	Organizations []string `json:"organizations,omitempty"`
+	Percentage    int      `json:"percentage,omitempty"`
+}
+
+// UpdateFeatureFlag is the information needed to update a feature flag, the fields left out are not changed.
+type UpdateFeatureFlag struct {
+	Description   *string  `json:"description,omitempty"`
+	Enabled       *bool    `json:"enabled,omitempty"`
+	Organizations []string `json:"organizations"` // Organizations replace the targeted organizations when present, an empty list removes them.
+	Percentage    *int     `json:"percentage,omitempty"`
+}
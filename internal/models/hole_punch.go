@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HolePunchMetadataPrefix is the prefix of the device metadata keys hole punches are signaled with,
+// the key of a hole punch is the prefix followed by the id of the peer.
+const HolePunchMetadataPrefix = "hole-punch:"
+
+// HolePunch coordinates the simultaneous UDP sends of two devices behind NATs to open a direct path
+// between them. The service signals it to both devices with the same start time.
+type HolePunch struct {
+	ID            uuid.UUID  `json:"id"`
+	DeviceID      uuid.UUID  `json:"device_id"` // the device the hole punch is signaled to
+	PeerID        uuid.UUID  `json:"peer_id"`
+	PeerPublicKey string     `json:"peer_public_key"`
+	PeerEndpoints []Endpoint `json:"peer_endpoints"`
+	PeerNatType   string     `json:"peer_nat_type"`
+	StartAt       time.Time  `json:"start_at"` // when both devices start sending
+	// when set, the device sprays many ports of the peer, whose NAT maps a different port per destination
+	Birthday bool `json:"birthday"`
+}
+
+// AddHolePunch is the information needed to coordinate a hole punch with a peer.
+type AddHolePunch struct {
+	PeerID uuid.UUID `json:"peer_id"`
+}
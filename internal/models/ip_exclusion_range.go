@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// IPExclusionRange is a range of addresses of the organization cidr that IPAM never hands out to devices, e.g.
+// the addresses assigned manually or kept for future growth.
+type IPExclusionRange struct {
+	Base
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid"`
+	StartAddress   string    `json:"start_address" example:"10.200.0.1"` // the first excluded address
+	EndAddress     string    `json:"end_address" example:"10.200.0.99"`  // the last excluded address
+	Description    string    `json:"description"`
+}
+
+// AddIPExclusionRange is the information needed to add an exclusion range to an organization.
+type AddIPExclusionRange struct {
+	StartAddress string `json:"start_address" example:"10.200.0.1"`
+	EndAddress   string `json:"end_address" example:"10.200.0.99"`
+	Description  string `json:"description"`
+}
+
+// UpdateIPExclusionRange is the information needed to update an exclusion range, the addresses of a range can
+// not be changed.
+type UpdateIPExclusionRange struct {
+	Description *string `json:"description"`
+}
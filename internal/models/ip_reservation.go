@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// IPReservation reserves a tunnel IP of a VPC to a device identity, so that the device gets the same address
+// every time it registers, even when the address was not requested with --request-ip.
+type IPReservation struct {
+	Base
+	VpcID          uuid.UUID  `json:"vpc_id" gorm:"type:uuid" example:"694aa002-5d19-495e-980b-3d8fd508ea10"`
+	OrganizationID uuid.UUID  `json:"-" gorm:"type:uuid"`                   // Denormalized from the VPC record for performance
+	PublicKey      string     `json:"public_key,omitempty"`                 // the public key of the device the address is reserved to
+	DeviceID       *uuid.UUID `json:"device_id,omitempty" gorm:"type:uuid"` // the id of the device the address is reserved to
+	Address        string     `json:"address" example:"100.64.0.10"`
+	Description    string     `json:"description"`
+}
+
+// AddIPReservation is the information needed to reserve a tunnel IP to a device identity.
+type AddIPReservation struct {
+	PublicKey   string    `json:"public_key"`
+	DeviceID    uuid.UUID `json:"device_id"`
+	Address     string    `json:"address" example:"100.64.0.10"`
+	Description string    `json:"description"`
+}
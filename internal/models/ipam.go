@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// IPAMPrefixUsage reports how many addresses of a VPC prefix are handed out.
+type IPAMPrefixUsage struct {
+	VpcID uuid.UUID `json:"vpc_id"`
+	Cidr  string    `json:"cidr" example:"100.64.0.0/10"`
+	// Shared is set when the prefix is the pool shared by all the VPCs without a private cidr
+	Shared bool `json:"shared"`
+	// Size is the number of addresses of the prefix, capped at 2147483647
+	Size      uint64 `json:"size"`
+	Allocated uint64 `json:"allocated"`
+	Free      uint64 `json:"free"`
+	// Fragmentation is 0 when the free addresses form a single block and approaches 1 as they get scattered
+	Fragmentation float64 `json:"fragmentation" example:"0.25"`
+}
+
+// OrganizationIPAM reports the address usage of the prefixes of the VPCs of an organization.
+type OrganizationIPAM struct {
+	OrganizationID uuid.UUID         `json:"organization_id"`
+	Prefixes       []IPAMPrefixUsage `json:"prefixes"`
+}
+
+// StaleLease is an IPAM allocation of a device that was deleted, or that has not been seen for the reclaim period.
+type StaleLease struct {
+	DeviceID       uuid.UUID `json:"device_id"`
+	VpcID          uuid.UUID `json:"vpc_id"`
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Address        string    `json:"address,omitempty" example:"100.64.0.10"` // the tunnel IP, empty for an advertised child prefix
+	Cidr           string    `json:"cidr" example:"100.64.0.0/10"`            // the prefix the address belongs to, or the advertised child prefix
+	Reason         string    `json:"reason" example:"deleted"`                // deleted or not-seen
+}
+
+// StaleLeaseReport lists the IPAM allocations the reclaim job releases on its next run.
+type StaleLeaseReport struct {
+	Leases []StaleLease `json:"leases"`
+}
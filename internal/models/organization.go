@@ -7,8 +7,15 @@ import (
 // Organization contains Users and VPCs
 type Organization struct {
 	Base
-	Name        string `json:"name" gorm:"uniqueIndex" sql:"index" example:"zone-red"`
-	Description string `json:"description" example:"Team A"`
+	Name                  string            `json:"name" gorm:"uniqueIndex" sql:"index" example:"zone-red"`
+	Description           string            `json:"description" example:"Team A"`
+	ListenPortMin         int               `json:"listen_port_min" example:"51820"` // the lowest wireguard listen port devices may use, 0 means no limit
+	ListenPortMax         int               `json:"listen_port_max" example:"51830"` // the highest wireguard listen port devices may use, 0 means no limit
+	UpdateChannel         string            `json:"update_channel" example:"stable"` // the agent release channel devices in the org follow
+	RequireDeviceApproval bool              `json:"require_device_approval"`         // new devices of the org are pending until an owner of the org approves them
+	DeviceTTLDays         int               `json:"device_ttl_days" example:"30"`    // devices not seen for this many days are expired, 0 means devices do not expire
+	DeleteExpiredDevices  bool              `json:"delete_expired_devices"`          // expired devices are deleted and their IPAM allocations released, instead of only flagged
+	Quota                 OrganizationQuota `json:"quota" gorm:"embedded;embeddedPrefix:quota_"`
 
 	Users       []*User       `json:"-" gorm:"many2many:user_organizations;"`
 	Invitations []*Invitation `json:"-"`
@@ -21,7 +28,53 @@ func (z *Organization) BeforeCreate(tx *gorm.DB) error {
 	return z.Base.BeforeCreate(tx)
 }
 
+// HasListenPortRange returns true if the organization restricts the wireguard listen ports of its devices
+func (z *Organization) HasListenPortRange() bool {
+	return z.ListenPortMin != 0 || z.ListenPortMax != 0
+}
+
+// ListenPortAllowed returns true if the port is within the organization's listen port range
+func (z *Organization) ListenPortAllowed(port int) bool {
+	if !z.HasListenPortRange() {
+		return true
+	}
+	return port >= z.ListenPortMin && port <= z.ListenPortMax
+}
+
+// AgentUpdateChannel returns the agent release channel of the organization
+func (z *Organization) AgentUpdateChannel() string {
+	if z.UpdateChannel == "" {
+		return UpdateChannelStable
+	}
+	return z.UpdateChannel
+}
+
+// OrganizationQuota limits the resources of an organization, a limit of 0 means no limit
+type OrganizationQuota struct {
+	MaxDevices        int `json:"max_devices" example:"100"`
+	MaxSecurityGroups int `json:"max_security_groups" example:"10"` // not counting the default security group of each vpc
+	MaxChildPrefixes  int `json:"max_child_prefixes" example:"20"`  // the total number of cidrs advertised by the devices
+}
+
 type AddOrganization struct {
-	Name        string `json:"name" example:"zone-red"`
-	Description string `json:"description" example:"The Red Zone"`
+	Name                  string `json:"name" example:"zone-red"`
+	Description           string `json:"description" example:"The Red Zone"`
+	ListenPortMin         int    `json:"listen_port_min" example:"51820"`
+	ListenPortMax         int    `json:"listen_port_max" example:"51830"`
+	UpdateChannel         string `json:"update_channel" example:"stable"`
+	RequireDeviceApproval bool   `json:"require_device_approval"`
+	DeviceTTLDays         int    `json:"device_ttl_days" example:"30"`
+	DeleteExpiredDevices  bool   `json:"delete_expired_devices"`
+	Ipv4Cidr              string `json:"ipv4_cidr" example:"10.200.0.0/16"` // the IPv4 prefix of the default vpc of the organization, the vpc is only created when a prefix is given
+	Ipv6Cidr              string `json:"ipv6_cidr" example:"fd00:200::/64"` // the IPv6 prefix of the default vpc of the organization
+}
+
+type UpdateOrganization struct {
+	Description           *string `json:"description" example:"The Red Zone"`
+	ListenPortMin         *int    `json:"listen_port_min" example:"51820"`
+	ListenPortMax         *int    `json:"listen_port_max" example:"51830"`
+	UpdateChannel         *string `json:"update_channel" example:"stable"`
+	RequireDeviceApproval *bool   `json:"require_device_approval" extensions:"x-nullable"`
+	DeviceTTLDays         *int    `json:"device_ttl_days" example:"30" extensions:"x-nullable"`
+	DeleteExpiredDevices  *bool   `json:"delete_expired_devices" extensions:"x-nullable"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationListenPortAllowed(t *testing.T) {
+	org := Organization{}
+	require.False(t, org.HasListenPortRange())
+	require.True(t, org.ListenPortAllowed(51820))
+
+	org = Organization{ListenPortMin: 51820, ListenPortMax: 51830}
+	require.True(t, org.HasListenPortRange())
+	require.True(t, org.ListenPortAllowed(51820))
+	require.True(t, org.ListenPortAllowed(51830))
+	require.False(t, org.ListenPortAllowed(51819))
+	require.False(t, org.ListenPortAllowed(51831))
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PeerConnectivity is the reachability of a peer last reported by a device, the reports of the devices of an
+// organization make up its connectivity matrix.
+type PeerConnectivity struct {
+	DeviceID       uuid.UUID `json:"device_id" gorm:"type:uuid;primary_key"` // the device that probed the peer
+	PeerID         uuid.UUID `json:"peer_id" gorm:"type:uuid;primary_key"`
+	OrganizationID uuid.UUID `json:"organization_id" gorm:"type:uuid;index"`
+	VpcID          uuid.UUID `json:"vpc_id" gorm:"type:uuid"`
+	Reachability   string    `json:"reachability" example:"reachable"` // reachable, degraded or unreachable
+	Method         string    `json:"method" example:"direct"`          // the peering method of the device with the peer
+	LatencyAvgMs   float64   `json:"latency_avg_ms"`
+	LatencyMaxMs   float64   `json:"latency_max_ms"`
+	Loss           float64   `json:"loss"`       // the fraction of the last probes that were not answered
+	UpdatedAt      time.Time `json:"updated_at"` // the time of the report
+}
+
+// ReportConnectivity is the reachability of the peers of a device, it replaces the previous report of the device.
+type ReportConnectivity struct {
+	Peers []ReportPeerConnectivity `json:"peers"`
+}
+
+// ReportPeerConnectivity is the reachability of one peer of a device.
+type ReportPeerConnectivity struct {
+	PeerID       uuid.UUID `json:"peer_id"`
+	Reachability string    `json:"reachability" example:"reachable"`
+	Method       string    `json:"method" example:"direct"`
+	LatencyAvgMs float64   `json:"latency_avg_ms"`
+	LatencyMaxMs float64   `json:"latency_max_ms"`
+	Loss         float64   `json:"loss"`
+}
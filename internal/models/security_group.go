@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -15,6 +17,14 @@ type SecurityGroup struct {
 	Revision       uint64         `json:"revision"  gorm:"type:bigserial;index:"`
 }
 
+// DeviceSecurityGroup attaches an additional security group to a device, the rules of all
+// the security groups of a device are applied to it.
+type DeviceSecurityGroup struct {
+	DeviceID        uuid.UUID `json:"device_id" gorm:"type:uuid;primary_key"`
+	SecurityGroupID uuid.UUID `json:"security_group_id" gorm:"type:uuid;primary_key;index"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
 // AddSecurityGroup is the information needed to add a new Security Group.
 type AddSecurityGroup struct {
 	Description   string         `json:"description" example:"group_description"`
@@ -0,0 +1,32 @@
+package models
+
+import "github.com/google/uuid"
+
+// Service is a named port of a device, published in the overlay DNS of the organization of the device
+type Service struct {
+	Base
+	OrganizationID uuid.UUID `json:"-"` // Denormalized from the device record for performance
+	DeviceID       uuid.UUID `json:"device_id"`
+	Name           string    `json:"name" example:"postgres"`
+	Port           int       `json:"port" example:"5432"`
+	Protocol       string    `json:"protocol" example:"tcp"`
+	Description    string    `json:"description"`
+	Revision       uint64    `json:"revision"  gorm:"type:bigserial;index:"`
+}
+
+// AddService is the information needed to add a new Service.
+type AddService struct {
+	DeviceID    uuid.UUID `json:"device_id" example:"aa22666c-0f57-45cb-a449-16efecc04f2e"`
+	Name        string    `json:"name" example:"postgres"`
+	Port        int       `json:"port" example:"5432"`
+	Protocol    string    `json:"protocol" example:"tcp"`
+	Description string    `json:"description" example:"the database of the inventory app"`
+}
+
+// UpdateService is the information needed to update an existing Service.
+type UpdateService struct {
+	DeviceID    *uuid.UUID `json:"device_id,omitempty" example:"aa22666c-0f57-45cb-a449-16efecc04f2e"`
+	Port        *int       `json:"port,omitempty" example:"5432"`
+	Protocol    *string    `json:"protocol,omitempty" example:"tcp"`
+	Description *string    `json:"description,omitempty" example:"the database of the inventory app"`
+}
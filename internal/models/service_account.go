@@ -0,0 +1,33 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ServiceAccount is a non-human member of an organization, CI pipelines and automation call the api as the
+// service account with the client credentials of the account.
+type ServiceAccount struct {
+	Base
+	OrganizationID uuid.UUID   `json:"organization_id"`
+	OwnerID        uuid.UUID   `json:"owner_id"`                             // OwnerID is the ID of the user that created the service account.
+	UserID         uuid.UUID   `json:"user_id"`                              // UserID is the ID of the user the api requests of the service account are made as.
+	Name           string      `json:"name" example:"ci"`                    // Name of the service account, the username of its user.
+	Description    string      `json:"description"`                          // Description of the service account.
+	Roles          StringArray `json:"roles" swaggertype:"array,string"`     // Roles of the service account in the organization.
+	SecretHash     string      `json:"-"`                                    // SecretHash is the SHA-256 hash of the client secret.
+	ClientSecret   string      `json:"client_secret,omitempty" gorm:"-:all"` // ClientSecret is only returned when the service account is created.
+}
+
+// AddServiceAccount is the information needed to add a service account to an organization.
+type AddServiceAccount struct {
+	Name        string   `json:"name" example:"ci"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"` // Roles of the service account in the organization, member when not set.
+}
+
+// ServiceAccountToken is the response of the token endpoint to the client credentials of a service account.
+type ServiceAccountToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"3600"` // ExpiresIn is the lifetime of the access token in seconds.
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEventTypes are the types of the events the webhooks can subscribe to, a resource type and an action of the
+// audit events.
+var WebhookEventTypes = []string{
+	"device.create",
+	"device.delete",
+	"device.expire",
+	"security_group.create",
+	"security_group.update",
+	"security_group.delete",
+	"organization_user.create",
+	"organization_user.delete",
+}
+
+// Webhook is an HTTP endpoint the apiserver posts the events of an organization to
+type Webhook struct {
+	Base
+	OrganizationID uuid.UUID   `json:"organization_id" gorm:"type:uuid"`
+	URL            string      `json:"url" example:"https://hooks.example.com/nexodus"`
+	Secret         string      `json:"-"`                                                                            // Secret is the key of the HMAC signature of the posts, it is never returned.
+	EventTypes     StringArray `json:"event_types" swaggertype:"array,string" example:"device.create,device.delete"` // EventTypes are the types of the events posted to the webhook.
+	Description    string      `json:"description"`
+}
+
+// AddWebhook is the information needed to add a webhook to an organization.
+type AddWebhook struct {
+	URL         string   `json:"url" example:"https://hooks.example.com/nexodus"`
+	Secret      string   `json:"secret"`
+	EventTypes  []string `json:"event_types" example:"device.create,device.delete"`
+	Description string   `json:"description"`
+}
+
+// UpdateWebhook is the information needed to update a webhook, the fields left out are not changed.
+type UpdateWebhook struct {
+	URL         *string  `json:"url,omitempty"`
+	Secret      *string  `json:"secret,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Description *string  `json:"description,omitempty"`
+}
+
+// WebhookEvent is the body of the posts to the webhooks
+type WebhookEvent struct {
+	ID             uuid.UUID              `json:"id" example:"aa22666c-0f57-45cb-a449-16efecc04f2e"` // the id of the audit event
+	Type           string                 `json:"type" example:"device.create"`
+	CreatedAt      time.Time              `json:"created_at"`
+	OrganizationID uuid.UUID              `json:"organization_id"`
+	ActorID        uuid.UUID              `json:"actor_id"` // the user that made the change, the nil uuid for the changes of the apiserver jobs
+	ResourceID     uuid.UUID              `json:"resource_id"`
+	Changes        map[string]AuditChange `json:"changes"`
+}
+
+// WebhookDelivery is a post of an event to a webhook, it is retried until the webhook replies with a 2xx status or
+// the attempts run out.
+type WebhookDelivery struct {
+	Base
+	WebhookID     uuid.UUID  `json:"webhook_id" gorm:"type:uuid"`
+	EventID       uuid.UUID  `json:"event_id" gorm:"type:uuid"`
+	EventType     string     `json:"event_type" example:"device.create"`
+	Payload       string     `json:"-"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
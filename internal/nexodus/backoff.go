@@ -0,0 +1,53 @@
+package nexodus
+
+import (
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	// maxApiBackOff caps how long the agent waits between attempts to reach the api server
+	maxApiBackOff = 5 * time.Minute
+)
+
+// apiBackOff spaces out the attempts to reach the api server after a failure so that a control
+// plane restart does not cause every agent to reconnect at the same moment. It is only used from
+// the agent's main reconcile loop.
+type apiBackOff struct {
+	backoff *backoff.ExponentialBackOff
+	retryAt time.Time
+}
+
+func newApiBackOff() *apiBackOff {
+	return &apiBackOff{
+		backoff: util.NewJitteredBackOff(pollInterval, maxApiBackOff),
+	}
+}
+
+// ready is true when the api server may be contacted again
+func (b *apiBackOff) ready() bool {
+	return !time.Now().Before(b.retryAt)
+}
+
+// failed records a failed attempt and returns how long to wait before the next one
+func (b *apiBackOff) failed() time.Duration {
+	wait := b.backoff.NextBackOff()
+	b.retryAt = time.Now().Add(wait)
+	return wait
+}
+
+// succeeded resets the backoff so the next failure is retried quickly
+func (b *apiBackOff) succeeded() {
+	b.backoff.Reset()
+	b.retryAt = time.Time{}
+}
+
+// next returns the jittered wait until the next poll of the api server
+func (b *apiBackOff) next(interval time.Duration) time.Duration {
+	if wait := time.Until(b.retryAt); wait > 0 {
+		return wait
+	}
+	return util.Jitter(interval)
+}
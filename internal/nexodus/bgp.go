@@ -0,0 +1,115 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/nexodus-io/nexodus/internal/bgp"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// startBGP starts the BGP session with the router of the site. The prefixes announced by the router are advertised
+// by the device, and the prefixes of the VPC and of the other devices are announced to the router.
+func (nx *Nexodus) startBGP(ctx context.Context, wg *sync.WaitGroup) error {
+	peer, err := bgp.ParsePeer(nx.bgpPeer)
+	if err != nil {
+		return err
+	}
+	routerID := nx.bgpRouterID
+	if routerID == "" {
+		routerID = nx.endpointLocalAddress
+	}
+	id, err := netip.ParseAddr(routerID)
+	if err != nil {
+		return fmt.Errorf("invalid BGP router id %s: %w", routerID, err)
+	}
+	nx.bgp, err = bgp.NewSpeaker(bgp.Config{
+		LocalAS:  nx.bgpLocalAS,
+		PeerAS:   nx.bgpPeerAS,
+		RouterID: id,
+		Peer:     peer,
+	}, nx.logger)
+	if err != nil {
+		return fmt.Errorf("invalid BGP configuration: %w", err)
+	}
+	// the traffic between the site and the overlay is routed through this node
+	if err := nx.enableForwardingIP(); err != nil {
+		return fmt.Errorf("failed to enable ip forwarding for BGP: %w", err)
+	}
+	nx.exportBGPRoutes()
+	util.GoWithWaitGroup(wg, func() {
+		nx.bgp.Run(ctx)
+	})
+	return nil
+}
+
+// bgpChanged is signaled when the prefixes imported from the BGP peer changed, it never is without a BGP peer
+func (nx *Nexodus) bgpChanged() <-chan struct{} {
+	if nx.bgp == nil {
+		return nil
+	}
+	return nx.bgp.Changed()
+}
+
+// exportBGPRoutes announces the prefix of the VPC and the prefixes advertised by the other devices to the BGP peer,
+// the prefixes learned by the device, including the ones imported from the peer, are not announced to it
+func (nx *Nexodus) exportBGPRoutes() {
+	var prefixes []netip.Prefix
+	add := func(cidr string) {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil || prefix.Bits() == 0 || slices.Contains(nx.learnedCidrs, cidr) {
+			return
+		}
+		// the gateways of a site may advertise the same prefix
+		if prefix = prefix.Masked(); !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if nx.vpc != nil {
+		add(nx.vpc.Ipv4Cidr)
+		add(nx.vpc.Ipv6Cidr)
+	}
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey {
+			return
+		}
+		for _, cidr := range d.device.AdvertiseCidrs {
+			add(cidr)
+		}
+	})
+	nx.bgpExported = prefixes
+	nx.bgp.SetExported(prefixes)
+}
+
+// bgpImportedCidrs returns the prefixes announced by the BGP peer that the device advertises
+func (nx *Nexodus) bgpImportedCidrs() []string {
+	var imported []string
+	for _, prefix := range nx.bgp.Imported() {
+		if nx.importBGPPrefix(prefix) {
+			imported = append(imported, prefix.String())
+		}
+	}
+	return imported
+}
+
+// importBGPPrefix is false for the default route, which is advertised by exit nodes instead, for the prefixes of
+// the overlay and for the prefixes announced to the peer
+func (nx *Nexodus) importBGPPrefix(prefix netip.Prefix) bool {
+	if prefix.Bits() == 0 || slices.Contains(nx.bgpExported, prefix) {
+		return false
+	}
+	if nx.vpc != nil {
+		for _, cidr := range []string{nx.vpc.Ipv4Cidr, nx.vpc.Ipv6Cidr} {
+			if vpcPrefix, err := netip.ParsePrefix(cidr); err == nil && vpcPrefix.Overlaps(prefix) {
+				return false
+			}
+		}
+	}
+	if nx.podIPAM != nil && nx.podIPAM.prefix.Overlaps(prefix) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package nexodus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const captivePortalProbeTimeout = 5 * time.Second
+
+// captivePortalProbeURL answers with an empty 204 response on an open network. Captive portals
+// intercept the plain http request and redirect it to, or answer it with, their sign in page.
+var captivePortalProbeURL = "http://connectivitycheck.gstatic.com/generate_204"
+
+// probeCaptivePortal requests the probe url and reports whether the response was intercepted,
+// along with the location the portal redirected to when it is known.
+func probeCaptivePortal(ctx context.Context, probeURL string) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, captivePortalProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return false, "", err
+	}
+	httpClient := &http.Client{
+		// the redirect itself is the evidence of a portal, don't follow it
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// no response at all means the network is down rather than intercepted
+		return false, "", err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, "", nil
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return true, resp.Header.Get("Location"), nil
+	default:
+		// a portal that serves its sign in page in place of the probe response
+		return true, "", nil
+	}
+}
+
+// checkCaptivePortal probes for a captive portal after a request to the nexodus service failed
+// without a response from the service, which is how hotel and airport networks typically show up.
+// If a portal is detected the status is updated so that nexctl status explains what is going on.
+func (nx *Nexodus) checkCaptivePortal(ctx context.Context, err error) bool {
+	var apiErr *public.GenericOpenAPIError
+	if err == nil || errors.As(err, &apiErr) || isUpgradeRequired(err) {
+		return false
+	}
+	detected, location, probeErr := probeCaptivePortal(ctx, captivePortalProbeURL)
+	if probeErr != nil {
+		nx.logger.Debugf("captive portal probe failed: %v", probeErr)
+	}
+	if !detected {
+		return false
+	}
+
+	msg := "captive portal detected, open a browser to sign in to the network"
+	if location != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, location)
+	}
+	nx.logger.Warn(msg)
+	nx.SetStatus(NexdStatusCaptivePortal, msg+"\n")
+	return true
+}
+
+// clearCaptivePortal restores the running status once the nexodus service is reachable again
+func (nx *Nexodus) clearCaptivePortal() {
+	if nx.status == NexdStatusCaptivePortal {
+		nx.logger.Info("Captive portal cleared, the nexodus service is reachable")
+		nx.SetStatus(NexdStatusRunning, "")
+	}
+}
@@ -0,0 +1,52 @@
+package nexodus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeCaptivePortal(t *testing.T) {
+	tests := []struct {
+		name             string
+		handler          http.HandlerFunc
+		expectedDetected bool
+		expectedLocation string
+	}{
+		{
+			name: "open network",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name: "redirect to the sign in page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "http://portal.example.com/login", http.StatusFound)
+			},
+			expectedDetected: true,
+			expectedLocation: "http://portal.example.com/login",
+		},
+		{
+			name: "sign in page served in place of the probe",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("<html>Welcome to the hotel wifi</html>"))
+			},
+			expectedDetected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			detected, location, err := probeCaptivePortal(context.Background(), server.URL)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedDetected, detected)
+			require.Equal(t, tt.expectedLocation, location)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package nexodus
+
+import (
+	"context"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// how often the reachability of the peers is reported to the api-server
+const connectivityReportInterval = time.Minute
+
+// runConnectivityReporting periodically reports the reachability of the peers, so that nexctl connectivity
+// can show the connectivity matrix of the organization.
+func (nx *Nexodus) runConnectivityReporting(ctx context.Context) {
+	timer := time.NewTimer(util.Jitter(connectivityReportInterval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := nx.updateConnectivityReport(ctx); err != nil {
+				nx.logger.Debugf("failed to report the connectivity of the peers: %v", err)
+			}
+			timer.Reset(util.Jitter(connectivityReportInterval))
+		}
+	}
+}
+
+// updateConnectivityReport reports the results of the last probes of the peers that were probed, it replaces the
+// previous report of the device
+func (nx *Nexodus) updateConnectivityReport(ctx context.Context) error {
+	if nx.deviceId == "" {
+		return nil
+	}
+	report := public.ModelsReportConnectivity{
+		Peers: []public.ModelsReportPeerConnectivity{},
+	}
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || d.reachability == "" {
+			return
+		}
+		stats := aggregateProbes(d.probeHistory)
+		report.Peers = append(report.Peers, public.ModelsReportPeerConnectivity{
+			PeerId:       d.device.Id,
+			Reachability: d.reachability,
+			Method:       d.peeringMethod,
+			LatencyAvgMs: float32(stats.latencyAvg.Microseconds()) / 1000,
+			LatencyMaxMs: float32(stats.latencyMax.Microseconds()) / 1000,
+			Loss:         float32(stats.loss),
+		})
+	})
+	_, _, err := nx.client.DevicesApi.ReportDeviceConnectivity(ctx, nx.deviceId).Report(report).Execute()
+	return err
+}
@@ -61,7 +61,7 @@ func (nx *Nexodus) connectivityProbe(family string) api.PingPeersResponse {
 			var nodeAddr string
 			pubKey := value.device.PublicKey
 			if family == v6 {
-				nodeAddr = value.device.Ipv6TunnelIps[0].Address
+				nodeAddr = tunnelIpV6(value.device)
 			} else {
 				nodeAddr = value.device.Ipv4TunnelIps[0].Address
 			}
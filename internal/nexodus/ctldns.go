@@ -0,0 +1,45 @@
+package nexodus
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DnsList lists the names published in the overlay DNS zone of the organization
+func (ac *NexdCtl) DnsList(_ string, result *string) error {
+	if ac.nx.overlayDNS == nil {
+		return fmt.Errorf("the overlay DNS resolver is not enabled, start nexd with --dns-listen-address")
+	}
+
+	namesJSON, err := json.Marshal(ac.nx.overlayDNS.names())
+	if err != nil {
+		return fmt.Errorf("error marshalling dns names: %w", err)
+	}
+
+	*result = string(namesJSON)
+	return nil
+}
+
+// DnsLookup resolves a device name in the overlay DNS zone of the organization
+func (ac *NexdCtl) DnsLookup(name string, result *string) error {
+	if ac.nx.overlayDNS == nil {
+		return fmt.Errorf("the overlay DNS resolver is not enabled, start nexd with --dns-listen-address")
+	}
+
+	ips := ac.nx.overlayDNS.lookup(name)
+	if len(ips) == 0 {
+		return fmt.Errorf("%s not found in the overlay DNS zone %s", name, ac.nx.overlayDNS.zone)
+	}
+
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	addrsJSON, err := json.Marshal(addrs)
+	if err != nil {
+		return fmt.Errorf("error marshalling dns lookup results: %w", err)
+	}
+
+	*result = string(addrsJSON)
+	return nil
+}
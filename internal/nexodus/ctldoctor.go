@@ -0,0 +1,283 @@
+package nexodus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api"
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+const (
+	// how long the doctor waits for the api-server
+	doctorAPITimeout = 10 * time.Second
+	// the number of peers the path MTU is probed with
+	doctorMTUPeers = 3
+	// the MTU of the tunnel when it can not be read from the interface
+	defaultTunnelMTU = 1420
+	// the size of the IPv4 header of the MTU probes
+	ipv4HeaderSize = 20
+)
+
+// Doctor runs the connectivity checks of nexctl nexd doctor
+func (ac *NexdCtl) Doctor(_ string, result *string) error {
+	findings := ac.nx.runDoctor()
+	findingsJSON, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("error marshalling the doctor findings: %w", err)
+	}
+	*result = string(findingsJSON)
+	return nil
+}
+
+// runDoctor checks each link of the connectivity of the device, from the api-server to the peers, and returns
+// what it found with a hint of what to do about each problem
+func (nx *Nexodus) runDoctor() []api.DoctorFinding {
+	var findings []api.DoctorFinding
+	findings = append(findings, nx.doctorAPI())
+	findings = append(findings, nx.doctorStun())
+	findings = append(findings, nx.doctorRelay())
+	findings = append(findings, nx.doctorHandshakes()...)
+	findings = append(findings, nx.doctorMTU())
+	findings = append(findings, nx.doctorFirewall())
+	return findings
+}
+
+func (nx *Nexodus) doctorAPI() api.DoctorFinding {
+	finding := api.DoctorFinding{Check: "api"}
+	if nx.client == nil {
+		finding.Status = api.DoctorFail
+		finding.Message = "nexd has not connected to the Nexodus Service yet"
+		finding.Hint = "Check the nexd status with nexctl nexd status, nexd may be waiting for the login to complete"
+		return finding
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorAPITimeout)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := nx.client.UsersApi.GetUser(ctx, "me").Execute(); err != nil {
+		finding.Status = api.DoctorFail
+		finding.Message = fmt.Sprintf("The Nexodus Service at %s is unreachable: %v", nx.apiURL, err)
+		finding.Hint = "Check the DNS resolution of the service host and that HTTPS to it is allowed, including through any proxy. " +
+			"The tunnels keep working, but the peer changes are not received until nexd reconnects"
+		return finding
+	}
+	finding.Status = api.DoctorOK
+	finding.Message = fmt.Sprintf("The Nexodus Service at %s answered in %s", nx.apiURL, time.Since(start).Round(time.Millisecond))
+	return finding
+}
+
+func (nx *Nexodus) doctorStun() api.DoctorFinding {
+	finding := api.DoctorFinding{Check: "stun"}
+	reflexive, server, err := stun.RequestWithFallback(nx.logger, nx.listenPort)
+	if err != nil {
+		finding.Status = api.DoctorFail
+		finding.Message = fmt.Sprintf("None of the STUN servers answered: %v", err)
+		finding.Hint = "Outbound UDP appears to be blocked on this network, allow UDP to the STUN servers and to the WireGuard port of the peers. " +
+			"Until then the peers are only reached through the DERP relay over TCP port 443"
+		return finding
+	}
+	finding.Message = fmt.Sprintf("The reflexive address of UDP port %d is %s, as seen by %s", nx.listenPort, reflexive, server)
+	if nx.symmetricNat && !nx.relay {
+		finding.Status = api.DoctorWarn
+		finding.Message += ", this device is behind a symmetric NAT or runs with --relay-only"
+		finding.Hint = "The peers are reached through a relay, forward the WireGuard port on the NAT or enable --port-mapping to peer directly"
+		return finding
+	}
+	finding.Status = api.DoctorOK
+	return finding
+}
+
+func (nx *Nexodus) doctorRelay() api.DoctorFinding {
+	finding := api.DoctorFinding{Check: "relay"}
+	if nx.relay {
+		finding.Status = api.DoctorSkip
+		finding.Message = "This device is a relay"
+		return finding
+	}
+
+	nx.deviceCacheLock.RLock()
+	relay, found := nx.selectRelay()
+	nx.deviceCacheLock.RUnlock()
+	if !found {
+		if nx.symmetricNat {
+			finding.Status = api.DoctorFail
+			finding.Message = "A relay is required but the vpc has no relay, only the devices on the same local network are reachable"
+			finding.Hint = "Start a relay in the vpc, see https://docs.nexodus.io/user-guide/relay-nodes/"
+			return finding
+		}
+		finding.Status = api.DoctorSkip
+		finding.Message = "The vpc has no relay, this device does not require one"
+		return finding
+	}
+
+	name := relay.device.Hostname
+	if !relay.peerHealthy {
+		finding.Status = api.DoctorWarn
+		if nx.symmetricNat {
+			finding.Status = api.DoctorFail
+		}
+		finding.Message = fmt.Sprintf("There is no WireGuard session with the relay %s", name)
+		finding.Hint = "Check that UDP to the endpoint of the relay is allowed and that the relay is online with nexctl relay list"
+		return finding
+	}
+	if len(relay.device.Ipv4TunnelIps) == 0 {
+		finding.Status = api.DoctorOK
+		finding.Message = fmt.Sprintf("The WireGuard session with the relay %s is up", name)
+		return finding
+	}
+	latency, err := nx.doPing(relay.device.Ipv4TunnelIps[0].Address, uint64(time.Now().UnixNano()), reachabilityProbeTimeout)
+	if err != nil {
+		finding.Status = api.DoctorWarn
+		finding.Message = fmt.Sprintf("The WireGuard session with the relay %s is up, but it does not answer probes: %v", name, err)
+		finding.Hint = "A security group of the relay may block ICMP, check its rules"
+		return finding
+	}
+	finding.Status = api.DoctorOK
+	finding.Message = fmt.Sprintf("The relay %s answers in %s", name, latency)
+	return finding
+}
+
+// doctorHandshakes returns a finding for each peer without a WireGuard session, or one finding when all the
+// sessions are up. The peers reached through a relay are covered by the relay check.
+func (nx *Nexodus) doctorHandshakes() []api.DoctorFinding {
+	var findings []api.DoctorFinding
+	healthy := 0
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || d.device.Relay || strings.HasPrefix(d.peeringMethod, "via-") {
+			return
+		}
+		if d.peerHealthy {
+			healthy++
+			return
+		}
+		finding := api.DoctorFinding{
+			Check:  "handshake",
+			Status: api.DoctorFail,
+			Hint: fmt.Sprintf("Check that the firewalls of both devices allow UDP between their endpoints, the last endpoints tried were [ %s ]",
+				strings.Join(peerEndpointAddresses(d), ", ")),
+		}
+		if d.lastHandshakeTime.IsZero() {
+			finding.Message = fmt.Sprintf("There has been no WireGuard handshake with %s (peering method %s)", d.device.Hostname, d.peeringMethod)
+		} else {
+			finding.Message = fmt.Sprintf("The last WireGuard handshake with %s (peering method %s) was %s ago",
+				d.device.Hostname, d.peeringMethod, time.Since(d.lastHandshakeTime).Round(time.Second))
+		}
+		findings = append(findings, finding)
+	})
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Message < findings[j].Message
+	})
+	if len(findings) == 0 {
+		findings = append(findings, api.DoctorFinding{
+			Check:   "handshake",
+			Status:  api.DoctorOK,
+			Message: fmt.Sprintf("The WireGuard sessions with the %d directly peered peers are up", healthy),
+		})
+	}
+	return findings
+}
+
+// peerEndpointAddresses returns the endpoint addresses of a peer device
+func peerEndpointAddresses(d deviceCacheEntry) []string {
+	var addresses []string
+	for _, endpoint := range d.device.Endpoints {
+		addresses = append(addresses, endpoint.Address)
+	}
+	return addresses
+}
+
+// doctorMTU probes a few healthy peers with packets of the size of the tunnel MTU. A peer that answers small
+// probes but not full size ones is behind a path whose MTU is lower than the tunnel MTU plus the WireGuard overhead.
+func (nx *Nexodus) doctorMTU() api.DoctorFinding {
+	finding := api.DoctorFinding{Check: "mtu"}
+	var peers []deviceCacheEntry
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || !d.peerHealthy || len(d.device.Ipv4TunnelIps) == 0 {
+			return
+		}
+		peers = append(peers, d)
+	})
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].device.Hostname < peers[j].device.Hostname
+	})
+	if len(peers) > doctorMTUPeers {
+		peers = peers[:doctorMTUPeers]
+	}
+
+	mtu := nx.tunnelMTU()
+	probed := 0
+	var lost []string
+	for _, d := range peers {
+		addr := d.device.Ipv4TunnelIps[0].Address
+		if _, err := nx.doPing(addr, uint64(time.Now().UnixNano()), reachabilityProbeTimeout); err != nil {
+			// the peer does not answer probes at all, it tells nothing about the MTU
+			continue
+		}
+		probed++
+		if _, err := nx.doPingSize(addr, uint64(time.Now().UnixNano()), reachabilityProbeTimeout, mtu-ipv4HeaderSize); err != nil {
+			lost = append(lost, d.device.Hostname)
+		}
+	}
+	switch {
+	case probed == 0:
+		finding.Status = api.DoctorSkip
+		finding.Message = "There is no peer answering probes to probe the path MTU with"
+	case len(lost) > 0:
+		finding.Status = api.DoctorWarn
+		finding.Message = fmt.Sprintf("Packets of %d bytes to %s are lost while smaller packets arrive", mtu, strings.Join(lost, ", "))
+		finding.Hint = fmt.Sprintf("The path MTU is lower than the MTU of the %s interface plus the WireGuard overhead, "+
+			"lower the MTU of the interface, for example to 1280", nx.tunnelIface)
+	default:
+		finding.Status = api.DoctorOK
+		finding.Message = fmt.Sprintf("Packets of %d bytes, the MTU of the tunnel, reach %d peers", mtu, probed)
+	}
+	return finding
+}
+
+// tunnelMTU returns the MTU of the tunnel interface
+func (nx *Nexodus) tunnelMTU() int {
+	if nx.userspaceMode {
+		return defaultTunnelMTU
+	}
+	iface, err := net.InterfaceByName(nx.tunnelIface)
+	if err != nil || iface.MTU == 0 {
+		return defaultTunnelMTU
+	}
+	return iface.MTU
+}
+
+func (nx *Nexodus) doctorFirewall() api.DoctorFinding {
+	finding := api.DoctorFinding{Check: "firewall"}
+	group := nx.securityGroup
+	if group == nil || (len(group.InboundRules) == 0 && len(group.OutboundRules) == 0) {
+		finding.Status = api.DoctorSkip
+		finding.Message = "No security group rules apply to this device, the traffic of the peers is not filtered"
+		return finding
+	}
+	if nx.userspaceMode {
+		finding.Status = api.DoctorOK
+		finding.Message = "The security group rules are applied by the userspace tunnel"
+		return finding
+	}
+	present, err := nx.securityRulesPresent()
+	if err != nil {
+		finding.Status = api.DoctorFail
+		finding.Message = fmt.Sprintf("The firewall rules of the security groups could not be checked: %v", err)
+		finding.Hint = "Check that the firewall tool of the host, nft on Linux or pfctl on macOS, is installed and that nexd runs as root"
+		return finding
+	}
+	if !present {
+		finding.Status = api.DoctorFail
+		finding.Message = "The firewall rules of the security groups of this device are missing"
+		finding.Hint = "Another tool may have flushed the firewall rules, restart nexd to apply them again and check the nexd logs for errors"
+		return finding
+	}
+	finding.Status = api.DoctorOK
+	finding.Message = "The firewall rules of the security groups of this device are installed"
+	return finding
+}
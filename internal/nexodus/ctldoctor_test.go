@@ -0,0 +1,51 @@
+package nexodus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api"
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestDoctorHandshakes(t *testing.T) {
+	require := require.New(t)
+	peer := func(id string, method string, healthy bool) deviceCacheEntry {
+		d := deviceCacheEntry{
+			device: public.ModelsDevice{Id: id, PublicKey: id, Hostname: id, Endpoints: []public.ModelsEndpoint{
+				{Address: "192.0.2.10:51820", Source: "local"},
+			}},
+			peeringMethod: method,
+		}
+		d.peerHealthy = healthy
+		return d
+	}
+	nx := &Nexodus{
+		wireguardPubKey: "self",
+		deviceCache: map[string]deviceCacheEntry{
+			"self": {device: public.ModelsDevice{Id: "self", PublicKey: "self"}},
+			"a":    peer("a", peeringMethodReflexive, true),
+			// the peers reached through the relay are covered by the relay check
+			"b": peer("b", peeringMethodViaRelay, false),
+		},
+	}
+
+	findings := nx.doctorHandshakes()
+	require.Len(findings, 1)
+	require.Equal(api.DoctorOK, findings[0].Status)
+
+	nx.deviceCache["c"] = peer("c", peeringMethodDirectLocal, false)
+	stale := peer("d", peeringMethodReflexive, false)
+	stale.lastHandshakeTime = time.Now().Add(-5 * time.Minute)
+	nx.deviceCache["d"] = stale
+	findings = nx.doctorHandshakes()
+	require.Len(findings, 2)
+	for _, finding := range findings {
+		require.Equal(api.DoctorFail, finding.Status)
+		require.Contains(finding.Hint, "192.0.2.10:51820")
+	}
+	require.Contains(findings[0].Message, "last WireGuard handshake with d")
+	require.Contains(findings[1].Message, "no WireGuard handshake with c")
+}
@@ -19,7 +19,7 @@ func (ac *NexdCtl) EnableExitNodeClient(_ string, result *string) error {
 }
 
 func (ac *NexdCtl) DisableExitNodeClient(_ string, result *string) error {
-	err := ac.nx.exitNodeClientTeardown()
+	err := ac.nx.exitNodeClientDisable()
 
 	disableExitNodeClientJson, err := json.Marshal(err)
 	if err != nil {
@@ -31,37 +31,36 @@ func (ac *NexdCtl) DisableExitNodeClient(_ string, result *string) error {
 	return nil
 }
 
+// UseExitNode routes the traffic of this device through the exit node with the hostname, device id or public key
+func (ac *NexdCtl) UseExitNode(name string, result *string) error {
+	origin, err := ac.nx.UseExitNode(name)
+	if err != nil {
+		return err
+	}
+	*result = fmt.Sprintf("Now using the exit node %s\n", origin.Hostname)
+	return nil
+}
+
 // ListExitNodes lists all exit node origins
 func (ac *NexdCtl) ListExitNodes(_ string, result *string) error {
-	var allExitNodeOrigins []wgPeerConfig
-	isExitNode := false
+	ac.nx.deviceCacheLock.RLock()
+	exitNodeOrigins := ac.nx.exitNodeOrigins()
+	ac.nx.deviceCacheLock.RUnlock()
 
-	// Check if the local node is an exit node
+	// Append the local node if it is an exit node
 	for _, prefix := range ac.nx.advertiseCidrs {
 		if prefix == "0.0.0.0/0" {
-			isExitNode = true
+			exitNodeOrigins = append(exitNodeOrigins, ExitNodeOrigin{
+				Hostname:  ac.nx.hostname,
+				PublicKey: ac.nx.wireguardPubKey,
+				Endpoint:  ac.nx.nodeReflexiveAddressIPv4.String(),
+				Local:     true,
+			})
 			break
 		}
 	}
 
-	// If the local node is an exit node, create a copy and append the new instance
-	if isExitNode {
-		// Make a copy of exitNodeOrigins to a new slice
-		allExitNodeOrigins = make([]wgPeerConfig, len(ac.nx.exitNode.exitNodeOrigins))
-		copy(allExitNodeOrigins, ac.nx.exitNode.exitNodeOrigins)
-
-		// Create a new instance of wgPeerConfig
-		newPeerConfig := wgPeerConfig{
-			PublicKey: ac.nx.wireguardPubKey,
-			Endpoint:  ac.nx.nodeReflexiveAddressIPv4.String(),
-		}
-		// Append the local node if it is an exit node
-		allExitNodeOrigins = append(allExitNodeOrigins, newPeerConfig)
-	} else {
-		allExitNodeOrigins = ac.nx.exitNode.exitNodeOrigins
-	}
-
-	exitNodeOriginsJSON, err := json.Marshal(allExitNodeOrigins)
+	exitNodeOriginsJSON, err := json.Marshal(exitNodeOrigins)
 	if err != nil {
 		return fmt.Errorf("error marshalling exit node list results: %w", err)
 	}
@@ -70,3 +69,32 @@ func (ac *NexdCtl) ListExitNodes(_ string, result *string) error {
 
 	return nil
 }
+
+// ExitNodeExceptAdd keeps the traffic to the CIDR, IP address or domain on the local default route when an exit node is in use
+func (ac *NexdCtl) ExitNodeExceptAdd(except string, result *string) error {
+	except, err := ac.nx.ExitNodeExceptAdd(except)
+	if err != nil {
+		return err
+	}
+	*result = fmt.Sprintf("Added exit node exception: %s\n", except)
+	return nil
+}
+
+// ExitNodeExceptRemove removes an exit node exception
+func (ac *NexdCtl) ExitNodeExceptRemove(except string, result *string) error {
+	except, err := ac.nx.ExitNodeExceptRemove(except)
+	if err != nil {
+		return err
+	}
+	*result = fmt.Sprintf("Removed exit node exception: %s\n", except)
+	return nil
+}
+
+// ExitNodeExceptList lists the exit node exceptions
+func (ac *NexdCtl) ExitNodeExceptList(_ string, result *string) error {
+	*result = ""
+	for _, except := range ac.nx.exitNodeExcepts() {
+		*result += fmt.Sprintf("%s\n", except)
+	}
+	return nil
+}
@@ -8,13 +8,31 @@ import (
 type ListPeersResponse struct {
 	RelayPresent  bool                  `json:"relay-present"`
 	RelayRequired bool                  `json:"relay-required"`
+	Relay         string                `json:"relay,omitempty"` // hostname of the relay in use
 	Peers         map[string]WgSessions `json:"peers"`
 }
 
 func (ac *NexdCtl) ListPeers(_ string, result *string) error {
+	response, err := ac.listPeers()
+	if err != nil {
+		return err
+	}
+
+	peersJSON, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error marshalling list of peers: %w", err)
+	}
+
+	*result = string(peersJSON)
+
+	return nil
+}
+
+// listPeers combines the wireguard session stats of each peer with what nexd knows about the peer device
+func (ac *NexdCtl) listPeers() (ListPeersResponse, error) {
 	peers, err := ac.nx.DumpPeersDefault()
 	if err != nil {
-		return fmt.Errorf("error getting list of peers: %w", err)
+		return ListPeersResponse{}, fmt.Errorf("error getting list of peers: %w", err)
 	}
 	response := ListPeersResponse{
 		Peers:         peers,
@@ -29,18 +47,21 @@ func (ac *NexdCtl) ListPeers(_ string, result *string) error {
 			return
 		}
 		p.Healthy = d.peerHealthy
+		p.Reachability = d.reachability
+		p.ProbeLatency = d.probeLatency
+		stats := aggregateProbes(d.probeHistory)
+		p.LatencyAvg = stats.latencyAvg
+		p.LatencyMax = stats.latencyMax
+		p.ProbeLoss = stats.loss
+		p.RxRate, p.TxRate = ac.nx.peerTrafficRates(d.device.PublicKey)
+		p.Hostname = d.device.Hostname
+		p.PeeringMethod = d.peeringMethod
+		p.RelayReason = ac.nx.relayReason(d.device)
 		response.Peers[d.device.PublicKey] = p
-		if d.peerHealthy && d.device.Relay {
+		if d.peerHealthy && d.device.PublicKey == ac.nx.relayPublicKey {
 			response.RelayPresent = true
+			response.Relay = d.device.Hostname
 		}
 	})
-
-	peersJSON, err := json.Marshal(response)
-	if err != nil {
-		return fmt.Errorf("error marshalling list of peers: %w", err)
-	}
-
-	*result = string(peersJSON)
-
-	return nil
+	return response, nil
 }
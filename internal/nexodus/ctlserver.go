@@ -1,9 +1,11 @@
 package nexodus
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
-	"github.com/bytedance/gopkg/util/logger"
+	"github.com/nexodus-io/nexodus/internal/api/public"
 
 	"go.uber.org/zap"
 )
@@ -12,26 +14,92 @@ type NexdCtl struct {
 	nx *Nexodus
 }
 
-func (ac *NexdCtl) Status(_ string, result *string) error {
-	var statusStr string
-	switch ac.nx.status {
+func statusString(status int) string {
+	switch status {
 	case NexdStatusStarting:
-		statusStr = "Starting"
+		return "Starting"
 	case NexdStatusAuth:
-		statusStr = "WaitingForAuth"
+		return "WaitingForAuth"
 	case NexdStatusRunning:
-		statusStr = "Running"
+		return "Running"
+	case NexdStatusUpgradeRequired:
+		return "UpgradeRequired"
+	case NexdStatusCaptivePortal:
+		return "CaptivePortal"
 	default:
-		statusStr = "Unknown"
+		return "Unknown"
 	}
-	res := fmt.Sprintf("Status: %s\n", statusStr)
+}
+
+func (ac *NexdCtl) Status(_ string, result *string) error {
+	res := fmt.Sprintf("Status: %s\n", statusString(ac.nx.status))
 	if len(ac.nx.statusMsg) > 0 {
 		res += ac.nx.statusMsg
 	}
+	if exitNode := ac.nx.activeExitNode(); exitNode != "" {
+		res += fmt.Sprintf("Exit Node: %s\n", exitNode)
+	}
+	res += ac.nx.exitNodeFailoverStatus()
+	res += ac.nx.udpBlockedStatus()
+	res += ac.nx.natStatus()
+	if ac.nx.status == NexdStatusRunning {
+		res += ac.nx.peerReachabilityStatus()
+	}
 	*result = res
 	return nil
 }
 
+type StatusResponse struct {
+	Status           string                      `json:"status"`
+	StatusMessage    string                      `json:"status-message,omitempty"`
+	Version          string                      `json:"version"`
+	Hostname         string                      `json:"hostname"`
+	TunnelIPv4       string                      `json:"tunnel-ipv4,omitempty"`
+	TunnelIPv6       string                      `json:"tunnel-ipv6,omitempty"`
+	Relay            string                      `json:"relay,omitempty"`              // hostname of the relay in use
+	ExitNode         string                      `json:"exit-node,omitempty"`          // hostname of the exit node in use
+	ExitNodeFailover *ExitNodeFailover           `json:"exit-node-failover,omitempty"` // the last exit node failover
+	UDPBlocked       bool                        `json:"udp-blocked,omitempty"`        // the relay is reached over TCP/443
+	NatMapping       string                      `json:"nat-mapping,omitempty"`        // the RFC 5780 mapping behavior of the NAT in front of the device
+	NatHairpin       bool                        `json:"nat-hairpin,omitempty"`        // the NAT supports hairpinning
+	SecurityGroup    *public.ModelsSecurityGroup `json:"security-group,omitempty"`
+	Peers            map[string]WgSessions       `json:"peers"`
+}
+
+// StatusJSON returns the status of nexd along with the peers and the security group rules in effect
+func (ac *NexdCtl) StatusJSON(_ string, result *string) error {
+	response := StatusResponse{
+		Status:           statusString(ac.nx.status),
+		StatusMessage:    strings.TrimSpace(ac.nx.statusMsg),
+		Version:          ac.nx.version,
+		Hostname:         ac.nx.hostname,
+		TunnelIPv4:       ac.nx.TunnelIP,
+		TunnelIPv6:       ac.nx.TunnelIpV6,
+		ExitNode:         ac.nx.activeExitNode(),
+		ExitNodeFailover: ac.nx.lastExitNodeFailover(),
+		UDPBlocked:       ac.nx.udpBlocked,
+		NatMapping:       string(ac.nx.natBehavior.Mapping),
+		NatHairpin:       ac.nx.natBehavior.Hairpin,
+		SecurityGroup:    ac.nx.securityGroup,
+		Peers:            map[string]WgSessions{},
+	}
+	if ac.nx.status == NexdStatusRunning {
+		peers, err := ac.listPeers()
+		if err != nil {
+			return err
+		}
+		response.Relay = peers.Relay
+		response.Peers = peers.Peers
+	}
+
+	statusJSON, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error marshalling the status: %w", err)
+	}
+	*result = string(statusJSON)
+	return nil
+}
+
 func (ac *NexdCtl) Version(_ string, result *string) error {
 	*result = ac.nx.version
 	return nil
@@ -62,10 +130,12 @@ func (ac *NexdCtl) ProxyList(_ string, result *string) error {
 }
 
 func (ac *NexdCtl) proxyAdd(proxyType ProxyType, rule string, result *string) error {
-
+	if !ac.nx.userspaceMode {
+		return fmt.Errorf("proxy rules are only supported by nexd proxy and nexd container")
+	}
 	proxyRule, err := ParseProxyRule(rule, proxyType)
 	if err != nil {
-		logger.Fatal(fmt.Sprintf("Failed to parse %s proxy rule (%s): %v", proxyType, rule, err))
+		return fmt.Errorf("failed to parse %s proxy rule (%s): %w", proxyType, rule, err)
 	}
 	proxyRule.stored = true
 
@@ -92,9 +162,12 @@ func (ac *NexdCtl) ProxyAddEgress(rule string, result *string) error {
 }
 
 func (ac *NexdCtl) proxyRemove(proxyType ProxyType, rule string, result *string) error {
+	if !ac.nx.userspaceMode {
+		return fmt.Errorf("proxy rules are only supported by nexd proxy and nexd container")
+	}
 	proxyRule, err := ParseProxyRule(rule, proxyType)
 	if err != nil {
-		logger.Fatal(fmt.Sprintf("Failed to parse %s proxy rule (%s): %v", proxyType, rule, err))
+		return fmt.Errorf("failed to parse %s proxy rule (%s): %w", proxyType, rule, err)
 	}
 	proxyRule.stored = true
 
@@ -107,7 +180,7 @@ func (ac *NexdCtl) proxyRemove(proxyType ProxyType, rule string, result *string)
 		return err
 	}
 
-	*result = fmt.Sprintf("Removed ingress proxy rule: %s\n", rule)
+	*result = fmt.Sprintf("Removed %s proxy rule: %s\n", proxyType, rule)
 	return nil
 }
 func (ac *NexdCtl) ProxyRemoveIngress(rule string, result *string) error {
@@ -138,3 +211,12 @@ func (ac *NexdCtl) GetDebug(_ string, result *string) error {
 	}
 	return nil
 }
+
+func (ac *NexdCtl) Reload(_ string, result *string) error {
+	msg, err := ac.nx.Reload()
+	if err != nil {
+		return err
+	}
+	*result = msg
+	return nil
+}
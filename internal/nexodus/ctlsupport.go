@@ -0,0 +1,127 @@
+package nexodus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const (
+	// how long each command of the support bundle may run
+	supportCommandTimeout = 10 * time.Second
+	// the number of the last log lines included in the support bundle
+	supportLogLines = 5000
+	redacted        = "REDACTED"
+)
+
+// supportCommand is a command whose output is a file of the support bundle
+type supportCommand struct {
+	file string
+	args []string
+}
+
+// jwtPattern matches the bearer tokens that may appear in the logs
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+
+// SupportBundle returns the files of the support bundle of nexctl nexd support-bundle keyed by file name, the
+// secrets nexd knows of are redacted from them
+func (ac *NexdCtl) SupportBundle(_ string, result *string) error {
+	files := ac.nx.supportBundle()
+	filesJSON, err := json.Marshal(files)
+	if err != nil {
+		return fmt.Errorf("error marshalling the support bundle: %w", err)
+	}
+	*result = string(filesJSON)
+	return nil
+}
+
+func (nx *Nexodus) supportBundle() map[string]string {
+	files := map[string]string{}
+	addJSON := func(file string, value interface{}) {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			data = []byte(fmt.Sprintf("error: %v", err))
+		}
+		files[file] = string(data)
+	}
+
+	ctl := &NexdCtl{nx: nx}
+	var status string
+	if err := ctl.StatusJSON("", &status); err != nil {
+		status = fmt.Sprintf("error: %v", err)
+	}
+	files["status.json"] = status
+	addJSON("flags.json", nx.resolvedFlags)
+	if sessions, err := nx.DumpPeersDefault(); err != nil {
+		files["wg-peers.json"] = fmt.Sprintf("error: %v", err)
+	} else {
+		addJSON("wg-peers.json", sessions)
+	}
+
+	// the state of the device and its peers as received from the api-server
+	var devices []public.ModelsDevice
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		device := d.device
+		device.BearerToken = ""
+		if device.PublicKey == nx.wireguardPubKey {
+			addJSON("device.json", device)
+			return
+		}
+		devices = append(devices, device)
+	})
+	addJSON("peers.json", devices)
+	addJSON("security-group.json", nx.securityGroup)
+	addJSON("doctor.json", nx.runDoctor())
+
+	for _, command := range supportBundleCommands(nx.tunnelIface) {
+		files[command.file] = runSupportCommand(command.args)
+	}
+	for file, path := range supportBundleLogFiles() {
+		files[file] = tailFile(path, supportLogLines)
+	}
+
+	for file, content := range files {
+		files[file] = nx.redact(content)
+	}
+	return files
+}
+
+// runSupportCommand returns the output of a command of the support bundle, with the error it failed with
+func runSupportCommand(args []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), supportCommandTimeout)
+	defer cancel()
+	// #nosec G204
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("$ %s\nerror: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return fmt.Sprintf("$ %s\n%s", strings.Join(args, " "), output)
+}
+
+// tailFile returns the last lines of a file
+func tailFile(path string, lines int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	all := strings.Split(string(data), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n")
+}
+
+// redact removes the private key of the device and the bearer tokens from the content of a file
+func (nx *Nexodus) redact(content string) string {
+	if nx.wireguardPvtKey != "" {
+		content = strings.ReplaceAll(content, nx.wireguardPvtKey, redacted)
+	}
+	return jwtPattern.ReplaceAllString(content, redacted)
+}
@@ -223,6 +223,7 @@ func (nr *nexRelay) derpWriteChanOfAddr(addr netip.AddrPort, peer key.NodePublic
 	})
 
 	dc.SetCanAckPings(true)
+	dc.SetWebsocketFallback(true)
 	dc.NotePreferred(nr.myDerp == regionID)
 	dc.SetAddressFamilySelector(derpAddrFamSelector{nr})
 	dc.DNSCache = dnscache.Get()
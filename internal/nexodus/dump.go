@@ -21,6 +21,23 @@ type WgSessions struct {
 	Rx                int64
 	// Only set when populating from the device cache, wgSessionsCached()
 	Healthy bool
+	// Result of the last in-tunnel probe of the peer, see the Peer* reachability constants
+	Reachability string `json:",omitempty"`
+	// Round trip time of the last successful probe
+	ProbeLatency time.Duration `json:",omitempty"`
+	// Average and maximum round trip time and the fraction of the probes lost, over the last probes
+	LatencyAvg time.Duration `json:",omitempty"`
+	LatencyMax time.Duration `json:",omitempty"`
+	ProbeLoss  float64       `json:",omitempty"`
+	// Bytes per second received from and sent to the peer, between the last two samples of the counters
+	RxRate float64 `json:",omitempty"`
+	TxRate float64 `json:",omitempty"`
+	// Hostname of the peer device, only set when populating from the device cache
+	Hostname string `json:",omitempty"`
+	// How the peering was established, see the peeringMethod* constants
+	PeeringMethod string `json:",omitempty"`
+	// Why the peer is reached through a relay, only set when populating from the device cache
+	RelayReason string `json:",omitempty"`
 }
 
 func (nx *Nexodus) DumpPeersDefault() (map[string]WgSessions, error) {
@@ -0,0 +1,135 @@
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const (
+	// the source of the endpoint candidates of the addresses of this device other than the local endpoint address
+	endpointSourceHost = "host"
+
+	// the priorities of the endpoint candidates, following the ICE type preferences, peers try the candidates
+	// with a higher priority first and fall back to the relays last
+	candidatePriorityLocal     = 126
+	candidatePriorityHost      = 110
+	candidatePriorityReflexive = 100
+)
+
+// the prefixes of the names of the virtual interfaces whose addresses are not reachable from other hosts
+var candidateIfaceSkipPrefixes = []string{"docker", "br-", "veth", "virbr", "cni", "flannel", "podman"}
+
+// hostCandidates discovers the IPv4 addresses of the interfaces of this device other than the local endpoint
+// address, which peers on the same networks may reach this device with, and the prefixes of the networks
+// attached to this device.
+func (nx *Nexodus) hostCandidates() ([]string, []netip.Prefix) {
+	var hosts []string
+	var prefixes []netip.Prefix
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		nx.logger.Debugf("failed to list the interfaces for the endpoint candidates: %v", err)
+		return nil, nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 || iface.Name == nx.tunnelIface || skipCandidateIface(iface.Name) {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip, ok := netip.AddrFromSlice(ipNet.IP)
+			if !ok || !ip.Unmap().Is4() || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			ip = ip.Unmap()
+			bits, _ := ipNet.Mask.Size()
+			prefixes = append(prefixes, netip.PrefixFrom(ip, bits).Masked())
+			if ip.String() != nx.endpointLocalAddress {
+				hosts = append(hosts, ip.String())
+			}
+		}
+	}
+	return hosts, prefixes
+}
+
+func skipCandidateIface(name string) bool {
+	for _, prefix := range candidateIfaceSkipPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// endpointCandidates returns the endpoint candidates published for this device in priority order
+func (nx *Nexodus) endpointCandidates(hosts []string, reflexiveIP netip.AddrPort, stunServer string, mapped netip.AddrPort) []public.ModelsEndpoint {
+	port := fmt.Sprintf("%d", nx.listenPort)
+	endpoints := []public.ModelsEndpoint{
+		{
+			Source:   "local",
+			Address:  net.JoinHostPort(nx.endpointLocalAddress, port),
+			Priority: candidatePriorityLocal,
+		},
+	}
+	for _, host := range hosts {
+		endpoints = append(endpoints, public.ModelsEndpoint{
+			Source:   endpointSourceHost,
+			Address:  net.JoinHostPort(host, port),
+			Priority: candidatePriorityHost,
+		})
+	}
+	// the reflexive endpoints go last, older agents use the last endpoint that is not local as the reflexive endpoint
+	endpoints = append(endpoints, public.ModelsEndpoint{
+		Source:   "stun:" + stunServer,
+		Address:  reflexiveIP.String(),
+		Priority: candidatePriorityReflexive,
+	})
+	if mapped.IsValid() {
+		endpoints = append(endpoints, public.ModelsEndpoint{
+			Source:   endpointSourcePortMap,
+			Address:  mapped.String(),
+			Priority: candidatePriorityPortMap,
+		})
+	}
+	return endpoints
+}
+
+// peerHostCandidates returns the host endpoint candidates of a peer on the networks attached to this device, in
+// priority order. Assumes deviceCacheLock is held.
+func (nx *Nexodus) peerHostCandidates(device public.ModelsDevice) []string {
+	var candidates []public.ModelsEndpoint
+	for _, endpoint := range device.Endpoints {
+		if endpoint.Source != endpointSourceHost {
+			continue
+		}
+		addrPort, err := netip.ParseAddrPort(endpoint.Address)
+		if err != nil {
+			continue
+		}
+		for _, prefix := range nx.localPrefixes {
+			if prefix.Contains(addrPort.Addr()) {
+				candidates = append(candidates, endpoint)
+				break
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+	addrs := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		addrs = append(addrs, c.Address)
+	}
+	return addrs
+}
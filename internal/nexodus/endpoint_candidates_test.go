@@ -0,0 +1,86 @@
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestEndpointCandidates(t *testing.T) {
+	require := require.New(t)
+	nx := &Nexodus{endpointLocalAddress: "192.168.1.10", listenPort: 51820}
+
+	endpoints := nx.endpointCandidates([]string{"10.0.0.10"}, netip.MustParseAddrPort("1.1.1.1:1234"), "stun.example.com:3478", netip.AddrPort{})
+	require.Equal([]public.ModelsEndpoint{
+		{Source: "local", Address: "192.168.1.10:51820", Priority: candidatePriorityLocal},
+		{Source: endpointSourceHost, Address: "10.0.0.10:51820", Priority: candidatePriorityHost},
+		{Source: "stun:stun.example.com:3478", Address: "1.1.1.1:1234", Priority: candidatePriorityReflexive},
+	}, endpoints)
+
+	// agents without candidate support take the last endpoint that is not local as the reflexive endpoint
+	localIP, reflexiveIP4 := nx.extractLocalAndReflexiveIP(public.ModelsDevice{Endpoints: endpoints})
+	require.Equal("192.168.1.10:51820", localIP)
+	require.Equal("1.1.1.1:1234", reflexiveIP4)
+
+	// the endpoint mapped on the local gateway is preferred to the stun reflexive address
+	endpoints = nx.endpointCandidates(nil, netip.MustParseAddrPort("1.1.1.1:1234"), "stun.example.com:3478", netip.MustParseAddrPort("1.1.1.1:51820"))
+	require.Len(endpoints, 3)
+	require.Equal(public.ModelsEndpoint{Source: endpointSourcePortMap, Address: "1.1.1.1:51820", Priority: candidatePriorityPortMap}, endpoints[2])
+	_, reflexiveIP4 = nx.extractLocalAndReflexiveIP(public.ModelsDevice{Endpoints: endpoints})
+	require.Equal("1.1.1.1:51820", reflexiveIP4)
+}
+
+func TestHostCandidatePeering(t *testing.T) {
+	require := require.New(t)
+	zLogger, _ := zap.NewDevelopment()
+	nx := &Nexodus{
+		vpc: &public.ModelsVPC{
+			Ipv4Cidr: "100.64.0.0/10",
+			Ipv6Cidr: "200::/64",
+		},
+		nodeReflexiveAddressIPv4: netip.MustParseAddrPort("1.1.1.1:1234"),
+		localPrefixes:            []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("172.16.0.0/16")},
+		logger:                   zLogger.Sugar(),
+	}
+	d := deviceCacheEntry{
+		device: public.ModelsDevice{
+			PublicKey:  "peer",
+			AllowedIps: []string{"100.64.0.2/32"},
+			Endpoints: []public.ModelsEndpoint{
+				{Source: "local", Address: "192.168.2.20:51820", Priority: candidatePriorityLocal},
+				{Source: endpointSourceHost, Address: "172.16.5.20:51820", Priority: candidatePriorityHost - 1},
+				{Source: endpointSourceHost, Address: "10.0.0.20:51820", Priority: candidatePriorityHost},
+				{Source: endpointSourceHost, Address: "10.9.9.20:51820", Priority: candidatePriorityHost},
+				{Source: "stun:stun.example.com:3478", Address: "2.2.2.2:4321", Priority: candidatePriorityReflexive},
+			},
+		},
+	}
+	nx.peeringReset(&d)
+
+	// the candidates off the networks of this device are skipped
+	require.Equal([]string{"10.0.0.20:51820", "172.16.5.20:51820"}, nx.peerHostCandidates(d.device))
+
+	// the host candidates are tried in priority order before the reflexive address
+	expected := []struct {
+		method   string
+		endpoint string
+	}{
+		{peeringMethodHostCandidate, "10.0.0.20:51820"},
+		{peeringMethodHostCandidate, "172.16.5.20:51820"},
+		{peeringMethodReflexive, "2.2.2.2:4321"},
+	}
+	for _, e := range expected {
+		peer, method, index := nx.rebuildPeerConfig(&d, false, false)
+		require.Equal(e.method, method)
+		require.Equal(e.endpoint, peer.Endpoint)
+		d.peeringMethod = method
+		d.peeringMethodIndex = index
+		// peering with this candidate times out
+		d.peeringTime = time.Now().Add(-peeringTimeout - time.Second)
+	}
+}
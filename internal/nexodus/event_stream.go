@@ -0,0 +1,99 @@
+package nexodus
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const (
+	// consecutive event stream failures before falling back to polling the list endpoints
+	maxEventStreamFailures = 3
+	// timeout of a list request made while polling
+	pollRequestTimeout = 30 * time.Second
+)
+
+// eventStream tracks the health of the vpc event stream shared by the informers
+type eventStream struct {
+	failures int
+	// the devices listed while polling, and the revision the next poll lists the changes from
+	polledDevices         map[string]public.ModelsDevice
+	polledDevicesRevision int32
+}
+
+// polling is true once the event stream has failed often enough that changes are polled for instead
+func (s *eventStream) polling() bool {
+	return s.failures >= maxEventStreamFailures
+}
+
+// executeOrPoll returns the items streamed by the informer. When the event stream keeps failing,
+// for example behind a proxy that does not support streaming responses, the items are listed
+// with a regular request instead. The informer is still tried first, so the agent switches back
+// to streaming as soon as the event stream recovers.
+func executeOrPoll[T any](nx *Nexodus, informer *public.Informer[T], poll func(ctx context.Context) (map[string]T, *http.Response, error)) (map[string]T, *http.Response, error) {
+	items, resp, err := informer.Execute()
+	if err == nil {
+		if nx.eventStream.polling() {
+			nx.logger.Info("The event stream has recovered, streaming changes from the api server")
+		}
+		nx.eventStream.failures = 0
+		return items, resp, nil
+	}
+
+	nx.eventStream.failures++
+	if !nx.eventStream.polling() {
+		return nil, resp, err
+	}
+	if nx.eventStream.failures == maxEventStreamFailures {
+		nx.logger.Warnf("The event stream failed %d times, polling the api server for changes: %v", maxEventStreamFailures, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pollRequestTimeout)
+	defer cancel()
+	return poll(ctx)
+}
+
+func (nx *Nexodus) listDevices() (map[string]public.ModelsDevice, *http.Response, error) {
+	return executeOrPoll(nx, nx.devicesInformer, nx.pollDevices)
+}
+
+// pollDevices applies the device changes made since the previous poll to the polled devices, so that
+// only the changes are downloaded on each poll rather than every device of the vpc.
+func (nx *Nexodus) pollDevices(ctx context.Context) (map[string]public.ModelsDevice, *http.Response, error) {
+	s := &nx.eventStream
+	changes, resp, err := nx.client.OrganizationsApi.ListDevicesInOrganization(ctx, nx.vpc.OrganizationId).
+		VpcId(nx.vpc.Id).
+		Since(s.polledDevicesRevision).
+		Execute()
+	if err != nil {
+		return nil, resp, err
+	}
+	if s.polledDevices == nil || s.polledDevicesRevision == 0 {
+		s.polledDevices = make(map[string]public.ModelsDevice, len(changes.Devices))
+	}
+	for _, device := range changes.Devices {
+		s.polledDevices[device.Id] = device
+	}
+	for _, id := range changes.Deleted {
+		delete(s.polledDevices, id)
+	}
+	s.polledDevicesRevision = changes.Revision
+	return maps.Clone(s.polledDevices), resp, nil
+}
+
+func (nx *Nexodus) listSecurityGroups() (map[string]public.ModelsSecurityGroup, *http.Response, error) {
+	return executeOrPoll(nx, nx.securityGroupsInformer, func(ctx context.Context) (map[string]public.ModelsSecurityGroup, *http.Response, error) {
+		listed, resp, err := nx.client.VPCApi.ListSecurityGroupsInVPC(ctx, nx.vpc.Id).Execute()
+		if err != nil {
+			return nil, resp, err
+		}
+		items := make(map[string]public.ModelsSecurityGroup, len(listed))
+		for _, sg := range listed {
+			items[sg.Id] = sg
+		}
+		return items, resp, nil
+	})
+}
@@ -2,6 +2,8 @@ package nexodus
 
 import (
 	"fmt"
+	"net/netip"
+	"strings"
 
 	"go.uber.org/zap"
 )
@@ -15,35 +17,6 @@ func enableExitSrcValidMarkV4() error {
 	return nil
 }
 
-// addExitSrcRuleToRPDB adds a rule to the routing policy database (RPDB) that says, If a packet does
-// not have the firewall mark 51820, look up the routing table 51820.
-func addExitSrcRuleToRPDB() error {
-	if _, err := RunCommand("ip", "-4", "rule", "add", "not", "fwmark", wgFwMarkStr, "table", wgFwMarkStr); err != nil {
-		return fmt.Errorf("failed to add fwmark rule to RPDB: %w", err)
-	}
-
-	return nil
-}
-
-// addExitSrcRuleIgnorePrefixLength adds a rule to the RPDB that says, "When looking up the main routing table, ignore
-// the source address prefix length. This is useful for avoiding unnecessary routing cache updates when using policy-based routing.
-func addExitSrcRuleIgnorePrefixLength() error {
-	if _, err := RunCommand("ip", "-4", "rule", "add", "table", "main", "suppress_prefixlength", "0"); err != nil {
-		return fmt.Errorf("failed to add fwmark rule to RPDB: %w", err)
-	}
-
-	return nil
-}
-
-// addExitSrcDefaultRouteTable adds a default route to the routing table 51820, which says that all traffic should be sent through wg0.
-func addExitSrcDefaultRouteTable() error {
-	if _, err := RunCommand("ip", "-4", "route", "add", "0.0.0.0/0", "dev", wgIface, "table", wgFwMarkStr); err != nil {
-		return fmt.Errorf("failed to add default route to routing table: %w", err)
-	}
-
-	return nil
-}
-
 // nfAddExitSrcMangleTable create a nftables table for mangle
 func nfAddExitSrcMangleTable(logger *zap.SugaredLogger) error {
 	if _, err := policyCmd(logger, []string{"add", "table", "inet", nfOobMangleTable}); err != nil {
@@ -113,34 +86,75 @@ func nfAddExitSrcSnatRule(logger *zap.SugaredLogger, phyIface string) error {
 	return nil
 }
 
-// addExitSrcDefaultRouteTableOOB adds a default route to the OOB routing table, which sources traffic through the physical interface with a gateway
-func addExitSrcDefaultRouteTableOOB(phyIface string) error {
-	gwIP, err := getDefaultGatewayIPv4()
-	if err != nil {
-		return fmt.Errorf("failed to find an IPv4 default gateway: %w", err)
+// nfAddKillSwitchTable creates the nftables table of the exit node kill switch, with the sets of the exit node exceptions
+func nfAddKillSwitchTable(logger *zap.SugaredLogger) error {
+	if _, err := policyCmd(logger, []string{"add", "table", "inet", nfKillSwitchTable}); err != nil {
+		return fmt.Errorf("failed to add nftables table %s: %w", nfKillSwitchTable, err)
 	}
 
-	if _, err := RunCommand("ip", "-4", "route", "add", "0.0.0.0/0", "table", oobFwMark, "via", gwIP, "dev", phyIface); err != nil {
-		return fmt.Errorf("failed to add default route to routing table %s: %w", oobFwMark, err)
+	for _, set := range []string{"excepts4 { type ipv4_addr ; flags interval ; auto-merge ; }", "excepts6 { type ipv6_addr ; flags interval ; auto-merge ; }"} {
+		if _, err := policyCmd(logger, append([]string{"add", "set", "inet", nfKillSwitchTable}, strings.Fields(set)...)); err != nil {
+			return fmt.Errorf("failed to add nftables set to %s: %w", nfKillSwitchTable, err)
+		}
 	}
 
 	return nil
 }
 
-// addExitSrcRuleFwMarkOOB This command adds a rule to the RPDB that says, If a packet has the firewall mark 19302, look up the routing
-// table 19302. This is used to route marked packets with destination port 19302 using the custom routing table
-func addExitSrcRuleFwMarkOOB() error {
-	if _, err := RunCommand("ip", "-4", "rule", "add", "fwmark", oobFwMark, "table", oobFwMark); err != nil {
-		return fmt.Errorf("failed to add OOB fwmark rule to RPDB: %w", err)
+// nfAddKillSwitchOutputChain creates the chain filtering the egress traffic of the device
+func nfAddKillSwitchOutputChain(logger *zap.SugaredLogger) error {
+	if _, err := policyCmd(logger, []string{"add", "chain", "inet", nfKillSwitchTable,
+		"output", "{", "type", "filter", "hook", "output", "priority", "filter", ";", "policy", "accept", ";", "}"}); err != nil {
+		return fmt.Errorf("failed to add nftables output chain to %s: %w", nfKillSwitchTable, err)
 	}
 
 	return nil
 }
 
-// flushExitSrcRouteTableOOB flushes the specified routing table
-func flushExitSrcRouteTableOOB(routeTable string) error {
-	if _, err := RunCommand("ip", "route", "flush", "table", routeTable); err != nil {
-		return fmt.Errorf("failed to flush routing table %s: %w", routeTable, err)
+// nfAddKillSwitchRules drops the egress traffic that does not go through the tunnel, except for the wireguard
+// packets of the tunnel itself, the out of band traffic, DHCP, neighbor discovery and the exit node exceptions.
+func nfAddKillSwitchRules(logger *zap.SugaredLogger, tunnelIface string) error {
+	rules := [][]string{
+		{"oifname", "lo", "accept"},
+		{"oifname", tunnelIface, "accept"},
+		{"meta", "mark", fmt.Sprintf("%d", wgFwMark), "accept"},
+		{"meta", "mark", oobFwdMarkHex, "accept"},
+		{"udp", "dport", "{", "67", ",", "547", "}", "accept"},
+		{"icmpv6", "type", "{", "nd-router-solicit", ",", "nd-neighbor-solicit", ",", "nd-neighbor-advert", "}", "accept"},
+		{"ip", "daddr", "@excepts4", "accept"},
+		{"ip6", "daddr", "@excepts6", "accept"},
+		{"counter", "drop"},
+	}
+	for _, rule := range rules {
+		if _, err := policyCmd(logger, append([]string{"add", "rule", "inet", nfKillSwitchTable, "output"}, rule...)); err != nil {
+			return fmt.Errorf("failed to add nftables kill switch rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// nfSetKillSwitchExcepts replaces the exit node exceptions accepted by the kill switch
+func nfSetKillSwitchExcepts(logger *zap.SugaredLogger, prefixes []netip.Prefix) error {
+	elements := map[string][]string{"excepts4": {}, "excepts6": {}}
+	for _, prefix := range prefixes {
+		if prefix.Addr().Is6() {
+			elements["excepts6"] = append(elements["excepts6"], prefix.String())
+		} else {
+			elements["excepts4"] = append(elements["excepts4"], prefix.String())
+		}
+	}
+
+	for set, prefixes := range elements {
+		if _, err := policyCmd(logger, []string{"flush", "set", "inet", nfKillSwitchTable, set}); err != nil {
+			return fmt.Errorf("failed to flush nftables set %s: %w", set, err)
+		}
+		if len(prefixes) == 0 {
+			continue
+		}
+		if _, err := policyCmd(logger, []string{"add", "element", "inet", nfKillSwitchTable, set, "{", strings.Join(prefixes, ", "), "}"}); err != nil {
+			return fmt.Errorf("failed to add the exit node exceptions to nftables set %s: %w", set, err)
+		}
 	}
 
 	return nil
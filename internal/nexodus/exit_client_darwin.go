@@ -0,0 +1,65 @@
+//go:build darwin
+
+package nexodus
+
+import (
+	"errors"
+	"net/netip"
+)
+
+var errExitNodeClientUnsupported = errors.New("exit node client is currently unsupported on darwin")
+
+// addExitSrcRuleToRPDB for darwin build purposes, exit node client routing currently unsupported on darwin
+func addExitSrcRuleToRPDB() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRuleIgnorePrefixLength for darwin build purposes
+func addExitSrcRuleIgnorePrefixLength() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTable for darwin build purposes
+func addExitSrcDefaultRouteTable() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTableOOB for darwin build purposes
+func addExitSrcDefaultRouteTableOOB(phyIface string) error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRuleFwMarkOOB for darwin build purposes
+func addExitSrcRuleFwMarkOOB() error {
+	return errExitNodeClientUnsupported
+}
+
+// flushExitSrcRouteTableOOB for darwin build purposes
+func flushExitSrcRouteTableOOB(routeTable int) error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRulesIPv6 for darwin build purposes
+func addExitSrcRulesIPv6() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTableIPv6 for darwin build purposes
+func addExitSrcDefaultRouteTableIPv6() error {
+	return errExitNodeClientUnsupported
+}
+
+// deleteExitSrcRules for darwin build purposes
+func deleteExitSrcRules() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcExceptRoute for darwin build purposes
+func addExitSrcExceptRoute(phyIface string, prefix netip.Prefix) error {
+	return errExitNodeClientUnsupported
+}
+
+// deleteExitSrcExceptRoute for darwin build purposes
+func deleteExitSrcExceptRoute(prefix netip.Prefix) error {
+	return errExitNodeClientUnsupported
+}
@@ -0,0 +1,250 @@
+//go:build linux
+
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// addExitSrcRuleToRPDB adds a rule to the routing policy database (RPDB) that says, If a packet does
+// not have the firewall mark 51820, look up the routing table 51820.
+func addExitSrcRuleToRPDB() error {
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V4
+	rule.Mark = wgFwMark
+	rule.Invert = true
+	rule.Table = wgFwMark
+	if err := netlink.RuleAdd(rule); err != nil {
+		return fmt.Errorf("failed to add fwmark rule to RPDB: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcRuleIgnorePrefixLength adds a rule to the RPDB that says, "When looking up the main routing table, ignore
+// the source address prefix length. This is useful for avoiding unnecessary routing cache updates when using policy-based routing.
+func addExitSrcRuleIgnorePrefixLength() error {
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V4
+	rule.Table = unix.RT_TABLE_MAIN
+	rule.SuppressPrefixlen = 0
+	if err := netlink.RuleAdd(rule); err != nil {
+		return fmt.Errorf("failed to add fwmark rule to RPDB: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcDefaultRouteTable adds a default route to the routing table 51820, which says that all traffic should be sent through wg0.
+func addExitSrcDefaultRouteTable() error {
+	link, err := netlink.LinkByName(wgIface)
+	if err != nil {
+		return fmt.Errorf("failed to lookup netlink device %s: %w", wgIface, err)
+	}
+
+	err = netlink.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       defaultRouteV4(),
+		Scope:     netlink.SCOPE_LINK,
+		Table:     wgFwMark,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add default route to routing table: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcRulesIPv6 adds the rules of addExitSrcRuleToRPDB and addExitSrcRuleIgnorePrefixLength for IPv6
+func addExitSrcRulesIPv6() error {
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V6
+	rule.Mark = wgFwMark
+	rule.Invert = true
+	rule.Table = wgFwMark
+	if err := netlink.RuleAdd(rule); err != nil {
+		return fmt.Errorf("failed to add IPv6 fwmark rule to RPDB: %w", err)
+	}
+
+	rule = netlink.NewRule()
+	rule.Family = netlink.FAMILY_V6
+	rule.Table = unix.RT_TABLE_MAIN
+	rule.SuppressPrefixlen = 0
+	if err := netlink.RuleAdd(rule); err != nil {
+		return fmt.Errorf("failed to add IPv6 suppress prefix length rule to RPDB: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcDefaultRouteTableIPv6 adds an IPv6 default route to the routing table 51820 through wg0.
+func addExitSrcDefaultRouteTableIPv6() error {
+	link, err := netlink.LinkByName(wgIface)
+	if err != nil {
+		return fmt.Errorf("failed to lookup netlink device %s: %w", wgIface, err)
+	}
+
+	err = netlink.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(0, 128)},
+		Scope:     netlink.SCOPE_LINK,
+		Table:     wgFwMark,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add IPv6 default route to routing table: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcDefaultRouteTableOOB adds a default route to the OOB routing table, which sources traffic through the physical interface with a gateway
+func addExitSrcDefaultRouteTableOOB(phyIface string) error {
+	gwIP, err := getDefaultGatewayIPv4()
+	if err != nil {
+		return fmt.Errorf("failed to find an IPv4 default gateway: %w", err)
+	}
+
+	link, err := netlink.LinkByName(phyIface)
+	if err != nil {
+		return fmt.Errorf("failed to lookup netlink device %s: %w", phyIface, err)
+	}
+
+	err = netlink.RouteAdd(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       defaultRouteV4(),
+		Gw:        net.ParseIP(gwIP),
+		Table:     oobFwMark,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add default route to routing table %d: %w", oobFwMark, err)
+	}
+
+	return nil
+}
+
+// addExitSrcRuleFwMarkOOB This command adds a rule to the RPDB that says, If a packet has the firewall mark 19302, look up the routing
+// table 19302. This is used to route marked packets with destination port 19302 using the custom routing table
+func addExitSrcRuleFwMarkOOB() error {
+	rule := netlink.NewRule()
+	rule.Family = netlink.FAMILY_V4
+	rule.Mark = oobFwMark
+	rule.Table = oobFwMark
+	if err := netlink.RuleAdd(rule); err != nil {
+		return fmt.Errorf("failed to add OOB fwmark rule to RPDB: %w", err)
+	}
+
+	return nil
+}
+
+// addExitSrcExceptRoute adds a route for the prefix to the routing table 51820 through the default gateway of the
+// physical interface, which is more specific than the default route through wg0 and keeps the traffic off the exit node.
+func addExitSrcExceptRoute(phyIface string, prefix netip.Prefix) error {
+	family := netlink.FAMILY_V4
+	if prefix.Addr().Is6() {
+		family = netlink.FAMILY_V6
+	}
+	gw, err := defaultGateway(family)
+	if err != nil {
+		return fmt.Errorf("failed to find a default gateway for the exit node exception %s: %w", prefix, err)
+	}
+
+	link, err := netlink.LinkByName(phyIface)
+	if err != nil {
+		return fmt.Errorf("failed to lookup netlink device %s: %w", phyIface, err)
+	}
+
+	_, dst, err := net.ParseCIDR(prefix.String())
+	if err != nil {
+		return err
+	}
+	err = netlink.RouteReplace(&netlink.Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       dst,
+		Gw:        gw,
+		Table:     wgFwMark,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add the exit node exception route %s to routing table %d: %w", prefix, wgFwMark, err)
+	}
+
+	return nil
+}
+
+// deleteExitSrcExceptRoute deletes the route of an exit node exception from the routing table 51820
+func deleteExitSrcExceptRoute(prefix netip.Prefix) error {
+	_, dst, err := net.ParseCIDR(prefix.String())
+	if err != nil {
+		return err
+	}
+	if err := netlink.RouteDel(&netlink.Route{Dst: dst, Table: wgFwMark}); err != nil {
+		return fmt.Errorf("failed to delete the exit node exception route %s from routing table %d: %w", prefix, wgFwMark, err)
+	}
+
+	return nil
+}
+
+// defaultGateway returns the gateway of the default route of the main routing table for the family
+func defaultGateway(family int) (net.IP, error) {
+	routes, err := netlink.RouteList(nil, family)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, route := range routes {
+		if (route.Dst == nil || route.Dst.IP.IsUnspecified() && isDefaultMask(route.Dst.Mask)) && route.Gw != nil {
+			return route.Gw, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unable to determine default route")
+}
+
+func isDefaultMask(mask net.IPMask) bool {
+	ones, _ := mask.Size()
+	return ones == 0
+}
+
+// deleteExitSrcRules deletes the rules added to the RPDB by the exit node client
+func deleteExitSrcRules() error {
+	rules, err := netlink.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		return fmt.Errorf("failed to list the RPDB rules: %w", err)
+	}
+
+	for i := range rules {
+		rule := rules[i]
+		if rule.Table != wgFwMark && rule.Table != oobFwMark && !(rule.Table == unix.RT_TABLE_MAIN && rule.SuppressPrefixlen == 0) {
+			continue
+		}
+		if err := netlink.RuleDel(&rule); err != nil {
+			return fmt.Errorf("failed to delete RPDB rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// flushExitSrcRouteTableOOB flushes the specified routing table
+func flushExitSrcRouteTableOOB(routeTable int) error {
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: routeTable}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return fmt.Errorf("failed to flush routing table %d: %w", routeTable, err)
+	}
+
+	for i := range routes {
+		if err := netlink.RouteDel(&routes[i]); err != nil {
+			return fmt.Errorf("failed to flush routing table %d: %w", routeTable, err)
+		}
+	}
+
+	return nil
+}
+
+func defaultRouteV4() *net.IPNet {
+	return &net.IPNet{IP: net.IPv4zero.To4(), Mask: net.CIDRMask(0, 32)}
+}
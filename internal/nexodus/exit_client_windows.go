@@ -0,0 +1,65 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"errors"
+	"net/netip"
+)
+
+var errExitNodeClientUnsupported = errors.New("exit node client is currently unsupported on windows")
+
+// addExitSrcRuleToRPDB for windows build purposes, exit node client routing currently unsupported on windows
+func addExitSrcRuleToRPDB() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRuleIgnorePrefixLength for windows build purposes
+func addExitSrcRuleIgnorePrefixLength() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTable for windows build purposes
+func addExitSrcDefaultRouteTable() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTableOOB for windows build purposes
+func addExitSrcDefaultRouteTableOOB(phyIface string) error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRuleFwMarkOOB for windows build purposes
+func addExitSrcRuleFwMarkOOB() error {
+	return errExitNodeClientUnsupported
+}
+
+// flushExitSrcRouteTableOOB for windows build purposes
+func flushExitSrcRouteTableOOB(routeTable int) error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcRulesIPv6 for windows build purposes
+func addExitSrcRulesIPv6() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcDefaultRouteTableIPv6 for windows build purposes
+func addExitSrcDefaultRouteTableIPv6() error {
+	return errExitNodeClientUnsupported
+}
+
+// deleteExitSrcRules for windows build purposes
+func deleteExitSrcRules() error {
+	return errExitNodeClientUnsupported
+}
+
+// addExitSrcExceptRoute for windows build purposes
+func addExitSrcExceptRoute(phyIface string, prefix netip.Prefix) error {
+	return errExitNodeClientUnsupported
+}
+
+// deleteExitSrcExceptRoute for windows build purposes
+func deleteExitSrcExceptRoute(prefix netip.Prefix) error {
+	return errExitNodeClientUnsupported
+}
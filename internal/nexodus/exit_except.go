@@ -0,0 +1,169 @@
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// how often the domains of the exit node exceptions are resolved again
+const exitNodeExceptRefreshInterval = 5 * time.Minute
+
+var domainRegex = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+
+// parseExitNodeExcept validates an exit node exception, a CIDR, an IP address or a domain name, and returns it normalized
+func parseExitNodeExcept(except string) (string, error) {
+	except = strings.TrimSpace(except)
+	if prefix, err := netip.ParsePrefix(except); err == nil {
+		if prefix.Bits() == 0 {
+			return "", fmt.Errorf("the default route %s can not be an exit node exception", except)
+		}
+		return prefix.Masked().String(), nil
+	}
+	if addr, err := netip.ParseAddr(except); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+	}
+	if except != "" && len(except) <= 253 && domainRegex.MatchString(except) {
+		return strings.ToLower(strings.TrimSuffix(except, ".")), nil
+	}
+	return "", fmt.Errorf("invalid exit node exception %q, must be a CIDR, an IP address or a domain name", except)
+}
+
+// exitNodeExcepts returns the exit node exceptions of this device
+func (nx *Nexodus) exitNodeExcepts() []string {
+	if nx.stateStore == nil {
+		return nil
+	}
+	return slices.Clone(nx.stateStore.State().ExitNodeExcepts)
+}
+
+// ExitNodeExceptAdd keeps the traffic to the CIDR, IP address or domain on the local default route when an exit node is in use
+func (nx *Nexodus) ExitNodeExceptAdd(except string) (string, error) {
+	except, err := parseExitNodeExcept(except)
+	if err != nil {
+		return "", err
+	}
+	if nx.stateStore == nil {
+		return "", fmt.Errorf("exit node exceptions can not be stored")
+	}
+
+	nx.exitNode.exceptLock.Lock()
+	defer nx.exitNode.exceptLock.Unlock()
+	s := nx.stateStore.State()
+	if slices.Contains(s.ExitNodeExcepts, except) {
+		return except, nil
+	}
+	s.ExitNodeExcepts = append(s.ExitNodeExcepts, except)
+	if err := nx.stateStore.Store(); err != nil {
+		return "", err
+	}
+	return except, nx.applyExitNodeExcepts()
+}
+
+// ExitNodeExceptRemove removes an exit node exception, the traffic to it goes through the exit node in use again
+func (nx *Nexodus) ExitNodeExceptRemove(except string) (string, error) {
+	except, err := parseExitNodeExcept(except)
+	if err != nil {
+		return "", err
+	}
+	if nx.stateStore == nil {
+		return "", fmt.Errorf("exit node exceptions can not be stored")
+	}
+
+	nx.exitNode.exceptLock.Lock()
+	defer nx.exitNode.exceptLock.Unlock()
+	s := nx.stateStore.State()
+	i := slices.Index(s.ExitNodeExcepts, except)
+	if i < 0 {
+		return "", fmt.Errorf("no exit node exception %s found", except)
+	}
+	s.ExitNodeExcepts = slices.Delete(s.ExitNodeExcepts, i, i+1)
+	if err := nx.stateStore.Store(); err != nil {
+		return "", err
+	}
+	return except, nx.applyExitNodeExcepts()
+}
+
+// refreshExitNodeExcepts resolves the domains of the exit node exceptions again, updating their routes
+func (nx *Nexodus) refreshExitNodeExcepts() {
+	nx.exitNode.exceptLock.Lock()
+	defer nx.exitNode.exceptLock.Unlock()
+	if err := nx.applyExitNodeExcepts(); err != nil {
+		nx.logger.Warnf("failed to update the exit node exception routes: %v", err)
+	}
+}
+
+// exitNodeExceptPrefixes resolves the exit node exceptions to the prefixes routed through the local default gateway
+func (nx *Nexodus) exitNodeExceptPrefixes(excepts []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var lastErr error
+	for _, except := range excepts {
+		if prefix, err := netip.ParsePrefix(except); err == nil {
+			prefixes = append(prefixes, prefix)
+			continue
+		}
+		ips, err := net.LookupIP(except)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to resolve the exit node exception %s: %w", except, err)
+			continue
+		}
+		for _, ip := range ips {
+			if addr, ok := netip.AddrFromSlice(ip); ok {
+				addr = addr.Unmap()
+				prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			}
+		}
+	}
+	return prefixes, lastErr
+}
+
+// applyExitNodeExcepts programs the exit node exceptions as more specific routes than the default route through the
+// exit node, and removes the routes of the exceptions that were removed. Assumes exceptLock is held.
+func (nx *Nexodus) applyExitNodeExcepts() error {
+	nx.deviceCacheLock.RLock()
+	enabled := nx.exitNode.exitNodeClientEnabled && nx.exitNode.activeOrigin != ""
+	nx.deviceCacheLock.RUnlock()
+
+	var prefixes []netip.Prefix
+	var lastErr error
+	if enabled {
+		prefixes, lastErr = nx.exitNodeExceptPrefixes(nx.exitNodeExcepts())
+	}
+
+	devName, err := getInterfaceFromIPv4(nx.endpointLocalAddress)
+	if err != nil {
+		nx.logger.Debugf("failed to discover the interface with the address [ %s ] %v", nx.endpointLocalAddress, err)
+	}
+	routes := map[netip.Prefix]bool{}
+	var routed []netip.Prefix
+	for _, prefix := range prefixes {
+		if prefix.Addr().Is6() && !nx.ipv6Supported {
+			continue
+		}
+		if err := addExitSrcExceptRoute(devName, prefix); err != nil {
+			lastErr = err
+			continue
+		}
+		routes[prefix] = true
+		routed = append(routed, prefix)
+	}
+	for prefix := range nx.exitNode.exceptRoutes {
+		if routes[prefix] {
+			continue
+		}
+		if err := deleteExitSrcExceptRoute(prefix); err != nil {
+			nx.logger.Debug(err)
+		}
+	}
+	nx.exitNode.exceptRoutes = routes
+	if nx.exitNode.killSwitchInstalled {
+		if err := nfSetKillSwitchExcepts(nx.logger, routed); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,146 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// how often the exit node in use is probed through the tunnel
+	exitNodeProbeInterval = 10 * time.Second
+	// a wireguard session rekeys every 120 seconds (REKEY_AFTER_TIME) while traffic flows, so a
+	// handshake older than this means the exit node stopped answering handshakes
+	exitNodeHandshakeTimeout = 135 * time.Second
+)
+
+// ExitNodeFailover is a failover from an exit node that stopped handshaking to the next healthy exit node
+type ExitNodeFailover struct {
+	Time   time.Time `json:"time"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason"`
+}
+
+// runExitNodeClient checks the health of the exit node in use and refreshes the routes of the exit node exceptions
+func (nx *Nexodus) runExitNodeClient(ctx context.Context) {
+	ticker := time.NewTicker(exitNodeProbeInterval)
+	defer ticker.Stop()
+	exceptTicker := time.NewTicker(exitNodeExceptRefreshInterval)
+	defer exceptTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nx.checkExitNodeHealth()
+		case <-exceptTicker.C:
+			if nx.activeExitNode() != "" {
+				nx.refreshExitNodeExcepts()
+			}
+		}
+	}
+}
+
+// exitNodeHandshakeStale returns true if the exit node in use since activeSince stopped handshaking
+func exitNodeHandshakeStale(lastHandshake, activeSince, now time.Time) bool {
+	if now.Sub(activeSince) < exitNodeHandshakeTimeout {
+		// give a newly selected exit node the time to complete a handshake
+		return false
+	}
+	return lastHandshake.IsZero() || now.Sub(lastHandshake) > exitNodeHandshakeTimeout
+}
+
+// checkExitNodeHealth probes the exit node in use, and fails over to the next healthy exit node origin when it stopped handshaking
+func (nx *Nexodus) checkExitNodeHealth() {
+	nx.deviceCacheLock.RLock()
+	enabled := nx.exitNode.exitNodeClientEnabled && nx.exitNode.activeOrigin != ""
+	active, ok := nx.deviceCache[nx.exitNode.activeOrigin]
+	activeSince := nx.exitNode.activeSince
+	nx.deviceCacheLock.RUnlock()
+	if !enabled || !ok {
+		return
+	}
+
+	// the probe sends traffic through the tunnel, which makes wireguard attempt a handshake when the session expired
+	probeErr := fmt.Errorf("the exit node has no tunnel ip")
+	if len(active.device.Ipv4TunnelIps) > 0 {
+		_, probeErr = nx.doPing(active.device.Ipv4TunnelIps[0].Address, uint64(time.Now().UnixNano()), reachabilityProbeTimeout)
+	}
+	now := time.Now()
+	if probeErr == nil || !exitNodeHandshakeStale(active.lastHandshakeTime, activeSince, now) {
+		nx.exitNode.failoverUnavailable = false
+		return
+	}
+
+	reason := "no handshake with the exit node"
+	if !active.lastHandshakeTime.IsZero() {
+		reason = fmt.Sprintf("last handshake with the exit node %s ago", now.Sub(active.lastHandshakeTime).Round(time.Second))
+	}
+	nx.deviceCacheLock.RLock()
+	next, found := nx.nextExitNodeOrigin()
+	nx.deviceCacheLock.RUnlock()
+	if !found {
+		if !nx.exitNode.failoverUnavailable {
+			nx.logger.Warnf("Exit node %s stopped handshaking (%s), no other healthy exit node is available", active.device.Hostname, reason)
+			nx.exitNode.failoverUnavailable = true
+		}
+		return
+	}
+
+	nx.logger.Warnf("Exit node %s stopped handshaking (%s), failing over to the exit node %s", active.device.Hostname, reason, next.Hostname)
+	if _, err := nx.selectExitNodeOrigin(next.PublicKey); err != nil {
+		nx.logger.Errorf("failed to fail over to the exit node %s: %v", next.Hostname, err)
+		return
+	}
+	nx.deviceCacheLock.Lock()
+	nx.exitNode.lastFailover = &ExitNodeFailover{
+		Time:   now,
+		From:   active.device.Hostname,
+		To:     next.Hostname,
+		Reason: reason,
+	}
+	nx.deviceCacheLock.Unlock()
+	nx.exitNode.failoverUnavailable = false
+}
+
+// nextExitNodeOrigin returns the next healthy exit node origin after the exit node in use, assumes deviceCacheLock is held.
+func (nx *Nexodus) nextExitNodeOrigin() (ExitNodeOrigin, bool) {
+	origins := nx.exitNodeOrigins()
+	start := 0
+	for i, o := range origins {
+		if o.PublicKey == nx.exitNode.activeOrigin {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(origins); i++ {
+		o := origins[(start+i)%len(origins)]
+		if o.PublicKey == nx.exitNode.activeOrigin {
+			continue
+		}
+		if _, ok := nx.wgConfig.Peers[o.PublicKey]; !ok {
+			continue
+		}
+		if d, ok := nx.deviceCache[o.PublicKey]; ok && d.peerHealthy {
+			return o, true
+		}
+	}
+	return ExitNodeOrigin{}, false
+}
+
+// exitNodeFailoverStatus renders the last exit node failover for the nexd status output
+func (nx *Nexodus) exitNodeFailoverStatus() string {
+	failover := nx.lastExitNodeFailover()
+	if failover == nil {
+		return ""
+	}
+	return fmt.Sprintf("Exit Node Failover: from %s to %s at %s (%s)\n",
+		failover.From, failover.To, failover.Time.Format(time.RFC3339), failover.Reason)
+}
+
+func (nx *Nexodus) lastExitNodeFailover() *ExitNodeFailover {
+	nx.deviceCacheLock.RLock()
+	defer nx.deviceCacheLock.RUnlock()
+	return nx.exitNode.lastFailover
+}
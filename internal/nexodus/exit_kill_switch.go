@@ -0,0 +1,50 @@
+package nexodus
+
+// exitNodeKillSwitchSetup installs the kill switch if it is enabled, which drops the egress traffic of the device
+// that would leave the physical interface instead of the tunnel. Assumes exceptLock is held.
+func (nx *Nexodus) exitNodeKillSwitchSetup() error {
+	if !nx.exitNode.killSwitch || nx.exitNode.killSwitchInstalled {
+		return nil
+	}
+
+	// teardown any residual kill switch from previous runs
+	if err := nx.policyTableDrop(nfKillSwitchTable); err != nil {
+		nx.logger.Debug(err)
+	}
+
+	if err := nfAddKillSwitchTable(nx.logger); err != nil {
+		return err
+	}
+
+	if err := nfAddKillSwitchOutputChain(nx.logger); err != nil {
+		return err
+	}
+
+	if err := nfAddKillSwitchRules(nx.logger, nx.tunnelIface); err != nil {
+		_ = nx.policyTableDrop(nfKillSwitchTable)
+		return err
+	}
+
+	nx.exitNode.killSwitchInstalled = true
+	nx.logger.Info("Exit node kill switch has been enabled, traffic leaving the physical interface outside of the tunnel is dropped")
+
+	return nil
+}
+
+// exitNodeKillSwitchTeardown removes the kill switch
+func (nx *Nexodus) exitNodeKillSwitchTeardown() error {
+	nx.exitNode.exceptLock.Lock()
+	defer nx.exitNode.exceptLock.Unlock()
+	if !nx.exitNode.killSwitchInstalled {
+		return nil
+	}
+
+	if err := nx.policyTableDrop(nfKillSwitchTable); err != nil {
+		return err
+	}
+
+	nx.exitNode.killSwitchInstalled = false
+	nx.logger.Info("Exit node kill switch has been disabled")
+
+	return nil
+}
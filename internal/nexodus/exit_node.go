@@ -2,61 +2,199 @@ package nexodus
 
 import (
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
 	"github.com/nexodus-io/nexodus/internal/util"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
 const (
-	oobDNS           = 53
-	oobHttps         = 443
-	oobGoogleStun    = 19302
-	wgFwMark         = 51820
-	wgFwMarkStr      = "51820"
-	oobFwMark        = "19302"
-	oobFwdMarkHex    = "0x4B66"
-	nfExitNodeTable  = "nexodus-exit-node"
-	nfOobMangleTable = "nexodus-oob-mangle"
-	nfOobSnatTable   = "nexodus-oob-snat"
+	oobDNS            = 53
+	oobHttps          = 443
+	oobGoogleStun     = 19302
+	wgFwMark          = 51820
+	oobFwMark         = 19302
+	oobFwdMarkHex     = "0x4B66"
+	nfExitNodeTable   = "nexodus-exit-node"
+	nfOobMangleTable  = "nexodus-oob-mangle"
+	nfOobSnatTable    = "nexodus-oob-snat"
+	nfKillSwitchTable = "nexodus-kill-switch"
 )
 
-// ExitNodeClientSetup setups up the routing tables, netfilter tables and out of band connections for the exit node client
-func (nx *Nexodus) ExitNodeClientSetup() error {
-	nx.exitNode.exitNodeClientEnabled = true
+// ExitNodeOrigin is a peer of this device that advertises a default route and can be used as its exit node
+type ExitNodeOrigin struct {
+	Hostname  string `json:"hostname"`
+	DeviceId  string `json:"device-id"`
+	PublicKey string `json:"public-key"`
+	Endpoint  string `json:"endpoint"`
+	Active    bool   `json:"active"`
+	Local     bool   `json:"local,omitempty"`
+}
 
-	// Lock deviceCache for read
-	nx.deviceCacheLock.RLock()
-	defer nx.deviceCacheLock.RUnlock()
+// advertisesDefaultRoute returns true if the device advertises a default route, which makes it an exit node origin
+func advertisesDefaultRoute(device public.ModelsDevice) bool {
+	for _, cidr := range device.AdvertiseCidrs {
+		if util.IsDefaultIPv4Route(cidr) || util.IsDefaultIPv6Route(cidr) {
+			return true
+		}
+	}
+	return false
+}
 
-	exitNodeFound := false
-	for _, deviceEntry := range nx.deviceCache {
-		for _, allowedIp := range deviceEntry.device.AllowedIps {
-			if util.IsDefaultIPv4Route(allowedIp) {
-				// assign the device advertising a default route as the exit node server/origin node
-				localEndpoint := ""
-				for _, endpoint := range deviceEntry.device.Endpoints {
-					if endpoint.Source == "local" {
-						localEndpoint = endpoint.Address
-						break
-					}
-				}
-				nx.exitNode.exitNodeOrigins[0] = wgPeerConfig{
-					PublicKey:           deviceEntry.device.PublicKey,
-					Endpoint:            localEndpoint,
-					AllowedIPs:          deviceEntry.device.AllowedIps,
-					PersistentKeepAlive: persistentKeepalive,
-				}
-				exitNodeFound = true
-				break
-			}
+// exitNodeOrigins lists the exit node origins in this device's peerings sorted by hostname, assumes deviceCacheLock is held.
+func (nx *Nexodus) exitNodeOrigins() []ExitNodeOrigin {
+	origins := []ExitNodeOrigin{}
+	for _, d := range nx.deviceCache {
+		if d.device.PublicKey == nx.wireguardPubKey || !advertisesDefaultRoute(d.device) {
+			continue
 		}
-		if exitNodeFound {
+		origins = append(origins, ExitNodeOrigin{
+			Hostname:  d.device.Hostname,
+			DeviceId:  d.device.Id,
+			PublicKey: d.device.PublicKey,
+			Endpoint:  nx.wgConfig.Peers[d.device.PublicKey].Endpoint,
+			Active:    nx.exitNode.exitNodeClientEnabled && d.device.PublicKey == nx.exitNode.activeOrigin,
+		})
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		if origins[i].Hostname != origins[j].Hostname {
+			return origins[i].Hostname < origins[j].Hostname
+		}
+		return origins[i].PublicKey < origins[j].PublicKey
+	})
+	return origins
+}
+
+// exitNodeAllowedIPs keeps the default routes advertised by the exit node origins on the peer of the exit node
+// in use only, since wireguard routes a prefix through a single peer. The exit node in use also gets the IPv6
+// default route if this device supports IPv6.
+func (nx *Nexodus) exitNodeAllowedIPs(device public.ModelsDevice, allowedIPs []string) []string {
+	if !nx.exitNode.exitNodeClientEnabled || nx.exitNode.activeOrigin == "" || len(allowedIPs) == 0 || !advertisesDefaultRoute(device) {
+		return allowedIPs
+	}
+	result := make([]string, 0, len(allowedIPs)+2)
+	for _, allowedIP := range allowedIPs {
+		if util.IsDefaultIPv4Route(allowedIP) || util.IsDefaultIPv6Route(allowedIP) {
+			continue
+		}
+		result = append(result, allowedIP)
+	}
+	if device.PublicKey == nx.exitNode.activeOrigin {
+		result = append(result, "0.0.0.0/0")
+		if nx.ipv6Supported {
+			result = append(result, "::/0")
+		}
+	}
+	return result
+}
+
+// selectExitNodeOrigin selects the exit node origin with the hostname, device id or public key, or if no name is given
+// the exit node in use or the first one found, and moves the default routes to its wireguard peer.
+func (nx *Nexodus) selectExitNodeOrigin(name string) (ExitNodeOrigin, error) {
+	nx.deviceCacheLock.Lock()
+	defer nx.deviceCacheLock.Unlock()
+
+	origins := nx.exitNodeOrigins()
+	var origin *ExitNodeOrigin
+	for i, o := range origins {
+		if (name != "" && (o.Hostname == name || o.DeviceId == name || o.PublicKey == name)) ||
+			(name == "" && o.PublicKey == nx.exitNode.activeOrigin) {
+			origin = &origins[i]
 			break
 		}
 	}
+	if origin == nil && name == "" && len(origins) > 0 {
+		origin = &origins[0]
+	}
+	if origin == nil {
+		if name != "" {
+			return ExitNodeOrigin{}, fmt.Errorf("no exit node %q found in this device's peerings", name)
+		}
+		return ExitNodeOrigin{}, fmt.Errorf("no exit node found in this device's peerings")
+	}
+	if _, ok := nx.wgConfig.Peers[origin.PublicKey]; !ok {
+		return ExitNodeOrigin{}, fmt.Errorf("the exit node %s is not peered directly with this device", origin.Hostname)
+	}
+
+	if !nx.exitNode.exitNodeClientEnabled || nx.exitNode.activeOrigin != origin.PublicKey {
+		nx.exitNode.activeSince = time.Now()
+	}
+	nx.exitNode.exitNodeClientEnabled = true
+	nx.exitNode.activeOrigin = origin.PublicKey
+	for _, d := range nx.deviceCache {
+		peer, ok := nx.wgConfig.Peers[d.device.PublicKey]
+		if !ok || d.device.PublicKey == origin.PublicKey || !advertisesDefaultRoute(d.device) {
+			continue
+		}
+		peer.AllowedIPs = nx.exitNodeAllowedIPs(d.device, peer.AllowedIPs)
+		nx.wgConfig.Peers[d.device.PublicKey] = peer
+	}
+	// the exit node in use is configured last, taking over the default routes of the other peers
+	d := nx.deviceCache[origin.PublicKey]
+	peer := nx.wgConfig.Peers[origin.PublicKey]
+	peer.AllowedIPs = nx.exitNodeAllowedIPs(d.device, peer.AllowedIPs)
+	nx.wgConfig.Peers[origin.PublicKey] = peer
+	if err := nx.handlePeerTunnel(peer); err != nil {
+		return ExitNodeOrigin{}, err
+	}
+	origin.Active = true
+	return *origin, nil
+}
+
+// activeExitNode returns the hostname of the exit node in use by this device, empty if it does not use an exit node
+func (nx *Nexodus) activeExitNode() string {
+	nx.deviceCacheLock.RLock()
+	defer nx.deviceCacheLock.RUnlock()
+	if !nx.exitNode.exitNodeClientEnabled || nx.exitNode.activeOrigin == "" {
+		return ""
+	}
+	if d, ok := nx.deviceCache[nx.exitNode.activeOrigin]; ok {
+		return d.device.Hostname
+	}
+	return nx.exitNode.activeOrigin
+}
+
+// ExitNodeClientSetup setups up the routing tables, netfilter tables and out of band connections for the exit node client,
+// using the exit node in use or the first exit node origin found in this device's peerings.
+func (nx *Nexodus) ExitNodeClientSetup() error {
+	_, err := nx.exitNodeClientSetup("")
+	return err
+}
+
+// UseExitNode routes the traffic of this device through the exit node origin with the hostname, device id or public key
+func (nx *Nexodus) UseExitNode(name string) (ExitNodeOrigin, error) {
+	if name == "" {
+		return ExitNodeOrigin{}, fmt.Errorf("an exit node is required")
+	}
+	return nx.exitNodeClientSetup(name)
+}
 
-	if len(nx.exitNode.exitNodeOrigins) == 0 {
-		return fmt.Errorf("no exit node found in this device's peerings")
+func (nx *Nexodus) exitNodeClientSetup(name string) (ExitNodeOrigin, error) {
+	if !nx.featureEnabled(exitNodeFlag, true) {
+		return ExitNodeOrigin{}, fmt.Errorf("exit node support is not enabled for this organization")
+	}
+	// the kill switch is installed first, so that no traffic leaks until an exit node is in use
+	nx.exitNode.exceptLock.Lock()
+	err := nx.exitNodeKillSwitchSetup()
+	nx.exitNode.exceptLock.Unlock()
+	if err != nil {
+		return ExitNodeOrigin{}, err
+	}
+
+	origin, err := nx.selectExitNodeOrigin(name)
+	if err != nil {
+		return origin, err
+	}
+	return origin, nx.exitNodeClientRoutes(origin)
+}
+
+// exitNodeClientRoutes routes the traffic of this device through the exit node origin
+func (nx *Nexodus) exitNodeClientRoutes(origin ExitNodeOrigin) error {
+	if err := nx.checkExitRouteTableConflicts(); err != nil {
+		return err
 	}
 
 	// teardown any residual nf tables or routing tables from previous runs
@@ -82,11 +220,6 @@ func (nx *Nexodus) ExitNodeClientSetup() error {
 		return fmt.Errorf("error adding exit node client fwdMark: %w", err)
 	}
 
-	if err := nx.handlePeerTunnel(nx.exitNode.exitNodeOrigins[0]); err != nil {
-		nx.logger.Debug(err)
-		return err
-	}
-
 	devName, err := getInterfaceFromIPv4(nx.endpointLocalAddress)
 	if err != nil {
 		nx.logger.Debugf("failed to discover the interface with the address [ %s ] %v", nx.endpointLocalAddress, err)
@@ -109,7 +242,19 @@ func (nx *Nexodus) ExitNodeClientSetup() error {
 
 	if err := addExitSrcDefaultRouteTable(); err != nil {
 		nx.logger.Debug(err)
-		nx.logger.Debugf("default route already exists in table %s", oobFwMark)
+		nx.logger.Debugf("default route already exists in table %d", oobFwMark)
+	}
+
+	if nx.ipv6Supported {
+		if err := addExitSrcRulesIPv6(); err != nil {
+			nx.logger.Debug(err)
+			return err
+		}
+
+		if err := addExitSrcDefaultRouteTableIPv6(); err != nil {
+			nx.logger.Debug(err)
+			nx.logger.Debugf("IPv6 default route already exists in table %d", wgFwMark)
+		}
 	}
 
 	if err := nfAddExitSrcMangleTable(nx.logger); err != nil {
@@ -165,7 +310,7 @@ func (nx *Nexodus) ExitNodeClientSetup() error {
 	}
 
 	if err := addExitSrcDefaultRouteTableOOB(devName); err != nil {
-		nx.logger.Debugf("default route already exists in table %s", oobFwMark)
+		nx.logger.Debugf("default route already exists in table %d", oobFwMark)
 	}
 
 	if err := addExitSrcRuleFwMarkOOB(); err != nil {
@@ -173,8 +318,10 @@ func (nx *Nexodus) ExitNodeClientSetup() error {
 		return err
 	}
 
-	nx.logger.Info("Exit node client configuration has been enabled")
-	nx.logger.Debugf("Exit node client enabled and using the exit node server: %+v", nx.exitNode.exitNodeOrigins[0])
+	nx.refreshExitNodeExcepts()
+
+	nx.logger.Infof("Exit node client configuration has been enabled, using the exit node %s", origin.Hostname)
+	nx.logger.Debugf("Exit node client enabled and using the exit node server: %+v", origin)
 
 	return nil
 }
@@ -220,19 +367,41 @@ func (nx *Nexodus) exitNodeOriginSetup() error {
 	return nil
 }
 
+// exitNodeClientDisable stops routing the traffic of this device through an exit node
+func (nx *Nexodus) exitNodeClientDisable() error {
+	nx.deviceCacheLock.Lock()
+	nx.exitNode.exitNodeClientEnabled = false
+	nx.exitNode.activeOrigin = ""
+	nx.deviceCacheLock.Unlock()
+
+	// the routes of the exit node exceptions are flushed with the routing tables
+	nx.exitNode.exceptLock.Lock()
+	nx.exitNode.exceptRoutes = nil
+	nx.exitNode.exceptLock.Unlock()
+
+	if err := nx.exitNodeKillSwitchTeardown(); err != nil {
+		return err
+	}
+	return nx.exitNodeClientTeardown()
+}
+
 func (nx *Nexodus) exitNodeClientTeardown() error {
 	var err1, err2 error
 
 	// TODO: this needs to be able to be set by nexctl but not for initial pre-deploy checks
 	// nx.exitNode.exitNodeClientEnabled = false
 
-	exitNodeRouteTables := []string{wgFwMarkStr, oobFwMark}
+	exitNodeRouteTables := []int{wgFwMark, oobFwMark}
 	for _, routeTable := range exitNodeRouteTables {
 		if err1 = flushExitSrcRouteTableOOB(routeTable); err1 != nil {
 			nx.logger.Debug(err1)
 		}
 	}
 
+	if err := deleteExitSrcRules(); err != nil {
+		nx.logger.Debug(err)
+	}
+
 	exitNodeNFTables := []string{nfOobMangleTable, nfOobSnatTable}
 	for _, nfTable := range exitNodeNFTables {
 		if err2 = nx.policyTableDrop(nfTable); err2 != nil {
@@ -0,0 +1,111 @@
+package nexodus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestExitNodeAllowedIPs(t *testing.T) {
+	require := require.New(t)
+	active := public.ModelsDevice{PublicKey: "active", AdvertiseCidrs: []string{"0.0.0.0/0"}}
+	other := public.ModelsDevice{PublicKey: "other", AdvertiseCidrs: []string{"0.0.0.0/0"}}
+	peer := public.ModelsDevice{PublicKey: "peer", AdvertiseCidrs: []string{"10.10.0.0/24"}}
+	nx := &Nexodus{}
+
+	// without an exit node in use the allowed ips are unchanged
+	require.Equal([]string{"100.64.0.2/32", "0.0.0.0/0"}, nx.exitNodeAllowedIPs(other, []string{"100.64.0.2/32", "0.0.0.0/0"}))
+
+	nx.exitNode.exitNodeClientEnabled = true
+	nx.exitNode.activeOrigin = "active"
+	require.Equal([]string{"100.64.0.1/32", "0.0.0.0/0"}, nx.exitNodeAllowedIPs(active, []string{"100.64.0.1/32"}))
+	require.Equal([]string{"100.64.0.2/32"}, nx.exitNodeAllowedIPs(other, []string{"100.64.0.2/32", "0.0.0.0/0"}))
+	require.Equal([]string{"100.64.0.3/32", "10.10.0.0/24"}, nx.exitNodeAllowedIPs(peer, []string{"100.64.0.3/32", "10.10.0.0/24"}))
+
+	nx.ipv6Supported = true
+	require.Equal([]string{"100.64.0.1/32", "0.0.0.0/0", "::/0"}, nx.exitNodeAllowedIPs(active, []string{"100.64.0.1/32", "0.0.0.0/0"}))
+}
+
+func TestExitNodeHandshakeStale(t *testing.T) {
+	require := require.New(t)
+	now := time.Now()
+	activeSince := now.Add(-time.Hour)
+
+	require.False(exitNodeHandshakeStale(now.Add(-time.Minute), activeSince, now))
+	require.True(exitNodeHandshakeStale(now.Add(-3*time.Minute), activeSince, now))
+	require.True(exitNodeHandshakeStale(time.Time{}, activeSince, now))
+	// a newly selected exit node gets the time to complete a handshake
+	require.False(exitNodeHandshakeStale(time.Time{}, now.Add(-time.Minute), now))
+}
+
+func TestNextExitNodeOrigin(t *testing.T) {
+	require := require.New(t)
+	device := func(hostname string, healthy bool) deviceCacheEntry {
+		d := deviceCacheEntry{
+			device: public.ModelsDevice{Hostname: hostname, PublicKey: hostname, AdvertiseCidrs: []string{"0.0.0.0/0"}},
+		}
+		d.peerHealthy = healthy
+		return d
+	}
+	nx := &Nexodus{
+		deviceCache: map[string]deviceCacheEntry{
+			"a": device("a", true),
+			"b": device("b", false),
+			"c": device("c", true),
+		},
+		wgConfig: wgConfig{Peers: map[string]wgPeerConfig{
+			"a": {PublicKey: "a"},
+			"b": {PublicKey: "b"},
+			"c": {PublicKey: "c"},
+		}},
+	}
+	nx.exitNode.exitNodeClientEnabled = true
+
+	// the unhealthy exit node b is skipped
+	nx.exitNode.activeOrigin = "a"
+	next, found := nx.nextExitNodeOrigin()
+	require.True(found)
+	require.Equal("c", next.Hostname)
+
+	// the exit nodes wrap around
+	nx.exitNode.activeOrigin = "c"
+	next, found = nx.nextExitNodeOrigin()
+	require.True(found)
+	require.Equal("a", next.Hostname)
+
+	nx.deviceCache["a"] = device("a", false)
+	_, found = nx.nextExitNodeOrigin()
+	require.False(found)
+}
+
+func TestParseExitNodeExcept(t *testing.T) {
+	tests := []struct {
+		except   string
+		expected string
+		wantErr  bool
+	}{
+		{except: "10.1.2.0/24", expected: "10.1.2.0/24"},
+		{except: "10.1.2.3/24", expected: "10.1.2.0/24"},
+		{except: "192.168.1.10", expected: "192.168.1.10/32"},
+		{except: "2001:db8::1", expected: "2001:db8::1/128"},
+		{except: "Example.COM.", expected: "example.com"},
+		{except: "0.0.0.0/0", wantErr: true},
+		{except: "::/0", wantErr: true},
+		{except: "not a domain", wantErr: true},
+		{except: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.except, func(t *testing.T) {
+			except, err := parseExitNodeExcept(tt.except)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, except)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package nexodus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const featureFlagsInterval = 10 * time.Minute
+
+// exitNodeFlag is the feature flag the exit node client is progressively enabled with, the exit node client is
+// enabled when the apiserver does not define it.
+const exitNodeFlag = "exit-node"
+
+// featureFlags are the values of the feature flags of the apiserver for the organization of the device
+type featureFlags struct {
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+// fetchFeatureFlags reads the values of the feature flags for the organization of the device
+func (nx *Nexodus) fetchFeatureFlags(ctx context.Context) error {
+	values, _, err := nx.client.FFlagApi.ListFeatureFlags(ctx).OrganizationId(nx.vpc.OrganizationId).Execute()
+	if err != nil {
+		return err
+	}
+	nx.featureFlags.mu.Lock()
+	defer nx.featureFlags.mu.Unlock()
+	nx.featureFlags.values = values
+	return nil
+}
+
+// featureEnabled returns the value of the feature flag for the organization of the device, defaultValue when the
+// apiserver does not define the flag or the flags could not be fetched yet.
+func (nx *Nexodus) featureEnabled(name string, defaultValue bool) bool {
+	nx.featureFlags.mu.RLock()
+	defer nx.featureFlags.mu.RUnlock()
+	if enabled, found := nx.featureFlags.values[name]; found {
+		return enabled
+	}
+	return defaultValue
+}
+
+// runFeatureFlags refreshes the feature flags every featureFlagsInterval, so the features rolled out to the
+// organization are picked up without restarting the agent.
+func (nx *Nexodus) runFeatureFlags(ctx context.Context) {
+	timer := time.NewTimer(util.Jitter(featureFlagsInterval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := nx.fetchFeatureFlags(ctx); err != nil {
+				nx.logger.Debugf("failed to fetch the feature flags: %v", err)
+			}
+			timer.Reset(util.Jitter(featureFlagsInterval))
+		}
+	}
+}
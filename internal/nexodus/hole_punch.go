@@ -0,0 +1,286 @@
+package nexodus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/stun"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	peeringMethodHolePunch = "hole-punch"
+	// the prefix of the device metadata keys the service signals hole punches with
+	holePunchMetadataPrefix = "hole-punch:"
+	// a hole punch with a peer is requested at most this often
+	holePunchInterval = 5 * time.Minute
+	// hole punches signaled longer ago than this are ignored
+	holePunchExpiry = 30 * time.Second
+	// how long the devices send probes to each other from the start of a hole punch
+	holePunchDuration      = 5 * time.Second
+	holePunchProbeInterval = 250 * time.Millisecond
+	// the number of ports of the peer sprayed by a birthday hole punch
+	holePunchBirthdayPorts = 256
+	// the ports after the reflexive port of the peer are tried first, NATs often allocate their ports sequentially
+	holePunchPortWindow = 32
+
+	holePunchMagic     uint32 = 0x4e584850 // "NXHP", not a valid wireguard message type
+	holePunchTypeProbe byte   = 1
+	holePunchTypeAck   byte   = 2
+)
+
+var errHolePunchUnsupported = errors.New("hole punching is not supported on this platform")
+
+// holePunch tracks the hole punches requested and run by this device, and the endpoints they found
+type holePunch struct {
+	lock sync.Mutex
+	// the time a hole punch was last requested with a peer, by public key
+	requested map[string]time.Time
+	// the hole punches handled, by id
+	handled map[string]time.Time
+	// the endpoints opened by hole punches, by public key
+	endpoints map[string]string
+}
+
+// holePunchEndpoint returns the endpoint of the peer opened by a hole punch
+func (nx *Nexodus) holePunchEndpoint(publicKey string) string {
+	nx.holePunch.lock.Lock()
+	defer nx.holePunch.lock.Unlock()
+	return nx.holePunch.endpoints[publicKey]
+}
+
+// forgetHolePunch forgets the endpoint of the peer opened by a hole punch once peering with it failed
+func (nx *Nexodus) forgetHolePunch(publicKey string) {
+	nx.holePunch.lock.Lock()
+	defer nx.holePunch.lock.Unlock()
+	delete(nx.holePunch.endpoints, publicKey)
+}
+
+// buildHolePunchPeer peers directly with a peer using the endpoint opened by a hole punch
+func buildHolePunchPeer(nx *Nexodus, device public.ModelsDevice, _ []string, _, _, _ string) wgPeerConfig {
+	device.AllowedIps = append(device.AllowedIps, device.AdvertiseCidrs...)
+	return wgPeerConfig{
+		PublicKey:           device.PublicKey,
+		Endpoint:            nx.holePunchEndpoint(device.PublicKey),
+		AllowedIPs:          device.AllowedIps,
+		PersistentKeepAlive: persistentKeepalive,
+	}
+}
+
+// holePunchCandidate returns true if a hole punch could open a direct path to the peer, which is reached through
+// a relay. At most one of the devices can be behind a NAT with an endpoint dependent mapping, the other one sprays
+// the ports of its NAT. Devices that are relay-only or on a network blocking UDP are always relayed.
+func (nx *Nexodus) holePunchCandidate(device public.ModelsDevice) bool {
+	if nx.relay || device.Relay || nx.relayOnly || nx.udpBlocked || nx.os != Linux.String() || device.Os != Linux.String() {
+		return false
+	}
+	selfDependent := nx.natBehavior.Mapping.EndpointDependent()
+	peerDependent := stun.Mapping(device.NatType).EndpointDependent()
+	if nx.symmetricNat && !selfDependent || device.SymmetricNat && !peerDependent {
+		return false
+	}
+	return (selfDependent || peerDependent) && !(selfDependent && peerDependent)
+}
+
+// maybeRequestHolePunch asks the service to coordinate a hole punch with a peer reached through a relay. Only the
+// device with the lower public key requests it, so that both devices do not request one at the same time.
+func (nx *Nexodus) maybeRequestHolePunch(device public.ModelsDevice) {
+	if nx.wireguardPubKey > device.PublicKey || !nx.holePunchCandidate(device) {
+		return
+	}
+	nx.holePunch.lock.Lock()
+	defer nx.holePunch.lock.Unlock()
+	if time.Since(nx.holePunch.requested[device.PublicKey]) < holePunchInterval {
+		return
+	}
+	if nx.holePunch.requested == nil {
+		nx.holePunch.requested = map[string]time.Time{}
+	}
+	nx.holePunch.requested[device.PublicKey] = time.Now()
+
+	deviceId := nx.deviceId
+	go func() {
+		_, _, err := nx.client.DevicesApi.CreateHolePunch(context.Background(), deviceId).
+			HolePunch(public.ModelsAddHolePunch{PeerId: device.Id}).Execute()
+		if err != nil {
+			nx.logger.Debugf("failed to request a hole punch with peer [ %s ]: %v", device.PublicKey, err)
+		}
+	}()
+}
+
+// reconcileHolePunches runs the hole punches the service signaled to this device
+func (nx *Nexodus) reconcileHolePunches(ctx context.Context) {
+	items, _, err := nx.holePunchInformer.Execute()
+	if err != nil {
+		nx.logger.Debugf("failed to list the hole punches: %v", err)
+		return
+	}
+
+	now := time.Now()
+	nx.holePunch.lock.Lock()
+	defer nx.holePunch.lock.Unlock()
+	if nx.holePunch.handled == nil {
+		nx.holePunch.handled = map[string]time.Time{}
+	}
+	for id, at := range nx.holePunch.handled {
+		if now.Sub(at) > holePunchExpiry {
+			delete(nx.holePunch.handled, id)
+		}
+	}
+	for _, item := range items {
+		if item.DeviceId != nx.deviceId {
+			continue
+		}
+		var hp public.ModelsHolePunch
+		if err := util.JsonUnmarshal(item.Value, &hp); err != nil {
+			nx.logger.Debugf("invalid hole punch %s: %v", item.Key, err)
+			continue
+		}
+		startAt, err := time.Parse(time.RFC3339, hp.StartAt)
+		if err != nil || now.Sub(startAt) > holePunchExpiry {
+			continue
+		}
+		if _, ok := nx.holePunch.handled[hp.Id]; ok {
+			continue
+		}
+		nx.holePunch.handled[hp.Id] = now
+
+		key := item.Key
+		util.GoWithWaitGroup(nx.nexWg, func() {
+			nx.runHolePunch(ctx, hp, startAt)
+			// the hole punch was handled
+			if _, err := nx.client.DevicesApi.DeleteDeviceMetadataKey(context.Background(), nx.deviceId, key).Execute(); err != nil {
+				nx.logger.Debugf("failed to delete the hole punch %s: %v", key, err)
+			}
+		})
+	}
+}
+
+// runHolePunch sends probes to the peer of the hole punch from the wireguard port, starting at the same time as the
+// peer, and acknowledges the probes of the peer. The address the peer is heard from is the endpoint used to peer
+// with it. When the peer is behind a NAT with an endpoint dependent mapping, the probes are sent to many of its
+// ports, one of which is likely the port its NAT mapped for the probes the peer sends to this device.
+func (nx *Nexodus) runHolePunch(ctx context.Context, hp public.ModelsHolePunch, startAt time.Time) {
+	id, err := uuid.Parse(hp.Id)
+	if err != nil {
+		return
+	}
+	_, reflexiveIP4 := nx.extractLocalAndReflexiveIP(public.ModelsDevice{Endpoints: hp.PeerEndpoints})
+	peerAddr, err := netip.ParseAddrPort(reflexiveIP4)
+	if err != nil {
+		nx.logger.Debugf("hole punch with peer [ %s ] skipped, it has no reflexive address", hp.PeerPublicKey)
+		return
+	}
+	conn, err := newHolePunchConn(nx.listenPort)
+	if err != nil {
+		nx.logger.Debugf("hole punch with peer [ %s ] skipped: %v", hp.PeerPublicKey, err)
+		return
+	}
+	defer conn.close()
+
+	targets := []netip.AddrPort{peerAddr}
+	if hp.Birthday {
+		// #nosec G404
+		targets = holePunchTargets(peerAddr, holePunchBirthdayPorts, rand.New(rand.NewSource(time.Now().UnixNano())))
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Until(startAt)):
+	}
+	deadline := time.Now().Add(holePunchDuration)
+
+	found := make(chan netip.AddrPort, 1)
+	go func() {
+		for {
+			from, payload, err := conn.receive(deadline)
+			if err != nil {
+				return
+			}
+			kind, ok := parseHolePunchMessage(payload, id)
+			if !ok {
+				continue
+			}
+			if kind == holePunchTypeProbe {
+				_ = conn.send(from, holePunchMessage(holePunchTypeAck, id))
+			}
+			select {
+			case found <- from:
+			default:
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(holePunchProbeInterval)
+	defer ticker.Stop()
+	for {
+		for _, target := range targets {
+			if err := conn.send(target, holePunchMessage(holePunchTypeProbe, id)); err != nil {
+				nx.logger.Debugf("failed to send a hole punch probe to %s: %v", target, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case endpoint := <-found:
+			nx.logger.Infof("Hole punch with peer [ %s ] succeeded, peering with it at %s", hp.PeerPublicKey, endpoint)
+			nx.holePunch.lock.Lock()
+			if nx.holePunch.endpoints == nil {
+				nx.holePunch.endpoints = map[string]string{}
+			}
+			nx.holePunch.endpoints[hp.PeerPublicKey] = endpoint.String()
+			nx.holePunch.lock.Unlock()
+			return
+		case <-ticker.C:
+			if time.Now().After(deadline) {
+				nx.logger.Debugf("hole punch with peer [ %s ] failed", hp.PeerPublicKey)
+				return
+			}
+		}
+	}
+}
+
+// holePunchTargets returns the addresses a birthday hole punch sends its probes to: the ports after the reflexive
+// port of the peer, then random ports.
+func holePunchTargets(peer netip.AddrPort, count int, r *rand.Rand) []netip.AddrPort {
+	seen := map[uint16]bool{}
+	var targets []netip.AddrPort
+	add := func(port int) {
+		if port < 1024 || port > 65535 || seen[uint16(port)] {
+			return
+		}
+		seen[uint16(port)] = true
+		targets = append(targets, netip.AddrPortFrom(peer.Addr(), uint16(port)))
+	}
+	for i := 0; i < holePunchPortWindow && len(targets) < count; i++ {
+		add(int(peer.Port()) + i)
+	}
+	for len(targets) < count {
+		add(1024 + r.Intn(65536-1024))
+	}
+	return targets
+}
+
+// holePunchMessage builds a hole punch message: the magic, the message type and the id of the hole punch
+func holePunchMessage(kind byte, id uuid.UUID) []byte {
+	msg := binary.BigEndian.AppendUint32(nil, holePunchMagic)
+	msg = append(msg, kind)
+	return append(msg, id[:]...)
+}
+
+// parseHolePunchMessage returns the type of the message if it belongs to the hole punch
+func parseHolePunchMessage(msg []byte, id uuid.UUID) (byte, bool) {
+	if len(msg) != 21 || binary.BigEndian.Uint32(msg) != holePunchMagic || !bytes.Equal(msg[5:], id[:]) {
+		return 0, false
+	}
+	return msg[4], msg[4] == holePunchTypeProbe || msg[4] == holePunchTypeAck
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package nexodus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/net/ipv4"
+)
+
+// holePunchConn sends and receives the hole punch messages with the source port of the wireguard socket,
+// using a raw socket since the port is owned by wireguard.
+type holePunchConn struct {
+	conn *ipv4.PacketConn
+	port uint16
+}
+
+func newHolePunchConn(port int) (*holePunchConn, error) {
+	raw, err := net.ListenPacket("ip4:udp", "0.0.0.0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the hole punch messages: %w", err)
+	}
+	conn := ipv4.NewPacketConn(raw)
+	filter, err := holePunchBpfFilter(uint16(port))
+	if err != nil {
+		_ = raw.Close()
+		return nil, err
+	}
+	if err := conn.SetBPF(filter); err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("bpf filter attach error: %w", err)
+	}
+	return &holePunchConn{conn: conn, port: uint16(port)}, nil
+}
+
+func (c *holePunchConn) send(to netip.AddrPort, payload []byte) error {
+	msg := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(msg[0:], c.port)
+	binary.BigEndian.PutUint16(msg[2:], to.Port())
+	binary.BigEndian.PutUint16(msg[4:], uint16(8+len(payload)))
+	// a zero checksum is valid for UDP over IPv4
+	_, err := c.conn.WriteTo(append(msg, payload...), nil, &net.IPAddr{IP: to.Addr().AsSlice()})
+	return err
+}
+
+// receive returns the source address and the payload of the next hole punch message
+func (c *holePunchConn) receive(deadline time.Time) (netip.AddrPort, []byte, error) {
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return netip.AddrPort{}, nil, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, _, src, err := c.conn.ReadFrom(buf)
+		if err != nil {
+			return netip.AddrPort{}, nil, err
+		}
+		ipAddr, ok := src.(*net.IPAddr)
+		if !ok || n < 8 {
+			continue
+		}
+		addr, ok := netip.AddrFromSlice(ipAddr.IP)
+		if !ok {
+			continue
+		}
+		port := binary.BigEndian.Uint16(buf[0:])
+		return netip.AddrPortFrom(addr.Unmap(), port), append([]byte{}, buf[8:n]...), nil
+	}
+}
+
+func (c *holePunchConn) close() {
+	_ = c.conn.Close()
+}
+
+// holePunchBpfFilter only accepts the hole punch messages sent to the port
+func holePunchBpfFilter(port uint16) ([]bpf.RawInstruction, error) {
+	const (
+		udpOff     = 5 * 4
+		payloadOff = udpOff + 2*4
+	)
+	r, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: udpOff + 2, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(port), SkipFalse: 3},
+		bpf.LoadAbsolute{Off: payloadOff, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: holePunchMagic, SkipFalse: 1},
+		bpf.RetConstant{Val: 0xffff},
+		bpf.RetConstant{Val: 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hole punch bpf filter failed: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,72 @@
+package nexodus
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+func TestHolePunchTargets(t *testing.T) {
+	require := require.New(t)
+	peer := netip.MustParseAddrPort("198.51.100.1:40000")
+
+	targets := holePunchTargets(peer, holePunchBirthdayPorts, rand.New(rand.NewSource(1)))
+	require.Len(targets, holePunchBirthdayPorts)
+	// the ports after the reflexive port come first
+	require.Equal(peer, targets[0])
+	require.Equal(netip.MustParseAddrPort("198.51.100.1:40031"), targets[holePunchPortWindow-1])
+	seen := map[netip.AddrPort]bool{}
+	for _, target := range targets {
+		require.False(seen[target], "duplicate target %s", target)
+		seen[target] = true
+		require.Equal(peer.Addr(), target.Addr())
+		require.GreaterOrEqual(target.Port(), uint16(1024))
+	}
+}
+
+func TestHolePunchMessage(t *testing.T) {
+	require := require.New(t)
+	id := uuid.New()
+
+	kind, ok := parseHolePunchMessage(holePunchMessage(holePunchTypeAck, id), id)
+	require.True(ok)
+	require.Equal(holePunchTypeAck, kind)
+
+	// messages of other hole punches are ignored
+	_, ok = parseHolePunchMessage(holePunchMessage(holePunchTypeProbe, uuid.New()), id)
+	require.False(ok)
+	_, ok = parseHolePunchMessage([]byte("not a hole punch message"), id)
+	require.False(ok)
+}
+
+func TestHolePunchCandidate(t *testing.T) {
+	require := require.New(t)
+	nx := &Nexodus{os: Linux.String()}
+	peer := public.ModelsDevice{Os: Linux.String()}
+
+	// neither device is behind a symmetric NAT
+	require.False(nx.holePunchCandidate(peer))
+
+	peer.SymmetricNat = true
+	peer.NatType = string(stun.MappingAddressAndPortDependent)
+	require.True(nx.holePunchCandidate(peer))
+
+	// both devices are behind a symmetric NAT
+	nx.symmetricNat = true
+	nx.natBehavior = stun.NatBehavior{Mapping: stun.MappingAddressDependent}
+	require.False(nx.holePunchCandidate(peer))
+
+	// a relay-only peer is always relayed
+	peer.NatType = string(stun.MappingEndpointIndependent)
+	require.True(nx.holePunchCandidate(public.ModelsDevice{Os: Linux.String()}))
+	require.False(nx.holePunchCandidate(peer))
+
+	nx.os = Windows.String()
+	require.False(nx.holePunchCandidate(public.ModelsDevice{Os: Linux.String()}))
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"net/netip"
+	"time"
+)
+
+type holePunchConn struct{}
+
+func newHolePunchConn(_ int) (*holePunchConn, error) {
+	return nil, errHolePunchUnsupported
+}
+
+func (c *holePunchConn) send(_ netip.AddrPort, _ []byte) error {
+	return errHolePunchUnsupported
+}
+
+func (c *holePunchConn) receive(_ time.Time) (netip.AddrPort, []byte, error) {
+	return netip.AddrPort{}, nil, errHolePunchUnsupported
+}
+
+func (c *holePunchConn) close() {}
@@ -0,0 +1,60 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+const peeringMethodDirectIPv6 = "direct-ipv6"
+
+// discoverIPv6Endpoint discovers the global IPv6 endpoint of this device, relays are peered over IPv4
+func (nx *Nexodus) discoverIPv6Endpoint() (netip.AddrPort, error) {
+	if nx.relay || nx.relayDerp {
+		return netip.AddrPort{}, nil
+	}
+	return stun.DiscoverIPv6Endpoint(nx.logger, nx.listenPort)
+}
+
+// ipv6Endpoint returns the IPv6 endpoint of this device as published on the device, empty when it has none
+func (nx *Nexodus) ipv6Endpoint() string {
+	if !nx.nodeReflexiveAddressIPv6.IsValid() {
+		return ""
+	}
+	return nx.nodeReflexiveAddressIPv6.String()
+}
+
+// reconcileIPv6Endpoint updates the IPv6 endpoint of this device once it changed. A failed discovery keeps the
+// current endpoint, it is only cleared when the addresses of the host change.
+func (nx *Nexodus) reconcileIPv6Endpoint(deviceID string) error {
+	endpoint, err := nx.discoverIPv6Endpoint()
+	if err != nil || endpoint == nx.nodeReflexiveAddressIPv6 {
+		return nil
+	}
+	nx.logger.Infof("detected the IPv6 endpoint of this device %s changed from %s to %s, updating peers", deviceID, nx.ipv6Endpoint(), endpoint)
+
+	if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(context.Background(), deviceID, map[string]interface{}{
+		"endpoint_ipv6": endpoint.String(),
+	}); err != nil {
+		return fmt.Errorf("failed to update the IPv6 endpoint of this device, retrying in 20s: %w", err)
+	}
+	nx.nodeReflexiveAddressIPv6 = endpoint
+	if err := nx.reconcileDeviceCache(); err != nil {
+		nx.logger.Debugf("reconcile failed %v", err)
+	}
+	return nil
+}
+
+// buildIPv6Peer peers with the IPv6 endpoint of a peer, both devices have global IPv6 connectivity
+func buildIPv6Peer(nx *Nexodus, device public.ModelsDevice, _ []string, _, _, _ string) wgPeerConfig {
+	device.AllowedIps = append(device.AllowedIps, device.AdvertiseCidrs...)
+	return wgPeerConfig{
+		PublicKey:           device.PublicKey,
+		Endpoint:            device.EndpointIpv6,
+		AllowedIPs:          device.AllowedIps,
+		PersistentKeepAlive: persistentKeepalive,
+	}
+}
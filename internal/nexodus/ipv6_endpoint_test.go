@@ -0,0 +1,56 @@
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestIPv6Peering(t *testing.T) {
+	require := require.New(t)
+	zLogger, _ := zap.NewDevelopment()
+	nx := &Nexodus{
+		vpc: &public.ModelsVPC{
+			Ipv4Cidr: "100.64.0.0/10",
+			Ipv6Cidr: "200::/64",
+		},
+		nodeReflexiveAddressIPv4: netip.MustParseAddrPort("1.1.1.1:1234"),
+		nodeReflexiveAddressIPv6: netip.MustParseAddrPort("[2001:db8::1]:51820"),
+		logger:                   zLogger.Sugar(),
+	}
+	d := deviceCacheEntry{
+		device: public.ModelsDevice{
+			PublicKey:  "peer",
+			AllowedIps: []string{"100.64.0.2/32"},
+			Endpoints: []public.ModelsEndpoint{
+				{Source: "local", Address: "192.168.2.20:51820", Priority: candidatePriorityLocal},
+				{Source: "stun:stun.example.com:3478", Address: "2.2.2.2:4321", Priority: candidatePriorityReflexive},
+			},
+			EndpointIpv6: "[2001:db8::2]:51820",
+		},
+	}
+	nx.peeringReset(&d)
+
+	// the IPv6 endpoint is tried before the IPv4 reflexive address
+	peer, method, index := nx.rebuildPeerConfig(&d, false, false)
+	require.Equal(peeringMethodDirectIPv6, method)
+	require.Equal("[2001:db8::2]:51820", peer.Endpoint)
+
+	d.peeringMethod = method
+	d.peeringMethodIndex = index
+	d.peeringTime = time.Now().Add(-peeringTimeout - time.Second)
+	peer, method, _ = nx.rebuildPeerConfig(&d, false, false)
+	require.Equal(peeringMethodReflexive, method)
+	require.Equal("2.2.2.2:4321", peer.Endpoint)
+
+	// this device has no IPv6 endpoint
+	nx.nodeReflexiveAddressIPv6 = netip.AddrPort{}
+	nx.peeringReset(&d)
+	_, method, _ = nx.rebuildPeerConfig(&d, false, false)
+	require.Equal(peeringMethodReflexive, method)
+}
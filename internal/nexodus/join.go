@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/nexodus-io/nexodus/internal/api/public"
 )
@@ -17,10 +18,15 @@ func (nx *Nexodus) createOrUpdateDeviceOperation(userID string, endpoints []publ
 		PublicKey:       nx.wireguardPubKey,
 		AdvertiseCidrs:  nx.advertiseCidrs,
 		SymmetricNat:    nx.symmetricNat,
+		NatType:         string(nx.natBehavior.Mapping),
+		NatHairpin:      nx.natBehavior.Hairpin,
 		Hostname:        nx.hostname,
 		Relay:           nx.relay || nx.relayDerp,
 		Os:              nx.os,
 		Endpoints:       endpoints,
+		EndpointIpv6:    nx.ipv6Endpoint(),
+		ListenPort:      int32(nx.listenPort),
+		DisableIpv6:     nx.disableIPv6,
 	}
 
 	if len(nx.requestedIP) > 0 {
@@ -39,14 +45,7 @@ func (nx *Nexodus) createOrUpdateDeviceOperation(userID string, endpoints []publ
 		if errors.As(err, &apiError) {
 			switch model := apiError.Model().(type) {
 			case public.ModelsConflictsError:
-				d, resp, err = nx.client.DevicesApi.UpdateDevice(context.Background(), model.Id).Update(public.ModelsUpdateDevice{
-					VpcId:          nx.vpc.Id,
-					AdvertiseCidrs: nx.advertiseCidrs,
-					SymmetricNat:   nx.symmetricNat,
-					Hostname:       nx.hostname,
-					Endpoints:      endpoints,
-					Relay:          nx.relay || nx.relayDerp,
-				}).Execute()
+				d, resp, err = nx.client.DevicesApi.UpdateDevice(context.Background(), model.Id).Update(nx.deviceUpdate(endpoints)).Execute()
 				deviceOperationMsg = "Reconnected as device"
 				if err != nil {
 					respText := ""
@@ -83,6 +82,55 @@ func (nx *Nexodus) createOrUpdateDeviceOperation(userID string, endpoints []publ
 	return *d, deviceOperationMsg, nil
 }
 
+// updateRegisteredDeviceOperation updates the device of the cached registration, the single request also validates
+// that the service still accepts the registration. errRegistrationRejected is returned when it does not.
+func (nx *Nexodus) updateRegisteredDeviceOperation(endpoints []public.ModelsEndpoint) (public.ModelsDevice, string, error) {
+	d, resp, err := nx.client.DevicesApi.UpdateDevice(context.Background(), nx.registration.DeviceID).Update(nx.deviceUpdate(endpoints)).Execute()
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+				return public.ModelsDevice{}, "", fmt.Errorf("%w: %s", errRegistrationRejected, resp.Status)
+			}
+		}
+		return public.ModelsDevice{}, "", fmt.Errorf("error updating device: %w", err)
+	}
+	if d.PublicKey != nx.wireguardPubKey {
+		return public.ModelsDevice{}, "", fmt.Errorf("%w: the device has another public key", errRegistrationRejected)
+	}
+
+	resp, err = nx.updateDeviceRelayMetadata(d.Id)
+	if err != nil {
+		respText := ""
+		if resp != nil {
+			bytes, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return public.ModelsDevice{}, "", fmt.Errorf("error updating device metadata: %w - %s", err, resp.Status)
+			}
+			respText = string(bytes)
+		}
+		return public.ModelsDevice{}, "", fmt.Errorf("error updating device metadata: %w - %s", err, respText)
+	}
+
+	return *d, "Reconnected as device", nil
+}
+
+// deviceUpdate is the update of an existing device with the current configuration of nexd
+func (nx *Nexodus) deviceUpdate(endpoints []public.ModelsEndpoint) public.ModelsUpdateDevice {
+	return public.ModelsUpdateDevice{
+		VpcId:          nx.vpc.Id,
+		AdvertiseCidrs: nx.advertiseCidrs,
+		SymmetricNat:   nx.symmetricNat,
+		NatType:        string(nx.natBehavior.Mapping),
+		NatHairpin:     nx.natBehavior.Hairpin,
+		Hostname:       nx.hostname,
+		Endpoints:      endpoints,
+		EndpointIpv6:   nx.ipv6Endpoint(),
+		Relay:          nx.relay || nx.relayDerp,
+		ListenPort:     int32(nx.listenPort),
+	}
+}
+
 func (nx *Nexodus) updateDeviceRelayMetadata(deviceId string) (*http.Response, error) {
 	if nx.relay || nx.relayDerp {
 		var rtype interface{}
@@ -97,6 +145,11 @@ func (nx *Nexodus) updateDeviceRelayMetadata(deviceId string) (*http.Response, e
 		} else {
 			relayMetadata = map[string]interface{}{"type": rtype}
 		}
+		// the load of the relay is used by the service to assign relays to the devices behind a symmetric NAT
+		peers, rxRate, txRate := nx.relayLoad(time.Now())
+		relayMetadata["peers"] = peers
+		relayMetadata["rxrate"] = rxRate
+		relayMetadata["txrate"] = txRate
 
 		md, resp, err := nx.client.DevicesApi.UpdateDeviceMetadataKey(context.Background(), deviceId, "relay").Value(relayMetadata).Execute()
 		nx.logger.Debugf("Updated relay device %s metadata to: %v", deviceId, md)
@@ -78,10 +78,15 @@ func cksum(bs []byte) uint16 {
 }
 
 func (nx *Nexodus) doPing(host string, i uint64, waitFor time.Duration) (string, error) {
+	return nx.doPingSize(host, i, waitFor, PACKETSIZE)
+}
+
+// doPingSize sends an echo request of size bytes, not counting the IP header
+func (nx *Nexodus) doPingSize(host string, i uint64, waitFor time.Duration, size int) (string, error) {
 	if nx.userspaceMode {
-		return nx.pingUS(host, i, waitFor)
+		return nx.pingUS(host, i, waitFor, size)
 	} else {
-		return nx.pingOS(host, i, waitFor)
+		return nx.pingOS(host, i, waitFor, size)
 	}
 }
 
@@ -90,7 +95,7 @@ const (
 	protocolIPv6ICMP = 58
 )
 
-func (nx *Nexodus) pingUS(host string, i uint64, waitFor time.Duration) (string, error) {
+func (nx *Nexodus) pingUS(host string, i uint64, waitFor time.Duration, size int) (string, error) {
 	var networkType string
 	var icmpType icmp.Type
 	var icmpProto int
@@ -108,9 +113,14 @@ func (nx *Nexodus) pingUS(host string, i uint64, waitFor time.Duration) (string,
 	if err != nil {
 		return "", err
 	}
+	data := []byte("pingity ping")
+	if size > len(data)+8 {
+		// pad the payload after the 8 bytes of the icmp header
+		data = append(data, make([]byte, size-len(data)-8)...)
+	}
 	requestPing := icmp.Echo{
 		Seq:  int(i),
-		Data: []byte("pingity ping"),
+		Data: data,
 	}
 	icmpBytes, _ := (&icmp.Message{Type: icmpType, Code: 0, Body: &requestPing}).Marshal(nil)
 	err = socket.SetReadDeadline(time.Now().Add(waitFor))
@@ -145,7 +155,7 @@ func (nx *Nexodus) pingUS(host string, i uint64, waitFor time.Duration) (string,
 	return fmt.Sprintf("%.2fms", roundedLatency), nil
 }
 
-func (nx *Nexodus) pingOS(host string, i uint64, waitFor time.Duration) (string, error) {
+func (nx *Nexodus) pingOS(host string, i uint64, waitFor time.Duration, size int) (string, error) {
 	var v6Host bool
 	var netname string
 
@@ -166,7 +176,7 @@ func (nx *Nexodus) pingOS(host string, i uint64, waitFor time.Duration) (string,
 		nx.logger.Debugf("probe error: %v", err)
 	}
 
-	msg := make([]byte, PACKETSIZE)
+	msg := make([]byte, size)
 	if v6Host {
 		msg[0] = ICMP6_TYPE_ECHO_REQUEST
 	} else {
@@ -183,7 +193,7 @@ func (nx *Nexodus) pingOS(host string, i uint64, waitFor time.Duration) (string,
 	if err = c.SetDeadline(time.Now().Add(waitFor)); err != nil {
 		nx.logger.Debugf("probe error: %v", err)
 	}
-	rmsg := make([]byte, PACKETSIZE+256)
+	rmsg := make([]byte, size+256)
 	start := time.Now()
 	amt, err := c.Read(rmsg[:])
 	if err != nil {
@@ -1,8 +1,11 @@
 package nexodus
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/nexodus-io/nexodus/internal/api/public"
+
 	"go4.org/mem"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 	"tailscale.com/types/key"
@@ -46,3 +49,31 @@ func (nx *Nexodus) handleKeys() error {
 	nx.wireguardPvtKey = state.PrivateKey
 	return nil
 }
+
+// rotateKeys generates a new key pair for the registered device and submits its public key to the apiserver,
+// the device keeps its id and tunnel addresses, and its peers are sent the new key.
+func (nx *Nexodus) rotateKeys(ctx context.Context) error {
+	wgKey, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate private key: %w", err)
+	}
+	publicKey := wgKey.PublicKey().String()
+	_, _, err = nx.client.DevicesApi.RotateDeviceKey(ctx, nx.deviceId).
+		Key(public.ModelsRotateDeviceKey{PublicKey: publicKey}).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to rotate the device key: %w", err)
+	}
+
+	state := nx.stateStore.State()
+	state.PublicKey = publicKey
+	state.PrivateKey = wgKey.String()
+	if err := nx.stateStore.Store(); err != nil {
+		return fmt.Errorf("failed store the rotated keys, the device must be registered again: %w", err)
+	}
+	nx.nexRelay.privateKey = key.NodePrivateFromRaw32(mem.B(wgKey[:])) //nolint:staticcheck
+	nx.wireguardPubKey = state.PublicKey
+	nx.wireguardPvtKey = state.PrivateKey
+	nx.logger.Infof("Rotated the keys of the device, the new public key is [ %s ]", publicKey)
+	return nil
+}
@@ -0,0 +1,192 @@
+package nexodus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const (
+	// the udp port the lan discovery beacons are broadcast to
+	lanDiscoveryPort = 51819
+	// how often this device broadcasts its local endpoint on the lan
+	lanDiscoveryInterval = 10 * time.Second
+	// a peer that has not broadcast a beacon for this long is no longer considered on the lan
+	lanDiscoveryTimeout = 3 * lanDiscoveryInterval
+)
+
+// lanBeacon is the lan discovery beacon, announcing the local endpoint of a device to the devices on the same lan
+type lanBeacon struct {
+	PublicKey string `json:"public-key"`
+	VpcId     string `json:"vpc-id"`
+	Endpoint  string `json:"endpoint"`
+}
+
+// lanPeer is a peer discovered on the lan
+type lanPeer struct {
+	endpoint string
+	lastSeen time.Time
+}
+
+type lanDiscovery struct {
+	enabled bool
+	lock    sync.Mutex
+	// the peers discovered on the lan by public key
+	peers map[string]lanPeer
+}
+
+// runLanDiscovery broadcasts the local endpoint of this device on the lan and listens to the beacons of the other devices
+func (nx *Nexodus) runLanDiscovery(ctx context.Context) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: lanDiscoveryPort})
+	if err != nil {
+		nx.logger.Warnf("LAN discovery is disabled, failed to listen on udp port %d: %v", lanDiscoveryPort, err)
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	go nx.receiveLanBeacons(ctx, conn)
+
+	ticker := time.NewTicker(lanDiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		nx.sendLanBeacon(conn)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendLanBeacon broadcasts the local endpoint of this device to the subnet of its local address
+func (nx *Nexodus) sendLanBeacon(conn *net.UDPConn) {
+	if nx.vpc == nil || nx.endpointLocalAddress == "" {
+		return
+	}
+	beacon, err := json.Marshal(lanBeacon{
+		PublicKey: nx.wireguardPubKey,
+		VpcId:     nx.vpc.Id,
+		Endpoint:  net.JoinHostPort(nx.endpointLocalAddress, fmt.Sprintf("%d", nx.listenPort)),
+	})
+	if err != nil {
+		nx.logger.Debugf("failed to encode the lan discovery beacon: %v", err)
+		return
+	}
+	broadcast := &net.UDPAddr{IP: lanBroadcastAddress(nx.endpointLocalAddress), Port: lanDiscoveryPort}
+	if _, err := conn.WriteToUDP(beacon, broadcast); err != nil {
+		nx.logger.Debugf("failed to send the lan discovery beacon to %s: %v", broadcast, err)
+	}
+}
+
+// lanBroadcastAddress returns the broadcast address of the subnet of the local address, the limited broadcast
+// address if the subnet is not found
+func lanBroadcastAddress(localAddress string) net.IP {
+	ip := net.ParseIP(localAddress).To4()
+	addrs, err := net.InterfaceAddrs()
+	if ip == nil || err != nil {
+		return net.IPv4bcast
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.Equal(ip) || len(ipNet.Mask) != net.IPv4len {
+			continue
+		}
+		broadcast := make(net.IP, net.IPv4len)
+		for i := range broadcast {
+			broadcast[i] = ip[i] | ^ipNet.Mask[i]
+		}
+		return broadcast
+	}
+	return net.IPv4bcast
+}
+
+func (nx *Nexodus) receiveLanBeacons(ctx context.Context, conn *net.UDPConn) {
+	buf := make([]byte, 1500)
+	for {
+		n, src, err := conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			nx.logger.Debugf("failed to read a lan discovery beacon: %v", err)
+			continue
+		}
+		var beacon lanBeacon
+		if err := json.Unmarshal(buf[:n], &beacon); err != nil {
+			nx.logger.Debugf("invalid lan discovery beacon from %s: %v", src, err)
+			continue
+		}
+		nx.handleLanBeacon(beacon, src.Addr().Unmap(), time.Now())
+	}
+}
+
+// handleLanBeacon records the local endpoint of a peer of the vpc discovered on the lan. When the endpoint is new the
+// peering with the peer is reset, so that the peering starts over and prefers the lan endpoint.
+func (nx *Nexodus) handleLanBeacon(beacon lanBeacon, src netip.Addr, now time.Time) {
+	if nx.vpc == nil || beacon.VpcId != nx.vpc.Id || beacon.PublicKey == nx.wireguardPubKey {
+		return
+	}
+	endpoint, err := netip.ParseAddrPort(beacon.Endpoint)
+	if err != nil || endpoint.Addr() != src {
+		// only accept the endpoint of the device that sent the beacon
+		return
+	}
+
+	nx.deviceCacheLock.Lock()
+	defer nx.deviceCacheLock.Unlock()
+	d, ok := nx.deviceCache[beacon.PublicKey]
+	if !ok {
+		return
+	}
+
+	nx.lanDiscovery.lock.Lock()
+	defer nx.lanDiscovery.lock.Unlock()
+	if nx.lanDiscovery.peers == nil {
+		nx.lanDiscovery.peers = map[string]lanPeer{}
+	}
+	existing, found := nx.lanDiscovery.peers[beacon.PublicKey]
+	nx.lanDiscovery.peers[beacon.PublicKey] = lanPeer{endpoint: beacon.Endpoint, lastSeen: now}
+	if found && existing.endpoint == beacon.Endpoint && now.Sub(existing.lastSeen) < lanDiscoveryTimeout {
+		return
+	}
+
+	nx.logger.Infof("Discovered the peer %s on the LAN at %s", d.device.Hostname, beacon.Endpoint)
+	if d.peeringMethod != peeringMethodLanDiscovery {
+		nx.peeringReset(&d)
+		nx.deviceCache[beacon.PublicKey] = d
+	}
+}
+
+// lanPeerEndpoint returns the endpoint of a peer discovered on the lan, or an empty string.
+// Assumes deviceCacheLock is held.
+func (nx *Nexodus) lanPeerEndpoint(publicKey string) string {
+	if !nx.lanDiscovery.enabled {
+		return ""
+	}
+	nx.lanDiscovery.lock.Lock()
+	defer nx.lanDiscovery.lock.Unlock()
+	peer, ok := nx.lanDiscovery.peers[publicKey]
+	if !ok || time.Since(peer.lastSeen) > lanDiscoveryTimeout {
+		return ""
+	}
+	return peer.endpoint
+}
+
+// buildLanDiscoveryPeer peers directly with a peer discovered on the lan using its local endpoint
+func buildLanDiscoveryPeer(nx *Nexodus, device public.ModelsDevice, _ []string, _, _, _ string) wgPeerConfig {
+	device.AllowedIps = append(device.AllowedIps, device.AdvertiseCidrs...)
+	return wgPeerConfig{
+		PublicKey:           device.PublicKey,
+		Endpoint:            nx.lanPeerEndpoint(device.PublicKey),
+		AllowedIPs:          device.AllowedIps,
+		PersistentKeepAlive: persistentKeepalive,
+	}
+}
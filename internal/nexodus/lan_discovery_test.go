@@ -0,0 +1,66 @@
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestHandleLanBeacon(t *testing.T) {
+	require := require.New(t)
+	zLogger, _ := zap.NewDevelopment()
+	nx := &Nexodus{
+		vpc:                      &public.ModelsVPC{Id: "vpc", Ipv4Cidr: "100.64.0.0/10"},
+		nodeReflexiveAddressIPv4: netip.MustParseAddrPort("1.1.1.1:1234"),
+		wireguardPubKey:          "self",
+		logger:                   zLogger.Sugar(),
+		deviceCache: map[string]deviceCacheEntry{
+			"peer": {
+				device: public.ModelsDevice{
+					PublicKey:  "peer",
+					AllowedIps: []string{"100.64.0.2/32"},
+					Endpoints: []public.ModelsEndpoint{
+						{Address: "192.168.1.20:51820", Source: "local"},
+						{Address: "2.2.2.2:4321", Source: "stun"},
+					},
+				},
+				peeringMethod:      peeringMethodReflexive,
+				peeringMethodIndex: 6,
+			},
+		},
+	}
+	nx.lanDiscovery.enabled = true
+	now := time.Now()
+	src := netip.MustParseAddr("10.0.0.20")
+	beacon := lanBeacon{PublicKey: "peer", VpcId: "vpc", Endpoint: "10.0.0.20:51820"}
+
+	// beacons of other vpcs, unknown devices and endpoints of other hosts are ignored
+	nx.handleLanBeacon(lanBeacon{PublicKey: "peer", VpcId: "other", Endpoint: beacon.Endpoint}, src, now)
+	nx.handleLanBeacon(lanBeacon{PublicKey: "unknown", VpcId: "vpc", Endpoint: beacon.Endpoint}, src, now)
+	nx.handleLanBeacon(beacon, netip.MustParseAddr("10.0.0.30"), now)
+	require.Empty(nx.lanPeerEndpoint("peer"))
+
+	// a discovered peer resets its peering to prefer the lan endpoint
+	nx.handleLanBeacon(beacon, src, now)
+	require.Equal("10.0.0.20:51820", nx.lanPeerEndpoint("peer"))
+	require.Equal(-1, nx.deviceCache["peer"].peeringMethodIndex)
+
+	d := nx.deviceCache["peer"]
+	peer, method, _ := nx.rebuildPeerConfig(&d, false, false)
+	require.Equal(peeringMethodLanDiscovery, method)
+	require.Equal("10.0.0.20:51820", peer.Endpoint)
+
+	// the lan endpoint is not used once the peer stops sending beacons
+	nx.lanDiscovery.peers["peer"] = lanPeer{endpoint: beacon.Endpoint, lastSeen: now.Add(-lanDiscoveryTimeout - time.Second)}
+	require.Empty(nx.lanPeerEndpoint("peer"))
+
+	// lan discovery is opt-in
+	nx.handleLanBeacon(beacon, src, now)
+	nx.lanDiscovery.enabled = false
+	require.Empty(nx.lanPeerEndpoint("peer"))
+}
@@ -0,0 +1,115 @@
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// the prefixes of the names of the virtual interfaces of containers, VMs and VPNs, their subnets are not part of the LAN
+var virtualInterfacePrefixes = []string{
+	"br-", "cali", "cilium", "cni", "docker", "flannel", "nxd", "podman", "tailscale", "tap", "tun", "veth", "virbr", "vnet", "wg", "zt",
+}
+
+// lanInterface is a network interface of the host with the prefixes of its addresses
+type lanInterface struct {
+	name     string
+	flags    net.Flags
+	prefixes []netip.Prefix
+}
+
+// discoverLanSubnets advertises the IPv4 subnets directly connected to the LAN interfaces of the host, so that the
+// node is the gateway of its site without listing them with --advertise-cidr
+func (nx *Nexodus) discoverLanSubnets() error {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return fmt.Errorf("failed to list the network interfaces: %w", err)
+	}
+	var lanIfaces []lanInterface
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			nx.logger.Debugf("failed to list the addresses of %s: %v", iface.Name, err)
+			continue
+		}
+		lanIface := lanInterface{name: iface.Name, flags: iface.Flags}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if prefix, err := netip.ParsePrefix(ipNet.String()); err == nil {
+				lanIface.prefixes = append(lanIface.prefixes, prefix)
+			}
+		}
+		lanIfaces = append(lanIfaces, lanIface)
+	}
+
+	// the addresses of the overlay are not part of the LAN
+	var excluded []netip.Prefix
+	if nx.vpc != nil {
+		if prefix, err := netip.ParsePrefix(nx.vpc.Ipv4Cidr); err == nil {
+			excluded = append(excluded, prefix)
+		}
+	}
+	if nx.podIPAM != nil {
+		excluded = append(excluded, nx.podIPAM.prefix)
+	}
+
+	nx.lanSubnets = lanSubnets(lanIfaces, nx.lanInterfaces, nx.tunnelIface, excluded)
+	if len(nx.lanSubnets) == 0 {
+		return fmt.Errorf("no LAN subnet found, select the LAN interfaces with --lan-interface or use --advertise-cidr")
+	}
+	for _, subnet := range nx.lanSubnets {
+		if !slices.Contains(nx.advertiseCidrs, subnet) {
+			nx.logger.Infof("Advertising the LAN subnet %s", subnet)
+			nx.advertiseCidrs = append(nx.advertiseCidrs, subnet)
+		}
+	}
+	return nil
+}
+
+// lanSubnets returns the IPv4 subnets of the interfaces of the LAN. Without a list of interfaces, the LAN is made of
+// the physical interfaces that are up and the subnets are the private ones, a public subnet is usually the uplink
+// of the gateway. The subnets overlapping an excluded prefix are skipped.
+func lanSubnets(ifaces []lanInterface, include []string, tunnelIface string, excluded []netip.Prefix) []string {
+	var subnets []string
+	for _, iface := range ifaces {
+		selected := slices.Contains(include, iface.name)
+		if len(include) != 0 && !selected {
+			continue
+		}
+		if !selected && !isLanInterface(iface, tunnelIface) {
+			continue
+		}
+		for _, prefix := range iface.prefixes {
+			addr := prefix.Addr().Unmap()
+			if !addr.Is4() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || prefix.Bits() == 32 {
+				continue
+			}
+			if !selected && !addr.IsPrivate() {
+				continue
+			}
+			subnet := netip.PrefixFrom(addr, prefix.Bits()).Masked()
+			if slices.Contains(subnets, subnet.String()) || slices.ContainsFunc(excluded, subnet.Overlaps) {
+				continue
+			}
+			subnets = append(subnets, subnet.String())
+		}
+	}
+	return subnets
+}
+
+func isLanInterface(iface lanInterface, tunnelIface string) bool {
+	if iface.flags&net.FlagUp == 0 || iface.flags&(net.FlagLoopback|net.FlagPointToPoint) != 0 || iface.name == tunnelIface {
+		return false
+	}
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(iface.name, prefix) {
+			return false
+		}
+	}
+	return true
+}
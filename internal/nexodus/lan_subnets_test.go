@@ -0,0 +1,42 @@
+package nexodus
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanSubnets(t *testing.T) {
+	require := require.New(t)
+
+	prefixes := func(s ...string) []netip.Prefix {
+		var p []netip.Prefix
+		for _, prefix := range s {
+			p = append(p, netip.MustParsePrefix(prefix))
+		}
+		return p
+	}
+	ifaces := []lanInterface{
+		{name: "lo", flags: net.FlagUp | net.FlagLoopback, prefixes: prefixes("127.0.0.1/8")},
+		{name: "wan", flags: net.FlagUp, prefixes: prefixes("203.0.113.7/24")},
+		{name: "eth0", flags: net.FlagUp, prefixes: prefixes("192.168.1.1/24", "fe80::1/64", "fd00::1/64")},
+		{name: "eth1", flags: net.FlagUp, prefixes: prefixes("10.1.2.3/16", "172.16.0.1/32")},
+		{name: "eth2", prefixes: prefixes("10.9.0.1/24")},
+		{name: "docker0", flags: net.FlagUp, prefixes: prefixes("172.17.0.1/16")},
+		{name: "wg0", flags: net.FlagUp, prefixes: prefixes("100.64.0.1/32")},
+		{name: "cgnat", flags: net.FlagUp, prefixes: prefixes("100.64.3.1/24")},
+		{name: "lan", flags: net.FlagUp, prefixes: prefixes("192.168.1.7/24")},
+	}
+	excluded := prefixes("100.64.0.0/10")
+
+	// the private subnets of the physical interfaces that are up
+	require.Equal([]string{"192.168.1.0/24", "10.1.0.0/16"}, lanSubnets(ifaces, nil, "wg0", excluded))
+
+	// the subnets of the selected interfaces, even public or down
+	require.Equal([]string{"203.0.113.0/24", "10.9.0.0/24"}, lanSubnets(ifaces, []string{"wan", "eth2"}, "wg0", excluded))
+
+	// the excluded prefixes are not advertised when selected either
+	require.Empty(lanSubnets(ifaces, []string{"cgnat"}, "wg0", excluded))
+}
@@ -0,0 +1,156 @@
+package nexodus
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const lastKnownConfigFile = "last-known-config.json"
+
+// lastKnownConfig is the last peer set and security group applied to the wireguard interface. It is restored when
+// nexd starts while the nexodus service is unreachable, so that the mesh keeps working until the service is back.
+type lastKnownConfig struct {
+	PublicKey     string                      `json:"public_key"`
+	VPC           public.ModelsVPC            `json:"vpc"`
+	Devices       []public.ModelsDevice       `json:"devices"` // the devices of the vpc, including the local device
+	SecurityGroup *public.ModelsSecurityGroup `json:"security_group,omitempty"`
+	AppliedAt     time.Time                   `json:"applied_at"`
+}
+
+// localDevice returns the device of the public key of the config, the config can only be restored if it has one
+// with a tunnel address.
+func (c *lastKnownConfig) localDevice() (public.ModelsDevice, bool) {
+	for _, device := range c.Devices {
+		if device.PublicKey == c.PublicKey && len(device.Ipv4TunnelIps) > 0 {
+			return device, true
+		}
+	}
+	return public.ModelsDevice{}, false
+}
+
+// readLastKnownConfig returns the last known config of the state dir, nil if there is none.
+func readLastKnownConfig(stateDir string) (*lastKnownConfig, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir, lastKnownConfigFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	config := &lastKnownConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// writeLastKnownConfig replaces the last known config of the state dir, a config that is only partly written
+// is never read back.
+func writeLastKnownConfig(stateDir string, config lastKnownConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(stateDir, lastKnownConfigFile)
+	if err := os.WriteFile(file+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// storeLastKnownConfig saves the devices and the security group that were just applied, the devices are only
+// saved when their peering configuration changed. With nil devices the security group is saved with the previously
+// saved devices. Assumes deviceCacheLock is held.
+func (nx *Nexodus) storeLastKnownConfig(devices map[string]public.ModelsDevice) {
+	if nx.stateDir == "" || nx.vpc == nil {
+		return
+	}
+	if devices != nil {
+		if !lastKnownDevicesChanged(nx.lastKnownDevices, devices) {
+			return
+		}
+		nx.lastKnownDevices = make([]public.ModelsDevice, 0, len(devices))
+		for _, device := range devices {
+			nx.lastKnownDevices = append(nx.lastKnownDevices, device)
+		}
+	}
+	if nx.lastKnownDevices == nil {
+		return
+	}
+	err := writeLastKnownConfig(nx.stateDir, lastKnownConfig{
+		PublicKey:     nx.wireguardPubKey,
+		VPC:           *nx.vpc,
+		Devices:       nx.lastKnownDevices,
+		SecurityGroup: nx.securityGroup,
+		AppliedAt:     time.Now(),
+	})
+	if err != nil {
+		nx.logger.Warnf("Failed to store the last known peer configuration: %v", err)
+	}
+}
+
+// lastKnownDevicesChanged returns whether the peering configuration of the devices differs from the saved devices.
+func lastKnownDevicesChanged(saved []public.ModelsDevice, devices map[string]public.ModelsDevice) bool {
+	if saved == nil || len(saved) != len(devices) {
+		return true
+	}
+	byKey := make(map[string]public.ModelsDevice, len(devices))
+	for _, device := range devices {
+		byKey[device.PublicKey] = device
+	}
+	for _, device := range saved {
+		current, ok := byKey[device.PublicKey]
+		if !ok || deviceUpdated(device, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreLastKnownConfig configures the wireguard interface with the peers and the security group of the previous
+// run, it returns false when there is no config of the current key pair to restore. The device cache is then
+// reconciled with the devices of the service once it is reachable. Assumes the keys are loaded.
+func (nx *Nexodus) restoreLastKnownConfig() (bool, error) {
+	if nx.stateDir == "" {
+		return false, nil
+	}
+	config, err := readLastKnownConfig(nx.stateDir)
+	if err != nil || config == nil {
+		return false, err
+	}
+	if config.PublicKey != nx.wireguardPubKey {
+		// the keys were replaced since, the peers do not know the current public key
+		return false, nil
+	}
+	if _, ok := config.localDevice(); !ok {
+		return false, nil
+	}
+
+	nx.vpc = &config.VPC
+	nx.deviceCacheLock.Lock()
+	for _, device := range config.Devices {
+		nx.addToDeviceCache(device)
+	}
+	nx.lastKnownDevices = config.Devices
+	updatePeers := nx.buildPeersConfig()
+	err = nx.DeployWireguardConfig(updatePeers)
+	nx.deviceCacheLock.Unlock()
+	if err != nil {
+		return false, err
+	}
+
+	if config.SecurityGroup != nil {
+		nx.securityGroup = config.SecurityGroup
+		if err := nx.applySecurityGroupRules(); err != nil {
+			return true, err
+		}
+	}
+	nx.logger.Infof("Restored the %d peers last applied at %s, reconciling once the nexodus service is reachable",
+		len(updatePeers), config.AppliedAt.Format(time.RFC3339))
+	return true, nil
+}
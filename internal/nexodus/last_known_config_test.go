@@ -0,0 +1,71 @@
+package nexodus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestLastKnownConfig(t *testing.T) {
+	require := require.New(t)
+	stateDir := t.TempDir()
+
+	config, err := readLastKnownConfig(stateDir)
+	require.NoError(err)
+	require.Nil(config)
+
+	local := public.ModelsDevice{
+		Id:            "local",
+		PublicKey:     "local-key",
+		Ipv4TunnelIps: []public.ModelsTunnelIP{{Address: "100.64.0.1"}},
+	}
+	peer := public.ModelsDevice{
+		Id:         "peer",
+		PublicKey:  "peer-key",
+		AllowedIps: []string{"100.64.0.2/32"},
+	}
+	require.NoError(writeLastKnownConfig(stateDir, lastKnownConfig{
+		PublicKey:     "local-key",
+		VPC:           public.ModelsVPC{Id: "vpc"},
+		Devices:       []public.ModelsDevice{local, peer},
+		SecurityGroup: &public.ModelsSecurityGroup{Id: "group"},
+		AppliedAt:     time.Now(),
+	}))
+
+	config, err = readLastKnownConfig(stateDir)
+	require.NoError(err)
+	require.Equal("vpc", config.VPC.Id)
+	require.Equal("group", config.SecurityGroup.Id)
+	require.Len(config.Devices, 2)
+	device, ok := config.localDevice()
+	require.True(ok)
+	require.Equal("local", device.Id)
+
+	// a config without the local device can not be restored
+	config.PublicKey = "other-key"
+	_, ok = config.localDevice()
+	require.False(ok)
+}
+
+func TestLastKnownDevicesChanged(t *testing.T) {
+	require := require.New(t)
+	saved := []public.ModelsDevice{
+		{PublicKey: "a", AllowedIps: []string{"100.64.0.1/32"}},
+		{PublicKey: "b", AllowedIps: []string{"100.64.0.2/32"}},
+	}
+	devices := map[string]public.ModelsDevice{
+		"a": saved[0],
+		"b": {PublicKey: "b", AllowedIps: []string{"100.64.0.2/32"}, Online: true},
+	}
+	require.True(lastKnownDevicesChanged(nil, devices))
+	// the status of the devices does not change their peering
+	require.False(lastKnownDevicesChanged(saved, devices))
+
+	devices["b"] = public.ModelsDevice{PublicKey: "b", AllowedIps: []string{"100.64.0.3/32"}}
+	require.True(lastKnownDevicesChanged(saved, devices))
+	delete(devices, "b")
+	require.True(lastKnownDevicesChanged(saved, devices))
+}
@@ -0,0 +1,99 @@
+package nexodus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// the number of listen ports tried when the listen port is already in use as the listener starts
+const maxListenPortAttempts = 5
+
+// parseListenPortRange parses a listen port range of the form min-max, an empty range is no range
+func parseListenPortRange(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+	minStr, maxStr, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid listen port range %q, expected min-max", value)
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(minStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid listen port range %q: %w", value, err)
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(maxStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid listen port range %q: %w", value, err)
+	}
+	if min < 1 || max > 65535 || min > max {
+		return 0, 0, fmt.Errorf("invalid listen port range %q, the ports must be between 1 and 65535 and min no greater than max", value)
+	}
+	return min, max, nil
+}
+
+// inListenPortRange returns true if the port is within the listen port range, any port is without a range
+func (nx *Nexodus) inListenPortRange(port int) bool {
+	return nx.listenPortMax == 0 || port >= nx.listenPortMin && port <= nx.listenPortMax
+}
+
+// allocateListenPort allocates a free port within the listen port range, or a random free port without a range
+func (nx *Nexodus) allocateListenPort() (int, error) {
+	if nx.listenPortMax != 0 {
+		return getWgListenPortInRange(nx.listenPortMin, nx.listenPortMax)
+	}
+	return getWgListenPort()
+}
+
+// listenWithRetry starts the wireguard listener with listen, moving to another free port when the listen port is
+// already in use, since another service may have bound it after it was picked. A requested listen port is never
+// changed. When the port changed, the new port is stored and published with the endpoints of the device.
+func (nx *Nexodus) listenWithRetry(listen func(port int) error) error {
+	oldPort := nx.listenPort
+	for attempt := 1; ; attempt++ {
+		err := listen(nx.listenPort)
+		if err == nil {
+			break
+		}
+		if nx.listenPortRequested || !errors.Is(err, syscall.EADDRINUSE) || attempt == maxListenPortAttempts {
+			return err
+		}
+		port, err := nx.allocateListenPort()
+		if err != nil {
+			return err
+		}
+		nx.logger.Warnf("The listen port %d is already in use, listening on port %d instead", nx.listenPort, port)
+		nx.listenPort = port
+	}
+	if nx.listenPort == oldPort {
+		return nil
+	}
+
+	if !nx.userspaceMode {
+		nx.stateStore.State().Port = nx.listenPort
+		if err := nx.stateStore.Store(); err != nil {
+			nx.logger.Warnf("failed to store the listen port: %v", err)
+		}
+	}
+	if nx.portMapper != nil {
+		nx.portMapper.SetLocalPort(uint16(nx.listenPort))
+	}
+	if nx.deviceId == "" {
+		// the port is published when the device joins
+		return nil
+	}
+	if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(context.Background(), nx.deviceId, map[string]interface{}{
+		"listen_port": nx.listenPort,
+	}); err != nil {
+		nx.logger.Warnf("failed to update the listen port of this device: %v", err)
+	}
+	// the endpoints are re-discovered with the new port like after an address change
+	select {
+	case nx.addressChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package nexodus
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseListenPortRange(t *testing.T) {
+	require := require.New(t)
+
+	min, max, err := parseListenPortRange("51820-51900")
+	require.NoError(err)
+	require.Equal(51820, min)
+	require.Equal(51900, max)
+
+	min, max, err = parseListenPortRange("")
+	require.NoError(err)
+	require.Zero(min)
+	require.Zero(max)
+
+	for _, value := range []string{"51820", "51900-51820", "0-100", "1-65536", "a-b"} {
+		_, _, err = parseListenPortRange(value)
+		require.Error(err, value)
+	}
+}
+
+func TestListenWithRetry(t *testing.T) {
+	require := require.New(t)
+	zLogger, _ := zap.NewDevelopment()
+	nx := &Nexodus{
+		logger:        zLogger.Sugar(),
+		listenPort:    51820,
+		listenPortMin: 52000,
+		listenPortMax: 52100,
+	}
+	nx.userspaceMode = true
+
+	// the listener moves to a port in the range once the port is in use
+	var tried []int
+	err := nx.listenWithRetry(func(port int) error {
+		tried = append(tried, port)
+		if port == 51820 {
+			return fmt.Errorf("failed to listen: %w", syscall.EADDRINUSE)
+		}
+		return nil
+	})
+	require.NoError(err)
+	require.Len(tried, 2)
+	require.Equal(tried[1], nx.listenPort)
+	require.True(nx.inListenPortRange(nx.listenPort))
+
+	// a requested port is never changed
+	nx.listenPort = 51820
+	nx.listenPortRequested = true
+	err = nx.listenWithRetry(func(port int) error {
+		return syscall.EADDRINUSE
+	})
+	require.ErrorIs(err, syscall.EADDRINUSE)
+	require.Equal(51820, nx.listenPort)
+}
@@ -0,0 +1,53 @@
+package nexodus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+// relayReason explains why the peer device is reached through a relay, returns an empty string if the
+// devices can peer directly. Assumes deviceCacheLock is held.
+func (nx *Nexodus) relayReason(device public.ModelsDevice) string {
+	if nx.relay || device.Relay {
+		return ""
+	}
+	_, reflexiveIP4 := nx.extractLocalAndReflexiveIP(device)
+	if nx.nodeReflexiveAddressIPv4.Addr().String() == parseIPfromAddrPort(reflexiveIP4) {
+		// behind the same NAT, the devices peer with their local addresses
+		return ""
+	}
+
+	var reasons []string
+	switch {
+	case !nx.symmetricNat:
+	case nx.udpBlocked:
+		reasons = append(reasons, "UDP is blocked on the network of this device")
+	case nx.symmetricNatDetected:
+		reasons = append(reasons, fmt.Sprintf("this device is behind a NAT with %s mapping", nx.natBehavior.Mapping))
+	default:
+		reasons = append(reasons, "this device is relay-only")
+	}
+	if device.SymmetricNat {
+		if mapping := stun.Mapping(device.NatType); mapping.EndpointDependent() {
+			reasons = append(reasons, fmt.Sprintf("%s is behind a NAT with %s mapping", device.Hostname, mapping))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("%s is relay-only or UDP is blocked on its network", device.Hostname))
+		}
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// natStatus renders the NAT behavior for the nexd status output
+func (nx *Nexodus) natStatus() string {
+	if nx.natBehavior.Mapping == stun.MappingUnknown {
+		return ""
+	}
+	hairpin := "not supported"
+	if nx.natBehavior.Hairpin {
+		hairpin = "supported"
+	}
+	return fmt.Sprintf("NAT Mapping: %s (hairpinning %s)\n", nx.natBehavior.Mapping, hairpin)
+}
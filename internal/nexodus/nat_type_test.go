@@ -0,0 +1,49 @@
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+func TestRelayReason(t *testing.T) {
+	require := require.New(t)
+	nx := &Nexodus{
+		nodeReflexiveAddressIPv4: netip.MustParseAddrPort("1.1.1.1:1234"),
+	}
+	peer := public.ModelsDevice{
+		Hostname: "peer",
+		Endpoints: []public.ModelsEndpoint{
+			{Source: "local", Address: "192.168.2.20:51820"},
+			{Source: "stun:stun.example.com:3478", Address: "2.2.2.2:4321"},
+		},
+	}
+
+	// neither device is behind a symmetric NAT
+	require.Equal("", nx.relayReason(peer))
+
+	peer.SymmetricNat = true
+	peer.NatType = string(stun.MappingAddressAndPortDependent)
+	require.Equal("peer is behind a NAT with address-and-port-dependent mapping", nx.relayReason(peer))
+
+	peer.NatType = string(stun.MappingEndpointIndependent)
+	require.Equal("peer is relay-only or UDP is blocked on its network", nx.relayReason(peer))
+
+	nx.symmetricNat = true
+	nx.symmetricNatDetected = true
+	nx.natBehavior = stun.NatBehavior{Mapping: stun.MappingEndpointDependent}
+	require.Equal("this device is behind a NAT with endpoint-dependent mapping, peer is relay-only or UDP is blocked on its network", nx.relayReason(peer))
+
+	// devices behind the same NAT peer with their local addresses
+	peer.Endpoints[1].Address = "1.1.1.1:4321"
+	require.Equal("", nx.relayReason(peer))
+
+	// relays are always reached directly
+	peer.Endpoints[1].Address = "2.2.2.2:4321"
+	peer.Relay = true
+	require.Equal("", nx.relayReason(peer))
+}
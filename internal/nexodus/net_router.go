@@ -25,6 +25,10 @@ func (nx *Nexodus) setupNetworkRouterNode() error {
 			nx.logger.Warnf("IPv6 is not currently supported for --net-router: %s", cidr)
 			continue
 		}
+		if nx.podIPAM != nil && cidr == nx.podIPAM.prefix.String() {
+			// the pods are routed to their veth interfaces, not through a physical interface
+			continue
+		}
 
 		ip, _, err := net.ParseCIDR(cidr)
 		if err != nil {
@@ -2,11 +2,13 @@ package nexodus
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net"
 	"net/http"
 	"net/netip"
@@ -15,15 +17,18 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/nexodus-io/nexodus/internal/wgcrypto"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"tailscale.com/net/portmapper"
 	"tailscale.com/syncs"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/key"
@@ -34,6 +39,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/bgp"
 	"github.com/nexodus-io/nexodus/internal/client"
 	"github.com/nexodus-io/nexodus/internal/stun"
 	"github.com/nexodus-io/nexodus/internal/util"
@@ -46,6 +52,7 @@ import (
 
 const (
 	pollInterval       = 5 * time.Second
+	secGroupInterval   = 20 * time.Second
 	wgGoBinary         = "wireguard-go"
 	nexdWgGoBinary     = "nexd-wireguard-go"
 	wgWinBinary        = "wireguard.exe"
@@ -62,6 +69,10 @@ const (
 	NexdStatusAuth
 	// nexd is up and running normally
 	NexdStatusRunning
+	// the nexodus service no longer supports this version of nexd
+	NexdStatusUpgradeRequired
+	// the network is intercepting requests and the user must sign in to the network with a browser
+	NexdStatusCaptivePortal
 )
 
 const (
@@ -81,7 +92,9 @@ type userspaceWG struct {
 	userspaceMode bool
 	userspaceTun  tun.Device
 	userspaceNet  *netstack.Net
-	userspaceDev  *device.Device
+	// userspaceFilter applies the security group to the packets of the userspace tunnel device
+	userspaceFilter *packetFilter
+	userspaceDev    *device.Device
 	// the last address configured on the userspace wireguard interface
 	userspaceLastAddress string
 	proxyLock            sync.RWMutex
@@ -168,8 +181,11 @@ type deviceCacheEntry struct {
 	// the last time this device was updated as seen from the API
 	lastUpdated time.Time
 	peerHealth
+	peerReachability
 	peeringMethod      string
 	peeringMethodIndex int
+	// the index of the host candidate of the peer in use by the host candidate peering method
+	candidateIndex int
 	// The last time a new peering configuration was generated for this device
 	peeringTime time.Time
 }
@@ -179,93 +195,205 @@ type exitNode struct {
 	exitNodeClientEnabled bool
 	exitNodeOriginEnabled bool
 	exitNodeOrigins       []wgPeerConfig
+	// activeOrigin is the public key of the exit node origin in use by the exit node client
+	activeOrigin string
+	// activeSince is the time the exit node origin in use was selected
+	activeSince time.Time
+	// lastFailover is the last failover from an exit node that stopped handshaking
+	lastFailover *ExitNodeFailover
+	// failoverUnavailable is set once it is logged that no exit node is available to fail over to
+	failoverUnavailable bool
+	// exceptLock guards the exit node exceptions and their routes
+	exceptLock sync.Mutex
+	// exceptRoutes are the routes of the exit node exceptions in routing table 51820
+	exceptRoutes map[netip.Prefix]bool
+	// killSwitch drops the egress traffic that leaves the physical interface while the exit node client is enabled
+	killSwitch          bool
+	killSwitchInstalled bool
 }
 
 type Options struct {
 	AdvertiseCidrs          []string
 	ApiURL                  *url.URL
+	AutoUpdate              bool
 	Context                 context.Context
 	Derper                  *Derper
+	DisableIPv6             bool
+	DNSListenAddress        string
 	ExitNodeClientEnabled   bool
+	ExitNodeKillSwitch      bool
 	ExitNodeOriginEnabled   bool
+	Hostname                string
 	InsecureSkipTlsVerify   bool
+	LanDiscovery            bool
+	AdvertiseLan            bool     // advertise the subnets of the LAN interfaces, see discoverLanSubnets
+	LanInterfaces           []string // the LAN interfaces, all the physical ones when empty
+	BGPPeer                 string   // the address of the BGP router of the site, see startBGP
+	BGPLocalAS              uint32
+	BGPPeerAS               uint32
+	BGPRouterID             string   // the BGP router id, the local endpoint address by default
+	AdvertiseRoutes         []string // advertise the routes of the routing table within these prefixes, see runRouteLearning
 	ListenPort              int
+	ListenPortRange         string // the range of the listen ports to pick a port from, as min-max
+	RandomListenPort        bool   // pick a random listen port rather than the standard wireguard port for relays and userspace mode
 	LogLevel                *zap.AtomicLevel
 	Logger                  *zap.SugaredLogger
 	NetworkRouter           bool
 	NetworkRouterDisableNAT bool
+	OverrideRoutes          bool
 	Password                string
+	PodCIDR                 string // the CIDR to allocate the addresses of the pods of this node from, see the nexodus CNI plugin
+	PortMapping             bool
+	ProxyURL                *url.URL // the proxy of the requests to the service and its identity provider, see client.WithProxy
+	RotateKey               bool     // replace the key pair of the device once it is registered, see rotateKeys
 	RegKey                  string
 	Relay                   bool
 	RelayDerp               bool
 	RelayOnly               bool
+	RelayStunPort           int
+	ReportConnectivity      bool // report the reachability of the peers to the api-server
+	ReportPeerLatency       bool // report the latency and loss of the paths to the peers to the api-server
+	ReportPeerTraffic       bool // report the traffic exchanged with the peers to the api-server
+	Reload                  func() (ReloadOptions, error)
 	RequestedIP             string
+	ResolvedFlags           map[string]string // the flags nexd was started with, included in the support bundle
 	StateDir                string
 	StateStore              state.Store
+	UpdatePublicKey         string
 	UserProvidedLocalIP     string
 	Username                string
+	OidcProvider            string // the identity provider the device flow logs in with, the default provider when empty
+	DevicePollViaService    bool   // poll the tokens of the device flow through the service rather than the identity provider
 	UserspaceMode           bool
 	Version                 string
 	VpcId                   string
 	SecurityGroupId         string
+	SocksListen             string // the address to serve the SOCKS5 and HTTP proxy into the Nexodus network on, userspace mode only
 }
 type Nexodus struct {
 	advertiseCidrs          []string
+	advertiseLan            bool
+	lanInterfaces           []string
+	lanSubnets              []string // the discovered LAN subnets, advertised with advertiseCidrs
+	bgpPeer                 string
+	bgpLocalAS              uint32
+	bgpPeerAS               uint32
+	bgpRouterID             string
+	bgp                     *bgp.Speaker
+	routeFilters            []netip.Prefix
+	learnedRoutesCh         chan []string
+	learnedRoutes           []string       // the prefixes of the routes within routeFilters
+	learnedCidrs            []string       // the learned routes and BGP imports, advertised with advertiseCidrs
+	bgpExported             []netip.Prefix // the prefixes announced to the BGP peer
+	apiBackOff              *apiBackOff
 	apiURL                  *url.URL
+	proxyURL                *url.URL
+	autoUpdate              bool
+	disableIPv6             bool
+	dnsListenAddress        string
 	insecureSkipTlsVerify   bool
 	listenPort              int
+	listenPortRequested     bool
+	listenPortMin           int // the range of the listen ports to pick a port from, unset when listenPortMax is 0
+	listenPortMax           int
+	orgListenPortMin        int32 // the listen port range of the organization of the vpc, if it defines one
+	orgListenPortMax        int32
 	logLevel                *zap.AtomicLevel
 	logger                  *zap.SugaredLogger
 	networkRouter           bool
 	networkRouterDisableNAT bool
+	overrideRoutes          bool
 	password                string
+	portMapping             bool
+	rotateKey               bool
 	regKey                  string
 	relay                   bool
 	relayDerp               bool
+	relayOnly               bool
+	relayStunPort           int
+	reportConnectivity      bool
+	reportPeerLatency       bool
+	reportPeerTraffic       bool
+	reloadOptions           func() (ReloadOptions, error)
 	requestedIP             string
+	resolvedFlags           map[string]string
 	stateDir                string
 	stateStore              state.Store
+	updatePublicKey         ed25519.PublicKey
 	userProvidedLocalIP     string
 	username                string
+	oidcProvider            string
+	devicePollViaService    bool
 	version                 string
 	vpcId                   string
 	securityGroupId         string
+	socksListen             string
 
 	userspaceWG
 	Derper                   *Derper
 	nexRelay                 nexRelay
 	TunnelIP                 string
 	TunnelIpV6               string
+	addressChanged           chan struct{} // signaled once the addresses of the host changed
 	client                   *client.APIClient
 	clientOptions            []client.Option
 	deviceCache              map[string]deviceCacheEntry
+	deviceId                 string
 	deviceCacheLock          sync.RWMutex
 	deviceReconciled         bool
+	registration             *state.Registration   // the registration of the device, at first the one cached by the previous run
+	lastKnownDevices         []public.ModelsDevice // the devices of the last known config, see storeLastKnownConfig
 	devicesInformer          *public.Informer[public.ModelsDevice]
+	eventStream              eventStream
 	endpointLocalAddress     string
 	exitNode                 exitNode
 	hostname                 string
+	hostCandidateAddrs       []string
+	holePunch                holePunch
+	holePunchInformer        *public.Informer[public.ModelsDeviceMetadata]
 	informerStop             context.CancelFunc
 	ipv6Supported            bool
+	localPrefixes            []netip.Prefix
+	lanDiscovery             lanDiscovery
+	natBehavior              stun.NatBehavior // the NAT behavior found by the last STUN discovery
 	needSecGroupReconcile    bool
 	netRouterInterfaceMap    map[string]*net.Interface
 	nexCtx                   context.Context
 	nexWg                    *sync.WaitGroup
 	nodeReflexiveAddressIPv4 netip.AddrPort
+	nodeReflexiveAddressIPv6 netip.AddrPort // the global IPv6 endpoint of this device, invalid when it has none
+	featureFlags             featureFlags
 	os                       string
+	overlayDNS               *overlayDNS
+	podIPAM                  *podIPAM          // allocates the pod addresses, nil without a pod CIDR
+	prefixGateways           map[string]string // the public key of the gateway elected for each prefix advertised by several devices
+	portMapper               *portmapper.Client
 	reflexiveAddrStunSrc     string
+	relayLoadSample          relayLoadSample
+	relayPublicKey           string // the public key of the relay in use
 	relayWgIP                string
+	traffic                  trafficAccounting
+	reloadCh                 chan reloadRequest
+	restartCh                chan struct{}
 	securityGroup            *public.ModelsSecurityGroup
 	securityGroupsInformer   *public.Informer[public.ModelsSecurityGroup]
+	servicesInformer         *public.Informer[public.ModelsService]
+	dnsRecordsInformer       *public.Informer[public.ModelsDNSRecord]
 	status                   int // See the NexdStatus* constants
 	statusMsg                string
+	stunServer               *stun.ClosableServer
 	symmetricNat             bool
+	symmetricNatDetected     bool
 	tunnelIface              string
-	vpc                      *public.ModelsVPC
-	wgConfig                 wgConfig
-	wireguardPubKey          string
-	wireguardPubKeyInConfig  bool
-	wireguardPvtKey          string
+	udpBlocked               bool
+	// the in-process wireguard device and control socket backing the tunnel interface on darwin
+	tunnelDev               *device.Device
+	tunnelUAPI              net.Listener
+	vpc                     *public.ModelsVPC
+	wgConfig                wgConfig
+	wireguardPubKey         string
+	wireguardPubKeyInConfig bool
+	wireguardPvtKey         string
 }
 
 type wgConfig struct {
@@ -292,36 +420,68 @@ func New(o Options) (*Nexodus, error) {
 		return nil, err
 	}
 
-	hostname, err := os.Hostname()
-	if err != nil {
-		return nil, err
+	var err error
+	hostname := o.Hostname
+	if hostname == "" {
+		hostname, err = os.Hostname()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	nx := &Nexodus{
 		requestedIP:             o.RequestedIP,
 		userProvidedLocalIP:     o.UserProvidedLocalIP,
 		advertiseCidrs:          o.AdvertiseCidrs,
+		advertiseLan:            o.AdvertiseLan,
+		lanInterfaces:           o.LanInterfaces,
+		bgpPeer:                 o.BGPPeer,
+		bgpLocalAS:              o.BGPLocalAS,
+		bgpPeerAS:               o.BGPPeerAS,
+		bgpRouterID:             o.BGPRouterID,
+		learnedRoutesCh:         make(chan []string, 1),
 		relay:                   o.Relay,
 		relayDerp:               o.RelayDerp,
+		relayStunPort:           o.RelayStunPort,
 		networkRouter:           o.NetworkRouter,
 		networkRouterDisableNAT: o.NetworkRouterDisableNAT,
+		apiBackOff:              newApiBackOff(),
 		apiURL:                  o.ApiURL,
+		proxyURL:                o.ProxyURL,
+		autoUpdate:              o.AutoUpdate,
+		disableIPv6:             o.DisableIPv6,
+		dnsListenAddress:        o.DNSListenAddress,
+		overrideRoutes:          o.OverrideRoutes,
+		portMapping:             o.PortMapping,
+		rotateKey:               o.RotateKey,
 		symmetricNat:            o.RelayOnly,
+		relayOnly:               o.RelayOnly,
+		reportConnectivity:      o.ReportConnectivity,
+		reportPeerLatency:       o.ReportPeerLatency,
+		reportPeerTraffic:       o.ReportPeerTraffic,
+		reloadOptions:           o.Reload,
+		resolvedFlags:           o.ResolvedFlags,
 		logger:                  o.Logger,
 		logLevel:                o.LogLevel,
 		version:                 o.Version,
 		regKey:                  o.RegKey,
 		username:                o.Username,
+		oidcProvider:            o.OidcProvider,
+		devicePollViaService:    o.DevicePollViaService,
 		password:                o.Password,
 		insecureSkipTlsVerify:   o.InsecureSkipTlsVerify,
 		stateStore:              o.StateStore,
 		stateDir:                o.StateDir,
 		vpcId:                   o.VpcId,
 		securityGroupId:         o.SecurityGroupId,
-
-		hostname:    hostname,
-		deviceCache: make(map[string]deviceCacheEntry),
-		status:      NexdStatusStarting,
+		socksListen:             o.SocksListen,
+
+		hostname:       hostname,
+		deviceCache:    make(map[string]deviceCacheEntry),
+		status:         NexdStatusStarting,
+		restartCh:      make(chan struct{}),
+		reloadCh:       make(chan reloadRequest),
+		addressChanged: make(chan struct{}, 1),
 		userspaceWG: userspaceWG{
 			proxies: map[ProxyKey]*UsProxy{},
 		},
@@ -336,21 +496,59 @@ func New(o Options) (*Nexodus, error) {
 		},
 		exitNode: exitNode{
 			exitNodeClientEnabled: o.ExitNodeClientEnabled,
+			killSwitch:            o.ExitNodeKillSwitch,
 			exitNodeOriginEnabled: o.ExitNodeOriginEnabled,
 		},
+		lanDiscovery: lanDiscovery{
+			enabled: o.LanDiscovery,
+		},
+	}
+
+	nx.userspaceMode = o.UserspaceMode
+	if nx.socksListen != "" && !nx.userspaceMode {
+		return nil, fmt.Errorf("the socks proxy is only supported by nexd proxy and nexd container")
+	}
+	if o.PodCIDR != "" {
+		if err := nx.stateStore.Load(); err != nil {
+			return nil, err
+		}
+		nx.podIPAM, err = newPodIPAM(o.PodCIDR, nx.stateStore.State().PodAddresses)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cidr := range o.AdvertiseRoutes {
+		filter, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid advertise routes prefix %s: %w", cidr, err)
+		}
+		nx.routeFilters = append(nx.routeFilters, filter.Masked())
 	}
 
-	err = nx.setListenPort(o.ListenPort)
+	nx.listenPortMin, nx.listenPortMax, err = parseListenPortRange(o.ListenPortRange)
+	if err != nil {
+		return nil, err
+	}
+	err = nx.setListenPort(o.ListenPort, o.RandomListenPort)
 	if err != nil {
 		return nil, err
 	}
 
+	if nx.autoUpdate {
+		if o.UpdatePublicKey == "" {
+			return nil, fmt.Errorf("an update public key is required to enable automatic updates")
+		}
+		nx.updatePublicKey, err = ParseUpdatePublicKey(o.UpdatePublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	nx.nexRelay.connCtx, nx.nexRelay.connCtxCancel = context.WithCancel(context.Background())
 	nx.nexRelay.donec = nx.nexRelay.connCtx.Done()
 	nx.nexRelay.muCond = sync.NewCond(&nx.nexRelay.mu)
 
-	nx.userspaceMode = o.UserspaceMode
-
 	if !nx.userspaceMode {
 		isOk, err := isElevated()
 		if !isOk {
@@ -370,6 +568,9 @@ func New(o Options) (*Nexodus, error) {
 	if err := nx.symmetricNatDisco(o.Context); err != nil {
 		nx.logger.Warn(err)
 	}
+	if nx.nodeReflexiveAddressIPv6, err = nx.discoverIPv6Endpoint(); err != nil {
+		nx.logger.Debugf("this device has no IPv6 endpoint: %v", err)
+	}
 
 	err = nx.migrateLegacyState(o.StateDir)
 	if err != nil {
@@ -379,14 +580,29 @@ func New(o Options) (*Nexodus, error) {
 	return nx, nil
 }
 
-func (nx *Nexodus) setListenPort(requestedPort int) error {
+func (nx *Nexodus) setListenPort(requestedPort int, random bool) error {
 	if requestedPort != 0 {
 		// Always use what is specified as a command line argument if provided
 		nx.listenPort = requestedPort
+		nx.listenPortRequested = true
 		return nil
-	} else if nx.relay || nx.userspaceMode {
-		// For a relay or when running in userspace mode, default to the standard wireguard port
-		nx.listenPort = WgDefaultPort
+	}
+	if (nx.relay || nx.userspaceMode) && !random && nx.listenPortMax == 0 {
+		// For a relay or when running in userspace mode, default to the standard wireguard port,
+		// unless another service is already listening on it
+		if nx.currentWgPort() == WgDefaultPort || testWgListenPort(WgDefaultPort) == nil {
+			nx.listenPort = WgDefaultPort
+			return nil
+		}
+		nx.logger.Warnf("The wireguard port %d is already in use, listening on a random port", WgDefaultPort)
+	}
+	if nx.userspaceMode {
+		allocatedPort, err := nx.allocateListenPort()
+		if err != nil {
+			return err
+		}
+		nx.logger.Debugf("New random port allocated: %d", allocatedPort)
+		nx.listenPort = allocatedPort
 		return nil
 	}
 
@@ -401,7 +617,7 @@ func (nx *Nexodus) setListenPort(requestedPort int) error {
 	}
 
 	s := nx.stateStore.State()
-	if s.Port != 0 {
+	if s.Port != 0 && nx.inListenPortRange(s.Port) {
 		if s.Port == nx.currentWgPort() {
 			nx.logger.Debug("Using wireguard port already in use")
 			nx.listenPort = s.Port
@@ -414,7 +630,7 @@ func (nx *Nexodus) setListenPort(requestedPort int) error {
 		}
 	}
 
-	allocatedPort, err := getWgListenPort()
+	allocatedPort, err := nx.allocateListenPort()
 	if err != nil {
 		return err
 	}
@@ -424,6 +640,62 @@ func (nx *Nexodus) setListenPort(requestedPort int) error {
 	return nx.stateStore.Store()
 }
 
+// applyListenPortRange makes sure the wireguard listen port falls within the listen
+// port range of the organization the vpc belongs to, if the organization defines one.
+func (nx *Nexodus) applyListenPortRange(ctx context.Context) error {
+	if nx.relay {
+		// relays always listen on the well-known wireguard port
+		return nil
+	}
+
+	if nx.registration != nil {
+		// the range of the organization is remembered with the registration of the device
+		nx.orgListenPortMin, nx.orgListenPortMax = nx.registration.ListenPortMin, nx.registration.ListenPortMax
+	} else {
+		org, _, err := nx.client.OrganizationsApi.GetOrganizations(ctx, nx.vpc.OrganizationId).Execute()
+		if err != nil {
+			return fmt.Errorf("get organization error: %w", err)
+		}
+		nx.orgListenPortMin, nx.orgListenPortMax = org.ListenPortMin, org.ListenPortMax
+	}
+	if nx.orgListenPortMin == 0 && nx.orgListenPortMax == 0 {
+		return nil
+	}
+
+	min, max := int(nx.orgListenPortMin), int(nx.orgListenPortMax)
+	if nx.listenPort >= min && nx.listenPort <= max {
+		return nil
+	}
+	if nx.listenPortRequested {
+		return fmt.Errorf("listen port %d is outside of the organization's allowed listen port range %d-%d", nx.listenPort, min, max)
+	}
+	if nx.listenPortMax != 0 {
+		// pick the port from the part of the configured range the organization allows
+		if nx.listenPortMin > min {
+			min = nx.listenPortMin
+		}
+		if nx.listenPortMax < max {
+			max = nx.listenPortMax
+		}
+		if min > max {
+			return fmt.Errorf("the listen port range %d-%d is outside of the organization's allowed listen port range %d-%d",
+				nx.listenPortMin, nx.listenPortMax, nx.orgListenPortMin, nx.orgListenPortMax)
+		}
+	}
+
+	allocatedPort, err := getWgListenPortInRange(min, max)
+	if err != nil {
+		return err
+	}
+	nx.logger.Debugf("New port allocated from the organization's listen port range: %d", allocatedPort)
+	nx.listenPort = allocatedPort
+	if nx.userspaceMode {
+		return nil
+	}
+	nx.stateStore.State().Port = allocatedPort
+	return nx.stateStore.Store()
+}
+
 func (nx *Nexodus) SetStatus(status int, msg string) {
 	nx.statusMsg = msg
 	nx.status = status
@@ -489,6 +761,13 @@ func (nx *Nexodus) migrateLegacyState(stateDir string) error {
 	return nx.stateStore.Store()
 }
 
+// isUpgradeRequired is true once the nexodus service has rejected this version of nexd, retrying
+// the api request will not help.
+func isUpgradeRequired(err error) bool {
+	var upgradeErr *client.UpgradeRequiredError
+	return errors.As(err, &upgradeErr)
+}
+
 func (nx *Nexodus) resetApiClient(ctx context.Context) error {
 	var err error
 	nx.client, err = client.NewAPIClient(ctx, nx.apiURL.String(), func(msg string) {
@@ -496,8 +775,10 @@ func (nx *Nexodus) resetApiClient(ctx context.Context) error {
 	}, nx.clientOptions...)
 	if err != nil {
 		nx.logger.Warnf("client api error - retrying: %v", err)
+		nx.checkCaptivePortal(ctx, err)
 		return err
 	}
+	nx.clearCaptivePortal()
 	return nil
 }
 
@@ -514,10 +795,8 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 		return fmt.Errorf("CtlServerStart(): %w", err)
 	}
 
-	if runtime.GOOS != Linux.String() && runtime.GOOS != Darwin.String() {
-		nx.logger.Info("Security Groups are currently only supported on Linux and macOS")
-	} else if nx.userspaceMode {
-		nx.logger.Info("Security Groups are not supported in userspace proxy mode")
+	if runtime.GOOS != Linux.String() && runtime.GOOS != Darwin.String() && runtime.GOOS != Windows.String() && !nx.userspaceMode {
+		nx.logger.Info("Security Groups are currently only supported on Linux, macOS and Windows")
 	}
 
 	options := []client.Option{
@@ -532,7 +811,10 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 			options = append(options, client.WithTokenStore(StateTokenStore{store: nx.stateStore}))
 		}
 		if nx.username == "" {
-			options = append(options, client.WithDeviceFlow())
+			options = append(options, client.WithDeviceFlow(), client.WithOidcProvider(nx.oidcProvider))
+			if nx.devicePollViaService {
+				options = append(options, client.WithServiceDevicePolling())
+			}
 		} else if nx.username != "" && nx.password == "" {
 			fmt.Print("Enter nexodus account password: ")
 			passwdInput, err := term.ReadPassword(int(syscall.Stdin))
@@ -551,11 +833,37 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 			InsecureSkipVerify: true,
 		}))
 	}
+	if nx.proxyURL != nil {
+		options = append(options, client.WithProxy(nx.proxyURL))
+	}
 	nx.clientOptions = options
 
-	var err error
-	err = util.RetryOperation(ctx, retryInterval, maxRetries, func() error {
-		return nx.resetApiClient(ctx)
+	if err := nx.handleKeys(); err != nil {
+		return fmt.Errorf("handleKeys: %w", err)
+	}
+
+	// a device that registered before only validates its registration once it knows its endpoints
+	nx.registration = nx.cachedRegistration()
+	if nx.registration != nil && nx.registration.DeviceToken != "" {
+		nx.clientOptions = append(slices.Clip(options), client.WithBearerToken(nx.registration.DeviceToken))
+	}
+
+	// the mesh of the previous run keeps working while the service is unreachable, so keep retrying until it is back
+	retries := maxRetries
+	restored, err := nx.restoreLastKnownConfig()
+	if err != nil {
+		nx.logger.Warnf("Failed to restore the last known peer configuration: %v", err)
+	}
+	if restored {
+		retries = math.MaxInt
+	}
+
+	err = util.RetryOperation(ctx, retryInterval, retries, func() error {
+		err := nx.resetApiClient(ctx)
+		if isUpgradeRequired(err) {
+			return backoff.Permanent(err)
+		}
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("client api error: %w", err)
@@ -563,15 +871,25 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 
 	nx.SetStatus(NexdStatusRunning, "")
 
-	if err := nx.handleKeys(); err != nil {
-		return fmt.Errorf("handleKeys: %w", err)
+	var userId string
+	if nx.registration != nil {
+		userId = nx.registration.UserID
+		nx.vpcId = nx.registration.VPC.Id
+		nx.vpc = registrationVPC(nx.registration)
+	} else {
+		userId, nx.vpc, err = nx.fetchUserIdAndVpc(ctx)
+		if err != nil {
+			return err
+		}
 	}
-	userId, vpc, err := nx.fetchUserIdAndVpc(ctx)
-	if err != nil {
+
+	if err := nx.applyListenPortRange(ctx); err != nil {
 		return err
 	}
 
-	nx.vpc = vpc
+	if err := nx.validatePodCIDR(ctx); err != nil {
+		return err
+	}
 
 	// User requested ip --request-ip takes precedent
 	if nx.userProvidedLocalIP != "" {
@@ -585,6 +903,13 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 
 	nx.os = runtime.GOOS
 
+	// the LAN subnets are advertised when the device registers
+	if nx.advertiseLan {
+		if err := nx.discoverLanSubnets(); err != nil {
+			return err
+		}
+	}
+
 	// if this device is a network router node, enable ip forwarding and set up the network router netfilter policy
 	if nx.networkRouter {
 		err := nx.setupNetworkRouterNode()
@@ -593,29 +918,77 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 		}
 	}
 
+	// the traffic of the peers to the pods of this node is forwarded to their veth interfaces
+	if nx.podIPAM != nil {
+		if err := nx.enableForwardingIP(); err != nil {
+			return fmt.Errorf("failed to enable ip forwarding for the pod network: %w", err)
+		}
+	}
+
+	if nx.bgpPeer != "" {
+		if err := nx.startBGP(ctx, wg); err != nil {
+			return err
+		}
+	}
+
+	// the traffic of the peers to the learned routes is forwarded through this node
+	if len(nx.routeFilters) != 0 {
+		if err := nx.enableForwardingIP(); err != nil {
+			return fmt.Errorf("failed to enable ip forwarding for the learned routes: %w", err)
+		}
+	}
+
 	if nx.exitNode.exitNodeOriginEnabled {
 		if err := nx.exitNodeOriginSetup(); err != nil {
 			return fmt.Errorf("failed to setup this device as an exit-node: %w", err)
 		}
 	}
 
-	endpointSocket := net.JoinHostPort(nx.endpointLocalAddress, fmt.Sprintf("%d", nx.listenPort))
-	endpoints := []public.ModelsEndpoint{
-		{
-			Source:  "local",
-			Address: endpointSocket,
-		},
-		{
-			Source:  "stun:" + nx.reflexiveAddrStunSrc,
-			Address: nx.nodeReflexiveAddressIPv4.String(),
-		},
+	var mapped netip.AddrPort
+	if nx.portMapping {
+		nx.startPortMapping()
+		mapped, _ = nx.portMappedEndpoint()
+	}
+	hostCandidates, localPrefixes := nx.hostCandidates()
+	endpoints := nx.endpointCandidates(hostCandidates, nx.nodeReflexiveAddressIPv4, nx.reflexiveAddrStunSrc, mapped)
+	if mapped.IsValid() {
+		nx.nodeReflexiveAddressIPv4 = mapped
 	}
 
 	var modelsDevice public.ModelsDevice
 	var deviceOperationLogMsg string
-	err = util.RetryOperation(ctx, retryInterval, maxRetries, func() error {
+	err = util.RetryOperation(ctx, retryInterval, retries, func() error {
+		if nx.registration != nil {
+			modelsDevice, deviceOperationLogMsg, err = nx.updateRegisteredDeviceOperation(endpoints)
+			if err == nil {
+				return nil
+			}
+			if isUpgradeRequired(err) {
+				return backoff.Permanent(err)
+			}
+			if !errors.Is(err, errRegistrationRejected) {
+				nx.logger.Warnf("device join error - retrying: %v", err)
+				return err
+			}
+			nx.logger.Infof("Registering the device again: %v", err)
+			nx.forgetRegistration()
+			nx.clientOptions = options
+			userId = ""
+		}
+		if userId == "" {
+			// the cached registration was rejected, look up the registration settings like a new device
+			if err := nx.resetApiClient(ctx); err != nil {
+				return err
+			}
+			if userId, nx.vpc, err = nx.fetchUserIdAndVpc(ctx); err != nil {
+				return err
+			}
+		}
 		modelsDevice, deviceOperationLogMsg, err = nx.createOrUpdateDeviceOperation(userId, endpoints)
 		if err != nil {
+			if isUpgradeRequired(err) {
+				return backoff.Permanent(err)
+			}
 			nx.logger.Warnf("device join error - retrying: %v", err)
 			return err
 		}
@@ -625,9 +998,21 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 		return fmt.Errorf("join error %w", err)
 	}
 	nx.logger.Debug(fmt.Sprintf("Device: %+v", modelsDevice))
+	nx.deviceId = modelsDevice.Id
+	nx.hostCandidateAddrs = hostCandidates
+	nx.deviceCacheLock.Lock()
+	nx.localPrefixes = localPrefixes
+	nx.deviceCacheLock.Unlock()
 	nx.logger.Infof("%s with UUID: [ %+v ] into vpc: [ %s (%s) ]",
 		deviceOperationLogMsg, modelsDevice.Id, nx.vpc.Id, nx.vpc.Description)
+	if modelsDevice.Pending {
+		nx.logger.Warnf("The device is pending approval, it receives its peers once an owner of the organization approves it with: nexctl device approve --device-id %s", modelsDevice.Id)
+	}
 
+	var deviceToken string
+	if nx.registration != nil {
+		deviceToken = nx.registration.DeviceToken
+	}
 	// Use the device token to auth with the apiserver...
 	if modelsDevice.BearerToken != "" {
 
@@ -646,19 +1031,22 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 			return err
 		}
 
-		//nx.stateStore.State().DeviceToken = string(data)
-		//err = nx.stateStore.Store()
-		//if err != nil {
-		//	return err
-		//}
-
-		options = append(options, client.WithBearerToken(string(data)))
+		deviceToken = string(data)
+		options = append(options, client.WithBearerToken(deviceToken))
 		nx.client, err = client.NewAPIClient(ctx, nx.apiURL.String(), func(msg string) {}, options...)
 		if err != nil {
 			return err
 		}
 	}
 
+	// the interface is not set up yet, it is configured with the rotated key
+	if nx.rotateKey {
+		if err := nx.rotateKeys(ctx); err != nil {
+			return err
+		}
+	}
+	nx.storeRegistration(userId, modelsDevice, deviceToken)
+
 	informerCtx, informerCancel := context.WithCancel(ctx)
 	nx.informerStop = informerCancel
 
@@ -666,20 +1054,10 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 	informerCtx = nx.client.VPCApi.WatchEvents(informerCtx, nx.vpc.Id).PublicKey(nx.wireguardPubKey).NewSharedInformerContext()
 	nx.securityGroupsInformer = nx.client.VPCApi.ListSecurityGroupsInVPC(informerCtx, nx.vpc.Id).Informer()
 	nx.devicesInformer = nx.client.VPCApi.ListDevicesInVPC(informerCtx, nx.vpc.Id).Informer()
-
-	if nx.relay {
-		peerMap, _, err := nx.devicesInformer.Execute()
-		if err != nil {
-			return err
-		}
-
-		existingRelay, err := nx.orgRelayCheck(peerMap)
-		if err != nil {
-			return err
-		}
-		if existingRelay != "" {
-			return fmt.Errorf("the organization already contains a relay node, device %s needs to be deleted before adding a new relay", existingRelay)
-		}
+	nx.holePunchInformer = nx.client.VPCApi.ListMetadataInVPC(informerCtx, nx.vpc.Id, []string{holePunchMetadataPrefix}).Informer()
+	if nx.dnsListenAddress != "" {
+		nx.servicesInformer = nx.client.VPCApi.ListServicesInVPC(informerCtx, nx.vpc.Id).Informer()
+		nx.dnsRecordsInformer = nx.client.VPCApi.ListDNSRecordsInVPC(informerCtx, nx.vpc.Id).Informer()
 	}
 
 	// a relay node requires ip forwarding and nftable rules, OS type has already been checked
@@ -690,29 +1068,104 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 		if err := nfRelayTablesSetup(wgIface); err != nil {
 			return err
 		}
+		if nx.relayStunPort > 0 {
+			// serve stun so that the devices do not depend on public stun servers
+			server, err := stun.ListenAndStart(fmt.Sprintf(":%d", nx.relayStunPort), nx.logger.Desugar())
+			if err != nil {
+				return fmt.Errorf("failed to start the stun server: %w", err)
+			}
+			nx.stunServer = server
+		}
 	}
 
 	if nx.relayDerp {
 		nx.Derper.StartDerp()
 	}
 
+	if nx.dnsListenAddress != "" {
+		if err := nx.startOverlayDNS(ctx, wg); err != nil {
+			return err
+		}
+	}
+
+	nx.confirmUpdate()
+	if err := nx.fetchFeatureFlags(ctx); err != nil {
+		nx.logger.Warnf("failed to fetch the feature flags: %v", err)
+	}
+	if !nx.relay {
+		// relays peer with every device, leave probing to the devices themselves
+		util.GoWithWaitGroup(wg, func() {
+			nx.runReachabilityProbes(ctx)
+		})
+		util.GoWithWaitGroup(wg, func() {
+			nx.runExitNodeClient(ctx)
+		})
+		if nx.lanDiscovery.enabled {
+			util.GoWithWaitGroup(wg, func() {
+				nx.runLanDiscovery(ctx)
+			})
+		}
+	}
+	if nx.autoUpdate {
+		util.GoWithWaitGroup(wg, func() {
+			nx.runUpdater(ctx)
+		})
+	}
+	util.GoWithWaitGroup(wg, func() {
+		nx.runFeatureFlags(ctx)
+	})
+	util.GoWithWaitGroup(wg, func() {
+		nx.runRelayReporting(ctx)
+	})
+	util.GoWithWaitGroup(wg, func() {
+		nx.runAddressMonitor(ctx)
+	})
+	if len(nx.routeFilters) != 0 {
+		util.GoWithWaitGroup(wg, func() {
+			nx.runRouteLearning(ctx)
+		})
+	}
+	if nx.reportPeerLatency {
+		util.GoWithWaitGroup(wg, func() {
+			nx.runPeerLatencyReporting(ctx)
+		})
+	}
+	if nx.reportConnectivity && !nx.relay {
+		util.GoWithWaitGroup(wg, func() {
+			nx.runConnectivityReporting(ctx)
+		})
+	}
+	util.GoWithWaitGroup(wg, func() {
+		nx.runTrafficAccounting(ctx)
+	})
+
 	util.GoWithWaitGroup(wg, func() {
 		// kick it off with an immediate reconcile
 		nx.reconcileDevices(ctx, options)
 		nx.reconcileSecurityGroups(ctx)
+		// the interface is up and the peers are configured
+		nx.notifyReady()
 		for _, proxy := range nx.proxies {
 			proxy.Start(ctx, wg, nx.userspaceNet)
 		}
+		if nx.socksListen != "" {
+			util.GoWithWaitGroup(wg, func() {
+				if err := nx.runSocksProxy(ctx, wg); err != nil {
+					nx.logger.Errorf("The socks proxy stopped: %v", err)
+				}
+			})
+		}
 		if nx.exitNode.exitNodeClientEnabled {
 			if err := nx.ExitNodeClientSetup(); err != nil {
 				nx.logger.Errorf("failed to enable this device as an exit-node client: %v", err)
 			}
 		}
 		stunTicker := time.NewTicker(time.Second * 20)
-		secGroupTicker := time.NewTicker(time.Second * 20)
 		defer stunTicker.Stop()
-		pollTicker := time.NewTicker(pollInterval)
-		defer pollTicker.Stop()
+		secGroupTimer := time.NewTimer(util.Jitter(secGroupInterval))
+		defer secGroupTimer.Stop()
+		pollTimer := time.NewTimer(nx.apiBackOff.next(pollInterval))
+		defer pollTimer.Stop()
 		for {
 			select {
 			case <-ctx.Done():
@@ -723,23 +1176,48 @@ func (nx *Nexodus) Start(ctx context.Context, wg *sync.WaitGroup) error {
 						nx.logger.Debug(err)
 					}
 				}
+			case <-nx.addressChanged:
+				if err := nx.handleAddressChange(ctx, modelsDevice.Id); err != nil {
+					nx.logger.Warn(err)
+				}
 			case <-nx.devicesInformer.Changed():
 				nx.reconcileDevices(ctx, options)
 			case <-nx.securityGroupsInformer.Changed():
 				nx.reconcileSecurityGroups(ctx)
-			case <-pollTicker.C:
+			case <-nx.holePunchInformer.Changed():
+				nx.reconcileHolePunches(ctx)
+			case <-nx.servicesChanged():
+				nx.reconcileServices()
+			case <-nx.dnsRecordsChanged():
+				nx.reconcileDNSRecords()
+			case <-pollTimer.C:
 				// This does not actually poll the API for changes. Peer configuration changes will only
 				// be processed when they come in on the informer. This periodic check is needed to
 				// re-establish our connection to the API if it is lost.
 				nx.reconcileDevices(ctx, options)
-			case <-secGroupTicker.C:
+				pollTimer.Reset(nx.apiBackOff.next(pollInterval))
+			case <-secGroupTimer.C:
 				nx.reconcileSecurityGroups(ctx)
+				secGroupTimer.Reset(util.Jitter(secGroupInterval))
+			case req := <-nx.reloadCh:
+				changes, err := nx.applyReload(ctx, req.options)
+				req.result <- reloadResult{changes: changes, err: err}
+			case <-nx.bgpChanged():
+			case routes := <-nx.learnedRoutesCh:
+				nx.learnedRoutes = routes
 			}
 			if nx.needSecGroupReconcile {
 				// device reconcile noticed that the security group Id changed
 				nx.reconcileSecurityGroups(ctx)
 				nx.needSecGroupReconcile = false
 			}
+			if nx.bgp != nil || len(nx.routeFilters) != 0 {
+				// the routing table or the routes of the BGP peer may have changed
+				nx.reconcileLearnedCidrs(ctx)
+			}
+			if nx.bgp != nil {
+				nx.exportBGPRoutes()
+			}
 		}
 	})
 
@@ -789,6 +1267,9 @@ func (nx *Nexodus) fetchUserIdAndVpcFromAPI(ctx context.Context) (string, *publi
 	err = util.RetryOperationExpBackoff(ctx, retryInterval, func() error {
 		user, resp, err = nx.client.UsersApi.GetUser(ctx, "me").Execute()
 		if err != nil {
+			if isUpgradeRequired(err) {
+				return backoff.Permanent(err)
+			}
 			if strings.Contains(err.Error(), invalidTokenGrant.Error()) || strings.Contains(err.Error(), invalidToken.Error()) ||
 				strings.Contains(resp.Header.Get("Www-Authenticate"), invalidToken.Error()) {
 				nx.logger.Debug("invalid auth token, removing and retrying")
@@ -803,6 +1284,7 @@ func (nx *Nexodus) fetchUserIdAndVpcFromAPI(ctx context.Context) (string, *publi
 				return err
 			} else {
 				nx.logger.Warnf("get user error - retrying error: %v", err)
+				nx.checkCaptivePortal(ctx, err)
 				return err
 			}
 		}
@@ -821,6 +1303,9 @@ func (nx *Nexodus) fetchUserIdAndVpcFromAPI(ctx context.Context) (string, *publi
 
 		vpc, resp, err = nx.client.VPCApi.GetVPC(ctx, nx.vpcId).Execute()
 		if err != nil {
+			if isUpgradeRequired(err) {
+				return backoff.Permanent(err)
+			}
 			if resp != nil {
 				nx.logger.Warnf("get vpc error - retrying error: %v header: %+v", err, resp.Header)
 				return err
@@ -842,6 +1327,7 @@ func (nx *Nexodus) fetchUserIdAndVpcFromAPI(ctx context.Context) (string, *publi
 
 func (nx *Nexodus) Stop() {
 	nx.logger.Info("Stopping nexd")
+	nx.notifyStopping()
 	for _, proxy := range nx.proxies {
 		proxy.Stop()
 	}
@@ -851,6 +1337,9 @@ func (nx *Nexodus) Stop() {
 		if err := nx.exitNodeClientTeardown(); err != nil {
 			nx.logger.Errorf("failed to remove the exit node client configuration %v", err)
 		}
+		if err := nx.exitNodeKillSwitchTeardown(); err != nil {
+			nx.logger.Errorf("failed to remove the exit node kill switch %v", err)
+		}
 	}
 
 	if nx.exitNode.exitNodeOriginEnabled {
@@ -860,6 +1349,15 @@ func (nx *Nexodus) Stop() {
 		}
 	}
 
+	nx.stopPortMapping()
+
+	if nx.stunServer != nil {
+		nx.logger.Info("Stopping STUN Server")
+		if err := nx.stunServer.Shutdown(); err != nil {
+			nx.logger.Debugf("failed to stop the stun server: %v", err)
+		}
+	}
+
 	if nx.Derper != nil {
 		nx.logger.Info("Stopping Derp Server")
 		nx.Derper.StopDerper()
@@ -872,7 +1370,7 @@ func (nx *Nexodus) Stop() {
 
 // reconcileSecurityGroups will check the security group and update it if necessary.
 func (nx *Nexodus) reconcileSecurityGroups(ctx context.Context) {
-	if runtime.GOOS != Linux.String() && runtime.GOOS != Darwin.String() || nx.userspaceMode {
+	if runtime.GOOS != Linux.String() && runtime.GOOS != Darwin.String() && runtime.GOOS != Windows.String() && !nx.userspaceMode {
 		return
 	}
 
@@ -882,7 +1380,18 @@ func (nx *Nexodus) reconcileSecurityGroups(ctx context.Context) {
 		return
 	}
 
-	if existing.device.SecurityGroupId == uuid.Nil.String() {
+	apply := func() {
+		if err := nx.applySecurityGroupRules(); err != nil {
+			nx.logger.Error(err)
+			return
+		}
+		nx.deviceCacheLock.Lock()
+		nx.storeLastKnownConfig(nil)
+		nx.deviceCacheLock.Unlock()
+	}
+
+	groupIds := deviceSecurityGroupIds(existing.device)
+	if len(groupIds) == 0 {
 		// local device has no security group
 		if nx.securityGroup == nil {
 			// already set up that way, nothing to do
@@ -890,36 +1399,42 @@ func (nx *Nexodus) reconcileSecurityGroups(ctx context.Context) {
 		}
 		// drop local security group configuration
 		nx.securityGroup = nil
-		if err := nx.processSecurityGroupRules(); err != nil {
-			nx.logger.Error(err)
-		}
+		apply()
+		return
+	}
+
+	if !nx.apiBackOff.ready() {
+		// the api server is unreachable, wait for the device reconcile to re-establish the connection
 		return
 	}
 
-	// if the security group ID is not nil, lookup the ID and check for any changes
-	securityGroups, httpResp, err := nx.securityGroupsInformer.Execute()
+	// if the device has security groups, lookup the IDs and check for any changes
+	securityGroups, httpResp, err := nx.listSecurityGroups()
 	if err != nil {
 		// if the group ID returns a 404, clear the current rules
 		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
 			nx.securityGroup = nil
-			if err := nx.processSecurityGroupRules(); err != nil {
-				nx.logger.Error(err)
-			}
+			apply()
 			return
 		}
 		nx.logger.Errorf("Error retrieving the security groups: %v", err)
 		return
 	}
 
-	responseSecGroup, found := securityGroups[existing.device.SecurityGroupId]
-	if !found {
-		nx.securityGroup = nil
-		if err := nx.processSecurityGroupRules(); err != nil {
-			nx.logger.Error(err)
+	var groups []public.ModelsSecurityGroup
+	for _, id := range groupIds {
+		if group, found := securityGroups[id]; found {
+			groups = append(groups, group)
+		} else {
+			nx.logger.Errorf("Error retrieving the security group %s", id)
 		}
-		nx.logger.Errorf("Error retrieving the security group")
+	}
+	if len(groups) == 0 {
+		nx.securityGroup = nil
+		apply()
 		return
 	}
+	responseSecGroup := mergeSecurityGroups(groups)
 
 	if nx.securityGroup != nil && reflect.DeepEqual(responseSecGroup, *nx.securityGroup) {
 		// no changes to previously applied security group
@@ -938,20 +1453,80 @@ func (nx *Nexodus) reconcileSecurityGroups(ctx context.Context) {
 	}
 
 	// apply the new security group rules
-	if err := nx.processSecurityGroupRules(); err != nil {
-		nx.logger.Error(err)
+	apply()
+}
+
+// applySecurityGroupRules applies the rules of the security group to the userspace datapath or the host firewall
+func (nx *Nexodus) applySecurityGroupRules() error {
+	if nx.userspaceMode {
+		if nx.userspaceFilter == nil {
+			return nil
+		}
+		return nx.userspaceFilter.update(nx.securityGroup, nx.ipv6Supported)
+	}
+	return nx.processSecurityGroupRules()
+}
+
+// deviceSecurityGroupIds returns the ids of the security groups that apply to the device
+func deviceSecurityGroupIds(device public.ModelsDevice) []string {
+	var ids []string
+	if device.SecurityGroupId != "" && device.SecurityGroupId != uuid.Nil.String() {
+		ids = append(ids, device.SecurityGroupId)
+	}
+	for _, id := range device.SecurityGroupIds {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
 	}
+	return ids
+}
+
+// mergeSecurityGroups combines the security groups of a device into the group whose rules are applied locally.
+// The rules of the groups are allow lists, so traffic permitted by any of the groups is permitted. A group without
+// rules in a direction permits all traffic in that direction, so the merged group has no rules in that direction either.
+func mergeSecurityGroups(groups []public.ModelsSecurityGroup) public.ModelsSecurityGroup {
+	merged := groups[0]
+	if len(groups) == 1 {
+		return merged
+	}
+	var descriptions []string
+	allInbound, allOutbound := false, false
+	merged.InboundRules, merged.OutboundRules = nil, nil
+	for _, group := range groups {
+		descriptions = append(descriptions, group.Description)
+		allInbound = allInbound || len(group.InboundRules) == 0
+		allOutbound = allOutbound || len(group.OutboundRules) == 0
+		merged.InboundRules = append(merged.InboundRules, group.InboundRules...)
+		merged.OutboundRules = append(merged.OutboundRules, group.OutboundRules...)
+	}
+	if allInbound {
+		merged.InboundRules = nil
+	}
+	if allOutbound {
+		merged.OutboundRules = nil
+	}
+	merged.Description = strings.Join(descriptions, ", ")
+	return merged
 }
 
 func (nx *Nexodus) reconcileDevices(ctx context.Context, options []client.Option) {
+	if !nx.apiBackOff.ready() {
+		// a previous attempt failed, the poll timer will retry once the backoff expires
+		return
+	}
+
 	var err error
 	if err = nx.reconcileDeviceCache(); err == nil {
+		// the event stream is (re)connected, so resume polling at the normal interval
+		nx.apiBackOff.succeeded()
+		nx.clearCaptivePortal()
 		if !nx.deviceReconciled {
 			nx.deviceReconciled = true
 			nx.logger.Info("Nexodus agent has reconciled state with API server")
 		}
 		return
 	}
+	retryIn := nx.apiBackOff.failed()
 
 	var dnsErr *net.DNSError
 	if errors.As(err, &dnsErr) && dnsErr.Temporary() {
@@ -960,7 +1535,20 @@ func (nx *Nexodus) reconcileDevices(ctx context.Context, options []client.Option
 		return
 	}
 
-	nx.logger.Errorf("Failed to reconcile state with the nexodus API server: %v", err)
+	var upgradeErr *client.UpgradeRequiredError
+	if errors.As(err, &upgradeErr) {
+		nx.logger.Error(upgradeErr.Error())
+		nx.SetStatus(NexdStatusUpgradeRequired, upgradeErr.Error())
+		nx.deviceReconciled = false
+		return
+	}
+
+	if nx.checkCaptivePortal(ctx, err) {
+		nx.deviceReconciled = false
+		return
+	}
+
+	nx.logger.Errorf("Failed to reconcile state with the nexodus API server, retrying in %v: %v", retryIn.Round(time.Second), err)
 	nx.deviceReconciled = false
 
 	// if the token grant becomes invalid expires refresh or exit depending on the onboard method
@@ -986,7 +1574,7 @@ func (nx *Nexodus) reconcileDevices(ctx context.Context, options []client.Option
 		nx.SetStatus(NexdStatusAuth, msg)
 	}, options...)
 	if err != nil {
-		nx.logger.Errorf("Failed to reconnect to the api-server, retrying in %v seconds: %v", pollInterval, err)
+		nx.logger.Errorf("Failed to reconnect to the api-server, retrying in %v: %v", retryIn.Round(time.Second), err)
 		return
 	}
 
@@ -997,43 +1585,64 @@ func (nx *Nexodus) reconcileDevices(ctx context.Context, options []client.Option
 	informerCtx = nx.client.VPCApi.WatchEvents(informerCtx, nx.vpc.Id).NewSharedInformerContext()
 	nx.securityGroupsInformer = nx.client.VPCApi.ListSecurityGroupsInVPC(informerCtx, nx.vpc.Id).Informer()
 	nx.devicesInformer = nx.client.VPCApi.ListDevicesInVPC(informerCtx, nx.vpc.Id).Informer()
+	nx.holePunchInformer = nx.client.VPCApi.ListMetadataInVPC(informerCtx, nx.vpc.Id, []string{holePunchMetadataPrefix}).Informer()
+	if nx.dnsListenAddress != "" {
+		nx.servicesInformer = nx.client.VPCApi.ListServicesInVPC(informerCtx, nx.vpc.Id).Informer()
+		nx.dnsRecordsInformer = nx.client.VPCApi.ListDNSRecordsInVPC(informerCtx, nx.vpc.Id).Informer()
+	}
 
+	nx.apiBackOff.succeeded()
 	nx.SetStatus(NexdStatusRunning, "")
 	nx.logger.Infoln("Nexodus agent has re-established a connection to the api-server")
 }
 
 func (nx *Nexodus) reconcileStun(deviceID string) error {
+	if nx.udpBlocked {
+		if err := nx.checkUDPUnblocked(deviceID); err != nil {
+			return err
+		}
+	}
+	if err := nx.reconcileIPv6Endpoint(deviceID); err != nil {
+		return err
+	}
 	if nx.symmetricNat {
 		return nil
 	}
 
 	nx.logger.Debug("sending stun request")
-	stunServer1 := stun.NextServer()
-	reflexiveIP, err := stun.Request(nx.logger, stunServer1, nx.listenPort)
+	reflexiveIP, stunServer1, err := stun.RequestWithFallback(nx.logger, nx.listenPort)
 	if err != nil {
 		return fmt.Errorf("stun request error: %w", err)
 	}
 
-	if nx.nodeReflexiveAddressIPv4 != reflexiveIP {
-		nx.logger.Infof("detected a NAT binding changed for this device %s from %s to %s, updating peers", deviceID, nx.nodeReflexiveAddressIPv4, reflexiveIP)
+	hostCandidates, localPrefixes := nx.hostCandidates()
+	nx.deviceCacheLock.Lock()
+	nx.localPrefixes = localPrefixes
+	nx.deviceCacheLock.Unlock()
+
+	// peers use the endpoint mapped on the local gateway rather than the stun reflexive address
+	mapped, _ := nx.portMappedEndpoint()
+	nodeReflexiveIP := reflexiveIP
+	if mapped.IsValid() {
+		nodeReflexiveIP = mapped
+	}
+
+	if nx.nodeReflexiveAddressIPv4 != nodeReflexiveIP || !slices.Equal(nx.hostCandidateAddrs, hostCandidates) {
+		if nx.nodeReflexiveAddressIPv4 != nodeReflexiveIP {
+			nx.logger.Infof("detected a NAT binding changed for this device %s from %s to %s, updating peers", deviceID, nx.nodeReflexiveAddressIPv4, nodeReflexiveIP)
+		} else {
+			nx.logger.Infof("detected the host endpoint candidates changed for this device %s to [ %s ], updating peers", deviceID, strings.Join(hostCandidates, ", "))
+		}
 
 		res, _, err := nx.client.DevicesApi.UpdateDevice(context.Background(), deviceID).Update(public.ModelsUpdateDevice{
-			Endpoints: []public.ModelsEndpoint{
-				{
-					Source:  "local",
-					Address: net.JoinHostPort(nx.endpointLocalAddress, fmt.Sprintf("%d", nx.listenPort)),
-				},
-				{
-					Source:  "stun:" + stunServer1,
-					Address: reflexiveIP.String(),
-				},
-			},
+			Endpoints: nx.endpointCandidates(hostCandidates, reflexiveIP, stunServer1, mapped),
 		}).Execute()
 		if err != nil {
 			return fmt.Errorf("failed to update this device's new NAT binding, likely still reconnecting to the api-server, retrying in 20s: %w", err)
 		} else {
 			nx.logger.Debugf("update device response %+v", res)
-			nx.nodeReflexiveAddressIPv4 = reflexiveIP
+			nx.nodeReflexiveAddressIPv4 = nodeReflexiveIP
+			nx.hostCandidateAddrs = hostCandidates
 			// reinitialize peers if the NAT binding has changed for the node
 			if err = nx.reconcileDeviceCache(); err != nil {
 				nx.logger.Debugf("reconcile failed %v", res)
@@ -1122,7 +1731,7 @@ func (nx *Nexodus) addToDeviceCache(p public.ModelsDevice) {
 }
 
 func (nx *Nexodus) reconcileDeviceCache() error {
-	peerMap, resp, err := nx.devicesInformer.Execute()
+	peerMap, resp, err := nx.listDevices()
 	if err != nil {
 		if resp != nil {
 			return fmt.Errorf("error: %w header: %v", err, resp.Header)
@@ -1130,6 +1739,10 @@ func (nx *Nexodus) reconcileDeviceCache() error {
 		return fmt.Errorf("error: %w", err)
 	}
 
+	if nx.overlayDNS != nil {
+		nx.overlayDNS.update(peerMap)
+	}
+
 	// Get the current peer configuration data from the wireguard interface
 	peerStats, err := nx.DumpPeersDefault()
 	if err != nil {
@@ -1153,7 +1766,8 @@ func (nx *Nexodus) reconcileDeviceCache() error {
 		if !ok || deviceUpdated(existing.device, p) {
 			if p.PublicKey == nx.wireguardPubKey {
 				newLocalConfig = true
-				if nx.securityGroup == nil || !reflect.DeepEqual(p.SecurityGroupId, nx.securityGroup.Id) {
+				if nx.securityGroup == nil || !reflect.DeepEqual(p.SecurityGroupId, nx.securityGroup.Id) ||
+					!slices.Equal(existing.device.SecurityGroupIds, p.SecurityGroupIds) {
 					nx.needSecGroupReconcile = true
 				}
 			}
@@ -1161,6 +1775,11 @@ func (nx *Nexodus) reconcileDeviceCache() error {
 			existing = nx.deviceCache[p.PublicKey]
 			delete(peerStats, p.PublicKey)
 		}
+		// the gateways of the shared prefixes are elected from the online state of the devices, which does not impact
+		// the peering with them
+		existing.device.Online = p.Online
+		existing.device.OnlineAt = p.OnlineAt
+		nx.deviceCache[p.PublicKey] = existing
 
 		// Store the relay IP for easy reference later
 		if p.Relay {
@@ -1244,6 +1863,7 @@ func (nx *Nexodus) reconcileDeviceCache() error {
 		nx.logger.Error(err)
 	}
 
+	nx.storeLastKnownConfig(peerMap)
 	return nil
 }
 
@@ -1255,77 +1875,61 @@ func deviceUpdated(d1, d2 public.ModelsDevice) bool {
 		!reflect.DeepEqual(d1.Endpoints, d2.Endpoints) ||
 		d1.Relay != d2.Relay ||
 		d1.SymmetricNat != d2.SymmetricNat ||
-		d1.SecurityGroupId != d2.SecurityGroupId
+		d1.NatType != d2.NatType ||
+		d1.NatHairpin != d2.NatHairpin ||
+		d1.EndpointIpv6 != d2.EndpointIpv6 ||
+		d1.RelayId != d2.RelayId ||
+		d1.SecurityGroupId != d2.SecurityGroupId ||
+		!slices.Equal(d1.SecurityGroupIds, d2.SecurityGroupIds)
 }
 
 // checkUnsupportedConfigs general matrix checks of required information or constraints to run the agent and join the mesh
 func (nx *Nexodus) checkUnsupportedConfigs() error {
 
-	if nx.ipv6Supported = isIPv6Supported(); !nx.ipv6Supported {
+	if nx.disableIPv6 {
+		nx.logger.Info("IPv6 is disabled, only IPv4 will be provisioned")
+		nx.ipv6Supported = false
+	} else if nx.ipv6Supported = isIPv6Supported(); !nx.ipv6Supported {
 		nx.logger.Warn("IPv6 does not appear to be enabled on this host, only IPv4 will be provisioned or restart nexd with IPv6 enabled on this host")
 	}
 
 	return nil
 }
 
-// symmetricNatDisco determine if the joining node is within a symmetric NAT cone
+// symmetricNatDisco determine if the joining node is within a symmetric NAT cone, along with the
+// mapping and hairpinning behavior of the NAT which are reported to the service
 func (nx *Nexodus) symmetricNatDisco(ctx context.Context) error {
 
 	stunRetryTimer := time.Second * 1
 	err := util.RetryOperation(ctx, stunRetryTimer, maxRetries, func() error {
-		stunServer1 := stun.NextServer()
-		stunServer2 := stun.NextServer()
-		stunAddr1, err := stun.Request(nx.logger, stunServer1, nx.listenPort)
+		behavior, err := stun.DiscoverNatBehavior(nx.logger, nx.listenPort)
 		if err != nil {
 			return err
-		} else {
-			nx.nodeReflexiveAddressIPv4 = stunAddr1
-		}
-
-		isSymmetric := false
-		stunAddr2, err := stun.Request(nx.logger, stunServer2, nx.listenPort)
-		if err != nil {
-			return err
-		} else {
-			isSymmetric = stunAddr1.String() != stunAddr2.String()
-		}
-
-		if stunAddr1.Addr().String() != "" {
-			nx.logger.Debugf("first NAT discovery STUN request returned: %s", stunAddr1.String())
-		} else {
-			nx.logger.Debugf("first NAT discovery STUN request returned an empty value")
-		}
-
-		if stunAddr2.Addr().String() != "" {
-			nx.logger.Debugf("second NAT discovery STUN request returned: %s", stunAddr2.String())
-		} else {
-			nx.logger.Debugf("second NAT discovery STUN request returned an empty value")
 		}
+		nx.nodeReflexiveAddressIPv4 = behavior.ReflexiveAddress
+		nx.natBehavior = behavior
+		isSymmetric := behavior.Mapping.EndpointDependent()
+		nx.logger.Debugf("NAT discovery returned the reflexive address %s, mapping behavior %s, hairpinning %v",
+			behavior.ReflexiveAddress, behavior.Mapping, behavior.Hairpin)
 
 		if isSymmetric {
 			nx.symmetricNat = true
+			nx.symmetricNatDetected = true
 			nx.logger.Infof("Symmetric NAT detected. A relay node is required to reach other devices outside of this local network. See See https://docs.nexodus.io/user-guide/relay-nodes/")
 		}
 
 		return nil
 	})
 	if err != nil {
+		if ctx.Err() == nil {
+			nx.noteUDPBlocked()
+		}
 		return fmt.Errorf("STUN discovery error: %w", err)
 	}
 
 	return nil
 }
 
-// orgRelayCheck checks if there is an existing Relay node in the organization that does not match this device's pub key
-func (nx *Nexodus) orgRelayCheck(peerMap map[string]public.ModelsDevice) (string, error) {
-	for _, p := range peerMap {
-		if p.Relay && nx.wireguardPubKey != p.PublicKey {
-			return p.Id, nil
-		}
-	}
-	return "", nil
-}
-
 func (nx *Nexodus) defaultTunnelDev() string {
 	if nx.userspaceMode {
 		return nx.defaultTunnelDevUS()
@@ -3,38 +3,38 @@
 package nexodus
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/nexodus-io/nexodus/internal/util"
 	"go.uber.org/zap"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/ipc"
+	"golang.zx2c4.com/wireguard/tun"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
-	"os/exec"
 )
 
+const wireguardSocketDir = "/var/run/wireguard"
+
 func (nx *Nexodus) setupInterfaceOS() error {
 
 	logger := nx.logger
 	localAddress := nx.TunnelIP
 	localAddressIPv6 := fmt.Sprintf("%s/%s", nx.TunnelIpV6, wgOrgIPv6PrefixLen)
-	dev := nx.tunnelIface
-
-	if ifaceExists(logger, dev) {
-		deleteDarwinIface(logger, dev)
-	}
 
-	// prefer nexd-wireguard-go over wireguard-go since it supports port reuse.
-	wgBinary := wgGoBinary
-	if path, err := exec.LookPath(nexdWgGoBinary); err == nil {
-		wgBinary = path
-	}
+	nx.removeExistingInterface()
 
-	_, err := RunCommand(wgBinary, dev)
-	if err != nil {
-		logger.Errorf("failed to create the %s interface: %v\n", dev, err)
+	if err := nx.createDarwinTunnel(); err != nil {
+		logger.Errorf("failed to create the %s interface: %v\n", nx.tunnelIface, err)
 		return fmt.Errorf("%w", interfaceErr)
 	}
+	dev := nx.tunnelIface
 
-	_, err = RunCommand("ifconfig", dev, "inet", localAddress, localAddress, "alias")
+	_, err := RunCommand("ifconfig", dev, "inet", localAddress, localAddress, "alias")
 	if err != nil {
 		logger.Errorf("failed to assign an IPv4 address to the local osx interface: %v\n", err)
 		return fmt.Errorf("%w", interfaceErr)
@@ -67,11 +67,13 @@ func (nx *Nexodus) setupInterfaceOS() error {
 	}
 	defer util.IgnoreError(c.Close)
 
-	err = c.ConfigureDevice(dev, wgtypes.Config{
-		PrivateKey:   &privateKey,
-		ListenPort:   &nx.listenPort,
-		ReplacePeers: true,
-		Peers:        nil,
+	err = nx.listenWithRetry(func(listenPort int) error {
+		return c.ConfigureDevice(dev, wgtypes.Config{
+			PrivateKey:   &privateKey,
+			ListenPort:   &listenPort,
+			ReplacePeers: true,
+			Peers:        nil,
+		})
 	})
 	if err != nil {
 		logger.Errorf("failed to start the wireguard listener: %v\n", err)
@@ -82,24 +84,91 @@ func (nx *Nexodus) setupInterfaceOS() error {
 }
 
 func (nx *Nexodus) removeExistingInterface() {
-	if ifaceExists(nx.logger, nx.tunnelIface) {
-		deleteDarwinIface(nx.logger, nx.tunnelIface)
+	nx.closeDarwinTunnel()
+	// a wireguard-go process left behind by an earlier nexd exits once its control socket is removed
+	if err := removeWireguardSocket(nx.tunnelIface); err != nil {
+		nx.logger.Warnf("failed to delete the darwin interface %s: %v", nx.tunnelIface, err)
 	}
 }
 
-// deleteDarwinIface delete the darwin userspace wireguard interface
-func deleteDarwinIface(logger *zap.SugaredLogger, dev string) {
-	tunSock := fmt.Sprintf("/var/run/wireguard/%s.sock", dev)
-	_, err := RunCommand("rm", "-f", tunSock)
+// createDarwinTunnel creates the utun device and runs wireguard on it in-process, serving the
+// standard control socket so that wgctrl and the wg tool can manage it. The configured interface
+// name is requested first, if that utun unit is taken the kernel assigns the next free unit.
+func (nx *Nexodus) createDarwinTunnel() error {
+	tunDev, err := tun.CreateTUN(nx.tunnelIface, device.DefaultMTU)
+	if err != nil {
+		nx.logger.Debugf("failed to create %s, requesting the next free utun unit: %v", nx.tunnelIface, err)
+		tunDev, err = tun.CreateTUN("utun", device.DefaultMTU)
+		if err != nil {
+			return fmt.Errorf("failed to create the utun device: %w", err)
+		}
+	}
+	name, err := tunDev.Name()
+	if err != nil {
+		_ = tunDev.Close()
+		return fmt.Errorf("failed to get the utun device name: %w", err)
+	}
+	if name != nx.tunnelIface {
+		nx.logger.Infof("%s is in use, using %s as the tunnel interface", nx.tunnelIface, name)
+		nx.tunnelIface = name
+	}
+
+	uapiFile, err := ipc.UAPIOpen(name)
 	if err != nil {
-		logger.Debugf("failed to delete darwin interface: %v", err)
+		_ = tunDev.Close()
+		return fmt.Errorf("failed to open the wireguard control socket: %w", err)
+	}
+	logger := &device.Logger{
+		Verbosef: device.DiscardLogf,
+		Errorf:   nx.logger.Errorf,
 	}
-	// /var/run/wireguard/wg0.name doesn't currently exist since utun8 isn't mapped to wg0 (fails silently)
-	wgName := fmt.Sprintf("/var/run/wireguard/%s.name", dev)
-	_, err = RunCommand("rm", "-f", wgName)
+	if nx.logger.Level() == zap.DebugLevel {
+		logger.Verbosef = nx.logger.Debugf
+	}
+	wgDev := device.NewDevice(tunDev, conn.NewDefaultBind(), logger)
+	uapi, err := ipc.UAPIListen(name, uapiFile)
 	if err != nil {
-		logger.Debugf("failed to delete darwin interface: %v", err)
+		wgDev.Close()
+		return fmt.Errorf("failed to listen on the wireguard control socket: %w", err)
 	}
+	go func() {
+		for {
+			c, err := uapi.Accept()
+			if err != nil {
+				return
+			}
+			go wgDev.IpcHandle(c)
+		}
+	}()
+
+	nx.tunnelDev = wgDev
+	nx.tunnelUAPI = uapi
+	return nil
+}
+
+// closeDarwinTunnel stops the in-process wireguard device, which also removes the utun device
+func (nx *Nexodus) closeDarwinTunnel() {
+	if nx.tunnelUAPI != nil {
+		util.IgnoreError(nx.tunnelUAPI.Close)
+		nx.tunnelUAPI = nil
+	}
+	if nx.tunnelDev != nil {
+		nx.tunnelDev.Close()
+		nx.tunnelDev = nil
+	}
+}
+
+// removeWireguardSocket deletes the wireguard control socket and name mapping of the interface
+func removeWireguardSocket(dev string) error {
+	for _, file := range []string{
+		filepath.Join(wireguardSocketDir, dev+".sock"),
+		filepath.Join(wireguardSocketDir, dev+".name"),
+	} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
 }
 
 func (nx *Nexodus) findLocalIP() (string, error) {
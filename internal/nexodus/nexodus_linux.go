@@ -3,46 +3,56 @@
 package nexodus
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
-	"strings"
 
 	"github.com/nexodus-io/nexodus/internal/util"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 	"golang.zx2c4.com/wireguard/wgctrl"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 )
 
-func (nx *Nexodus) runIpLinkAdd() (string, error) {
+// errKernelWireguardUnsupported is returned when the kernel has no wireguard support
+var errKernelWireguardUnsupported = errors.New("kernel wireguard support unavailable")
+
+// addWireguardLink creates the kernel wireguard interface
+func (nx *Nexodus) addWireguardLink() error {
 	if _, found := os.LookupEnv("NEXD_USE_WIREGUARD_GO"); found {
-		return "", fmt.Errorf("Error: Unknown device type.")
+		return errKernelWireguardUnsupported
+	}
+	err := netlink.LinkAdd(&netlink.Wireguard{LinkAttrs: netlink.LinkAttrs{Name: nx.tunnelIface}})
+	if errors.Is(err, unix.EOPNOTSUPP) {
+		return errKernelWireguardUnsupported
 	}
-	return RunCommand("ip", "link", "add", nx.tunnelIface, "type", "wireguard")
+	return err
 }
 
-// setupLinuxInterface TODO replace with netlink calls
+// setupInterfaceOS creates and configures the wireguard interface using netlink.
 // this is called if this is the first run or if the local node
 // address got assigned a new address by the controller
 func (nx *Nexodus) setupInterfaceOS() error {
 
 	logger := nx.logger
-	// delete the wireguard ip link interface if it exists
-	if ifaceExists(logger, nx.tunnelIface) {
-		_, err := RunCommand("ip", "link", "del", nx.tunnelIface)
-		if err != nil {
-			logger.Debugf("failed to delete the ip link interface: %v\n", err)
+	// delete the wireguard interface if it exists
+	if linkExists(nx.tunnelIface) {
+		if err := delLink(nx.tunnelIface); err != nil {
+			logger.Debugf("failed to delete the netlink interface: %v\n", err)
 		}
 	}
 
-	if nx.TunnelIP == "" || nx.TunnelIpV6 == "" {
+	if nx.TunnelIP == "" || (nx.ipv6Supported && nx.TunnelIpV6 == "") {
 		return fmt.Errorf("Have not received local node address configuration from the service, returning for a retry")
 	}
 
-	// create the wireguard ip link interface
-	_, err := nx.runIpLinkAdd()
+	// create the wireguard interface
+	err := nx.addWireguardLink()
 	if err != nil {
-		if !strings.Contains(err.Error(), "Error: Unknown device type.") {
-			logger.Errorf("failed to create the ip link interface: %v\n", err)
+		if !errors.Is(err, errKernelWireguardUnsupported) {
+			logger.Errorf("failed to create the netlink interface: %v\n", err)
 			return fmt.Errorf("%w", interfaceErr)
 		}
 		// the linux kernel might not be compiled with wg support.
@@ -76,12 +86,6 @@ func (nx *Nexodus) setupInterfaceOS() error {
 		}
 	}
 
-	listenPort := nx.listenPort
-	// start the wireguard listener on a well-known port if it is a discovery or relay node as all
-	// nodes need to be able to reach those services for state distribution, hole punching and relay.
-	if nx.relay {
-		listenPort = WgDefaultPort
-	}
 	privateKey, err := wgtypes.ParseKey(nx.wireguardPvtKey)
 	if err != nil {
 		logger.Errorf("invalid wiregaurd private key: %v\n", err)
@@ -94,11 +98,13 @@ func (nx *Nexodus) setupInterfaceOS() error {
 	}
 	defer util.IgnoreError(c.Close)
 
-	err = c.ConfigureDevice(nx.tunnelIface, wgtypes.Config{
-		PrivateKey:   &privateKey,
-		ListenPort:   &listenPort,
-		ReplacePeers: true,
-		Peers:        nil,
+	err = nx.listenWithRetry(func(listenPort int) error {
+		return c.ConfigureDevice(nx.tunnelIface, wgtypes.Config{
+			PrivateKey:   &privateKey,
+			ListenPort:   &listenPort,
+			ReplacePeers: true,
+			Peers:        nil,
+		})
 	})
 
 	if err != nil {
@@ -106,33 +112,51 @@ func (nx *Nexodus) setupInterfaceOS() error {
 		return fmt.Errorf("%w", interfaceErr)
 	}
 
+	link, err := netlink.LinkByName(nx.tunnelIface)
+	if err != nil {
+		logger.Errorf("failed to lookup netlink device %s: %v\n", nx.tunnelIface, err)
+		return fmt.Errorf("%w", interfaceErr)
+	}
+
 	// assign the wg interface a v6 address
 	if nx.ipv6Supported {
 		localAddressIPv6 := fmt.Sprintf("%s/%s", nx.TunnelIpV6, wgOrgIPv6PrefixLen)
-		_, err = RunCommand("ip", "-6", "address", "add", localAddressIPv6, "dev", nx.tunnelIface)
+		addr, err := netlink.ParseAddr(localAddressIPv6)
+		if err == nil {
+			err = netlink.AddrAdd(link, addr)
+		}
 		if err != nil {
 			logger.Infof("failed to assign an IPv6 address to the local linux ipv6 interface, ensure v6 is supported: %v\n", err)
 		}
 	}
 
 	// assign the wg interface a v4 address, delete the existing if one is present
-	_, err = RunCommand("ip", "address", "add", nx.TunnelIP, "dev", nx.tunnelIface)
+	localAddress, err := hostAddr(nx.TunnelIP)
+	if err != nil {
+		logger.Errorf("invalid tunnel address %s: %v\n", nx.TunnelIP, err)
+		return fmt.Errorf("%w", interfaceErr)
+	}
+	err = netlink.AddrAdd(link, localAddress)
 	if err != nil {
 		logger.Debugf("failed to assign an address to the local linux interface, attempting to flush the iface: %v\n", err)
-		wgIP := nx.getIPv4Iface(nx.tunnelIface)
 		// TODO: this is likely legacy from a push model, should be ok to remove the deletes since the agent now deletes wg0 on startup
-		_, err = RunCommand("ip", "address", "del", wgIP.To4().String(), "dev", nx.tunnelIface)
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
 		if err != nil {
-			logger.Errorf("failed to assign an IPv4 address to the local linux interface: %v\n", err)
+			logger.Errorf("failed to list the IPv4 addresses of the local linux interface: %v\n", err)
+		}
+		for i := range addrs {
+			if err := netlink.AddrDel(link, &addrs[i]); err != nil {
+				logger.Errorf("failed to delete the IPv4 address %s from the local linux interface: %v\n", addrs[i].IPNet, err)
+			}
 		}
-		_, err = RunCommand("ip", "address", "add", nx.TunnelIP, "dev", nx.tunnelIface)
+		err = netlink.AddrAdd(link, localAddress)
 		if err != nil {
 			logger.Errorf("failed to assign an address to the local linux interface: %v\n", err)
 			return fmt.Errorf("%w", interfaceErr)
 		}
 	}
 	// bring the wg0 interface up
-	_, err = RunCommand("ip", "link", "set", nx.tunnelIface, "up")
+	err = netlink.LinkSetUp(link)
 	if err != nil {
 		logger.Errorf("failed to bring up the wg interface: %v\n", err)
 		return fmt.Errorf("%w", interfaceErr)
@@ -163,3 +187,20 @@ func (nx *Nexodus) findLocalIP() (string, error) {
 func (nx *Nexodus) configureLoopback(ip string) error {
 	return nil
 }
+
+// hostAddr returns a single host netlink address for the ip, an address with a prefix length is used as is
+func hostAddr(ip string) (*netlink.Addr, error) {
+	if addr, err := netlink.ParseAddr(ip); err == nil {
+		return addr, nil
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid address: %s", ip)
+	}
+	bits := 128
+	if parsed.To4() != nil {
+		parsed = parsed.To4()
+		bits = 32
+	}
+	return &netlink.Addr{IPNet: &net.IPNet{IP: parsed, Mask: net.CIDRMask(bits, bits)}}, nil
+}
@@ -0,0 +1,35 @@
+package nexodus
+
+import (
+	"testing"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceSecurityGroupIds(t *testing.T) {
+	assert.Empty(t, deviceSecurityGroupIds(public.ModelsDevice{SecurityGroupId: "00000000-0000-0000-0000-000000000000"}))
+	assert.Equal(t, []string{"a", "b"}, deviceSecurityGroupIds(public.ModelsDevice{
+		SecurityGroupId:  "a",
+		SecurityGroupIds: []string{"a", "b"},
+	}))
+}
+
+func TestMergeSecurityGroups(t *testing.T) {
+	ssh := public.ModelsSecurityRule{IpProtocol: "tcp", FromPort: 22, ToPort: 22}
+	http := public.ModelsSecurityRule{IpProtocol: "tcp", FromPort: 80, ToPort: 80}
+	dns := public.ModelsSecurityRule{IpProtocol: "udp", FromPort: 53, ToPort: 53}
+
+	single := public.ModelsSecurityGroup{Id: "a", InboundRules: []public.ModelsSecurityRule{ssh}}
+	assert.Equal(t, single, mergeSecurityGroups([]public.ModelsSecurityGroup{single}))
+
+	merged := mergeSecurityGroups([]public.ModelsSecurityGroup{
+		{Id: "a", Description: "ssh", InboundRules: []public.ModelsSecurityRule{ssh}, OutboundRules: []public.ModelsSecurityRule{dns}},
+		{Id: "b", Description: "web", InboundRules: []public.ModelsSecurityRule{http}},
+	})
+	assert.Equal(t, "a", merged.Id)
+	assert.Equal(t, "ssh, web", merged.Description)
+	assert.Equal(t, []public.ModelsSecurityRule{ssh, http}, merged.InboundRules)
+	// the second group permits all outbound traffic
+	assert.Empty(t, merged.OutboundRules)
+}
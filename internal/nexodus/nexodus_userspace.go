@@ -16,11 +16,14 @@ import (
 const defaultDeviceName = "go"
 
 func (nx *Nexodus) setupInterfaceUS() error {
+	localAddresses := []netip.Addr{
+		netip.MustParseAddr(nx.TunnelIP),
+	}
+	if nx.TunnelIpV6 != "" {
+		localAddresses = append(localAddresses, netip.MustParseAddr(nx.TunnelIpV6))
+	}
 	tun, tnet, err := netstack.CreateNetTUN(
-		[]netip.Addr{
-			netip.MustParseAddr(nx.TunnelIP),
-			netip.MustParseAddr(nx.TunnelIpV6),
-		},
+		localAddresses,
 		// TODO - Is there something else that makes more sense as a DNS server?
 		// So far I don't think DNS will ever be used. If Nexodus has its own
 		// built-in DNS, that would make sense here.
@@ -38,6 +41,11 @@ func (nx *Nexodus) setupInterfaceUS() error {
 	}
 	nx.userspaceTun = tun
 	nx.userspaceNet = tnet
+	nx.userspaceFilter = newPacketFilter(tun)
+	// the interface is recreated when the tunnel address changes, keep applying the current security group
+	if err := nx.userspaceFilter.update(nx.securityGroup, nx.ipv6Supported); err != nil {
+		nx.logger.Error(err)
+	}
 	logger := &device.Logger{
 		Verbosef: device.DiscardLogf,
 		Errorf:   nx.logger.Errorf,
@@ -45,7 +53,7 @@ func (nx *Nexodus) setupInterfaceUS() error {
 	if nx.logger.Level() == zap.DebugLevel {
 		logger.Verbosef = nx.logger.Debugf
 	}
-	dev := device.NewDevice(nx.userspaceTun, conn.NewDefaultBind(), logger)
+	dev := device.NewDevice(nx.userspaceFilter, conn.NewDefaultBind(), logger)
 	pvtDecoded, err := base64.StdEncoding.DecodeString(nx.wireguardPvtKey)
 	if err != nil {
 		nx.logger.Errorf("Failed to decode wireguard private key: %w", err)
@@ -56,7 +64,9 @@ func (nx *Nexodus) setupInterfaceUS() error {
 		nx.logger.Errorf("Failed to set private key: %w", err)
 		return err
 	}
-	err = dev.IpcSet(fmt.Sprintf("listen_port=%d", nx.listenPort))
+	err = nx.listenWithRetry(func(listenPort int) error {
+		return dev.IpcSet(fmt.Sprintf("listen_port=%d", listenPort))
+	})
 	if err != nil {
 		nx.logger.Errorf("Failed to set listen port: %w", err)
 		return err
@@ -5,10 +5,14 @@ package nexodus
 import (
 	"errors"
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"text/template"
 	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
 )
 
 var (
@@ -18,6 +22,8 @@ var (
 const (
 	windowsConfFilePermissions = 0644
 	windowsWgConfigFile        = "C:/nexd/wg0.conf"
+	// prefer the tunnel over physical interfaces for routes of equal length
+	windowsTunnelMetric = 5
 )
 
 func (nx *Nexodus) setupInterfaceOS() error {
@@ -55,6 +61,51 @@ func (nx *Nexodus) setupInterfaceOS() error {
 		return fmt.Errorf("failed to create the windows wireguard interface: %w", err)
 	}
 
+	if err := nx.configureInterfaceWinIPCfg(dev); err != nil {
+		// roll back the tunnel service so a half configured interface is not left behind
+		nx.removeExistingInterface()
+		return fmt.Errorf("failed to configure the windows wireguard interface: %w", err)
+	}
+
+	return nil
+}
+
+// configureInterfaceWinIPCfg assigns the tunnel addresses and sets the metric of the interface using the IP helper API
+func (nx *Nexodus) configureInterfaceWinIPCfg(dev string) error {
+	luid, err := interfaceLUID(dev)
+	if err != nil {
+		return err
+	}
+
+	address, err := netip.ParsePrefix(fmt.Sprintf("%s/32", nx.TunnelIP))
+	if err != nil {
+		return fmt.Errorf("invalid tunnel address %s: %w", nx.TunnelIP, err)
+	}
+	addresses := []netip.Prefix{address}
+	families := []winipcfg.AddressFamily{windows.AF_INET}
+	if nx.ipv6Supported && nx.TunnelIpV6 != "" {
+		addressV6, err := netip.ParsePrefix(fmt.Sprintf("%s/%s", nx.TunnelIpV6, wgOrgIPv6PrefixLen))
+		if err != nil {
+			return fmt.Errorf("invalid tunnel address %s: %w", nx.TunnelIpV6, err)
+		}
+		addresses = append(addresses, addressV6)
+		families = append(families, windows.AF_INET6)
+	}
+	if err := luid.SetIPAddresses(addresses); err != nil {
+		return fmt.Errorf("failed to assign the tunnel addresses: %w", err)
+	}
+
+	for _, family := range families {
+		iface, err := luid.IPInterface(family)
+		if err != nil {
+			return fmt.Errorf("failed to lookup the %s interface settings: %w", dev, err)
+		}
+		iface.UseAutomaticMetric = false
+		iface.Metric = windowsTunnelMetric
+		if err := iface.Set(); err != nil {
+			return fmt.Errorf("failed to set the %s interface metric: %w", dev, err)
+		}
+	}
 	return nil
 }
 
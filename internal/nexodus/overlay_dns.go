@@ -0,0 +1,367 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.uber.org/zap"
+)
+
+const (
+	// overlayDNSDomain is the domain the names of the devices of an organization are published under
+	overlayDNSDomain = "nexodus.local"
+	overlayDNSTTL    = 60
+)
+
+// overlayDNS answers queries for <device>.<organization>.nexodus.local using the tunnel addresses of the
+// devices in the device list the agent receives from the service. The services of the organization are
+// published as <service>.<organization>.nexodus.local with the addresses of their device, and as
+// _<service>._<protocol>.<organization>.nexodus.local SRV records with their port. The custom A, AAAA and
+// CNAME records of the organization are served in the same zone.
+type overlayDNS struct {
+	logger     *zap.SugaredLogger
+	zone       string
+	lock       sync.RWMutex
+	devices    map[string]public.ModelsDevice
+	services   map[string]public.ModelsService
+	dnsRecords map[string]public.ModelsDNSRecord
+	records    map[string][]net.IP
+	srvRecords map[string][]srvTarget
+	cnames     map[string]string
+}
+
+// srvTarget is the device name and the port a service is reached at
+type srvTarget struct {
+	target string
+	port   uint16
+}
+
+func newOverlayDNS(logger *zap.SugaredLogger, organization string) *overlayDNS {
+	return &overlayDNS{
+		logger:     logger,
+		zone:       overlayDNSZone(organization),
+		records:    map[string][]net.IP{},
+		srvRecords: map[string][]srvTarget{},
+		cnames:     map[string]string{},
+	}
+}
+
+// overlayDNSZone returns the fully qualified zone that the devices of the organization are published in
+func overlayDNSZone(organization string) string {
+	return dns.Fqdn(util.DNSLabel(organization) + "." + overlayDNSDomain)
+}
+
+// deviceDNSName returns the name reserved for the device, falling back to its hostname for devices
+// that were registered before names were reserved by the service.
+func deviceDNSName(device public.ModelsDevice) string {
+	if device.DnsName != "" {
+		return device.DnsName
+	}
+	return util.DNSLabel(device.Hostname)
+}
+
+// update replaces the records with the tunnel addresses of the devices
+func (o *overlayDNS) update(devices map[string]public.ModelsDevice) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.devices = devices
+	o.rebuild()
+}
+
+// updateServices replaces the records of the services
+func (o *overlayDNS) updateServices(services map[string]public.ModelsService) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.services = services
+	o.rebuild()
+}
+
+// updateRecords replaces the custom records of the organization
+func (o *overlayDNS) updateRecords(dnsRecords map[string]public.ModelsDNSRecord) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.dnsRecords = dnsRecords
+	o.rebuild()
+}
+
+// cnameTarget returns the fully qualified target of a CNAME record, a target without a dot is a name in the zone
+func (o *overlayDNS) cnameTarget(value string) string {
+	value = strings.ToLower(strings.TrimSuffix(value, "."))
+	if !strings.Contains(value, ".") {
+		return value + "." + o.zone
+	}
+	return dns.Fqdn(value)
+}
+
+// rebuild computes the records from the devices, the services and the custom records, the caller must hold the
+// write lock
+func (o *overlayDNS) rebuild() {
+	records := map[string][]net.IP{}
+	deviceNames := map[string]string{}
+	for _, device := range o.devices {
+		name := deviceDNSName(device)
+		if name == "" {
+			continue
+		}
+		fqdn := name + "." + o.zone
+		deviceNames[device.Id] = fqdn
+		for _, tunnelIP := range append(device.Ipv4TunnelIps, device.Ipv6TunnelIps...) {
+			if ip := net.ParseIP(tunnelIP.Address); ip != nil {
+				records[fqdn] = append(records[fqdn], ip)
+			}
+		}
+	}
+
+	srvRecords := map[string][]srvTarget{}
+	for _, service := range o.services {
+		// the services of the devices of other vpcs can not be reached from this device
+		target, found := deviceNames[service.DeviceId]
+		if !found || service.Name == "" || service.Port <= 0 || service.Port > 65535 {
+			continue
+		}
+		fqdn := service.Name + "." + o.zone
+		if _, taken := records[fqdn]; !taken {
+			// the service shares the namespace of the device names, the service keeps them unique
+			records[fqdn] = records[target]
+		}
+		srvName := fmt.Sprintf("_%s._%s.%s", service.Name, service.Protocol, o.zone)
+		srvRecords[srvName] = append(srvRecords[srvName], srvTarget{target: target, port: uint16(service.Port)})
+	}
+
+	cnames := map[string]string{}
+	for _, record := range o.dnsRecords {
+		if record.Name == "" {
+			continue
+		}
+		fqdn := strings.ToLower(record.Name) + "." + o.zone
+		switch record.Type {
+		case "A", "AAAA":
+			if ip := net.ParseIP(record.Value); ip != nil {
+				records[fqdn] = append(records[fqdn], ip)
+			}
+		case "CNAME":
+			cnames[fqdn] = o.cnameTarget(record.Value)
+		}
+	}
+
+	o.records = records
+	o.srvRecords = srvRecords
+	o.cnames = cnames
+}
+
+// lookup returns the addresses of a name, a name without a domain is looked up in the zone of the organization
+func (o *overlayDNS) lookup(name string) []net.IP {
+	name = strings.ToLower(dns.Fqdn(name))
+	if !dns.IsSubDomain(o.zone, name) {
+		name = name + o.zone
+	}
+
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	if target, found := o.cnames[name]; found {
+		return o.records[target]
+	}
+	return o.records[name]
+}
+
+// names returns the published names with their addresses
+func (o *overlayDNS) names() map[string][]string {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	names := map[string][]string{}
+	for name, ips := range o.records {
+		addrs := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			addrs = append(addrs, ip.String())
+		}
+		sort.Strings(addrs)
+		names[strings.TrimSuffix(name, ".")] = addrs
+	}
+	for name, targets := range o.srvRecords {
+		addrs := make([]string, 0, len(targets))
+		for _, target := range targets {
+			addrs = append(addrs, net.JoinHostPort(strings.TrimSuffix(target.target, "."), fmt.Sprint(target.port)))
+		}
+		sort.Strings(addrs)
+		names[strings.TrimSuffix(name, ".")] = addrs
+	}
+	for name, target := range o.cnames {
+		names[strings.TrimSuffix(name, ".")] = []string{strings.TrimSuffix(target, ".")}
+	}
+	return names
+}
+
+// ServeDNS answers A, AAAA, CNAME and SRV queries for the names in the zone of the organization
+func (o *overlayDNS) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := &dns.Msg{}
+	m.SetReply(r)
+	m.Authoritative = true
+
+	if len(r.Question) != 1 {
+		m.SetRcode(r, dns.RcodeFormatError)
+		_ = w.WriteMsg(m)
+		return
+	}
+	q := r.Question[0]
+	name := strings.ToLower(q.Name)
+	if !dns.IsSubDomain(o.zone, name) {
+		// this is not a recursive resolver
+		m.Authoritative = false
+		m.SetRcode(r, dns.RcodeRefused)
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	o.lock.RLock()
+	ips, found := o.records[name]
+	targets, srvFound := o.srvRecords[name]
+	if srvFound && (q.Qtype == dns.TypeSRV || q.Qtype == dns.TypeANY) {
+		for _, target := range targets {
+			m.Answer = append(m.Answer, &dns.SRV{
+				Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: overlayDNSTTL},
+				Target: target.target,
+				Port:   target.port,
+			})
+			// save the client the lookup of the address of the device
+			m.Extra = append(m.Extra, addressRecords(target.target, dns.TypeANY, o.records[target.target])...)
+		}
+	}
+	target, cnameFound := o.cnames[name]
+	if cnameFound {
+		m.Answer = append(m.Answer, &dns.CNAME{
+			Hdr:    dns.RR_Header{Name: q.Name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: overlayDNSTTL},
+			Target: target,
+		})
+		// a target in the zone is resolved here, the client resolves the other targets
+		m.Answer = append(m.Answer, addressRecords(target, q.Qtype, o.records[target])...)
+	}
+	o.lock.RUnlock()
+	if !found && !srvFound && !cnameFound {
+		m.SetRcode(r, dns.RcodeNameError)
+		_ = w.WriteMsg(m)
+		return
+	}
+
+	m.Answer = append(m.Answer, addressRecords(q.Name, q.Qtype, ips)...)
+	if err := w.WriteMsg(m); err != nil {
+		o.logger.Debugf("failed to write dns response for %s: %v", q.Name, err)
+	}
+}
+
+// addressRecords returns the A and AAAA records of the addresses of a name that answer the query type
+func addressRecords(name string, qtype uint16, ips []net.IP) []dns.RR {
+	var rrs []dns.RR
+	for _, ip := range ips {
+		hdr := dns.RR_Header{Name: name, Class: dns.ClassINET, Ttl: overlayDNSTTL}
+		if ip4 := ip.To4(); ip4 != nil {
+			if qtype == dns.TypeA || qtype == dns.TypeANY {
+				hdr.Rrtype = dns.TypeA
+				rrs = append(rrs, &dns.A{Hdr: hdr, A: ip4})
+			}
+		} else if qtype == dns.TypeAAAA || qtype == dns.TypeANY {
+			hdr.Rrtype = dns.TypeAAAA
+			rrs = append(rrs, &dns.AAAA{Hdr: hdr, AAAA: ip})
+		}
+	}
+	return rrs
+}
+
+// start serves the zone on the udp and tcp address until the context is done
+func (o *overlayDNS) start(ctx context.Context, wg *sync.WaitGroup, address string) error {
+	pc, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen for dns requests on %s: %w", address, err)
+	}
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		_ = pc.Close()
+		return fmt.Errorf("failed to listen for dns requests on %s: %w", address, err)
+	}
+
+	servers := []*dns.Server{
+		{PacketConn: pc, Handler: o},
+		{Listener: l, Handler: o},
+	}
+	for _, server := range servers {
+		server := server
+		util.GoWithWaitGroup(wg, func() {
+			if err := server.ActivateAndServe(); err != nil {
+				o.logger.Warnf("overlay dns server stopped: %v", err)
+			}
+		})
+	}
+	util.GoWithWaitGroup(wg, func() {
+		<-ctx.Done()
+		for _, server := range servers {
+			_ = server.Shutdown()
+		}
+	})
+	o.logger.Infof("Serving the overlay DNS zone %s on %s", o.zone, address)
+	return nil
+}
+
+// startOverlayDNS starts the overlay DNS resolver for the organization of the vpc
+func (nx *Nexodus) startOverlayDNS(ctx context.Context, wg *sync.WaitGroup) error {
+	org, _, err := nx.client.OrganizationsApi.GetOrganizations(ctx, nx.vpc.OrganizationId).Execute()
+	if err != nil {
+		return fmt.Errorf("get organization error: %w", err)
+	}
+	resolver := newOverlayDNS(nx.logger, org.Name)
+	if err := resolver.start(ctx, wg, nx.dnsListenAddress); err != nil {
+		return err
+	}
+	nx.overlayDNS = resolver
+	return nil
+}
+
+// servicesChanged returns the channel signaled when the services of the organization change, the services
+// are only watched when the overlay DNS resolver is enabled
+func (nx *Nexodus) servicesChanged() <-chan struct{} {
+	if nx.servicesInformer == nil {
+		return nil
+	}
+	return nx.servicesInformer.Changed()
+}
+
+// reconcileServices publishes the services of the organization in the overlay DNS
+func (nx *Nexodus) reconcileServices() {
+	if nx.overlayDNS == nil || nx.servicesInformer == nil {
+		return
+	}
+	services, _, err := nx.servicesInformer.Execute()
+	if err != nil {
+		nx.logger.Debugf("failed to list the services: %v", err)
+		return
+	}
+	nx.overlayDNS.updateServices(services)
+}
+
+// dnsRecordsChanged returns the channel signaled when the custom dns records of the organization change, the
+// records are only watched when the overlay DNS resolver is enabled
+func (nx *Nexodus) dnsRecordsChanged() <-chan struct{} {
+	if nx.dnsRecordsInformer == nil {
+		return nil
+	}
+	return nx.dnsRecordsInformer.Changed()
+}
+
+// reconcileDNSRecords serves the custom dns records of the organization in the overlay DNS
+func (nx *Nexodus) reconcileDNSRecords() {
+	if nx.overlayDNS == nil || nx.dnsRecordsInformer == nil {
+		return
+	}
+	records, _, err := nx.dnsRecordsInformer.Execute()
+	if err != nil {
+		nx.logger.Debugf("failed to list the dns records: %v", err)
+		return
+	}
+	nx.overlayDNS.updateRecords(records)
+}
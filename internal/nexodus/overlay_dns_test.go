@@ -0,0 +1,116 @@
+package nexodus
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestOverlayDNS(t *testing.T) {
+	require := require.New(t)
+
+	resolver := newOverlayDNS(zap.NewNop().Sugar(), "My Org")
+	require.Equal("my-org.nexodus.local.", resolver.zone)
+	resolver.update(map[string]public.ModelsDevice{
+		"key1": {
+			Id:            "device1",
+			DnsName:       "node1",
+			Hostname:      "node1.example.com",
+			Ipv4TunnelIps: []public.ModelsTunnelIP{{Address: "100.100.0.1"}},
+			Ipv6TunnelIps: []public.ModelsTunnelIP{{Address: "200::1"}},
+		},
+		"key2": {
+			Id:            "device2",
+			Hostname:      "Node2",
+			Ipv4TunnelIps: []public.ModelsTunnelIP{{Address: "100.100.0.2"}},
+		},
+	})
+	resolver.updateServices(map[string]public.ModelsService{
+		"service1": {Id: "service1", DeviceId: "device1", Name: "postgres", Port: 5432, Protocol: "tcp"},
+		// the device is in another vpc
+		"service2": {Id: "service2", DeviceId: "device3", Name: "redis", Port: 6379, Protocol: "tcp"},
+	})
+
+	resolver.updateRecords(map[string]public.ModelsDNSRecord{
+		"record1": {Id: "record1", Name: "www", Type: "A", Value: "100.100.0.10"},
+		"record2": {Id: "record2", Name: "db.prod", Type: "CNAME", Value: "node1"},
+		"record3": {Id: "record3", Name: "docs", Type: "CNAME", Value: "docs.example.com"},
+	})
+
+	require.Equal([]net.IP{net.ParseIP("100.100.0.1"), net.ParseIP("200::1")}, resolver.lookup("node1"))
+	require.Equal([]net.IP{net.ParseIP("100.100.0.10")}, resolver.lookup("www"))
+	require.Equal([]net.IP{net.ParseIP("100.100.0.1"), net.ParseIP("200::1")}, resolver.lookup("db.prod"))
+	require.Equal([]net.IP{net.ParseIP("100.100.0.1"), net.ParseIP("200::1")}, resolver.lookup("postgres"))
+	require.Empty(resolver.lookup("redis"))
+	require.Equal([]net.IP{net.ParseIP("100.100.0.2")}, resolver.lookup("node2.my-org.nexodus.local"))
+	require.Empty(resolver.lookup("node3"))
+	require.Equal(map[string][]string{
+		"node1.my-org.nexodus.local":          {"100.100.0.1", "200::1"},
+		"node2.my-org.nexodus.local":          {"100.100.0.2"},
+		"postgres.my-org.nexodus.local":       {"100.100.0.1", "200::1"},
+		"_postgres._tcp.my-org.nexodus.local": {"node1.my-org.nexodus.local:5432"},
+		"www.my-org.nexodus.local":            {"100.100.0.10"},
+		"db.prod.my-org.nexodus.local":        {"node1.my-org.nexodus.local"},
+		"docs.my-org.nexodus.local":           {"docs.example.com"},
+	}, resolver.names())
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(err)
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: pc, Handler: resolver, NotifyStartedFunc: func() { close(started) }}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	defer func() {
+		_ = server.Shutdown()
+	}()
+	<-started
+
+	tests := []struct {
+		name          string
+		query         string
+		qtype         uint16
+		expectedRcode int
+		expected      []string
+		expectedExtra []string
+	}{
+		{"A record", "node1.my-org.nexodus.local.", dns.TypeA, dns.RcodeSuccess, []string{"node1.my-org.nexodus.local.\t60\tIN\tA\t100.100.0.1"}, nil},
+		{"AAAA record", "NODE1.my-org.nexodus.local.", dns.TypeAAAA, dns.RcodeSuccess, []string{"NODE1.my-org.nexodus.local.\t60\tIN\tAAAA\t200::1"}, nil},
+		{"no AAAA record", "node2.my-org.nexodus.local.", dns.TypeAAAA, dns.RcodeSuccess, nil, nil},
+		{"unknown device", "node3.my-org.nexodus.local.", dns.TypeA, dns.RcodeNameError, nil, nil},
+		{"outside of the zone", "example.com.", dns.TypeA, dns.RcodeRefused, nil, nil},
+		{"service A record", "postgres.my-org.nexodus.local.", dns.TypeA, dns.RcodeSuccess, []string{"postgres.my-org.nexodus.local.\t60\tIN\tA\t100.100.0.1"}, nil},
+		{"SRV record", "_postgres._tcp.my-org.nexodus.local.", dns.TypeSRV, dns.RcodeSuccess,
+			[]string{"_postgres._tcp.my-org.nexodus.local.\t60\tIN\tSRV\t0 0 5432 node1.my-org.nexodus.local."},
+			[]string{"node1.my-org.nexodus.local.\t60\tIN\tA\t100.100.0.1", "node1.my-org.nexodus.local.\t60\tIN\tAAAA\t200::1"}},
+		{"custom A record", "www.my-org.nexodus.local.", dns.TypeA, dns.RcodeSuccess, []string{"www.my-org.nexodus.local.\t60\tIN\tA\t100.100.0.10"}, nil},
+		{"CNAME record in the zone", "db.prod.my-org.nexodus.local.", dns.TypeA, dns.RcodeSuccess,
+			[]string{"db.prod.my-org.nexodus.local.\t60\tIN\tCNAME\tnode1.my-org.nexodus.local.", "node1.my-org.nexodus.local.\t60\tIN\tA\t100.100.0.1"}, nil},
+		{"CNAME record outside of the zone", "docs.my-org.nexodus.local.", dns.TypeAAAA, dns.RcodeSuccess,
+			[]string{"docs.my-org.nexodus.local.\t60\tIN\tCNAME\tdocs.example.com."}, nil},
+		{"SRV record of another protocol", "_postgres._udp.my-org.nexodus.local.", dns.TypeSRV, dns.RcodeNameError, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &dns.Msg{}
+			m.SetQuestion(tt.query, tt.qtype)
+			resp, err := dns.Exchange(m, pc.LocalAddr().String())
+			require.NoError(err)
+			require.Equal(tt.expectedRcode, resp.Rcode)
+			var answers []string
+			for _, rr := range resp.Answer {
+				answers = append(answers, rr.String())
+			}
+			require.Equal(tt.expected, answers)
+			var extra []string
+			for _, rr := range resp.Extra {
+				extra = append(extra, rr.String())
+			}
+			require.Equal(tt.expectedExtra, extra)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package nexodus
+
+import (
+	"context"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	// how often the latency and loss of the paths to the peers are reported to the api-server
+	peerLatencyReportInterval = time.Minute
+	// the metadata key under which devices report the latency and loss of the paths to their peers
+	peerLatencyMetadataKey = "peer-latency"
+)
+
+// runPeerLatencyReporting periodically reports the latency and loss of the paths to the peers, so that
+// nexctl device list --show-latency can show the degraded paths across the organization.
+func (nx *Nexodus) runPeerLatencyReporting(ctx context.Context) {
+	timer := time.NewTimer(util.Jitter(peerLatencyReportInterval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := nx.updatePeerLatencyMetadata(); err != nil {
+				nx.logger.Debugf("failed to report the latency of the peers: %v", err)
+			}
+			timer.Reset(util.Jitter(peerLatencyReportInterval))
+		}
+	}
+}
+
+// updatePeerLatencyMetadata reports the aggregated probe results of the peers that were probed, keyed by peer device id
+func (nx *Nexodus) updatePeerLatencyMetadata() error {
+	if nx.deviceId == "" {
+		return nil
+	}
+	peers := map[string]interface{}{}
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || len(d.probeHistory) == 0 {
+			return
+		}
+		stats := aggregateProbes(d.probeHistory)
+		peers[d.device.Id] = map[string]interface{}{
+			"hostname":       d.device.Hostname,
+			"reachability":   d.reachability,
+			"latency_avg_ms": float64(stats.latencyAvg.Microseconds()) / 1000,
+			"latency_max_ms": float64(stats.latencyMax.Microseconds()) / 1000,
+			"loss":           stats.loss,
+		}
+	})
+	_, _, err := nx.client.DevicesApi.UpdateDeviceMetadataKey(context.Background(), nx.deviceId, peerLatencyMetadataKey).Value(peers).Execute()
+	return err
+}
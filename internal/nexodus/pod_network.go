@@ -0,0 +1,180 @@
+package nexodus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+// podIPAM allocates the addresses of the pods of this node from its pod CIDR, which this node advertises to
+// the peers like an --advertise-cidr prefix, so that the pods of every node in the organization reach each other
+// through the WireGuard mesh. The allocations are kept in the state store so that they survive a restart of nexd.
+type podIPAM struct {
+	lock sync.Mutex
+	// serializes the allocations of the CNI requests with their writes to the state store
+	storeLock sync.Mutex
+	prefix    netip.Prefix
+	// the addresses allocated to the pods, by container id
+	allocated map[string]netip.Addr
+}
+
+// PodAddress is the address allocated to a pod or container, returned to the nexodus CNI and Docker network plugins
+type PodAddress struct {
+	Address string `json:"address"` // the address of the pod with the prefix length of its host route
+	PodCIDR string `json:"pod-cidr"`
+}
+
+// newPodIPAM returns the allocator of the pod CIDR, restoring the stored allocations that are within it
+func newPodIPAM(podCIDR string, stored map[string]string) (*podIPAM, error) {
+	prefix, err := netip.ParsePrefix(podCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod CIDR %s: %w", podCIDR, err)
+	}
+	if !prefix.Addr().Is4() || prefix.Bits() > 30 {
+		return nil, fmt.Errorf("invalid pod CIDR %s, it must be an IPv4 prefix of at most /30", podCIDR)
+	}
+	ipam := &podIPAM{
+		prefix:    prefix.Masked(),
+		allocated: map[string]netip.Addr{},
+	}
+	for containerID, address := range stored {
+		addr, err := netip.ParseAddr(address)
+		if err != nil || !ipam.prefix.Contains(addr) {
+			continue
+		}
+		ipam.allocated[containerID] = addr
+	}
+	return ipam, nil
+}
+
+// allocate returns the address of the pod, allocating the first free address of the pod CIDR on the first call.
+// The network and broadcast addresses of the pod CIDR are not allocated.
+func (ipam *podIPAM) allocate(containerID string) (netip.Addr, error) {
+	ipam.lock.Lock()
+	defer ipam.lock.Unlock()
+	if addr, ok := ipam.allocated[containerID]; ok {
+		return addr, nil
+	}
+	used := map[netip.Addr]bool{}
+	for _, addr := range ipam.allocated {
+		used[addr] = true
+	}
+	for addr := ipam.prefix.Addr().Next(); ipam.prefix.Contains(addr.Next()); addr = addr.Next() {
+		if !used[addr] {
+			ipam.allocated[containerID] = addr
+			return addr, nil
+		}
+	}
+	return netip.Addr{}, fmt.Errorf("no address is left in the pod CIDR %s", ipam.prefix)
+}
+
+// release frees the address of the pod, releasing the address of an unknown pod is not an error
+func (ipam *podIPAM) release(containerID string) {
+	ipam.lock.Lock()
+	defer ipam.lock.Unlock()
+	delete(ipam.allocated, containerID)
+}
+
+// addresses returns the allocated addresses by container id, for the state store
+func (ipam *podIPAM) addresses() map[string]string {
+	ipam.lock.Lock()
+	defer ipam.lock.Unlock()
+	addresses := make(map[string]string, len(ipam.allocated))
+	for containerID, addr := range ipam.allocated {
+		addresses[containerID] = addr.String()
+	}
+	return addresses
+}
+
+// validatePodCIDR checks that the pod CIDR does not overlap the CIDR of the VPC or a prefix advertised by another
+// device of the organization, the pods of this node would not be reachable through the mesh
+func (nx *Nexodus) validatePodCIDR(ctx context.Context) error {
+	if nx.podIPAM == nil {
+		return nil
+	}
+	devices, _, err := nx.client.VPCApi.ListDevicesInVPC(ctx, nx.vpc.Id).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to list the devices to validate the pod CIDR: %w", err)
+	}
+	return podCIDRConflict(nx.podIPAM.prefix, nx.vpc.Ipv4Cidr, devices, nx.wireguardPubKey)
+}
+
+// podCIDRConflict returns an error if the pod CIDR overlaps the CIDR of the VPC or a prefix advertised by one of the
+// devices other than this device, identified by its public key. The default routes of the exit nodes are ignored.
+func podCIDRConflict(podCIDR netip.Prefix, vpcCIDR string, devices []public.ModelsDevice, self string) error {
+	if prefix, err := netip.ParsePrefix(vpcCIDR); err == nil && prefix.Overlaps(podCIDR) {
+		return fmt.Errorf("the pod CIDR %s overlaps the CIDR %s of the vpc", podCIDR, vpcCIDR)
+	}
+	for _, d := range devices {
+		if d.PublicKey == self {
+			continue
+		}
+		for _, cidr := range d.AdvertiseCidrs {
+			prefix, err := netip.ParsePrefix(cidr)
+			if err != nil || prefix.Bits() == 0 {
+				continue
+			}
+			if prefix.Overlaps(podCIDR) {
+				return fmt.Errorf("the pod CIDR %s overlaps the prefix %s advertised by the device %s", podCIDR, cidr, d.Hostname)
+			}
+		}
+	}
+	return nil
+}
+
+// storePodAddresses stores the allocated pod addresses in the state store
+func (nx *Nexodus) storePodAddresses() error {
+	nx.stateStore.State().PodAddresses = nx.podIPAM.addresses()
+	return nx.stateStore.Store()
+}
+
+// CniAdd allocates the address of a pod for the nexodus CNI plugin, or of a container for the nexodus Docker network
+// plugin, the argument is the container or endpoint id
+func (ac *NexdCtl) CniAdd(containerID string, result *string) error {
+	if ac.nx.podIPAM == nil {
+		return fmt.Errorf("nexd has no pod CIDR, start it with nexd router --pod-cidr")
+	}
+	if containerID == "" {
+		return fmt.Errorf("a container id is required")
+	}
+	ac.nx.podIPAM.storeLock.Lock()
+	defer ac.nx.podIPAM.storeLock.Unlock()
+	addr, err := ac.nx.podIPAM.allocate(containerID)
+	if err != nil {
+		return err
+	}
+	if err := ac.nx.storePodAddresses(); err != nil {
+		ac.nx.podIPAM.release(containerID)
+		return fmt.Errorf("failed to store the address of the pod: %w", err)
+	}
+	ac.nx.logger.Debugf("Allocated the address %s to the pod of container %s", addr, containerID)
+
+	resultJSON, err := json.Marshal(PodAddress{
+		Address: netip.PrefixFrom(addr, addr.BitLen()).String(),
+		PodCIDR: ac.nx.podIPAM.prefix.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling the pod address: %w", err)
+	}
+	*result = string(resultJSON)
+	return nil
+}
+
+// CniDel releases the address of a pod or container allocated by CniAdd, the argument is the container or endpoint id
+func (ac *NexdCtl) CniDel(containerID string, result *string) error {
+	if ac.nx.podIPAM == nil {
+		return fmt.Errorf("nexd has no pod CIDR, start it with nexd router --pod-cidr")
+	}
+	ac.nx.podIPAM.storeLock.Lock()
+	defer ac.nx.podIPAM.storeLock.Unlock()
+	ac.nx.podIPAM.release(containerID)
+	if err := ac.nx.storePodAddresses(); err != nil {
+		return fmt.Errorf("failed to store the pod addresses: %w", err)
+	}
+	*result = fmt.Sprintf("Released the address of the pod of container %s\n", containerID)
+	return nil
+}
@@ -0,0 +1,57 @@
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestPodIPAM(t *testing.T) {
+	require := require.New(t)
+
+	_, err := newPodIPAM("fd00::/64", nil)
+	require.Error(err)
+	_, err = newPodIPAM("10.244.1.0/31", nil)
+	require.Error(err)
+
+	// the stored allocations outside of the pod CIDR are dropped
+	ipam, err := newPodIPAM("10.244.1.0/30", map[string]string{"a": "10.244.1.1", "b": "10.244.2.1"})
+	require.NoError(err)
+	require.Equal(map[string]string{"a": "10.244.1.1"}, ipam.addresses())
+
+	addr, err := ipam.allocate("a")
+	require.NoError(err)
+	require.Equal(netip.MustParseAddr("10.244.1.1"), addr)
+	addr, err = ipam.allocate("c")
+	require.NoError(err)
+	require.Equal(netip.MustParseAddr("10.244.1.2"), addr)
+
+	// the broadcast address is not allocated
+	_, err = ipam.allocate("d")
+	require.Error(err)
+
+	ipam.release("a")
+	addr, err = ipam.allocate("d")
+	require.NoError(err)
+	require.Equal(netip.MustParseAddr("10.244.1.1"), addr)
+}
+
+func TestPodCIDRConflict(t *testing.T) {
+	require := require.New(t)
+	podCIDR := netip.MustParsePrefix("10.244.1.0/24")
+	devices := []public.ModelsDevice{
+		{PublicKey: "self", Hostname: "self", AdvertiseCidrs: []string{"10.244.1.0/24"}},
+		{PublicKey: "exit", Hostname: "exit", AdvertiseCidrs: []string{"0.0.0.0/0"}},
+		{PublicKey: "other", Hostname: "other", AdvertiseCidrs: []string{"10.244.2.0/24"}},
+	}
+	require.NoError(podCIDRConflict(podCIDR, "100.64.0.0/10", devices, "self"))
+
+	// the pod CIDR of another node
+	devices[2].AdvertiseCidrs = append(devices[2].AdvertiseCidrs, "10.244.0.0/16")
+	require.ErrorContains(podCIDRConflict(podCIDR, "100.64.0.0/10", devices, "self"), "advertised by the device other")
+
+	require.ErrorContains(podCIDRConflict(podCIDR, "10.0.0.0/8", nil, "self"), "of the vpc")
+}
@@ -77,13 +77,20 @@ func (nx *Nexodus) processSecurityGroupRules() error {
 		return fmt.Errorf("failed to append io.nexodus anchor: %w", err)
 	}
 
+	inboundRules := nx.securityGroup.InboundRules
+	outboundRules := nx.securityGroup.OutboundRules
+	if !nx.ipv6Supported {
+		inboundRules = ipv4SecurityRules(inboundRules)
+		outboundRules = ipv4SecurityRules(outboundRules)
+	}
+
 	// Explicit drop if rules are defined
-	if len(nx.securityGroup.InboundRules) > 0 {
+	if len(inboundRules) > 0 {
 		prb.pfBlockAll("in")
 	}
 
 	// Process inbound rules
-	for _, rule := range nx.securityGroup.InboundRules {
+	for _, rule := range inboundRules {
 		if len(rule.IpRanges) == 0 || containsEmptyRange(rule.IpRanges) {
 			if err := prb.pfPermitProtoPortAnyAddr(rule, "inbound"); err != nil {
 				nx.logger.Errorf("pfctl setup error, failed to process inbound rule with 'any': %v", err)
@@ -103,12 +110,12 @@ func (nx *Nexodus) processSecurityGroupRules() error {
 	}
 
 	// Explicit drop if rules are defined
-	if len(nx.securityGroup.OutboundRules) > 0 {
+	if len(outboundRules) > 0 {
 		prb.pfBlockAll("out")
 	}
 
 	// Process outbound rules
-	for _, rule := range nx.securityGroup.OutboundRules {
+	for _, rule := range outboundRules {
 		if len(rule.IpRanges) == 0 || containsEmptyRange(rule.IpRanges) {
 			if err := prb.pfPermitProtoPortAnyAddr(rule, "outbound"); err != nil {
 				nx.logger.Errorf("pfctl setup error, failed to process outbound rule with 'any': %v", err)
@@ -407,3 +414,17 @@ func (nx *Nexodus) networkRouterSetup() error {
 func (nx *Nexodus) policyTableDrop(table string) error {
 	return nil
 }
+
+// securityRulesPresent checks that the rules of the security group are loaded in the io.nexodus pf anchor
+func (nx *Nexodus) securityRulesPresent() (bool, error) {
+	output, err := policyCmd(nx.logger, []string{"-a", "io.nexodus", "-sr"})
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "pass") || strings.HasPrefix(line, "block") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
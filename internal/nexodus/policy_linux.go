@@ -62,6 +62,10 @@ func (nx *Nexodus) processSecurityGroupRules() error {
 
 	inboundRules := nx.securityGroup.InboundRules
 	outboundRules := nx.securityGroup.OutboundRules
+	if !nx.ipv6Supported {
+		inboundRules = ipv4SecurityRules(inboundRules)
+		outboundRules = ipv4SecurityRules(outboundRules)
+	}
 
 	// Enable rule debugging to print rules via debug logging as they are processed
 	if nx.logger.Level().Enabled(zapcore.DebugLevel) {
@@ -676,3 +680,8 @@ func (nx *Nexodus) rtrCreateChain(chainName, chainType, chainPriority string) er
 
 	return nil
 }
+
+// securityRulesPresent checks that the nftables table of the security group rules exists
+func (nx *Nexodus) securityRulesPresent() (bool, error) {
+	return nx.nfTableExists(sgTableName)
+}
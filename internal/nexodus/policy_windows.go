@@ -3,11 +3,219 @@
 package nexodus
 
 import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	wfpSessionName = "Nexodus Security Group"
 )
 
-// processSecurityGroupRules for windows build purposes, policy currently unsupported on windows
+var (
+	// wfpSecurityGroupSession holds the filters of the security group currently applied to the tunnel interface
+	wfpSecurityGroupSession *wfpSession
+)
+
+// wfpPermit is a filter permitting the connections matched by a security rule
+type wfpPermit struct {
+	inbound  bool
+	ipv6     bool
+	protocol uint8 // 0 matches any protocol
+	fromPort uint16
+	toPort   uint16 // a port range of 0-0 matches any port
+	prefixes []netip.Prefix
+	ranges   [][2]netip.Addr
+}
+
+func (p wfpPermit) String() string {
+	direction, family := "outbound", "ipv4"
+	if p.inbound {
+		direction = "inbound"
+	}
+	if p.ipv6 {
+		family = "ipv6"
+	}
+	var addrs []string
+	for _, prefix := range p.prefixes {
+		addrs = append(addrs, prefix.String())
+	}
+	for _, r := range p.ranges {
+		addrs = append(addrs, r[0].String()+"-"+r[1].String())
+	}
+	if len(addrs) == 0 {
+		addrs = append(addrs, "any")
+	}
+	return fmt.Sprintf("permit %s %s protocol %d ports %d-%d addresses %s", direction, family, p.protocol, p.fromPort, p.toPort, strings.Join(addrs, ","))
+}
+
+// wfpPermits translates a security rule into the permit filters of the ipv4 and ipv6 layers
+func wfpPermits(rule public.ModelsSecurityRule, inbound bool) ([]wfpPermit, error) {
+	r, err := parseSecurityRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var permits []wfpPermit
+	add := func(ipv6 bool, protocols []uint8, prefixes []netip.Prefix, ranges [][2]netip.Addr) {
+		if !r.anyAddress && len(prefixes) == 0 && len(ranges) == 0 {
+			// the rule has no addresses of this family
+			return
+		}
+		for _, protocol := range protocols {
+			permit := wfpPermit{
+				inbound:  inbound,
+				ipv6:     ipv6,
+				protocol: protocol,
+				prefixes: prefixes,
+				ranges:   ranges,
+			}
+			if r.hasPorts(protocol) {
+				permit.fromPort, permit.toPort = r.fromPort, r.toPort
+			}
+			permits = append(permits, permit)
+		}
+	}
+	add(false, r.v4, r.v4Prefixes, r.v4Ranges)
+	add(true, r.v6, r.v6Prefixes, r.v6Ranges)
+	return permits, nil
+}
+
+// processSecurityGroupRules processes a security group for a Windows node by programming
+// Windows Filtering Platform filters for the tunnel interface
 func (nx *Nexodus) processSecurityGroupRules() error {
+	// Remove the filters if there is no security group or it has no rules, which permits all traffic
+	if nx.securityGroup == nil || (len(nx.securityGroup.InboundRules) == 0 && len(nx.securityGroup.OutboundRules) == 0) {
+		return closeSecurityGroupSession()
+	}
+
+	inboundRules := nx.securityGroup.InboundRules
+	outboundRules := nx.securityGroup.OutboundRules
+	if !nx.ipv6Supported {
+		inboundRules = ipv4SecurityRules(inboundRules)
+		outboundRules = ipv4SecurityRules(outboundRules)
+	}
+
+	var permits []wfpPermit
+	for _, rule := range inboundRules {
+		p, err := wfpPermits(rule, true)
+		if err != nil {
+			return fmt.Errorf("wfp setup error, failed to process inbound rule: %w", err)
+		}
+		permits = append(permits, p...)
+	}
+	for _, rule := range outboundRules {
+		p, err := wfpPermits(rule, false)
+		if err != nil {
+			return fmt.Errorf("wfp setup error, failed to process outbound rule: %w", err)
+		}
+		permits = append(permits, p...)
+	}
+
+	// Enable rule debugging to print rules via debug logging as they are processed
+	if nx.logger.Level().Enabled(zapcore.DebugLevel) {
+		for _, permit := range permits {
+			nx.logger.Debugf("wfp filter: %s", permit)
+		}
+	}
+
+	luid, err := interfaceLUID(nx.tunnelIface)
+	if err != nil {
+		return err
+	}
+
+	session, err := newWFPSession(wfpSessionName)
+	if err != nil {
+		return fmt.Errorf("wfp setup error, failed to open the filter engine: %w", err)
+	}
+	err = session.transaction(func() error {
+		// append a drop that appears implicit to the user only if there are any rules in the direction
+		if len(nx.securityGroup.InboundRules) != 0 {
+			for _, layer := range []windows.GUID{fwpmLayerAleAuthRecvAcceptV4, fwpmLayerAleAuthRecvAcceptV6} {
+				if err := session.addFilter(wfpSessionName+" inbound drop", layer, fwpActionBlock, wfpWeightBlock,
+					[]fwpmFilterCondition0{session.interfaceCondition(uint64(luid))}); err != nil {
+					return fmt.Errorf("failed to add ingress drop filter: %w", err)
+				}
+			}
+		}
+		if len(nx.securityGroup.OutboundRules) != 0 {
+			for _, layer := range []windows.GUID{fwpmLayerAleAuthConnectV4, fwpmLayerAleAuthConnectV6} {
+				if err := session.addFilter(wfpSessionName+" outbound drop", layer, fwpActionBlock, wfpWeightBlock,
+					[]fwpmFilterCondition0{session.interfaceCondition(uint64(luid))}); err != nil {
+					return fmt.Errorf("failed to add egress drop filter: %w", err)
+				}
+			}
+		}
+		for _, permit := range permits {
+			if err := session.addPermit(uint64(luid), permit); err != nil {
+				return fmt.Errorf("failed to add filter %q: %w", permit, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = session.close()
+		return fmt.Errorf("wfp setup error: %w", err)
+	}
+
+	// the filters of the previous security group are removed once the new filters are in place
+	previous := wfpSecurityGroupSession
+	wfpSecurityGroupSession = session
+	if previous != nil {
+		if err := previous.close(); err != nil {
+			nx.logger.Debugf("failed to remove the previous wfp filters: %v", err)
+		}
+	}
+	return nil
+}
+
+// addPermit adds the filter of a permit, conditions of the same field are matched if any of them match
+func (s *wfpSession) addPermit(luid uint64, permit wfpPermit) error {
+	layer := fwpmLayerAleAuthConnectV4
+	portField := fwpmConditionIpRemotePort
+	if permit.inbound {
+		layer = fwpmLayerAleAuthRecvAcceptV4
+		portField = fwpmConditionIpLocalPort
+	}
+	if permit.ipv6 {
+		if permit.inbound {
+			layer = fwpmLayerAleAuthRecvAcceptV6
+		} else {
+			layer = fwpmLayerAleAuthConnectV6
+		}
+	}
+
+	conditions := []fwpmFilterCondition0{s.interfaceCondition(luid)}
+	if permit.protocol != 0 {
+		conditions = append(conditions, s.protocolCondition(permit.protocol))
+	}
+	if permit.fromPort != 0 {
+		conditions = append(conditions, s.portCondition(portField, permit.fromPort, permit.toPort))
+	}
+	for _, prefix := range permit.prefixes {
+		conditions = append(conditions, s.prefixCondition(prefix))
+	}
+	for _, r := range permit.ranges {
+		conditions = append(conditions, s.rangeCondition(r[0], r[1]))
+	}
+	return s.addFilter(wfpSessionName+" "+permit.String(), layer, fwpActionPermit, wfpWeightPermit, conditions)
+}
+
+// closeSecurityGroupSession removes the filters of the security group applied to the tunnel interface
+func closeSecurityGroupSession() error {
+	if wfpSecurityGroupSession == nil {
+		return nil
+	}
+	session := wfpSecurityGroupSession
+	wfpSecurityGroupSession = nil
+	if err := session.close(); err != nil {
+		return fmt.Errorf("failed to remove the wfp filters: %w", err)
+	}
 	return nil
 }
 
@@ -25,3 +233,8 @@ func policyCmd(logger *zap.SugaredLogger, cmd []string) (string, error) {
 func (nx *Nexodus) policyTableDrop(table string) error {
 	return nil
 }
+
+// securityRulesPresent checks that the filters of the security group are applied to the tunnel interface
+func (nx *Nexodus) securityRulesPresent() (bool, error) {
+	return wfpSecurityGroupSession != nil, nil
+}
@@ -0,0 +1,64 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWFPPermits(t *testing.T) {
+	permits, err := wfpPermits(public.ModelsSecurityRule{IpProtocol: "tcp", FromPort: 22, ToPort: 22}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []wfpPermit{
+		{inbound: true, protocol: ipProtocolTCP, fromPort: 22, toPort: 22},
+		{inbound: true, ipv6: true, protocol: ipProtocolTCP, fromPort: 22, toPort: 22},
+	}, permits)
+
+	// only the families of the addresses of a rule are permitted
+	permits, err = wfpPermits(public.ModelsSecurityRule{
+		IpProtocol: "udp",
+		FromPort:   80,
+		ToPort:     90,
+		IpRanges:   []string{"10.130.0.1-10.130.0.5", "192.168.2.1/24", "100.100.0.1"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []wfpPermit{{
+		protocol: ipProtocolUDP,
+		fromPort: 80,
+		toPort:   90,
+		prefixes: []netip.Prefix{netip.MustParsePrefix("192.168.2.0/24"), netip.MustParsePrefix("100.100.0.1/32")},
+		ranges:   [][2]netip.Addr{{netip.MustParseAddr("10.130.0.1"), netip.MustParseAddr("10.130.0.5")}},
+	}}, permits)
+
+	// ports of the ip protocols are matched for tcp and udp
+	permits, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "ipv6", FromPort: 30000, ToPort: 31000, IpRanges: []string{"2002:0db8::/64"}}, true)
+	require.NoError(t, err)
+	prefixes := []netip.Prefix{netip.MustParsePrefix("2002:db8::/64")}
+	assert.Equal(t, []wfpPermit{
+		{inbound: true, ipv6: true, protocol: ipProtocolTCP, fromPort: 30000, toPort: 31000, prefixes: prefixes},
+		{inbound: true, ipv6: true, protocol: ipProtocolUDP, fromPort: 30000, toPort: 31000, prefixes: prefixes},
+	}, permits)
+
+	permits, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "icmp"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []wfpPermit{
+		{inbound: true, protocol: ipProtocolICMPv4},
+		{inbound: true, ipv6: true, protocol: ipProtocolICMPv6},
+	}, permits)
+
+	permits, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "icmpv6", IpRanges: []string{"10.0.0.0/8"}}, true)
+	require.NoError(t, err)
+	assert.Empty(t, permits)
+
+	_, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "sctp"}, true)
+	assert.Error(t, err)
+	_, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "tcp", FromPort: 90, ToPort: 80}, true)
+	assert.Error(t, err)
+	_, err = wfpPermits(public.ModelsSecurityRule{IpProtocol: "tcp", IpRanges: []string{"10.0.0.5-fd00::1"}}, true)
+	assert.Error(t, err)
+}
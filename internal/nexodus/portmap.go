@@ -0,0 +1,69 @@
+package nexodus
+
+import (
+	"net/netip"
+	"time"
+
+	"tailscale.com/net/portmapper"
+)
+
+const (
+	// the source of the endpoint mapped on the local gateway
+	endpointSourcePortMap = "portmap"
+	// the priority of the endpoint mapped on the local gateway, which is reachable regardless of the NAT type
+	candidatePriorityPortMap = 105
+	// how long to wait for the local gateway to map the listen port when nexd starts
+	portMapTimeout = 5 * time.Second
+)
+
+// startPortMapping maps the wireguard listen port on the local gateway using UPnP IGD, NAT-PMP or PCP, waiting for
+// the mapping for up to portMapTimeout. The mapping is renewed by reconcileStun.
+func (nx *Nexodus) startPortMapping() {
+	mapped := make(chan struct{}, 1)
+	nx.portMapper = portmapper.NewClient(func(format string, args ...any) {
+		nx.logger.Debugf("portmapper: "+format, args...)
+	}, nil, nil, nil, func() {
+		select {
+		case mapped <- struct{}{}:
+		default:
+		}
+	})
+	nx.portMapper.SetLocalPort(uint16(nx.listenPort))
+
+	if _, ok := nx.portMapper.GetCachedMappingOrStartCreatingOne(); !ok {
+		select {
+		case <-mapped:
+		case <-time.After(portMapTimeout):
+		}
+	}
+	external, ok := nx.portMappedEndpoint()
+	if !ok {
+		nx.logger.Info("The local gateway did not map the listen port with UPnP, NAT-PMP or PCP, peering with the STUN reflexive address")
+		return
+	}
+	nx.logger.Infof("The listen port %d is mapped on the local gateway to %s", nx.listenPort, external)
+	if nx.symmetricNatDetected && !nx.relayOnly {
+		// peers reach this device on the mapped endpoint regardless of the NAT type
+		nx.logger.Info("Symmetric NAT was detected, but peers can reach this device on the mapped endpoint, a relay is not required")
+		nx.symmetricNat = false
+		nx.symmetricNatDetected = false
+	}
+}
+
+// portMappedEndpoint returns the endpoint mapped on the local gateway, starting to create or renew a mapping if needed
+func (nx *Nexodus) portMappedEndpoint() (netip.AddrPort, bool) {
+	if nx.portMapper == nil {
+		return netip.AddrPort{}, false
+	}
+	return nx.portMapper.GetCachedMappingOrStartCreatingOne()
+}
+
+// stopPortMapping releases the mapping of the listen port on the local gateway
+func (nx *Nexodus) stopPortMapping() {
+	if nx.portMapper == nil {
+		return
+	}
+	if err := nx.portMapper.Close(); err != nil {
+		nx.logger.Debugf("failed to release the port mapping: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package nexodus
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// electPrefixGateways elects the gateway of each prefix advertised by several devices, since wireguard routes a
+// prefix through a single peer, assumes deviceCacheLock is held. The other devices advertising the prefix are its
+// standby gateways.
+func (nx *Nexodus) electPrefixGateways() {
+	devices := make([]public.ModelsDevice, 0, len(nx.deviceCache))
+	for _, d := range nx.deviceCache {
+		devices = append(devices, d.device)
+	}
+	gateways := prefixGateways(devices, nx.wireguardPubKey)
+	for cidr, gateway := range gateways {
+		if nx.prefixGateways[cidr] == gateway {
+			continue
+		}
+		if gateway == nx.wireguardPubKey {
+			nx.logger.Infof("Prefix %s is also advertised by other gateways, this device routes it locally", cidr)
+		} else {
+			nx.logger.Infof("Prefix %s is advertised by several gateways, routing it through %s", cidr, nx.deviceCache[gateway].device.Hostname)
+		}
+	}
+	nx.prefixGateways = gateways
+}
+
+// prefixGateways returns the public key of the gateway of each prefix advertised by several devices. The device
+// advertising the prefix itself routes it locally. Otherwise the election only depends on the state of the devices
+// kept by the service, so that all the devices elect the same gateway: the device that has been online the longest
+// is elected, and a gateway coming back online does not take the prefix back from the gateway that took over.
+func prefixGateways(devices []public.ModelsDevice, self string) map[string]string {
+	advertisers := map[string][]public.ModelsDevice{}
+	for _, d := range devices {
+		for _, cidr := range d.AdvertiseCidrs {
+			// the default routes are routed through the exit node in use instead
+			if util.IsDefaultIPRoute(cidr) {
+				continue
+			}
+			advertisers[cidr] = append(advertisers[cidr], d)
+		}
+	}
+
+	gateways := map[string]string{}
+	for cidr, candidates := range advertisers {
+		if len(candidates) < 2 {
+			continue
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return gatewayPreferred(candidates[i], candidates[j], self)
+		})
+		gateways[cidr] = candidates[0].PublicKey
+	}
+	return gateways
+}
+
+// gatewayPreferred returns true if the device a is preferred over the device b as the gateway of a prefix
+func gatewayPreferred(a, b public.ModelsDevice, self string) bool {
+	if (a.PublicKey == self) != (b.PublicKey == self) {
+		return a.PublicKey == self
+	}
+	if a.Online != b.Online {
+		return a.Online
+	}
+	aSince, aErr := time.Parse(time.RFC3339Nano, a.OnlineAt)
+	bSince, bErr := time.Parse(time.RFC3339Nano, b.OnlineAt)
+	if (aErr == nil) != (bErr == nil) {
+		return aErr == nil
+	}
+	if aErr == nil && !aSince.Equal(bSince) {
+		return aSince.Before(bSince)
+	}
+	return a.Id < b.Id
+}
+
+// gatewayAllowedIPs removes the prefixes the peer is a standby gateway of from its allowed ips, assumes
+// deviceCacheLock is held
+func (nx *Nexodus) gatewayAllowedIPs(device public.ModelsDevice, allowedIPs []string) []string {
+	if len(nx.prefixGateways) == 0 || len(allowedIPs) == 0 {
+		return allowedIPs
+	}
+	result := make([]string, 0, len(allowedIPs))
+	for _, allowedIP := range allowedIPs {
+		if gateway, ok := nx.prefixGateways[allowedIP]; ok && gateway != device.PublicKey {
+			continue
+		}
+		result = append(result, allowedIP)
+	}
+	return result
+}
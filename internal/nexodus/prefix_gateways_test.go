@@ -0,0 +1,46 @@
+package nexodus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestPrefixGateways(t *testing.T) {
+	require := require.New(t)
+	primary := public.ModelsDevice{Id: "1", PublicKey: "primary", Online: true, OnlineAt: "2024-03-01T10:00:00Z", AdvertiseCidrs: []string{"10.10.0.0/24", "0.0.0.0/0"}}
+	standby := public.ModelsDevice{Id: "2", PublicKey: "standby", Online: true, OnlineAt: "2024-03-01T11:00:00Z", AdvertiseCidrs: []string{"10.10.0.0/24", "0.0.0.0/0"}}
+	other := public.ModelsDevice{Id: "3", PublicKey: "other", Online: true, AdvertiseCidrs: []string{"10.20.0.0/24"}}
+
+	// the prefixes advertised by a single device and the default routes are not elected
+	require.Equal(map[string]string{"10.10.0.0/24": "primary"}, prefixGateways([]public.ModelsDevice{standby, primary, other}, "other"))
+
+	// the standby takes over once the primary is offline, and keeps the prefix once the primary is back online
+	primary.Online = false
+	primary.OnlineAt = "2024-03-01T12:00:00Z"
+	require.Equal(map[string]string{"10.10.0.0/24": "standby"}, prefixGateways([]public.ModelsDevice{primary, standby, other}, "other"))
+	primary.Online = true
+	require.Equal(map[string]string{"10.10.0.0/24": "standby"}, prefixGateways([]public.ModelsDevice{primary, standby, other}, "other"))
+
+	// a gateway routes the prefix locally
+	require.Equal(map[string]string{"10.10.0.0/24": "primary"}, prefixGateways([]public.ModelsDevice{primary, standby, other}, "primary"))
+
+	// the devices online at the same time are ordered by id
+	standby.OnlineAt = primary.OnlineAt
+	require.Equal(map[string]string{"10.10.0.0/24": "primary"}, prefixGateways([]public.ModelsDevice{standby, primary}, "other"))
+}
+
+func TestGatewayAllowedIPs(t *testing.T) {
+	require := require.New(t)
+	primary := public.ModelsDevice{PublicKey: "primary"}
+	standby := public.ModelsDevice{PublicKey: "standby"}
+	nx := &Nexodus{}
+
+	require.Equal([]string{"100.64.0.2/32", "10.10.0.0/24"}, nx.gatewayAllowedIPs(standby, []string{"100.64.0.2/32", "10.10.0.0/24"}))
+
+	nx.prefixGateways = map[string]string{"10.10.0.0/24": "primary"}
+	require.Equal([]string{"100.64.0.1/32", "10.10.0.0/24"}, nx.gatewayAllowedIPs(primary, []string{"100.64.0.1/32", "10.10.0.0/24"}))
+	require.Equal([]string{"100.64.0.2/32", "10.20.0.0/24"}, nx.gatewayAllowedIPs(standby, []string{"100.64.0.2/32", "10.10.0.0/24", "10.20.0.0/24"}))
+}
@@ -134,3 +134,22 @@ func ParseProxyRule(rule string, ruleType ProxyType) (emptyRule ProxyRule, err e
 		},
 	}, nil
 }
+
+// ParsePublishRule parses a container port to publish in the form [protocol:]port[:container_port]
+// and returns the ingress rule that forwards it to the container loopback address.
+func ParsePublishRule(publish string) (emptyRule ProxyRule, err error) {
+	parts := strings.Split(publish, ":")
+	protocol := proxyProtocolTCP
+	if p, err := parseProxyProtocol(parts[0]); err == nil {
+		protocol = p
+		parts = parts[1:]
+	}
+	if len(parts) < 1 || len(parts) > 2 {
+		return emptyRule, fmt.Errorf("invalid publish format, must be [protocol:]port[:container_port] (%s)", publish)
+	}
+	containerPort := parts[0]
+	if len(parts) == 2 {
+		containerPort = parts[1]
+	}
+	return ParseProxyRule(fmt.Sprintf("%s:%s:127.0.0.1:%s", protocol, parts[0], containerPort), ProxyTypeIngress)
+}
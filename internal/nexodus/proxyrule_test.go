@@ -0,0 +1,35 @@
+package nexodus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePublishRule(t *testing.T) {
+	tests := []struct {
+		publish  string
+		expected string
+		wantErr  bool
+	}{
+		{publish: "80", expected: "--ingress tcp:80:127.0.0.1:80"},
+		{publish: "80:8080", expected: "--ingress tcp:80:127.0.0.1:8080"},
+		{publish: "udp:53", expected: "--ingress udp:53:127.0.0.1:53"},
+		{publish: "TCP:443:8443", expected: "--ingress tcp:443:127.0.0.1:8443"},
+		{publish: "tcp", wantErr: true},
+		{publish: "80:8080:9090", wantErr: true},
+		{publish: "http:80", wantErr: true},
+		{publish: "70000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.publish, func(t *testing.T) {
+			rule, err := ParsePublishRule(tt.publish)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rule.AsFlag())
+		})
+	}
+}
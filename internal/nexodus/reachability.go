@@ -0,0 +1,224 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	// how often each peer is probed through the tunnel
+	reachabilityProbeInterval = 30 * time.Second
+	// how long to wait for a probe reply
+	reachabilityProbeTimeout = 2 * time.Second
+	// probe round trips slower than this mark the peer as degraded
+	degradedLatency = 500 * time.Millisecond
+	// the number of the last probes of a peer its latency and loss are aggregated over
+	reachabilityHistorySize = 10
+
+	PeerReachable   = "reachable"
+	PeerDegraded    = "degraded"
+	PeerUnreachable = "unreachable"
+)
+
+// peerReachability is the result of the last in-tunnel probe of a peer
+type peerReachability struct {
+	// one of the Peer* reachability constants, empty until the peer has been probed
+	reachability string
+	// round trip time of the last successful probe
+	probeLatency time.Duration
+	// the time of the last probe
+	lastProbeTime time.Time
+	// the results of the last probes, oldest first
+	probeHistory []probeSample
+}
+
+// probeSample is the result of one probe of a peer
+type probeSample struct {
+	latency time.Duration
+	lost    bool
+}
+
+// probeStats are the latency and loss of the last probes of a peer
+type probeStats struct {
+	latencyAvg time.Duration
+	latencyMax time.Duration
+	// the fraction of the probes that were not answered
+	loss float64
+}
+
+// aggregateProbes returns the latency of the answered probes and the fraction of the probes that were lost
+func aggregateProbes(history []probeSample) probeStats {
+	stats := probeStats{}
+	if len(history) == 0 {
+		return stats
+	}
+	var total time.Duration
+	answered, lost := 0, 0
+	for _, sample := range history {
+		if sample.lost {
+			lost++
+			continue
+		}
+		answered++
+		total += sample.latency
+		if sample.latency > stats.latencyMax {
+			stats.latencyMax = sample.latency
+		}
+	}
+	if answered > 0 {
+		stats.latencyAvg = total / time.Duration(answered)
+	}
+	stats.loss = float64(lost) / float64(len(history))
+	return stats
+}
+
+// appendProbe records a probe result in the history of a peer, keeping the last reachabilityHistorySize results
+func appendProbe(history []probeSample, sample probeSample) []probeSample {
+	history = append(history, sample)
+	if len(history) > reachabilityHistorySize {
+		history = history[len(history)-reachabilityHistorySize:]
+	}
+	return history
+}
+
+// classifyReachability combines a probe result with the wireguard handshake health of a peer.
+// A peer that does not answer probes while its wireguard session is up is degraded rather than
+// unreachable, since the probes may be filtered by a security group.
+func classifyReachability(probeErr error, latency time.Duration, handshakeHealthy bool) string {
+	switch {
+	case probeErr == nil && latency > degradedLatency:
+		return PeerDegraded
+	case probeErr == nil:
+		return PeerReachable
+	case handshakeHealthy:
+		return PeerDegraded
+	default:
+		return PeerUnreachable
+	}
+}
+
+func (nx *Nexodus) runReachabilityProbes(ctx context.Context) {
+	timer := time.NewTimer(util.Jitter(reachabilityProbeInterval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			nx.probeReachability()
+			timer.Reset(util.Jitter(reachabilityProbeInterval))
+		}
+	}
+}
+
+// probeReachability pings the tunnel address of every peer in batches and records the results in the device cache
+func (nx *Nexodus) probeReachability() {
+	peerAddrs := map[string]string{}
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || len(d.device.Ipv4TunnelIps) == 0 {
+			return
+		}
+		addr := d.device.Ipv4TunnelIps[0].Address
+		if net.ParseIP(addr) == nil {
+			return
+		}
+		peerAddrs[d.device.PublicKey] = addr
+	})
+
+	type probeResult struct {
+		publicKey string
+		latency   time.Duration
+		err       error
+	}
+	results := make(chan probeResult, len(peerAddrs))
+	batch := make(chan struct{}, batchSize)
+	wg := sync.WaitGroup{}
+	seq := uint64(time.Now().UnixNano())
+	for publicKey, addr := range peerAddrs {
+		seq++
+		wg.Add(1)
+		batch <- struct{}{}
+		go func(publicKey string, addr string, seq uint64) {
+			defer wg.Done()
+			defer func() { <-batch }()
+			start := time.Now()
+			_, err := nx.doPing(addr, seq, reachabilityProbeTimeout)
+			results <- probeResult{publicKey: publicKey, latency: time.Since(start), err: err}
+		}(publicKey, addr, seq)
+	}
+	wg.Wait()
+	close(results)
+
+	nx.deviceCacheLock.Lock()
+	defer nx.deviceCacheLock.Unlock()
+	now := time.Now()
+	for result := range results {
+		d, ok := nx.deviceCache[result.publicKey]
+		if !ok {
+			continue
+		}
+		reachability := classifyReachability(result.err, result.latency, d.peerHealthy)
+		d.probeHistory = appendProbe(d.probeHistory, probeSample{latency: result.latency, lost: result.err != nil})
+		if reachability != d.reachability {
+			switch {
+			case d.reachability == PeerReachable:
+				// the path to the peer degraded
+				stats := aggregateProbes(d.probeHistory)
+				nx.logger.Warnf("The path to peer (hostname:%s pubkey:%s) is %s, average latency %.2fms, %.0f%% of the probes lost",
+					d.device.Hostname, d.device.PublicKey, reachability, float64(stats.latencyAvg)/float64(time.Millisecond), stats.loss*100)
+			case reachability == PeerReachable && d.reachability != "":
+				nx.logger.Infof("The path to peer (hostname:%s pubkey:%s) recovered", d.device.Hostname, d.device.PublicKey)
+			default:
+				nx.logger.Debugf("peer (hostname:%s pubkey:%s) is %s", d.device.Hostname, d.device.PublicKey, reachability)
+			}
+		}
+		d.reachability = reachability
+		d.probeLatency = 0
+		if result.err == nil {
+			d.probeLatency = result.latency
+		}
+		d.lastProbeTime = now
+		nx.deviceCache[result.publicKey] = d
+	}
+}
+
+// peerReachabilityStatus renders the reachability of each peer for the nexd status output
+func (nx *Nexodus) peerReachabilityStatus() string {
+	var rows []string
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if d.device.PublicKey == nx.wireguardPubKey || d.lastProbeTime.IsZero() {
+			return
+		}
+		tunnelIP := ""
+		if len(d.device.Ipv4TunnelIps) > 0 {
+			tunnelIP = d.device.Ipv4TunnelIps[0].Address
+		}
+		latency := "-"
+		if d.probeLatency > 0 {
+			latency = fmt.Sprintf("%.2fms", float64(d.probeLatency)/float64(time.Millisecond))
+		}
+		rows = append(rows, fmt.Sprintf("  %s\t%s\t%s\t%s\t", d.device.Hostname, tunnelIP, d.reachability, latency))
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+	sort.Strings(rows)
+
+	sb := &strings.Builder{}
+	sb.WriteString("Peers:\n")
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  HOSTNAME\tTUNNEL IP\tREACHABILITY\tLATENCY\t")
+	for _, row := range rows {
+		fmt.Fprintln(w, row)
+	}
+	_ = w.Flush()
+	return sb.String()
+}
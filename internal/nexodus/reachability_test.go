@@ -0,0 +1,55 @@
+package nexodus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyReachability(t *testing.T) {
+	probeErr := errors.New("ping failed")
+	tests := []struct {
+		name             string
+		probeErr         error
+		latency          time.Duration
+		handshakeHealthy bool
+		expected         string
+	}{
+		{"probe answered", nil, 10 * time.Millisecond, true, PeerReachable},
+		{"probe answered slowly", nil, time.Second, true, PeerDegraded},
+		{"probe lost with a live session", probeErr, 0, true, PeerDegraded},
+		{"probe lost without a session", probeErr, 0, false, PeerUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, classifyReachability(tt.probeErr, tt.latency, tt.handshakeHealthy))
+		})
+	}
+}
+
+func TestAggregateProbes(t *testing.T) {
+	require := require.New(t)
+	require.Equal(probeStats{}, aggregateProbes(nil))
+
+	var history []probeSample
+	for i := 1; i <= reachabilityHistorySize+2; i++ {
+		history = appendProbe(history, probeSample{latency: time.Duration(i) * time.Millisecond})
+	}
+	// the oldest probes were dropped
+	require.Len(history, reachabilityHistorySize)
+	require.Equal(3*time.Millisecond, history[0].latency)
+
+	history = appendProbe(history, probeSample{lost: true})
+	history = appendProbe(history, probeSample{lost: true})
+	stats := aggregateProbes(history)
+	// the probes of 5ms to 12ms were answered
+	require.Equal(8500*time.Microsecond, stats.latencyAvg)
+	require.Equal(12*time.Millisecond, stats.latencyMax)
+	require.InDelta(0.2, stats.loss, 0.0001)
+
+	stats = aggregateProbes([]probeSample{{lost: true}})
+	require.Zero(stats.latencyAvg)
+	require.Equal(1.0, stats.loss)
+}
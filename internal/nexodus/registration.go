@@ -0,0 +1,103 @@
+package nexodus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/state"
+)
+
+// errRegistrationRejected is returned when the service no longer accepts the cached registration of the device
+var errRegistrationRejected = errors.New("the registration of the device was rejected")
+
+// registrationCredential identifies the credentials nexd registers the device with
+func (nx *Nexodus) registrationCredential() string {
+	if nx.regKey != "" {
+		sum := sha256.Sum256([]byte(nx.regKey))
+		return hex.EncodeToString(sum[:])
+	}
+	return nx.username
+}
+
+// cachedRegistration returns the registration of the previous run if it matches the current options, nil when
+// the device has to register again. Assumes the keys are loaded.
+func (nx *Nexodus) cachedRegistration() *state.Registration {
+	if nx.stateStore == nil {
+		return nil
+	}
+	registration := nx.stateStore.State().Registration
+	switch {
+	case registration == nil:
+		return nil
+	case registration.ServiceURL != nx.apiURL.String(),
+		registration.Credential != nx.registrationCredential(),
+		registration.PublicKey != nx.wireguardPubKey:
+		return nil
+	case nx.vpcId != "" && nx.vpcId != registration.VPC.Id:
+		return nil
+	case nx.requestedIP != "" && !slices.Contains(registration.TunnelIPs, nx.requestedIP):
+		return nil
+	case nx.regKey != "" && registration.DeviceToken == "":
+		// the reg key is only exchanged for the device token when the device registers
+		return nil
+	}
+	return registration
+}
+
+// registrationVPC returns the vpc of the cached registration
+func registrationVPC(registration *state.Registration) *public.ModelsVPC {
+	return &public.ModelsVPC{
+		Id:             registration.VPC.Id,
+		OrganizationId: registration.VPC.OrganizationId,
+		Description:    registration.VPC.Description,
+		Ipv4Cidr:       registration.VPC.Ipv4Cidr,
+		Ipv6Cidr:       registration.VPC.Ipv6Cidr,
+	}
+}
+
+// storeRegistration saves the registration of the device, so that the next start only has to validate it
+func (nx *Nexodus) storeRegistration(userId string, device public.ModelsDevice, deviceToken string) {
+	if nx.stateStore == nil {
+		return
+	}
+	registration := &state.Registration{
+		ServiceURL:  nx.apiURL.String(),
+		Credential:  nx.registrationCredential(),
+		PublicKey:   nx.wireguardPubKey,
+		DeviceID:    device.Id,
+		UserID:      userId,
+		DeviceToken: deviceToken,
+		VPC: state.RegistrationVPC{
+			Id:             nx.vpc.Id,
+			OrganizationId: nx.vpc.OrganizationId,
+			Description:    nx.vpc.Description,
+			Ipv4Cidr:       nx.vpc.Ipv4Cidr,
+			Ipv6Cidr:       nx.vpc.Ipv6Cidr,
+		},
+		ListenPortMin: nx.orgListenPortMin,
+		ListenPortMax: nx.orgListenPortMax,
+	}
+	for _, ip := range device.Ipv4TunnelIps {
+		registration.TunnelIPs = append(registration.TunnelIPs, ip.Address)
+	}
+	for _, ip := range device.Ipv6TunnelIps {
+		registration.TunnelIPs = append(registration.TunnelIPs, ip.Address)
+	}
+	nx.registration = registration
+	nx.stateStore.State().Registration = registration
+	if err := nx.stateStore.Store(); err != nil {
+		nx.logger.Warnf("Failed to store the registration of the device: %v", err)
+	}
+}
+
+// forgetRegistration drops the cached registration, the device registers again
+func (nx *Nexodus) forgetRegistration() {
+	nx.registration = nil
+	nx.stateStore.State().Registration = nil
+	if err := nx.stateStore.Store(); err != nil {
+		nx.logger.Warnf("Failed to remove the registration of the device: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package nexodus
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/state/fstore"
+)
+
+func TestRegistration(t *testing.T) {
+	require := require.New(t)
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	apiURL, err := url.Parse("https://api.try.nexodus.127.0.0.1.nip.io")
+	require.NoError(err)
+	newNexodus := func() *Nexodus {
+		store := fstore.New(stateFile)
+		require.NoError(store.Load())
+		return &Nexodus{
+			apiURL:          apiURL,
+			regKey:          "RK:token",
+			stateStore:      store,
+			wireguardPubKey: "local-key",
+			logger:          zap.NewNop().Sugar(),
+		}
+	}
+
+	nx := newNexodus()
+	require.Nil(nx.cachedRegistration())
+	nx.vpc = &public.ModelsVPC{Id: "vpc", OrganizationId: "org", Ipv4Cidr: "100.64.0.0/10"}
+	nx.orgListenPortMin, nx.orgListenPortMax = 51820, 51900
+	nx.storeRegistration("user", public.ModelsDevice{
+		Id:            "device",
+		Ipv4TunnelIps: []public.ModelsTunnelIP{{Address: "100.64.0.1"}},
+	}, "DT:token")
+
+	// the next start reads the registration from the state file
+	nx = newNexodus()
+	registration := nx.cachedRegistration()
+	require.NotNil(registration)
+	require.Equal("device", registration.DeviceID)
+	require.Equal("user", registration.UserID)
+	require.Equal("DT:token", registration.DeviceToken)
+	require.Equal([]string{"100.64.0.1"}, registration.TunnelIPs)
+	require.Equal(int32(51900), registration.ListenPortMax)
+	require.Equal("100.64.0.0/10", registrationVPC(registration).Ipv4Cidr)
+
+	nx.requestedIP = "100.64.0.1"
+	require.NotNil(nx.cachedRegistration())
+
+	// the device registers again when the options do not match the registration
+	nx.requestedIP = "100.64.0.2"
+	require.Nil(nx.cachedRegistration())
+	nx.requestedIP = ""
+	nx.regKey = "RK:other"
+	require.Nil(nx.cachedRegistration())
+	nx.regKey = "RK:token"
+	nx.vpcId = "other-vpc"
+	require.Nil(nx.cachedRegistration())
+	nx.vpcId = ""
+	nx.wireguardPubKey = "rotated-key"
+	require.Nil(nx.cachedRegistration())
+	nx.wireguardPubKey = "local-key"
+
+	nx.forgetRegistration()
+	nx = newNexodus()
+	require.Nil(nx.cachedRegistration())
+}
@@ -0,0 +1,156 @@
+package nexodus
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+	"tailscale.com/metrics"
+)
+
+const (
+	// how often relays report their load and devices behind a symmetric NAT their latency to the relays
+	relayReportInterval = time.Minute
+	// a peer of a relay without a handshake for this long is not counted in the load of the relay
+	relayActivePeerTimeout = 3 * time.Minute
+	// the metadata key under which devices behind a symmetric NAT report their latency to the relays
+	relayLatencyMetadataKey = "relay-latency"
+)
+
+// relayLoadSample is the traffic counters of a relay at the time of the last load report
+type relayLoadSample struct {
+	time    time.Time
+	rxBytes int64
+	txBytes int64
+}
+
+// selectRelay returns the relay this device uses, assumes deviceCacheLock is held. Healthy relays are
+// preferred over unhealthy ones, and the relay the service assigned to this device over the others.
+func (nx *Nexodus) selectRelay() (deviceCacheEntry, bool) {
+	assigned := ""
+	if self, ok := nx.deviceCache[nx.wireguardPubKey]; ok {
+		assigned = self.device.RelayId
+	}
+	rank := func(d deviceCacheEntry) int {
+		r := 0
+		if d.peerHealthy {
+			r += 2
+		}
+		if assigned != "" && d.device.Id == assigned {
+			r++
+		}
+		return r
+	}
+
+	var relay deviceCacheEntry
+	found := false
+	for _, d := range nx.deviceCache {
+		if !d.device.Relay {
+			continue
+		}
+		if !found || rank(d) > rank(relay) || (rank(d) == rank(relay) && d.device.PublicKey < relay.device.PublicKey) {
+			relay = d
+			found = true
+		}
+	}
+	return relay, found
+}
+
+func (nx *Nexodus) runRelayReporting(ctx context.Context) {
+	timer := time.NewTimer(util.Jitter(relayReportInterval))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			var err error
+			if nx.relay || nx.relayDerp {
+				_, err = nx.updateDeviceRelayMetadata(nx.deviceId)
+			} else if nx.symmetricNat {
+				err = nx.reportRelayLatency()
+			}
+			if err != nil {
+				nx.logger.Debugf("failed to report to the relay selection: %v", err)
+			}
+			timer.Reset(util.Jitter(relayReportInterval))
+		}
+	}
+}
+
+// relayLoad returns the number of active peers of this relay and the bytes per second it received
+// and sent since the last time it was called.
+func (nx *Nexodus) relayLoad(now time.Time) (int, float64, float64) {
+	peers := 0
+	var rxBytes, txBytes int64
+	if nx.relayDerp && nx.Derper != nil {
+		var clients int64
+		clients, rxBytes, txBytes = nx.Derper.load()
+		peers = int(clients)
+	} else {
+		sessions, err := nx.DumpPeersDefault()
+		if err != nil {
+			nx.logger.Debugf("failed to get the peer stats for the relay load: %v", err)
+		}
+		for _, s := range sessions {
+			if !s.LastHandshakeTime.IsZero() && now.Sub(s.LastHandshakeTime) < relayActivePeerTimeout {
+				peers++
+			}
+			rxBytes += s.Rx
+			txBytes += s.Tx
+		}
+	}
+
+	last := nx.relayLoadSample
+	nx.relayLoadSample = relayLoadSample{time: now, rxBytes: rxBytes, txBytes: txBytes}
+	elapsed := now.Sub(last.time).Seconds()
+	if last.time.IsZero() || elapsed <= 0 {
+		return peers, 0, 0
+	}
+	rate := func(current, previous int64) float64 {
+		if current < previous {
+			// the counters were reset
+			return 0
+		}
+		return float64(current-previous) / elapsed
+	}
+	return peers, rate(rxBytes, last.rxBytes), rate(txBytes, last.txBytes)
+}
+
+// load returns the number of clients connected to the derp server and the bytes it received and sent
+func (d *Derper) load() (int64, int64, int64) {
+	m, ok := expvar.Get("derp").(*metrics.Set)
+	if !ok {
+		return 0, 0, 0
+	}
+	value := func(name string) int64 {
+		if v, ok := m.Get(name).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	return value("gauge_current_connections"), value("bytes_received"), value("bytes_sent")
+}
+
+// reportRelayLatency reports the round trip times of the last probes of the relays, so that the
+// service can assign this device the relay that is best for it. Nothing is reported when there
+// is no relay to choose from.
+func (nx *Nexodus) reportRelayLatency() error {
+	latencies := map[string]interface{}{}
+	relays := 0
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		if !d.device.Relay {
+			return
+		}
+		relays++
+		if d.probeLatency > 0 {
+			latencies[d.device.Id] = float64(d.probeLatency.Microseconds()) / 1000
+		}
+	})
+	if relays < 2 || len(latencies) == 0 {
+		return nil
+	}
+	_, _, err := nx.client.DevicesApi.UpdateDeviceMetadataKey(context.Background(), nx.deviceId, relayLatencyMetadataKey).Value(latencies).Execute()
+	return err
+}
@@ -0,0 +1,50 @@
+package nexodus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestSelectRelay(t *testing.T) {
+	require := require.New(t)
+	relay := func(id string, healthy bool) deviceCacheEntry {
+		d := deviceCacheEntry{
+			device: public.ModelsDevice{Id: id, PublicKey: id, Hostname: id, Relay: true},
+		}
+		d.peerHealthy = healthy
+		return d
+	}
+	nx := &Nexodus{
+		wireguardPubKey: "self",
+		deviceCache: map[string]deviceCacheEntry{
+			"self": {device: public.ModelsDevice{Id: "self", PublicKey: "self", SymmetricNat: true}},
+			"a":    relay("a", true),
+			"b":    relay("b", true),
+		},
+	}
+
+	// without an assigned relay the choice is stable
+	selected, found := nx.selectRelay()
+	require.True(found)
+	require.Equal("a", selected.device.Hostname)
+
+	// the relay assigned by the service is used
+	self := nx.deviceCache["self"]
+	self.device.RelayId = "b"
+	nx.deviceCache["self"] = self
+	selected, _ = nx.selectRelay()
+	require.Equal("b", selected.device.Hostname)
+
+	// unless it is unhealthy and another relay is healthy
+	nx.deviceCache["b"] = relay("b", false)
+	selected, _ = nx.selectRelay()
+	require.Equal("a", selected.device.Hostname)
+
+	delete(nx.deviceCache, "a")
+	delete(nx.deviceCache, "b")
+	_, found = nx.selectRelay()
+	require.False(found)
+}
@@ -0,0 +1,141 @@
+package nexodus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap/zapcore"
+)
+
+const reloadTimeout = 30 * time.Second
+
+// ReloadOptions are the options of a running nexd that can be changed without tearing down
+// the wireguard interface and re-registering the device.
+type ReloadOptions struct {
+	// AdvertiseCidrs replaces the cidrs advertised by a router, nil leaves them unchanged
+	AdvertiseCidrs []string
+	RelayOnly      bool
+	// SecurityGroupId replaces the security group of the device, empty leaves it unchanged
+	SecurityGroupId string
+	// LogLevel replaces the log level, nil leaves it unchanged
+	LogLevel *zapcore.Level
+}
+
+type reloadRequest struct {
+	options ReloadOptions
+	result  chan reloadResult
+}
+
+type reloadResult struct {
+	changes []string
+	err     error
+}
+
+// Reload reloads the options returned by the reload function of the options, the changes are
+// applied by the reconcile loop so that they don't race with the peer reconciliation.
+func (nx *Nexodus) Reload() (string, error) {
+	if nx.reloadOptions == nil {
+		return "", errors.New("this nexd does not support reloading its configuration")
+	}
+	options, err := nx.reloadOptions()
+	if err != nil {
+		return "", fmt.Errorf("failed to load the configuration: %w", err)
+	}
+
+	req := reloadRequest{options: options, result: make(chan reloadResult, 1)}
+	select {
+	case nx.reloadCh <- req:
+	case <-time.After(reloadTimeout):
+		return "", errors.New("timed out waiting for nexd to accept the configuration, it may still be starting")
+	}
+	res := <-req.result
+	if res.err != nil {
+		return "", res.err
+	}
+	if len(res.changes) == 0 {
+		return "Configuration reloaded, nothing changed", nil
+	}
+	return fmt.Sprintf("Configuration reloaded, changed: %s", strings.Join(res.changes, ", ")), nil
+}
+
+// applyReload applies reloaded options to the device, the wireguard interface and the sessions with the peers are kept
+func (nx *Nexodus) applyReload(ctx context.Context, o ReloadOptions) ([]string, error) {
+	for _, cidr := range o.AdvertiseCidrs {
+		if err := ValidateCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid advertise cidr: %w", err)
+		}
+	}
+	if o.SecurityGroupId != "" {
+		if _, err := uuid.Parse(o.SecurityGroupId); err != nil {
+			return nil, fmt.Errorf("invalid security group id %q: %w", o.SecurityGroupId, err)
+		}
+		if nx.regKey != "" {
+			return nil, errors.New("the security group of a device registered with a registration key can not be changed")
+		}
+	}
+
+	if o.AdvertiseCidrs != nil {
+		// the pod CIDR, the discovered LAN subnets and the learned prefixes are advertised along the configured cidrs
+		var automatic []string
+		if nx.podIPAM != nil {
+			automatic = append(automatic, nx.podIPAM.prefix.String())
+		}
+		automatic = append(automatic, nx.lanSubnets...)
+		automatic = append(automatic, nx.learnedCidrs...)
+		cidrs := slices.Clone(o.AdvertiseCidrs)
+		for _, cidr := range automatic {
+			if !slices.Contains(cidrs, cidr) {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+		o.AdvertiseCidrs = cidrs
+	}
+
+	var changes []string
+	fields := map[string]interface{}{}
+	advertiseCidrsChanged := o.AdvertiseCidrs != nil && !slices.Equal(o.AdvertiseCidrs, nx.advertiseCidrs)
+	if advertiseCidrsChanged {
+		fields["advertise_cidrs"] = o.AdvertiseCidrs
+		changes = append(changes, "advertise cidrs")
+	}
+	// a relay is still required if symmetric NAT was detected or UDP is blocked
+	symmetricNat := o.RelayOnly || nx.symmetricNatDetected || nx.udpBlocked
+	if o.RelayOnly != nx.relayOnly {
+		fields["symmetric_nat"] = symmetricNat
+		changes = append(changes, "relay only")
+	}
+	if o.SecurityGroupId != "" && o.SecurityGroupId != nx.securityGroupId {
+		fields["security_group_id"] = o.SecurityGroupId
+		changes = append(changes, "security group")
+	}
+
+	if len(fields) != 0 {
+		if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(ctx, nx.deviceId, fields); err != nil {
+			return nil, fmt.Errorf("failed to update the device: %w", err)
+		}
+		if advertiseCidrsChanged {
+			nx.advertiseCidrs = o.AdvertiseCidrs
+			if nx.networkRouter {
+				if err := nx.setupNetworkRouterNode(); err != nil {
+					return changes, fmt.Errorf("failed to setup this device as a network router node: %w", err)
+				}
+			}
+		}
+		nx.relayOnly = o.RelayOnly
+		nx.symmetricNat = symmetricNat
+		if o.SecurityGroupId != "" {
+			nx.securityGroupId = o.SecurityGroupId
+		}
+	}
+
+	if o.LogLevel != nil && *o.LogLevel != nx.logLevel.Level() {
+		nx.logLevel.SetLevel(*o.LogLevel)
+		changes = append(changes, "log level")
+	}
+	return changes, nil
+}
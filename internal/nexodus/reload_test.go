@@ -0,0 +1,58 @@
+package nexodus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestApplyReload(t *testing.T) {
+	require := require.New(t)
+	logLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	nx := &Nexodus{
+		logLevel:       &logLevel,
+		advertiseCidrs: []string{"10.10.0.0/24"},
+		relayOnly:      true,
+	}
+
+	// nothing changes without calling the api
+	changes, err := nx.applyReload(context.Background(), ReloadOptions{RelayOnly: true})
+	require.NoError(err)
+	require.Empty(changes)
+
+	changes, err = nx.applyReload(context.Background(), ReloadOptions{
+		AdvertiseCidrs: []string{"10.10.0.0/24"},
+		RelayOnly:      true,
+	})
+	require.NoError(err)
+	require.Empty(changes)
+
+	// the discovered LAN subnets are kept
+	nx.lanSubnets = []string{"192.168.1.0/24"}
+	nx.advertiseCidrs = []string{"10.10.0.0/24", "192.168.1.0/24"}
+	changes, err = nx.applyReload(context.Background(), ReloadOptions{
+		AdvertiseCidrs: []string{"10.10.0.0/24"},
+		RelayOnly:      true,
+	})
+	require.NoError(err)
+	require.Empty(changes)
+
+	debug := zapcore.DebugLevel
+	changes, err = nx.applyReload(context.Background(), ReloadOptions{RelayOnly: true, LogLevel: &debug})
+	require.NoError(err)
+	require.Equal([]string{"log level"}, changes)
+	require.Equal(zapcore.DebugLevel, logLevel.Level())
+
+	_, err = nx.applyReload(context.Background(), ReloadOptions{AdvertiseCidrs: []string{"not-a-cidr"}})
+	require.Error(err)
+
+	_, err = nx.applyReload(context.Background(), ReloadOptions{SecurityGroupId: "not-a-uuid"})
+	require.Error(err)
+
+	nx.regKey = "key"
+	_, err = nx.applyReload(context.Background(), ReloadOptions{SecurityGroupId: "1b3b2f52-7c6b-4d0a-9e2a-3f3f3c1c5c6d"})
+	require.Error(err)
+}
@@ -0,0 +1,159 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/stun"
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+// address changes come in bursts when the device roams, they are handled once none was seen for this long
+const addressChangeDebounce = time.Second
+
+// runAddressMonitor watches for the address changes of the host and signals them to the main loop, so that roaming
+// to another network is handled right away rather than on the next STUN reconcile.
+func (nx *Nexodus) runAddressMonitor(ctx context.Context) {
+	events := make(chan struct{}, 1)
+	util.GoWithWaitGroup(nx.nexWg, func() {
+		if err := nx.watchAddresses(ctx, events); err != nil {
+			nx.logger.Warnf("Address changes of this device are not monitored, roaming is detected by the STUN reconcile: %v", err)
+		}
+	})
+
+	debounce := time.NewTimer(addressChangeDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			debounce.Reset(addressChangeDebounce)
+		case <-debounce.C:
+			select {
+			case nx.addressChanged <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// notifyAddressChange signals an address change to runAddressMonitor without blocking
+func notifyAddressChange(events chan<- struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
+
+// handleAddressChange re-discovers the endpoints of this device once the addresses of the host changed: the local
+// address, the host candidates, and the reflexive address and NAT behavior of the network the device roamed to.
+// When any of them changed, the device is updated so that peers re-peer with the new endpoints, and peering with
+// every peer starts over.
+func (nx *Nexodus) handleAddressChange(ctx context.Context, deviceID string) error {
+	localAddress := nx.endpointLocalAddress
+	if nx.userProvidedLocalIP == "" {
+		var err error
+		localAddress, err = nx.findLocalIP()
+		if err != nil {
+			// the device is likely between networks, the next address change is handled
+			nx.logger.Debugf("unable to determine the ip address of the host after its addresses changed: %v", err)
+			return nil
+		}
+	}
+	oldLocalAddress := nx.endpointLocalAddress
+	nx.endpointLocalAddress = localAddress
+	hostCandidates, localPrefixes := nx.hostCandidates()
+
+	// the gateway of the new network needs a new mapping
+	if nx.portMapper != nil {
+		nx.portMapper.NoteNetworkDown()
+	}
+
+	var behavior stun.NatBehavior
+	err := util.RetryOperation(ctx, time.Second, maxRetries, func() error {
+		var err error
+		behavior, err = stun.DiscoverNatBehavior(nx.logger, nx.listenPort)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if nx.udpBlocked || nx.relay || nx.relayDerp {
+			return nil
+		}
+		nx.noteUDPBlocked()
+		if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(context.Background(), deviceID, map[string]interface{}{
+			"symmetric_nat": true,
+		}); err != nil {
+			return fmt.Errorf("failed to update this device once UDP was found blocked on the new network: %w", err)
+		}
+		return nil
+	}
+
+	// the device may have lost or gained global IPv6 connectivity
+	ipv6Endpoint, err := nx.discoverIPv6Endpoint()
+	if err != nil {
+		nx.logger.Debugf("this device has no IPv6 endpoint: %v", err)
+	}
+
+	symmetricNatDetected := behavior.Mapping.EndpointDependent()
+	symmetricNat := nx.relayOnly || symmetricNatDetected
+	if localAddress == oldLocalAddress && slices.Equal(nx.hostCandidateAddrs, hostCandidates) &&
+		behavior.ReflexiveAddress == nx.nodeReflexiveAddressIPv4 && behavior.Mapping == nx.natBehavior.Mapping &&
+		behavior.Hairpin == nx.natBehavior.Hairpin && symmetricNat == nx.symmetricNat && !nx.udpBlocked &&
+		ipv6Endpoint == nx.nodeReflexiveAddressIPv6 {
+		nx.logger.Debug("the addresses of this device changed, but its endpoints did not")
+		return nil
+	}
+	nx.logger.Infof("This device roamed, the local address is %s and the reflexive address is %s, updating peers",
+		localAddress, behavior.ReflexiveAddress)
+
+	fields := map[string]interface{}{
+		"endpoints":     nx.endpointCandidates(hostCandidates, behavior.ReflexiveAddress, nx.reflexiveAddrStunSrc, netip.AddrPort{}),
+		"symmetric_nat": symmetricNat,
+		"nat_type":      string(behavior.Mapping),
+		"nat_hairpin":   behavior.Hairpin,
+		"endpoint_ipv6": "",
+	}
+	if ipv6Endpoint.IsValid() {
+		fields["endpoint_ipv6"] = ipv6Endpoint.String()
+	}
+	_, _, err = nx.client.DevicesApi.UpdateDeviceFields(context.Background(), deviceID, fields)
+	if err != nil {
+		// the STUN reconcile publishes the new endpoints once the api-server is reachable again
+		return fmt.Errorf("failed to update the endpoints of this device after it roamed: %w", err)
+	}
+	nx.nodeReflexiveAddressIPv4 = behavior.ReflexiveAddress
+	nx.nodeReflexiveAddressIPv6 = ipv6Endpoint
+	nx.hostCandidateAddrs = hostCandidates
+	nx.natBehavior = behavior
+	nx.symmetricNat = symmetricNat
+	nx.symmetricNatDetected = symmetricNatDetected
+	nx.udpBlocked = false
+
+	// the paths to the peers found on the previous network are gone
+	nx.holePunch.lock.Lock()
+	nx.holePunch.endpoints = nil
+	nx.holePunch.requested = nil
+	nx.holePunch.lock.Unlock()
+	nx.deviceCacheLock.Lock()
+	nx.localPrefixes = localPrefixes
+	for key, d := range nx.deviceCache {
+		if key == nx.wireguardPubKey {
+			continue
+		}
+		nx.peeringReset(&d)
+		nx.deviceCache[key] = d
+	}
+	nx.deviceCacheLock.Unlock()
+	if err := nx.reconcileDeviceCache(); err != nil {
+		nx.logger.Debugf("reconcile failed after this device roamed: %v", err)
+	}
+	return nil
+}
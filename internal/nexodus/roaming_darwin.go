@@ -0,0 +1,50 @@
+//go:build darwin
+
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/route"
+	"golang.org/x/sys/unix"
+)
+
+// watchAddresses notifies the address changes of the interfaces other than the tunnel interface, as reported by
+// the routing socket
+func (nx *Nexodus) watchAddresses(ctx context.Context, events chan<- struct{}) error {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		return fmt.Errorf("failed to open the routing socket: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = unix.Close(fd)
+	}()
+
+	buf := make([]byte, 2048)
+	for {
+		n, err := unix.Read(fd, buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read the routing socket: %w", err)
+		}
+		msgs, err := route.ParseRIB(route.RIBTypeRoute, buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			addrMsg, ok := msg.(*route.InterfaceAddrMessage)
+			if !ok || addrMsg.Type != unix.RTM_NEWADDR && addrMsg.Type != unix.RTM_DELADDR {
+				continue
+			}
+			if tunnel, err := net.InterfaceByName(nx.tunnelIface); err == nil && tunnel.Index == addrMsg.Index {
+				continue
+			}
+			notifyAddressChange(events)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// watchAddresses notifies the address changes of the interfaces other than the tunnel interface, as reported by
+// netlink
+func (nx *Nexodus) watchAddresses(ctx context.Context, events chan<- struct{}) error {
+	updates := make(chan netlink.AddrUpdate)
+	if err := netlink.AddrSubscribeWithOptions(updates, ctx.Done(), netlink.AddrSubscribeOptions{
+		ErrorCallback: func(err error) {
+			nx.logger.Debugf("netlink address subscription error: %v", err)
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to subscribe to the netlink address updates: %w", err)
+	}
+	for update := range updates {
+		if update.LinkAddress.IP.IsLoopback() || update.LinkAddress.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if tunnel, err := net.InterfaceByName(nx.tunnelIface); err == nil && tunnel.Index == update.LinkIndex {
+			continue
+		}
+		notifyAddressChange(events)
+	}
+	return nil
+}
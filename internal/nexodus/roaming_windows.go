@@ -0,0 +1,55 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"context"
+	"net"
+	"slices"
+	"time"
+)
+
+// how often the addresses of the interfaces are compared, windows address change notifications are not used
+const addressPollInterval = 2 * time.Second
+
+// watchAddresses notifies the address changes of the interfaces other than the tunnel interface by comparing the
+// addresses every addressPollInterval
+func (nx *Nexodus) watchAddresses(ctx context.Context, events chan<- struct{}) error {
+	last := nx.interfaceAddrs()
+	ticker := time.NewTicker(addressPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			addrs := nx.interfaceAddrs()
+			if !slices.Equal(last, addrs) {
+				last = addrs
+				notifyAddressChange(events)
+			}
+		}
+	}
+}
+
+// interfaceAddrs returns the addresses of the interfaces other than the tunnel interface
+func (nx *Nexodus) interfaceAddrs() []string {
+	var addrs []string
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		if iface.Name == nx.tunnelIface {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			addrs = append(addrs, iface.Name+"/"+addr.String())
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,138 @@
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// mainRouteTable selects the default host routing table when listing host routes
+const mainRouteTable = 0
+
+// hostRoute is an existing entry in the host routing table
+type hostRoute struct {
+	Dst   *net.IPNet
+	Gw    net.IP
+	Dev   string
+	Table int
+}
+
+func (r hostRoute) String() string {
+	s := r.Dst.String()
+	if r.Gw != nil && !r.Gw.IsUnspecified() {
+		s += " via " + r.Gw.String()
+	}
+	if r.Dev != "" {
+		s += " dev " + r.Dev
+	}
+	return s
+}
+
+// conflictsWith is true when the host route and the prefix cover some of the same addresses.
+// Default routes only conflict with other default routes, every other route is more specific.
+func (r hostRoute) conflictsWith(prefix *net.IPNet) bool {
+	if (r.Dst.IP.To4() == nil) != (prefix.IP.To4() == nil) {
+		return false
+	}
+	routeOnes, _ := r.Dst.Mask.Size()
+	prefixOnes, _ := prefix.Mask.Size()
+	if routeOnes == 0 || prefixOnes == 0 {
+		return routeOnes == prefixOnes
+	}
+	return r.Dst.Contains(prefix.IP) || prefix.Contains(r.Dst.IP)
+}
+
+// shadows is true when the host route is at least as specific as the prefix, so it would keep
+// taking precedence over a route for the prefix unless it is removed.
+func (r hostRoute) shadows(prefix *net.IPNet) bool {
+	routeOnes, _ := r.Dst.Mask.Size()
+	prefixOnes, _ := prefix.Mask.Size()
+	return routeOnes >= prefixOnes
+}
+
+// findRouteConflicts filters the host routes that conflict with prefix and are not on the tunnel device
+func findRouteConflicts(routes []hostRoute, prefix *net.IPNet, tunnelDev string) []hostRoute {
+	var conflicts []hostRoute
+	for _, r := range routes {
+		if r.Dev == tunnelDev || !r.conflictsWith(prefix) {
+			continue
+		}
+		conflicts = append(conflicts, r)
+	}
+	return conflicts
+}
+
+// checkRouteConflicts looks for existing host routes that conflict with a route for prefix through
+// the tunnel. Without --override-routes the conflicts are logged and false is returned so that the
+// route is not installed, which would otherwise silently blackhole traffic to the local network.
+// With --override-routes the conflicting routes that would take precedence are removed.
+func (nx *Nexodus) checkRouteConflicts(prefix, dev string) bool {
+	destNet, err := ParseIPNet(prefix)
+	if err != nil {
+		nx.logger.Debugf("failed to parse a valid network address from %s: %v", prefix, err)
+		return true
+	}
+	routes, err := listHostRoutes(destNet, mainRouteTable)
+	if err != nil {
+		nx.logger.Debugf("failed to list host routes: %v", err)
+		return true
+	}
+	conflicts := findRouteConflicts(routes, destNet, dev)
+	if len(conflicts) == 0 {
+		return true
+	}
+
+	if !nx.overrideRoutes {
+		nx.logger.Warnf("Not installing the route for %s: %v", prefix, routeConflictError(conflicts))
+		return false
+	}
+
+	nx.logger.Warnf("Replacing the host routes [ %s ] that conflict with %s", formatHostRoutes(conflicts), prefix)
+	for _, c := range conflicts {
+		if !c.shadows(destNet) {
+			continue
+		}
+		if err := deleteHostRoute(c); err != nil {
+			nx.logger.Errorf("failed to remove the conflicting route %s: %v", c, err)
+			return false
+		}
+	}
+	return true
+}
+
+// checkExitRouteTableConflicts makes sure the routing table used for exit node traffic is not
+// already in use by other software, such as wg-quick, before it is flushed and replaced.
+func (nx *Nexodus) checkExitRouteTableConflicts() error {
+	_, defaultNet, _ := net.ParseCIDR("0.0.0.0/0")
+	routes, err := listHostRoutes(defaultNet, wgFwMark)
+	if err != nil {
+		nx.logger.Debugf("failed to list host routes: %v", err)
+		return nil
+	}
+	var conflicts []hostRoute
+	for _, r := range routes {
+		if r.Dev != nx.tunnelIface {
+			conflicts = append(conflicts, r)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	if !nx.overrideRoutes {
+		return fmt.Errorf("routing table %d is already in use: %w", wgFwMark, routeConflictError(conflicts))
+	}
+	nx.logger.Warnf("Replacing the host routes [ %s ] in routing table %d", formatHostRoutes(conflicts), wgFwMark)
+	return nil
+}
+
+func formatHostRoutes(routes []hostRoute) string {
+	entries := make([]string, len(routes))
+	for i, r := range routes {
+		entries[i] = r.String()
+	}
+	return strings.Join(entries, ", ")
+}
+
+func routeConflictError(conflicts []hostRoute) error {
+	return fmt.Errorf("conflicts with the existing host routes [ %s ], use --override-routes to replace them", formatHostRoutes(conflicts))
+}
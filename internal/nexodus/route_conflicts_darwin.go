@@ -0,0 +1,113 @@
+//go:build darwin
+
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// listHostRoutes returns the routes in the routing table that are of the same address family as
+// prefix. Darwin has a single routing table so the table is ignored.
+func listHostRoutes(prefix *net.IPNet, table int) ([]hostRoute, error) {
+	if table != mainRouteTable {
+		return nil, nil
+	}
+	family := "inet6"
+	if prefix.IP.To4() != nil {
+		family = "inet"
+	}
+	out, err := RunCommand("netstat", "-r", "-n", "-f", family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the routing table: %w", err)
+	}
+	return parseNetstatRoutes(out, family == "inet6"), nil
+}
+
+// parseNetstatRoutes parses the output of netstat -r -n, locating the columns from its header
+func parseNetstatRoutes(out string, v6 bool) []hostRoute {
+	var routes []hostRoute
+	netifCol := -1
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "Destination" {
+			for i, f := range fields {
+				if f == "Netif" {
+					netifCol = i
+				}
+			}
+			continue
+		}
+		if netifCol < 0 || len(fields) <= netifCol {
+			continue
+		}
+		dst := parseNetstatDestination(fields[0], v6)
+		if dst == nil {
+			continue
+		}
+		routes = append(routes, hostRoute{
+			Dst: dst,
+			Gw:  net.ParseIP(fields[1]),
+			Dev: fields[netifCol],
+		})
+	}
+	return routes
+}
+
+// parseNetstatDestination converts the abbreviated destinations printed by netstat, such as
+// "default", "10.1/16" or "192.168.1", to a network.
+func parseNetstatDestination(dest string, v6 bool) *net.IPNet {
+	if dest == "default" {
+		if v6 {
+			dest = "::/0"
+		} else {
+			dest = "0.0.0.0/0"
+		}
+	}
+	addr, bits, hasBits := strings.Cut(dest, "/")
+	// strip the scope of link local addresses
+	addr, _, _ = strings.Cut(addr, "%")
+
+	if v6 {
+		if !hasBits {
+			bits = "128"
+		}
+		_, ipNet, err := net.ParseCIDR(addr + "/" + bits)
+		if err != nil {
+			return nil
+		}
+		return ipNet
+	}
+
+	octets := strings.Split(addr, ".")
+	if len(octets) > 4 {
+		return nil
+	}
+	if !hasBits {
+		bits = fmt.Sprintf("%d", len(octets)*8)
+	}
+	for len(octets) < 4 {
+		octets = append(octets, "0")
+	}
+	_, ipNet, err := net.ParseCIDR(strings.Join(octets, ".") + "/" + bits)
+	if err != nil {
+		return nil
+	}
+	return ipNet
+}
+
+// deleteHostRoute removes an existing route from the host routing table
+func deleteHostRoute(r hostRoute) error {
+	family := "-inet6"
+	if r.Dst.IP.To4() != nil {
+		family = "-inet"
+	}
+	if _, err := RunCommand("route", "-q", "-n", "delete", family, r.Dst.String()); err != nil {
+		return fmt.Errorf("failed to delete the route %s: %w", r, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build linux
+
+package nexodus
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// listHostRoutes returns the routes in the routing table that are of the same address family as prefix
+func listHostRoutes(prefix *net.IPNet, table int) ([]hostRoute, error) {
+	family := netlink.FAMILY_V6
+	if prefix.IP.To4() != nil {
+		family = netlink.FAMILY_V4
+	}
+	if table == mainRouteTable {
+		table = unix.RT_TABLE_MAIN
+	}
+
+	routes, err := netlink.RouteListFiltered(family, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving netlink routes: %w", err)
+	}
+
+	var hostRoutes []hostRoute
+	for _, r := range routes {
+		dst := r.Dst
+		if dst == nil {
+			if family == netlink.FAMILY_V4 {
+				_, dst, _ = net.ParseCIDR("0.0.0.0/0")
+			} else {
+				_, dst, _ = net.ParseCIDR("::/0")
+			}
+		}
+		dev := ""
+		if link, err := netlink.LinkByIndex(r.LinkIndex); err == nil {
+			dev = link.Attrs().Name
+		}
+		hostRoutes = append(hostRoutes, hostRoute{
+			Dst:   dst,
+			Gw:    r.Gw,
+			Dev:   dev,
+			Table: r.Table,
+		})
+	}
+	return hostRoutes, nil
+}
+
+// deleteHostRoute removes an existing route from the host routing table
+func deleteHostRoute(r hostRoute) error {
+	route := &netlink.Route{
+		Dst:   r.Dst,
+		Gw:    r.Gw,
+		Table: r.Table,
+	}
+	if link, err := netlink.LinkByName(r.Dev); err == nil {
+		route.LinkIndex = link.Attrs().Index
+	}
+	if err := netlink.RouteDel(route); err != nil {
+		return fmt.Errorf("failed to delete the route %s: %w", r, err)
+	}
+	return nil
+}
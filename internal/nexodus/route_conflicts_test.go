@@ -0,0 +1,53 @@
+package nexodus
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	require.NoError(t, err)
+	return ipNet
+}
+
+func TestFindRouteConflicts(t *testing.T) {
+	routes := []hostRoute{
+		{Dst: mustParseCIDR(t, "0.0.0.0/0"), Gw: net.ParseIP("192.168.1.1"), Dev: "eth0"},
+		{Dst: mustParseCIDR(t, "192.168.1.0/24"), Dev: "eth0"},
+		{Dst: mustParseCIDR(t, "10.0.0.0/8"), Gw: net.ParseIP("192.168.1.254"), Dev: "eth0"},
+		{Dst: mustParseCIDR(t, "100.64.0.0/10"), Dev: "wg0"},
+		{Dst: mustParseCIDR(t, "fd00::/64"), Dev: "eth0"},
+	}
+	tests := []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{"no overlap", "172.16.0.0/16", nil},
+		{"same prefix", "192.168.1.0/24", []string{"192.168.1.0/24 dev eth0"}},
+		{"more specific prefix", "10.1.0.0/16", []string{"10.0.0.0/8 via 192.168.1.254 dev eth0"}},
+		{"less specific prefix", "192.168.0.0/16", []string{"192.168.1.0/24 dev eth0"}},
+		{"tunnel routes are ignored", "100.64.0.1/32", nil},
+		{"default route", "0.0.0.0/0", []string{"0.0.0.0/0 via 192.168.1.1 dev eth0"}},
+		{"other address family", "fd00::/64", []string{"fd00::/64 dev eth0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conflicts []string
+			for _, c := range findRouteConflicts(routes, mustParseCIDR(t, tt.prefix), "wg0") {
+				conflicts = append(conflicts, c.String())
+			}
+			require.Equal(t, tt.expected, conflicts)
+		})
+	}
+}
+
+func TestHostRouteShadows(t *testing.T) {
+	prefix := mustParseCIDR(t, "10.1.0.0/16")
+	require.True(t, hostRoute{Dst: mustParseCIDR(t, "10.1.2.0/24")}.shadows(prefix))
+	require.True(t, hostRoute{Dst: mustParseCIDR(t, "10.1.0.0/16")}.shadows(prefix))
+	require.False(t, hostRoute{Dst: mustParseCIDR(t, "10.0.0.0/8")}.shadows(prefix))
+}
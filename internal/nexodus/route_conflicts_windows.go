@@ -0,0 +1,69 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+)
+
+// listHostRoutes returns the routes in the routing table that are of the same address family as
+// prefix. Windows has a single routing table so the table is ignored.
+func listHostRoutes(prefix *net.IPNet, table int) ([]hostRoute, error) {
+	if table != mainRouteTable {
+		return nil, nil
+	}
+	family := winipcfg.AddressFamily(windows.AF_INET6)
+	if prefix.IP.To4() != nil {
+		family = windows.AF_INET
+	}
+	routes, err := winipcfg.GetIPForwardTable2(family)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving windows routes: %w", err)
+	}
+
+	var hostRoutes []hostRoute
+	for _, r := range routes {
+		if r.Loopback {
+			continue
+		}
+		dst := r.DestinationPrefix.Prefix()
+		dev := ""
+		if iface, err := r.InterfaceLUID.Interface(); err == nil {
+			dev = iface.Alias()
+		}
+		hostRoutes = append(hostRoutes, hostRoute{
+			Dst: &net.IPNet{
+				IP:   dst.Addr().AsSlice(),
+				Mask: net.CIDRMask(dst.Bits(), dst.Addr().BitLen()),
+			},
+			Gw:  r.NextHop.Addr().AsSlice(),
+			Dev: dev,
+		})
+	}
+	return hostRoutes, nil
+}
+
+// deleteHostRoute removes an existing route from the host routing table
+func deleteHostRoute(r hostRoute) error {
+	destination, err := parseRoutePrefix(r.Dst.String())
+	if err != nil {
+		return err
+	}
+	luid, err := interfaceLUID(r.Dev)
+	if err != nil {
+		return err
+	}
+	nextHop := unspecifiedNextHop(destination)
+	if gw, ok := netip.AddrFromSlice(r.Gw); ok {
+		nextHop = gw.Unmap()
+	}
+	if err := luid.DeleteRoute(destination, nextHop); err != nil {
+		return fmt.Errorf("failed to delete the route %s: %w", r, err)
+	}
+	return nil
+}
@@ -30,6 +30,9 @@ func (nx *Nexodus) handlePeerRouteOS(wgPeerConfig wgPeerConfig) error {
 		if util.IsIPv6Prefix(allowedIP) && !nx.ipv6Supported {
 			continue
 		}
+		if !nx.checkRouteConflicts(allowedIP, devName) {
+			continue
+		}
 		routeExists, err := RouteExistsOS(allowedIP)
 		if err != nil {
 			nx.logger.Debugf("failed to check if route exists: %v", err)
@@ -0,0 +1,168 @@
+package nexodus
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	// route changes come in bursts, e.g. when an interface goes down, they are handled once none was seen for this long
+	routeChangeDebounce = time.Second
+	// the routing table is listed again this often, in case a change was not notified
+	routePollInterval = time.Minute
+)
+
+// runRouteLearning watches the routing table for the routes within the --advertise-routes filters and hands their
+// prefixes to the reconcile loop, which advertises them
+func (nx *Nexodus) runRouteLearning(ctx context.Context) {
+	events := make(chan struct{}, 1)
+	util.GoWithWaitGroup(nx.nexWg, func() {
+		if err := nx.watchRoutes(ctx, events); err != nil {
+			nx.logger.Warnf("Route changes of this device are not monitored, the routing table is polled: %v", err)
+		}
+	})
+
+	debounce := time.NewTimer(0)
+	defer debounce.Stop()
+	poll := time.NewTicker(routePollInterval)
+	defer poll.Stop()
+	var learned []string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			debounce.Reset(routeChangeDebounce)
+			continue
+		case <-poll.C:
+		case <-debounce.C:
+		}
+		routes, err := nx.listFilteredRoutes()
+		if err != nil {
+			nx.logger.Warnf("failed to list the routes to advertise: %v", err)
+			continue
+		}
+		cidrs := matchRoutes(routes, nx.routeFilters, nx.tunnelIface)
+		if slices.Equal(cidrs, learned) {
+			continue
+		}
+		learned = cidrs
+		// only the latest routes matter to the reconcile loop
+		select {
+		case <-nx.learnedRoutesCh:
+		default:
+		}
+		nx.learnedRoutesCh <- cidrs
+	}
+}
+
+// notifyRouteChange signals a route change to runRouteLearning without blocking
+func notifyRouteChange(events chan<- struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
+
+// listFilteredRoutes lists the routes of the main routing table of the address families of the filters
+func (nx *Nexodus) listFilteredRoutes() ([]hostRoute, error) {
+	var routes []hostRoute
+	for _, family := range []string{"0.0.0.0/0", "::/0"} {
+		_, all, _ := net.ParseCIDR(family)
+		if !slices.ContainsFunc(nx.routeFilters, func(filter netip.Prefix) bool {
+			return filter.Addr().Is4() == (all.IP.To4() != nil)
+		}) {
+			continue
+		}
+		familyRoutes, err := listHostRoutes(all, mainRouteTable)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, familyRoutes...)
+	}
+	return routes, nil
+}
+
+// matchRoutes returns the sorted prefixes of the routes within the filters. The default routes are advertised by
+// exit nodes instead, and the routes of the tunnel interface are the ones of the peers.
+func matchRoutes(routes []hostRoute, filters []netip.Prefix, tunnelIface string) []string {
+	var prefixes []netip.Prefix
+	for _, r := range routes {
+		if r.Dev == tunnelIface {
+			continue
+		}
+		ones, _ := r.Dst.Mask.Size()
+		addr, ok := netip.AddrFromSlice(r.Dst.IP)
+		if !ok || ones == 0 {
+			continue
+		}
+		prefix := netip.PrefixFrom(addr.Unmap(), ones).Masked()
+		if !prefix.IsValid() || slices.Contains(prefixes, prefix) {
+			continue
+		}
+		if slices.ContainsFunc(filters, func(filter netip.Prefix) bool {
+			return filter.Bits() <= prefix.Bits() && filter.Contains(prefix.Addr())
+		}) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	slices.SortFunc(prefixes, func(a, b netip.Prefix) int {
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c
+		}
+		return a.Bits() - b.Bits()
+	})
+	cidrs := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		cidrs = append(cidrs, prefix.String())
+	}
+	return cidrs
+}
+
+// reconcileLearnedCidrs advertises the prefixes learned from the routing table and from the BGP peer along the
+// other advertised cidrs, and no longer advertises the ones that were withdrawn. The prefixes covered by another
+// advertised cidr are not advertised again. The update is retried by the next reconcile when it fails.
+func (nx *Nexodus) reconcileLearnedCidrs(ctx context.Context) {
+	learned := slices.Clone(nx.learnedRoutes)
+	if nx.bgp != nil {
+		learned = append(learned, nx.bgpImportedCidrs()...)
+	}
+
+	cidrs := slices.DeleteFunc(slices.Clone(nx.advertiseCidrs), func(cidr string) bool {
+		return slices.Contains(nx.learnedCidrs, cidr)
+	})
+	var added []string
+	for _, cidr := range learned {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil || slices.Contains(added, cidr) || slices.ContainsFunc(cidrs, func(advertised string) bool {
+			a, err := netip.ParsePrefix(advertised)
+			return err == nil && a.Bits() <= prefix.Bits() && a.Contains(prefix.Addr())
+		}) {
+			continue
+		}
+		added = append(added, cidr)
+	}
+	if slices.Equal(added, nx.learnedCidrs) {
+		return
+	}
+	cidrs = append(cidrs, added...)
+
+	fields := map[string]interface{}{"advertise_cidrs": cidrs}
+	if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(ctx, nx.deviceId, fields); err != nil {
+		nx.logger.Warnf("failed to advertise the learned prefixes: %v", err)
+		return
+	}
+	nx.logger.Infof("Advertising the learned prefixes: %v", added)
+	nx.advertiseCidrs = cidrs
+	nx.learnedCidrs = added
+	if nx.networkRouter {
+		if err := nx.setupNetworkRouterNode(); err != nil {
+			nx.logger.Errorf("failed to setup this device as a network router node: %v", err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package nexodus
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// watchRoutes notifies the changes of the main routing table other than the routes of the tunnel interface, as
+// reported by netlink
+func (nx *Nexodus) watchRoutes(ctx context.Context, events chan<- struct{}) error {
+	updates := make(chan netlink.RouteUpdate)
+	if err := netlink.RouteSubscribeWithOptions(updates, ctx.Done(), netlink.RouteSubscribeOptions{
+		ErrorCallback: func(err error) {
+			nx.logger.Debugf("netlink route subscription error: %v", err)
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to subscribe to the netlink route updates: %w", err)
+	}
+	for update := range updates {
+		if update.Table != unix.RT_TABLE_MAIN {
+			continue
+		}
+		if tunnel, err := net.InterfaceByName(nx.tunnelIface); err == nil && tunnel.Index == update.LinkIndex {
+			continue
+		}
+		notifyRouteChange(events)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package nexodus
+
+import (
+	"context"
+	"errors"
+)
+
+// watchRoutes is not supported, nexd router --advertise-routes is only supported on Linux
+func (nx *Nexodus) watchRoutes(ctx context.Context, events chan<- struct{}) error {
+	return errors.New("route change notifications are only supported on Linux")
+}
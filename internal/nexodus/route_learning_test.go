@@ -0,0 +1,37 @@
+package nexodus
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRoutes(t *testing.T) {
+	require := require.New(t)
+
+	route := func(dst, dev string) hostRoute {
+		_, ipNet, err := net.ParseCIDR(dst)
+		require.NoError(err)
+		return hostRoute{Dst: ipNet, Dev: dev}
+	}
+	routes := []hostRoute{
+		route("0.0.0.0/0", "eth0"),
+		route("10.20.0.0/16", "eth1"),
+		route("10.10.5.0/24", "eth1"),
+		route("10.10.5.0/24", "eth2"),
+		route("10.10.0.0/16", "eth1"),
+		route("10.30.0.0/16", "wg0"),
+		route("192.168.1.0/24", "eth0"),
+		route("fd00:10::/64", "eth1"),
+	}
+	filters := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/16")}
+
+	// the default route and the routes of the peers through the tunnel interface are not advertised
+	require.Equal([]string{"10.10.0.0/16", "10.10.5.0/24", "10.20.0.0/16", "fd00:10::/64"}, matchRoutes(routes, filters, "wg0"))
+
+	// the routes must be within a filter
+	require.Equal([]string{"10.10.5.0/24"}, matchRoutes(routes, []netip.Prefix{netip.MustParsePrefix("10.10.4.0/23")}, "wg0"))
+	require.Empty(matchRoutes(routes, nil, "wg0"))
+}
@@ -29,6 +29,9 @@ func (nx *Nexodus) handlePeerRouteOS(wgPeerConfig wgPeerConfig) error {
 			nx.logger.Warnf("%v", err)
 		}
 		if !routeExists {
+			if !nx.checkRouteConflicts(allowedIP, nx.tunnelIface) {
+				continue
+			}
 			if err := AddRoute(allowedIP, nx.tunnelIface); err != nil {
 				nx.logger.Errorf("route add failed: %v", err)
 				return err
@@ -3,16 +3,20 @@
 package nexodus
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 
 	"github.com/nexodus-io/nexodus/internal/api/public"
 	"github.com/nexodus-io/nexodus/internal/util"
+	"golang.org/x/sys/windows"
 )
 
 // handlePeerRoute when a new configuration is deployed, delete/add the peer allowedIPs
 func (nx *Nexodus) handlePeerRouteOS(wgPeerConfig wgPeerConfig) error {
-	// If advertised CIDR, split the two prefixes (host /32) and advertised CIDR
+	// routes added for this peer, removed again if any of the peer routes fail
+	var added []string
 	for _, allowedIP := range wgPeerConfig.AllowedIPs {
 		// if the peer is advertising a default route, append it as an exit origin node, but don't add the route
 		if util.IsDefaultIPv4Route(allowedIP) || util.IsDefaultIPv6Route(allowedIP) {
@@ -28,28 +32,27 @@ func (nx *Nexodus) handlePeerRouteOS(wgPeerConfig wgPeerConfig) error {
 		if err != nil {
 			nx.logger.Debugf("failed to check if route exists: %v", err)
 		}
-
-		if util.IsIPv4Prefix(allowedIP) {
-			if routeExists {
-				if err := DeleteRoute(allowedIP, wgIface); err != nil {
-					nx.logger.Debug(err)
-				}
-			}
-			if err := AddRoute(allowedIP, wgIface); err != nil {
-				nx.logger.Debug(err)
-			}
+		if routeExists {
+			continue
+		}
+		if !nx.checkRouteConflicts(allowedIP, wgIface) {
+			continue
 		}
 
+		addRoute := AddRoute
 		if util.IsIPv6Prefix(allowedIP) {
-			if routeExists {
-				if err := DeleteRouteV6(allowedIP, wgIface); err != nil {
-					nx.logger.Debug(err)
+			addRoute = AddRouteV6
+		}
+		if err := addRoute(allowedIP, wgIface); err != nil {
+			nx.logger.Errorf("route add failed: %v", err)
+			for _, prefix := range added {
+				if err := deleteRouteWinIPCfg(prefix, wgIface); err != nil {
+					nx.logger.Debugf("failed to roll back the route %s: %v", prefix, err)
 				}
 			}
-			if err := AddRouteV6(allowedIP, wgIface); err != nil {
-				nx.logger.Debug(err)
-			}
+			return err
 		}
+		added = append(added, allowedIP)
 	}
 
 	return nil
@@ -100,44 +103,77 @@ func findInterfaceForIPRoute(ipRoute string) (*net.Interface, error) {
 
 // AddRoute adds a windows route to the specified interface
 func AddRoute(prefix, dev string) error {
-	// netsh interface ip add route [prefix] [interface|*]
-	_, err := RunCommand("netsh", "interface", "ipv4", "add", "route", prefix, dev)
-	if err != nil {
+	if err := addRouteWinIPCfg(prefix, dev); err != nil {
 		return fmt.Errorf("no windows route added: %w", err)
 	}
-
 	return nil
 }
 
 // DeleteRoute deletes a windows route
 func DeleteRoute(prefix, dev string) error {
-	// netsh interface ip delete route [prefix] [interface|*]
-	_, err := RunCommand("netsh", "interface", "ipv4", "del", "route", prefix, dev)
-	if err != nil {
+	if err := deleteRouteWinIPCfg(prefix, dev); err != nil {
 		return fmt.Errorf("no route deleted: %w", err)
 	}
-
 	return nil
 }
 
-// AddRouteV6 adds a route to the specified interface using netsh in Windows
+// AddRouteV6 adds a v6 route to the specified interface
 func AddRouteV6(prefix, dev string) error {
-	// netsh interface ipv6 add route [prefix] [interface] [gateway] [metric]
-	_, err := RunCommand("netsh", "interface", "ipv6", "add", "route", prefix, dev, "::", "metric=256")
-	if err != nil {
+	if err := addRouteWinIPCfg(prefix, dev); err != nil {
 		return fmt.Errorf("v6 route add failed: %w", err)
 	}
-
 	return nil
 }
 
-// DeleteRouteV6 deletes a route from the specified interface using netsh in Windows
+// DeleteRouteV6 deletes a v6 route from the specified interface
 func DeleteRouteV6(prefix, dev string) error {
-	// netsh interface ipv6 delete route [prefix] [interface]
-	_, err := RunCommand("netsh", "interface", "ipv6", "delete", "route", prefix, dev)
-	if err != nil {
+	if err := deleteRouteWinIPCfg(prefix, dev); err != nil {
 		return fmt.Errorf("no route deleted: %w", err)
 	}
-
 	return nil
 }
+
+// addRouteWinIPCfg adds an on-link route for the prefix to the interface using the IP helper API
+func addRouteWinIPCfg(prefix, dev string) error {
+	destination, err := parseRoutePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	luid, err := interfaceLUID(dev)
+	if err != nil {
+		return err
+	}
+	err = luid.AddRoute(destination, unspecifiedNextHop(destination), 0)
+	if errors.Is(err, windows.ERROR_OBJECT_ALREADY_EXISTS) {
+		return nil
+	}
+	return err
+}
+
+// deleteRouteWinIPCfg removes the on-link route for the prefix from the interface using the IP helper API
+func deleteRouteWinIPCfg(prefix, dev string) error {
+	destination, err := parseRoutePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	luid, err := interfaceLUID(dev)
+	if err != nil {
+		return err
+	}
+	return luid.DeleteRoute(destination, unspecifiedNextHop(destination))
+}
+
+func parseRoutePrefix(prefix string) (netip.Prefix, error) {
+	destination, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("failed to parse a valid network address from %s: %w", prefix, err)
+	}
+	return destination.Masked(), nil
+}
+
+func unspecifiedNextHop(destination netip.Prefix) netip.Addr {
+	if destination.Addr().Is4() {
+		return netip.IPv4Unspecified()
+	}
+	return netip.IPv6Unspecified()
+}
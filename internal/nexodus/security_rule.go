@@ -0,0 +1,154 @@
+package nexodus
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+const (
+	ipProtocolICMPv4 = 1
+	ipProtocolTCP    = 6
+	ipProtocolUDP    = 17
+	ipProtocolICMPv6 = 58
+)
+
+// securityRule is a security group rule parsed for matching connections by the filters
+// that are not programmed with nftables or pf rules.
+type securityRule struct {
+	v4 []uint8 // the ip protocols matched for each address family, 0 matches any protocol
+	v6 []uint8
+	// the tcp and udp destination ports, a range of 0-0 matches any port
+	fromPort uint16
+	toPort   uint16
+	// the addresses of the remote end of the connection
+	anyAddress bool
+	v4Prefixes []netip.Prefix
+	v6Prefixes []netip.Prefix
+	v4Ranges   [][2]netip.Addr
+	v6Ranges   [][2]netip.Addr
+}
+
+// parseSecurityRule parses the protocol, port range and ip ranges of a security rule
+func parseSecurityRule(rule public.ModelsSecurityRule) (securityRule, error) {
+	r := securityRule{anyAddress: true}
+	if rule.FromPort < 0 || rule.ToPort < 0 || rule.FromPort > 65535 || rule.ToPort > 65535 {
+		return r, fmt.Errorf("invalid port range %d-%d", rule.FromPort, rule.ToPort)
+	}
+	r.fromPort, r.toPort = uint16(rule.FromPort), uint16(rule.ToPort)
+	if r.toPort == 0 {
+		r.toPort = r.fromPort
+	}
+	if r.toPort < r.fromPort {
+		return r, fmt.Errorf("invalid port range %d-%d", rule.FromPort, rule.ToPort)
+	}
+
+	switch rule.IpProtocol {
+	case "tcp":
+		r.v4, r.v6 = []uint8{ipProtocolTCP}, []uint8{ipProtocolTCP}
+	case "udp":
+		r.v4, r.v6 = []uint8{ipProtocolUDP}, []uint8{ipProtocolUDP}
+	case "icmpv4", "icmp4":
+		r.v4 = []uint8{ipProtocolICMPv4}
+	case "icmpv6", "icmp6":
+		r.v6 = []uint8{ipProtocolICMPv6}
+	case "icmp":
+		r.v4, r.v6 = []uint8{ipProtocolICMPv4}, []uint8{ipProtocolICMPv6}
+	case "ipv4", "ipv6":
+		protocols := []uint8{0}
+		if r.fromPort != 0 {
+			// only tcp and udp have ports
+			protocols = []uint8{ipProtocolTCP, ipProtocolUDP}
+		}
+		if rule.IpProtocol == "ipv4" {
+			r.v4 = protocols
+		} else {
+			r.v6 = protocols
+		}
+	default:
+		return r, fmt.Errorf("unsupported protocol %q", rule.IpProtocol)
+	}
+
+	for _, ipRange := range rule.IpRanges {
+		ipRange = strings.TrimSpace(ipRange)
+		if ipRange == "" {
+			continue
+		}
+		r.anyAddress = false
+		if from, to, found := strings.Cut(ipRange, "-"); found {
+			start, err := netip.ParseAddr(strings.TrimSpace(from))
+			if err != nil {
+				return r, fmt.Errorf("invalid ip range %q: %w", ipRange, err)
+			}
+			end, err := netip.ParseAddr(strings.TrimSpace(to))
+			if err != nil {
+				return r, fmt.Errorf("invalid ip range %q: %w", ipRange, err)
+			}
+			if start.Is4() != end.Is4() || end.Less(start) {
+				return r, fmt.Errorf("invalid ip range %q", ipRange)
+			}
+			if start.Is4() {
+				r.v4Ranges = append(r.v4Ranges, [2]netip.Addr{start, end})
+			} else {
+				r.v6Ranges = append(r.v6Ranges, [2]netip.Addr{start, end})
+			}
+			continue
+		}
+		var prefix netip.Prefix
+		if strings.Contains(ipRange, "/") {
+			p, err := netip.ParsePrefix(ipRange)
+			if err != nil {
+				return r, fmt.Errorf("invalid ip range %q: %w", ipRange, err)
+			}
+			prefix = p.Masked()
+		} else {
+			addr, err := netip.ParseAddr(ipRange)
+			if err != nil {
+				return r, fmt.Errorf("invalid ip range %q: %w", ipRange, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if prefix.Addr().Is4() {
+			r.v4Prefixes = append(r.v4Prefixes, prefix)
+		} else {
+			r.v6Prefixes = append(r.v6Prefixes, prefix)
+		}
+	}
+	return r, nil
+}
+
+// hasPorts returns whether the rule matches the destination port of the protocol
+func (r securityRule) hasPorts(protocol uint8) bool {
+	return r.fromPort != 0 && (protocol == ipProtocolTCP || protocol == ipProtocolUDP)
+}
+
+// matches returns whether a connection of the protocol to the destination port with the remote address is permitted by the rule
+func (r securityRule) matches(protocol uint8, remote netip.Addr, port uint16) bool {
+	protocols, prefixes, ranges := r.v4, r.v4Prefixes, r.v4Ranges
+	if !remote.Is4() {
+		protocols, prefixes, ranges = r.v6, r.v6Prefixes, r.v6Ranges
+	}
+	if !slices.Contains(protocols, 0) && !slices.Contains(protocols, protocol) {
+		return false
+	}
+	if r.hasPorts(protocol) && (port < r.fromPort || port > r.toPort) {
+		return false
+	}
+	if r.anyAddress {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if prefix.Contains(remote) {
+			return true
+		}
+	}
+	for _, addrs := range ranges {
+		if addrs[0].Compare(remote) <= 0 && remote.Compare(addrs[1]) <= 0 {
+			return true
+		}
+	}
+	return false
+}
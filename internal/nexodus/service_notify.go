@@ -0,0 +1,22 @@
+package nexodus
+
+import (
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// notifyReady tells systemd that nexd is ready when it runs as a Type=notify service, see nexd install,
+// nothing is sent when nexd was not started by systemd.
+func (nx *Nexodus) notifyReady() {
+	nx.notifyServiceManager(daemon.SdNotifyReady)
+}
+
+// notifyStopping tells systemd that nexd is shutting down
+func (nx *Nexodus) notifyStopping() {
+	nx.notifyServiceManager(daemon.SdNotifyStopping)
+}
+
+func (nx *Nexodus) notifyServiceManager(state string) {
+	if _, err := daemon.SdNotify(false, state); err != nil {
+		nx.logger.Debugf("failed to notify the service manager: %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package nexodus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.uber.org/zap"
+)
+
+const (
+	socksVersion5 = 0x05
+
+	socksAuthNone         = 0x00
+	socksAuthNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyHostUnreachable     = 0x04
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddrNotSupported    = 0x08
+
+	// the time a client has to send its SOCKS or HTTP request once it connected
+	overlayProxyHandshakeTimeout = 10 * time.Second
+)
+
+// overlayProxy is a SOCKS5 and HTTP proxy that dials the destinations of its clients through the userspace
+// netstack, so that tools that can not use a TUN interface reach the services of the Nexodus network. Both
+// protocols are served on the same listener, a SOCKS5 client is told apart by the version of its first byte.
+type overlayProxy struct {
+	logger *zap.SugaredLogger
+	dial   func(ctx context.Context, network, address string) (net.Conn, error)
+	// resolve returns the address of a host name, or the name itself for the dialer to resolve
+	resolve func(host string) string
+}
+
+// newOverlayProxy returns the proxy dialing through the userspace netstack, the names of the devices
+// in the overlay DNS zone are resolved to their tunnel addresses.
+func (nx *Nexodus) newOverlayProxy() *overlayProxy {
+	return &overlayProxy{
+		logger: nx.logger.With("proxy", "socks"),
+		dial:   nx.userspaceNet.DialContext,
+		resolve: func(host string) string {
+			if nx.overlayDNS != nil {
+				if ips := nx.overlayDNS.lookup(host); len(ips) > 0 {
+					return ips[0].String()
+				}
+			}
+			return host
+		},
+	}
+}
+
+// runSocksProxy serves the SOCKS5 and HTTP proxy on the --socks-listen address until the context is done
+func (nx *Nexodus) runSocksProxy(ctx context.Context, wg *sync.WaitGroup) error {
+	l, err := net.Listen("tcp", nx.socksListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on the socks proxy address %s: %w", nx.socksListen, err)
+	}
+	nx.logger.Infof("Proxying SOCKS5 and HTTP connections on %s into the Nexodus network", l.Addr())
+	util.GoWithWaitGroup(wg, func() {
+		<-ctx.Done()
+		util.IgnoreError(l.Close)
+	})
+	return nx.newOverlayProxy().serve(ctx, wg, l)
+}
+
+// serve handles the connections of the listener until it is closed
+func (p *overlayProxy) serve(ctx context.Context, wg *sync.WaitGroup, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		util.GoWithWaitGroup(wg, func() {
+			if err := p.handle(ctx, conn); err != nil {
+				p.logger.Debugf("Proxy connection from %s closed: %v", conn.RemoteAddr(), err)
+			}
+		})
+	}
+}
+
+func (p *overlayProxy) handle(ctx context.Context, conn net.Conn) error {
+	defer util.IgnoreError(conn.Close)
+	_ = conn.SetDeadline(time.Now().Add(overlayProxyHandshakeTimeout))
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	if err != nil {
+		return err
+	}
+	if first[0] == socksVersion5 {
+		return p.handleSocks(ctx, conn, reader)
+	}
+	return p.handleHTTP(ctx, conn, reader)
+}
+
+// handleSocks serves the CONNECT command of a SOCKS5 client without authentication, RFC 1928
+func (p *overlayProxy) handleSocks(ctx context.Context, conn net.Conn, reader *bufio.Reader) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return err
+	}
+	method := byte(socksAuthNoAcceptable)
+	for _, m := range methods {
+		if m == socksAuthNone {
+			method = socksAuthNone
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion5, method}); err != nil {
+		return err
+	}
+	if method == socksAuthNoAcceptable {
+		return fmt.Errorf("the socks client does not support connecting without authentication")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(reader, request); err != nil {
+		return err
+	}
+	if request[1] != socksCmdConnect {
+		_ = writeSocksReply(conn, socksReplyCommandNotSupported)
+		return fmt.Errorf("unsupported socks command %d", request[1])
+	}
+	var host string
+	switch request[3] {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if request[3] == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make([]byte, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return err
+		}
+		host = net.IP(ip).String()
+	case socksAddrDomain:
+		size, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		name := make([]byte, size)
+		if _, err := io.ReadFull(reader, name); err != nil {
+			return err
+		}
+		host = p.resolve(string(name))
+	default:
+		_ = writeSocksReply(conn, socksReplyAddrNotSupported)
+		return fmt.Errorf("unsupported socks address type %d", request[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return err
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+
+	outConn, err := p.dial(ctx, "tcp", address)
+	if err != nil {
+		_ = writeSocksReply(conn, socksReplyHostUnreachable)
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer util.IgnoreError(outConn.Close)
+	if err := writeSocksReply(conn, socksReplySucceeded); err != nil {
+		return err
+	}
+	p.logger.Debugf("Proxying SOCKS connection from %s to %s", conn.RemoteAddr(), address)
+	_ = conn.SetDeadline(time.Time{})
+	return pipeConns(conn, reader, outConn)
+}
+
+// writeSocksReply writes a reply without a bound address, clients connecting with CONNECT do not use it
+func writeSocksReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion5, reply, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// handleHTTP serves an HTTP CONNECT request, or forwards a plain HTTP request with an absolute URL
+func (p *overlayProxy) handleHTTP(ctx context.Context, conn net.Conn, reader *bufio.Reader) error {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return err
+	}
+	if req.Host == "" || (req.Method != http.MethodConnect && !req.URL.IsAbs()) {
+		_, _ = io.WriteString(conn, "HTTP/1.1 400 Bad Request\r\nConnection: close\r\n\r\n")
+		return fmt.Errorf("the http request to %s is not a proxy request", req.URL)
+	}
+	host, port, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host, port = req.Host, "80"
+		if req.Method == http.MethodConnect || req.URL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	address := net.JoinHostPort(p.resolve(host), port)
+
+	outConn, err := p.dial(ctx, "tcp", address)
+	if err != nil {
+		_, _ = io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\nConnection: close\r\n\r\n")
+		return fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer util.IgnoreError(outConn.Close)
+	_ = conn.SetDeadline(time.Time{})
+
+	if req.Method == http.MethodConnect {
+		if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+			return err
+		}
+		p.logger.Debugf("Proxying HTTP CONNECT from %s to %s", conn.RemoteAddr(), address)
+		return pipeConns(conn, reader, outConn)
+	}
+
+	// a single request is forwarded per connection
+	req.Header.Del("Proxy-Connection")
+	req.Header.Del("Proxy-Authorization")
+	req.Header.Set("Connection", "close")
+	req.Close = true
+	if err := req.Write(outConn); err != nil {
+		return err
+	}
+	p.logger.Debugf("Proxying HTTP request from %s to %s", conn.RemoteAddr(), address)
+	_, err = io.Copy(conn, outConn)
+	return err
+}
+
+// pipeConns copies between the client, read through its buffered reader, and the destination until either
+// side closes the connection
+func pipeConns(conn net.Conn, reader io.Reader, outConn net.Conn) error {
+	done := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(outConn, reader)
+		done <- err
+	}()
+	go func() {
+		_, err := io.Copy(conn, outConn)
+		done <- err
+	}()
+	err := <-done
+	// unblock the other copy
+	util.IgnoreError(conn.Close)
+	util.IgnoreError(outConn.Close)
+	<-done
+	return err
+}
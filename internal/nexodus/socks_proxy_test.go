@@ -0,0 +1,163 @@
+package nexodus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// startOverlayProxy starts the proxy dialing with the host network stack, returning its address
+func startOverlayProxy(t *testing.T, resolve func(string) string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	p := &overlayProxy{
+		logger:  zap.NewNop().Sugar(),
+		dial:    (&net.Dialer{}).DialContext,
+		resolve: resolve,
+	}
+	go func() {
+		_ = p.serve(ctx, wg, l)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		_ = l.Close()
+	})
+	return l.Addr().String()
+}
+
+// startEchoServer returns the address of a server echoing the lines it receives
+func startEchoServer(t *testing.T) *net.TCPAddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr)
+}
+
+func TestOverlayProxySocks(t *testing.T) {
+	require := require.New(t)
+	echo := startEchoServer(t)
+	proxyAddr := startOverlayProxy(t, func(host string) string {
+		if host == "echo.nexodus.local" {
+			return "127.0.0.1"
+		}
+		return host
+	})
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	require.NoError(err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	_, err = conn.Write([]byte{socksVersion5, 1, socksAuthNone})
+	require.NoError(err)
+	reply := make([]byte, 2)
+	_, err = io.ReadFull(reader, reply)
+	require.NoError(err)
+	require.Equal([]byte{socksVersion5, socksAuthNone}, reply)
+
+	// the names of the devices are resolved by the proxy
+	name := "echo.nexodus.local"
+	request := []byte{socksVersion5, socksCmdConnect, 0, socksAddrDomain, byte(len(name))}
+	request = append(request, name...)
+	request = binary.BigEndian.AppendUint16(request, uint16(echo.Port))
+	_, err = conn.Write(request)
+	require.NoError(err)
+	reply = make([]byte, 10)
+	_, err = io.ReadFull(reader, reply)
+	require.NoError(err)
+	require.Equal(byte(socksReplySucceeded), reply[1])
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(err)
+	line, err := reader.ReadString('\n')
+	require.NoError(err)
+	require.Equal("hello\n", line)
+}
+
+func TestOverlayProxySocksUnsupportedCommand(t *testing.T) {
+	require := require.New(t)
+	proxyAddr := startOverlayProxy(t, func(host string) string { return host })
+
+	conn, err := net.Dial("tcp", proxyAddr)
+	require.NoError(err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	_, err = conn.Write([]byte{socksVersion5, 1, socksAuthNone})
+	require.NoError(err)
+	_, err = io.ReadFull(reader, make([]byte, 2))
+	require.NoError(err)
+
+	// BIND is not supported
+	_, err = conn.Write([]byte{socksVersion5, 0x02, 0, socksAddrIPv4, 127, 0, 0, 1, 0, 80})
+	require.NoError(err)
+	reply := make([]byte, 10)
+	_, err = io.ReadFull(reader, reply)
+	require.NoError(err)
+	require.Equal(byte(socksReplyCommandNotSupported), reply[1])
+}
+
+func TestOverlayProxyHTTP(t *testing.T) {
+	require := require.New(t)
+	echo := startEchoServer(t)
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "path %s", r.URL.Path)
+	})}
+	go func() { _ = server.Serve(backend) }()
+	defer server.Close()
+	proxyAddr := startOverlayProxy(t, func(host string) string { return host })
+
+	// a plain request is forwarded
+	conn, err := net.Dial("tcp", proxyAddr)
+	require.NoError(err)
+	defer conn.Close()
+	_, err = fmt.Fprintf(conn, "GET http://%s/test HTTP/1.1\r\nHost: %s\r\n\r\n", backend.Addr(), backend.Addr())
+	require.NoError(err)
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(err)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal("path /test", string(body))
+
+	// CONNECT tunnels the connection
+	conn, err = net.Dial("tcp", proxyAddr)
+	require.NoError(err)
+	defer conn.Close()
+	_, err = fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echo, echo)
+	require.NoError(err)
+	reader := bufio.NewReader(conn)
+	resp, err = http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	require.NoError(err)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(err)
+	line, err := reader.ReadString('\n')
+	require.NoError(err)
+	require.Equal("hello\n", line)
+}
@@ -0,0 +1,19 @@
+package nexodus
+
+// supportBundleCommands are the commands whose output is included in the support bundle
+func supportBundleCommands(iface string) []supportCommand {
+	return []supportCommand{
+		{file: "wg-show.txt", args: []string{"wg", "show", iface}},
+		{file: "addresses.txt", args: []string{"ifconfig"}},
+		{file: "routes.txt", args: []string{"netstat", "-rn"}},
+		{file: "pf-rules.txt", args: []string{"pfctl", "-a", "io.nexodus", "-sr"}},
+	}
+}
+
+// supportBundleLogFiles are the log files of the launchd service included in the support bundle keyed by file name
+func supportBundleLogFiles() map[string]string {
+	return map[string]string{
+		"nexd-stdout.log": "/var/log/nexd-stdout.log",
+		"nexd-stderr.log": "/var/log/nexd-stderr.log",
+	}
+}
@@ -0,0 +1,21 @@
+package nexodus
+
+import "fmt"
+
+// supportBundleCommands are the commands whose output is included in the support bundle
+func supportBundleCommands(iface string) []supportCommand {
+	return []supportCommand{
+		{file: "wg-show.txt", args: []string{"wg", "show", iface}},
+		{file: "addresses.txt", args: []string{"ip", "address", "show"}},
+		{file: "routes-ipv4.txt", args: []string{"ip", "-4", "route", "show", "table", "all"}},
+		{file: "routes-ipv6.txt", args: []string{"ip", "-6", "route", "show", "table", "all"}},
+		{file: "nft-ruleset.txt", args: []string{"nft", "list", "ruleset"}},
+		{file: "nexd.log", args: []string{"journalctl", "--unit", "nexodus", "--lines", fmt.Sprint(supportLogLines), "--no-pager"}},
+	}
+}
+
+// supportBundleLogFiles are the log files included in the support bundle keyed by file name, the logs of the
+// systemd service are read from the journal
+func supportBundleLogFiles() map[string]string {
+	return nil
+}
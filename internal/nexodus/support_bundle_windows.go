@@ -0,0 +1,21 @@
+package nexodus
+
+import "fmt"
+
+// supportBundleCommands are the commands whose output is included in the support bundle
+func supportBundleCommands(iface string) []supportCommand {
+	return []supportCommand{
+		{file: "wg-show.txt", args: []string{"wg", "show", iface}},
+		{file: "addresses.txt", args: []string{"ipconfig", "/all"}},
+		{file: "routes.txt", args: []string{"route", "print"}},
+		{file: "firewall-rules.txt", args: []string{"netsh", "wfp", "show", "filters", "file=-"}},
+		{file: "nexd.log", args: []string{"wevtutil", "qe", "Application", "/q:*[System[Provider[@Name='nexd']]]",
+			fmt.Sprintf("/c:%d", supportLogLines), "/rd:true", "/f:text"}},
+	}
+}
+
+// supportBundleLogFiles are the log files included in the support bundle keyed by file name, the logs of the
+// windows service are read from the event log
+func supportBundleLogFiles() map[string]string {
+	return nil
+}
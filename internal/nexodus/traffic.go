@@ -0,0 +1,177 @@
+package nexodus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+)
+
+const (
+	// how often the wireguard counters of the peers are sampled for their traffic rates
+	peerTrafficSampleInterval = 30 * time.Second
+	// how often the traffic of the peers is reported to the api-server
+	peerTrafficReportInterval = 5 * time.Minute
+	// the metadata key under which devices report the traffic exchanged with their peers
+	peerTrafficMetadataKey = "peer-traffic"
+)
+
+// peerTraffic is the traffic exchanged with a peer
+type peerTraffic struct {
+	// the wireguard counters at the last sample, they start over when the peer is re-created
+	rxBytes int64
+	txBytes int64
+	// the bytes per second received and sent between the last two samples
+	rxRate float64
+	txRate float64
+	// the bytes received and sent since the last report to the api-server
+	rxReported int64
+	txReported int64
+}
+
+// trafficAccounting is the traffic exchanged with the peers, by public key
+type trafficAccounting struct {
+	lock       sync.Mutex
+	peers      map[string]peerTraffic
+	sampleTime time.Time
+	reportTime time.Time
+}
+
+// trafficDelta returns the bytes counted since the previous sample of a wireguard counter
+func trafficDelta(current, previous int64) int64 {
+	if current < previous {
+		// the counter started over, the peer was re-created since the previous sample
+		return current
+	}
+	return current - previous
+}
+
+// runTrafficAccounting periodically samples the wireguard counters of the peers, and reports the traffic exchanged
+// with them to the api-server when --report-peer-traffic is set.
+func (nx *Nexodus) runTrafficAccounting(ctx context.Context) {
+	sampleTicker := time.NewTicker(peerTrafficSampleInterval)
+	defer sampleTicker.Stop()
+	var reportTimer *time.Timer
+	var report <-chan time.Time
+	if nx.reportPeerTraffic {
+		reportTimer = time.NewTimer(util.Jitter(peerTrafficReportInterval))
+		defer reportTimer.Stop()
+		report = reportTimer.C
+	}
+	nx.sampleTraffic(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sampleTicker.C:
+			nx.sampleTraffic(time.Now())
+		case <-report:
+			if err := nx.updatePeerTrafficMetadata(time.Now()); err != nil {
+				nx.logger.Debugf("failed to report the traffic of the peers: %v", err)
+			}
+			reportTimer.Reset(util.Jitter(peerTrafficReportInterval))
+		}
+	}
+}
+
+// sampleTraffic updates the traffic of the peers from their wireguard counters
+func (nx *Nexodus) sampleTraffic(now time.Time) {
+	sessions, err := nx.DumpPeersDefault()
+	if err != nil {
+		nx.logger.Debugf("failed to get the peer stats for the traffic accounting: %v", err)
+		return
+	}
+	nx.traffic.update(sessions, now)
+}
+
+// update accounts the wireguard counters of the peers sampled at now
+func (ta *trafficAccounting) update(sessions map[string]WgSessions, now time.Time) {
+	ta.lock.Lock()
+	defer ta.lock.Unlock()
+	elapsed := now.Sub(ta.sampleTime).Seconds()
+	peers := make(map[string]peerTraffic, len(sessions))
+	for key, s := range sessions {
+		last, ok := ta.peers[key]
+		t := peerTraffic{
+			rxBytes:    s.Rx,
+			txBytes:    s.Tx,
+			rxReported: s.Rx,
+			txReported: s.Tx,
+		}
+		if ok {
+			rx, tx := trafficDelta(s.Rx, last.rxBytes), trafficDelta(s.Tx, last.txBytes)
+			t.rxReported, t.txReported = last.rxReported+rx, last.txReported+tx
+			if elapsed > 0 {
+				t.rxRate, t.txRate = float64(rx)/elapsed, float64(tx)/elapsed
+			}
+		}
+		peers[key] = t
+	}
+	// the traffic of removed peers is no longer reported
+	ta.peers = peers
+	ta.sampleTime = now
+	if ta.reportTime.IsZero() {
+		ta.reportTime = now
+	}
+}
+
+// peerTrafficRates returns the bytes per second received from and sent to a peer
+func (nx *Nexodus) peerTrafficRates(publicKey string) (float64, float64) {
+	nx.traffic.lock.Lock()
+	defer nx.traffic.lock.Unlock()
+	t := nx.traffic.peers[publicKey]
+	return t.rxRate, t.txRate
+}
+
+// updatePeerTrafficMetadata reports the bytes exchanged with every peer since the last report, keyed by peer device id
+func (nx *Nexodus) updatePeerTrafficMetadata(now time.Time) error {
+	if nx.deviceId == "" {
+		return nil
+	}
+	nx.sampleTraffic(now)
+
+	nx.traffic.lock.Lock()
+	traffic := make(map[string]peerTraffic, len(nx.traffic.peers))
+	for key, t := range nx.traffic.peers {
+		traffic[key] = t
+	}
+	lastReport := nx.traffic.reportTime
+	nx.traffic.lock.Unlock()
+
+	elapsed := now.Sub(lastReport).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+	peers := map[string]interface{}{}
+	nx.deviceCacheIterRead(func(d deviceCacheEntry) {
+		t, ok := traffic[d.device.PublicKey]
+		if !ok || d.device.PublicKey == nx.wireguardPubKey {
+			return
+		}
+		peers[d.device.Id] = map[string]interface{}{
+			"hostname": d.device.Hostname,
+			"rx_bytes": t.rxReported,
+			"tx_bytes": t.txReported,
+			"rx_rate":  float64(t.rxReported) / elapsed,
+			"tx_rate":  float64(t.txReported) / elapsed,
+		}
+	})
+	_, _, err := nx.client.DevicesApi.UpdateDeviceMetadataKey(context.Background(), nx.deviceId, peerTrafficMetadataKey).Value(peers).Execute()
+	if err != nil {
+		return err
+	}
+
+	// the next report starts counting from this one
+	nx.traffic.lock.Lock()
+	for key, t := range nx.traffic.peers {
+		if reported, ok := traffic[key]; ok {
+			t.rxReported -= reported.rxReported
+			t.txReported -= reported.txReported
+			nx.traffic.peers[key] = t
+		}
+	}
+	nx.traffic.reportTime = now
+	nx.traffic.lock.Unlock()
+	return nil
+}
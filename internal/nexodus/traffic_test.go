@@ -0,0 +1,35 @@
+package nexodus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficAccounting(t *testing.T) {
+	require := require.New(t)
+	now := time.Now()
+	ta := trafficAccounting{}
+
+	ta.update(map[string]WgSessions{"peer": {Rx: 1000, Tx: 500}}, now)
+	require.Equal(int64(1000), ta.peers["peer"].rxReported)
+	require.Zero(ta.peers["peer"].rxRate)
+
+	now = now.Add(10 * time.Second)
+	ta.update(map[string]WgSessions{"peer": {Rx: 3000, Tx: 1500}}, now)
+	require.Equal(200.0, ta.peers["peer"].rxRate)
+	require.Equal(100.0, ta.peers["peer"].txRate)
+	require.Equal(int64(3000), ta.peers["peer"].rxReported)
+
+	// the peer was re-created and its counters started over
+	now = now.Add(10 * time.Second)
+	ta.update(map[string]WgSessions{"peer": {Rx: 500, Tx: 0}}, now)
+	require.Equal(50.0, ta.peers["peer"].rxRate)
+	require.Equal(int64(3500), ta.peers["peer"].rxReported)
+	require.Equal(int64(1500), ta.peers["peer"].txReported)
+
+	// removed peers are dropped
+	ta.update(map[string]WgSessions{}, now.Add(10*time.Second))
+	require.Empty(ta.peers)
+}
@@ -0,0 +1,48 @@
+package nexodus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nexodus-io/nexodus/internal/stun"
+)
+
+// noteUDPBlocked is called when none of the STUN servers answered. UDP is likely blocked on this network, so a relay
+// is required and the peers are reached through the DERP relay, which carries the WireGuard packets over TCP port 443.
+func (nx *Nexodus) noteUDPBlocked() {
+	if nx.relay || nx.relayDerp {
+		return
+	}
+	nx.udpBlocked = true
+	nx.symmetricNat = true
+	nx.logger.Warn("The STUN servers are unreachable, UDP appears to be blocked on this network. Peers are reached through the DERP relay over TCP port 443")
+}
+
+// checkUDPUnblocked checks if the STUN servers are reachable again once UDP was found blocked, in which case the
+// device no longer requires a relay.
+func (nx *Nexodus) checkUDPUnblocked(deviceID string) error {
+	if _, err := stun.Request(nx.logger, stun.NextServer(), nx.listenPort); err != nil {
+		// still blocked
+		return nil
+	}
+
+	// a relay is still required if symmetric NAT was detected
+	symmetricNat := nx.relayOnly || nx.symmetricNatDetected
+	if _, _, err := nx.client.DevicesApi.UpdateDeviceFields(context.Background(), deviceID, map[string]interface{}{
+		"symmetric_nat": symmetricNat,
+	}); err != nil {
+		return fmt.Errorf("failed to update the device once UDP is no longer blocked: %w", err)
+	}
+	nx.udpBlocked = false
+	nx.symmetricNat = symmetricNat
+	nx.logger.Info("The STUN servers are reachable again, UDP is no longer blocked on this network")
+	return nil
+}
+
+// udpBlockedStatus renders the relay transport for the nexd status output when UDP is blocked
+func (nx *Nexodus) udpBlockedStatus() string {
+	if !nx.udpBlocked {
+		return ""
+	}
+	return "Relay Transport: TCP/443 (UDP is blocked on this network)\n"
+}
@@ -0,0 +1,283 @@
+package nexodus
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/client"
+	"github.com/nexodus-io/nexodus/internal/util"
+	"go.uber.org/zap"
+)
+
+const (
+	updateCheckInterval = time.Hour
+	updateMarkerFile    = "update.json"
+	maxUpdateAttempts   = 3
+	maxAgentReleaseSize = 512 * 1024 * 1024
+)
+
+// updateMarker records a staged agent update so that a binary that repeatedly fails to start can be rolled back
+type updateMarker struct {
+	Version         string `json:"version"`
+	PreviousVersion string `json:"previous_version"`
+	Binary          string `json:"binary"`
+	Backup          string `json:"backup"`
+	Attempts        int    `json:"attempts"`
+	Pending         bool   `json:"pending"`
+	FailedVersion   string `json:"failed_version,omitempty"`
+}
+
+// ParseUpdatePublicKey decodes the base64 encoded ed25519 public key used to verify agent releases
+func ParseUpdatePublicKey(value string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update public key: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid update public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
+// verifyAgentRelease checks that the downloaded binary matches the digest and signature of the release
+func verifyAgentRelease(data []byte, release public.ModelsAgentRelease, publicKey ed25519.PublicKey) error {
+	digest := sha256.Sum256(data)
+	if hex.EncodeToString(digest[:]) != release.Sha256 {
+		return fmt.Errorf("sha256 digest of release %s does not match", release.Version)
+	}
+	signature, err := base64.StdEncoding.DecodeString(release.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature for release %s: %w", release.Version, err)
+	}
+	if !ed25519.Verify(publicKey, data, signature) {
+		return fmt.Errorf("signature verification of release %s failed", release.Version)
+	}
+	return nil
+}
+
+// RestartRequested is closed once a verified update has been staged and nexd should be restarted
+func (nx *Nexodus) RestartRequested() <-chan struct{} {
+	return nx.restartCh
+}
+
+func (nx *Nexodus) runUpdater(ctx context.Context) {
+	if runtime.GOOS == Windows.String() {
+		nx.logger.Info("Automatic updates are currently only supported on Linux and macOS")
+		return
+	}
+	timer := time.NewTimer(util.Jitter(updateCheckInterval))
+	defer timer.Stop()
+	for {
+		staged, err := nx.checkForUpdate(ctx)
+		if err != nil {
+			nx.logger.Warnf("agent update check failed: %v", err)
+		}
+		if staged {
+			close(nx.restartCh)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(util.Jitter(updateCheckInterval))
+		}
+	}
+}
+
+// checkForUpdate fetches the release advertised on the update channel of the organization and
+// stages it in place of the running binary once the release has been verified.
+func (nx *Nexodus) checkForUpdate(ctx context.Context) (bool, error) {
+	release, httpResp, err := nx.client.OrganizationsApi.GetAgentRelease(ctx, nx.vpc.OrganizationId).
+		Os(runtime.GOOS).
+		Arch(runtime.GOARCH).
+		Execute()
+	if err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			nx.logger.Debugf("no agent release is available for %s/%s", runtime.GOOS, runtime.GOARCH)
+			return false, nil
+		}
+		return false, err
+	}
+	if release.Version == nx.version {
+		return false, nil
+	}
+
+	marker, err := readUpdateMarker(nx.stateDir)
+	if err != nil {
+		return false, err
+	}
+	if marker.FailedVersion == release.Version {
+		nx.logger.Debugf("skipping agent release %s, it was previously rolled back", release.Version)
+		return false, nil
+	}
+
+	nx.logger.Infof("Updating nexd from %s to %s", nx.version, release.Version)
+	data, err := downloadAgentRelease(ctx, nx.proxyURL, release.Url)
+	if err != nil {
+		return false, err
+	}
+	if err := verifyAgentRelease(data, *release, nx.updatePublicKey); err != nil {
+		return false, err
+	}
+
+	binary, err := executablePath()
+	if err != nil {
+		return false, err
+	}
+	backup, err := stageUpdate(binary, data)
+	if err != nil {
+		return false, err
+	}
+	err = writeUpdateMarker(nx.stateDir, updateMarker{
+		Version:         release.Version,
+		PreviousVersion: nx.version,
+		Binary:          binary,
+		Backup:          backup,
+		Pending:         true,
+	})
+	if err != nil {
+		// without the marker a broken release could not be rolled back
+		if rollbackErr := os.Rename(backup, binary); rollbackErr != nil {
+			nx.logger.Errorf("failed to restore %s: %v", binary, rollbackErr)
+		}
+		return false, err
+	}
+	nx.logger.Infof("Staged nexd %s, restarting", release.Version)
+	return true, nil
+}
+
+func downloadAgentRelease(ctx context.Context, proxyURL *url.URL, releaseURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the release is downloaded through the proxy of the requests to the service
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = client.ProxyFunc(proxyURL)
+	httpClient := &http.Client{Transport: transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", releaseURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxAgentReleaseSize))
+}
+
+func executablePath() (string, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(binary)
+}
+
+// stageUpdate swaps the binary for the new release and keeps the previous binary as a backup
+func stageUpdate(binary string, data []byte) (string, error) {
+	staged := binary + ".new"
+	backup := binary + ".old"
+	// #nosec G306
+	if err := os.WriteFile(staged, data, 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(binary, backup); err != nil {
+		_ = os.Remove(staged)
+		return "", err
+	}
+	if err := os.Rename(staged, binary); err != nil {
+		_ = os.Rename(backup, binary)
+		return "", err
+	}
+	return backup, nil
+}
+
+func readUpdateMarker(stateDir string) (updateMarker, error) {
+	marker := updateMarker{}
+	data, err := os.ReadFile(filepath.Join(stateDir, updateMarkerFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return marker, nil
+		}
+		return marker, err
+	}
+	err = json.Unmarshal(data, &marker)
+	return marker, err
+}
+
+func writeUpdateMarker(stateDir string, marker updateMarker) error {
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir, updateMarkerFile), data, 0600)
+}
+
+// recoverFromUpdate counts the start attempts of a staged update and restores the previous
+// binary once the update has failed to start too many times. It returns true if the previous
+// binary was restored and needs to be started.
+func recoverFromUpdate(logger *zap.SugaredLogger, stateDir string) (bool, error) {
+	marker, err := readUpdateMarker(stateDir)
+	if err != nil || !marker.Pending {
+		return false, err
+	}
+	marker.Attempts++
+	if marker.Attempts <= maxUpdateAttempts {
+		return false, writeUpdateMarker(stateDir, marker)
+	}
+
+	logger.Warnf("nexd %s failed to start after %d attempts, rolling back to %s", marker.Version, maxUpdateAttempts, marker.PreviousVersion)
+	if err := os.Rename(marker.Backup, marker.Binary); err != nil {
+		return false, fmt.Errorf("failed to restore %s: %w", marker.Binary, err)
+	}
+	err = writeUpdateMarker(stateDir, updateMarker{
+		FailedVersion: marker.Version,
+	})
+	return true, err
+}
+
+// RecoverFromUpdate rolls back a staged update that has repeatedly failed to start, and restarts
+// nexd using the previous binary.
+func RecoverFromUpdate(logger *zap.SugaredLogger, stateDir string) error {
+	restart, err := recoverFromUpdate(logger, stateDir)
+	if err != nil || !restart {
+		return err
+	}
+	return RestartAgent()
+}
+
+// confirmUpdate marks a staged update as good once the agent has successfully joined its vpc
+func (nx *Nexodus) confirmUpdate() {
+	marker, err := readUpdateMarker(nx.stateDir)
+	if err != nil {
+		nx.logger.Warnf("failed to read the update marker: %v", err)
+		return
+	}
+	if !marker.Pending || marker.Version != nx.version {
+		return
+	}
+	if err := os.Remove(marker.Backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		nx.logger.Warnf("failed to remove %s: %v", marker.Backup, err)
+	}
+	if err := writeUpdateMarker(nx.stateDir, updateMarker{}); err != nil {
+		nx.logger.Warnf("failed to write the update marker: %v", err)
+		return
+	}
+	nx.logger.Infof("Confirmed update from nexd %s to %s", marker.PreviousVersion, marker.Version)
+}
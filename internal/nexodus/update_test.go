@@ -0,0 +1,73 @@
+package nexodus
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestVerifyAgentRelease(t *testing.T) {
+	require := require.New(t)
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+
+	data := []byte("nexd")
+	digest := sha256.Sum256(data)
+	release := public.ModelsAgentRelease{
+		Version:   "v0.1.0",
+		Sha256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)),
+	}
+	require.NoError(verifyAgentRelease(data, release, publicKey))
+	require.Error(verifyAgentRelease([]byte("tampered"), release, publicKey))
+
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(err)
+	require.Error(verifyAgentRelease(data, release, otherKey))
+}
+
+func TestRecoverFromUpdate(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "nexd")
+	require.NoError(os.WriteFile(binary, []byte("old"), 0600))
+
+	backup, err := stageUpdate(binary, []byte("new"))
+	require.NoError(err)
+	require.NoError(writeUpdateMarker(dir, updateMarker{
+		Version:         "v0.2.0",
+		PreviousVersion: "v0.1.0",
+		Binary:          binary,
+		Backup:          backup,
+		Pending:         true,
+	}))
+
+	logger := zap.NewNop().Sugar()
+	for i := 0; i < maxUpdateAttempts; i++ {
+		restart, err := recoverFromUpdate(logger, dir)
+		require.NoError(err)
+		require.False(restart)
+	}
+	restart, err := recoverFromUpdate(logger, dir)
+	require.NoError(err)
+	require.True(restart)
+
+	data, err := os.ReadFile(binary)
+	require.NoError(err)
+	require.Equal("old", string(data))
+
+	marker, err := readUpdateMarker(dir)
+	require.NoError(err)
+	require.False(marker.Pending)
+	require.Equal("v0.2.0", marker.FailedVersion)
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin
+
+package nexodus
+
+import (
+	"os"
+	"syscall"
+)
+
+// RestartAgent replaces the running process with the nexd binary currently installed on disk
+func RestartAgent() error {
+	binary, err := executablePath()
+	if err != nil {
+		return err
+	}
+	// #nosec G204
+	return syscall.Exec(binary, os.Args, os.Environ())
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package nexodus
+
+import "errors"
+
+// RestartAgent replaces the running process with the nexd binary currently installed on disk
+func RestartAgent() error {
+	return errors.New("automatic updates are not supported on windows")
+}
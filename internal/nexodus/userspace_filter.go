@@ -0,0 +1,234 @@
+package nexodus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+const (
+	// flowTimeout is how long the packets of a permitted connection are permitted in both directions after its last packet
+	flowTimeout = 5 * time.Minute
+	// flowSweepInterval is how often expired connections are removed
+	flowSweepInterval = time.Minute
+)
+
+// flowKey identifies a connection by its local and remote address and port
+type flowKey struct {
+	protocol   uint8
+	local      netip.Addr
+	remote     netip.Addr
+	localPort  uint16
+	remotePort uint16
+}
+
+// packetFilter applies the rules of the security group to the packets of the userspace tunnel device. Packets
+// read from the device are sent by the netstack to the peers and are matched by the outbound rules, packets written
+// to the device are received from the peers and are matched by the inbound rules. Like the nftables ruleset, the
+// replies of permitted connections are permitted and a direction without rules permits all packets.
+type packetFilter struct {
+	tun.Device
+	now func() time.Time
+
+	lock     sync.RWMutex
+	inbound  []securityRule
+	outbound []securityRule
+
+	flowsLock sync.Mutex
+	flows     map[flowKey]time.Time
+	lastSweep time.Time
+}
+
+func newPacketFilter(dev tun.Device) *packetFilter {
+	return &packetFilter{
+		Device: dev,
+		now:    time.Now,
+		flows:  map[flowKey]time.Time{},
+	}
+}
+
+// update replaces the rules of the filter with the rules of the security group, a nil group permits all packets
+func (f *packetFilter) update(group *public.ModelsSecurityGroup, ipv6Supported bool) error {
+	var inbound, outbound []securityRule
+	if group != nil {
+		inboundRules, outboundRules := group.InboundRules, group.OutboundRules
+		if !ipv6Supported {
+			inboundRules = ipv4SecurityRules(inboundRules)
+			outboundRules = ipv4SecurityRules(outboundRules)
+		}
+		for _, rule := range inboundRules {
+			r, err := parseSecurityRule(rule)
+			if err != nil {
+				return fmt.Errorf("userspace filter setup error, failed to process inbound rule: %w", err)
+			}
+			inbound = append(inbound, r)
+		}
+		for _, rule := range outboundRules {
+			r, err := parseSecurityRule(rule)
+			if err != nil {
+				return fmt.Errorf("userspace filter setup error, failed to process outbound rule: %w", err)
+			}
+			outbound = append(outbound, r)
+		}
+		// a direction is only filtered if there are any user defined rules, even if none apply to the host
+		if len(group.InboundRules) != 0 && inbound == nil {
+			inbound = []securityRule{}
+		}
+		if len(group.OutboundRules) != 0 && outbound == nil {
+			outbound = []securityRule{}
+		}
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.inbound, f.outbound = inbound, outbound
+	return nil
+}
+
+// Read reads the packets sent to the peers and drops the packets that are not permitted
+func (f *packetFilter) Read(bufs [][]byte, sizes []int, offset int) (int, error) {
+	n, err := f.Device.Read(bufs, sizes, offset)
+	kept := 0
+	for i := 0; i < n; i++ {
+		if !f.allow(bufs[i][offset:offset+sizes[i]], false) {
+			continue
+		}
+		if kept != i {
+			copy(bufs[kept][offset:], bufs[i][offset:offset+sizes[i]])
+			sizes[kept] = sizes[i]
+		}
+		kept++
+	}
+	return kept, err
+}
+
+// Write writes the packets received from the peers and drops the packets that are not permitted
+func (f *packetFilter) Write(bufs [][]byte, offset int) (int, error) {
+	permitted := make([][]byte, 0, len(bufs))
+	for _, buf := range bufs {
+		if f.allow(buf[offset:], true) {
+			permitted = append(permitted, buf)
+		}
+	}
+	if len(permitted) == 0 {
+		return len(bufs), nil
+	}
+	if _, err := f.Device.Write(permitted, offset); err != nil {
+		return 0, err
+	}
+	return len(bufs), nil
+}
+
+// allow returns whether a packet is permitted by the rules of its direction or belongs to a permitted connection
+func (f *packetFilter) allow(packet []byte, inbound bool) bool {
+	f.lock.RLock()
+	rules, reverse := f.outbound, f.inbound
+	if inbound {
+		rules, reverse = f.inbound, f.outbound
+	}
+	f.lock.RUnlock()
+
+	if rules == nil && reverse == nil {
+		return true
+	}
+
+	protocol, src, dst, srcPort, dstPort, ok := parsePacket(packet)
+	if !ok {
+		return rules == nil
+	}
+	key := flowKey{protocol: protocol, local: src, remote: dst, localPort: srcPort, remotePort: dstPort}
+	if inbound {
+		key = flowKey{protocol: protocol, local: dst, remote: src, localPort: dstPort, remotePort: srcPort}
+	}
+
+	if rules != nil && !f.flowExists(key) {
+		permitted := false
+		for _, rule := range rules {
+			if rule.matches(protocol, key.remote, dstPort) {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return false
+		}
+	}
+	if reverse != nil {
+		// track the connection so that its replies are permitted
+		f.trackFlow(key)
+	}
+	return true
+}
+
+func (f *packetFilter) flowExists(key flowKey) bool {
+	f.flowsLock.Lock()
+	defer f.flowsLock.Unlock()
+	lastSeen, found := f.flows[key]
+	if !found || f.now().Sub(lastSeen) > flowTimeout {
+		return false
+	}
+	f.flows[key] = f.now()
+	return true
+}
+
+func (f *packetFilter) trackFlow(key flowKey) {
+	f.flowsLock.Lock()
+	defer f.flowsLock.Unlock()
+	now := f.now()
+	f.flows[key] = now
+	if now.Sub(f.lastSweep) < flowSweepInterval {
+		return
+	}
+	f.lastSweep = now
+	for k, lastSeen := range f.flows {
+		if now.Sub(lastSeen) > flowTimeout {
+			delete(f.flows, k)
+		}
+	}
+}
+
+// parsePacket returns the ip protocol, addresses and ports of an ip packet. The ports of protocols
+// other than tcp and udp, and of fragments that do not hold the transport header are 0.
+func parsePacket(packet []byte) (protocol uint8, src, dst netip.Addr, srcPort, dstPort uint16, ok bool) {
+	if len(packet) < 1 {
+		return
+	}
+	var transport []byte
+	switch packet[0] >> 4 {
+	case 4:
+		if len(packet) < 20 {
+			return
+		}
+		headerLen := int(packet[0]&0x0f) * 4
+		if headerLen < 20 || len(packet) < headerLen {
+			return
+		}
+		protocol = packet[9]
+		src = netip.AddrFrom4([4]byte(packet[12:16]))
+		dst = netip.AddrFrom4([4]byte(packet[16:20]))
+		if binary.BigEndian.Uint16(packet[6:8])&0x1fff == 0 {
+			transport = packet[headerLen:]
+		}
+	case 6:
+		if len(packet) < 40 {
+			return
+		}
+		// extension headers are not parsed, so the next header is matched as the protocol
+		protocol = packet[6]
+		src = netip.AddrFrom16([16]byte(packet[8:24]))
+		dst = netip.AddrFrom16([16]byte(packet[24:40]))
+		transport = packet[40:]
+	default:
+		return
+	}
+	if (protocol == ipProtocolTCP || protocol == ipProtocolUDP) && len(transport) >= 4 {
+		srcPort = binary.BigEndian.Uint16(transport[0:2])
+		dstPort = binary.BigEndian.Uint16(transport[2:4])
+	}
+	return protocol, src, dst, srcPort, dstPort, true
+}
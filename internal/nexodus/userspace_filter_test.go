@@ -0,0 +1,133 @@
+package nexodus
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+// fakeTun is a tun device that returns the queued packets and records the written packets
+type fakeTun struct {
+	tun.Device
+	read    [][]byte
+	written [][]byte
+}
+
+func (t *fakeTun) Read(bufs [][]byte, sizes []int, offset int) (int, error) {
+	n := 0
+	for ; n < len(bufs) && len(t.read) > 0; n++ {
+		sizes[n] = copy(bufs[n][offset:], t.read[0])
+		t.read = t.read[1:]
+	}
+	return n, nil
+}
+
+func (t *fakeTun) Write(bufs [][]byte, offset int) (int, error) {
+	for _, buf := range bufs {
+		t.written = append(t.written, append([]byte{}, buf[offset:]...))
+	}
+	return len(bufs), nil
+}
+
+func testPacket(protocol uint8, src, dst string, srcPort, dstPort uint16) []byte {
+	srcAddr, dstAddr := netip.MustParseAddr(src), netip.MustParseAddr(dst)
+	var packet []byte
+	if srcAddr.Is4() {
+		packet = make([]byte, 20+8)
+		packet[0] = 0x45
+		packet[9] = protocol
+		copy(packet[12:16], srcAddr.AsSlice())
+		copy(packet[16:20], dstAddr.AsSlice())
+	} else {
+		packet = make([]byte, 40+8)
+		packet[0] = 0x60
+		packet[6] = protocol
+		copy(packet[8:24], srcAddr.AsSlice())
+		copy(packet[24:40], dstAddr.AsSlice())
+	}
+	transport := packet[len(packet)-8:]
+	binary.BigEndian.PutUint16(transport[0:2], srcPort)
+	binary.BigEndian.PutUint16(transport[2:4], dstPort)
+	return packet
+}
+
+func TestParsePacket(t *testing.T) {
+	protocol, src, dst, srcPort, dstPort, ok := parsePacket(testPacket(ipProtocolTCP, "100.64.0.2", "100.64.0.1", 40000, 22))
+	require.True(t, ok)
+	assert.Equal(t, uint8(ipProtocolTCP), protocol)
+	assert.Equal(t, netip.MustParseAddr("100.64.0.2"), src)
+	assert.Equal(t, netip.MustParseAddr("100.64.0.1"), dst)
+	assert.Equal(t, uint16(40000), srcPort)
+	assert.Equal(t, uint16(22), dstPort)
+
+	protocol, _, dst, _, dstPort, ok = parsePacket(testPacket(ipProtocolUDP, "200::2", "200::1", 5353, 53))
+	require.True(t, ok)
+	assert.Equal(t, uint8(ipProtocolUDP), protocol)
+	assert.Equal(t, netip.MustParseAddr("200::1"), dst)
+	assert.Equal(t, uint16(53), dstPort)
+
+	_, _, _, _, _, ok = parsePacket([]byte{0x45, 0})
+	assert.False(t, ok)
+}
+
+func TestPacketFilter(t *testing.T) {
+	dev := &fakeTun{}
+	filter := newPacketFilter(dev)
+	now := time.Now()
+	filter.now = func() time.Time { return now }
+
+	local := "100.64.0.1"
+	ssh := testPacket(ipProtocolTCP, "100.64.0.2", local, 40000, 22)
+	http := testPacket(ipProtocolTCP, "100.64.0.2", local, 40000, 80)
+	outside := testPacket(ipProtocolTCP, "10.0.0.1", local, 40000, 22)
+	dns := testPacket(ipProtocolUDP, local, "8.8.8.8", 5353, 53)
+	dnsReply := testPacket(ipProtocolUDP, "8.8.8.8", local, 53, 5353)
+	otherDns := testPacket(ipProtocolUDP, local, "1.1.1.1", 5353, 53)
+
+	// without a security group all packets are permitted
+	assert.True(t, filter.allow(http, true))
+	assert.True(t, filter.allow(otherDns, false))
+
+	require.NoError(t, filter.update(&public.ModelsSecurityGroup{
+		InboundRules: []public.ModelsSecurityRule{{IpProtocol: "tcp", FromPort: 22, ToPort: 22, IpRanges: []string{"100.64.0.0/10"}}},
+	}, true))
+	assert.True(t, filter.allow(ssh, true))
+	assert.False(t, filter.allow(http, true))
+	assert.False(t, filter.allow(outside, true))
+	// a direction without rules permits all packets, the connection is tracked so that its replies are permitted
+	quad9 := testPacket(ipProtocolUDP, local, "9.9.9.9", 5353, 53)
+	assert.True(t, filter.allow(quad9, false))
+	assert.True(t, filter.allow(testPacket(ipProtocolUDP, "9.9.9.9", local, 53, 5353), true))
+
+	require.NoError(t, filter.update(&public.ModelsSecurityGroup{
+		InboundRules:  []public.ModelsSecurityRule{{IpProtocol: "tcp", FromPort: 22, ToPort: 22}},
+		OutboundRules: []public.ModelsSecurityRule{{IpProtocol: "udp", FromPort: 53, ToPort: 53, IpRanges: []string{"8.8.8.8"}}},
+	}, true))
+	assert.False(t, filter.allow(otherDns, false))
+	assert.False(t, filter.allow(dnsReply, true))
+	assert.True(t, filter.allow(dns, false))
+	// the replies of permitted connections are permitted
+	assert.True(t, filter.allow(dnsReply, true))
+	now = now.Add(flowTimeout + time.Second)
+	assert.False(t, filter.allow(dnsReply, true))
+
+	// packets that are not permitted are not written to or read from the device
+	n, err := filter.Write([][]byte{http, ssh}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, [][]byte{ssh}, dev.written)
+
+	dev.read = [][]byte{otherDns, dns}
+	bufs := [][]byte{make([]byte, 100), make([]byte, 100)}
+	sizes := make([]int, 2)
+	n, err = filter.Read(bufs, sizes, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	assert.Equal(t, dns, bufs[0][:sizes[0]])
+}
@@ -12,7 +12,9 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/nexodus-io/nexodus/internal/api/public"
 	"github.com/nexodus-io/nexodus/internal/stun"
+	"github.com/nexodus-io/nexodus/internal/util"
 	"github.com/txn2/txeh"
 
 	"go.uber.org/zap"
@@ -116,7 +118,7 @@ func IsNAT(logger *zap.SugaredLogger, nodeOS, controller string, port string) (b
 		}
 		hostIP = linuxIP.String()
 	}
-	ipAndPort, err := stun.Request(logger, stun.NextServer(), 0)
+	ipAndPort, _, err := stun.RequestWithFallback(logger, 0)
 	if err != nil {
 		return false, err
 	}
@@ -309,3 +311,28 @@ func RemoveLocalDerpDnsEntry(domain string) error {
 	}
 	return nil
 }
+
+// ipv4SecurityRules drops the IPv6 only rules and IPv6 ranges from security group rules,
+// it is used when IPv6 is disabled on the host.
+func ipv4SecurityRules(rules []public.ModelsSecurityRule) []public.ModelsSecurityRule {
+	var result []public.ModelsSecurityRule
+	for _, rule := range rules {
+		if rule.IpProtocol == "ipv6" || rule.IpProtocol == "icmpv6" {
+			continue
+		}
+		if len(rule.IpRanges) > 0 {
+			var ipRanges []string
+			for _, ipRange := range rule.IpRanges {
+				if util.ContainsValidCustomIPv4Ranges([]string{ipRange}) || !util.ContainsValidCustomIPv6Ranges([]string{ipRange}) {
+					ipRanges = append(ipRanges, ipRange)
+				}
+			}
+			if len(ipRanges) == 0 {
+				continue
+			}
+			rule.IpRanges = ipRanges
+		}
+		result = append(result, rule)
+	}
+	return result
+}
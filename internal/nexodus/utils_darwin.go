@@ -114,11 +114,8 @@ func defaultTunnelDevOS() string {
 
 // binaryChecks validate the required binaries are available
 func binaryChecks() error {
-	// Darwin wireguard-go userspace binary
-	if IsCommandAvailable(nexdWgGoBinary) || IsCommandAvailable(wgGoBinary) {
-		return nil
-	}
-	return fmt.Errorf("%s command not found, is wireguard installed?", wgGoBinary)
+	// wireguard runs in-process on darwin, no binaries are required
+	return nil
 }
 
 // isIPv6Supported returns true if the platform supports IPv6, return true if ifconfig isn't present for whatever reason
@@ -0,0 +1,24 @@
+package nexodus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+func TestIpv4SecurityRules(t *testing.T) {
+	rules := []public.ModelsSecurityRule{
+		{IpProtocol: "tcp", FromPort: 22, ToPort: 22},
+		{IpProtocol: "ipv6"},
+		{IpProtocol: "icmpv6", IpRanges: []string{"200::/64"}},
+		{IpProtocol: "udp", IpRanges: []string{"100.64.0.0/10", "200::/64"}},
+		{IpProtocol: "tcp", IpRanges: []string{"2001:4860:4860::8888-2001:4860:4860::8889"}},
+	}
+
+	require.Equal(t, []public.ModelsSecurityRule{
+		{IpProtocol: "tcp", FromPort: 22, ToPort: 22},
+		{IpProtocol: "udp", IpRanges: []string{"100.64.0.0/10"}},
+	}, ipv4SecurityRules(rules))
+}
@@ -3,42 +3,50 @@
 package nexodus
 
 import (
-	"bytes"
 	"fmt"
 	"net"
 	"os"
-	"os/exec"
-	"strings"
 
-	"github.com/nexodus-io/nexodus/internal/util"
 	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
 )
 
 // RouteExistsOS checks to see if a route exists for the specified prefix
 func RouteExistsOS(prefix string) (bool, error) {
-	if err := ValidateCIDR(prefix); err != nil {
+	destination, err := parseRoutePrefix(prefix)
+	if err != nil {
 		return false, err
 	}
 
-	var output bytes.Buffer
-	var cmd *exec.Cmd
-
-	if util.IsIPv4Prefix(prefix) {
-		cmd = exec.Command("netsh", "interface", "ipv4", "show", "route")
-		cmd.Stdout = &output
+	family := winipcfg.AddressFamily(windows.AF_INET6)
+	if destination.Addr().Is4() {
+		family = windows.AF_INET
 	}
-
-	if util.IsIPv6Prefix(prefix) {
-		cmd = exec.Command("netsh", "interface", "ipv6", "show", "route")
-		cmd.Stdout = &output
+	routes, err := winipcfg.GetIPForwardTable2(family)
+	if err != nil {
+		return false, fmt.Errorf("error retrieving windows routes: %w", err)
 	}
 
-	if err := cmd.Run(); err != nil {
-		return false, err
+	for _, route := range routes {
+		if route.DestinationPrefix.Prefix() == destination {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	// Validate the IP we're expecting is in the output
-	return strings.Contains(output.String(), prefix), nil
+// interfaceLUID looks up the locally unique identifier the IP helper API uses for the interface
+func interfaceLUID(dev string) (winipcfg.LUID, error) {
+	iface, err := net.InterfaceByName(dev)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lookup the windows interface %s: %w", dev, err)
+	}
+	luid, err := winipcfg.LUIDFromIndex(uint32(iface.Index))
+	if err != nil {
+		return 0, fmt.Errorf("failed to lookup the LUID of the windows interface %s: %w", dev, err)
+	}
+	return luid, nil
 }
 
 // discoverLinuxAddress only used for windows build purposes
@@ -79,17 +87,17 @@ func binaryChecks() error {
 
 // isIPv6Supported returns true if the platform supports IPv6
 func isIPv6Supported() bool {
-	// Use netsh to check IPv6 status on interfaces
-	data, err := RunCommand("netsh", "interface", "ipv6", "show", "interfaces")
+	// IPv6 is supported if the IPv6 stack is bound to a connected interface
+	ifaces, err := winipcfg.GetIPInterfaceTable(windows.AF_INET6)
 	if err != nil {
 		return false
 	}
-
-	if strings.Contains(strings.ToLower(data), "disabled") {
-		return false
+	for _, iface := range ifaces {
+		if iface.Connected {
+			return true
+		}
 	}
-
-	return true
+	return false
 }
 
 // getDefaultGatewayIPv4 not currently implemented for windows
@@ -0,0 +1,332 @@
+//go:build windows
+
+package nexodus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Bindings to the Windows Filtering Platform management API, see
+// https://learn.microsoft.com/en-us/windows/win32/fwp/fwp-reference
+
+var (
+	modfwpuclnt                = windows.NewLazySystemDLL("fwpuclnt.dll")
+	procFwpmEngineOpen0        = modfwpuclnt.NewProc("FwpmEngineOpen0")
+	procFwpmEngineClose0       = modfwpuclnt.NewProc("FwpmEngineClose0")
+	procFwpmSubLayerAdd0       = modfwpuclnt.NewProc("FwpmSubLayerAdd0")
+	procFwpmFilterAdd0         = modfwpuclnt.NewProc("FwpmFilterAdd0")
+	procFwpmTransactionBegin0  = modfwpuclnt.NewProc("FwpmTransactionBegin0")
+	procFwpmTransactionCommit0 = modfwpuclnt.NewProc("FwpmTransactionCommit0")
+	procFwpmTransactionAbort0  = modfwpuclnt.NewProc("FwpmTransactionAbort0")
+)
+
+const (
+	rpcCAuthnWinNT         = 10
+	fwpmSessionFlagDynamic = 0x00000001
+
+	fwpActionFlagTerminating = 0x00001000
+	fwpActionBlock           = 0x00000001 | fwpActionFlagTerminating
+	fwpActionPermit          = 0x00000002 | fwpActionFlagTerminating
+
+	fwpMatchEqual = 0
+	fwpMatchRange = 5
+
+	fwpUint8           = 1
+	fwpUint16          = 2
+	fwpUint32          = 3
+	fwpUint64          = 4
+	fwpByteArray16Type = 11
+	fwpV4AddrMask      = 0x100
+	fwpV6AddrMask      = 0x101
+	fwpRangeType       = 0x102
+
+	// the filters of the sublayer are weighted so that the permits are evaluated before the drops
+	wfpWeightBlock    = 0
+	wfpWeightPermit   = 15
+	wfpSublayerWeight = 0xffff
+)
+
+var (
+	// the ALE layers are stateful, the packets of an accepted connection are not evaluated again
+	fwpmLayerAleAuthConnectV4    = windows.GUID{Data1: 0xc38d57d1, Data2: 0x05a7, Data3: 0x4c33, Data4: [8]byte{0x90, 0x4f, 0x7f, 0xbc, 0xee, 0xe6, 0x0e, 0x82}}
+	fwpmLayerAleAuthConnectV6    = windows.GUID{Data1: 0x4a72393b, Data2: 0x319f, Data3: 0x44bc, Data4: [8]byte{0x84, 0xc3, 0xba, 0x54, 0xdc, 0xb3, 0xb6, 0xb4}}
+	fwpmLayerAleAuthRecvAcceptV4 = windows.GUID{Data1: 0xe1cd9fe7, Data2: 0xf4b5, Data3: 0x4273, Data4: [8]byte{0x96, 0xc0, 0x59, 0x2e, 0x48, 0x7b, 0x86, 0x50}}
+	fwpmLayerAleAuthRecvAcceptV6 = windows.GUID{Data1: 0xa3b42c97, Data2: 0x9f04, Data3: 0x4672, Data4: [8]byte{0xb8, 0x7e, 0xce, 0xe9, 0xc4, 0x83, 0x25, 0x7f}}
+
+	fwpmConditionIpLocalInterface = windows.GUID{Data1: 0x4cd62a49, Data2: 0x59c3, Data3: 0x4969, Data4: [8]byte{0xb7, 0xf3, 0xbd, 0xa5, 0xd3, 0x28, 0x90, 0xa4}}
+	fwpmConditionIpRemoteAddress  = windows.GUID{Data1: 0xb235ae9a, Data2: 0x1d64, Data3: 0x49b8, Data4: [8]byte{0xa4, 0x4c, 0x5f, 0xf3, 0xd9, 0x09, 0x50, 0x45}}
+	fwpmConditionIpProtocol       = windows.GUID{Data1: 0x3971ef2b, Data2: 0x623e, Data3: 0x4f9a, Data4: [8]byte{0x8c, 0xb1, 0x6e, 0x79, 0xb8, 0x06, 0xb9, 0xa7}}
+	fwpmConditionIpLocalPort      = windows.GUID{Data1: 0x0c1ba1af, Data2: 0x5765, Data3: 0x453f, Data4: [8]byte{0xaf, 0x22, 0xa8, 0xf7, 0x91, 0xac, 0x77, 0x5b}}
+	fwpmConditionIpRemotePort     = windows.GUID{Data1: 0xc35a604d, Data2: 0xd22b, Data3: 0x4e1a, Data4: [8]byte{0x91, 0xb4, 0x68, 0xf6, 0x74, 0xee, 0x67, 0x4b}}
+)
+
+type fwpmDisplayData0 struct {
+	Name        *uint16
+	Description *uint16
+}
+
+type fwpByteBlob struct {
+	Size uint32
+	Data *uint8
+}
+
+type fwpmSession0 struct {
+	SessionKey           windows.GUID
+	DisplayData          fwpmDisplayData0
+	Flags                uint32
+	TxnWaitTimeoutInMSec uint32
+	ProcessId            uint32
+	Sid                  *windows.SID
+	Username             *uint16
+	KernelMode           int32
+}
+
+type fwpmSublayer0 struct {
+	SubLayerKey  windows.GUID
+	DisplayData  fwpmDisplayData0
+	Flags        uint32
+	ProviderKey  *windows.GUID
+	ProviderData fwpByteBlob
+	Weight       uint16
+}
+
+// fwpValue0 is used for both FWP_VALUE0 and FWP_CONDITION_VALUE0, the value is either
+// the value itself or a pointer to it depending on the type.
+type fwpValue0 struct {
+	Type  uint32
+	Value uintptr
+}
+
+type fwpmFilterCondition0 struct {
+	FieldKey       windows.GUID
+	MatchType      uint32
+	ConditionValue fwpValue0
+}
+
+type fwpmAction0 struct {
+	Type       uint32
+	FilterType windows.GUID
+}
+
+type fwpmFilter0 struct {
+	FilterKey           windows.GUID
+	DisplayData         fwpmDisplayData0
+	Flags               uint32
+	ProviderKey         *windows.GUID
+	ProviderData        fwpByteBlob
+	LayerKey            windows.GUID
+	SubLayerKey         windows.GUID
+	Weight              fwpValue0
+	NumFilterConditions uint32
+	FilterCondition     *fwpmFilterCondition0
+	Action              fwpmAction0
+	ProviderContextKey  [2]uint64 // union of the raw context and provider context key
+	Reserved            *windows.GUID
+	FilterId            uint64
+	EffectiveWeight     fwpValue0
+}
+
+type fwpV4AddrAndMask struct {
+	Addr uint32
+	Mask uint32
+}
+
+type fwpV6AddrAndMask struct {
+	Addr         [16]byte
+	PrefixLength uint8
+}
+
+type fwpRange0 struct {
+	ValueLow  fwpValue0
+	ValueHigh fwpValue0
+}
+
+func wfpCall(proc *windows.LazyProc, args ...uintptr) error {
+	r, _, _ := proc.Call(args...)
+	if r != 0 {
+		return fmt.Errorf("%s failed: %w", proc.Name, windows.Errno(r))
+	}
+	return nil
+}
+
+// wfpSession is a dynamic session of the filter engine, the sublayer and filters
+// added by the session are removed when it is closed or the process exits.
+type wfpSession struct {
+	engine   windows.Handle
+	sublayer windows.GUID
+	// the values referenced by the conditions of the filters being added
+	values []interface{}
+}
+
+func newWFPSession(name string) (*wfpSession, error) {
+	displayName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	session := fwpmSession0{
+		DisplayData: fwpmDisplayData0{Name: displayName},
+		Flags:       fwpmSessionFlagDynamic,
+	}
+	s := &wfpSession{}
+	if err := wfpCall(procFwpmEngineOpen0, 0, rpcCAuthnWinNT, 0,
+		uintptr(unsafe.Pointer(&session)), uintptr(unsafe.Pointer(&s.engine))); err != nil {
+		return nil, err
+	}
+
+	s.sublayer, err = windows.GenerateGUID()
+	if err != nil {
+		_ = s.close()
+		return nil, err
+	}
+	sublayer := fwpmSublayer0{
+		SubLayerKey: s.sublayer,
+		DisplayData: fwpmDisplayData0{Name: displayName},
+		Weight:      wfpSublayerWeight,
+	}
+	if err := wfpCall(procFwpmSubLayerAdd0, uintptr(s.engine), uintptr(unsafe.Pointer(&sublayer)), 0); err != nil {
+		_ = s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *wfpSession) close() error {
+	return wfpCall(procFwpmEngineClose0, uintptr(s.engine))
+}
+
+// transaction adds the filters of fn atomically
+func (s *wfpSession) transaction(fn func() error) error {
+	if err := wfpCall(procFwpmTransactionBegin0, uintptr(s.engine), 0); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		_ = wfpCall(procFwpmTransactionAbort0, uintptr(s.engine))
+		return err
+	}
+	return wfpCall(procFwpmTransactionCommit0, uintptr(s.engine))
+}
+
+func (s *wfpSession) addFilter(name string, layer windows.GUID, action uint32, weight uint8, conditions []fwpmFilterCondition0) error {
+	displayName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	filter := fwpmFilter0{
+		DisplayData:         fwpmDisplayData0{Name: displayName},
+		LayerKey:            layer,
+		SubLayerKey:         s.sublayer,
+		Weight:              fwpValue0{Type: fwpUint8, Value: uintptr(weight)},
+		NumFilterConditions: uint32(len(conditions)),
+		Action:              fwpmAction0{Type: action},
+	}
+	if len(conditions) > 0 {
+		filter.FilterCondition = &conditions[0]
+	}
+	var id uint64
+	err = wfpCall(procFwpmFilterAdd0, uintptr(s.engine), uintptr(unsafe.Pointer(&filter)), 0, uintptr(unsafe.Pointer(&id)))
+	runtime.KeepAlive(conditions)
+	runtime.KeepAlive(s.values)
+	s.values = nil
+	return err
+}
+
+// keep stores a value referenced by a condition until the filter has been added
+func (s *wfpSession) keep(value interface{}) uintptr {
+	s.values = append(s.values, value)
+	switch v := value.(type) {
+	case *uint64:
+		return uintptr(unsafe.Pointer(v))
+	case *[16]byte:
+		return uintptr(unsafe.Pointer(v))
+	case *fwpV4AddrAndMask:
+		return uintptr(unsafe.Pointer(v))
+	case *fwpV6AddrAndMask:
+		return uintptr(unsafe.Pointer(v))
+	case *fwpRange0:
+		return uintptr(unsafe.Pointer(v))
+	}
+	panic(fmt.Sprintf("unsupported wfp condition value %T", value))
+}
+
+func (s *wfpSession) interfaceCondition(luid uint64) fwpmFilterCondition0 {
+	return fwpmFilterCondition0{
+		FieldKey:       fwpmConditionIpLocalInterface,
+		MatchType:      fwpMatchEqual,
+		ConditionValue: fwpValue0{Type: fwpUint64, Value: s.keep(&luid)},
+	}
+}
+
+func (s *wfpSession) protocolCondition(protocol uint8) fwpmFilterCondition0 {
+	return fwpmFilterCondition0{
+		FieldKey:       fwpmConditionIpProtocol,
+		MatchType:      fwpMatchEqual,
+		ConditionValue: fwpValue0{Type: fwpUint8, Value: uintptr(protocol)},
+	}
+}
+
+func (s *wfpSession) portCondition(field windows.GUID, from, to uint16) fwpmFilterCondition0 {
+	if from == to {
+		return fwpmFilterCondition0{
+			FieldKey:       field,
+			MatchType:      fwpMatchEqual,
+			ConditionValue: fwpValue0{Type: fwpUint16, Value: uintptr(from)},
+		}
+	}
+	return fwpmFilterCondition0{
+		FieldKey:  field,
+		MatchType: fwpMatchRange,
+		ConditionValue: fwpValue0{Type: fwpRangeType, Value: s.keep(&fwpRange0{
+			ValueLow:  fwpValue0{Type: fwpUint16, Value: uintptr(from)},
+			ValueHigh: fwpValue0{Type: fwpUint16, Value: uintptr(to)},
+		})},
+	}
+}
+
+func (s *wfpSession) prefixCondition(prefix netip.Prefix) fwpmFilterCondition0 {
+	condition := fwpmFilterCondition0{
+		FieldKey:  fwpmConditionIpRemoteAddress,
+		MatchType: fwpMatchEqual,
+	}
+	if prefix.Addr().Is4() {
+		// ipv4 addresses are in host byte order
+		addr := prefix.Addr().As4()
+		mask := uint32(0)
+		if prefix.Bits() > 0 {
+			mask = ^uint32(0) << (32 - prefix.Bits())
+		}
+		condition.ConditionValue = fwpValue0{Type: fwpV4AddrMask, Value: s.keep(&fwpV4AddrAndMask{
+			Addr: binary.BigEndian.Uint32(addr[:]),
+			Mask: mask,
+		})}
+	} else {
+		condition.ConditionValue = fwpValue0{Type: fwpV6AddrMask, Value: s.keep(&fwpV6AddrAndMask{
+			Addr:         prefix.Addr().As16(),
+			PrefixLength: uint8(prefix.Bits()),
+		})}
+	}
+	return condition
+}
+
+func (s *wfpSession) rangeCondition(from, to netip.Addr) fwpmFilterCondition0 {
+	var value fwpRange0
+	if from.Is4() {
+		low, high := from.As4(), to.As4()
+		value.ValueLow = fwpValue0{Type: fwpUint32, Value: uintptr(binary.BigEndian.Uint32(low[:]))}
+		value.ValueHigh = fwpValue0{Type: fwpUint32, Value: uintptr(binary.BigEndian.Uint32(high[:]))}
+	} else {
+		low, high := from.As16(), to.As16()
+		value.ValueLow = fwpValue0{Type: fwpByteArray16Type, Value: s.keep(&low)}
+		value.ValueHigh = fwpValue0{Type: fwpByteArray16Type, Value: s.keep(&high)}
+	}
+	return fwpmFilterCondition0{
+		FieldKey:       fwpmConditionIpRemoteAddress,
+		MatchType:      fwpMatchRange,
+		ConditionValue: fwpValue0{Type: fwpRangeType, Value: s.keep(&value)},
+	}
+}
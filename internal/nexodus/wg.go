@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"net"
 	"strconv"
 	"time"
@@ -254,6 +255,22 @@ func testWgListenPort(port int) error {
 	return nil
 }
 
+// getWgListenPortInRange() will allocate a UDP port within the min-max range to use as our
+// wireguard listen port. It starts at a random port in the range and moves on to the next
+// port whenever the port is already bound.
+func getWgListenPortInRange(min, max int) (int, error) {
+	size := max - min + 1
+	// #nosec G404
+	offset := rand.Intn(size)
+	for i := 0; i < size; i++ {
+		port := min + (offset+i)%size
+		if testWgListenPort(port) == nil {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free UDP port available in the listen port range %d-%d", min, max)
+}
+
 // getWgListenPort() will allocate a random UDP port to use as our wireguard listen port
 func getWgListenPort() (int, error) {
 	l, err := net.ListenUDP("udp", &net.UDPAddr{})
@@ -64,8 +64,10 @@ func (nx *Nexodus) setupInterface() error {
 	if err := checkIPConflict(nx.TunnelIP); err != nil {
 		return err
 	}
-	if err := checkIPConflict(nx.TunnelIpV6); err != nil {
-		return err
+	if nx.TunnelIpV6 != "" {
+		if err := checkIPConflict(nx.TunnelIpV6); err != nil {
+			return err
+		}
 	}
 
 	return nx.setupInterfaceOS()
@@ -5,6 +5,7 @@ import (
 	"net"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,7 +23,9 @@ const (
 	peeringMethodRelaySelf            = "relay-node-self"
 	peeringMethodRelayPeerDirectLocal = "relay-node-peer-direct-local"
 	peeringMethodRelayPeer            = "relay-node-peer"
+	peeringMethodLanDiscovery         = "lan-discovery"
 	peeringMethodDirectLocal          = "direct-local"
+	peeringMethodHostCandidate        = "host-candidate"
 	peeringMethodReflexive            = "reflexive"
 	peeringMethodViaRelay             = "via-relay"
 	peeringMethodViaDerpRelay         = "via-derp-relay"
@@ -72,6 +75,14 @@ var wgPeerMethods = []wgPeerMethod{
 		},
 		buildPeerConfig: buildRelayPeer,
 	},
+	{
+		// The peer announced its local endpoint on the same LAN as this node, try direct peering over the local segment
+		name: peeringMethodLanDiscovery,
+		checkPrereqs: func(nx *Nexodus, device public.ModelsDevice, _ string, healthyRelay bool, _ bool) bool {
+			return !nx.relay && !device.Relay && nx.lanPeerEndpoint(device.PublicKey) != ""
+		},
+		buildPeerConfig: buildLanDiscoveryPeer,
+	},
 	{
 		// We are behind the same reflexive address as the peer, try direct, local peering
 		name: peeringMethodDirectLocal,
@@ -80,6 +91,23 @@ var wgPeerMethods = []wgPeerMethod{
 		},
 		buildPeerConfig: buildDirectLocalPeer,
 	},
+	{
+		// The peer has addresses on a network attached to this node, try its host candidates in priority order
+		name: peeringMethodHostCandidate,
+		checkPrereqs: func(nx *Nexodus, device public.ModelsDevice, _ string, healthyRelay bool, _ bool) bool {
+			return !nx.relay && !device.Relay && len(nx.peerHostCandidates(device)) > 0
+		},
+		buildPeerConfig: buildDirectLocalPeer,
+	},
+	{
+		// Both nodes have global IPv6 connectivity, which is not behind a NAT, try peering with the IPv6 endpoint
+		// of the peer before its IPv4 reflexive address
+		name: peeringMethodDirectIPv6,
+		checkPrereqs: func(nx *Nexodus, device public.ModelsDevice, _ string, healthyRelay bool, _ bool) bool {
+			return !nx.relay && !device.Relay && nx.nodeReflexiveAddressIPv6.IsValid() && device.EndpointIpv6 != ""
+		},
+		buildPeerConfig: buildIPv6Peer,
+	},
 	{
 		// If neither side is behind symmetric NAT, we can try peering with its reflexive address.
 		// This is the address+port opened up by the peer using STUN.
@@ -89,6 +117,14 @@ var wgPeerMethods = []wgPeerMethod{
 		},
 		buildPeerConfig: buildReflexivePeer,
 	},
+	{
+		// A hole punch coordinated by the service opened a direct path to the peer behind a NAT
+		name: peeringMethodHolePunch,
+		checkPrereqs: func(nx *Nexodus, device public.ModelsDevice, _ string, healthyRelay bool, _ bool) bool {
+			return !nx.relay && !device.Relay && nx.holePunchEndpoint(device.PublicKey) != ""
+		},
+		buildPeerConfig: buildHolePunchPeer,
+	},
 	{
 		// Try connecting to the peer via a derp relay, in case the legacy relay is not available
 		// and none of the peering methods above worked
@@ -120,6 +156,7 @@ func (nx *Nexodus) peeringReset(d *deviceCacheEntry) {
 	// By setting the peering method index to -1, we will consider all other
 	// methods that may be available.
 	d.peeringMethodIndex = -1
+	d.candidateIndex = 0
 
 	// All the stats are now invalid
 	d.peeringTime = time.Time{}
@@ -164,8 +201,23 @@ func (nx *Nexodus) rebuildPeerConfig(d *deviceCacheEntry, healthyRelay bool, wgR
 		nx.vpc.Ipv4Cidr,
 		nx.vpc.Ipv6Cidr,
 	}
+	if d.device.Relay && d.device.PublicKey != nx.relayPublicKey {
+		// only the relay in use gets the vpc prefixes, the other relays are peered like any other peer
+		relayAllowedIP = append(slices.Clone(d.device.AllowedIps), d.device.AdvertiseCidrs...)
+	}
 
 	tryNextMethod := nx.peeringFailed(*d, healthyRelay)
+	candidates := nx.peerHostCandidates(d.device)
+	if tryNextMethod && d.peeringMethod == peeringMethodHostCandidate && d.candidateIndex+1 < len(candidates) {
+		// Try the next host candidate of the peer before moving on to the next method
+		nx.logger.Debugf("Peering with peer [ %s ] using host candidate [ %s ] has failed, trying next candidate", d.device.PublicKey, candidates[d.candidateIndex])
+		d.candidateIndex++
+		tryNextMethod = false
+	}
+	if tryNextMethod && d.peeringMethod == peeringMethodHolePunch {
+		// the path opened by the hole punch is gone
+		nx.forgetHolePunch(d.device.PublicKey)
+	}
 	if tryNextMethod {
 		nx.logger.Debugf("Peering with peer [ %s ] using method [ %s ] has failed, trying next method", d.device.PublicKey, d.peeringMethod)
 		if nx.shouldResetPeering(d, reflexiveIP4, healthyRelay, wgRelayAvailable) {
@@ -179,6 +231,7 @@ func (nx *Nexodus) rebuildPeerConfig(d *deviceCacheEntry, healthyRelay bool, wgR
 	chosenMethod := d.peeringMethod
 	chosenMethodIndex := d.peeringMethodIndex
 	for i, method := range wgPeerMethods {
+		endpointIP := localIP
 		if i < d.peeringMethodIndex {
 			// A peering method was previously chosen and we haven't reached it yet
 			continue
@@ -195,12 +248,24 @@ func (nx *Nexodus) rebuildPeerConfig(d *deviceCacheEntry, healthyRelay bool, wgR
 			// We are already set up to use a relay for this peer
 			break
 		}
-		peer = method.buildPeerConfig(nx, d.device, relayAllowedIP, localIP, peerPort, reflexiveIP4)
+		if method.name == peeringMethodHostCandidate {
+			if i != d.peeringMethodIndex || d.candidateIndex >= len(candidates) {
+				// Start with the candidate with the highest priority
+				d.candidateIndex = 0
+			}
+			endpointIP = candidates[d.candidateIndex]
+		}
+		peer = method.buildPeerConfig(nx, d.device, relayAllowedIP, endpointIP, peerPort, reflexiveIP4)
 		chosenMethod = method.name
 		chosenMethodIndex = i
 		break
 	}
 
+	if chosenMethod == peeringMethodViaRelay || chosenMethod == peeringMethodViaDerpRelay {
+		// a hole punch may open a direct path to the peer
+		nx.maybeRequestHolePunch(d.device)
+	}
+
 	return peer, chosenMethod, chosenMethodIndex
 }
 
@@ -221,21 +286,10 @@ func (nx *Nexodus) buildPeersConfig() map[string]public.ModelsDevice {
 	nx.buildLocalConfig()
 
 	// do we have a healthy relay available?
-	healthyRelay := false
-	relayAvailable := false
-	isDerpRelay := false
-	var relayDevice deviceCacheEntry
-	for _, d := range nx.deviceCache {
-		if d.device.Relay {
-			relayAvailable = true
-			relayDevice = d
-			isDerpRelay = nx.derpRelay(d)
-			if d.peerHealthy {
-				healthyRelay = true
-				break
-			}
-		}
-	}
+	relayDevice, relayAvailable := nx.selectRelay()
+	healthyRelay := relayAvailable && relayDevice.peerHealthy
+	isDerpRelay := relayAvailable && nx.derpRelay(relayDevice)
+	nx.relayPublicKey = relayDevice.device.PublicKey
 
 	// If on-boarded relay is available and it's derp relay, set custom derp map
 	// If there is no on-boarded relay, set default derp map
@@ -306,6 +360,8 @@ func (nx *Nexodus) buildPeersConfig() map[string]public.ModelsDevice {
 
 	}
 
+	nx.electPrefixGateways()
+
 	now := time.Now()
 	wgRelayAvailable := relayAvailable && !isDerpRelay
 	for _, dIter := range nx.deviceCache {
@@ -316,6 +372,9 @@ func (nx *Nexodus) buildPeersConfig() map[string]public.ModelsDevice {
 		}
 
 		peerConfig, chosenMethod, chosenMethodIndex := nx.rebuildPeerConfig(&d, healthyRelay, wgRelayAvailable)
+		peerConfig.AllowedIPs = nx.exitNodeAllowedIPs(d.device, peerConfig.AllowedIPs)
+		peerConfig.AllowedIPs = nx.gatewayAllowedIPs(d.device, peerConfig.AllowedIPs)
+		peerConfig.AllowedIPsForRelay = nx.gatewayAllowedIPs(d.device, peerConfig.AllowedIPsForRelay)
 		if len(peerConfig.AllowedIPsForRelay) > 0 {
 			allowedIPsForRelay = append(allowedIPsForRelay, peerConfig.AllowedIPsForRelay...)
 		}
@@ -401,9 +460,12 @@ func (nx *Nexodus) extractLocalAndReflexiveIP(device public.ModelsDevice) (strin
 	localIP := ""
 	reflexiveIP4 := ""
 	for _, endpoint := range device.Endpoints {
-		if endpoint.Source == "local" {
+		switch endpoint.Source {
+		case "local":
 			localIP = endpoint.Address
-		} else {
+		case endpointSourceHost:
+			// host candidates are tried by the host candidate peering method
+		default:
 			reflexiveIP4 = endpoint.Address
 		}
 	}
@@ -529,7 +591,7 @@ func (nx *Nexodus) buildLocalConfig() {
 
 	// if the local node address changed replace it on wg0
 	if nx.TunnelIP != d.device.Ipv4TunnelIps[0].Address {
-		nx.logger.Infof("New local Wireguard interface addresses assigned IPv4 [ %s ] IPv6 [ %s ]", d.device.Ipv4TunnelIps[0].Address, d.device.Ipv6TunnelIps[0].Address)
+		nx.logger.Infof("New local Wireguard interface addresses assigned IPv4 [ %s ] IPv6 [ %s ]", d.device.Ipv4TunnelIps[0].Address, tunnelIpV6(d.device))
 		if runtime.GOOS == Linux.String() && linkExists(nx.tunnelIface) {
 			if err := delLink(nx.tunnelIface); err != nil {
 				nx.logger.Infof("Failed to delete %s: %v", nx.tunnelIface, err)
@@ -537,7 +599,7 @@ func (nx *Nexodus) buildLocalConfig() {
 		}
 	}
 	nx.TunnelIP = d.device.Ipv4TunnelIps[0].Address
-	nx.TunnelIpV6 = d.device.Ipv6TunnelIps[0].Address
+	nx.TunnelIpV6 = tunnelIpV6(d.device)
 	localInterface = wgLocalConfig{
 		nx.wireguardPvtKey,
 		nx.listenPort,
@@ -546,6 +608,15 @@ func (nx *Nexodus) buildLocalConfig() {
 	nx.wgConfig.Interface = localInterface
 }
 
+// tunnelIpV6 returns the IPv6 tunnel address of a device or an empty string
+// if the device is running in IPv4 only mode.
+func tunnelIpV6(device public.ModelsDevice) string {
+	if len(device.Ipv6TunnelIps) == 0 {
+		return ""
+	}
+	return device.Ipv6TunnelIps[0].Address
+}
+
 func (nx *Nexodus) derpRelay(d deviceCacheEntry) bool {
 	rtype, ok := d.metadata.Value["type"]
 	if !ok {
@@ -0,0 +1,79 @@
+package routers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/internal/models"
+)
+
+const (
+	// APIVersion is the current version of the api, served under /api/<version>
+	APIVersion = "v1"
+	// APIVersionHeader is the header a client requests a version of the unversioned routes with, and the
+	// header of the responses that tells the version that served the request
+	APIVersionHeader = "Nexodus-API-Version"
+)
+
+// APIVersions are the versions of the api served by the apiserver
+var APIVersions = []string{APIVersion}
+
+// unversionedAPIDeprecatedAt is when the unversioned /api routes were deprecated in favor of /api/v1
+var unversionedAPIDeprecatedAt = time.Date(2024, time.March, 25, 0, 0, 0, 0, time.UTC)
+
+func isAPIVersion(version string) bool {
+	for _, v := range APIVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// apiPolicyPath returns the segments of the path of an api request without its version, the authz policy
+// applies to all the versions of a route
+func apiPolicyPath(urlPath string) []string {
+	path := strings.Split(strings.TrimLeft(urlPath, "/"), "/")
+	if len(path) > 1 && path[0] == "api" && isAPIVersion(path[1]) {
+		path = append(path[:1:1], path[2:]...)
+	}
+	return path
+}
+
+// APIVersionMiddleware tells the clients the version of the api that served the request
+func APIVersionMiddleware(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(APIVersionHeader, version)
+		c.Next()
+	}
+}
+
+// UnversionedAPIMiddleware serves the unversioned /api routes as aliases of the version requested with the
+// Nexodus-API-Version header, the current version when not requested, and marks them deprecated with the
+// Deprecation, Sunset and Link headers of RFC 9745 and RFC 8594. The sunset is not announced when zero.
+func UnversionedAPIMiddleware(sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader(APIVersionHeader)
+		if version == "" {
+			version = APIVersion
+		}
+		// only the current version has aliases, the older versions are served under their own path
+		if version != APIVersion {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, models.NewBaseError(
+				fmt.Sprintf("api version %s is not served by the unversioned routes, use /api/%s", version, APIVersion)))
+			return
+		}
+
+		c.Header(APIVersionHeader, version)
+		c.Header("Deprecation", fmt.Sprintf("@%d", unversionedAPIDeprecatedAt.Unix()))
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		successor := "/api/" + version + strings.TrimPrefix(c.Request.URL.Path, "/api")
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIPolicyPath(t *testing.T) {
+	require := require.New(t)
+	require.Equal([]string{"api", "devices", "1234"}, apiPolicyPath("/api/v1/devices/1234"))
+	require.Equal([]string{"api", "devices", "1234"}, apiPolicyPath("/api/devices/1234"))
+	require.Equal([]string{"api", "v2", "devices"}, apiPolicyPath("/api/v2/devices"))
+}
+
+func TestUnversionedAPIMiddleware(t *testing.T) {
+	require := require.New(t)
+	gin.SetMode(gin.TestMode)
+	sunset := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	r := gin.New()
+	r.GET("/api/v1/devices/:id", APIVersionMiddleware(APIVersion), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/api/devices/:id", UnversionedAPIMiddleware(sunset), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if version != "" {
+			req.Header.Set(APIVersionHeader, version)
+		}
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	res := serve("/api/v1/devices/1234", "")
+	require.Equal(http.StatusOK, res.Code)
+	require.Equal("v1", res.Header().Get(APIVersionHeader))
+	require.Empty(res.Header().Get("Deprecation"))
+
+	res = serve("/api/devices/1234", "")
+	require.Equal(http.StatusOK, res.Code)
+	require.Equal("v1", res.Header().Get(APIVersionHeader))
+	require.Equal("@1711324800", res.Header().Get("Deprecation"))
+	require.Equal("Wed, 01 Jan 2025 00:00:00 GMT", res.Header().Get("Sunset"))
+	require.Equal(`</api/v1/devices/1234>; rel="successor-version"`, res.Header().Get("Link"))
+
+	res = serve("/api/devices/1234", "v1")
+	require.Equal(http.StatusOK, res.Code)
+	res = serve("/api/devices/1234", "v2")
+	require.Equal(http.StatusNotAcceptable, res.Code)
+}
@@ -7,19 +7,23 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/nexodus-io/nexodus/internal/handlers"
+	"github.com/nexodus-io/nexodus/internal/models"
 	"github.com/redis/go-redis/v9"
 	"io"
 	"net/http"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 	csmap "github.com/mhmtszr/concurrent-swiss-map"
 	"github.com/nexodus-io/nexodus/internal/util"
 	"github.com/nexodus-io/nexodus/internal/util/cache"
 	"github.com/open-policy-agent/opa/rego"
 	"golang.org/x/oauth2"
+	"gorm.io/gorm"
 )
 
 // key for username in gin.Context
@@ -30,8 +34,37 @@ var policy string
 
 var jwksCache = cache.NewMemoizeCache[string, string](time.Second*30, time.Second*5)
 
+// tokenIssuer is an identity provider whose access tokens are accepted
+type tokenIssuer struct {
+	// the name of the provider, empty for the provider of OidcURL
+	name     string
+	issuer   string
+	jwksURI  string
+	audience string
+}
+
+// tokenIssuerOf returns the issuer of the tokens whose iss claim is the one of the unverified token, the
+// first issuer when none is, and no issuer when there are none. The signature of the token is verified by
+// the authz policy.
+func tokenIssuerOf(issuers []tokenIssuer, token string) tokenIssuer {
+	if len(issuers) == 0 {
+		return tokenIssuer{}
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err == nil {
+		if iss, ok := claims["iss"].(string); ok {
+			for _, issuer := range issuers {
+				if issuer.issuer == iss {
+					return issuer
+				}
+			}
+		}
+	}
+	return issuers[0]
+}
+
 // Naive JWS Key validation
-func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodusJWKS string) (func(*gin.Context), error) {
+func ValidateJWT(ctx context.Context, o APIRouterOptions, issuers []tokenIssuer, nexodusJWKS string) (func(*gin.Context), error) {
 	query, err := rego.New(
 		rego.Query(`result = {
 			"authorized": data.token.valid_token,
@@ -40,6 +73,7 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 			"user_name": data.token.user_name,
 			"full_name": data.token.full_name,
 			"token_payload": data.token.token_payload,
+			"apiserver_token": data.token.apiserver_token,
 		}`),
 		rego.Store(o.Store),
 		rego.Module("policy.rego", policy),
@@ -58,15 +92,6 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 	return func(c *gin.Context) {
 		logger := util.WithTrace(c.Request.Context(), o.Logger)
 
-		keySet, err := jwksCache.MemoizeCanErr(jwksURI, func() (string, error) {
-			return getURLAsText(ctx, jwksURI)
-		})
-		if err != nil {
-			handlers.SendInternalServerError(c, o.Logger, err)
-			c.Abort()
-			return
-		}
-
 		authHeader := c.Request.Header.Get("Authorization")
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 {
@@ -79,13 +104,31 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 			return
 		}
 
-		path := strings.Split(strings.TrimLeft(c.Request.URL.Path, "/"), "/")
+		issuer := tokenIssuerOf(issuers, parts[1])
+		// without an identity provider only the tokens of the apiserver are valid
+		var err error
+		keySet := ""
+		if issuer.jwksURI != "" {
+			keySet, err = jwksCache.MemoizeCanErr(issuer.jwksURI, func() (string, error) {
+				return getURLAsText(ctx, issuer.jwksURI)
+			})
+			if err != nil {
+				handlers.SendInternalServerError(c, o.Logger, err)
+				c.Abort()
+				return
+			}
+		}
+
+		path := apiPolicyPath(c.Request.URL.Path)
 		input := map[string]interface{}{
-			"jwks":         keySet,
-			"nexodus_jwks": nexodusJWKS,
-			"access_token": parts[1],
-			"method":       c.Request.Method,
-			"path":         path,
+			"jwks":           keySet,
+			"nexodus_jwks":   nexodusJWKS,
+			"nexodus_issuer": o.Api.URL,
+			"access_token":   parts[1],
+			"audience":       issuer.audience,
+			"provider":       issuer.name,
+			"method":         c.Request.Method,
+			"path":           path,
 		}
 
 		results, err := query.Eval(c.Request.Context(), rego.EvalInput(input))
@@ -158,9 +201,48 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 		claims := result["token_payload"].(map[string]interface{})
 		c.Set("_nexodus.Claims", claims)
 
+		// the requests of a service account or of an api key are made as its user, the tokens of a deleted service
+		// account and of a deleted or expired api key are not accepted. Only the tokens issued by the apiserver are
+		// accepted as the tokens of a service account or of an api key, the other tokens with their scopes are not
+		// accepted at all.
+		scope, _ := claims["scope"].(string)
+		apiserverToken, _ := result["apiserver_token"].(bool)
+		var credentialUserID func(ctx context.Context, id string) (uuid.UUID, error)
+		if slices.Contains(strings.Fields(scope), handlers.ServiceAccountScope) {
+			credentialUserID = o.Api.ServiceAccountUserID
+		} else if slices.Contains(strings.Fields(scope), handlers.APIKeyScope) {
+			credentialUserID = o.Api.APIKeyUserID
+		}
+		if credentialUserID != nil {
+			if !apiserverToken {
+				logger.Debug("credential scope in a token not issued by the apiserver")
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			credentialID, _ := claims["jti"].(string)
+			userID, err := credentialUserID(c.Request.Context(), credentialID)
+			if err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					o.Api.SendInternalServerError(c, err)
+					c.Abort()
+					return
+				}
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Set(gin.AuthUserKey, userID)
+			c.Set(AuthUserName, idpUserName)
+			c.Next()
+			return
+		}
+
 		if len(idpUserName) == 0 {
 			idpUserName = idpFullName
 		}
+		// the subjects of the providers may collide, the users of the other providers are told apart by the provider name
+		if issuer.name != "" {
+			idpUserID = issuer.name + ":" + idpUserID
+		}
 
 		var limiters *UserLimiters
 		userLimiters.SetIf(idpUserID, func(value *UserLimiters, found bool) (*UserLimiters, bool) {
@@ -188,12 +270,15 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 		// still occur. The change here will still limit the number of db connections from here at a time, but they
 		// will not necessarily be serialized.
 		canceled := limiters.Single.Do(c, func() {
-			cachedUserId, err = o.Api.Redis.Get(c.Request.Context(), prefixId).Result()
-			if err != nil {
-				if errors.Is(err, redis.Nil) {
-					o.Logger.Debugf("user id doesn't exits in the cache:%s", err)
-				} else {
-					o.Logger.Warnf("failed to find user in the cache:%s", err)
+			// the users are not cached without redis, in dev mode
+			if o.Api.Redis != nil {
+				cachedUserId, err = o.Api.Redis.Get(c.Request.Context(), prefixId).Result()
+				if err != nil {
+					if errors.Is(err, redis.Nil) {
+						o.Logger.Debugf("user id doesn't exits in the cache:%s", err)
+					} else {
+						o.Logger.Warnf("failed to find user in the cache:%s", err)
+					}
 				}
 			}
 
@@ -210,7 +295,9 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 					return
 				}
 				cachedUserId = userId.String()
-				o.Api.Redis.Set(c.Request.Context(), prefixId, userId.String(), handlers.CacheExp)
+				if o.Api.Redis != nil {
+					o.Api.Redis.Set(c.Request.Context(), prefixId, userId.String(), handlers.CacheExp)
+				}
 			}
 		})
 
@@ -230,6 +317,32 @@ func ValidateJWT(ctx context.Context, o APIRouterOptions, jwksURI string, nexodu
 	}, nil
 }
 
+// ValidateAPIKey replaces the token of an api key in the authorization header with an access token of the
+// apiserver limited to the scopes of the key, which ValidateJWT then authorizes. Behind envoy the token is
+// already replaced by the ext_authz Check.
+func ValidateAPIKey(o APIRouterOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, found := strings.CutPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+		if !found || !strings.HasPrefix(token, handlers.APIKeyPrefix) {
+			c.Next()
+			return
+		}
+		accessToken, err := o.Api.APIKeyJWT(c.Request.Context(), token)
+		if err != nil {
+			var apiResponseError *handlers.ApiResponseError
+			if errors.As(err, &apiResponseError) {
+				c.JSON(apiResponseError.Status, apiResponseError.Body)
+			} else {
+				o.Api.SendInternalServerError(c, err)
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Header.Set("Authorization", "Bearer "+accessToken)
+		c.Next()
+	}
+}
+
 func getURLAsText(ctx context.Context, jwksURL string) (string, error) {
 
 	httpClient := http.DefaultClient
@@ -258,3 +371,43 @@ func NoCacheMiddleware(c *gin.Context) {
 	c.Header("Cache-Control", "no-cache, no-store, max-age=0, must-revalidate")
 	c.Next()
 }
+
+// agentVersionExemptRoutes are the routes an outdated agent still needs, to refresh its token and to find
+// the release to upgrade to.
+var agentVersionExemptRoutes = []string{
+	"/device/certs",
+	"/device/token",
+	"/organizations/:id/agent-release",
+}
+
+// AgentVersionMiddleware rejects requests from nexd agents older than minVersion with an HTTP 426
+// that tells the user how to upgrade. Agents that do not report a release version, like development
+// builds, are always allowed.
+func AgentVersionMiddleware(minVersion string, instructions string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if minVersion == "" || isAgentVersionExempt(c.FullPath()) {
+			c.Next()
+			return
+		}
+		agent, _, _ := strings.Cut(c.Request.UserAgent(), " ")
+		version, found := strings.CutPrefix(agent, "nexd/")
+		if !found {
+			c.Next()
+			return
+		}
+		if cmp, err := util.CompareVersions(version, minVersion); err == nil && cmp < 0 {
+			c.AbortWithStatusJSON(http.StatusUpgradeRequired, models.NewUpgradeRequiredError(version, minVersion, instructions))
+			return
+		}
+		c.Next()
+	}
+}
+
+func isAgentVersionExempt(route string) bool {
+	for _, exempt := range agentVersionExemptRoutes {
+		if strings.HasSuffix(route, exempt) {
+			return true
+		}
+	}
+	return false
+}
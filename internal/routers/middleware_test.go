@@ -0,0 +1,60 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenIssuerOf(t *testing.T) {
+	require := require.New(t)
+	issuers := []tokenIssuer{
+		{issuer: "https://auth.example.com/realms/nexodus", audience: "account"},
+		{name: "corp", issuer: "https://sso.corp.example.com", audience: "nexodus"},
+	}
+	token := func(iss string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": iss, "sub": "1234"}).SignedString([]byte("key"))
+		require.NoError(err)
+		return signed
+	}
+
+	require.Equal("corp", tokenIssuerOf(issuers, token("https://sso.corp.example.com")).name)
+	require.Equal("", tokenIssuerOf(issuers, token("https://auth.example.com/realms/nexodus")).name)
+	// the tokens of the apiserver and the unknown issuers are checked against the default provider
+	require.Equal("", tokenIssuerOf(issuers, token("https://api.example.com")).name)
+	require.Equal("", tokenIssuerOf(issuers, "not-a-jwt").name)
+}
+
+func TestAgentVersionMiddleware(t *testing.T) {
+	require := require.New(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	versionCheck := AgentVersionMiddleware("0.2.0", "upgrade nexd")
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	r.GET("/api/v1/devices/:id", versionCheck, ok)
+	r.GET("/api/v1/organizations/:id/agent-release", versionCheck, ok)
+	r.GET("/api/organizations/:id/agent-release", versionCheck, ok)
+	r.POST("/device/token", versionCheck, ok)
+
+	serve := func(method string, path string, userAgent string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("User-Agent", userAgent)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	require.Equal(http.StatusUpgradeRequired, serve(http.MethodGet, "/api/v1/devices/1234", "nexd/0.1.0 (linux)"))
+	require.Equal(http.StatusOK, serve(http.MethodGet, "/api/v1/devices/1234", "nexd/0.2.0 (linux)"))
+	require.Equal(http.StatusOK, serve(http.MethodGet, "/api/v1/devices/1234", "nexctl/0.1.0"))
+	// an outdated agent can still refresh its token and find the release to upgrade to
+	require.Equal(http.StatusOK, serve(http.MethodGet, "/api/v1/organizations/1234/agent-release", "nexd/0.1.0 (linux)"))
+	require.Equal(http.StatusOK, serve(http.MethodGet, "/api/organizations/1234/agent-release", "nexd/0.1.0 (linux)"))
+	require.Equal(http.StatusOK, serve(http.MethodPost, "/device/token", "nexd/0.1.0 (linux)"))
+}
@@ -0,0 +1,148 @@
+package routers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	csmap "github.com/mhmtszr/concurrent-swiss-map"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimitGroup is the route group whose rate limit applies to the route groups without one
+const DefaultRateLimitGroup = "default"
+
+// rateLimitSweepInterval is how often the token buckets of the idle clients are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is the token bucket of a client on a route group, Rate requests per second with bursts of up to Burst
+// requests. A zero Rate leaves the route group unlimited.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseRateLimits parses the rate limits of the route groups, in the group=rate:burst format, like devices=5:20.
+// The group is the first path segment after the api version, or default for the groups without a limit of their own.
+func ParseRateLimits(values []string) (map[string]RateLimit, error) {
+	limits := map[string]RateLimit{}
+	for _, value := range values {
+		group, limit, found := strings.Cut(value, "=")
+		if !found || group == "" {
+			return nil, fmt.Errorf("invalid rate limit '%s', the format is group=rate:burst", value)
+		}
+		r, b, found := strings.Cut(limit, ":")
+		perSecond, err := strconv.ParseFloat(r, 64)
+		if err != nil || perSecond < 0 || math.IsInf(perSecond, 0) {
+			return nil, fmt.Errorf("invalid rate of the rate limit '%s'", value)
+		}
+		burst := int(math.Ceil(perSecond))
+		if found {
+			burst, err = strconv.Atoi(b)
+			if err != nil || burst < 1 {
+				return nil, fmt.Errorf("invalid burst of the rate limit '%s'", value)
+			}
+		}
+		limits[group] = RateLimit{Rate: perSecond, Burst: burst}
+	}
+	return limits, nil
+}
+
+// RateLimitMiddleware limits the rate of the requests of each user and device on each route group with a token
+// bucket, the requests over the limit are rejected with an HTTP 429. It runs after ValidateJWT, the clients are
+// told apart by the device of their device token, or else by their user.
+// NOTE: The buckets are kept per apiserver process, with replicas a client gets the limit on each replica.
+func RateLimitMiddleware(limits map[string]RateLimit) gin.HandlerFunc {
+	if len(limits) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	buckets := csmap.Create[string, *rate.Limiter](
+		csmap.WithShardCount[string, *rate.Limiter](2*uint64(runtime.GOMAXPROCS(-1))),
+		csmap.WithSize[string, *rate.Limiter](1000),
+	)
+	lastSweep := atomic.Int64{}
+	lastSweep.Store(time.Now().UnixNano())
+
+	return func(c *gin.Context) {
+		group := ""
+		if path := apiPolicyPath(c.Request.URL.Path); len(path) > 1 {
+			group = path[1]
+		}
+		limit, ok := limits[group]
+		if !ok {
+			limit, ok = limits[DefaultRateLimitGroup]
+		}
+		client := rateLimitClient(c)
+		if !ok || limit.Rate == 0 || client == "" {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		if last := lastSweep.Load(); now.Sub(time.Unix(0, last)) > rateLimitSweepInterval && lastSweep.CompareAndSwap(last, now.UnixNano()) {
+			sweepRateLimits(buckets)
+		}
+
+		var bucket *rate.Limiter
+		buckets.SetIf(group+"|"+client, func(value *rate.Limiter, found bool) (*rate.Limiter, bool) {
+			if !found {
+				value = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+			}
+			bucket = value
+			return value, !found
+		})
+
+		reservation := bucket.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, models.NewBaseError("too many requests"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient returns the identity the requests are rate limited by, empty when the request is not authenticated
+func rateLimitClient(c *gin.Context) string {
+	if claims, ok := c.Get("_nexodus.Claims"); ok {
+		if claims, ok := claims.(map[string]interface{}); ok && claims["scope"] == "device-token" {
+			if id, ok := claims["jti"].(string); ok && id != "" {
+				return "device:" + id
+			}
+		}
+	}
+	if userID, ok := c.Get(gin.AuthUserKey); ok {
+		if userID, ok := userID.(uuid.UUID); ok {
+			return "user:" + userID.String()
+		}
+	}
+	return ""
+}
+
+// sweepRateLimits drops the full token buckets, a client whose bucket has refilled gets a new one on its next request
+func sweepRateLimits(buckets *csmap.CsMap[string, *rate.Limiter]) {
+	var full []string
+	buckets.Range(func(key string, value *rate.Limiter) bool {
+		if value.Tokens() >= float64(value.Burst()) {
+			full = append(full, key)
+		}
+		return false
+	})
+	for _, key := range full {
+		buckets.DeleteIf(key, func(value *rate.Limiter) bool {
+			return value.Tokens() >= float64(value.Burst())
+		})
+	}
+}
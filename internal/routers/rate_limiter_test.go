@@ -0,0 +1,84 @@
+package routers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nexodus-io/nexodus/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	require := require.New(t)
+	limits, err := ParseRateLimits([]string{"default=10:20", "devices=0.5", "fflags=0"})
+	require.NoError(err)
+	require.Equal(map[string]RateLimit{
+		"default": {Rate: 10, Burst: 20},
+		"devices": {Rate: 0.5, Burst: 1},
+		"fflags":  {Rate: 0, Burst: 0},
+	}, limits)
+
+	for _, value := range []string{"devices", "=1:1", "devices=fast", "devices=-1", "devices=1:0", "devices=1:many"} {
+		_, err := ParseRateLimits([]string{value})
+		require.Error(err, value)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	require := require.New(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set(gin.AuthUserKey, uuid.MustParse(user))
+		}
+		if device := c.GetHeader("X-Device"); device != "" {
+			c.Set("_nexodus.Claims", map[string]interface{}{"scope": "device-token", "jti": device})
+		}
+	}, RateLimitMiddleware(map[string]RateLimit{
+		DefaultRateLimitGroup: {Rate: 0.001, Burst: 2},
+		"devices":             {Rate: 0.001, Burst: 1},
+		"fflags":              {Rate: 0},
+	}))
+	r.GET("/api/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	user := uuid.New().String()
+	serve := func(path string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	require.Equal(http.StatusOK, serve("/api/v1/devices", "X-User", user).Code)
+	res := serve("/api/v1/devices/1234", "X-User", user)
+	require.Equal(http.StatusTooManyRequests, res.Code)
+	require.NotEmpty(res.Header().Get("Retry-After"))
+	var body models.BaseError
+	require.NoError(json.Unmarshal(res.Body.Bytes(), &body))
+	require.Equal("too many requests", body.Error)
+	// the unversioned routes share the bucket of the versioned routes
+	require.Equal(http.StatusTooManyRequests, serve("/api/devices", "X-User", user).Code)
+
+	// the other route groups, users and devices have their own buckets
+	require.Equal(http.StatusOK, serve("/api/v1/vpcs", "X-User", user).Code)
+	require.Equal(http.StatusOK, serve("/api/v1/vpcs", "X-User", user).Code)
+	require.Equal(http.StatusTooManyRequests, serve("/api/v1/vpcs", "X-User", user).Code)
+	require.Equal(http.StatusOK, serve("/api/v1/organizations", "X-User", user).Code)
+	require.Equal(http.StatusOK, serve("/api/v1/devices", "X-User", uuid.New().String()).Code)
+	require.Equal(http.StatusOK, serve("/api/v1/devices", "X-User", user, "X-Device", uuid.New().String()).Code)
+
+	for i := 0; i < 5; i++ {
+		require.Equal(http.StatusOK, serve("/api/v1/fflags", "X-User", user).Code)
+		require.Equal(http.StatusOK, serve("/api/v1/devices").Code)
+	}
+}
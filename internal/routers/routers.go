@@ -3,6 +3,7 @@ package routers
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"github.com/go-session/session/v3"
 	"github.com/nexodus-io/nexodus/internal/docs"
 	"github.com/nexodus-io/nexodus/pkg/ginsession"
@@ -31,17 +32,43 @@ import (
 const name = "github.com/nexodus-io/nexodus/internal/routers"
 
 type APIRouterOptions struct {
-	Logger          *zap.SugaredLogger
-	Api             *handlers.API
-	ClientIdWeb     string
-	ClientIdCli     string
+	Logger      *zap.SugaredLogger
+	Api         *handlers.API
+	ClientIdWeb string
+	ClientIdCli string
+	// OidcURL is the url of the identity provider, empty when only the tokens of the apiserver are accepted, like in dev mode
 	OidcURL         string
 	OidcBackchannel string
 	InsecureTLS     bool
-	BrowserFlow     *agent.OidcAgent
-	DeviceFlow      *agent.OidcAgent
-	Store           storage.Store
-	SessionStore    session.ManagerStore
+	// BrowserFlow and DeviceFlow log in with the identity providers, their routes are not served when nil
+	BrowserFlow  *agent.MultiOidcAgent
+	DeviceFlow   *agent.MultiOidcAgent
+	Store        storage.Store
+	SessionStore session.ManagerStore
+	// MinAgentVersion is the oldest nexd version allowed to use the api, empty to allow all versions
+	MinAgentVersion string
+	// OidcProviders are the identity providers whose access tokens are accepted besides the one of OidcURL
+	OidcProviders []OidcProvider
+	// AgentUpgradeInstructions are returned to nexd agents older than MinAgentVersion
+	AgentUpgradeInstructions string
+	// UnversionedAPISunset is when the unversioned /api routes are to be removed, announced in their Sunset header when not zero
+	UnversionedAPISunset time.Time
+	// RateLimits are the rate limits of the users and devices on the api route groups, keyed by route group, no limits when empty
+	RateLimits map[string]RateLimit
+	// AuthMiddleware authenticates, authorizes and rate limits the requests of the api routes, created with NewAuthMiddleware when nil
+	AuthMiddleware []gin.HandlerFunc
+}
+
+// OidcProvider is an identity provider whose access tokens are accepted by the api
+type OidcProvider struct {
+	// Name identifies the provider, the users of the provider are told apart from the users of the other providers by it
+	Name string
+	// Issuer is the url of the provider, the issuer of its tokens
+	Issuer string
+	// Backchannel is the backend address of the provider, when the apiserver reaches it at another address than Issuer
+	Backchannel string
+	// Audience is the audience of the access tokens of the provider, account when empty
+	Audience string
 }
 
 func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error) {
@@ -75,40 +102,49 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 
 	r.GET("/openapi/*any", ginSwagger.WrapHandler(swaggerFiles.Handler), loggerMiddleware)
 
-	deviceGroup := r.Group("/device", loggerMiddleware)
+	agentVersionMiddleware := AgentVersionMiddleware(o.MinAgentVersion, o.AgentUpgradeInstructions)
+
+	deviceGroup := r.Group("/device", loggerMiddleware, agentVersionMiddleware)
 	{
-		deviceGroup.POST("/login/start", o.DeviceFlow.DeviceStart)
+		if o.DeviceFlow != nil {
+			deviceGroup.POST("/login/start", o.DeviceFlow.DeviceStart)
+			deviceGroup.POST("/login/authorize", o.DeviceFlow.DeviceAuthorize)
+			deviceGroup.POST("/login/poll", o.DeviceFlow.DevicePoll)
+		}
 		deviceGroup.GET("/certs", o.Api.Certs)
+		deviceGroup.POST("/token", o.Api.ServiceAccountToken)
 	}
-	webGroup := r.Group("/web", loggerMiddleware)
-	{
+	if o.BrowserFlow != nil {
+		webGroup := r.Group("/web", loggerMiddleware)
+		o.BrowserFlow.SetSessionStore(o.SessionStore)
 		webGroup.Use(o.BrowserFlow.OriginVerifier())
 		webGroup.Use(ginsession.New(
 			session.SetCookieName(handlers.SESSION_ID_COOKIE_NAME),
 			session.SetStore(o.SessionStore)))
+		webGroup.GET("/providers", o.BrowserFlow.Providers)
 		webGroup.GET("/login/start", o.BrowserFlow.LoginStart)
 		webGroup.GET("/login/end", o.BrowserFlow.LoginEnd)
 		webGroup.GET("/user_info", o.BrowserFlow.UserInfo)
 		webGroup.GET("/claims", o.BrowserFlow.Claims)
-		webGroup.GET("/logout", o.BrowserFlow.Logout)
+		webGroup.GET("/csrf_token", agent.CSRFToken)
+		webGroup.GET("/logout", agent.CSRFMiddleware(), o.BrowserFlow.Logout)
 		// web.GET("/check_auth", o.BrowserFlow.CheckAuth)
-		webGroup.POST("/refresh", o.BrowserFlow.Refresh)
+		webGroup.POST("/refresh", agent.CSRFMiddleware(), o.BrowserFlow.Refresh)
+		webGroup.POST("/backchannel_logout", o.BrowserFlow.BackchannelLogout)
 	}
-	apiGroup := r.Group("/api", loggerMiddleware)
-	{
-		api := o.Api
-		nexodusJWKS, err := api.JSONWebKeySet()
-		if err != nil {
-			return nil, err
-		}
-
-		validateJWT, err := newValidateJWT(ctx, o, string(nexodusJWKS))
+	api := o.Api
+	authMiddleware := o.AuthMiddleware
+	if authMiddleware == nil {
+		authMiddleware, err = NewAuthMiddleware(ctx, o)
 		if err != nil {
 			return nil, err
 		}
-
-		apiGroup.Use(validateJWT)
-
+	}
+	// the unversioned routes are aliases of the current version, kept for the agents and the clients that predate the versioned api
+	for _, apiGroup := range []*gin.RouterGroup{
+		r.Group("/api/"+APIVersion, append([]gin.HandlerFunc{loggerMiddleware, agentVersionMiddleware, APIVersionMiddleware(APIVersion)}, authMiddleware...)...),
+		r.Group("/api", append([]gin.HandlerFunc{loggerMiddleware, agentVersionMiddleware, UnversionedAPIMiddleware(o.UnversionedAPISunset)}, authMiddleware...)...),
+	} {
 		// Feature Flags
 		apiGroup.GET("fflags", api.ListFeatureFlags)
 		apiGroup.GET("fflags/:name", api.GetFeatureFlag)
@@ -123,8 +159,34 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 		apiGroup.GET("/organizations", api.ListOrganizations)
 		apiGroup.POST("/organizations", api.CreateOrganization)
 		apiGroup.GET("/organizations/:id", api.GetOrganizations)
+		apiGroup.PATCH("/organizations/:id", api.UpdateOrganization)
 		apiGroup.DELETE("/organizations/:id", api.DeleteOrganization)
 
+		apiGroup.GET("/organizations/:id/agent-release", api.GetAgentRelease)
+		apiGroup.GET("/organizations/:id/audit", api.ListAuditEvents)
+		apiGroup.GET("/organizations/:id/connectivity", api.ListOrganizationConnectivity)
+		apiGroup.GET("/organizations/:id/devices", api.ListDevicesInOrganization)
+		apiGroup.GET("/organizations/:id/ip-exclusion-ranges", api.ListIPExclusionRanges)
+		apiGroup.GET("/organizations/:id/ip-exclusion-ranges/:range_id", api.GetIPExclusionRange)
+		apiGroup.POST("/organizations/:id/ip-exclusion-ranges", api.CreateIPExclusionRange)
+		apiGroup.PATCH("/organizations/:id/ip-exclusion-ranges/:range_id", api.UpdateIPExclusionRange)
+		apiGroup.DELETE("/organizations/:id/ip-exclusion-ranges/:range_id", api.DeleteIPExclusionRange)
+		apiGroup.GET("/organizations/:id/dns-records", api.ListDNSRecords)
+		apiGroup.GET("/organizations/:id/dns-records/:record_id", api.GetDNSRecord)
+		apiGroup.POST("/organizations/:id/dns-records", api.CreateDNSRecord)
+		apiGroup.PATCH("/organizations/:id/dns-records/:record_id", api.UpdateDNSRecord)
+		apiGroup.DELETE("/organizations/:id/dns-records/:record_id", api.DeleteDNSRecord)
+		apiGroup.GET("/organizations/:id/ipam", api.GetOrganizationIPAM)
+		apiGroup.GET("/organizations/:id/service-accounts", api.ListServiceAccounts)
+		apiGroup.GET("/organizations/:id/service-accounts/:account_id", api.GetServiceAccount)
+		apiGroup.POST("/organizations/:id/service-accounts", api.CreateServiceAccount)
+		apiGroup.DELETE("/organizations/:id/service-accounts/:account_id", api.DeleteServiceAccount)
+		apiGroup.GET("/organizations/:id/webhooks", api.ListWebhooks)
+		apiGroup.GET("/organizations/:id/webhooks/:webhook_id", api.GetWebhook)
+		apiGroup.POST("/organizations/:id/webhooks", api.CreateWebhook)
+		apiGroup.PATCH("/organizations/:id/webhooks/:webhook_id", api.UpdateWebhook)
+		apiGroup.DELETE("/organizations/:id/webhooks/:webhook_id", api.DeleteWebhook)
+		apiGroup.GET("/organizations/:id/webhooks/:webhook_id/deliveries", api.ListWebhookDeliveries)
 		apiGroup.GET("/organizations/:id/users", api.ListOrganizationUsers)
 		apiGroup.GET("/organizations/:id/users/:uid", api.GetOrganizationUser)
 		apiGroup.DELETE("/organizations/:id/users/:uid", api.DeleteOrganizationUser)
@@ -141,6 +203,9 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 		apiGroup.PATCH("/vpcs/:id", api.UpdateVPC)
 		apiGroup.POST("/vpcs", api.CreateVPC)
 		apiGroup.DELETE("/vpcs/:id", api.DeleteVPC)
+		apiGroup.GET("/vpcs/:id/ip-reservations", api.ListIPReservationsInVPC)
+		apiGroup.POST("/vpcs/:id/ip-reservations", api.CreateIPReservation)
+		apiGroup.DELETE("/vpcs/:id/ip-reservations/:reservation_id", api.DeleteIPReservation)
 
 		// Registration Tokens
 		apiGroup.GET("/reg-keys", api.ListRegKeys)
@@ -149,12 +214,24 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 		apiGroup.PATCH("/reg-keys/:id", api.UpdateRegKey)
 		apiGroup.DELETE("/reg-keys/:id", api.DeleteRegKey)
 
+		// API Keys
+		apiGroup.GET("/api-keys", api.ListAPIKeys)
+		apiGroup.GET("/api-keys/:id", api.GetAPIKey)
+		apiGroup.POST("/api-keys", api.CreateAPIKey)
+		apiGroup.DELETE("/api-keys/:id", api.DeleteAPIKey)
+
 		// Devices
 		apiGroup.GET("/devices", api.ListDevices)
 		apiGroup.GET("/devices/:id", api.GetDevice)
 		apiGroup.PATCH("/devices/:id", api.UpdateDevice)
 		apiGroup.POST("/devices", api.CreateDevice)
+		apiGroup.POST("/devices/batch-delete", api.BatchDeleteDevices)
+		apiGroup.POST("/devices/batch-labels", api.BatchUpdateDeviceLabels)
 		apiGroup.DELETE("/devices/:id", api.DeleteDevice)
+		apiGroup.POST("/devices/:id/approve", api.ApproveDevice)
+		apiGroup.POST("/devices/:id/rotate-key", api.RotateDeviceKey)
+		apiGroup.PUT("/devices/:id/security-groups/:security_group_id", api.AttachDeviceSecurityGroup)
+		apiGroup.DELETE("/devices/:id/security-groups/:security_group_id", api.DetachDeviceSecurityGroup)
 
 		// Device Metadata
 		apiGroup.GET("/devices/:id/metadata", api.ListDeviceMetadata)
@@ -162,6 +239,8 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 		apiGroup.PUT("/devices/:id/metadata/:key", api.UpdateDeviceMetadataKey)
 		apiGroup.DELETE("/devices/:id/metadata/:key", api.DeleteDeviceMetadataKey)
 		apiGroup.DELETE("/devices/:id/metadata", api.DeleteDeviceMetadata)
+		apiGroup.PUT("/devices/:id/connectivity", api.ReportDeviceConnectivity)
+		apiGroup.POST("/devices/:id/hole-punch", api.CreateHolePunch)
 
 		// Sites
 		apiGroup.GET("/sites", api.ListSites)
@@ -177,19 +256,45 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 		apiGroup.PATCH("/security-groups/:id", api.UpdateSecurityGroup)
 		apiGroup.DELETE("/security-groups/:id", api.DeleteSecurityGroup)
 
+		// Services
+		apiGroup.GET("/services", api.ListServices)
+		apiGroup.GET("/services/:id", api.GetService)
+		apiGroup.POST("/services", api.CreateService)
+		apiGroup.PATCH("/services/:id", api.UpdateService)
+		apiGroup.DELETE("/services/:id", api.DeleteService)
+
 		// List / Watch Event API used by nexd
 		apiGroup.POST("/vpcs/:id/events", api.WatchEvents)
 		apiGroup.GET("/vpcs/:id/devices", api.ListDevicesInVPC)
 		apiGroup.GET("/vpcs/:id/sites", api.ListSitesInVPC)
 		apiGroup.GET("/vpcs/:id/metadata", api.ListMetadataInVPC)
 		apiGroup.GET("/vpcs/:id/security-groups", api.ListSecurityGroupsInVPC)
+		apiGroup.GET("/vpcs/:id/services", api.ListServicesInVPC)
+		apiGroup.GET("/vpcs/:id/dns-records", api.ListDNSRecordsInVPC)
 
 		apiGroup.POST("/ca/sign", api.SignCSR)
+
+		// Admin, reserved to the controller admins
+		apiGroup.GET("/admin/organizations", api.AdminListOrganizations)
+		apiGroup.DELETE("/admin/organizations/:id", api.AdminDeleteOrganization)
+		apiGroup.GET("/admin/devices", api.AdminListDevices)
+		apiGroup.DELETE("/admin/devices/:id", api.AdminDeleteDevice)
+		apiGroup.GET("/admin/users", api.AdminListUsers)
+		apiGroup.DELETE("/admin/users/:id", api.AdminDeleteUser)
+		apiGroup.POST("/admin/users/:id/impersonate", api.AdminImpersonateUser)
+		apiGroup.GET("/admin/audit", api.AdminListAuditEvents)
+		apiGroup.GET("/admin/fflags", api.AdminListFeatureFlags)
+		apiGroup.POST("/admin/fflags", api.AdminCreateFeatureFlag)
+		apiGroup.PATCH("/admin/fflags/:name", api.AdminUpdateFeatureFlag)
+		apiGroup.DELETE("/admin/fflags/:name", api.AdminDeleteFeatureFlag)
 	}
 
 	privateGroup := r.Group("/private")
 	{
 		privateGroup.GET("/gc", o.Api.GarbageCollect, loggerMiddleware)
+		privateGroup.GET("/organizations/:id/quota", o.Api.GetOrganizationQuota, loggerMiddleware)
+		privateGroup.PUT("/organizations/:id/quota", o.Api.UpdateOrganizationQuota, loggerMiddleware)
+		privateGroup.GET("/ipam/stale-leases", o.Api.ListStaleLeases, loggerMiddleware)
 		privateGroup.GET("/ready", o.Api.Ready)
 		privateGroup.GET("/live", o.Api.Live)
 	}
@@ -197,6 +302,23 @@ func NewAPIRouter(ctx context.Context, o APIRouterOptions) (*gin.Engine, error)
 	return r, nil
 }
 
+// NewAuthMiddleware returns the middleware that authenticates, authorizes and rate limits the requests of the api
+// routes, the versioned and unversioned routes and the gRPC API share it so that they share the token buckets of a client
+func NewAuthMiddleware(ctx context.Context, o APIRouterOptions) ([]gin.HandlerFunc, error) {
+	nexodusJWKS, err := o.Api.JSONWebKeySet()
+	if err != nil {
+		return nil, err
+	}
+	validateJWT, err := newValidateJWT(ctx, o, string(nexodusJWKS))
+	if err != nil {
+		return nil, err
+	}
+	return []gin.HandlerFunc{ValidateAPIKey(o), validateJWT, RateLimitMiddleware(o.RateLimits)}, nil
+}
+
+// defaultAudience is the audience of the access tokens of the provider of OidcURL
+const defaultAudience = "account"
+
 func newValidateJWT(ctx context.Context, o APIRouterOptions, nexodusJWKS string) (func(*gin.Context), error) {
 	if o.InsecureTLS {
 		transport := &http.Transport{
@@ -207,14 +329,38 @@ func newValidateJWT(ctx context.Context, o APIRouterOptions, nexodusJWKS string)
 		ctx = oidc.ClientContext(ctx, client)
 	}
 
-	oidcURL := o.OidcURL
-	if o.OidcBackchannel != "" {
-		ctx = oidc.InsecureIssuerURLContext(ctx, o.OidcURL)
-		oidcURL = o.OidcBackchannel
+	var issuers []tokenIssuer
+	if o.OidcURL != "" {
+		jwksURI, err := discoverJWKSURI(ctx, o.OidcURL, o.OidcBackchannel)
+		if err != nil {
+			return nil, err
+		}
+		issuers = append(issuers, tokenIssuer{issuer: o.OidcURL, jwksURI: jwksURI, audience: defaultAudience})
+	}
+	for _, p := range o.OidcProviders {
+		jwksURI, err := discoverJWKSURI(ctx, p.Issuer, p.Backchannel)
+		if err != nil {
+			return nil, fmt.Errorf("oidc provider %s: %w", p.Name, err)
+		}
+		audience := p.Audience
+		if audience == "" {
+			audience = defaultAudience
+		}
+		issuers = append(issuers, tokenIssuer{name: p.Name, issuer: p.Issuer, jwksURI: jwksURI, audience: audience})
+	}
+
+	return ValidateJWT(ctx, o, issuers, nexodusJWKS)
+}
+
+// discoverJWKSURI returns the url of the key set of an oidc provider
+func discoverJWKSURI(ctx context.Context, oidcURL string, backchannel string) (string, error) {
+	if backchannel != "" {
+		ctx = oidc.InsecureIssuerURLContext(ctx, oidcURL)
+		oidcURL = backchannel
 	}
 	provider, err := oidc.NewProvider(ctx, oidcURL)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var claims struct {
@@ -222,10 +368,9 @@ func newValidateJWT(ctx context.Context, o APIRouterOptions, nexodusJWKS string)
 	}
 	err = provider.Claims(&claims)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	return ValidateJWT(ctx, o, claims.JWKSUri, nexodusJWKS)
+	return claims.JWKSUri, nil
 }
 
 func newPrometheus() *ginprometheus.Prometheus {
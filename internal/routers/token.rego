@@ -10,7 +10,7 @@ valid_nexodus_token if {
 }
 
 valid_keycloak_token if {
-	[valid, _, _] := io.jwt.decode_verify(input.access_token, {"cert": input.jwks, "aud": "account"})
+	[valid, _, _] := io.jwt.decode_verify(input.access_token, {"cert": input.jwks, "aud": input.audience})
 	valid == true
 }
 
@@ -22,111 +22,170 @@ valid_token if {
 	valid_keycloak_token
 }
 
+# service accounts are accepted like the users of their organization, api keys like their user
+valid_user_token if {
+	valid_keycloak_token
+}
+
+valid_user_token if {
+	apiserver_token
+	contains(token_payload.scope, "service-account")
+}
+
+valid_user_token if {
+	apiserver_token
+	contains(token_payload.scope, "api-key")
+}
+
+# the tokens issued by the apiserver, only they carry the credential of a service account or an api key
+default apiserver_token := false
+
+apiserver_token if {
+	valid_nexodus_token
+	token_payload.iss == input.nexodus_issuer
+}
+
 default allow := false
 
 allow if {
 	"organizations" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:organizations")
 }
 
 allow if {
 	"organizations" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:organizations")
 }
 
 allow if {
 	"vpcs" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:organizations")
 }
 
 allow if {
 	"vpcs" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:organizations")
 }
 
 allow if {
 	"invitations" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:organizations")
 }
 
 allow if {
 	"invitations" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:organizations")
 }
 
 allow if {
 	input.path[1] in ["devices", "sites"]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:devices")
 }
 
 allow if {
 	input.path[1] in ["devices", "sites"]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
+	contains(token_payload.scope, "write:devices")
+}
+
+allow if {
+	"services" = input.path[1]
+	action_is_read
+	valid_user_token
+	contains(token_payload.scope, "read:devices")
+}
+
+allow if {
+	"services" = input.path[1]
+	action_is_write
+	valid_user_token
 	contains(token_payload.scope, "write:devices")
 }
 
 allow if {
 	"users" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:users")
 }
 
 allow if {
 	"users" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:users")
 }
 
 allow if {
 	"security-groups" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:organizations")
 }
 
 allow if {
 	"security-groups" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:organizations")
 }
 
 allow if {
 	"fflags" = input.path[1]
-	valid_keycloak_token
+	valid_user_token
+}
+
+# the agents read the feature flags of their organization
+allow if {
+	"fflags" = input.path[1]
+	action_is_read
+	valid_nexodus_token
+	contains(token_payload.scope, "device-token")
 }
 
 allow if {
 	"reg-keys" = input.path[1]
 	action_is_read
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "read:organizations")
 }
 
 allow if {
 	"reg-keys" = input.path[1]
 	action_is_write
-	valid_keycloak_token
+	valid_user_token
 	contains(token_payload.scope, "write:organizations")
 }
 
+allow if {
+	"api-keys" = input.path[1]
+	action_is_read
+	valid_user_token
+	contains(token_payload.scope, "read:users")
+}
+
+allow if {
+	"api-keys" = input.path[1]
+	action_is_write
+	valid_user_token
+	contains(token_payload.scope, "write:users")
+}
+
 # reg token can get its own token
 allow if {
 	valid_nexodus_token
@@ -180,9 +239,54 @@ allow if {
 	input.path[1] in ["devices", "sites"]
 }
 
+# device tokens can report the connectivity of their own device to its peers
+allow if {
+	valid_nexodus_token
+	contains(token_payload.scope, "device-token")
+	input.method == "PUT"
+	count(input.path) == 4
+	"devices" = input.path[1]
+	token_payload.jti = input.path[2]
+	"connectivity" = input.path[3]
+}
+
+# device tokens can rotate the key of their own device
+allow if {
+	valid_nexodus_token
+	contains(token_payload.scope, "device-token")
+	input.method == "POST"
+	count(input.path) == 4
+	"devices" = input.path[1]
+	token_payload.jti = input.path[2]
+	"rotate-key" = input.path[3]
+}
+
+# device tokens can request the hole punches of their own device and delete them once handled
+allow if {
+	valid_nexodus_token
+	contains(token_payload.scope, "device-token")
+	input.method == "POST"
+	count(input.path) == 4
+	"devices" = input.path[1]
+	token_payload.jti = input.path[2]
+	"hole-punch" = input.path[3]
+}
+
+# device tokens can publish the metadata of their own device, like the latencies to their peers
+allow if {
+	valid_nexodus_token
+	contains(token_payload.scope, "device-token")
+	input.method in ["PUT", "DELETE"]
+	count(input.path) == 5
+	"devices" = input.path[1]
+	token_payload.jti = input.path[2]
+	"metadata" = input.path[3]
+}
+
 allow if {
 	input.path[1] in ["organizations", "vpcs"]
 	action_is_read
+	not "webhooks" = input.path[3]
 	valid_nexodus_token
 	contains(token_payload.scope, "device-token")
 }
@@ -195,6 +299,19 @@ allow if {
 	contains(token_payload.scope, "device-token")
 }
 
+# the operators of the service can read and delete the resources of every organization and impersonate users,
+# only the roles of the primary identity provider are trusted
+controller_admin if {
+	valid_keycloak_token
+	input.provider == ""
+	"controller-admin" in token_payload.realm_access.roles
+}
+
+allow if {
+	"admin" = input.path[1]
+	controller_admin
+}
+
 allow if {
 	"ca" = input.path[1]
 	valid_token
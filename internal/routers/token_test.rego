@@ -37,12 +37,57 @@ mock_decode_verify("user-read-jwt", _) := [true, {}, {}]
 
 mock_decode("user-read-jwt") := [{}, valid_user("openid profile email read:users"), {}]
 
+mock_decode_verify("controller-admin-jwt", _) := [true, {}, {}]
+
+mock_decode("controller-admin-jwt") := [{}, object.union(valid_user("openid profile email"), {"realm_access": {"roles": ["controller-admin"]}}), {}]
+
+mock_decode_verify("device-token-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "nexodus-cert"
+}
+
+mock_decode("device-token-jwt") := [{}, object.union(valid_user("device-token"), {"jti": "1234"}), {}]
+
 mock_decode_verify("bad-jwt", _) := [false, {}, {}]
 
+mock_decode_verify("other-client-jwt", constraints) := [constraints.aud == "other-client", {}, {}]
+
+mock_decode("other-client-jwt") := [{}, valid_user("openid profile email read:organizations"), {}]
+
+mock_decode_verify("service-account-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "nexodus-cert"
+}
+
+mock_decode("service-account-jwt") := [{}, object.union(valid_user("service-account read:organizations write:organizations read:devices write:devices"), {"iss": "https://api.example.com"}), {}]
+
+mock_decode_verify("idp-service-account-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "my-cert"
+}
+
+mock_decode("idp-service-account-jwt") := [{}, object.union(valid_user("service-account read:organizations"), {"iss": "https://auth.example.com"}), {}]
+
+mock_decode_verify("api-key-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "nexodus-cert"
+}
+
+mock_decode("api-key-jwt") := [{}, object.union(valid_user("api-key read:devices read:users"), {"iss": "https://api.example.com"}), {}]
+
+mock_decode_verify("idp-api-key-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "my-cert"
+}
+
+mock_decode("idp-api-key-jwt") := [{}, object.union(valid_user("api-key read:devices"), {"iss": "https://auth.example.com"}), {}]
+
+mock_decode_verify("reg-token-jwt", constraints) := [true, {}, {}] if {
+	constraints.cert == "nexodus-cert"
+}
+
+mock_decode("reg-token-jwt") := [{}, valid_user("reg-token"), {}]
+
 test_org_get_allowed if {
 	token.allow with input.path as ["api", "organizations"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "org-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -52,6 +97,7 @@ test_org_get_member if {
 	token.allow with input.path as ["api", "organizations", "foo"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "org-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -61,6 +107,7 @@ test_org_post_allowed if {
 	token.allow with input.path as ["api", "organizations"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "org-write-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -70,6 +117,7 @@ test_org_post_with_read_scope_denied if {
 	not token.allow with input.path as ["api", "organizations"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "org-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -86,6 +134,17 @@ test_org_get_bad_jwt_denied if {
 	not token.allow with input.path as ["api", "organizations"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_org_get_other_audience_denied if {
+	not token.allow with input.path as ["api", "organizations"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.access_token as "other-client-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
 }
@@ -94,6 +153,7 @@ test_device_get_allowed if {
 	token.allow with input.path as ["api", "devices"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "device-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -103,6 +163,7 @@ test_device_post_allowed if {
 	token.allow with input.path as ["api", "devices"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "device-write-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -112,6 +173,37 @@ test_device_post_with_read_scope_denied if {
 	not token.allow with input.path as ["api", "devices"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.access_token as "device-read-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_get_allowed if {
+	token.allow with input.path as ["api", "services"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.access_token as "device-read-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_patch_allowed if {
+	token.allow with input.path as ["api", "services", "1234"]
+		with input.method as "PATCH"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.access_token as "device-write-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_post_with_read_scope_denied if {
+	not token.allow with input.path as ["api", "services"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "device-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -128,6 +220,7 @@ test_device_get_bad_jwt_denied if {
 	not token.allow with input.path as ["api", "devices"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "bad-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -137,6 +230,7 @@ test_user_get_allowed if {
 	token.allow with input.path as ["api", "users", "me"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "user-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -146,6 +240,7 @@ test_user_post_allowed if {
 	token.allow with input.path as ["api", "users", "me"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "user-write-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -155,6 +250,7 @@ test_user_post_with_read_scope_denied if {
 	not token.allow with input.path as ["api", "users", "me"]
 		with input.method as "POST"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "user-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -171,6 +267,7 @@ test_user_get_bad_jwt_denied if {
 	not token.allow with input.path as ["api", "users", "me"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "bad-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -180,6 +277,7 @@ test_get_fflags if {
 	token.allow with input.path as ["api", "fflags"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "user-read-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
@@ -196,7 +294,422 @@ test_get_fflags_bad_jwt_denied if {
 	not token.allow with input.path as ["api", "fflags"]
 		with input.method as "GET"
 		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
 		with input.access_token as "bad-jwt"
 		with io.jwt.decode_verify as mock_decode_verify
 		with io.jwt.decode as mock_decode
 }
+
+test_service_account_org_post_allowed if {
+	token.allow with input.path as ["api", "organizations"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_account_device_post_allowed if {
+	token.allow with input.path as ["api", "devices"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_account_user_post_denied if {
+	not token.allow with input.path as ["api", "users", "me"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+# the service account scope of the tokens of the other issuers is not accepted
+test_service_account_other_issuer_org_get_denied if {
+	not token.allow with input.path as ["api", "organizations"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.other.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_reg_token_org_post_denied if {
+	not token.allow with input.path as ["api", "organizations"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "reg-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_device_get_allowed if {
+	token.allow with input.path as ["api", "devices"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_device_post_denied if {
+	not token.allow with input.path as ["api", "devices"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_org_get_denied if {
+	not token.allow with input.path as ["api", "organizations"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+# the api key scope of the tokens of the other issuers is not accepted
+test_api_key_other_issuer_device_get_denied if {
+	not token.allow with input.path as ["api", "devices"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.other.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_api_keys_get_allowed if {
+	token.allow with input.path as ["api", "api-keys"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_api_keys_post_denied if {
+	not token.allow with input.path as ["api", "api-keys"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_controller_admin_allowed if {
+	token.allow with input.path as ["api", "admin", "devices"]
+		with input.method as "DELETE"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.provider as ""
+		with input.access_token as "controller-admin-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_controller_admin_secondary_provider_denied if {
+	not token.allow with input.path as ["api", "admin", "devices"]
+		with input.method as "DELETE"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.provider as "corp"
+		with input.access_token as "controller-admin-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_admin_user_denied if {
+	not token.allow with input.path as ["api", "admin", "devices"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.access_token as "user-read-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_admin_api_key_denied if {
+	not token.allow with input.path as ["api", "admin", "users"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_fflags_get_allowed if {
+	token.allow with input.path as ["api", "fflags"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_admin_fflags_denied if {
+	not token.allow with input.path as ["api", "admin", "fflags"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_organization_get_allowed if {
+	token.allow with input.path as ["api", "organizations", "1234", "devices"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_webhooks_denied if {
+	not token.allow with input.path as ["api", "organizations", "1234", "webhooks"]
+		with input.method as "GET"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_hole_punch_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "hole-punch"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_other_hole_punch_denied if {
+	not token.allow with input.path as ["api", "devices", "5678", "hole-punch"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_metadata_delete_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "metadata", "hole-punch-5678"]
+		with input.method as "DELETE"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_other_metadata_delete_denied if {
+	not token.allow with input.path as ["api", "devices", "5678", "metadata", "hole-punch-1234"]
+		with input.method as "DELETE"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_all_metadata_delete_denied if {
+	not token.allow with input.path as ["api", "devices", "1234", "metadata"]
+		with input.method as "DELETE"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_metadata_put_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "metadata", "peer-latency"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_other_metadata_put_denied if {
+	not token.allow with input.path as ["api", "devices", "5678", "metadata", "peer-latency"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_peer_traffic_put_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "metadata", "peer-traffic"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_relay_latency_put_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "metadata", "relay-latency"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_rotate_key_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "rotate-key"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_other_rotate_key_denied if {
+	not token.allow with input.path as ["api", "devices", "5678", "rotate-key"]
+		with input.method as "POST"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_own_connectivity_put_allowed if {
+	token.allow with input.path as ["api", "devices", "1234", "connectivity"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_device_token_other_connectivity_put_denied if {
+	not token.allow with input.path as ["api", "devices", "5678", "connectivity"]
+		with input.method as "PUT"
+		with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.access_token as "device-token-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_account_apiserver_token if {
+	token.apiserver_token with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_service_account_other_issuer_not_apiserver_token if {
+	not token.apiserver_token with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.other.example.com"
+		with input.access_token as "service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_idp_service_account_scope_not_apiserver_token if {
+	not token.apiserver_token with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "idp-service-account-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_api_key_apiserver_token if {
+	token.apiserver_token with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
+
+test_idp_api_key_scope_not_apiserver_token if {
+	not token.apiserver_token with input.jwks as "my-cert"
+		with input.audience as "my-audience"
+		with input.nexodus_jwks as "nexodus-cert"
+		with input.nexodus_issuer as "https://api.example.com"
+		with input.access_token as "idp-api-key-jwt"
+		with io.jwt.decode_verify as mock_decode_verify
+		with io.jwt.decode as mock_decode
+}
@@ -0,0 +1,103 @@
+package signalbus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var _ SignalBus = &RedisSignalBus{} // type check the interface is implemented.
+
+// RedisSignalBus implements a signalbus.SignalBus that is clustered using redis pub/sub, for the deployments
+// whose database does not support postgresql notify events, like CockroachDB.
+//
+// The signals only tell the subscribers to read the current state from the database, so a replica that joins
+// the cluster does not need the signals published before: its watchers list the state when they start, and
+// only wait for the signals from then on. A replica that leaves closes its event streams, the agents reconnect
+// through the load balancer to the other replicas and list the state again.
+type RedisSignalBus struct {
+	redis     *redis.Client
+	signalBus SignalBus // typically an in memory signal bus.
+	channel   string
+	logger    *zap.SugaredLogger
+}
+
+// NewRedisSignalBus creates a new RedisSignalBus
+func NewRedisSignalBus(signalBus SignalBus, client *redis.Client, logger *zap.SugaredLogger) *RedisSignalBus {
+	return &RedisSignalBus{
+		redis:     client,
+		signalBus: signalBus,
+		channel:   "signalbus",
+		logger:    logger,
+	}
+}
+
+// Notify will notify all the subscriptions created across the cluster of the given named signal.
+func (rsb *RedisSignalBus) Notify(name string) {
+	// like the PgSignalBus the signal is only sent to the in memory bus once redis sends it back
+	if err := rsb.redis.Publish(context.Background(), rsb.channel, name).Err(); err != nil {
+		rsb.logger.Info("notify failed:", err.Error())
+	}
+}
+
+func (rsb *RedisSignalBus) NotifyAll() {
+	rsb.Notify("*")
+}
+
+// Subscribe creates a subscription the named signal.
+// They are performed on the in memory bus.
+func (rsb *RedisSignalBus) Subscribe(name string) *Subscription {
+	return rsb.signalBus.Subscribe(name)
+}
+
+// Start starts the background worker that listens for the events that are sent from this process and all other
+// processes publishing to the signalbus channel. Every subscription is notified whenever the worker (re)subscribes
+// to the channel, since the signals published while this replica was disconnected from redis are lost.
+func (rsb *RedisSignalBus) Start(ctx context.Context, wg *sync.WaitGroup) {
+	pubsub := rsb.redis.Subscribe(ctx, rsb.channel)
+	util.GoWithWaitGroup(wg, func() {
+		// the blocking receive does not watch the context, closing the pubsub stops it
+		<-ctx.Done()
+		_ = pubsub.Close()
+	})
+	util.GoWithWaitGroup(wg, func() {
+		for {
+			msg, err := pubsub.ReceiveTimeout(ctx, 90*time.Second)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					// in case we have not received an event in a while... check that the connection is still good
+					rsb.logger.Debug("Received no events for 90 seconds, checking connection")
+					if err := pubsub.Ping(ctx); err != nil {
+						rsb.logger.Errorln("error checking the redis connection:", err.Error())
+					}
+					continue
+				}
+				rsb.logger.Errorln("error waiting for event:", err.Error())
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			switch msg := msg.(type) {
+			case *redis.Subscription:
+				rsb.logger.Infof("Subscribed to the redis channel: %s", msg.Channel)
+				rsb.signalBus.NotifyAll()
+			case *redis.Message:
+				rsb.logger.Infof("Received data from channel: %s, data: %s", msg.Channel, msg.Payload)
+				if msg.Payload == "*" {
+					rsb.signalBus.NotifyAll()
+				} else {
+					rsb.signalBus.Notify(msg.Payload)
+				}
+			}
+		}
+	})
+}
@@ -13,6 +13,36 @@ type State struct {
 	PrivateKey       string           `json:"private-key"`
 	ProxyRulesConfig ProxyRulesConfig `json:"proxy-rules-config"`
 	Port             int              `json:"port"`
+	// ExitNodeExcepts are the CIDRs and domains kept on the local default route when an exit node is in use
+	ExitNodeExcepts []string `json:"exit-node-excepts,omitempty"`
+	// PodAddresses are the addresses allocated to the pods of this node from its pod CIDR, by container id
+	PodAddresses map[string]string `json:"pod-addresses,omitempty"`
+	// Registration is the registration of the device with the service, nexd only validates it when it restarts
+	Registration *Registration `json:"registration,omitempty"`
+}
+
+// Registration is what nexd learned when it registered the device, so that a restart does not have to look it up again
+type Registration struct {
+	ServiceURL string `json:"service-url"`
+	// Credential identifies the credentials the device was registered with, a hash of the registration token or the username
+	Credential  string `json:"credential,omitempty"`
+	PublicKey   string `json:"public-key"`
+	DeviceID    string `json:"device-id"`
+	UserID      string `json:"user-id"`
+	DeviceToken string `json:"device-token,omitempty"`
+	// TunnelIPs are the addresses assigned to the device
+	TunnelIPs     []string        `json:"tunnel-ips,omitempty"`
+	VPC           RegistrationVPC `json:"vpc"`
+	ListenPortMin int32           `json:"listen-port-min,omitempty"`
+	ListenPortMax int32           `json:"listen-port-max,omitempty"`
+}
+
+type RegistrationVPC struct {
+	Id             string `json:"id"`
+	OrganizationId string `json:"organization-id"`
+	Description    string `json:"description,omitempty"`
+	Ipv4Cidr       string `json:"ipv4-cidr,omitempty"`
+	Ipv6Cidr       string `json:"ipv6-cidr,omitempty"`
 }
 
 type ProxyRulesConfig struct {
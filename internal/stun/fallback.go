@@ -0,0 +1,35 @@
+package stun
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// the number of stun servers tried by a request before giving up
+const maxFallbackServers = 3
+
+// RequestWithFallback sends a binding request to the stun servers in order, skipping the excluded servers,
+// until one of them answers. Returns the reflexive address and the server that answered.
+func RequestWithFallback(logger *zap.SugaredLogger, srcPort int, exclude ...string) (netip.AddrPort, string, error) {
+	tried := 0
+	lastErr := fmt.Errorf("no stun server available")
+	for _, server := range Servers() {
+		if slices.Contains(exclude, server) {
+			continue
+		}
+		if tried == maxFallbackServers {
+			break
+		}
+		tried++
+		addr, err := Request(logger, server, srcPort)
+		if err == nil {
+			return addr, server, nil
+		}
+		logger.Debugf("stun request to %s failed, trying the next stun server: %v", server, err)
+		lastErr = err
+	}
+	return netip.AddrPort{}, "", lastErr
+}
@@ -0,0 +1,69 @@
+package stun
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/pion/stun"
+	"go.uber.org/zap"
+)
+
+// DiscoverIPv6Endpoint sends a binding request over IPv6 to the stun servers in order until one of them answers.
+// IPv6 networks are usually not behind a NAT, so when the reflexive address is a global address of this host, the
+// device is reachable on it with the source port. Returns an error when the host has no global IPv6 connectivity or
+// its IPv6 traffic is translated.
+func DiscoverIPv6Endpoint(logger *zap.SugaredLogger, srcPort int) (netip.AddrPort, error) {
+	tried := 0
+	lastErr := fmt.Errorf("no stun server available")
+	for _, server := range Servers() {
+		if tried == maxFallbackServers {
+			break
+		}
+		tried++
+		mapped, err := requestIPv6(server)
+		if err != nil {
+			logger.Debugf("IPv6 stun request to %s failed, trying the next stun server: %v", server, err)
+			lastErr = err
+			continue
+		}
+		addr := mapped.Addr()
+		if !addr.IsGlobalUnicast() || addr.IsPrivate() || !isLocalAddr(addr) {
+			return netip.AddrPort{}, fmt.Errorf("the IPv6 reflexive address %s is not a global address of this host", addr)
+		}
+		logger.Debugf("IPv6 reflexive address is: %s", addr)
+		return netip.AddrPortFrom(addr, uint16(srcPort)), nil
+	}
+	return netip.AddrPort{}, lastErr
+}
+
+// requestIPv6 sends a binding request over IPv6 to the stun server from an ephemeral port
+func requestIPv6(server string) (netip.AddrPort, error) {
+	serverAddr, err := net.ResolveUDPAddr("udp6", server)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("failed to resolve the IPv6 address of the stun server %s: %w", server, err)
+	}
+	conn, err := net.ListenUDP("udp6", nil)
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	response, err := transact(conn, serverAddr, func(m *stun.Message) bool {
+		return m.Type == stun.BindingSuccess
+	})
+	if err != nil {
+		return netip.AddrPort{}, err
+	}
+	var mapped stun.XORMappedAddress
+	if err := mapped.GetFrom(response); err != nil {
+		return netip.AddrPort{}, err
+	}
+	addr, ok := netip.AddrFromSlice(mapped.IP)
+	if !ok || !addr.Is6() || addr.Is4In6() {
+		return netip.AddrPort{}, fmt.Errorf("the stun server %s did not return an IPv6 reflexive address", server)
+	}
+	return netip.AddrPortFrom(addr, uint16(mapped.Port)), nil
+}
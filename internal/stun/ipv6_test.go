@@ -0,0 +1,23 @@
+package stun
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/nexodus-io/nexodus/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIPv6(t *testing.T) {
+	require := require.New(t)
+	server, err := ListenAndStart("[::1]:0", nil)
+	if err != nil {
+		t.Skipf("IPv6 is not available: %v", err)
+	}
+	defer util.IgnoreError(server.Shutdown)
+
+	mapped, err := requestIPv6(fmt.Sprintf("[::1]:%d", server.Port))
+	require.NoError(err)
+	require.Equal(netip.IPv6Loopback(), mapped.Addr())
+}
@@ -0,0 +1,202 @@
+package stun
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/pion/stun"
+	"go.uber.org/zap"
+)
+
+// Mapping is the NAT mapping behavior as defined by RFC 5780 section 4.3
+type Mapping string
+
+const (
+	// MappingUnknown the mapping behavior could not be discovered
+	MappingUnknown Mapping = ""
+	// MappingNone the device is not behind a NAT, the reflexive address is a local address
+	MappingNone Mapping = "none"
+	// MappingEndpointIndependent the NAT reuses the mapping for all the destinations
+	MappingEndpointIndependent Mapping = "endpoint-independent"
+	// MappingAddressDependent the NAT creates a mapping per destination address
+	MappingAddressDependent Mapping = "address-dependent"
+	// MappingAddressAndPortDependent the NAT creates a mapping per destination address and port
+	MappingAddressAndPortDependent Mapping = "address-and-port-dependent"
+	// MappingEndpointDependent the NAT creates a mapping per destination address, whether it also
+	// depends on the destination port could not be tested
+	MappingEndpointDependent Mapping = "endpoint-dependent"
+)
+
+// hairpinTimeout is how long the hairpin test waits for its request to come back
+const hairpinTimeout = 2 * time.Second
+
+// EndpointDependent returns true if the NAT uses a different mapping per destination, a symmetric NAT,
+// in which case the reflexive address learned from a stun server can not be used by the peers.
+func (m Mapping) EndpointDependent() bool {
+	return m == MappingAddressDependent || m == MappingAddressAndPortDependent || m == MappingEndpointDependent
+}
+
+// NatBehavior is the behavior of the NAT in front of the device
+type NatBehavior struct {
+	// Mapping is the mapping behavior of the NAT
+	Mapping Mapping
+	// Hairpin is true if the NAT forwards the packets sent to a reflexive address from behind it
+	Hairpin bool
+	// ReflexiveAddress is the reflexive address returned by the first stun server
+	ReflexiveAddress netip.AddrPort
+}
+
+// DiscoverNatBehavior runs the RFC 5780 mapping behavior and hairpinning tests with the configured stun servers.
+// The public stun servers do not return an OTHER-ADDRESS, so the mapping tests send their requests to different
+// servers instead of the alternate addresses of one server: test I to a server, test II to a server on another host
+// and test III to the host of test II on another port, if one is configured.
+func DiscoverNatBehavior(logger *zap.SugaredLogger, srcPort int) (NatBehavior, error) {
+	behavior := NatBehavior{}
+
+	// test I
+	mapped1, server1, err := RequestWithFallback(logger, srcPort)
+	if err != nil {
+		return behavior, err
+	}
+	behavior.ReflexiveAddress = mapped1
+
+	// test II, to another server to tell if the mapping depends on the destination
+	mapped2, server2, err := RequestWithFallback(logger, srcPort, server1)
+	if err != nil {
+		return behavior, err
+	}
+
+	// test III, to the host of test II on another port to tell if the mapping depends on the destination port
+	var mapped3 netip.AddrPort
+	if mapped1 != mapped2 {
+		if server3 := otherPortServer(server2, Servers()); server3 != "" {
+			mapped3, err = Request(logger, server3, srcPort)
+			if err != nil {
+				logger.Debugf("NAT mapping test III to %s failed: %v", server3, err)
+			}
+		}
+	}
+
+	behavior.Mapping = classifyMapping(isLocalAddr(mapped1.Addr()) && int(mapped1.Port()) == srcPort, mapped1, mapped2, mapped3)
+	logger.Debugf("NAT mapping behavior is %s: %s from %s, %s from %s", behavior.Mapping, mapped1, server1, mapped2, server2)
+
+	behavior.Hairpin, err = hairpinTest(server1)
+	if err != nil {
+		logger.Debugf("NAT hairpin test failed: %v", err)
+	}
+	return behavior, nil
+}
+
+// classifyMapping returns the mapping behavior from the reflexive addresses of the mapping tests, mapped3 is not
+// valid if test III was not run.
+func classifyMapping(local bool, mapped1, mapped2, mapped3 netip.AddrPort) Mapping {
+	switch {
+	case !mapped1.IsValid() || !mapped2.IsValid():
+		return MappingUnknown
+	case local && mapped1 == mapped2:
+		return MappingNone
+	case mapped1 == mapped2:
+		return MappingEndpointIndependent
+	case !mapped3.IsValid():
+		return MappingEndpointDependent
+	case mapped2 == mapped3:
+		return MappingAddressDependent
+	default:
+		return MappingAddressAndPortDependent
+	}
+}
+
+// otherPortServer returns a server of the list on the host of server but on another port
+func otherPortServer(server string, servers []string) string {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return ""
+	}
+	for _, s := range servers {
+		h, p, err := net.SplitHostPort(s)
+		if err == nil && h == host && p != port {
+			return s
+		}
+	}
+	return ""
+}
+
+func isLocalAddr(addr netip.Addr) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if prefix, err := netip.ParsePrefix(a.String()); err == nil && prefix.Addr() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// hairpinTest runs the RFC 5780 hairpinning test: a binding request is sent to the reflexive address of a socket
+// from that socket, which receives it back if the NAT supports hairpinning.
+func hairpinTest(server string) (bool, error) {
+	serverAddr, err := net.ResolveUDPAddr("udp4", server)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve the stun server %s: %w", server, err)
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	response, err := transact(conn, serverAddr, func(m *stun.Message) bool {
+		return m.Type == stun.BindingSuccess
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get the reflexive address from %s: %w", server, err)
+	}
+	var mapped stun.XORMappedAddress
+	if err := mapped.GetFrom(response); err != nil {
+		return false, err
+	}
+
+	_, err = transact(conn, &net.UDPAddr{IP: mapped.IP, Port: mapped.Port}, func(m *stun.Message) bool {
+		return m.Type == stun.BindingRequest
+	})
+	if errors.Is(err, errTransactionTimeout) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+var errTransactionTimeout = errors.New("timed out waiting for the stun message")
+
+// transact sends a binding request to addr and waits for a message with the same transaction id that matches
+func transact(conn *net.UDPConn, addr *net.UDPAddr, match func(*stun.Message) bool) (*stun.Message, error) {
+	request := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+	if _, err := conn.WriteToUDP(request.Raw, addr); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(hairpinTimeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, errTransactionTimeout
+			}
+			return nil, err
+		}
+		m := &stun.Message{Raw: append([]byte{}, buf[:n]...)}
+		if m.Decode() != nil || m.TransactionID != request.TransactionID || !match(m) {
+			continue
+		}
+		return m, nil
+	}
+}
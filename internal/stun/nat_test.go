@@ -0,0 +1,33 @@
+package stun
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyMapping(t *testing.T) {
+	assert := assert.New(t)
+	a := netip.MustParseAddrPort("198.51.100.1:51820")
+	b := netip.MustParseAddrPort("198.51.100.1:40000")
+	c := netip.MustParseAddrPort("198.51.100.1:40001")
+
+	assert.Equal(MappingUnknown, classifyMapping(false, a, netip.AddrPort{}, netip.AddrPort{}))
+	assert.Equal(MappingNone, classifyMapping(true, a, a, netip.AddrPort{}))
+	assert.Equal(MappingEndpointIndependent, classifyMapping(false, a, a, netip.AddrPort{}))
+	assert.Equal(MappingEndpointDependent, classifyMapping(false, a, b, netip.AddrPort{}))
+	assert.Equal(MappingAddressDependent, classifyMapping(false, a, b, b))
+	assert.Equal(MappingAddressAndPortDependent, classifyMapping(false, a, b, c))
+
+	assert.False(MappingEndpointIndependent.EndpointDependent())
+	assert.True(MappingEndpointDependent.EndpointDependent())
+	assert.True(MappingAddressAndPortDependent.EndpointDependent())
+}
+
+func TestOtherPortServer(t *testing.T) {
+	assert := assert.New(t)
+	servers := []string{"stun1.example.com:3478", "stun2.example.com:3478", "stun2.example.com:3479"}
+	assert.Equal("stun2.example.com:3479", otherPortServer("stun2.example.com:3478", servers))
+	assert.Equal("", otherPortServer("stun1.example.com:3478", servers))
+}
@@ -3,6 +3,7 @@ package stun
 import (
 	_ "embed"
 	"math/rand"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -24,20 +25,29 @@ func init() {
 			servers = append(servers, strings.TrimSpace(server))
 		}
 	}
+	// spread the load of the agents over the public stun servers
+	// #nosec G404
+	rand.Shuffle(len(servers), func(i, j int) {
+		servers[i], servers[j] = servers[j], servers[i]
+	})
 	SetServers(servers)
 }
 
+// SetServers sets the stun servers, requests are sent to them in this order
 func SetServers(servers []string) {
 	stunServerMu.Lock()
 	defer stunServerMu.Unlock()
 	stunServers = servers
-	// #nosec G404
-	rand.Shuffle(len(stunServers), func(i, j int) {
-		stunServers[i], stunServers[j] = stunServers[j], stunServers[i]
-	})
 	currentStunServer = 0
 }
 
+// Servers returns the stun servers in the order requests are sent to them
+func Servers() []string {
+	stunServerMu.Lock()
+	defer stunServerMu.Unlock()
+	return slices.Clone(stunServers)
+}
+
 func NextServer() string {
 	stunServerMu.Lock()
 	defer stunServerMu.Unlock()
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 func TestNextStunServer(t *testing.T) {
@@ -28,3 +29,16 @@ func TestNextStunServer(t *testing.T) {
 		assert.GreaterOrEqual(count, 1, "Server was returned less than once: %s", server)
 	}
 }
+
+func TestSetServersKeepsOrder(t *testing.T) {
+	assert := assert.New(t)
+	defaults := Servers()
+	defer SetServers(defaults)
+
+	SetServers([]string{"relay.example.com:3478", "stun.example.com:3478"})
+	assert.Equal([]string{"relay.example.com:3478", "stun.example.com:3478"}, Servers())
+
+	// there is no server left to try when all of them are excluded
+	_, _, err := RequestWithFallback(zap.NewNop().Sugar(), 0, "relay.example.com:3478", "stun.example.com:3478")
+	assert.Error(err)
+}
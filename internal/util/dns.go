@@ -0,0 +1,29 @@
+package util
+
+import "strings"
+
+// maxDNSLabelLength is the maximum length of a single label of a DNS name
+const maxDNSLabelLength = 63
+
+// DNSLabel converts a name into a lowercase DNS label. Characters that are not valid in a
+// label are replaced with '-', and the result is trimmed to the maximum label length.
+func DNSLabel(name string) string {
+	sb := strings.Builder{}
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('-')
+		}
+	}
+	label := strings.Trim(sb.String(), "-")
+	if len(label) > maxDNSLabelLength {
+		label = strings.TrimRight(label[:maxDNSLabelLength], "-")
+	}
+	return label
+}
+
+// IsValidDNSLabel returns true if the label is a lowercase DNS label
+func IsValidDNSLabel(label string) bool {
+	return label != "" && DNSLabel(label) == label
+}
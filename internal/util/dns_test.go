@@ -0,0 +1,37 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Lowercase name", "myhost", "myhost"},
+		{"Mixed case name", "MyHost", "myhost"},
+		{"Domain name", "myhost.example.com", "myhost-example-com"},
+		{"Leading and trailing separators", "_my host_", "my-host"},
+		{"Invalid characters only", "___", ""},
+		{"Long name", strings.Repeat("a", 62) + "-b", strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DNSLabel(tt.input))
+		})
+	}
+}
+
+func TestIsValidDNSLabel(t *testing.T) {
+	assert.True(t, IsValidDNSLabel("my-host-1"))
+	assert.False(t, IsValidDNSLabel("MyHost"))
+	assert.False(t, IsValidDNSLabel("my.host"))
+	assert.False(t, IsValidDNSLabel("-myhost"))
+	assert.False(t, IsValidDNSLabel(""))
+}
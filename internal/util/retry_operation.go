@@ -3,15 +3,37 @@ package util
 import (
 	"context"
 	"errors"
+	"math/rand"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 )
 
-// RetryOperation retries the operation with a backoff policy.
+// maxBackOffMultiple caps jittered backoff intervals at a multiple of the initial interval
+const maxBackOffMultiple = 8
+
+// NewJitteredBackOff returns an exponential backoff policy that starts at initial and grows to at
+// most max, randomizing every interval so that many clients failing at the same time do not retry in lockstep.
+func NewJitteredBackOff(initial time.Duration, max time.Duration) *backoff.ExponentialBackOff {
+	ebo := backoff.NewExponentialBackOff()
+	ebo.InitialInterval = initial
+	ebo.MaxInterval = max
+	ebo.MaxElapsedTime = 0
+	ebo.Reset()
+	return ebo
+}
+
+// Jitter randomly spreads the duration by up to +/- backoff.DefaultRandomizationFactor of its value.
+func Jitter(duration time.Duration) time.Duration {
+	delta := backoff.DefaultRandomizationFactor * float64(duration)
+	// #nosec G404
+	return time.Duration(float64(duration) - delta + rand.Float64()*2*delta)
+}
+
+// RetryOperation retries the operation with a jittered exponential backoff policy starting at wait.
 func RetryOperation(ctx context.Context, wait time.Duration, retries int, operation func() error) error {
 	bo := backoff.WithMaxRetries(
-		backoff.NewConstantBackOff(wait),
+		NewJitteredBackOff(wait, wait*maxBackOffMultiple),
 		uint64(retries),
 	)
 	bo = backoff.WithContext(bo, ctx)
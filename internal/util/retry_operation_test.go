@@ -176,3 +176,20 @@ func TestRetryOperationForErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := Jitter(10 * time.Second)
+		assert.GreaterOrEqual(t, d, 5*time.Second)
+		assert.LessOrEqual(t, d, 15*time.Second)
+	}
+}
+
+func TestNewJitteredBackOff(t *testing.T) {
+	bo := NewJitteredBackOff(time.Second, 4*time.Second)
+	for i := 0; i < 20; i++ {
+		d := bo.NextBackOff()
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, 6*time.Second)
+	}
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseVersion parses the dotted numeric release of a version such as v0.1.0 or 2024.03.05-abc123,
+// ignoring any leading v and any pre-release or build suffix.
+func ParseVersion(version string) ([]int, error) {
+	release := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(release, "-+"); i >= 0 {
+		release = release[:i]
+	}
+	if release == "" {
+		return nil, fmt.Errorf("invalid version: %q", version)
+	}
+	var parts []int
+	for _, part := range strings.Split(release, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version: %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// CompareVersions returns -1, 0 or 1 if version a is older than, the same as, or newer than version b.
+func CompareVersions(a, b string) (int, error) {
+	av, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bv, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x < y {
+			return -1, nil
+		}
+		if x > y {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,34 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompareVersions tests the CompareVersions function.
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{"Same version", "2024.03.05-abc123", "2024.03.05", 0},
+		{"Older date version", "2024.01.31-abc123", "2024.03.05", -1},
+		{"Newer date version", "2024.03.06-abc123", "2024.03.05-def456", 1},
+		{"Semantic versions", "v0.10.0", "v0.9.1", 1},
+		{"Missing patch version", "v1.2", "v1.2.1", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareVersions(tt.a, tt.b)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := CompareVersions("dev", "2024.03.05")
+	assert.Error(t, err)
+}
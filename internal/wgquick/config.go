@@ -0,0 +1,116 @@
+// Package wgquick builds the wg-quick configuration of a device that does not run nexd, such as a mobile device or
+// an unmanaged device configured with a stock WireGuard client.
+package wgquick
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+)
+
+// the keepalive of the peers keeps the mappings of the NAT in front of the device open, the same as nexd
+const persistentKeepalive = 20
+
+// ReflexiveEndpoint returns the endpoint of a device as seen from the internet, the one a device without nexd can reach
+func ReflexiveEndpoint(device public.ModelsDevice) string {
+	for _, endpoint := range device.Endpoints {
+		switch endpoint.Source {
+		case "local", "host":
+			// only reachable from the networks of the device
+		default:
+			return endpoint.Address
+		}
+	}
+	return ""
+}
+
+// BuildConfig returns the wg-quick configuration of the device. When the device is peered through a relay, the relay
+// is its only peer and routes the VPC and the prefixes advertised by the other devices, otherwise the device is peered
+// directly with every device that has a reflexive endpoint and is not behind a symmetric NAT.
+func BuildConfig(privateKey string, vpc public.ModelsVPC, self public.ModelsDevice, devices []public.ModelsDevice, relay public.ModelsDevice) (string, error) {
+	var addresses []string
+	for _, ip := range self.Ipv4TunnelIps {
+		addresses = append(addresses, hostPrefix(ip.Address))
+	}
+	for _, ip := range self.Ipv6TunnelIps {
+		addresses = append(addresses, hostPrefix(ip.Address))
+	}
+	if len(addresses) == 0 {
+		return "", errors.New("the device has no tunnel address yet")
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "[Interface]\nPrivateKey = %s\nAddress = %s\n", privateKey, strings.Join(addresses, ", "))
+
+	if relay.PublicKey != "" {
+		allowedIPs := []string{}
+		for _, cidr := range []string{vpc.Ipv4Cidr, vpc.Ipv6Cidr} {
+			if cidr != "" {
+				allowedIPs = append(allowedIPs, cidr)
+			}
+		}
+		for _, d := range devices {
+			if d.PublicKey != self.PublicKey {
+				allowedIPs = append(allowedIPs, d.AdvertiseCidrs...)
+			}
+		}
+		writePeer(sb, relay, ReflexiveEndpoint(relay), allowedIPs)
+		return sb.String(), nil
+	}
+
+	// the peers are written in a stable order, so that the app can tell when the configuration changed
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].PublicKey < devices[j].PublicKey
+	})
+	for _, d := range devices {
+		endpoint := ReflexiveEndpoint(d)
+		if d.PublicKey == self.PublicKey || d.SymmetricNat || endpoint == "" {
+			continue
+		}
+		writePeer(sb, d, endpoint, append(append([]string{}, d.AllowedIps...), d.AdvertiseCidrs...))
+	}
+	return sb.String(), nil
+}
+
+// SelectRelay returns the WireGuard relay the device is peered through, the relay assigned by the service if any, from
+// the devices and the relay metadata of the VPC. DERP relays are not supported by stock WireGuard clients.
+func SelectRelay(devices []public.ModelsDevice, metadata []public.ModelsDeviceMetadata, relayID string) (public.ModelsDevice, bool) {
+	wireguardRelays := map[string]bool{}
+	for _, m := range metadata {
+		if m.Key == "relay" && m.Value["type"] == "wireguard" {
+			wireguardRelays[m.DeviceId] = true
+		}
+	}
+	var selected public.ModelsDevice
+	found := false
+	for _, d := range devices {
+		if !d.Relay || !wireguardRelays[d.Id] || ReflexiveEndpoint(d) == "" {
+			continue
+		}
+		if !found || d.Id == relayID {
+			selected = d
+			found = true
+		}
+	}
+	return selected, found
+}
+
+func writePeer(sb *strings.Builder, device public.ModelsDevice, endpoint string, allowedIPs []string) {
+	fmt.Fprintf(sb, "\n[Peer]\n# %s\nPublicKey = %s\nEndpoint = %s\nAllowedIPs = %s\nPersistentKeepalive = %d\n",
+		device.Hostname, device.PublicKey, endpoint, strings.Join(allowedIPs, ", "), persistentKeepalive)
+}
+
+// hostPrefix returns the tunnel address as a host prefix, the routes of the VPC are installed for the allowed IPs
+// of the peers instead
+func hostPrefix(address string) string {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		// the address already has a prefix length
+		return address
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()).String()
+}
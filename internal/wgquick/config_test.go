@@ -0,0 +1,108 @@
+package wgquick
+
+import (
+	"testing"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfig(t *testing.T) {
+	require := require.New(t)
+
+	vpc := public.ModelsVPC{Ipv4Cidr: "100.64.0.0/10", Ipv6Cidr: "200::/64"}
+	self := public.ModelsDevice{
+		PublicKey:     "self",
+		Ipv4TunnelIps: []public.ModelsTunnelIP{{Address: "100.64.0.5"}},
+		Ipv6TunnelIps: []public.ModelsTunnelIP{{Address: "200::5"}},
+	}
+	relay := public.ModelsDevice{
+		PublicKey:  "relay",
+		Hostname:   "relay",
+		Relay:      true,
+		AllowedIps: []string{"100.64.0.1/32"},
+		Endpoints:  []public.ModelsEndpoint{{Source: "local", Address: "10.0.0.1:51820"}, {Source: "stun:", Address: "1.2.3.4:51820"}},
+	}
+	direct := public.ModelsDevice{
+		PublicKey:      "direct",
+		Hostname:       "direct",
+		AllowedIps:     []string{"100.64.0.2/32", "200::2/128"},
+		AdvertiseCidrs: []string{"192.168.1.0/24"},
+		Endpoints:      []public.ModelsEndpoint{{Source: "host", Address: "10.0.0.2:51820"}, {Source: "stun:", Address: "5.6.7.8:51820"}},
+	}
+	symmetric := public.ModelsDevice{
+		PublicKey:    "symmetric",
+		SymmetricNat: true,
+		AllowedIps:   []string{"100.64.0.3/32"},
+		Endpoints:    []public.ModelsEndpoint{{Source: "stun:", Address: "9.9.9.9:4000"}},
+	}
+	devices := []public.ModelsDevice{symmetric, self, direct, relay}
+
+	// peered through the relay
+	config, err := BuildConfig("key", vpc, self, devices, relay)
+	require.NoError(err)
+	require.Equal(`[Interface]
+PrivateKey = key
+Address = 100.64.0.5/32, 200::5/128
+
+[Peer]
+# relay
+PublicKey = relay
+Endpoint = 1.2.3.4:51820
+AllowedIPs = 100.64.0.0/10, 200::/64, 192.168.1.0/24
+PersistentKeepalive = 20
+`, config)
+
+	// peered directly, the peer behind a symmetric NAT is not reachable
+	config, err = BuildConfig("key", vpc, self, devices, public.ModelsDevice{})
+	require.NoError(err)
+	require.Equal(`[Interface]
+PrivateKey = key
+Address = 100.64.0.5/32, 200::5/128
+
+[Peer]
+# direct
+PublicKey = direct
+Endpoint = 5.6.7.8:51820
+AllowedIPs = 100.64.0.2/32, 200::2/128, 192.168.1.0/24
+PersistentKeepalive = 20
+
+[Peer]
+# relay
+PublicKey = relay
+Endpoint = 1.2.3.4:51820
+AllowedIPs = 100.64.0.1/32
+PersistentKeepalive = 20
+`, config)
+
+	_, err = BuildConfig("key", vpc, public.ModelsDevice{PublicKey: "self"}, devices, relay)
+	require.Error(err)
+}
+
+func TestSelectRelay(t *testing.T) {
+	require := require.New(t)
+
+	stun := []public.ModelsEndpoint{{Source: "stun:", Address: "1.2.3.4:51820"}}
+	devices := []public.ModelsDevice{
+		{Id: "derp", Relay: true, Endpoints: stun},
+		{Id: "first", Relay: true, Endpoints: stun},
+		{Id: "assigned", Relay: true, Endpoints: stun},
+		{Id: "unreachable", Relay: true},
+		{Id: "device", Endpoints: stun},
+	}
+	metadata := []public.ModelsDeviceMetadata{
+		{DeviceId: "derp", Key: "relay", Value: map[string]interface{}{"type": "derp"}},
+		{DeviceId: "first", Key: "relay", Value: map[string]interface{}{"type": "wireguard"}},
+		{DeviceId: "assigned", Key: "relay", Value: map[string]interface{}{"type": "wireguard"}},
+		{DeviceId: "unreachable", Key: "relay", Value: map[string]interface{}{"type": "wireguard"}},
+	}
+
+	relay, found := SelectRelay(devices, metadata, "")
+	require.True(found)
+	require.Equal("first", relay.Id)
+	relay, found = SelectRelay(devices, metadata, "assigned")
+	require.True(found)
+	require.Equal("assigned", relay.Id)
+	_, found = SelectRelay(devices, metadata[:1], "")
+	require.False(found)
+}
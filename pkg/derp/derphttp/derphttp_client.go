@@ -82,6 +82,8 @@ type Client struct {
 	mu           sync.Mutex
 	preferred    bool
 	canAckPings  bool
+	wsFallback   bool // alternate between the DERP upgrade and WebSockets after failed connects
+	useWS        bool // the next connect uses WebSockets
 	closed       bool
 	netConn      io.Closer
 	client       *derp.Client
@@ -329,12 +331,16 @@ func (c *Client) connect(ctx context.Context, caller string) (client *derp.Clien
 			if tcpConn != nil {
 				go tcpConn.Close()
 			}
+			if c.wsFallback && dialWebsocketFunc != nil {
+				// middleboxes that only pass HTTPS may drop the DERP upgrade but allow WebSockets
+				c.useWS = !c.useWS
+			}
 		}
 	}()
 
 	var node *tailcfg.DERPNode // nil when using c.url to dial
 	switch {
-	case useWebsockets():
+	case useWebsockets() || c.useWS:
 		var urlStr string
 		if c.url != nil {
 			urlStr = c.url.String()
@@ -937,6 +943,14 @@ func (c *Client) SetCanAckPings(v bool) {
 	c.canAckPings = v
 }
 
+// SetWebsocketFallback sets whether the client alternates between the DERP
+// upgrade and a WebSocket connection to the server when connecting fails.
+func (c *Client) SetWebsocketFallback(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.wsFallback = v
+}
+
 // NotePreferred notes whether this Client is the caller's preferred
 // (home) DERP node. It's only used for stats.
 func (c *Client) NotePreferred(v bool) {
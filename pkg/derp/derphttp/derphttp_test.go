@@ -4,16 +4,20 @@
 package derphttp
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"nhooyr.io/websocket"
 	"tailscale.com/derp"
+	"tailscale.com/net/wsconn"
 	"tailscale.com/types/key"
 )
 
@@ -206,3 +210,58 @@ func TestPing(t *testing.T) {
 		t.Fatalf("Ping: %v", err)
 	}
 }
+
+func TestWebsocketFallback(t *testing.T) {
+	serverPrivateKey := key.NewNode()
+	s := derp.NewServer(serverPrivateKey, t.Logf)
+	defer s.Close()
+
+	// a server behind a middlebox that drops the DERP upgrade but passes WebSockets
+	httpsrv := &http.Server{
+		TLSNextProto: make(map[string]func(*http.Server, *tls.Conn, http.Handler)),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.ToLower(r.Header.Get("Upgrade")) != "websocket" {
+				http.Error(w, "upgrade not allowed", http.StatusForbidden)
+				return
+			}
+			c, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"derp"}})
+			if err != nil {
+				return
+			}
+			defer c.Close(websocket.StatusInternalError, "closing")
+			wc := wsconn.NetConn(r.Context(), c, websocket.MessageBinary, r.RemoteAddr)
+			brw := bufio.NewReadWriter(bufio.NewReader(wc), bufio.NewWriter(wc))
+			s.Accept(r.Context(), wc, brw, r.RemoteAddr)
+		}),
+	}
+
+	ln, err := net.Listen("tcp4", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverURL := "http://" + ln.Addr().String() + "/derp"
+
+	go func() {
+		if err := httpsrv.Serve(ln); err != nil {
+			if err == http.ErrServerClosed {
+				return
+			}
+			panic(err)
+		}
+	}()
+	defer httpsrv.Close()
+
+	c, err := NewClient(key.NewNode(), serverURL, t.Logf)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	c.SetWebsocketFallback(true)
+
+	if err := c.Connect(context.Background()); err == nil {
+		t.Fatal("expected the DERP upgrade to fail")
+	}
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("client Connect over WebSockets: %v", err)
+	}
+}
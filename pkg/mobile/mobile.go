@@ -0,0 +1,335 @@
+// Package mobile is the control plane of a Nexodus device packaged for gomobile, so that iOS and Android apps can
+// join a Nexodus VPC with the WireGuard backend of their platform. The app logs in, registers its device and
+// periodically fetches the wg-quick configuration of the device, which both the WireGuardKit of iOS and the
+// wireguard-android library can parse and apply.
+//
+// Build the bindings with:
+//
+//	gomobile bind -target=android ./pkg/mobile
+//	gomobile bind -target=ios ./pkg/mobile
+//
+// The exported API only uses the types supported by gomobile.
+package mobile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nexodus-io/nexodus/internal/api/public"
+	"github.com/nexodus-io/nexodus/internal/client"
+	"github.com/nexodus-io/nexodus/internal/wgcrypto"
+	"github.com/nexodus-io/nexodus/internal/wgquick"
+	"golang.org/x/oauth2"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// the time an api call of the client may take, the device flow login is bounded by the expiry of its code instead
+const apiTimeout = 30 * time.Second
+
+// TokenStore persists the OAuth token of the user between the runs of the app, for example in the keychain.
+// The token is a JSON document, Load returns an empty string when no token is stored.
+type TokenStore interface {
+	Load() (string, error)
+	Store(token string) error
+}
+
+// AuthHandler shows the instructions of the device flow login to the user, the message contains the URL to open
+// in a browser and the one-time code to enter.
+type AuthHandler interface {
+	ShowMessage(message string)
+}
+
+// Client is a Nexodus device of a mobile app
+type Client struct {
+	lock       sync.Mutex
+	serviceURL string
+	privateKey wgtypes.Key
+	userAgent  string
+	tokenStore TokenStore
+
+	api *client.APIClient
+	// the VPC and security group of the registration key the client logged in with
+	regKeyVpcID           string
+	regKeySecurityGroupID string
+	vpc                   *public.ModelsVPC
+	device                *public.ModelsDevice
+}
+
+// GenerateKey returns a new WireGuard private key, the app stores it and passes it to NewClient on every run so
+// that the device keeps its identity.
+func GenerateKey() (string, error) {
+	key, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+// NewClient returns the client of the Nexodus service at serviceURL, e.g. https://try.nexodus.io, for the device
+// with the given WireGuard private key.
+func NewClient(serviceURL, privateKey string) (*Client, error) {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service url %s: %w", serviceURL, err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid service url %s, the https:// scheme is required", serviceURL)
+	}
+	key, err := wgtypes.ParseKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	// the api of the service is served on the api subdomain
+	u.Host = "api." + u.Host
+	u.Path = ""
+	return &Client{
+		serviceURL: u.String(),
+		privateKey: key,
+		userAgent:  "nexodus-mobile",
+	}, nil
+}
+
+// PublicKey returns the WireGuard public key of the device
+func (c *Client) PublicKey() string {
+	return c.privateKey.PublicKey().String()
+}
+
+// SetUserAgent sets the user agent of the requests to the service, e.g. the name and version of the app
+func (c *Client) SetUserAgent(userAgent string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.userAgent = userAgent
+}
+
+// SetTokenStore sets the store of the OAuth token, the user only logs in again when the stored token is rejected
+func (c *Client) SetTokenStore(store TokenStore) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.tokenStore = store
+}
+
+// LoginWithDeviceFlow logs the user in with the OAuth device flow, it blocks until the user completed the login
+// in a browser or the one-time code expired.
+func (c *Client) LoginWithDeviceFlow(handler AuthHandler) error {
+	return c.login(func(msg string) {
+		if handler != nil {
+			handler.ShowMessage(msg)
+		}
+	}, client.WithDeviceFlow())
+}
+
+// LoginWithPassword logs the user in with the username and password of their account
+func (c *Client) LoginWithPassword(username, password string) error {
+	return c.login(nil, client.WithPasswordGrant(username, password))
+}
+
+// LoginWithRegistrationKey logs in with a registration key created with nexctl, the device joins the VPC and
+// security group of the key.
+func (c *Client) LoginWithRegistrationKey(regKey string) error {
+	if err := c.login(nil, client.WithBearerToken(regKey)); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	// the registration key is replaced by the device token once the device is registered
+	regKeyModel, _, err := c.api.RegKeyApi.GetRegKey(ctx, "me").Execute()
+	if err != nil {
+		return fmt.Errorf("could not fetch registration settings: %w", err)
+	}
+	c.regKeyVpcID = regKeyModel.VpcId
+	c.regKeySecurityGroupID = regKeyModel.SecurityGroupId
+	return nil
+}
+
+func (c *Client) login(authcb func(string), option client.Option) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	options := []client.Option{client.WithUserAgent(c.userAgent), option}
+	if c.tokenStore != nil {
+		options = append(options, client.WithTokenStore(tokenStoreAdapter{store: c.tokenStore}))
+	}
+	// the context is kept by the client to refresh the token
+	api, err := client.NewAPIClient(context.Background(), c.serviceURL, authcb, options...)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	c.api = api
+	c.regKeyVpcID = ""
+	c.regKeySecurityGroupID = ""
+	c.vpc = nil
+	c.device = nil
+	return nil
+}
+
+// Register registers the device in the VPC, or in the default VPC of the user when vpcID is empty, and updates it
+// when it is already registered. The platform is reported as the OS of the device, e.g. ios or android.
+func (c *Client) Register(vpcID, hostname, platform string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.api == nil {
+		return errors.New("the client is not logged in")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+
+	if c.regKeyVpcID != "" {
+		vpcID = c.regKeyVpcID
+	} else if vpcID == "" {
+		user, _, err := c.api.UsersApi.GetUser(ctx, "me").Execute()
+		if err != nil {
+			return fmt.Errorf("get user error: %w", err)
+		}
+		// the default VPC of a user has the id of the user
+		vpcID = user.Id
+	}
+	vpc, _, err := c.api.VPCApi.GetVPC(ctx, vpcID).Execute()
+	if err != nil {
+		return fmt.Errorf("get vpc error: %w", err)
+	}
+
+	// the device is reached through a WireGuard relay when the VPC has one, so that the peers do not have to
+	// traverse the NAT of the mobile network, otherwise the peers learn its endpoint from its handshakes
+	devices, _, err := c.api.VPCApi.ListDevicesInVPC(ctx, vpc.Id).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to list the devices of the vpc: %w", err)
+	}
+	_, relayAvailable, err := c.selectRelay(ctx, vpc.Id, devices, "")
+	if err != nil {
+		return err
+	}
+
+	device, _, err := c.api.DevicesApi.CreateDevice(ctx).Device(public.ModelsAddDevice{
+		VpcId:           vpc.Id,
+		SecurityGroupId: c.regKeySecurityGroupID,
+		PublicKey:       c.PublicKey(),
+		Hostname:        hostname,
+		Os:              platform,
+		SymmetricNat:    relayAvailable,
+	}).Execute()
+	if err != nil {
+		var apiError *public.GenericOpenAPIError
+		if !errors.As(err, &apiError) {
+			return fmt.Errorf("error creating device: %w", err)
+		}
+		conflict, ok := apiError.Model().(public.ModelsConflictsError)
+		if !ok {
+			return fmt.Errorf("error creating device: %w", err)
+		}
+		device, _, err = c.api.DevicesApi.UpdateDevice(ctx, conflict.Id).Update(public.ModelsUpdateDevice{
+			VpcId:        vpc.Id,
+			Hostname:     hostname,
+			SymmetricNat: relayAvailable,
+		}).Execute()
+		if err != nil {
+			return fmt.Errorf("error updating device: %w", err)
+		}
+	}
+
+	// the device token is sealed with the WireGuard key of the device
+	if device.BearerToken != "" {
+		sealed, err := wgcrypto.ParseSealed(device.BearerToken)
+		if err != nil {
+			return err
+		}
+		token, err := sealed.Open(c.privateKey[:])
+		if err != nil {
+			return err
+		}
+		c.api, err = client.NewAPIClient(context.Background(), c.serviceURL, nil, client.WithUserAgent(c.userAgent), client.WithBearerToken(string(token)))
+		if err != nil {
+			return err
+		}
+	}
+	c.vpc = vpc
+	c.device = device
+	return nil
+}
+
+// DeviceID returns the id of the registered device
+func (c *Client) DeviceID() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.device == nil {
+		return ""
+	}
+	return c.device.Id
+}
+
+// Config returns the wg-quick configuration of the registered device with the current peers of its VPC. The app
+// fetches it again periodically, the peers and their endpoints change as devices join and roam.
+func (c *Client) Config() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.device == nil {
+		return "", errors.New("the device is not registered")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+
+	devices, _, err := c.api.VPCApi.ListDevicesInVPC(ctx, c.vpc.Id).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to list the devices of the vpc: %w", err)
+	}
+	self := *c.device
+	for _, d := range devices {
+		if d.PublicKey == self.PublicKey {
+			// the tunnel addresses and the assigned relay of the device
+			self = d
+		}
+	}
+	relay, relayAvailable, err := c.selectRelay(ctx, c.vpc.Id, devices, self.RelayId)
+	if err != nil {
+		return "", err
+	}
+	if self.SymmetricNat && !relayAvailable {
+		return "", errors.New("the vpc has no WireGuard relay to reach the peers through, register the device again")
+	}
+	if !self.SymmetricNat {
+		relay = public.ModelsDevice{}
+	}
+	return wgquick.BuildConfig(c.privateKey.String(), *c.vpc, self, devices, relay)
+}
+
+// selectRelay returns the WireGuard relay the device is peered through, the relay assigned by the service if any
+func (c *Client) selectRelay(ctx context.Context, vpcID string, devices []public.ModelsDevice, relayID string) (public.ModelsDevice, bool, error) {
+	metadata, _, err := c.api.VPCApi.ListMetadataInVPC(ctx, vpcID, []string{"relay"}).Execute()
+	if err != nil {
+		return public.ModelsDevice{}, false, fmt.Errorf("failed to list the relays of the vpc: %w", err)
+	}
+	relay, found := wgquick.SelectRelay(devices, metadata, relayID)
+	return relay, found, nil
+}
+
+// tokenStoreAdapter stores the OAuth token of the client as JSON in the TokenStore of the app
+type tokenStoreAdapter struct {
+	store TokenStore
+}
+
+func (s tokenStoreAdapter) Load() (*oauth2.Token, error) {
+	data, err := s.store.Load()
+	if err != nil || data == "" {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	if err := json.Unmarshal([]byte(data), token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s tokenStoreAdapter) Store(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.store.Store(string(data))
+}
+
+var _ client.TokenStore = tokenStoreAdapter{}
@@ -8,15 +8,19 @@ import (
 	"net/url"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-session/session/v3"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 )
 
 type OidcAgent struct {
-	logger         *zap.SugaredLogger
+	logger *zap.SugaredLogger
+	// the name of the provider of the agent when it is one of the providers of a MultiOidcAgent
+	name           string
 	domain         string
 	trustedOrigins []string
 	clientID       string
+	clientSecret   string
 	redirectURL    string
 	oauthConfig    OauthConfig
 	oidcIssuer     string
@@ -24,9 +28,11 @@ type OidcAgent struct {
 	verifier       IDTokenVerifier
 	endSessionURL  string
 	deviceAuthURL  string
+	revocationURL  string
 	backend        *url.URL
 	cookieKey      string
 	insecureTLS    bool
+	sessionStore   session.ManagerStore
 }
 
 type OauthConfig interface {
@@ -85,6 +91,7 @@ func NewOidcAgent(ctx context.Context,
 	var claims struct {
 		DeviceAuthURL string `json:"device_authorization_endpoint"`
 		EndSessionURL string `json:"end_session_endpoint"`
+		RevocationURL string `json:"revocation_endpoint"`
 	}
 	err = provider.Claims(&claims)
 	if err != nil {
@@ -114,6 +121,7 @@ func NewOidcAgent(ctx context.Context,
 		domain:         domain,
 		trustedOrigins: origins,
 		clientID:       clientID,
+		clientSecret:   clientSecret,
 		redirectURL:    redirectURL,
 		oauthConfig:    config,
 		oidcIssuer:     oidcProvider,
@@ -121,6 +129,7 @@ func NewOidcAgent(ctx context.Context,
 		verifier:       verifier,
 		endSessionURL:  claims.EndSessionURL,
 		deviceAuthURL:  claims.DeviceAuthURL,
+		revocationURL:  claims.RevocationURL,
 		backend:        backendURL,
 		cookieKey:      cookieKey,
 		insecureTLS:    insecureTLS,
@@ -27,6 +27,10 @@ const (
 	backendArg          = "backend"
 	cookieKeyArg        = "cookie-key"
 	flowArg             = "flow"
+	sessionStoreArg     = "session-store"
+	redisServerArg      = "redis-server"
+	redisPasswordArg    = "redis-password" // #nosec: G101
+	redisDBArg          = "redis-db"
 )
 
 func main() {
@@ -115,6 +119,35 @@ func main() {
 				Value:   "p2s5v8y/B?E(G+KbPeShVmYq3t6w9z$C",
 				Sources: cli.EnvVars("COOKIE_KEY"),
 			},
+			&cli.StringFlag{
+				Name:  sessionStoreArg,
+				Usage: "Where the sessions are stored, one of 'memory', 'cookie' or 'redis'. Use 'redis' to run several replicas of the agent",
+				Value: agent.MemorySessionStore,
+				Action: func(ctx context.Context, command *cli.Command, s string) error {
+					if s != agent.MemorySessionStore && s != agent.CookieSessionStore && s != agent.RedisSessionStore {
+						return fmt.Errorf("flag 'session-store' value should be one of 'memory', 'cookie' or 'redis'")
+					}
+					return nil
+				},
+				Sources: cli.EnvVars("SESSION_STORE"),
+			},
+			&cli.StringFlag{
+				Name:    redisServerArg,
+				Usage:   "Redis server the sessions are stored in when the session store is 'redis'",
+				Value:   "redis:6379",
+				Sources: cli.EnvVars("REDIS_SERVER"),
+			},
+			&cli.StringFlag{
+				Name:    redisPasswordArg,
+				Usage:   "Password of the redis server",
+				Sources: cli.EnvVars("REDIS_PASSWORD"),
+			},
+			&cli.IntFlag{
+				Name:    redisDBArg,
+				Usage:   "Redis database the sessions are stored in",
+				Value:   0,
+				Sources: cli.EnvVars("REDIS_DB"),
+			},
 		},
 		Action: run,
 	}
@@ -166,6 +199,17 @@ func run(ctx context.Context, command *cli.Command) error {
 	if err != nil {
 		log.Fatal(err)
 	}
+	sessionStore, err := agent.NewSessionStore(command.String(sessionStoreArg), agent.SessionStoreOptions{
+		CookieKey:      cookieKey,
+		RedisServer:    command.String(redisServerArg),
+		RedisPassword:  command.String(redisPasswordArg),
+		RedisDB:        int(command.Int(redisDBArg)),
+		RedisKeyPrefix: "oidc-agent:session:",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	auth.SetSessionStore(sessionStore)
 	var r *gin.Engine
 	if flow == "authorization" {
 		r = agent.NewCodeFlowRouter(auth)
@@ -0,0 +1,68 @@
+package oidcagent
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
+)
+
+const (
+	// CSRFCookie is the cookie of the CSRF token of the browser
+	CSRFCookie = "csrf_token"
+	// CSRFHeader is the header the CSRF token is sent in by the frontend
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFQuery is the query parameter the CSRF token is sent in by the navigations of the frontend, such as
+	// the logout, which can not set headers
+	CSRFQuery = "csrf_token"
+)
+
+// CSRFToken returns the CSRF token of the browser, a new token is created and set in the CSRF cookie when the
+// browser has none.
+// @Summary      Get CSRF Token
+// @Description  Gets the CSRF token to send in the X-CSRF-Token header or the csrf_token query parameter of the requests changing the session.
+// @Id           WebCSRFToken
+// @Tags         Auth
+// @Accepts      json
+// @Produce      json
+// @Success      200 {object} models.CSRFTokenResponse
+// @Router       /web/csrf_token [get]
+func CSRFToken(c *gin.Context) {
+	token, err := c.Cookie(CSRFCookie)
+	if err != nil || token == "" {
+		token, err = randString(32)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(CSRFCookie, token, int((24 * time.Hour).Seconds()), "/", "", secureCookie(c), true)
+	c.JSON(http.StatusOK, models.CSRFTokenResponse{CSRFToken: token})
+}
+
+// CSRFMiddleware rejects the requests whose CSRF token, sent in the CSRF header or query parameter, does not
+// match the CSRF cookie. A cross-site page can make the browser send the cookie, but it can not read it.
+//
+// The login end is protected by the state parameter of the login flow instead, which is checked against the
+// state cookie the same way, since it is reached by a redirect of the provider.
+func CSRFMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cookie, err := c.Cookie(CSRFCookie)
+		if err != nil || cookie == "" {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		token := c.GetHeader(CSRFHeader)
+		if token == "" {
+			token = c.Query(CSRFQuery)
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) != 1 {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package oidcagent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/csrf_token", CSRFToken)
+	r.POST("/refresh", CSRFMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/logout", CSRFMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusFound)
+	})
+
+	req, _ := http.NewRequest("GET", "/csrf_token", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var token models.CSRFTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &token))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, CSRFCookie, cookies[0].Name)
+	require.Equal(t, token.CSRFToken, cookies[0].Value)
+	assert.False(t, cookies[0].Secure)
+
+	// the cookie is only sent over https behind a TLS-terminating proxy
+	req, _ = http.NewRequest("GET", "/csrf_token", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, w.Result().Cookies(), 1)
+	assert.True(t, w.Result().Cookies()[0].Secure)
+
+	// the token of a browser is kept
+	req, _ = http.NewRequest("GET", "/csrf_token", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var again models.CSRFTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &again))
+	assert.Equal(t, token.CSRFToken, again.CSRFToken)
+
+	serve := func(method string, target string, cookie *http.Cookie, header string) int {
+		req, _ := http.NewRequest(method, target, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if header != "" {
+			req.Header.Set(CSRFHeader, header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNoContent, serve("POST", "/refresh", cookies[0], token.CSRFToken))
+	assert.Equal(t, http.StatusFound, serve("GET", "/logout?csrf_token="+token.CSRFToken, cookies[0], ""))
+
+	// a cross-site request carries the cookie but not the token
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/refresh", cookies[0], ""))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/refresh", cookies[0], "forged"))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/logout", cookies[0], ""))
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/refresh", nil, token.CSRFToken))
+}
@@ -0,0 +1,202 @@
+package oidcagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+var (
+	// how long a poll of the device login waits for the user to authorize the device, below the write timeout of
+	// the apiserver and the response header timeout of the clients
+	devicePollTimeout = 8 * time.Second
+	// the interval the token endpoint is polled at, raised when the provider asks to slow down
+	devicePollInterval = 5 * time.Second
+)
+
+// DeviceAuthorize requests a device login from the device authorization endpoint of the provider.
+// @Summary     Authorize Device Login
+// @Description Requests a device login from the device authorization endpoint of the provider, for the devices that log in through the service rather than the provider. Responds like the device authorization endpoint of the provider.
+// @Id          DeviceAuthorize
+// @Tags        Auth
+// @Accept      x-www-form-urlencoded
+// @Produce     json
+// @Param       scope     formData  string  false "scopes of the tokens of the device login"
+// @Param       provider  query     string  false "name of the identity provider of the device login, see /web/providers"
+// @Success     200 {object} models.DeviceAuthorizationResponse
+// @Failure     400 {object} models.DeviceTokenError
+// @Failure     502 {object} models.DeviceTokenError
+// @Router      /device/login/authorize [post]
+func (o *OidcAgent) DeviceAuthorize(c *gin.Context) {
+	if o.deviceAuthURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type", "error_description": "the provider does not support device logins"})
+		return
+	}
+	form := url.Values{}
+	if scope := c.PostForm("scope"); scope != "" {
+		form.Set("scope", scope)
+	}
+	status, body, err := o.providerRequest(o.prepareContext(c), o.deviceAuthURL, form)
+	if err != nil {
+		o.logger.With("error", err).Debug("unable to request a device login")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "temporarily_unavailable", "error_description": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "application/json", body)
+}
+
+// DevicePoll polls the token endpoint of the provider for the tokens of a device login.
+// @Summary     Poll Device Login
+// @Description Polls the token endpoint of the provider for the tokens of a device login, for the devices that poll through the service rather than the provider. Responds like the token endpoint of the provider, with an authorization_pending error when the user has not authorized the device during the poll. With the refresh_token grant type, refreshes the tokens of a device login instead. The id tokens of the responses are verified by the service.
+// @Id          DevicePoll
+// @Tags        Auth
+// @Accept      x-www-form-urlencoded
+// @Produce     json
+// @Param       grant_type     formData  string  false "urn:ietf:params:oauth:grant-type:device_code, the default, or refresh_token"
+// @Param       device_code    formData  string  false "device code of the device authorization response, required by the device_code grant type"
+// @Param       refresh_token  formData  string  false "refresh token of the device login, required by the refresh_token grant type"
+// @Param       provider       query     string  false "name of the identity provider of the device login, see /web/providers"
+// @Success     200 {object} models.DeviceTokenResponse
+// @Failure     400 {object} models.DeviceTokenError
+// @Failure     502 {object} models.DeviceTokenError
+// @Router      /device/login/poll [post]
+func (o *OidcAgent) DevicePoll(c *gin.Context) {
+	if grantType := c.PostForm("grant_type"); grantType == "refresh_token" {
+		o.deviceRefresh(c)
+		return
+	} else if grantType != "" && grantType != deviceCodeGrantType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	logger := o.logger
+	deviceCode := c.PostForm("device_code")
+	if deviceCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "device_code missing"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(o.prepareContext(c), devicePollTimeout)
+	defer cancel()
+	form := url.Values{}
+	form.Set("grant_type", deviceCodeGrantType)
+	form.Set("device_code", deviceCode)
+	interval := devicePollInterval
+	var pollErr error
+	for {
+		status, body, err := o.providerRequest(ctx, o.provider.Endpoint().TokenURL, form)
+		pollErr = err
+		if ctx.Err() != nil {
+			// the poll was cut by its timeout
+			pollErr = nil
+		} else if err == nil {
+			var tokenError struct {
+				Error string `json:"error"`
+			}
+			_ = json.Unmarshal(body, &tokenError)
+			switch tokenError.Error {
+			case "authorization_pending":
+			case "slow_down":
+				interval += devicePollInterval
+			default:
+				// the tokens, or an error ending the device login
+				o.tokenResponse(c, status, body)
+				return
+			}
+		} else {
+			logger.With("error", err).Debug("unable to poll the token endpoint")
+		}
+
+		select {
+		case <-ctx.Done():
+			if pollErr != nil {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "temporarily_unavailable", "error_description": pollErr.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "authorization_pending"})
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// deviceRefresh refreshes the tokens of a device login with the token endpoint of the provider
+func (o *OidcAgent) deviceRefresh(c *gin.Context) {
+	refreshToken := c.PostForm("refresh_token")
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "refresh_token missing"})
+		return
+	}
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	status, body, err := o.providerRequest(o.prepareContext(c), o.provider.Endpoint().TokenURL, form)
+	if err != nil {
+		o.logger.With("error", err).Debug("unable to refresh the tokens of a device login")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "temporarily_unavailable", "error_description": err.Error()})
+		return
+	}
+	o.tokenResponse(c, status, body)
+}
+
+// tokenResponse responds with a response of the token endpoint of the provider, once the id token of its
+// tokens is verified, since the devices logging in through the service do not reach the keys of the provider
+func (o *OidcAgent) tokenResponse(c *gin.Context, status int, body []byte) {
+	if status == http.StatusOK {
+		var token struct {
+			IDToken string `json:"id_token"`
+		}
+		if err := json.Unmarshal(body, &token); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "server_error", "error_description": "invalid token response"})
+			return
+		}
+		if token.IDToken != "" {
+			if _, err := o.verifier.Verify(o.prepareContext(c), token.IDToken); err != nil {
+				o.logger.With("error", err).Debug("unable to verify the id token of a device login")
+				c.JSON(http.StatusBadGateway, gin.H{"error": "server_error", "error_description": "invalid id_token"})
+				return
+			}
+		}
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "application/json", body)
+}
+
+// providerRequest posts the form to an endpoint of the provider, authenticated as the client of the agent,
+// it returns the status and the body of the response
+func (o *OidcAgent) providerRequest(ctx context.Context, endpoint string, form url.Values) (int, []byte, error) {
+	if o.clientSecret == "" {
+		form.Set("client_id", o.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if o.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+	}
+	res, err := contextClient(ctx).Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusBadRequest && res.StatusCode != http.StatusUnauthorized {
+		return 0, nil, fmt.Errorf("the provider responded %s", res.Status)
+	}
+	return res.StatusCode, body, nil
+}
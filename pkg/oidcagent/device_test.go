@@ -0,0 +1,136 @@
+package oidcagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+func TestDevicePoll(t *testing.T) {
+	interval, timeout := devicePollInterval, devicePollTimeout
+	t.Cleanup(func() {
+		devicePollInterval, devicePollTimeout = interval, timeout
+	})
+	devicePollInterval = 10 * time.Millisecond
+	devicePollTimeout = 200 * time.Millisecond
+
+	polls := 0
+	authorized := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "my-cli", r.PostForm.Get("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/device":
+			assert.Equal(t, "openid", r.PostForm.Get("scope"))
+			_, _ = w.Write([]byte(`{"device_code":"pending","user_code":"ABCD-EFGH","verification_uri":"https://idp/device","expires_in":300}`))
+		case r.PostForm.Get("grant_type") == "refresh_token":
+			assert.Equal(t, "kittens", r.PostForm.Get("refresh_token"))
+			_, _ = w.Write([]byte(`{"access_token":"fluffy","token_type":"Bearer","id_token":"boxofkittehs","expires_in":300}`))
+		case r.PostForm.Get("device_code") == "forged":
+			_, _ = w.Write([]byte(`{"access_token":"floofy","token_type":"Bearer","id_token":"forged","expires_in":300}`))
+		case r.PostForm.Get("device_code") == "denied":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"access_denied"}`))
+		case !authorized || polls < 2:
+			polls++
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+		default:
+			_, _ = w.Write([]byte(`{"access_token":"floofy","token_type":"Bearer","refresh_token":"kittens","id_token":"boxofkittehs","expires_in":300}`))
+		}
+	}))
+	defer server.Close()
+
+	auth := &OidcAgent{
+		logger:        zap.NewExample().Sugar(),
+		clientID:      "my-cli",
+		deviceAuthURL: server.URL + "/device",
+		provider: &FakeOpenIDConnectProvider{
+			EndpointFn: func() oauth2.Endpoint {
+				return oauth2.Endpoint{TokenURL: server.URL + "/token"}
+			},
+		},
+		verifier: &FakeIDTokenVerifier{
+			VerifyFn: func(ctx context.Context, rawIDToken string) (*oidc.IDToken, error) {
+				if rawIDToken != "boxofkittehs" {
+					return nil, fmt.Errorf("invalid id token")
+				}
+				return &oidc.IDToken{}, nil
+			},
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	AddDeviceFlowRoutes(r, auth)
+	post := func(path string, form url.Values) (int, []byte) {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code, w.Body.Bytes()
+	}
+	poll := func(deviceCode string) (int, []byte) {
+		return post("/login/poll", url.Values{"device_code": []string{deviceCode}})
+	}
+
+	// the service requests the device login from the provider
+	code, body := post("/login/authorize", url.Values{"scope": []string{"openid"}})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var authorization models.DeviceAuthorizationResponse
+	require.NoError(t, json.Unmarshal(body, &authorization))
+	assert.Equal(t, "pending", authorization.DeviceCode)
+	assert.Equal(t, "ABCD-EFGH", authorization.UserCode)
+
+	// the poll ends with authorization_pending when the user does not authorize the device in time
+	code, body = poll("pending")
+	require.Equal(t, http.StatusBadRequest, code)
+	var tokenError models.DeviceTokenError
+	require.NoError(t, json.Unmarshal(body, &tokenError))
+	assert.Equal(t, "authorization_pending", tokenError.Error)
+
+	// the poll waits for the user to authorize the device
+	authorized, polls = true, 0
+	code, body = poll("pending")
+	require.Equal(t, http.StatusOK, code, string(body))
+	var token models.DeviceTokenResponse
+	require.NoError(t, json.Unmarshal(body, &token))
+	assert.Equal(t, "floofy", token.AccessToken)
+	assert.Equal(t, "boxofkittehs", token.IDToken)
+	assert.Equal(t, 300, token.ExpiresIn)
+
+	code, body = poll("denied")
+	require.Equal(t, http.StatusBadRequest, code)
+	require.NoError(t, json.Unmarshal(body, &tokenError))
+	assert.Equal(t, "access_denied", tokenError.Error)
+
+	code, _ = poll("")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// the id tokens are verified by the service
+	code, _ = poll("forged")
+	assert.Equal(t, http.StatusBadGateway, code)
+
+	// the tokens are refreshed through the service
+	code, body = post("/login/poll", url.Values{"grant_type": []string{"refresh_token"}, "refresh_token": []string{"kittens"}})
+	require.Equal(t, http.StatusOK, code, string(body))
+	require.NoError(t, json.Unmarshal(body, &token))
+	assert.Equal(t, "fluffy", token.AccessToken)
+
+	code, _ = post("/login/poll", url.Values{"grant_type": []string{"password"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -45,15 +45,30 @@ func (o *OidcAgent) prepareContext(c *gin.Context) context.Context {
 	return c.Request.Context()
 }
 
+// contextClient returns the http client of a context prepared by prepareContext
+func contextClient(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// secureCookie tells whether the cookies of the request are only to be sent over https, when it is made over
+// TLS or forwarded from https by a TLS-terminating proxy. The URL of a server request has no scheme.
+func secureCookie(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
 // LoginStart initiates the OIDC login process.
 // @Summary      Initiates OIDC Web Login
-// @Description  Generates state and nonce, then redirects the user to the OAuth2 authorization URL.
+// @Description  Generates state, nonce and a PKCE code verifier, then redirects the user to the OAuth2 authorization URL.
 // @Id           WebStart
 // @Tags         Auth
 // @Accepts      json
 // @Produce      json
 // @Param		 redirect     query  string   true "URL to redirect to if login succeeds"
 // @Param		 failure     query  string   false "URL to redirect to if login fails (optional)"
+// @Param		 provider    query  string   false "name of the identity provider to log in with, see /web/providers, a page to select one is returned when there are several and none is given"
 // @Success      302 {string} string "Redirects to the OAuth2 authorization URL"
 // @Router       /web/login/start [get]
 func (o *OidcAgent) LoginStart(c *gin.Context) {
@@ -91,11 +106,14 @@ func (o *OidcAgent) LoginStart(c *gin.Context) {
 	}
 
 	c.SetSameSite(http.SameSiteLaxMode)
-	c.SetCookie("redirect", query.Redirect, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
-	c.SetCookie("failure", query.Failure, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
-	c.SetCookie("state", state, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
-	c.SetCookie("nonce", nonce, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
-	url := o.oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce))
+	c.SetCookie("redirect", query.Redirect, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	c.SetCookie("failure", query.Failure, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	c.SetCookie("state", state, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	c.SetCookie("nonce", nonce, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	// the code verifier proves to the provider that the code is exchanged by the client that requested it (RFC 7636)
+	verifier := oauth2.GenerateVerifier()
+	c.SetCookie("code_verifier", verifier, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	url := o.oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
 	c.Redirect(http.StatusFound, url)
 }
 
@@ -139,9 +157,9 @@ func (o *OidcAgent) LoginEnd(c *gin.Context) {
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
-	c.SetCookie("redirect", "", -1, "/", "", c.Request.URL.Scheme == "https", true)
+	c.SetCookie("redirect", "", -1, "/", "", secureCookie(c), true)
 	failureURL, _ := c.Cookie("failure")
-	c.SetCookie("failure", "", -1, "/", "", c.Request.URL.Scheme == "https", true)
+	c.SetCookie("failure", "", -1, "/", "", secureCookie(c), true)
 	if failureURL == "" {
 		failureURL = redirectURL
 	}
@@ -166,7 +184,7 @@ func (o *OidcAgent) LoginEnd(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("state", "", -1, "/", "", c.Request.URL.Scheme == "https", true)
+	c.SetCookie("state", "", -1, "/", "", secureCookie(c), true)
 	if query.State != originalState {
 		logger.With("error", err).Debug("state does not match")
 		c.Redirect(302, failureURL)
@@ -178,6 +196,7 @@ func (o *OidcAgent) LoginEnd(c *gin.Context) {
 	if query.Code == "logout" {
 		session.Delete(IDTokenKey)
 		session.Delete(TokenKey)
+		session.Delete(ProviderKey)
 		if err := session.Save(); err != nil {
 			c.Redirect(302, failureURL)
 			return
@@ -192,9 +211,17 @@ func (o *OidcAgent) LoginEnd(c *gin.Context) {
 		c.Redirect(302, failureURL)
 		return
 	}
-	c.SetCookie("nonce", "", -1, "/", "", c.Request.URL.Scheme == "https", true)
+	c.SetCookie("nonce", "", -1, "/", "", secureCookie(c), true)
 
-	oauth2Token, err := o.oauthConfig.Exchange(ctx, query.Code)
+	verifier, err := c.Cookie("code_verifier")
+	if err != nil {
+		logger.With("error", err).Debug("unable to get code_verifier cookie")
+		c.Redirect(302, failureURL)
+		return
+	}
+	c.SetCookie("code_verifier", "", -1, "/", "", secureCookie(c), true)
+
+	oauth2Token, err := o.oauthConfig.Exchange(ctx, query.Code, oauth2.VerifierOption(verifier))
 	if err != nil {
 		logger.With("error", err).Debug("unable to exchange token")
 		c.Redirect(302, failureURL)
@@ -229,11 +256,15 @@ func (o *OidcAgent) LoginEnd(c *gin.Context) {
 	}
 	session.Set(TokenKey, tokenString)
 	session.Set(IDTokenKey, rawIDToken)
+	if o.name != "" {
+		session.Set(ProviderKey, o.name)
+	}
 	if err := session.Save(); err != nil {
 		logger.With("error", err, "id_token_size", len(rawIDToken)).Debug("can't save session storage")
 		c.Redirect(302, failureURL)
 		return
 	}
+	o.indexLogin(ctx, session.SessionID(), idToken)
 
 	logger.With("session_id", session.SessionID()).Debug("user is logged in")
 	c.Redirect(http.StatusFound, redirectURL)
@@ -338,6 +369,7 @@ func (o *OidcAgent) Claims(c *gin.Context) {
 // @Tags        Auth
 // @Accept      json
 // @Produce     json
+// @Param		X-CSRF-Token header string true "CSRF token, see /web/csrf_token"
 // @Success     204
 // @Router      /web/refresh [post]
 func (o *OidcAgent) Refresh(c *gin.Context) {
@@ -387,12 +419,13 @@ func (o *OidcAgent) Refresh(c *gin.Context) {
 
 // Logout provides the URL to log out the current user.
 // @Summary     Generate Logout URL
-// @Description Provides the URL to initiate the logout process for the current user.
+// @Description Revokes the tokens of the current user and provides the URL to initiate the logout process for the user.
 // @Id          Logout
 // @Tags        Auth
 // @Accept      json
 // @Produce     json
 // @Param		redirect     query  string   true "URL to redirect to after logout"
+// @Param		csrf_token   query  string   true "CSRF token, see /web/csrf_token"
 // @Success     302 {string} string "Redirects to the OAuth2 logout URL"
 // @Router      /web/logout [get]
 func (o *OidcAgent) Logout(c *gin.Context) {
@@ -420,6 +453,18 @@ func (o *OidcAgent) Logout(c *gin.Context) {
 		c.Redirect(http.StatusFound, query.Redirect)
 		return
 	}
+	if tokenRaw, ok := session.Get(TokenKey); ok {
+		if token, err := JsonStringToToken(tokenRaw.(string)); err == nil {
+			if err := o.revokeToken(o.prepareContext(c), token); err != nil {
+				logger.With("error", err).Info("unable to revoke the token")
+			}
+		}
+		session.Delete(TokenKey)
+		if err := session.Save(); err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
 	state, err := randString(16)
 	if err != nil {
 		c.AbortWithStatus(http.StatusInternalServerError)
@@ -445,8 +490,8 @@ func (o *OidcAgent) Logout(c *gin.Context) {
 	}
 
 	c.SetSameSite(http.SameSiteStrictMode)
-	c.SetCookie("redirect", query.Redirect, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
-	c.SetCookie("state", state, int(time.Hour.Seconds()), "/", "", c.Request.URL.Scheme == "https", true)
+	c.SetCookie("redirect", query.Redirect, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	c.SetCookie("state", state, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
 	c.Redirect(http.StatusFound, logoutURL.String())
 }
 
@@ -509,6 +554,7 @@ func (o *OidcAgent) CheckAuth(c *gin.Context) {
 // @Tags        Auth
 // @Accept      json
 // @Produce     json
+// @Param		provider     query  string   false "name of the identity provider to log in with, see /web/providers"
 // @Success     200 {object} models.DeviceStartResponse
 // @Router      /device/login/start [post]
 func (o *OidcAgent) DeviceStart(c *gin.Context) {
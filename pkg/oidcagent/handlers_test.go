@@ -2,11 +2,15 @@ package oidcagent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -28,7 +32,8 @@ func TestLoginStart(t *testing.T) {
 		logger: zap.NewExample().Sugar(),
 		oauthConfig: &FakeOauthConfig{
 			AuthCodeURLFn: func(state string, opts ...oauth2.AuthCodeOption) string {
-				return "https://auth.example.com/auth?state=foo&client_id=bar"
+				config := &oauth2.Config{ClientID: "bar", Endpoint: oauth2.Endpoint{AuthURL: "https://auth.example.com/auth"}}
+				return config.AuthCodeURL(state, opts...)
 			},
 		},
 	}
@@ -40,14 +45,24 @@ func TestLoginStart(t *testing.T) {
 	r.ServeHTTP(w, req)
 
 	require.Equal(t, http.StatusFound, w.Code)
-	assert.Equal(t, "https://auth.example.com/auth?state=foo&client_id=bar", w.Header().Get("Location"))
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "auth.example.com", location.Host)
+	assert.Equal(t, "bar", location.Query().Get("client_id"))
 
 	cookies := w.Result().Cookies()
-	assert.Equal(t, 4, len(cookies))
+	require.Equal(t, 5, len(cookies))
 	assert.Equal(t, "redirect", cookies[0].Name)
 	assert.Equal(t, "failure", cookies[1].Name)
 	assert.Equal(t, "state", cookies[2].Name)
+	assert.Equal(t, cookies[2].Value, location.Query().Get("state"))
 	assert.Equal(t, "nonce", cookies[3].Name)
+	assert.Equal(t, "code_verifier", cookies[4].Name)
+
+	// the challenge is the S256 hash of the verifier kept in the cookie
+	challenge := sha256.Sum256([]byte(cookies[4].Value))
+	assert.Equal(t, "S256", location.Query().Get("code_challenge_method"))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), location.Query().Get("code_challenge"))
 }
 
 func TestLoginEnd_AuthErrorOther(t *testing.T) {
@@ -77,6 +92,9 @@ func TestLoginEnd_HandleLogin(t *testing.T) {
 		},
 		oauthConfig: &FakeOauthConfig{
 			ExchangeFn: func(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+				if !assert.Equal(t, []oauth2.AuthCodeOption{oauth2.VerifierOption("baz")}, opts) {
+					return nil, fmt.Errorf("code_verifier missing")
+				}
 				t := &oauth2.Token{
 					AccessToken:  "floofy",
 					RefreshToken: "kittens",
@@ -122,6 +140,10 @@ func TestLoginEnd_HandleLogin(t *testing.T) {
 		Name:  "nonce",
 		Value: "bar",
 	})
+	req.AddCookie(&http.Cookie{
+		Name:  "code_verifier",
+		Value: "baz",
+	})
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
@@ -0,0 +1,189 @@
+package oidcagent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-session/session/v3"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// backchannelLogoutEvent is the event of the logout tokens of the OIDC back-channel logouts
+	backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+	// how long the sessions of the logins are kept for the back-channel logouts, the lifetime of the session cookies
+	loginIndexExpiry = 7 * 24 * time.Hour
+	// the key of the comma separated ids of the sessions in a login index
+	loginIndexSessionsKey = "sessions"
+)
+
+// loginIndexKey is the key, in the session store, of the ids of the sessions logged in with a claim of the
+// id token, the subject or the session of the provider
+func (o *OidcAgent) loginIndexKey(claim string, value string) string {
+	return fmt.Sprintf("backchannel-logout:%s:%s:%s", o.oidcIssuer, claim, value)
+}
+
+// indexSessionIDs returns the ids of the sessions of a login index
+func indexSessionIDs(index session.Store) []string {
+	sessions, ok := index.Get(loginIndexSessionsKey)
+	if !ok || sessions.(string) == "" {
+		return nil
+	}
+	return strings.Split(sessions.(string), ",")
+}
+
+// indexLogin records the session a user logged in with under the subject and the session of the provider of
+// the id token, so that the back-channel logouts of the provider find it
+func (o *OidcAgent) indexLogin(ctx context.Context, sessionID string, idToken *oidc.IDToken) {
+	if o.sessionStore == nil {
+		return
+	}
+	var claims struct {
+		Sid string `json:"sid"`
+	}
+	_ = idToken.Claims(&claims)
+	for claim, value := range map[string]string{"sub": idToken.Subject, "sid": claims.Sid} {
+		if value == "" {
+			continue
+		}
+		index, err := o.sessionStore.Update(ctx, o.loginIndexKey(claim, value), int64(loginIndexExpiry.Seconds()))
+		if err != nil {
+			o.logger.With("error", err).Info("unable to read the login index")
+			continue
+		}
+		if index == nil {
+			// the store keeps the sessions in the browser, such as the cookie store
+			return
+		}
+		index.Set(loginIndexSessionsKey, strings.Join(append(indexSessionIDs(index), sessionID), ","))
+		if err := index.Save(); err != nil {
+			o.logger.With("error", err).Info("unable to save the login index")
+		}
+	}
+}
+
+// BackchannelLogout ends the sessions of the users logged out by the provider.
+// @Summary      OIDC Back-Channel Logout
+// @Description  Ends the sessions of the subject or the provider session of the logout token, called by the provider when the user logs out of it.
+// @Id           WebBackchannelLogout
+// @Tags         Auth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        logout_token  formData  string  true  "logout token of the provider"
+// @Param        provider      query     string  false "name of the identity provider of the logout token, the default provider when not given"
+// @Success      200
+// @Failure      400 {object} map[string]string
+// @Router       /web/backchannel_logout [post]
+func (o *OidcAgent) BackchannelLogout(c *gin.Context) {
+	logger := o.logger
+	ctx := o.prepareContext(c)
+	c.Header("Cache-Control", "no-store")
+
+	invalid := func(description string) {
+		logger.With("reason", description).Info("invalid back-channel logout")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": description})
+	}
+	logoutToken := c.PostForm("logout_token")
+	if logoutToken == "" {
+		invalid("logout_token missing")
+		return
+	}
+	token, err := o.verifier.Verify(ctx, logoutToken)
+	if err != nil {
+		invalid(fmt.Sprintf("unable to verify the logout token: %v", err))
+		return
+	}
+	var claims struct {
+		Sid    string                 `json:"sid"`
+		Nonce  *string                `json:"nonce"`
+		Events map[string]interface{} `json:"events"`
+	}
+	if err := token.Claims(&claims); err != nil {
+		invalid("unable to read the claims of the logout token")
+		return
+	}
+	if _, ok := claims.Events[backchannelLogoutEvent]; !ok || claims.Nonce != nil {
+		invalid("not a logout token")
+		return
+	}
+	if token.Subject == "" && claims.Sid == "" {
+		invalid("the logout token has neither sub nor sid")
+		return
+	}
+	if o.sessionStore == nil {
+		c.AbortWithStatus(http.StatusNotImplemented)
+		return
+	}
+
+	for claim, value := range map[string]string{"sub": token.Subject, "sid": claims.Sid} {
+		if value == "" {
+			continue
+		}
+		key := o.loginIndexKey(claim, value)
+		index, err := o.sessionStore.Update(ctx, key, int64(loginIndexExpiry.Seconds()))
+		if err != nil {
+			logger.With("error", err).Info("unable to read the login index")
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if index == nil {
+			c.AbortWithStatus(http.StatusNotImplemented)
+			return
+		}
+		sessionIDs := indexSessionIDs(index)
+		for _, sessionID := range sessionIDs {
+			if err := o.sessionStore.Delete(ctx, sessionID); err != nil {
+				logger.With("error", err).Info("unable to delete the session")
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := o.sessionStore.Delete(ctx, key); err != nil {
+			logger.With("error", err).Info("unable to delete the login index")
+		}
+		logger.With(claim, value, "sessions", len(sessionIDs)).Debug("back-channel logout")
+	}
+	c.Status(http.StatusOK)
+}
+
+// revokeToken revokes the refresh token, or the access token when there is none, at the revocation endpoint
+// of the provider (RFC 7009), so that the tokens of a logged out session can not be used anymore
+func (o *OidcAgent) revokeToken(ctx context.Context, token *oauth2.Token) error {
+	if o.revocationURL == "" {
+		return nil
+	}
+	form := url.Values{}
+	if token.RefreshToken != "" {
+		form.Set("token", token.RefreshToken)
+		form.Set("token_type_hint", "refresh_token")
+	} else {
+		form.Set("token", token.AccessToken)
+		form.Set("token_type_hint", "access_token")
+	}
+	if o.clientSecret == "" {
+		form.Set("client_id", o.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.revocationURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if o.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.clientID), url.QueryEscape(o.clientSecret))
+	}
+	res, err := contextClient(ctx).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("the revocation endpoint responded %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package oidcagent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-session/session/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+func fakeIDToken(subject string, claims string) *oidc.IDToken {
+	t := &oidc.IDToken{Subject: subject}
+	field := reflect.ValueOf(t).Elem().FieldByName("claims")
+	setUnexportedField(field, []byte(claims))
+	return t
+}
+
+func TestRevokeToken(t *testing.T) {
+	var form url.Values
+	var user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		user, password, _ = r.BasicAuth()
+	}))
+	defer server.Close()
+
+	auth := &OidcAgent{
+		logger:        zap.NewExample().Sugar(),
+		clientID:      "my-app",
+		clientSecret:  "secret",
+		revocationURL: server.URL,
+	}
+	require.NoError(t, auth.revokeToken(context.Background(), &oauth2.Token{AccessToken: "floofy", RefreshToken: "kittens"}))
+	assert.Equal(t, "kittens", form.Get("token"))
+	assert.Equal(t, "refresh_token", form.Get("token_type_hint"))
+	assert.Equal(t, "my-app", user)
+	assert.Equal(t, "secret", password)
+
+	// a public client identifies itself in the form
+	auth.clientSecret = ""
+	require.NoError(t, auth.revokeToken(context.Background(), &oauth2.Token{AccessToken: "floofy"}))
+	assert.Equal(t, "floofy", form.Get("token"))
+	assert.Equal(t, "access_token", form.Get("token_type_hint"))
+	assert.Equal(t, "my-app", form.Get("client_id"))
+}
+
+func TestBackchannelLogout(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewMemoryStore()
+	logoutTokens := map[string]*oidc.IDToken{
+		"user":    fakeIDToken("user1", `{"events":{"http://schemas.openid.net/event/backchannel-logout":{}}}`),
+		"session": fakeIDToken("", `{"sid":"idp-session2","events":{"http://schemas.openid.net/event/backchannel-logout":{}}}`),
+		"id":      fakeIDToken("user1", `{"nonce":"bar","events":{"http://schemas.openid.net/event/backchannel-logout":{}}}`),
+		"other":   fakeIDToken("user1", `{"events":{}}`),
+	}
+	auth := &OidcAgent{
+		logger:     zap.NewExample().Sugar(),
+		oidcIssuer: "https://auth.example.com",
+		verifier: &FakeIDTokenVerifier{
+			VerifyFn: func(ctx context.Context, rawIDToken string) (*oidc.IDToken, error) {
+				return logoutTokens[rawIDToken], nil
+			},
+		},
+	}
+	auth.SetSessionStore(store)
+
+	login := func(sessionID string, idToken *oidc.IDToken) {
+		s, err := store.Create(ctx, sessionID, 3600)
+		require.NoError(t, err)
+		s.Set(TokenKey, "kittens")
+		require.NoError(t, s.Save())
+		auth.indexLogin(ctx, sessionID, idToken)
+	}
+	login("session1", fakeIDToken("user1", `{"sid":"idp-session1"}`))
+	login("session2", fakeIDToken("user2", `{"sid":"idp-session2"}`))
+	login("session3", fakeIDToken("user1", `{"sid":"idp-session3"}`))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/backchannel_logout", auth.BackchannelLogout)
+	logout := func(logoutToken string) int {
+		form := url.Values{}
+		if logoutToken != "" {
+			form.Set("logout_token", logoutToken)
+		}
+		req, _ := http.NewRequest("POST", "/backchannel_logout", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	loggedIn := func(sessionID string) bool {
+		ok, err := store.Check(ctx, sessionID)
+		require.NoError(t, err)
+		return ok
+	}
+
+	assert.Equal(t, http.StatusBadRequest, logout(""))
+	assert.Equal(t, http.StatusBadRequest, logout("id"))
+	assert.Equal(t, http.StatusBadRequest, logout("other"))
+	assert.True(t, loggedIn("session1"))
+
+	// the logout of a subject ends all its sessions
+	assert.Equal(t, http.StatusOK, logout("user"))
+	assert.False(t, loggedIn("session1"))
+	assert.False(t, loggedIn("session3"))
+	assert.True(t, loggedIn("session2"))
+
+	// the logout of a session of the provider ends the sessions logged in with it
+	assert.Equal(t, http.StatusOK, logout("session"))
+	assert.False(t, loggedIn("session2"))
+}
@@ -4,9 +4,6 @@ import (
 	"net/http"
 
 	"github.com/gin-contrib/cors"
-	"github.com/go-session/session/v3"
-	"github.com/nexodus-io/nexodus/pkg/cookie"
-	"github.com/nexodus-io/nexodus/pkg/ginsession"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,17 +31,7 @@ func (auth *OidcAgent) CorsMiddleware() gin.HandlerFunc {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowCredentials = true
 	corsConfig.AllowOrigins = auth.trustedOrigins
+	corsConfig.AllowHeaders = append(corsConfig.AllowHeaders, CSRFHeader)
 	corsConfig.ExposeHeaders = append(corsConfig.ExposeHeaders, "X-Total-Count")
 	return cors.New(corsConfig)
 }
-
-func (auth *OidcAgent) CookieSessionMiddleware() gin.HandlerFunc {
-	session.InitManager(
-		session.SetStore(
-			cookie.NewCookieStore(
-				cookie.SetHashKey([]byte(auth.cookieKey)),
-			),
-		),
-	)
-	return ginsession.New()
-}
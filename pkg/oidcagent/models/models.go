@@ -23,3 +23,41 @@ type DeviceStartResponse struct {
 	// in relation to the server.
 	ServerTime *time.Time `json:"server_time" format:"date-time"`
 }
+
+type ProviderResponse struct {
+	// the name of the provider in the provider query parameter of the login requests
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Issuer      string `json:"issuer"`
+}
+
+type CSRFTokenResponse struct {
+	// the token to send in the X-CSRF-Token header, or the csrf_token query parameter, of the requests changing the session
+	CSRFToken string `json:"csrf_token"`
+}
+
+// DeviceAuthorizationResponse is the device authorization response of the provider to a device login
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// DeviceTokenResponse is the token response of the provider to a device login
+type DeviceTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// DeviceTokenError is the error response of a poll of a device login, authorization_pending when the user
+// has not authorized the device yet
+type DeviceTokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
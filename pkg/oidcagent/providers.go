@@ -0,0 +1,226 @@
+package oidcagent
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-session/session/v3"
+	"github.com/nexodus-io/nexodus/pkg/ginsession"
+	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
+)
+
+// ProviderKey is the session key of the name of the identity provider the user logged in with
+const ProviderKey = "provider"
+
+// providerCookie keeps the provider selected at the login start for the login end
+const providerCookie = "provider"
+
+//go:embed providers.html
+var providersPage string
+
+var providersTemplate = template.Must(template.New("providers").Parse(providersPage))
+
+// Provider is an identity provider the users can log in with
+type Provider struct {
+	// Name identifies the provider in the login requests
+	Name string
+	// DisplayName is shown to the users selecting the provider they log in with
+	DisplayName string
+	Agent       *OidcAgent
+}
+
+// MultiOidcAgent serves the login flows of several identity providers, the users select the provider they
+// log in with. The first provider is the default one.
+type MultiOidcAgent struct {
+	providers []Provider
+}
+
+// NewMultiOidcAgent returns a MultiOidcAgent of the providers, the first provider is the default one
+func NewMultiOidcAgent(providers ...Provider) *MultiOidcAgent {
+	for i := range providers {
+		providers[i].Agent.name = providers[i].Name
+		if providers[i].DisplayName == "" {
+			providers[i].DisplayName = providers[i].Name
+		}
+	}
+	return &MultiOidcAgent{providers: providers}
+}
+
+// Default returns the agent of the default provider
+func (m *MultiOidcAgent) Default() *OidcAgent {
+	return m.providers[0].Agent
+}
+
+// Agent returns the agent of a provider, the default provider when name is empty
+func (m *MultiOidcAgent) Agent(name string) (*OidcAgent, bool) {
+	if name == "" {
+		return m.Default(), true
+	}
+	for _, p := range m.providers {
+		if p.Name == name {
+			return p.Agent, true
+		}
+	}
+	return nil, false
+}
+
+// sessionAgent returns the agent of the provider the user of the session logged in with
+func (m *MultiOidcAgent) sessionAgent(c *gin.Context) *OidcAgent {
+	session := ginsession.FromContext(c)
+	if name, ok := session.Get(ProviderKey); ok {
+		if agent, found := m.Agent(name.(string)); found {
+			return agent
+		}
+	}
+	return m.Default()
+}
+
+func (m *MultiOidcAgent) OriginVerifier() gin.HandlerFunc {
+	return m.Default().OriginVerifier()
+}
+
+// Providers lists the identity providers the users can log in with.
+// @Summary      List Identity Providers
+// @Description  Lists the identity providers the users can log in with, the first one is the default
+// @Id           WebProviders
+// @Tags         Auth
+// @Accepts      json
+// @Produce      json
+// @Success      200 {object} []models.ProviderResponse
+// @Router       /web/providers [get]
+func (m *MultiOidcAgent) Providers(c *gin.Context) {
+	providers := []models.ProviderResponse{}
+	for _, p := range m.providers {
+		providers = append(providers, models.ProviderResponse{
+			Name:        p.Name,
+			DisplayName: p.DisplayName,
+			Issuer:      p.Agent.oidcIssuer,
+		})
+	}
+	c.JSON(http.StatusOK, providers)
+}
+
+// LoginStart initiates the OIDC login process with the provider of the provider query parameter. When there
+// are several providers and none was selected, it responds with a page to select one.
+func (m *MultiOidcAgent) LoginStart(c *gin.Context) {
+	name := c.Query("provider")
+	if name == "" && len(m.providers) > 1 {
+		m.selectProvider(c)
+		return
+	}
+	agent, found := m.Agent(name)
+	if !found {
+		m.Default().logger.Info("unknown provider")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(providerCookie, agent.name, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	agent.LoginStart(c)
+}
+
+// selectProvider responds with a page linking to the login start of each provider
+func (m *MultiOidcAgent) selectProvider(c *gin.Context) {
+	type link struct {
+		DisplayName string
+		URL         string
+	}
+	var links []link
+	for _, p := range m.providers {
+		query := c.Request.URL.Query()
+		query.Set("provider", p.Name)
+		u := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+		links = append(links, link{DisplayName: p.DisplayName, URL: u.String()})
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := providersTemplate.Execute(c.Writer, links); err != nil {
+		m.Default().logger.With("error", err).Info("unable to render the providers page")
+	}
+}
+
+// LoginEnd completes the OIDC login process with the provider selected at the login start
+func (m *MultiOidcAgent) LoginEnd(c *gin.Context) {
+	name, _ := c.Cookie(providerCookie)
+	c.SetCookie(providerCookie, "", -1, "/", "", secureCookie(c), true)
+	agent, found := m.Agent(name)
+	if !found {
+		agent = m.Default()
+	}
+	agent.LoginEnd(c)
+}
+
+func (m *MultiOidcAgent) UserInfo(c *gin.Context) {
+	m.sessionAgent(c).UserInfo(c)
+}
+
+func (m *MultiOidcAgent) Claims(c *gin.Context) {
+	m.sessionAgent(c).Claims(c)
+}
+
+func (m *MultiOidcAgent) Refresh(c *gin.Context) {
+	m.sessionAgent(c).Refresh(c)
+}
+
+// Logout logs the user out of the provider they logged in with
+func (m *MultiOidcAgent) Logout(c *gin.Context) {
+	agent := m.sessionAgent(c)
+	c.SetCookie(providerCookie, agent.name, int(time.Hour.Seconds()), "/", "", secureCookie(c), true)
+	agent.Logout(c)
+}
+
+// BackchannelLogout ends the sessions logged out by the provider of the provider query parameter, the default
+// provider when not given
+func (m *MultiOidcAgent) BackchannelLogout(c *gin.Context) {
+	agent, found := m.Agent(c.Query("provider"))
+	if !found {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "unknown provider"})
+		return
+	}
+	agent.BackchannelLogout(c)
+}
+
+// SetSessionStore sets the session store of the agents of the providers, the store the sessions of the web
+// logins are kept in
+func (m *MultiOidcAgent) SetSessionStore(store session.ManagerStore) {
+	for _, p := range m.providers {
+		p.Agent.SetSessionStore(store)
+	}
+}
+
+// DeviceAuthorize requests a device login from the provider of the provider query parameter, the default
+// provider when not given
+func (m *MultiOidcAgent) DeviceAuthorize(c *gin.Context) {
+	agent, found := m.Agent(c.Query("provider"))
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "unknown provider"})
+		return
+	}
+	agent.DeviceAuthorize(c)
+}
+
+// DevicePoll polls the tokens of a device login of the provider of the provider query parameter, the default
+// provider when not given
+func (m *MultiOidcAgent) DevicePoll(c *gin.Context) {
+	agent, found := m.Agent(c.Query("provider"))
+	if !found {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request", "error_description": "unknown provider"})
+		return
+	}
+	agent.DevicePoll(c)
+}
+
+// DeviceStart initiates the device login process with the provider of the provider query parameter, the
+// default provider when not given.
+func (m *MultiOidcAgent) DeviceStart(c *gin.Context) {
+	agent, found := m.Agent(c.Query("provider"))
+	if !found {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	agent.DeviceStart(c)
+}
@@ -0,0 +1,68 @@
+package oidcagent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nexodus-io/nexodus/pkg/oidcagent/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+func TestMultiOidcAgentLoginStart(t *testing.T) {
+	newAgent := func(issuer string) *OidcAgent {
+		return &OidcAgent{
+			logger:     zap.NewExample().Sugar(),
+			oidcIssuer: issuer,
+			oauthConfig: &FakeOauthConfig{
+				AuthCodeURLFn: func(state string, opts ...oauth2.AuthCodeOption) string {
+					return issuer + "/auth"
+				},
+			},
+		}
+	}
+	auth := NewMultiOidcAgent(
+		Provider{Name: "default", DisplayName: "Nexodus", Agent: newAgent("https://auth.example.com")},
+		Provider{Name: "google", DisplayName: "Google", Agent: newAgent("https://accounts.example.com")},
+	)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/providers", auth.Providers)
+	r.GET("/login/start", auth.LoginStart)
+	serve := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/providers")
+	require.Equal(t, http.StatusOK, w.Code)
+	var providers []models.ProviderResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &providers))
+	require.Len(t, providers, 2)
+	assert.Equal(t, "google", providers[1].Name)
+	assert.Equal(t, "https://accounts.example.com", providers[1].Issuer)
+
+	// the users select the provider when there are several
+	w = serve("/login/start?redirect=a")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `href="/login/start?provider=google&amp;redirect=a"`)
+	assert.Contains(t, w.Body.String(), "Google")
+
+	w = serve("/login/start?redirect=a&provider=google")
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://accounts.example.com/auth", w.Header().Get("Location"))
+	cookies := w.Result().Cookies()
+	require.NotEmpty(t, cookies)
+	assert.Equal(t, "provider", cookies[0].Name)
+	assert.Equal(t, "google", cookies[0].Value)
+
+	w = serve("/login/start?redirect=a&provider=unknown")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -14,7 +14,7 @@ func init() {
 func NewCodeFlowRouter(auth *OidcAgent) *gin.Engine {
 	r := gin.Default()
 	r.Use(auth.CorsMiddleware())
-	r.Use(auth.CookieSessionMiddleware())
+	r.Use(auth.SessionMiddleware())
 	AddCodeFlowRoutes(r, auth)
 	r.Any("/api/*proxyPath", auth.CodeFlowProxy)
 	return r
@@ -26,9 +26,11 @@ func AddCodeFlowRoutes(r gin.IRouter, auth *OidcAgent) {
 	r.GET("/login/end", auth.LoginEnd)
 	r.GET("/user_info", auth.UserInfo)
 	r.GET("/claims", auth.Claims)
-	r.POST("/logout", auth.Logout)
+	r.GET("/csrf_token", CSRFToken)
+	r.POST("/logout", CSRFMiddleware(), auth.Logout)
 	// r.GET("/check_auth", auth.CheckAuth)
-	r.POST("/refresh", auth.Refresh)
+	r.POST("/refresh", CSRFMiddleware(), auth.Refresh)
+	r.POST("/backchannel_logout", auth.BackchannelLogout)
 }
 
 func NewDeviceFlowRouter(auth *OidcAgent) *gin.Engine {
@@ -40,4 +42,6 @@ func NewDeviceFlowRouter(auth *OidcAgent) *gin.Engine {
 
 func AddDeviceFlowRoutes(r gin.IRouter, auth *OidcAgent) {
 	r.POST("/login/start", auth.DeviceStart)
+	r.POST("/login/authorize", auth.DeviceAuthorize)
+	r.POST("/login/poll", auth.DevicePoll)
 }
@@ -0,0 +1,70 @@
+package oidcagent
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	redisStore "github.com/go-session/redis/v3"
+	"github.com/go-session/session/v3"
+	"github.com/nexodus-io/nexodus/pkg/cookie"
+	"github.com/nexodus-io/nexodus/pkg/ginsession"
+)
+
+// The kinds of session stores of NewSessionStore
+const (
+	// MemorySessionStore keeps the sessions in the memory of the agent, they are lost on restart and not
+	// shared by the replicas of the agent
+	MemorySessionStore = "memory"
+	// CookieSessionStore keeps the sessions in encrypted cookies, the tokens must fit in the size of a cookie
+	CookieSessionStore = "cookie"
+	// RedisSessionStore keeps the sessions in redis, so that they are shared by the replicas of the agent
+	RedisSessionStore = "redis"
+)
+
+// SessionStoreOptions configures the session store returned by NewSessionStore
+type SessionStoreOptions struct {
+	// CookieKey is the key the cookies of the cookie store are signed with
+	CookieKey string
+	// RedisServer is the address of the redis server of the redis store
+	RedisServer string
+	// RedisPassword is the password of the redis server of the redis store
+	RedisPassword string
+	// RedisDB is the redis database of the redis store
+	RedisDB int
+	// RedisKeyPrefix is the prefix of the keys of the sessions in redis
+	RedisKeyPrefix string
+}
+
+// NewSessionStore returns a session store of a kind, one of MemorySessionStore, CookieSessionStore or
+// RedisSessionStore
+func NewSessionStore(kind string, opts SessionStoreOptions) (session.ManagerStore, error) {
+	switch kind {
+	case MemorySessionStore:
+		return session.NewMemoryStore(), nil
+	case CookieSessionStore:
+		return cookie.NewCookieStore(cookie.SetHashKey([]byte(opts.CookieKey))), nil
+	case RedisSessionStore:
+		return redisStore.NewRedisStore(&redisStore.Options{
+			Addr:     opts.RedisServer,
+			Password: opts.RedisPassword,
+			DB:       opts.RedisDB,
+		}, opts.RedisKeyPrefix), nil
+	default:
+		return nil, fmt.Errorf("unknown session store %q, it should be one of '%s', '%s' or '%s'",
+			kind, MemorySessionStore, CookieSessionStore, RedisSessionStore)
+	}
+}
+
+// SetSessionStore sets the store of the sessions of SessionMiddleware, the sessions are kept in memory when
+// no store is set
+func (auth *OidcAgent) SetSessionStore(store session.ManagerStore) {
+	auth.sessionStore = store
+}
+
+// SessionMiddleware starts the session of each request in the session store of the agent
+func (auth *OidcAgent) SessionMiddleware() gin.HandlerFunc {
+	if auth.sessionStore == nil {
+		auth.sessionStore = session.NewMemoryStore()
+	}
+	return ginsession.New(session.SetStore(auth.sessionStore))
+}
@@ -0,0 +1,65 @@
+package oidcagent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-session/session/v3"
+	"github.com/nexodus-io/nexodus/pkg/ginsession"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionStore(t *testing.T) {
+	for _, kind := range []string{MemorySessionStore, CookieSessionStore, RedisSessionStore} {
+		store, err := NewSessionStore(kind, SessionStoreOptions{CookieKey: "secretkey", RedisServer: "localhost:6379"})
+		require.NoError(t, err, kind)
+		assert.NotNil(t, store, kind)
+	}
+	_, err := NewSessionStore("memcached", SessionStoreOptions{})
+	assert.Error(t, err)
+}
+
+func TestSessionMiddleware_SharedStore(t *testing.T) {
+	store := session.NewMemoryStore()
+	gin.SetMode(gin.TestMode)
+
+	// two replicas of the agent sharing a session store
+	replica := func() *gin.Engine {
+		auth := &OidcAgent{}
+		auth.SetSessionStore(store)
+		r := gin.New()
+		r.Use(auth.SessionMiddleware())
+		r.GET("/set", func(c *gin.Context) {
+			s := ginsession.FromContext(c)
+			s.Set(TokenKey, "kittens")
+			require.NoError(t, s.Save())
+		})
+		r.GET("/get", func(c *gin.Context) {
+			token, ok := ginsession.FromContext(c).Get(TokenKey)
+			if !ok {
+				c.Status(http.StatusNotFound)
+				return
+			}
+			c.String(http.StatusOK, token.(string))
+		})
+		return r
+	}
+	first, second := replica(), replica()
+
+	req, _ := http.NewRequest("GET", "/set", nil)
+	w := httptest.NewRecorder()
+	first.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	req, _ = http.NewRequest("GET", "/get", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	second.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "kittens", w.Body.String())
+}
@@ -1,5 +1,6 @@
 import { AuthProvider, UserIdentity } from "react-admin";
 import { RefreshManager } from "./RefreshManager";
+import { fetchCSRFToken } from "./CSRFToken";
 import { red } from "@mui/material/colors";
 
 const originalLocationURL = window.location.href;
@@ -60,8 +61,11 @@ export const goOidcAgentAuthProvider = (api: string): AuthProvider => {
       let redirect = window.location.href;
       // does the redirect contain a hash? If so, remove it.
       redirect = redirect.split("#")[0];
+      const csrfToken = await fetchCSRFToken(api);
       window.location.replace(
-        `${api}/web/logout?redirect=${encodeURIComponent(redirect)}`,
+        `${api}/web/logout?redirect=${encodeURIComponent(
+          redirect,
+        )}&csrf_token=${encodeURIComponent(csrfToken)}`,
       );
     },
 
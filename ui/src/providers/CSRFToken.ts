@@ -0,0 +1,15 @@
+// fetchCSRFToken gets the CSRF token the requests changing the session must carry,
+// in the X-CSRF-Token header or the csrf_token query parameter.
+export const fetchCSRFToken = async (api: string): Promise<string> => {
+  const request = new Request(`${api}/web/csrf_token`, {
+    credentials: "include",
+  });
+  const response = await fetch(request);
+  if (!response.ok) {
+    throw new Error(
+      `Received ${response.status} from server fetching the CSRF token.`,
+    );
+  }
+  const data = await response.json();
+  return data.csrf_token;
+};
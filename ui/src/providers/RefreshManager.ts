@@ -1,4 +1,5 @@
 import { jwtDecode } from "jwt-decode";
+import { fetchCSRFToken } from "./CSRFToken";
 
 export class RefreshManager {
   static refreshIntervalId: number | undefined = undefined;
@@ -6,12 +7,12 @@ export class RefreshManager {
 
   // one-time refresh POST
   static async postRefresh(api: string): Promise<void> {
-    const request = new Request(`${api}/web/refresh`, {
-      method: "POST",
-      credentials: "include",
-    });
-
     try {
+      const request = new Request(`${api}/web/refresh`, {
+        method: "POST",
+        credentials: "include",
+        headers: { "X-CSRF-Token": await fetchCSRFToken(api) },
+      });
       const response = await fetch(request);
       if (!response.ok) {
         console.error(